@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
@@ -67,6 +68,7 @@ func (hs *HTTPServer) AdminCreateUser(c *contextmodel.ReqContext) response.Respo
 	}
 
 	metrics.MApiAdminUserCreate.Inc()
+	hs.auditUser(c.Req.Context(), usr.ID, auditlog.SetAfter)
 
 	result := user.AdminCreateUserResponse{
 		Message: "User created",
@@ -164,6 +166,7 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 		}
 	}
 
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetBefore)
 	err = hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{
 		UserID:         userID,
 		IsGrafanaAdmin: &form.IsGrafanaAdmin,
@@ -175,6 +178,7 @@ func (hs *HTTPServer) AdminUpdateUserPermissions(c *contextmodel.ReqContext) res
 
 		return response.Error(http.StatusInternalServerError, "Failed to update user permissions", err)
 	}
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetAfter)
 
 	return response.Success("User permissions updated")
 }
@@ -202,6 +206,7 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 
 	cmd := user.DeleteUserCommand{UserID: userID}
 
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetBefore)
 	if err := hs.userService.Delete(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
@@ -293,12 +298,14 @@ func (hs *HTTPServer) AdminDisableUser(c *contextmodel.ReqContext) response.Resp
 	}
 
 	isDisabled := true
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetBefore)
 	if err := hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{UserID: userID, IsDisabled: &isDisabled}); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to disable user", err)
 	}
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetAfter)
 
 	err = hs.AuthTokenService.RevokeAllUserTokens(c.Req.Context(), userID)
 	if err != nil {
@@ -336,12 +343,14 @@ func (hs *HTTPServer) AdminEnableUser(c *contextmodel.ReqContext) response.Respo
 	}
 
 	isDisabled := false
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetBefore)
 	if err := hs.userService.Update(c.Req.Context(), &user.UpdateUserCommand{UserID: userID, IsDisabled: &isDisabled}); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to enable user", err)
 	}
+	hs.auditUser(c.Req.Context(), userID, auditlog.SetAfter)
 
 	return response.Success("User enabled")
 }
@@ -374,6 +383,34 @@ func (hs *HTTPServer) AdminLogoutUser(c *contextmodel.ReqContext) response.Respo
 	return hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
 }
 
+// swagger:route DELETE /admin/users/{user_id}/mfa admin_users adminResetUserMFA
+//
+// Reset the multi-factor authentication of a user.
+// Removes the authenticator app, the security keys and the recovery codes of the user, for example when they were lost.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResetUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.mfaService.Reset(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset multi-factor authentication", err)
+	}
+
+	return response.Success("Multi-factor authentication reset")
+}
+
 // swagger:route GET /admin/users/{user_id}/auth-tokens admin_users adminGetUserAuthTokens
 //
 // Return a list of all auth tokens (devices) that the user currently have logged in from.
@@ -469,6 +506,13 @@ type AdminLogoutUserParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters adminResetUserMFA
+type AdminResetUserMFAParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
 // swagger:parameters adminRevokeUserAuthToken
 type AdminRevokeUserAuthTokenParams struct {
 	// in:body
@@ -21,13 +21,16 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -220,6 +223,12 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 			// is for better UX, showing in Save/Delete dialogs and so it won't break anything if it is empty.
 			hs.log.Warn("Failed to create ProvisionedExternalId", "err", err)
 		}
+		meta.ProvisionedCommitSha = provisioningData.CommitSHA
+		// The push status can contain details of the git repository, which only users who can change the
+		// dashboard need.
+		if canSave {
+			meta.ProvisionedPush = hs.ProvisioningService.GetDashboardPushStatus(provisioningData)
+		}
 	}
 
 	// make sure db version is in sync with json model version
@@ -230,6 +239,10 @@ func (hs *HTTPServer) GetDashboard(c *contextmodel.ReqContext) response.Response
 		Meta:      meta,
 	}
 
+	if hs.dashboardUsageService != nil {
+		hs.dashboardUsageService.RecordView(ctx, c.SignedInUser, dash.UID)
+	}
+
 	c.TimeRequest(metrics.MApiDashboardGet)
 	return response.JSON(http.StatusOK, dto)
 }
@@ -337,6 +350,7 @@ func (hs *HTTPServer) RestoreDeletedDashboard(c *contextmodel.ReqContext) respon
 		}
 		return response.Error(http.StatusInternalServerError, "Dashboard cannot be restored", err)
 	}
+	auditDashboard(c.Req.Context(), dash, auditlog.SetAfter)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"title":   dash.Title,
@@ -377,6 +391,7 @@ func (hs *HTTPServer) SoftDeleteDashboard(c *contextmodel.ReqContext) response.R
 		return dashboardGuardianResponse(err)
 	}
 
+	auditDashboard(c.Req.Context(), dash, auditlog.SetBefore)
 	err = hs.DashboardService.SoftDeleteDashboard(c.Req.Context(), c.SignedInUser.GetOrgID(), uid)
 	if err != nil {
 		var dashboardErr dashboards.DashboardErr
@@ -461,6 +476,7 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 	if dash.IsFolder {
 		return response.Error(http.StatusBadRequest, "Use folders endpoint for deleting folders.", nil)
 	}
+	auditDashboard(c.Req.Context(), dash, auditlog.SetBefore)
 
 	// disconnect all library elements for this dashboard
 	err = hs.LibraryElementService.DisconnectElementsFromDashboard(c.Req.Context(), dash.ID)
@@ -591,6 +607,12 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		Overwrite: cmd.Overwrite,
 	}
 
+	if auditlog.IsRecording(ctx) && (dash.ID != 0 || dash.UID != "") {
+		if existing, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dash.OrgID}); err == nil {
+			auditDashboard(ctx, existing, auditlog.SetBefore)
+		}
+	}
+
 	dashboard, saveErr := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
 
 	if hs.Live != nil {
@@ -620,6 +642,18 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	if saveErr != nil {
 		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, saveErr)
 	}
+	auditDashboard(ctx, dashboard, auditlog.SetAfter)
+
+	var provisionedPush *gitsource.PushStatus
+	if provisioningData != nil && allowUiUpdate {
+		// Commit UI edits back to the provisioning source when it supports it, e.g. a git repository.
+		// Changes are pushed in the background, the state of the push is part of the dashboard metadata.
+		err := hs.ProvisioningService.PushDashboardChanges(ctx, provisioningData, dashboard.Data, cmd.Message, c.SignedInUser)
+		if err != nil {
+			hs.log.Warn("Failed to push dashboard changes to provisioning source", "uid", dashboard.UID, "error", err)
+		}
+		provisionedPush = hs.ProvisioningService.GetDashboardPushStatus(provisioningData)
+	}
 
 	// Clear permission cache for the user who's created the dashboard, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
@@ -634,7 +668,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":    "success",
 		"slug":      dashboard.Slug,
 		"version":   dashboard.Version,
@@ -642,7 +676,18 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
-	})
+	}
+	if provisionedPush != nil {
+		result["provisionedPush"] = provisionedPush
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// auditDashboard records the hash of the JSON model of the dashboard in the audit log event of the
+// request, before or after the change made by the request.
+func auditDashboard(ctx context.Context, dash *dashboards.Dashboard, set func(context.Context, any)) {
+	auditlog.SetResource(ctx, "dashboards", dash.UID)
+	set(ctx, dash.Data)
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -1058,6 +1103,140 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
 }
 
+// swagger:route POST /dashboards/semantic-diff dashboards calculateSemanticDashboardDiff
+//
+// Lists the changes of panels, queries, variables and datasources between two dashboards.
+//
+// The dashboards can be two versions of the same dashboard or two different dashboards.
+//
+// Responses:
+// 200: calculateSemanticDashboardDiffResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CalculateSemanticDashboardDiff(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.CalculateSemanticDashboardDiff")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	apiOptions := dtos.SemanticDiffOptions{}
+	if err := web.Bind(c.Req, &apiOptions); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	baseData, rsp := hs.getSemanticDiffTarget(c, apiOptions.Base)
+	if rsp != nil {
+		return rsp
+	}
+	newData, rsp := hs.getSemanticDiffTarget(c, apiOptions.New)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(http.StatusOK, dashdiffs.CalculateSemanticDiff(baseData, newData))
+}
+
+// getSemanticDiffTarget returns the data of the dashboard version to diff, or the unsaved dashboard.
+func (hs *HTTPServer) getSemanticDiffTarget(c *contextmodel.ReqContext, target dtos.SemanticDiffTarget) (*simplejson.Json, response.Response) {
+	if target.DashboardUID == "" && target.DashboardId == 0 {
+		if target.UnsavedDashboard == nil {
+			return nil, response.Error(http.StatusBadRequest, "dashboardUid, dashboardId or unsavedDashboard is required", nil)
+		}
+		return target.UnsavedDashboard, nil
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), target.DashboardId, target.DashboardUID)
+	if rsp != nil {
+		return nil, rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return nil, response.Err(err)
+	}
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return nil, dashboardGuardianResponse(err)
+	}
+
+	if target.UnsavedDashboard != nil {
+		return target.UnsavedDashboard, nil
+	}
+	if target.Version == 0 {
+		return dash.Data, nil
+	}
+
+	version, err := hs.dashboardVersionService.Get(c.Req.Context(), &dashver.GetDashboardVersionQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		Version:      target.Version,
+	})
+	if err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Dashboard version not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
+	}
+	return version.Data, nil
+}
+
+// swagger:route POST /dashboards/lint dashboards lintDashboard
+//
+// Checks a dashboard against best practices.
+//
+// Lints the dashboard JSON model of the request, or a saved dashboard if dashboardUid is set.
+//
+// Responses:
+// 200: lintDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+func (hs *HTTPServer) LintDashboard(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "api.LintDashboard")
+	defer span.End()
+	c.Req = c.Req.WithContext(ctx)
+
+	cmd := dtos.LintDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	data := cmd.Dashboard
+	if cmd.DashboardUID != "" {
+		dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, cmd.DashboardUID)
+		if rsp != nil {
+			return rsp
+		}
+		guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return response.Err(err)
+		}
+		if canView, err := guardian.CanView(); err != nil || !canView {
+			return dashboardGuardianResponse(err)
+		}
+		data = dash.Data
+	}
+	if data == nil {
+		return response.Error(http.StatusBadRequest, "dashboard or dashboardUid is required", nil)
+	}
+
+	return response.JSON(http.StatusOK, lint.NewFromCfg(hs.Cfg).Lint(data))
+}
+
+// swagger:route GET /dashboards/lint/rules dashboards getDashboardLintRules
+//
+// Lists the enabled dashboard lint rules.
+//
+// Responses:
+// 200: getDashboardLintRulesResponse
+// 401: unauthorisedError
+func (hs *HTTPServer) GetDashboardLintRules(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, lint.NewFromCfg(hs.Cfg).Rules())
+}
+
 // swagger:route POST /dashboards/id/{DashboardID}/restore dashboard_versions restoreDashboardVersionByID
 //
 // Restore a dashboard to a given dashboard version.
@@ -1376,6 +1555,38 @@ type CalculateDashboardDiffResponse struct {
 	Body []byte `json:"body"`
 }
 
+// swagger:response calculateSemanticDashboardDiffResponse
+type CalculateSemanticDashboardDiffResponse struct {
+	// in: body
+	Body dashdiffs.SemanticDiff `json:"body"`
+}
+
+// swagger:parameters calculateSemanticDashboardDiff
+type CalculateSemanticDashboardDiffParams struct {
+	// in:body
+	// required:true
+	Body dtos.SemanticDiffOptions
+}
+
+// swagger:parameters lintDashboard
+type LintDashboardParams struct {
+	// in:body
+	// required:true
+	Body dtos.LintDashboardCommand
+}
+
+// swagger:response lintDashboardResponse
+type LintDashboardResponse struct {
+	// in: body
+	Body lint.Result `json:"body"`
+}
+
+// swagger:response getDashboardLintRulesResponse
+type GetDashboardLintRulesResponse struct {
+	// in: body
+	Body []lint.RuleInfo `json:"body"`
+}
+
 // swagger:response getHomeDashboardResponse
 type GetHomeDashboardResponse struct {
 	// in: body
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errhttp"
 	"github.com/grafana/grafana/pkg/web"
@@ -84,6 +85,21 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) {
 		}
 	}
 
+	if cmd.RefreshInterval < 0 {
+		c.JsonApiErr(http.StatusBadRequest, "Refresh interval must not be negative", nil)
+		return
+	}
+	if cmd.RefreshInterval > 0 {
+		if cmd.External {
+			c.JsonApiErr(http.StatusBadRequest, "External snapshots cannot be refreshed", nil)
+			return
+		}
+		if time.Duration(cmd.RefreshInterval)*time.Second < hs.Cfg.SnapshotMinRefreshInterval {
+			c.JsonApiErr(http.StatusBadRequest, fmt.Sprintf("Refresh interval must be at least %s", hs.Cfg.SnapshotMinRefreshInterval), nil)
+			return
+		}
+	}
+
 	dashboardsnapshots.CreateDashboardSnapshot(c, dashboardsnapshot.SnapshotSharingOptions{
 		SnapshotsEnabled:     hs.Cfg.SnapshotEnabled,
 		ExternalEnabled:      hs.Cfg.ExternalEnabled,
@@ -127,19 +143,33 @@ func (hs *HTTPServer) GetDashboardSnapshot(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusNotFound, "Dashboard snapshot not found", err)
 	}
 
-	dto := dtos.DashboardFullWithMeta{
+	if snapshot.ShareLinksOnly && (!c.IsSignedIn || c.SignedInUser.GetOrgID() != snapshot.OrgID) {
+		return response.Error(http.StatusForbidden, "Dashboard snapshot is only available through share links", nil)
+	}
+
+	metrics.MApiDashboardSnapshotGet.Inc()
+
+	cacheControl := "public, max-age=3600"
+	if snapshot.ShareLinksOnly {
+		cacheControl = "private, no-store"
+	} else if snapshot.RefreshInterval > 0 && snapshot.RefreshInterval < 3600 {
+		cacheControl = fmt.Sprintf("public, max-age=%d", snapshot.RefreshInterval)
+	}
+
+	return response.JSON(http.StatusOK, snapshotDTO(snapshot)).SetHeader("Cache-Control", cacheControl)
+}
+
+func snapshotDTO(snapshot *dashboardsnapshots.DashboardSnapshot) dtos.DashboardFullWithMeta {
+	return dtos.DashboardFullWithMeta{
 		Dashboard: snapshot.Dashboard,
 		Meta: dtos.DashboardMeta{
 			Type:       dashboards.DashTypeSnapshot,
 			IsSnapshot: true,
 			Created:    snapshot.Created,
+			Updated:    snapshot.Updated,
 			Expires:    snapshot.Expires,
 		},
 	}
-
-	metrics.MApiDashboardSnapshotGet.Inc()
-
-	return response.JSON(http.StatusOK, dto).SetHeader("Cache-Control", "public, max-age=3600")
 }
 
 // swagger:route GET /snapshots-delete/{deleteKey} snapshots deleteDashboardSnapshotByDeleteKey
@@ -301,12 +331,197 @@ func (hs *HTTPServer) SearchDashboardSnapshots(c *contextmodel.ReqContext) respo
 			Expires:     snapshot.Expires,
 			Created:     snapshot.Created,
 			Updated:     snapshot.Updated,
+
+			RefreshInterval: snapshot.RefreshInterval,
+			ShareLinksOnly:  snapshot.ShareLinksOnly,
 		}
 	}
 
 	return response.JSON(http.StatusOK, dto)
 }
 
+// getManagedSnapshot returns the snapshot of the request if the signed in user can manage it.
+func (hs *HTTPServer) getManagedSnapshot(c *contextmodel.ReqContext) (*dashboardsnapshots.DashboardSnapshot, response.Response) {
+	if !hs.Cfg.SnapshotEnabled {
+		return nil, response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	key := web.Params(c.Req)[":key"]
+	if len(key) == 0 {
+		return nil, response.Error(http.StatusNotFound, "Snapshot not found", nil)
+	}
+
+	snapshot, err := hs.dashboardsnapshotsService.GetDashboardSnapshot(c.Req.Context(), &dashboardsnapshots.GetDashboardSnapshotQuery{Key: key})
+	if err != nil {
+		return nil, response.Err(err)
+	}
+
+	canManage, err := dashboardsnapshots.CanManage(c.Req.Context(), snapshot, c.SignedInUser)
+	if err != nil {
+		return nil, response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot", err)
+	}
+	if !canManage {
+		return nil, response.Error(http.StatusForbidden, "Access denied to this snapshot", nil)
+	}
+
+	return snapshot, nil
+}
+
+func snapshotShareDTO(share *dashboardsnapshots.DashboardSnapshotShare) *dashboardsnapshots.DashboardSnapshotShareDTO {
+	return &dashboardsnapshots.DashboardSnapshotShareDTO{
+		DashboardSnapshotShare: *share,
+		Protected:              share.Password != "",
+		URL:                    setting.ToAbsUrl("api/snapshot-shares/" + share.Key),
+	}
+}
+
+// swagger:route POST /snapshots/{key}/shares snapshots createDashboardSnapshotShare
+//
+// Create a share link for a snapshot.
+//
+// Share links can expire, be protected by a password and be limited to a number of views.
+//
+// Responses:
+// 200: createDashboardSnapshotShareResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CreateDashboardSnapshotShare(c *contextmodel.ReqContext) response.Response {
+	cmd := dashboardsnapshots.CreateDashboardSnapshotShareCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	snapshot, errResp := hs.getManagedSnapshot(c)
+	if errResp != nil {
+		return errResp
+	}
+	if snapshot.External {
+		return response.Error(http.StatusBadRequest, "External snapshots cannot be shared", nil)
+	}
+
+	cmd.SnapshotID = snapshot.ID
+	cmd.OrgID = snapshot.OrgID
+	cmd.CreatedBy, _ = identity.UserIdentifier(c.SignedInUser.GetID())
+
+	share, err := hs.dashboardsnapshotsService.CreateDashboardSnapshotShare(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create snapshot share", err)
+	}
+
+	return response.JSON(http.StatusOK, snapshotShareDTO(share))
+}
+
+// swagger:route GET /snapshots/{key}/shares snapshots getDashboardSnapshotShares
+//
+// List the share links of a snapshot.
+//
+// Responses:
+// 200: getDashboardSnapshotSharesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardSnapshotShares(c *contextmodel.ReqContext) response.Response {
+	snapshot, errResp := hs.getManagedSnapshot(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	shares, err := hs.dashboardsnapshotsService.GetDashboardSnapshotShares(c.Req.Context(), &dashboardsnapshots.GetDashboardSnapshotSharesQuery{SnapshotID: snapshot.ID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get snapshot shares", err)
+	}
+
+	dto := make([]*dashboardsnapshots.DashboardSnapshotShareDTO, len(shares))
+	for i, share := range shares {
+		dto[i] = snapshotShareDTO(share)
+	}
+
+	return response.JSON(http.StatusOK, dto)
+}
+
+// swagger:route DELETE /snapshots/{key}/shares/{shareKey} snapshots deleteDashboardSnapshotShare
+//
+// Revoke a share link of a snapshot.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteDashboardSnapshotShare(c *contextmodel.ReqContext) response.Response {
+	snapshot, errResp := hs.getManagedSnapshot(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	err := hs.dashboardsnapshotsService.DeleteDashboardSnapshotShare(c.Req.Context(), &dashboardsnapshots.DeleteDashboardSnapshotShareCommand{
+		Key:        web.Params(c.Req)[":shareKey"],
+		SnapshotID: snapshot.ID,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete snapshot share", err)
+	}
+
+	return response.Success("Snapshot share deleted")
+}
+
+// swagger:route GET /snapshot-shares/{shareKey} snapshots getDashboardSnapshotByShare
+//
+// Get Snapshot by share link.
+//
+// The password of protected share links is sent in the X-Grafana-Snapshot-Password header. Each
+// successful request counts as a view of the share link.
+//
+// Responses:
+// 200: getDashboardSnapshotResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardSnapshotByShare(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	shareKey := web.Params(c.Req)[":shareKey"]
+	// Wrong passwords are recorded like failed logins, for the share link and the address of
+	// the client, so that the password of a share link can't be guessed.
+	attemptKey := fmt.Sprintf("snapshot-share:%s:%s", shareKey, c.RemoteAddr())
+	ok, err := hs.loginAttemptService.Validate(c.Req.Context(), attemptKey)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to check snapshot share password attempts", err)
+	}
+	if !ok {
+		return response.Error(http.StatusTooManyRequests, "Too many incorrect snapshot share passwords, try again later", nil)
+	}
+
+	snapshot, err := hs.dashboardsnapshotsService.ViewDashboardSnapshotShare(c.Req.Context(), &dashboardsnapshots.ViewDashboardSnapshotShareCommand{
+		Key:      shareKey,
+		Password: c.Req.Header.Get("X-Grafana-Snapshot-Password"),
+	})
+	if err != nil {
+		if errors.Is(err, dashboardsnapshots.ErrShareInvalidPassword) {
+			if err := hs.loginAttemptService.Add(c.Req.Context(), attemptKey, c.RemoteAddr()); err != nil {
+				hs.log.Error("Failed to record snapshot share password attempt", "error", err)
+			}
+		}
+		return response.Err(err)
+	}
+	if err := hs.loginAttemptService.Reset(c.Req.Context(), attemptKey); err != nil {
+		hs.log.Error("Failed to reset snapshot share password attempts", "error", err)
+	}
+
+	metrics.MApiDashboardSnapshotGet.Inc()
+
+	// Views are counted, so responses must not be cached
+	return response.JSON(http.StatusOK, snapshotDTO(snapshot)).SetHeader("Cache-Control", "private, no-store")
+}
+
 // swagger:parameters createDashboardSnapshot
 type CreateSnapshotParams struct {
 	// in:body
@@ -343,6 +558,49 @@ type DeleteSnapshotByDeleteKeyParams struct {
 	DeleteKey string `json:"deleteKey"`
 }
 
+// swagger:parameters createDashboardSnapshotShare
+type CreateSnapshotShareParams struct {
+	// in:path
+	Key string `json:"key"`
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CreateDashboardSnapshotShareCommand `json:"body"`
+}
+
+// swagger:parameters getDashboardSnapshotShares
+type GetSnapshotSharesParams struct {
+	// in:path
+	Key string `json:"key"`
+}
+
+// swagger:parameters deleteDashboardSnapshotShare
+type DeleteSnapshotShareParams struct {
+	// in:path
+	Key string `json:"key"`
+	// in:path
+	ShareKey string `json:"shareKey"`
+}
+
+// swagger:parameters getDashboardSnapshotByShare
+type GetSnapshotByShareParams struct {
+	// in:path
+	ShareKey string `json:"shareKey"`
+	// in:header
+	Password string `json:"X-Grafana-Snapshot-Password"`
+}
+
+// swagger:response createDashboardSnapshotShareResponse
+type CreateSnapshotShareResponse struct {
+	// in:body
+	Body dashboardsnapshots.DashboardSnapshotShareDTO `json:"body"`
+}
+
+// swagger:response getDashboardSnapshotSharesResponse
+type GetSnapshotSharesResponse struct {
+	// in:body
+	Body []*dashboardsnapshots.DashboardSnapshotShareDTO `json:"body"`
+}
+
 // swagger:response createDashboardSnapshotResponse
 type CreateSnapshotResponse struct {
 	// in:body
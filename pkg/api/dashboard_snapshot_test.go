@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
@@ -372,6 +373,60 @@ func TestGetDashboardSnapshotFailure(t *testing.T) {
 		}, sqlmock)
 }
 
+func TestGetDashboardSnapshotByShare(t *testing.T) {
+	sqlmock := dbtest.NewFakeDB()
+
+	setUpShareTest := func(t *testing.T, err error) dashboardsnapshots.Service {
+		t.Helper()
+
+		dashSnapSvc := dashboardsnapshots.NewMockService(t)
+		dashSnapSvc.
+			On("ViewDashboardSnapshotShare", mock.Anything, mock.AnythingOfType("*dashboardsnapshots.ViewDashboardSnapshotShareCommand")).
+			Return(&dashboardsnapshots.DashboardSnapshot{Dashboard: simplejson.New()}, err).Maybe()
+		return dashSnapSvc
+	}
+
+	loggedInUserScenarioWithRole(t,
+		"GET /snapshot-shares/{shareKey} should record incorrect passwords", "GET",
+		"/api/snapshot-shares/abc", "/api/snapshot-shares/:shareKey", org.RoleViewer, func(sc *scenarioContext) {
+			loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+			hs := buildHttpServer(setUpShareTest(t, dashboardsnapshots.ErrShareInvalidPassword.Errorf("")), true)
+			hs.loginAttemptService = loginAttempts
+			sc.handlerFunc = hs.GetDashboardSnapshotByShare
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"shareKey": "abc"}).exec()
+
+			assert.Equal(t, http.StatusUnauthorized, sc.resp.Code)
+			assert.True(t, loginAttempts.AddCalled)
+			assert.False(t, loginAttempts.ResetCalled)
+		}, sqlmock)
+
+	loggedInUserScenarioWithRole(t,
+		"GET /snapshot-shares/{shareKey} should reset the attempts with the correct password", "GET",
+		"/api/snapshot-shares/abc", "/api/snapshot-shares/:shareKey", org.RoleViewer, func(sc *scenarioContext) {
+			loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+			hs := buildHttpServer(setUpShareTest(t, nil), true)
+			hs.loginAttemptService = loginAttempts
+			sc.handlerFunc = hs.GetDashboardSnapshotByShare
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"shareKey": "abc"}).exec()
+
+			assert.Equal(t, http.StatusOK, sc.resp.Code)
+			assert.False(t, loginAttempts.AddCalled)
+			assert.True(t, loginAttempts.ResetCalled)
+		}, sqlmock)
+
+	loggedInUserScenarioWithRole(t,
+		"GET /snapshot-shares/{shareKey} should return 429 after too many incorrect passwords", "GET",
+		"/api/snapshot-shares/abc", "/api/snapshot-shares/:shareKey", org.RoleViewer, func(sc *scenarioContext) {
+			// The password is not checked at all once the attempts are exhausted
+			hs := buildHttpServer(dashboardsnapshots.NewMockService(t), true)
+			hs.loginAttemptService = &loginattempttest.MockLoginAttemptService{ExpectedValid: false}
+			sc.handlerFunc = hs.GetDashboardSnapshotByShare
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{"shareKey": "abc"}).exec()
+
+			assert.Equal(t, http.StatusTooManyRequests, sc.resp.Code)
+		}, sqlmock)
+}
+
 func buildHttpServer(d dashboardsnapshots.Service, snapshotEnabled bool) *HTTPServer {
 	hs := &HTTPServer{
 		dashboardsnapshotsService: d,
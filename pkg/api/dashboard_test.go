@@ -131,7 +131,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil)
 	require.NoError(t, err)
 	return gLive
 }
@@ -564,6 +564,56 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 		})
 	})
 
+	t.Run("Given two dashboards being semantically compared", func(t *testing.T) {
+		fakeDash := dashboards.NewDashboard("Dash")
+		fakeDash.ID = 1
+		fakeDash.UID = "dash"
+
+		fakeDashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+		fakeDashboardVersionService.ExpectedDashboardVersions = []*dashver.DashboardVersionDTO{
+			{
+				DashboardID: 1,
+				Version:     1,
+				Data: simplejson.NewFromAny(map[string]any{
+					"title": "Dash",
+				}),
+			},
+		}
+		cmd := dtos.SemanticDiffOptions{
+			Base: dtos.SemanticDiffTarget{
+				DashboardUID: "dash",
+				Version:      1,
+			},
+			New: dtos.SemanticDiffTarget{
+				UnsavedDashboard: simplejson.NewFromAny(map[string]any{
+					"title": "Dash changed",
+				}),
+			},
+		}
+
+		t.Run("when user cannot view the dashboard", func(t *testing.T) {
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+			semanticDiffScenario(t, "When calling POST on", "/api/dashboards/semantic-diff", "/api/dashboards/semantic-diff", cmd, dashSvc, fakeDashboardVersionService, func(sc *scenarioContext) {
+				guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+				callPostDashboard(sc)
+				assert.Equal(t, http.StatusForbidden, sc.resp.Code)
+			})
+		})
+
+		t.Run("when user can view but not save the dashboard", func(t *testing.T) {
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(fakeDash, nil)
+			semanticDiffScenario(t, "When calling POST on", "/api/dashboards/semantic-diff", "/api/dashboards/semantic-diff", cmd, dashSvc, fakeDashboardVersionService, func(sc *scenarioContext) {
+				guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true, CanSaveValue: false})
+
+				callPostDashboard(sc)
+				assert.Equal(t, http.StatusOK, sc.resp.Code, sc.resp.Body.String())
+			})
+		})
+	})
+
 	t.Run("Given dashboard in folder being restored should restore to folder", func(t *testing.T) {
 		fakeDash := dashboards.NewDashboard("Child dash")
 		fakeDash.ID = 2
@@ -975,6 +1025,38 @@ func postDiffScenario(t *testing.T, desc string, url string, routePattern string
 	})
 }
 
+func semanticDiffScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.SemanticDiffOptions,
+	dashSvc dashboards.DashboardService, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService, fn scenarioFunc,
+) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		hs := HTTPServer{
+			Cfg:                     setting.NewCfg(),
+			dashboardVersionService: fakeDashboardVersionService,
+			Features:                featuremgmt.WithFeatures(),
+			DashboardService:        dashSvc,
+			tracer:                  tracing.InitializeTracerForTest(),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{
+				OrgID:   testOrgID,
+				UserID:  testUserID,
+				OrgRole: org.RoleViewer,
+			}
+
+			return hs.CalculateSemanticDashboardDiff(c)
+		})
+
+		sc.m.Post(routePattern, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func restoreDashboardVersionScenario(t *testing.T, desc string, url string, routePattern string,
 	mock *dashboards.FakeDashboardService, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService,
 	cmd dtos.RestoreDashboardVersionCommand, fn scenarioFunc, sqlStore db.DB,
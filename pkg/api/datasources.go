@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -180,6 +181,9 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
 
+	auditlog.SetResource(c.Req.Context(), "datasources", ds.UID)
+	auditlog.SetBefore(c.Req.Context(), ds)
+
 	cmd := &datasources.DeleteDataSourceCommand{ID: id, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -259,6 +263,8 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
 
+	auditlog.SetBefore(c.Req.Context(), ds)
+
 	cmd := &datasources.DeleteDataSourceCommand{UID: uid, OrgID: c.SignedInUser.GetOrgID(), Name: ds.Name}
 
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
@@ -310,6 +316,9 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusForbidden, "Cannot delete read-only data source", nil)
 	}
 
+	auditlog.SetResource(c.Req.Context(), "datasources", dataSource.UID)
+	auditlog.SetBefore(c.Req.Context(), dataSource)
+
 	cmd := &datasources.DeleteDataSourceCommand{Name: name, OrgID: c.SignedInUser.GetOrgID()}
 	err = hs.DataSourcesService.DeleteDataSource(c.Req.Context(), cmd)
 	if err != nil {
@@ -364,6 +373,27 @@ func validateJSONData(ctx context.Context, jsonData *simplejson.Json, cfg *setti
 		}
 	}
 
+	return validateQueryPoliciesJSON(jsonData)
+}
+
+func validateQueryPoliciesJSON(jsonData *simplejson.Json) error {
+	queryPolicies, err := datasources.GetQueryPolicies(jsonData)
+	if err != nil {
+		datasourcesLogger.Error("Invalid query policies", "error", err)
+		return fmt.Errorf("validation error, invalid query policies: %w", err)
+	}
+	if queryPolicies == nil {
+		return nil
+	}
+	for _, policy := range queryPolicies.Policies {
+		if policy.LabelSelector == "" {
+			continue
+		}
+		if _, err := parser.ParseMetricSelector(policy.LabelSelector); err != nil {
+			datasourcesLogger.Error("Cannot add a query policy with an invalid label selector", "labelSelector", policy.LabelSelector)
+			return errors.New("validation error, invalid label selector syntax in query policy")
+		}
+	}
 	return nil
 }
 
@@ -470,6 +500,9 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to add datasource", err)
 	}
 
+	auditlog.SetResource(c.Req.Context(), "datasources", dataSource.UID)
+	auditlog.SetAfter(c.Req.Context(), dataSource)
+
 	// Clear permission cache for the user who's created the data source, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
@@ -529,8 +562,8 @@ func (hs *HTTPServer) UpdateDataSourceByID(c *contextmodel.ReqContext) response.
 		return response.Error(http.StatusInternalServerError, "Failed to update datasource", err)
 	}
 
-	// check if LBAC rules have been modified
-	hasAccess, errAccess := checkTeamHTTPHeaderPermissions(hs, c, ds, cmd)
+	// check if LBAC rules or query policies have been modified
+	hasAccess, errAccess := checkAccessRulesPermissions(hs, c, ds, cmd)
 	if !hasAccess {
 		return response.Error(http.StatusForbidden, fmt.Sprintf("You'll need additional permissions to perform this action. Permissions needed: %s", datasources.ActionPermissionsWrite), errAccess)
 	}
@@ -577,8 +610,8 @@ func (hs *HTTPServer) UpdateDataSourceByUID(c *contextmodel.ReqContext) response
 	}
 	cmd.ID = ds.ID
 
-	// check if LBAC rules have been modified
-	hasAccess, errAccess := checkTeamHTTPHeaderPermissions(hs, c, ds, cmd)
+	// check if LBAC rules or query policies have been modified
+	hasAccess, errAccess := checkAccessRulesPermissions(hs, c, ds, cmd)
 	if !hasAccess {
 		return response.Error(http.StatusForbidden, fmt.Sprintf("You'll need additional permissions to perform this action. Permissions needed: %s", datasources.ActionPermissionsWrite), errAccess)
 	}
@@ -598,11 +631,15 @@ func getEncodedString(jsonData *simplejson.Json, key string) string {
 	return string(val)
 }
 
-func checkTeamHTTPHeaderPermissions(hs *HTTPServer, c *contextmodel.ReqContext, ds *datasources.DataSource, cmd datasources.UpdateDataSourceCommand) (bool, error) {
-	currentTeamHTTPHeaders := getEncodedString(ds.JsonData, "teamHttpHeaders")
-	newTeamHTTPHeaders := getEncodedString(cmd.JsonData, "teamHttpHeaders")
-	if (currentTeamHTTPHeaders != "" || newTeamHTTPHeaders != "") && currentTeamHTTPHeaders != newTeamHTTPHeaders {
-		return evaluateTeamHTTPHeaderPermissions(hs, c, datasources.ScopePrefix+ds.UID)
+// checkAccessRulesPermissions checks that the user can change the team HTTP headers and the
+// query policies of the data source, if the update changes them.
+func checkAccessRulesPermissions(hs *HTTPServer, c *contextmodel.ReqContext, ds *datasources.DataSource, cmd datasources.UpdateDataSourceCommand) (bool, error) {
+	for _, key := range []string{"teamHttpHeaders", "queryPolicies"} {
+		current := getEncodedString(ds.JsonData, key)
+		updated := getEncodedString(cmd.JsonData, key)
+		if (current != "" || updated != "") && current != updated {
+			return evaluateTeamHTTPHeaderPermissions(hs, c, datasources.ScopePrefix+ds.UID)
+		}
 	}
 	return true, nil
 }
@@ -642,6 +679,10 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 		return response.Error(http.StatusInternalServerError, "Failed to query datasource", err)
 	}
 
+	auditlog.SetResource(c.Req.Context(), "datasources", dataSource.UID)
+	auditlog.SetBefore(c.Req.Context(), ds)
+	auditlog.SetAfter(c.Req.Context(), dataSource)
+
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), dataSource)
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
@@ -522,6 +522,45 @@ func TestValidateLBACHeader(t *testing.T) {
 	}
 }
 
+func TestValidateQueryPoliciesJSON(t *testing.T) {
+	testcases := []struct {
+		desc     string
+		jsonData string
+		wantErr  bool
+	}{
+		{
+			desc:     "Should allow valid policies",
+			jsonData: `{"queryPolicies": {"policies": [{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"}, {"role": "Admin"}]}}`,
+		},
+		{
+			desc:     "Should allow json data without policies",
+			jsonData: `{"foo": "bar"}`,
+		},
+		{
+			desc:     "Should return error for invalid label selector",
+			jsonData: `{"queryPolicies": {"policies": [{"teamId": 1, "labelSelector": "tenant=\"team-a\""}]}}`,
+			wantErr:  true,
+		},
+		{
+			desc:     "Should return error for policy without team or role",
+			jsonData: `{"queryPolicies": {"policies": [{"allowedTables": ["orders"]}]}}`,
+			wantErr:  true,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.desc, func(t *testing.T) {
+			jsonData, err := simplejson.NewJson([]byte(tc.jsonData))
+			require.NoError(t, err)
+			err = validateQueryPoliciesJSON(jsonData)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 type dataSourcesServiceMock struct {
 	datasources.DataSourceService
 
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
@@ -17,6 +19,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -76,13 +79,37 @@ func (hs *HTTPServer) QueryMetricsV2(c *contextmodel.ReqContext) response.Respon
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	start := time.Now()
 	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, reqDTO)
+	hs.recordDashboardQuery(c, time.Since(start), err != nil || hasResponseError(resp))
 	if err != nil {
 		return hs.handleQueryMetricsError(err)
 	}
 	return hs.toJsonStreamingResponse(c.Req.Context(), resp)
 }
 
+// recordDashboardQuery records the query in the usage of the dashboard and panel that sent it.
+func (hs *HTTPServer) recordDashboardQuery(c *contextmodel.ReqContext, duration time.Duration, failed bool) {
+	dashboardUID := c.Req.Header.Get(query.HeaderDashboardUID)
+	if hs.dashboardUsageService == nil || dashboardUID == "" {
+		return
+	}
+	panelID, _ := strconv.ParseInt(c.Req.Header.Get(query.HeaderPanelID), 10, 64)
+	hs.dashboardUsageService.RecordQuery(c.Req.Context(), c.SignedInUser, dashboardUID, panelID, duration, failed)
+}
+
+func hasResponseError(qdr *backend.QueryDataResponse) bool {
+	if qdr == nil {
+		return false
+	}
+	for _, res := range qdr.Responses {
+		if res.Error != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (hs *HTTPServer) toJsonStreamingResponse(ctx context.Context, qdr *backend.QueryDataResponse) response.Response {
 	statusCode := http.StatusOK
 	for _, res := range qdr.Responses {
@@ -5,6 +5,7 @@ import (
 
 	dashboardsV0 "github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
 )
 
 type DashboardMeta struct {
@@ -33,6 +34,8 @@ type DashboardMeta struct {
 	FolderUrl              string                             `json:"folderUrl"`
 	Provisioned            bool                               `json:"provisioned"`
 	ProvisionedExternalId  string                             `json:"provisionedExternalId"`
+	ProvisionedCommitSha   string                             `json:"provisionedCommitSha,omitempty"`
+	ProvisionedPush        *gitsource.PushStatus              `json:"provisionedPush,omitempty"`
 	AnnotationsPermissions *dashboardsV0.AnnotationPermission `json:"annotationsPermissions"`
 	PublicDashboardEnabled bool                               `json:"publicDashboardEnabled,omitempty"`
 }
@@ -58,6 +61,26 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+type SemanticDiffOptions struct {
+	Base SemanticDiffTarget `json:"base" binding:"Required"`
+	New  SemanticDiffTarget `json:"new" binding:"Required"`
+}
+
+// SemanticDiffTarget is a version of a dashboard, the current version if version is not set,
+// or an unsaved dashboard.
+type SemanticDiffTarget struct {
+	DashboardUID     string           `json:"dashboardUid"`
+	DashboardId      int64            `json:"dashboardId"`
+	Version          int              `json:"version"`
+	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
+}
+
+// LintDashboardCommand lints the dashboard JSON model, or the saved dashboard with the UID.
+type LintDashboardCommand struct {
+	Dashboard    *simplejson.Json `json:"dashboard"`
+	DashboardUID string           `json:"dashboardUid"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
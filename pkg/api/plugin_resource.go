@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/httpresponsesender"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/util/proxyutil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -52,6 +53,12 @@ func (hs *HTTPServer) callPluginResource(c *contextmodel.ReqContext, pluginID st
 }
 
 func (hs *HTTPServer) callPluginResourceWithDataSource(c *contextmodel.ReqContext, pluginID string, ds *datasources.DataSource) {
+	// The query policies of the data source can't be applied to resource calls.
+	if query.RestrictedByQueryPolicies(c.SignedInUser, ds) {
+		c.JsonApiErr(http.StatusForbidden, "Access to the data source resources is not allowed by the query policies of the data source", nil)
+		return
+	}
+
 	pCtx, err := hs.pluginContextProvider.GetWithDataSource(c.Req.Context(), pluginID, c.SignedInUser, ds)
 	if err != nil {
 		if errors.Is(err, plugins.ErrPluginNotRegistered) {
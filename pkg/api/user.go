@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -266,16 +267,33 @@ func (hs *HTTPServer) handleUpdateUser(ctx context.Context, cmd user.UpdateUserC
 		}
 	}
 
+	hs.auditUser(ctx, cmd.UserID, auditlog.SetBefore)
 	if err := hs.userService.Update(ctx, &cmd); err != nil {
 		if errors.Is(err, user.ErrCaseInsensitive) {
 			return response.Error(http.StatusConflict, "Update would result in user login conflict", err)
 		}
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update user", err)
 	}
+	hs.auditUser(ctx, cmd.UserID, auditlog.SetAfter)
 
 	return response.Success("User updated")
 }
 
+// auditUser records the hash of the profile of the user in the audit log event of the request,
+// before or after the change made by the request. The profile doesn't contain the credentials of
+// the user.
+func (hs *HTTPServer) auditUser(ctx context.Context, userID int64, set func(context.Context, any)) {
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	profile, err := hs.userService.GetProfile(ctx, &user.GetUserProfileQuery{UserID: userID})
+	if err != nil {
+		return
+	}
+	auditlog.SetResource(ctx, "users", strconv.FormatInt(userID, 10))
+	set(ctx, profile)
+}
+
 func (hs *HTTPServer) StartEmailVerificaton(c *contextmodel.ReqContext) response.Response {
 	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
 		return response.Error(http.StatusBadRequest, "Only users can verify their email", nil)
@@ -74,6 +74,31 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /user/revoke-other-auth-tokens signed_in_user revokeOtherUserAuthTokens
+//
+// Revoke all other auth tokens of the actual User.
+//
+// Revokes all auth tokens (devices) of the actual user except the one of the current session. Users of the other devices will be required to authenticate again upon next activity.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeOtherUserAuthTokens(c *contextmodel.ReqContext) response.Response {
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	return hs.revokeOtherUserAuthTokensInternal(c, userID)
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -226,6 +251,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 			OperatingSystemVersion: osVersion,
 			Browser:                client.UserAgent.Family,
 			BrowserVersion:         browserVersion,
+			DeviceFingerprint:      auth.DeviceFingerprint(token.UserAgent, token.ClientIp),
 			CreatedAt:              createdAt,
 			SeenAt:                 seenAt,
 		})
@@ -269,6 +295,33 @@ func (hs *HTTPServer) revokeUserAuthTokenInternal(c *contextmodel.ReqContext, us
 	})
 }
 
+func (hs *HTTPServer) revokeOtherUserAuthTokensInternal(c *contextmodel.ReqContext, userID int64) response.Response {
+	if c.UserToken == nil {
+		return response.Error(http.StatusBadRequest, "Other auth tokens can only be revoked from a session", nil)
+	}
+
+	tokens, err := hs.AuthTokenService.GetUserTokens(c.Req.Context(), userID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get user auth tokens", err)
+	}
+
+	revoked := 0
+	for _, token := range tokens {
+		if token.Id == c.UserToken.Id {
+			continue
+		}
+		if err := hs.AuthTokenService.RevokeToken(c.Req.Context(), token, false); err != nil && !errors.Is(err, auth.ErrUserTokenNotFound) {
+			return response.Error(http.StatusInternalServerError, "Failed to revoke user auth token", err)
+		}
+		revoked++
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Other user auth tokens revoked",
+		"count":   revoked,
+	})
+}
+
 // swagger:parameters revokeUserAuthToken
 type RevokeUserAuthTokenParams struct {
 	// in:body
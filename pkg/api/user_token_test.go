@@ -92,6 +92,29 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 		}, mockUser)
 	})
 
+	t.Run("When revoking the other auth tokens of a user", func(t *testing.T) {
+		token := &auth.UserToken{Id: 2}
+		revokeOtherUserAuthTokensInternalScenario(t, "Should revoke all tokens except the active one", token, func(sc *scenarioContext) {
+			sc.userAuthTokenService.GetUserTokensProvider = func(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+				return []*auth.UserToken{{Id: 1}, {Id: 2}, {Id: 3}}, nil
+			}
+			var revoked []int64
+			sc.userAuthTokenService.RevokeTokenProvider = func(ctx context.Context, token *auth.UserToken, soft bool) error {
+				revoked = append(revoked, token.Id)
+				return nil
+			}
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 200, sc.resp.Code)
+			assert.Equal(t, []int64{1, 3}, revoked)
+			assert.Equal(t, 2, sc.ToJSON().Get("count").MustInt())
+		})
+
+		revokeOtherUserAuthTokensInternalScenario(t, "Should not be successful without a session", nil, func(sc *scenarioContext) {
+			sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+			assert.Equal(t, 400, sc.resp.Code)
+		})
+	})
+
 	t.Run("When gets auth tokens for a user", func(t *testing.T) {
 		currentToken := &auth.UserToken{Id: 1}
 		mockUser := usertest.NewUserServiceFake()
@@ -146,6 +169,8 @@ func TestUserTokenAPIEndpoint(t *testing.T) {
 			assert.Equal(t, "11.0", resultTwo.Get("browserVersion").MustString())
 			assert.Equal(t, "iOS", resultTwo.Get("os").MustString())
 			assert.Equal(t, "11.0", resultTwo.Get("osVersion").MustString())
+
+			assert.NotEqual(t, resultOne.Get("deviceFingerprint").MustString(), resultTwo.Get("deviceFingerprint").MustString())
 		}, mockUser)
 	})
 }
@@ -343,6 +368,30 @@ func revokeUserAuthTokenInternalScenario(t *testing.T, desc string, cmd auth.Rev
 	})
 }
 
+func revokeOtherUserAuthTokensInternalScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc) {
+	t.Run(desc, func(t *testing.T) {
+		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
+
+		hs := HTTPServer{
+			AuthTokenService: fakeAuthTokenService,
+		}
+
+		sc := setupScenarioContext(t, "/")
+		sc.userAuthTokenService = fakeAuthTokenService
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			sc.context = c
+			sc.context.UserID = testUserID
+			sc.context.OrgID = testOrgID
+			sc.context.OrgRole = org.RoleAdmin
+			sc.context.UserToken = token
+
+			return hs.revokeOtherUserAuthTokensInternal(c, testUserID)
+		})
+		sc.m.Post("/", sc.defaultHandler)
+		fn(sc)
+	})
+}
+
 func getUserAuthTokensInternalScenario(t *testing.T, desc string, token *auth.UserToken, fn scenarioFunc, userService user.Service) {
 	t.Run(desc, func(t *testing.T) {
 		fakeAuthTokenService := authtest.NewFakeUserAuthTokenService()
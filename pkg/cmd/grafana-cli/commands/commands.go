@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -164,6 +165,12 @@ var adminCommands = []*cli.Command{
 				Name:   "re-encrypt-data-keys",
 				Usage:  "Rotates persisted data encryption keys. Returns ok unless there is an error. Safe to execute multiple times.",
 				Action: runRunnerCommand(secretsmigrations.ReEncryptDEKS),
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "provider",
+						Usage: "Re-encrypt all data keys with this encryption provider instead of the current one, and fail if any data key could not be re-encrypted",
+					},
+				},
 			},
 		},
 	},
@@ -226,6 +233,35 @@ grafana-cli user-manager conflicts ingest-file <filepath>
 	},
 }
 
+var dashboardCommands = []*cli.Command{
+	{
+		Name:      "lint",
+		Usage:     "check dashboard JSON files against best practices",
+		ArgsUsage: "<file or directory>...",
+		Action:    runPluginCommand(lintDashboardsCommand),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "max-panels",
+				Usage: "Maximum number of panels of a dashboard",
+				Value: lint.DefaultMaxPanels,
+			},
+			&cli.StringFlag{
+				Name:  "min-refresh-interval",
+				Usage: "Minimum refresh interval of a dashboard",
+				Value: lint.DefaultMinRefreshInterval.String(),
+			},
+			&cli.StringSliceFlag{
+				Name:  "disable",
+				Usage: "Rules to disable, e.g. missing-description",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Fail on warnings",
+			},
+		},
+	},
+}
+
 var Commands = []*cli.Command{
 	{
 		Name:        "plugins",
@@ -237,4 +273,9 @@ var Commands = []*cli.Command{
 		Usage:       "Grafana admin commands",
 		Subcommands: adminCommands,
 	},
+	{
+		Name:        "dashboards",
+		Usage:       "Dashboard commands",
+		Subcommands: dashboardCommands,
+	},
 }
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards/lint"
+)
+
+var errLintProblems = errors.New("dashboards have lint errors")
+
+// lintDashboardsCommand lints dashboard JSON files, e.g. provisioning files before they are
+// imported. Directories are walked for .json files. It fails if a problem has the error
+// severity, or any problem if --strict is set.
+func lintDashboardsCommand(c utils.CommandLine) error {
+	if c.Args().Len() == 0 {
+		return errors.New("please specify the dashboard files or directories to lint")
+	}
+
+	settings := lint.Settings{MaxPanels: c.Int("max-panels")}
+	if interval := c.String("min-refresh-interval"); interval != "" {
+		d, err := gtime.ParseDuration(interval)
+		if err != nil {
+			return fmt.Errorf("invalid min refresh interval %q: %w", interval, err)
+		}
+		settings.MinRefreshInterval = d
+	}
+	linter := lint.New(lint.DefaultRules(settings)...)
+	for _, disabled := range c.StringSlice("disable") {
+		linter.Disable(strings.Split(disabled, ",")...)
+	}
+
+	files, err := dashboardFiles(c.Args().Slice())
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, file := range files {
+		// #nosec G304 - the files are given on the command line
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		data, err := simplejson.NewJson(content)
+		if err != nil {
+			logger.Errorf("%s: %s invalid JSON: %v\n", file, color.RedString("error"), err)
+			failed = true
+			continue
+		}
+		for _, p := range linter.Lint(data).Problems {
+			severity := color.YellowString(string(p.Severity))
+			if p.Severity == lint.SeverityError || c.Bool("strict") {
+				severity = color.RedString(string(p.Severity))
+				failed = true
+			}
+			logger.Infof("%s: %s %s: %s%s\n", file, severity, p.Rule, p.Message, problemLocation(p))
+		}
+	}
+
+	if failed {
+		return errLintProblems
+	}
+	logger.Infof("%d dashboards linted %s\n", len(files), color.GreenString("OK"))
+	return nil
+}
+
+func dashboardFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p == path && !d.IsDir() {
+				files = append(files, p)
+				return nil
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func problemLocation(p lint.Problem) string {
+	var location []string
+	if p.PanelTitle != "" || p.PanelID != 0 {
+		location = append(location, fmt.Sprintf("panel %q (id %d)", p.PanelTitle, p.PanelID))
+	}
+	if p.RefID != "" {
+		location = append(location, "query "+p.RefID)
+	}
+	if p.Variable != "" {
+		location = append(location, fmt.Sprintf("variable %q", p.Variable))
+	}
+	if len(location) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(location, ", ") + "]"
+}
@@ -5,9 +5,13 @@ import (
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
-func ReEncryptDEKS(_ utils.CommandLine, runner server.Runner) error {
+func ReEncryptDEKS(c utils.CommandLine, runner server.Runner) error {
+	if provider := c.String("provider"); provider != "" {
+		return runner.SecretsService.ReEncryptDataKeysToProvider(context.Background(), secrets.ProviderID(provider))
+	}
 	return runner.SecretsService.ReEncryptDataKeys(context.Background())
 }
 
@@ -0,0 +1,524 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type ChangeTarget string
+
+const (
+	ChangeTargetDashboard  ChangeTarget = "dashboard"
+	ChangeTargetPanel      ChangeTarget = "panel"
+	ChangeTargetQuery      ChangeTarget = "query"
+	ChangeTargetVariable   ChangeTarget = "variable"
+	ChangeTargetDatasource ChangeTarget = "datasource"
+)
+
+type SemanticChangeType string
+
+const (
+	SemanticChangeAdded    SemanticChangeType = "added"
+	SemanticChangeRemoved  SemanticChangeType = "removed"
+	SemanticChangeModified SemanticChangeType = "modified"
+	SemanticChangeMoved    SemanticChangeType = "moved"
+)
+
+// SemanticChange is a change of a dashboard element, e.g. a panel that was added or a query that was modified.
+type SemanticChange struct {
+	Target ChangeTarget       `json:"target"`
+	Type   SemanticChangeType `json:"type"`
+	// Property is the changed property of the element, e.g. gridPos for a moved panel.
+	Property   string `json:"property,omitempty"`
+	PanelID    int64  `json:"panelId,omitempty"`
+	PanelTitle string `json:"panelTitle,omitempty"`
+	RefID      string `json:"refId,omitempty"`
+	Variable   string `json:"variable,omitempty"`
+	Before     any    `json:"before,omitempty"`
+	After      any    `json:"after,omitempty"`
+	// Description is a human readable description of the change.
+	Description string `json:"description"`
+}
+
+// SemanticDiff is the list of changes between two dashboards.
+type SemanticDiff struct {
+	Changes []SemanticChange `json:"changes"`
+	// Summary counts the changes by target and type, e.g. "1 panel added, 2 queries modified".
+	Summary string `json:"summary"`
+}
+
+// dashboardProperties are the top level dashboard properties compared as a whole.
+var dashboardProperties = []string{"title", "description", "tags", "timezone", "refresh", "time", "graphTooltip", "editable", "links", "annotations"}
+
+// panelIgnoredProperties are compared separately or not relevant for a review.
+var panelIgnoredProperties = map[string]bool{
+	"id": true, "gridPos": true, "targets": true, "datasource": true, "panels": true, "collapsed": true, "pluginVersion": true,
+}
+
+// variableIgnoredProperties change at runtime without a change of the variable definition.
+var variableIgnoredProperties = map[string]bool{
+	"current": true, "options": true, "datasource": true,
+}
+
+// CalculateSemanticDiff returns the changes of panels, queries, variables and datasources between two
+// dashboards. The dashboards can be two versions of the same dashboard, or two different dashboards.
+func CalculateSemanticDiff(baseData, newData *simplejson.Json) *SemanticDiff {
+	base := baseData.MustMap()
+	changed := newData.MustMap()
+
+	d := &SemanticDiff{Changes: []SemanticChange{}}
+	for _, prop := range dashboardProperties {
+		if before, after := base[prop], changed[prop]; !jsonEqual(before, after) {
+			d.add(SemanticChange{
+				Target:      ChangeTargetDashboard,
+				Type:        SemanticChangeModified,
+				Property:    prop,
+				Before:      before,
+				After:       after,
+				Description: fmt.Sprintf("Dashboard %s changed", prop),
+			})
+		}
+	}
+	d.diffPanels(flattenPanels(base), flattenPanels(changed))
+	baseOrder, baseVariables := variables(base)
+	changedOrder, changedVariables := variables(changed)
+	d.diffVariables(baseOrder, baseVariables, changedOrder, changedVariables)
+	d.Summary = d.summary()
+	return d
+}
+
+func (d *SemanticDiff) add(c SemanticChange) {
+	d.Changes = append(d.Changes, c)
+}
+
+type panelInfo struct {
+	id    int64
+	title string
+	typ   string
+	// row is the title of the row the panel is in.
+	row     string
+	raw     map[string]any
+	matched bool
+}
+
+// flattenPanels returns the panels of the dashboard, including the panels of collapsed rows.
+// Panels of expanded rows follow their row panel in the panels of the dashboard.
+func flattenPanels(dash map[string]any) []*panelInfo {
+	var result []*panelInfo
+	var walk func(panels []any, row string)
+	walk = func(panels []any, row string) {
+		for _, p := range panels {
+			raw, ok := p.(map[string]any)
+			if !ok {
+				continue
+			}
+			panel := &panelInfo{
+				id:    toInt64(raw["id"]),
+				title: toString(raw["title"]),
+				typ:   toString(raw["type"]),
+				row:   row,
+				raw:   raw,
+			}
+			if panel.typ == "row" {
+				// A row ends the previous row
+				panel.row = ""
+				row = panel.title
+			}
+			result = append(result, panel)
+			if nested, ok := raw["panels"].([]any); ok {
+				walk(nested, panel.title)
+			}
+		}
+	}
+	panels, _ := dash["panels"].([]any)
+	walk(panels, "")
+	// Dashboards of schema version < 16 define panels inside rows.
+	if rows, ok := dash["rows"].([]any); ok {
+		for _, r := range rows {
+			if row, ok := r.(map[string]any); ok {
+				nested, _ := row["panels"].([]any)
+				walk(nested, toString(row["title"]))
+			}
+		}
+	}
+	return result
+}
+
+func (d *SemanticDiff) diffPanels(base, changed []*panelInfo) {
+	// Panels are matched by id first, then by title and type, since ids of different dashboards may differ.
+	baseByID := map[int64]*panelInfo{}
+	for _, p := range base {
+		if p.id != 0 {
+			baseByID[p.id] = p
+		}
+	}
+	pairs := map[*panelInfo]*panelInfo{}
+	for _, p := range changed {
+		if b, ok := baseByID[p.id]; ok && p.id != 0 && !b.matched && (b.typ == p.typ || b.title == p.title) {
+			b.matched, p.matched = true, true
+			pairs[p] = b
+		}
+	}
+	for _, p := range changed {
+		if p.matched {
+			continue
+		}
+		for _, b := range base {
+			if !b.matched && b.title == p.title && b.typ == p.typ {
+				b.matched, p.matched = true, true
+				pairs[p] = b
+				break
+			}
+		}
+	}
+
+	for _, b := range base {
+		if !b.matched {
+			d.add(SemanticChange{
+				Target:      ChangeTargetPanel,
+				Type:        SemanticChangeRemoved,
+				PanelID:     b.id,
+				PanelTitle:  b.title,
+				Before:      b.raw,
+				Description: fmt.Sprintf("Panel %s removed", panelName(b)),
+			})
+		}
+	}
+	for _, p := range changed {
+		b, ok := pairs[p]
+		if !ok {
+			d.add(SemanticChange{
+				Target:      ChangeTargetPanel,
+				Type:        SemanticChangeAdded,
+				PanelID:     p.id,
+				PanelTitle:  p.title,
+				After:       p.raw,
+				Description: fmt.Sprintf("Panel %s added", panelName(p)),
+			})
+			continue
+		}
+		d.diffPanel(b, p)
+	}
+}
+
+func (d *SemanticDiff) diffPanel(base, changed *panelInfo) {
+	name := panelName(changed)
+	panelChange := func(c SemanticChange) {
+		c.PanelID = changed.id
+		c.PanelTitle = changed.title
+		d.add(c)
+	}
+
+	if base.row != changed.row {
+		panelChange(SemanticChange{
+			Target:      ChangeTargetPanel,
+			Type:        SemanticChangeMoved,
+			Property:    "row",
+			Before:      base.row,
+			After:       changed.row,
+			Description: fmt.Sprintf("Panel %s moved from row %q to row %q", name, base.row, changed.row),
+		})
+	} else if !jsonEqual(base.raw["gridPos"], changed.raw["gridPos"]) {
+		panelChange(SemanticChange{
+			Target:      ChangeTargetPanel,
+			Type:        SemanticChangeMoved,
+			Property:    "gridPos",
+			Before:      base.raw["gridPos"],
+			After:       changed.raw["gridPos"],
+			Description: fmt.Sprintf("Panel %s moved or resized", name),
+		})
+	}
+
+	if before, after := base.raw["datasource"], changed.raw["datasource"]; !jsonEqual(before, after) {
+		panelChange(SemanticChange{
+			Target:      ChangeTargetDatasource,
+			Type:        SemanticChangeModified,
+			Before:      before,
+			After:       after,
+			Description: fmt.Sprintf("Datasource of panel %s changed from %s to %s", name, datasourceName(before), datasourceName(after)),
+		})
+	}
+
+	for _, prop := range changedProperties(base.raw, changed.raw, panelIgnoredProperties) {
+		panelChange(SemanticChange{
+			Target:      ChangeTargetPanel,
+			Type:        SemanticChangeModified,
+			Property:    prop,
+			Before:      base.raw[prop],
+			After:       changed.raw[prop],
+			Description: fmt.Sprintf("Panel %s %s changed", name, prop),
+		})
+	}
+
+	baseTargets, baseOrder := queriesByRefID(base.raw)
+	changedTargets, changedOrder := queriesByRefID(changed.raw)
+	for _, refID := range baseOrder {
+		if _, ok := changedTargets[refID]; !ok {
+			panelChange(SemanticChange{
+				Target:      ChangeTargetQuery,
+				Type:        SemanticChangeRemoved,
+				RefID:       refID,
+				Before:      baseTargets[refID],
+				Description: fmt.Sprintf("Query %s of panel %s removed", refID, name),
+			})
+		}
+	}
+	for _, refID := range changedOrder {
+		after := changedTargets[refID]
+		before, ok := baseTargets[refID]
+		if !ok {
+			panelChange(SemanticChange{
+				Target:      ChangeTargetQuery,
+				Type:        SemanticChangeAdded,
+				RefID:       refID,
+				After:       after,
+				Description: fmt.Sprintf("Query %s of panel %s added", refID, name),
+			})
+			continue
+		}
+		if !jsonEqual(before["datasource"], after["datasource"]) {
+			panelChange(SemanticChange{
+				Target:      ChangeTargetDatasource,
+				Type:        SemanticChangeModified,
+				RefID:       refID,
+				Before:      before["datasource"],
+				After:       after["datasource"],
+				Description: fmt.Sprintf("Datasource of query %s of panel %s changed from %s to %s", refID, name, datasourceName(before["datasource"]), datasourceName(after["datasource"])),
+			})
+		}
+		if props := changedProperties(before, after, map[string]bool{"datasource": true}); len(props) > 0 {
+			panelChange(SemanticChange{
+				Target:      ChangeTargetQuery,
+				Type:        SemanticChangeModified,
+				RefID:       refID,
+				Property:    strings.Join(props, ","),
+				Before:      before,
+				After:       after,
+				Description: fmt.Sprintf("Query %s of panel %s changed", refID, name),
+			})
+		}
+	}
+}
+
+// queriesByRefID returns the queries of a panel by ref id, and the ref ids in the panel order.
+func queriesByRefID(panel map[string]any) (map[string]map[string]any, []string) {
+	result := map[string]map[string]any{}
+	var order []string
+	targets, _ := panel["targets"].([]any)
+	for i, t := range targets {
+		target, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		refID := toString(target["refId"])
+		if refID == "" {
+			refID = "#" + strconv.Itoa(i)
+		}
+		if _, ok := result[refID]; ok {
+			continue
+		}
+		result[refID] = target
+		order = append(order, refID)
+	}
+	return result, order
+}
+
+func variables(dash map[string]any) ([]string, map[string]map[string]any) {
+	result := map[string]map[string]any{}
+	var order []string
+	templating, _ := dash["templating"].(map[string]any)
+	list, _ := templating["list"].([]any)
+	for _, v := range list {
+		variable, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := toString(variable["name"])
+		if _, ok := result[name]; ok {
+			continue
+		}
+		result[name] = variable
+		order = append(order, name)
+	}
+	return order, result
+}
+
+func (d *SemanticDiff) diffVariables(baseOrder []string, base map[string]map[string]any, changedOrder []string, changed map[string]map[string]any) {
+	for _, name := range baseOrder {
+		if _, ok := changed[name]; !ok {
+			d.add(SemanticChange{
+				Target:      ChangeTargetVariable,
+				Type:        SemanticChangeRemoved,
+				Variable:    name,
+				Before:      base[name],
+				Description: fmt.Sprintf("Variable %q removed", name),
+			})
+		}
+	}
+	for _, name := range changedOrder {
+		after := changed[name]
+		before, ok := base[name]
+		if !ok {
+			d.add(SemanticChange{
+				Target:      ChangeTargetVariable,
+				Type:        SemanticChangeAdded,
+				Variable:    name,
+				After:       after,
+				Description: fmt.Sprintf("Variable %q added", name),
+			})
+			continue
+		}
+		if !jsonEqual(before["datasource"], after["datasource"]) {
+			d.add(SemanticChange{
+				Target:      ChangeTargetDatasource,
+				Type:        SemanticChangeModified,
+				Variable:    name,
+				Before:      before["datasource"],
+				After:       after["datasource"],
+				Description: fmt.Sprintf("Datasource of variable %q changed from %s to %s", name, datasourceName(before["datasource"]), datasourceName(after["datasource"])),
+			})
+		}
+		for _, prop := range changedProperties(before, after, variableIgnoredProperties) {
+			d.add(SemanticChange{
+				Target:      ChangeTargetVariable,
+				Type:        SemanticChangeModified,
+				Variable:    name,
+				Property:    prop,
+				Before:      before[prop],
+				After:       after[prop],
+				Description: fmt.Sprintf("Variable %q %s changed", name, prop),
+			})
+		}
+	}
+}
+
+func (d *SemanticDiff) summary() string {
+	if len(d.Changes) == 0 {
+		return "No changes"
+	}
+	type key struct {
+		target ChangeTarget
+		typ    SemanticChangeType
+	}
+	counts := map[key]int{}
+	var keys []key
+	for _, c := range d.Changes {
+		k := key{c.Target, c.Type}
+		if counts[k] == 0 {
+			keys = append(keys, k)
+		}
+		counts[k]++
+	}
+	targetOrder := map[ChangeTarget]int{ChangeTargetDashboard: 0, ChangeTargetPanel: 1, ChangeTargetQuery: 2, ChangeTargetVariable: 3, ChangeTargetDatasource: 4}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return targetOrder[keys[i].target] < targetOrder[keys[j].target]
+	})
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		n := counts[k]
+		noun := string(k.target)
+		if n > 1 {
+			noun = pluralTarget(k.target)
+		}
+		if k.target == ChangeTargetDashboard {
+			noun = "dashboard " + map[bool]string{true: "properties", false: "property"}[n > 1]
+		}
+		parts = append(parts, fmt.Sprintf("%d %s %s", n, noun, k.typ))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func pluralTarget(t ChangeTarget) string {
+	if t == ChangeTargetQuery {
+		return "queries"
+	}
+	return string(t) + "s"
+}
+
+// changedProperties returns the sorted top level properties which differ between two objects.
+func changedProperties(before, after map[string]any, ignored map[string]bool) []string {
+	var props []string
+	for k, v := range before {
+		if !ignored[k] && !jsonEqual(v, after[k]) {
+			props = append(props, k)
+		}
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok && !ignored[k] {
+			props = append(props, k)
+		}
+	}
+	sort.Strings(props)
+	return props
+}
+
+// jsonEqual compares two decoded JSON values, ignoring the difference between number representations.
+func jsonEqual(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var av, bv any
+	if json.Unmarshal(aj, &av) != nil || json.Unmarshal(bj, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func panelName(p *panelInfo) string {
+	if p.title != "" {
+		return strconv.Quote(p.title)
+	}
+	return fmt.Sprintf("%d (%s)", p.id, p.typ)
+}
+
+// datasourceName returns the uid of a datasource reference, or its name for legacy references.
+func datasourceName(ds any) string {
+	switch v := ds.(type) {
+	case nil:
+		return "default"
+	case string:
+		return strconv.Quote(v)
+	case map[string]any:
+		if uid := toString(v["uid"]); uid != "" {
+			return strconv.Quote(uid)
+		}
+		if typ := toString(v["type"]); typ != "" {
+			return strconv.Quote(typ)
+		}
+	}
+	return "unknown"
+}
+
+func toString(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func toInt64(v any) int64 {
+	switch n := v.(type) {
+	case json.Number:
+		i, _ := n.Int64()
+		return i
+	case float64:
+		return int64(n)
+	case int:
+		return int64(n)
+	case int64:
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculateSemanticDiff(t *testing.T) {
+	const (
+		baseJSON = `{
+			"title": "Service",
+			"tags": ["prod"],
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Requests", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+				 "datasource": {"type": "prometheus", "uid": "prom-a"},
+				 "targets": [{"refId": "A", "expr": "rate(requests[5m])"}, {"refId": "B", "expr": "up"}]},
+				{"id": 2, "type": "stat", "title": "Errors", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "type": "row", "title": "Details", "collapsed": true, "panels": [
+					{"id": 4, "type": "table", "title": "Hosts", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}
+				]}
+			],
+			"templating": {"list": [
+				{"name": "env", "type": "custom", "query": "prod,dev", "current": {"value": "prod"}},
+				{"name": "host", "type": "query", "query": "label_values(host)", "datasource": {"uid": "prom-a"}}
+			]}
+		}`
+
+		newJSON = `{
+			"title": "Service overview",
+			"tags": ["prod"],
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Requests", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8},
+				 "datasource": {"type": "prometheus", "uid": "prom-b"},
+				 "targets": [{"refId": "A", "expr": "rate(requests[1m])"}, {"refId": "C", "expr": "down"}]},
+				{"id": 5, "type": "gauge", "title": "Latency", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "type": "row", "title": "Details", "collapsed": false, "panels": []},
+				{"id": 4, "type": "table", "title": "Hosts", "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}
+			],
+			"templating": {"list": [
+				{"name": "env", "type": "custom", "query": "prod,dev", "current": {"value": "dev"}},
+				{"name": "host", "type": "query", "query": "label_values(instance)", "datasource": {"uid": "prom-b"}},
+				{"name": "interval", "type": "interval", "query": "1m,5m"}
+			]}
+		}`
+	)
+
+	base, err := simplejson.NewJson([]byte(baseJSON))
+	require.NoError(t, err)
+	changed, err := simplejson.NewJson([]byte(newJSON))
+	require.NoError(t, err)
+
+	d := CalculateSemanticDiff(base, changed)
+
+	type change struct {
+		target   ChangeTarget
+		typ      SemanticChangeType
+		property string
+		panelID  int64
+		refID    string
+		variable string
+	}
+	var changes []change
+	for _, c := range d.Changes {
+		require.NotEmpty(t, c.Description)
+		changes = append(changes, change{c.Target, c.Type, c.Property, c.PanelID, c.RefID, c.Variable})
+	}
+	require.Equal(t, []change{
+		{target: ChangeTargetDashboard, typ: SemanticChangeModified, property: "title"},
+		{target: ChangeTargetPanel, typ: SemanticChangeRemoved, panelID: 2},
+		{target: ChangeTargetPanel, typ: SemanticChangeMoved, property: "gridPos", panelID: 1},
+		{target: ChangeTargetDatasource, typ: SemanticChangeModified, panelID: 1},
+		{target: ChangeTargetQuery, typ: SemanticChangeRemoved, panelID: 1, refID: "B"},
+		{target: ChangeTargetQuery, typ: SemanticChangeModified, property: "expr", panelID: 1, refID: "A"},
+		{target: ChangeTargetQuery, typ: SemanticChangeAdded, panelID: 1, refID: "C"},
+		{target: ChangeTargetPanel, typ: SemanticChangeAdded, panelID: 5},
+		{target: ChangeTargetDatasource, typ: SemanticChangeModified, variable: "host"},
+		{target: ChangeTargetVariable, typ: SemanticChangeModified, property: "query", variable: "host"},
+		{target: ChangeTargetVariable, typ: SemanticChangeAdded, variable: "interval"},
+	}, changes)
+	require.Equal(t, "1 dashboard property modified, 1 panel removed, 1 panel moved, 1 panel added, "+
+		"1 query removed, 1 query modified, 1 query added, 1 variable modified, 1 variable added, 2 datasources modified", d.Summary)
+
+	t.Run("Identical dashboards have no changes", func(t *testing.T) {
+		d := CalculateSemanticDiff(base, base)
+		require.Empty(t, d.Changes)
+		require.Equal(t, "No changes", d.Summary)
+	})
+
+	t.Run("Panels of different dashboards are matched by title and type", func(t *testing.T) {
+		other := simplejson.New()
+		other.Set("title", "Service")
+		other.Set("tags", []any{"prod"})
+		other.Set("panels", []any{
+			map[string]any{"id": 10, "type": "stat", "title": "Errors", "gridPos": map[string]any{"x": 12, "y": 0, "w": 12, "h": 8}},
+		})
+		d := CalculateSemanticDiff(base, other)
+		for _, c := range d.Changes {
+			require.NotEqual(t, "Errors", c.PanelTitle, c.Description)
+		}
+	})
+
+	t.Run("Panels following an expanded row are in the row", func(t *testing.T) {
+		details := map[string]any{"id": 1, "type": "row", "title": "Details", "collapsed": false, "panels": []any{}}
+		other := map[string]any{"id": 3, "type": "row", "title": "Other", "collapsed": false, "panels": []any{}}
+		hosts := map[string]any{"id": 2, "type": "table", "title": "Hosts"}
+		before := simplejson.NewFromAny(map[string]any{"panels": []any{details, hosts, other}})
+		after := simplejson.NewFromAny(map[string]any{"panels": []any{details, other, hosts}})
+
+		panels := flattenPanels(before.MustMap())
+		require.Equal(t, []string{"", "Details", ""}, []string{panels[0].row, panels[1].row, panels[2].row})
+
+		d := CalculateSemanticDiff(before, after)
+		require.Len(t, d.Changes, 1)
+		require.Equal(t, SemanticChangeMoved, d.Changes[0].Type)
+		require.Equal(t, "row", d.Changes[0].Property)
+		require.Equal(t, "Details", d.Changes[0].Before)
+		require.Equal(t, "Other", d.Changes[0].After)
+	})
+}
@@ -81,3 +81,14 @@ type FolderFullPathUpdated struct {
 	UIDs      []string  `json:"uids"`
 	OrgID     int64     `json:"org_id"`
 }
+
+// NewDeviceLogin is emitted when a user logs in from a device that none of the sessions of the
+// user are from. Devices are recognized by their user agent and the network of their IP address.
+type NewDeviceLogin struct {
+	Timestamp    time.Time `json:"timestamp"`
+	UserID       int64     `json:"user_id"`
+	TokenID      int64     `json:"token_id"`
+	UserAgent    string    `json:"user_agent"`
+	ClientSubnet string    `json:"client_subnet"`
+	Fingerprint  string    `json:"fingerprint"`
+}
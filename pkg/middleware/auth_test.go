@@ -102,6 +102,23 @@ func TestAuth_Middleware(t *testing.T) {
 			expecedReached: true,
 			expectedCode:   http.StatusOK,
 		},
+		{
+			desc:           "ReqOrgAdmin should return 200 for service account with admin role",
+			path:           "/api/secure",
+			authMiddleware: ReqOrgAdmin,
+			identity:       &authn.Identity{ID: "1", Type: claims.TypeServiceAccount, OrgID: 1, OrgRoles: map[int64]org.RoleType{1: org.RoleAdmin}},
+			expecedReached: true,
+			expectedCode:   http.StatusOK,
+		},
+		{
+			desc:           "ReqOrgAdmin should return 403 for restricted service account token",
+			path:           "/api/secure",
+			authMiddleware: ReqOrgAdmin,
+			// restricted tokens lose the role of the service account when their permissions are synced
+			identity:       &authn.Identity{ID: "1", Type: claims.TypeServiceAccount, OrgID: 1, OrgRoles: map[int64]org.RoleType{1: org.RoleNone}},
+			expecedReached: false,
+			expectedCode:   http.StatusForbidden,
+		},
 		{
 			desc:           "snapshot public mode disabled should return 200 for authenticated user",
 			path:           "/api/secure",
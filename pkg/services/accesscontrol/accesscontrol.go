@@ -265,6 +265,27 @@ func GroupScopesByActionContext(ctx context.Context, permissions []Permission) m
 	return m
 }
 
+// GetSignedInUserWithPermissions returns the user with the permissions of the user in the
+// organization, to act as the user outside of a request of the user such as in background jobs.
+func GetSignedInUserWithPermissions(ctx context.Context, userService user.Service, service Service, userID, orgID int64) (*user.SignedInUser, error) {
+	usr, err := userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: userID, OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	if usr.Permissions == nil {
+		usr.Permissions = make(map[int64]map[string][]string)
+	}
+	if _, ok := usr.Permissions[orgID]; ok {
+		return usr, nil
+	}
+	permissions, err := service.GetUserPermissions(ctx, usr, Options{ReloadCache: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user permissions: %w", err)
+	}
+	usr.Permissions[orgID] = GroupScopesByActionContext(ctx, permissions)
+	return usr, nil
+}
+
 // Reduce will reduce a list of permissions to its minimal form, grouping scopes by action
 func Reduce(ps []Permission) map[string][]string {
 	reduced := make(map[string][]string)
@@ -205,6 +205,12 @@ func (a *AccessControl) RegisterScopeAttributeResolver(prefix string, resolver a
 	a.resolvers.AddScopeAttributeResolver(prefix, resolver)
 }
 
+// ResolveScope returns the scopes that a scope is evaluated with, like the parent folders of a
+// dashboard, using the registered scope attribute resolvers.
+func (a *AccessControl) ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error) {
+	return a.resolvers.GetScopeAttributeMutator(orgID)(ctx, scope)
+}
+
 func (a *AccessControl) WithoutResolvers() accesscontrol.AccessControl {
 	return &AccessControl{
 		features:  a.features,
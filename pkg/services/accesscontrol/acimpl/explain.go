@@ -0,0 +1,246 @@
+package acimpl
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+)
+
+var _ accesscontrol.PermissionExplainer = new(PermissionExplainer)
+
+// scopeResolver resolves a scope to the scopes it is evaluated with, it is implemented by AccessControl.
+type scopeResolver interface {
+	ResolveScope(ctx context.Context, orgID int64, scope string) ([]string, error)
+}
+
+// PermissionExplainer explains access control decisions with the roles, the teams and the
+// basic roles that the permissions of a user come from.
+type PermissionExplainer struct {
+	service  *Service
+	store    *database.AccessControlStore
+	resolver scopeResolver
+}
+
+func NewPermissionExplainer(service *Service, store *database.AccessControlStore, accessControl accesscontrol.AccessControl) *PermissionExplainer {
+	explainer := &PermissionExplainer{service: service, store: store}
+	if resolver, ok := accessControl.(scopeResolver); ok {
+		explainer.resolver = resolver
+	}
+	return explainer
+}
+
+// ExplainPermission evaluates an action on a scope for a user from its role assignments in the
+// database, and evaluates it again with the proposed changes of the query if there are any.
+func (e *PermissionExplainer) ExplainPermission(ctx context.Context, query accesscontrol.ExplainPermissionQuery) (*accesscontrol.PermissionExplanation, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.acimpl.ExplainPermission")
+	defer span.End()
+
+	subject, err := e.store.GetPermissionSubject(ctx, query.OrgID, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedScopes, err := e.resolveScope(ctx, query.OrgID, query.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation, err := e.explain(ctx, query, *subject, resolvedScopes, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.WhatIf != nil {
+		proposed := applyPermissionChanges(*subject, *query.WhatIf)
+		if explanation.WhatIf, err = e.explain(ctx, query, proposed, resolvedScopes, query.WhatIf); err != nil {
+			return nil, err
+		}
+	}
+
+	return explanation, nil
+}
+
+// resolveScope returns the scope followed by the scopes it inherits permissions from.
+func (e *PermissionExplainer) resolveScope(ctx context.Context, orgID int64, scope string) ([]string, error) {
+	scopes := make([]string, 0)
+	if scope == "" {
+		return scopes, nil
+	}
+	scopes = append(scopes, scope)
+	if e.resolver == nil || strings.HasSuffix(scope, "*") {
+		return scopes, nil
+	}
+
+	resolved, err := e.resolver.ResolveScope(ctx, orgID, scope)
+	if err != nil {
+		if errors.Is(err, accesscontrol.ErrResolverNotFound) {
+			return scopes, nil
+		}
+		return nil, accesscontrol.ErrExplainScopeNotResolved.Errorf("could not resolve scope %s: %w", scope, err)
+	}
+	for _, s := range resolved {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
+
+func (e *PermissionExplainer) explain(ctx context.Context, query accesscontrol.ExplainPermissionQuery, subject accesscontrol.PermissionSubject,
+	resolvedScopes []string, changes *accesscontrol.PermissionChanges) (*accesscontrol.PermissionExplanation, error) {
+	actions := []string{query.Action}
+	if e.service.features.IsEnabled(ctx, featuremgmt.FlagAccessActionSets) {
+		actions = append(actions, e.service.actionResolver.ResolveAction(query.Action)...)
+	}
+
+	basicRoles := subject.BasicRoles(query.OrgID)
+	grants := e.fixedRoleGrants(actions, basicRoles)
+	if e.service.features.IsEnabled(ctx, featuremgmt.FlagNestedFolders) && SharedWithMeFolderPermission.Action == query.Action {
+		grants = append(grants, accesscontrol.PermissionGrant{
+			Action: SharedWithMeFolderPermission.Action,
+			Scope:  SharedWithMeFolderPermission.Scope,
+			Source: accesscontrol.GrantSourceDefault,
+		})
+	}
+
+	dbGrants, err := e.store.GetPermissionGrants(ctx, accesscontrol.GetPermissionGrantsQuery{
+		OrgID:        query.OrgID,
+		UserID:       subject.UserID,
+		TeamIDs:      subject.Teams,
+		Roles:        basicRoles,
+		Actions:      actions,
+		RolePrefixes: OSSRolesPrefixes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range dbGrants {
+		grant.Managed = strings.HasPrefix(grant.RoleName, accesscontrol.ManagedRolePrefix)
+		if grant.Source == accesscontrol.GrantSourceBasicRole && grant.BasicRole == accesscontrol.RoleGrafanaAdmin {
+			grant.Source = accesscontrol.GrantSourceGrafanaAdmin
+		}
+		grants = append(grants, grant)
+	}
+
+	if changes != nil {
+		grants = slices.DeleteFunc(grants, func(grant accesscontrol.PermissionGrant) bool {
+			return grant.RoleName != "" && slices.Contains(changes.RemoveRoles, grant.RoleName)
+		})
+		for _, name := range changes.AddRoles {
+			role, err := e.service.GetRoleByName(ctx, query.OrgID, name)
+			if err != nil {
+				return nil, accesscontrol.ErrExplainRoleNotFound.Errorf("could not find role %s: %w", name, err)
+			}
+			for _, p := range role.Permissions {
+				if slices.Contains(actions, p.Action) {
+					grants = append(grants, accesscontrol.PermissionGrant{
+						Action:          p.Action,
+						Scope:           p.Scope,
+						RoleName:        role.Name,
+						RoleDisplayName: role.DisplayName,
+						Source:          accesscontrol.GrantSourceProposed,
+					})
+				}
+			}
+		}
+		for _, p := range changes.AddPermissions {
+			if slices.Contains(actions, p.Action) {
+				grants = append(grants, accesscontrol.PermissionGrant{Action: p.Action, Scope: p.Scope, Source: accesscontrol.GrantSourceProposed})
+			}
+		}
+	}
+
+	explanation := &accesscontrol.PermissionExplanation{
+		Subject:        subject,
+		Action:         query.Action,
+		Scope:          query.Scope,
+		ResolvedScopes: resolvedScopes,
+		Grants:         make([]accesscontrol.PermissionGrant, 0),
+		OtherGrants:    make([]accesscontrol.PermissionGrant, 0),
+	}
+	for _, grant := range grants {
+		if matched, ok := matchGrant(query.Action, grant.Scope, resolvedScopes); ok {
+			grant.MatchedScope = matched
+			explanation.Grants = append(explanation.Grants, grant)
+		} else {
+			explanation.OtherGrants = append(explanation.OtherGrants, grant)
+		}
+	}
+	explanation.Allowed = len(explanation.Grants) > 0
+
+	return explanation, nil
+}
+
+// fixedRoleGrants returns the permissions for the actions of the fixed roles granted to the basic roles.
+func (e *PermissionExplainer) fixedRoleGrants(actions []string, basicRoles []string) []accesscontrol.PermissionGrant {
+	grants := make([]accesscontrol.PermissionGrant, 0)
+	for _, basicRole := range basicRoles {
+		source := accesscontrol.GrantSourceBasicRole
+		if basicRole == accesscontrol.RoleGrafanaAdmin {
+			source = accesscontrol.GrantSourceGrafanaAdmin
+		}
+		e.service.registrations.Range(func(registration accesscontrol.RoleRegistration) bool {
+			if _, ok := accesscontrol.BuiltInRolesWithParents(registration.Grants)[basicRole]; !ok {
+				return true
+			}
+			for _, p := range registration.Role.Permissions {
+				if slices.Contains(actions, p.Action) {
+					grants = append(grants, accesscontrol.PermissionGrant{
+						Action:          p.Action,
+						Scope:           p.Scope,
+						RoleName:        registration.Role.Name,
+						RoleDisplayName: registration.Role.DisplayName,
+						Source:          source,
+						BasicRole:       basicRole,
+					})
+				}
+			}
+			return true
+		})
+	}
+	return grants
+}
+
+// matchGrant returns the first resolved scope that a permission for the action matches. Without
+// scopes, any permission for the action matches.
+func matchGrant(action, grantScope string, resolvedScopes []string) (string, bool) {
+	if len(resolvedScopes) == 0 {
+		return "", true
+	}
+	permissions := map[string][]string{action: {grantScope}}
+	for _, scope := range resolvedScopes {
+		if accesscontrol.EvalPermission(action, scope).Evaluate(permissions) {
+			return scope, true
+		}
+	}
+	return "", false
+}
+
+// applyPermissionChanges returns the subject with the proposed org role, server admin flag and teams.
+func applyPermissionChanges(subject accesscontrol.PermissionSubject, changes accesscontrol.PermissionChanges) accesscontrol.PermissionSubject {
+	if changes.OrgRole != "" {
+		subject.OrgRole = changes.OrgRole
+	}
+	if changes.IsGrafanaAdmin != nil {
+		subject.IsGrafanaAdmin = *changes.IsGrafanaAdmin
+	}
+
+	teams := make([]int64, 0, len(subject.Teams)+len(changes.AddTeams))
+	for _, id := range subject.Teams {
+		if !slices.Contains(changes.RemoveTeams, id) {
+			teams = append(teams, id)
+		}
+	}
+	for _, id := range changes.AddTeams {
+		if !slices.Contains(teams, id) {
+			teams = append(teams, id)
+		}
+	}
+	subject.Teams = teams
+	return subject
+}
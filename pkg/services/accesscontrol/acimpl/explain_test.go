@@ -0,0 +1,208 @@
+package acimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/database"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/permreg"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeScopeResolver map[string][]string
+
+func (f fakeScopeResolver) ResolveScope(_ context.Context, _ int64, scope string) ([]string, error) {
+	if scopes, ok := f[scope]; ok {
+		return scopes, nil
+	}
+	return nil, accesscontrol.ErrResolverNotFound
+}
+
+func setupExplainTestEnv(t *testing.T) *PermissionExplainer {
+	t.Helper()
+	now := time.Now()
+	sqlStore := db.InitTestReplDB(t)
+	store := database.ProvideService(sqlStore)
+	service := &Service{
+		cache:         localcache.ProvideService(),
+		cfg:           setting.NewCfg(),
+		features:      featuremgmt.WithFeatures(),
+		log:           log.New("accesscontrol-test"),
+		registrations: accesscontrol.RegistrationList{},
+		roles:         accesscontrol.BuildBasicRoleDefinitions(),
+		store:         store,
+		permRegistry:  permreg.ProvidePermissionRegistry(),
+	}
+	require.NoError(t, service.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{Name: "fixed:dashboards:reader", DisplayName: "Dashboard reader", Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:read", Scope: "dashboards:*"},
+			}},
+			Grants: []string{string(org.RoleViewer)},
+		},
+		accesscontrol.RoleRegistration{
+			Role: accesscontrol.RoleDTO{Name: "fixed:dashboards:writer", DisplayName: "Dashboard writer", Permissions: []accesscontrol.Permission{
+				{Action: "dashboards:write", Scope: "dashboards:*"},
+			}},
+			Grants: []string{string(org.RoleAdmin)},
+		},
+	))
+	require.NoError(t, service.RegisterFixedRoles(context.Background()))
+
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		inserts := []any{
+			&user.User{ID: 1, UID: "user1", Login: "user1", Email: "user1@example.org", Created: now, Updated: now},
+			&user.User{ID: 2, UID: "user2", Login: "user2", Email: "user2@example.org", Created: now, Updated: now},
+			&org.OrgUser{ID: 1, UserID: 1, OrgID: 1, Role: org.RoleViewer, Created: now, Updated: now},
+			&team.Team{ID: 1, UID: "team1", OrgID: 1, Name: "Platform", Created: now, Updated: now},
+			&team.TeamMember{ID: 1, OrgID: 1, TeamID: 1, UserID: 1, Created: now, Updated: now},
+			&accesscontrol.Role{ID: 1, UID: "managed_users_1", Name: "managed:users:1:permissions", OrgID: 1, Version: 1, Created: now, Updated: now},
+			&accesscontrol.Role{ID: 2, UID: "managed_teams_1", Name: "managed:teams:1:permissions", OrgID: 1, Version: 1, Created: now, Updated: now},
+			&accesscontrol.UserRole{ID: 1, OrgID: 1, RoleID: 1, UserID: 1, Created: now},
+			&accesscontrol.TeamRole{ID: 1, OrgID: 1, RoleID: 2, TeamID: 1, Created: now},
+			&accesscontrol.Permission{RoleID: 1, Action: "dashboards:write", Scope: "dashboards:uid:other", Created: now, Updated: now},
+			&accesscontrol.Permission{RoleID: 2, Action: "dashboards:write", Scope: "folders:uid:parent", Created: now, Updated: now},
+		}
+		for _, row := range inserts {
+			if _, err := sess.Insert(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	explainer := NewPermissionExplainer(service, store, nil)
+	explainer.resolver = fakeScopeResolver{
+		"dashboards:uid:dash": {"dashboards:uid:dash", "folders:uid:parent", "folders:uid:root"},
+	}
+	return explainer
+}
+
+func TestPermissionExplainer_ExplainPermission(t *testing.T) {
+	ctx := context.Background()
+	explainer := setupExplainTestEnv(t)
+
+	t.Run("should explain a permission inherited from a folder through a team", func(t *testing.T) {
+		explanation, err := explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:write", Scope: "dashboards:uid:dash",
+		})
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Allowed)
+		assert.Equal(t, []string{"dashboards:uid:dash", "folders:uid:parent", "folders:uid:root"}, explanation.ResolvedScopes)
+		assert.Equal(t, []int64{1}, explanation.Subject.Teams)
+		require.Len(t, explanation.Grants, 1)
+		assert.Equal(t, accesscontrol.PermissionGrant{
+			Action:       "dashboards:write",
+			Scope:        "folders:uid:parent",
+			MatchedScope: "folders:uid:parent",
+			RoleName:     "managed:teams:1:permissions",
+			RoleUID:      "managed_teams_1",
+			Managed:      true,
+			Source:       accesscontrol.GrantSourceTeam,
+			TeamID:       1,
+			TeamName:     "Platform",
+		}, explanation.Grants[0])
+		require.Len(t, explanation.OtherGrants, 1)
+		assert.Equal(t, "dashboards:uid:other", explanation.OtherGrants[0].Scope)
+		assert.Equal(t, accesscontrol.GrantSourceUser, explanation.OtherGrants[0].Source)
+		assert.Nil(t, explanation.WhatIf)
+	})
+
+	t.Run("should explain a permission of a fixed role granted to the basic role", func(t *testing.T) {
+		explanation, err := explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:read", Scope: "dashboards:uid:dash",
+		})
+		require.NoError(t, err)
+
+		assert.True(t, explanation.Allowed)
+		require.Len(t, explanation.Grants, 1)
+		assert.Equal(t, "fixed:dashboards:reader", explanation.Grants[0].RoleName)
+		assert.Equal(t, accesscontrol.GrantSourceBasicRole, explanation.Grants[0].Source)
+		assert.Equal(t, string(org.RoleViewer), explanation.Grants[0].BasicRole)
+		assert.Equal(t, "dashboards:uid:dash", explanation.Grants[0].MatchedScope)
+	})
+
+	t.Run("should evaluate proposed changes", func(t *testing.T) {
+		explanation, err := explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:write", Scope: "dashboards:uid:dash",
+			WhatIf: &accesscontrol.PermissionChanges{RemoveTeams: []int64{1}},
+		})
+		require.NoError(t, err)
+		assert.True(t, explanation.Allowed)
+		require.NotNil(t, explanation.WhatIf)
+		assert.False(t, explanation.WhatIf.Allowed)
+		assert.Empty(t, explanation.WhatIf.Subject.Teams)
+
+		explanation, err = explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:write", Scope: "dashboards:uid:dash",
+			WhatIf: &accesscontrol.PermissionChanges{RemoveTeams: []int64{1}, OrgRole: string(org.RoleAdmin)},
+		})
+		require.NoError(t, err)
+		require.True(t, explanation.WhatIf.Allowed)
+		assert.Equal(t, "fixed:dashboards:writer", explanation.WhatIf.Grants[0].RoleName)
+		assert.Equal(t, string(org.RoleAdmin), explanation.WhatIf.Grants[0].BasicRole)
+
+		explanation, err = explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:write", Scope: "dashboards:uid:dash",
+			WhatIf: &accesscontrol.PermissionChanges{
+				RemoveRoles: []string{"managed:teams:1:permissions"},
+				AddRoles:    []string{"fixed:dashboards:writer"},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, explanation.WhatIf.Allowed)
+		require.Len(t, explanation.WhatIf.Grants, 1)
+		assert.Equal(t, "fixed:dashboards:writer", explanation.WhatIf.Grants[0].RoleName)
+		assert.Equal(t, accesscontrol.GrantSourceProposed, explanation.WhatIf.Grants[0].Source)
+	})
+
+	t.Run("should return an error for unknown proposed roles", func(t *testing.T) {
+		_, err := explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 1, Action: "dashboards:write",
+			WhatIf: &accesscontrol.PermissionChanges{AddRoles: []string{"fixed:unknown:role"}},
+		})
+		require.ErrorIs(t, err, accesscontrol.ErrExplainRoleNotFound)
+	})
+
+	t.Run("should not find users that are not members of the organization", func(t *testing.T) {
+		_, err := explainer.ExplainPermission(ctx, accesscontrol.ExplainPermissionQuery{
+			OrgID: 1, UserID: 2, Action: "dashboards:write",
+		})
+		require.ErrorIs(t, err, user.ErrUserNotFound)
+	})
+}
+
+func TestMatchGrant(t *testing.T) {
+	scopes := []string{"dashboards:uid:dash", "folders:uid:parent"}
+
+	matched, ok := matchGrant("dashboards:read", "folders:uid:parent", scopes)
+	assert.True(t, ok)
+	assert.Equal(t, "folders:uid:parent", matched)
+
+	matched, ok = matchGrant("dashboards:read", "dashboards:*", scopes)
+	assert.True(t, ok)
+	assert.Equal(t, "dashboards:uid:dash", matched)
+
+	_, ok = matchGrant("dashboards:read", "folders:uid:other", scopes)
+	assert.False(t, ok)
+
+	_, ok = matchGrant("dashboards:read", "", scopes)
+	assert.False(t, ok)
+
+	_, ok = matchGrant("dashboards:read", "", nil)
+	assert.True(t, ok)
+}
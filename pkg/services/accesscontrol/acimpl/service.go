@@ -55,9 +55,11 @@ func ProvideService(
 	accessControl accesscontrol.AccessControl, actionResolver accesscontrol.ActionResolver,
 	features featuremgmt.FeatureToggles, tracer tracing.Tracer, zclient zanzana.Client, permRegistry permreg.PermissionRegistry,
 ) (*Service, error) {
-	service := ProvideOSSService(cfg, database.ProvideService(db), actionResolver, cache, features, tracer, zclient, db.DB(), permRegistry)
+	store := database.ProvideService(db)
+	service := ProvideOSSService(cfg, store, actionResolver, cache, features, tracer, zclient, db.DB(), permRegistry)
 
-	api.NewAccessControlAPI(routeRegister, accessControl, service, features).RegisterAPIEndpoints()
+	explainer := NewPermissionExplainer(service, store, accessControl)
+	api.NewAccessControlAPI(routeRegister, accessControl, service, explainer, features).RegisterAPIEndpoints()
 	if err := accesscontrol.DeclareFixedRoles(service, cfg); err != nil {
 		return nil, err
 	}
@@ -1,26 +1,32 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
 	"go.opentelemetry.io/otel"
 )
 
 var tracer = otel.Tracer("github.com/grafana/grafana/pkg/services/accesscontrol/api")
 
 func NewAccessControlAPI(router routing.RouteRegister, accesscontrol ac.AccessControl, service ac.Service,
-	features featuremgmt.FeatureToggles) *AccessControlAPI {
+	explainer ac.PermissionExplainer, features featuremgmt.FeatureToggles) *AccessControlAPI {
 	return &AccessControlAPI{
 		RouteRegister: router,
 		Service:       service,
 		AccessControl: accesscontrol,
+		Explainer:     explainer,
 		features:      features,
 	}
 }
@@ -28,6 +34,7 @@ func NewAccessControlAPI(router routing.RouteRegister, accesscontrol ac.AccessCo
 type AccessControlAPI struct {
 	Service       ac.Service
 	AccessControl ac.AccessControl
+	Explainer     ac.PermissionExplainer
 	RouteRegister routing.RouteRegister
 	features      featuremgmt.FeatureToggles
 }
@@ -41,6 +48,9 @@ func (api *AccessControlAPI) RegisterAPIEndpoints() {
 		if api.features.IsEnabledGlobally(featuremgmt.FlagAccessControlOnCall) {
 			rr.Get("/users/permissions/search", authorize(ac.EvalPermission(ac.ActionUsersPermissionsRead)), routing.Wrap(api.searchUsersPermissions))
 		}
+		if api.Explainer != nil {
+			rr.Post("/explain", middleware.ReqSignedIn, routing.Wrap(api.explainPermission))
+		}
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
 }
 
@@ -106,3 +116,65 @@ func (api *AccessControlAPI) searchUsersPermissions(c *contextmodel.ReqContext)
 
 	return response.JSON(http.StatusOK, permsByAction)
 }
+
+// POST /api/access-control/explain
+func (api *AccessControlAPI) explainPermission(c *contextmodel.ReqContext) response.Response {
+	ctx, span := tracer.Start(c.Req.Context(), "accesscontrol.api.explainPermission")
+	defer span.End()
+
+	query := ac.ExplainPermissionQuery{}
+	if err := web.Bind(c.Req, &query); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if query.Action == "" {
+		return response.Error(http.StatusBadRequest, "action is required", nil)
+	}
+	if query.WhatIf != nil && query.WhatIf.OrgRole != "" && !identity.RoleType(query.WhatIf.OrgRole).IsValid() {
+		return response.Error(http.StatusBadRequest, "invalid proposed organization role", nil)
+	}
+
+	// Users can explain their own permissions, explaining the permissions of other users or
+	// evaluating proposed changes requires reading the permissions of the user.
+	signedInID, _ := identity.UserIdentifier(c.SignedInUser.GetID())
+	if query.UserID == 0 {
+		query.UserID = signedInID
+	}
+	if query.UserID == 0 {
+		return response.Error(http.StatusBadRequest, "userId is required", nil)
+	}
+	scope := ac.ScopeUsersPrefix + strconv.FormatInt(query.UserID, 10)
+	canReadPermissions, err := api.AccessControl.Evaluate(ctx, c.SignedInUser, ac.EvalPermission(ac.ActionUsersPermissionsRead, scope))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to evaluate permissions", err)
+	}
+	if (query.UserID != signedInID || query.WhatIf != nil) && !canReadPermissions {
+		return response.Error(http.StatusForbidden, "you are not allowed to read the permissions of this user", nil)
+	}
+
+	query.OrgID = c.SignedInUser.GetOrgID()
+	explanation, err := api.Explainer.ExplainPermission(ctx, query)
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return response.Error(http.StatusNotFound, "user not found", err)
+		}
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to explain permission", err)
+	}
+	if !canReadPermissions {
+		redactResolvedScopes(explanation)
+	}
+
+	return response.JSON(http.StatusOK, explanation)
+}
+
+// redactResolvedScopes removes the scopes that the scope was resolved to, like the parent folders
+// of a dashboard, since they can be scopes of resources that the user is not allowed to read.
+func redactResolvedScopes(explanation *ac.PermissionExplanation) {
+	if len(explanation.ResolvedScopes) > 1 {
+		explanation.ResolvedScopes = explanation.ResolvedScopes[:1]
+	}
+	for i := range explanation.Grants {
+		if explanation.Grants[i].MatchedScope != explanation.Scope {
+			explanation.Grants[i].MatchedScope = ""
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -40,7 +42,7 @@ func TestAPI_getUserActions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, featuremgmt.WithFeatures())
+			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, nil, featuremgmt.WithFeatures())
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -93,7 +95,7 @@ func TestAPI_getUserPermissions(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedPermissions: tt.permissions}
-			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, featuremgmt.WithFeatures())
+			api := NewAccessControlAPI(routing.NewRouteRegister(), actest.FakeAccessControl{}, acSvc, nil, featuremgmt.WithFeatures())
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -174,7 +176,7 @@ func TestAccessControlAPI_searchUsersPermissions(t *testing.T) {
 		t.Run(tt.desc, func(t *testing.T) {
 			acSvc := actest.FakeService{ExpectedUsersPermissions: tt.permissions}
 			accessControl := actest.FakeAccessControl{ExpectedEvaluate: true} // Always allow access to the endpoint
-			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, featuremgmt.WithFeatures(featuremgmt.FlagAccessControlOnCall))
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, acSvc, nil, featuremgmt.WithFeatures(featuremgmt.FlagAccessControlOnCall))
 			api.RegisterAPIEndpoints()
 
 			server := webtest.NewServer(t, api.RouteRegister)
@@ -199,3 +201,125 @@ func TestAccessControlAPI_searchUsersPermissions(t *testing.T) {
 		})
 	}
 }
+
+type fakeExplainer struct {
+	query ac.ExplainPermissionQuery
+	err   error
+}
+
+func (f *fakeExplainer) ExplainPermission(_ context.Context, query ac.ExplainPermissionQuery) (*ac.PermissionExplanation, error) {
+	f.query = query
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &ac.PermissionExplanation{
+		Action:         query.Action,
+		Scope:          query.Scope,
+		Allowed:        true,
+		ResolvedScopes: []string{query.Scope, "folders:uid:parent"},
+		Grants:         []ac.PermissionGrant{{Action: query.Action, Scope: "folders:uid:parent", MatchedScope: "folders:uid:parent"}},
+	}, nil
+}
+
+func TestAPI_explainPermission(t *testing.T) {
+	type testCase struct {
+		desc           string
+		body           string
+		canReadUsers   bool
+		explainErr     error
+		expectedCode   int
+		expectedUserID int64
+		// expectedResolved is whether the scopes the scope is resolved to are returned
+		expectedResolved bool
+	}
+
+	tests := []testCase{
+		{
+			desc:           "Should explain the permissions of the signed in user",
+			body:           `{"action": "dashboards:write", "scope": "dashboards:uid:dash"}`,
+			expectedCode:   http.StatusOK,
+			expectedUserID: 1,
+		},
+		{
+			desc:             "Should resolve the scope for users who can read their permissions",
+			body:             `{"action": "dashboards:write", "scope": "dashboards:uid:dash"}`,
+			canReadUsers:     true,
+			expectedCode:     http.StatusOK,
+			expectedUserID:   1,
+			expectedResolved: true,
+		},
+		{
+			desc:         "Should require an action",
+			body:         `{"scope": "dashboards:uid:dash"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should not explain the permissions of other users without permission",
+			body:         `{"userId": 2, "action": "dashboards:write"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "Should not evaluate proposed changes without permission",
+			body:         `{"action": "dashboards:write", "whatIf": {"orgRole": "Editor"}}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:             "Should explain the permissions of other users with permission",
+			body:             `{"userId": 2, "action": "dashboards:write", "whatIf": {"addTeams": [3]}}`,
+			canReadUsers:     true,
+			expectedCode:     http.StatusOK,
+			expectedUserID:   2,
+			expectedResolved: true,
+		},
+		{
+			desc:         "Should validate the proposed organization role",
+			body:         `{"userId": 2, "action": "dashboards:write", "whatIf": {"orgRole": "Owner"}}`,
+			canReadUsers: true,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "Should return not found for unknown users",
+			body:         `{"userId": 3, "action": "dashboards:write"}`,
+			canReadUsers: true,
+			explainErr:   user.ErrUserNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			explainer := &fakeExplainer{err: tt.explainErr}
+			accessControl := actest.FakeAccessControl{ExpectedEvaluate: tt.canReadUsers}
+			api := NewAccessControlAPI(routing.NewRouteRegister(), accessControl, actest.FakeService{}, explainer, featuremgmt.WithFeatures())
+			api.RegisterAPIEndpoints()
+
+			server := webtest.NewServer(t, api.RouteRegister)
+			req := server.NewPostRequest("/api/access-control/explain", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{
+				UserID:      1,
+				OrgID:       1,
+				Permissions: map[int64]map[string][]string{},
+			})
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var output ac.PermissionExplanation
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&output))
+				require.True(t, output.Allowed)
+				require.Equal(t, tt.expectedUserID, explainer.query.UserID)
+				require.Equal(t, int64(1), explainer.query.OrgID)
+				if tt.expectedResolved {
+					require.Len(t, output.ResolvedScopes, 2)
+					require.Equal(t, "folders:uid:parent", output.Grants[0].MatchedScope)
+				} else {
+					require.Len(t, output.ResolvedScopes, 1)
+					require.Empty(t, output.Grants[0].MatchedScope)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// GetPermissionSubject returns the role of a user in an organization and the teams of the user in it.
+// Users that are not members of the organization are not found.
+func (s *AccessControlStore) GetPermissionSubject(ctx context.Context, orgID, userID int64) (*accesscontrol.PermissionSubject, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetPermissionSubject")
+	defer span.End()
+
+	subject := &accesscontrol.PermissionSubject{}
+	err := s.sql.ReadReplica().WithDbSession(ctx, func(sess *db.Session) error {
+		q := `
+		SELECT u.id, u.login, u.is_service_account, u.is_admin, ou.role
+		FROM ` + s.sql.ReadReplica().GetDialect().Quote("user") + ` AS u
+		INNER JOIN org_user AS ou ON ou.user_id = u.id AND ou.org_id = ?
+		WHERE u.id = ?`
+		has, err := sess.SQL(q, orgID, userID).Get(subject)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return user.ErrUserNotFound
+		}
+
+		subject.Teams = make([]int64, 0)
+		return sess.SQL(`SELECT team_id FROM team_member WHERE user_id = ? AND org_id = ?`, userID, orgID).Find(&subject.Teams)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return subject, nil
+}
+
+// GetPermissionGrants returns the permissions for the actions that are assigned to a user, to its
+// teams and to its basic roles, with the role and the assignment they come from.
+func (s *AccessControlStore) GetPermissionGrants(ctx context.Context, query accesscontrol.GetPermissionGrantsQuery) ([]accesscontrol.PermissionGrant, error) {
+	ctx, span := tracer.Start(ctx, "accesscontrol.database.GetPermissionGrants")
+	defer span.End()
+
+	result := make([]accesscontrol.PermissionGrant, 0)
+	if len(query.Actions) == 0 || query.UserID == 0 && len(query.TeamIDs) == 0 && len(query.Roles) == 0 {
+		return result, nil
+	}
+
+	var assignments []string
+	var params []any
+	if query.UserID > 0 {
+		assignments = append(assignments, `
+			SELECT ur.role_id, 'user' AS source, 0 AS team_id, '' AS basic_role
+			FROM user_role AS ur
+			WHERE ur.user_id = ?
+			AND (ur.org_id = ? OR ur.org_id = ?)`)
+		params = append(params, query.UserID, query.OrgID, accesscontrol.GlobalOrgID)
+	}
+	if len(query.TeamIDs) > 0 {
+		assignments = append(assignments, `
+			SELECT tr.role_id, 'team' AS source, tr.team_id, '' AS basic_role
+			FROM team_role AS tr
+			WHERE tr.team_id IN (?`+strings.Repeat(", ?", len(query.TeamIDs)-1)+`)
+			AND tr.org_id = ?`)
+		for _, id := range query.TeamIDs {
+			params = append(params, id)
+		}
+		params = append(params, query.OrgID)
+	}
+	if len(query.Roles) > 0 {
+		assignments = append(assignments, `
+			SELECT br.role_id, 'basic role' AS source, 0 AS team_id, br.role AS basic_role
+			FROM builtin_role AS br
+			WHERE br.role IN (?`+strings.Repeat(", ?", len(query.Roles)-1)+`)
+			AND (br.org_id = ? OR br.org_id = ?)`)
+		for _, role := range query.Roles {
+			params = append(params, role)
+		}
+		params = append(params, query.OrgID, accesscontrol.GlobalOrgID)
+	}
+
+	q := `
+		SELECT
+			permission.action,
+			permission.scope,
+			role.name AS role_name,
+			role.uid AS role_uid,
+			COALESCE(role.display_name, '') AS role_display_name,
+			a.source,
+			a.team_id,
+			COALESCE(team.name, '') AS team_name,
+			a.basic_role
+		FROM permission
+		INNER JOIN role ON role.id = permission.role_id
+		INNER JOIN (` + strings.Join(assignments, "\n\t\t\tUNION ALL") + `
+		) AS a ON a.role_id = role.id
+		LEFT JOIN team ON team.id = a.team_id`
+
+	filter := " WHERE"
+	if len(query.RolePrefixes) > 0 {
+		rolePrefixesFilter, filterParams := accesscontrol.RolePrefixesFilter(query.RolePrefixes)
+		q += rolePrefixesFilter
+		params = append(params, filterParams...)
+		filter = " AND"
+	}
+	q += filter + " permission.action IN (?" + strings.Repeat(", ?", len(query.Actions)-1) + ")"
+	for _, action := range query.Actions {
+		params = append(params, action)
+	}
+	q += " ORDER BY permission.action, permission.scope, role.name"
+
+	err := s.sql.ReadReplica().WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(q, params...).Find(&result)
+	})
+	return result, err
+}
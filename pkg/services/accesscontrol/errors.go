@@ -29,6 +29,9 @@ var (
 	ErrRoleNotFound           = errors.New("role not found")
 
 	ErrActionSetValidationFailed = errutil.ValidationFailed("accesscontrol.actionSetInvalid")
+
+	ErrExplainScopeNotResolved = errutil.BadRequest("accesscontrol.explainScopeNotResolved", errutil.WithPublicMessage("The scope could not be resolved"))
+	ErrExplainRoleNotFound     = errutil.BadRequest("accesscontrol.explainRoleNotFound", errutil.WithPublicMessage("A proposed role was not found"))
 )
 
 func ErrInvalidBuiltinRoleData(builtInRole string) errutil.TemplateData {
@@ -0,0 +1,110 @@
+package accesscontrol
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// Sources of the permissions of a user, as reported by PermissionGrant.Source
+const (
+	GrantSourceUser         = "user"
+	GrantSourceTeam         = "team"
+	GrantSourceBasicRole    = "basic role"
+	GrantSourceGrafanaAdmin = "server admin"
+	GrantSourceDefault      = "default"
+	GrantSourceProposed     = "proposed"
+)
+
+// PermissionExplainer explains the access control decision for a user, an action and a scope.
+type PermissionExplainer interface {
+	ExplainPermission(ctx context.Context, query ExplainPermissionQuery) (*PermissionExplanation, error)
+}
+
+type ExplainPermissionQuery struct {
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"userId"`
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// WhatIf are proposed changes to the user that the decision is evaluated again with
+	WhatIf *PermissionChanges `json:"whatIf,omitempty"`
+}
+
+// PermissionChanges are changes to the roles and the teams of a user that are not saved.
+type PermissionChanges struct {
+	OrgRole        string  `json:"orgRole,omitempty"`
+	IsGrafanaAdmin *bool   `json:"isGrafanaAdmin,omitempty"`
+	AddTeams       []int64 `json:"addTeams,omitempty"`
+	RemoveTeams    []int64 `json:"removeTeams,omitempty"`
+	// AddRoles and RemoveRoles are names of roles, like fixed:dashboards:writer
+	AddRoles       []string     `json:"addRoles,omitempty"`
+	RemoveRoles    []string     `json:"removeRoles,omitempty"`
+	AddPermissions []Permission `json:"addPermissions,omitempty"`
+}
+
+// PermissionSubject is the user a decision is explained for.
+type PermissionSubject struct {
+	UserID           int64   `json:"userId" xorm:"id"`
+	Login            string  `json:"login"`
+	IsServiceAccount bool    `json:"isServiceAccount"`
+	IsGrafanaAdmin   bool    `json:"isGrafanaAdmin" xorm:"is_admin"`
+	OrgRole          string  `json:"orgRole" xorm:"role"`
+	Teams            []int64 `json:"teams" xorm:"-"`
+}
+
+// BasicRoles returns the basic roles of the subject, like GetOrgRoles does for a signed in user.
+func (s PermissionSubject) BasicRoles(orgID int64) []string {
+	roles := []string{}
+	if s.OrgRole != "" {
+		roles = append(roles, s.OrgRole)
+	}
+	if s.IsGrafanaAdmin {
+		if orgID == GlobalOrgID {
+			return []string{RoleGrafanaAdmin, string(org.RoleAdmin)}
+		}
+		roles = append(roles, RoleGrafanaAdmin)
+	}
+	return roles
+}
+
+type PermissionExplanation struct {
+	Subject PermissionSubject `json:"subject"`
+	Action  string            `json:"action"`
+	Scope   string            `json:"scope"`
+	Allowed bool              `json:"allowed"`
+	// ResolvedScopes are the scope and the scopes it inherits permissions from, like the parent folders of a dashboard
+	ResolvedScopes []string `json:"resolvedScopes"`
+	// Grants are the permissions that allow the action on the scope
+	Grants []PermissionGrant `json:"grants"`
+	// OtherGrants are the permissions of the user for the action on other scopes
+	OtherGrants []PermissionGrant `json:"otherGrants"`
+	// WhatIf is the explanation of the decision with the proposed changes
+	WhatIf *PermissionExplanation `json:"whatIf,omitempty"`
+}
+
+// PermissionGrant is a permission of a user and the role assignment it comes from.
+type PermissionGrant struct {
+	// Action is the action of the permission, or an action set that includes the action
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+	// MatchedScope is the resolved scope the permission matches
+	MatchedScope    string `json:"matchedScope,omitempty" xorm:"-"`
+	RoleName        string `json:"roleName,omitempty"`
+	RoleUID         string `json:"roleUid,omitempty" xorm:"role_uid"`
+	RoleDisplayName string `json:"roleDisplayName,omitempty"`
+	// Managed is true for the permissions set on resources, like the permissions of a folder
+	Managed   bool   `json:"managed" xorm:"-"`
+	Source    string `json:"source"`
+	TeamID    int64  `json:"teamId,omitempty" xorm:"team_id"`
+	TeamName  string `json:"teamName,omitempty"`
+	BasicRole string `json:"basicRole,omitempty"`
+}
+
+type GetPermissionGrantsQuery struct {
+	OrgID        int64
+	UserID       int64
+	TeamIDs      []int64
+	Roles        []string
+	Actions      []string
+	RolePrefixes []string
+}
@@ -0,0 +1,52 @@
+package accessrequests
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	// ActionCreate allows requesting access for oneself.
+	ActionCreate = "access.requests:create"
+	// ActionRead allows listing and reading the requests of all users of the organization.
+	ActionRead = "access.requests:read"
+	// ActionApprove allows approving, denying and revoking requests. Approvers also need the
+	// permission to grant the requested access.
+	ActionApprove = "access.requests:approve"
+)
+
+var (
+	requesterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:access.requests:requester",
+		DisplayName: "Access requester",
+		Description: "Request temporary roles and permissions",
+		Group:       "Access requests",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionCreate},
+		},
+	}
+
+	approverRole = accesscontrol.RoleDTO{
+		Name:        "fixed:access.requests:approver",
+		DisplayName: "Access request approver",
+		Description: "List, read, approve, deny and revoke requests of temporary roles and permissions",
+		Group:       "Access requests",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead},
+			{Action: ActionApprove},
+		},
+	}
+)
+
+func declareFixedRoles(ac accesscontrol.Service, approverBasicRole string) error {
+	requester := accesscontrol.RoleRegistration{
+		Role:   requesterRole,
+		Grants: []string{string(org.RoleViewer)},
+	}
+	approver := accesscontrol.RoleRegistration{
+		Role:   approverRole,
+		Grants: []string{approverBasicRole},
+	}
+
+	return ac.DeclareFixedRoles(requester, approver)
+}
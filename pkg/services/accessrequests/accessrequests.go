@@ -0,0 +1,350 @@
+// Package accessrequests lets users request a basic role, or a managed permission on a folder or
+// dashboard, for a limited time. Approved requests are granted until they expire, and the previous
+// access of the user is restored afterwards.
+package accessrequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/login/social"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// expiryInterval is how often the access of expired requests is revoked.
+const expiryInterval = time.Minute
+
+type Service struct {
+	cfg                  *setting.Cfg
+	store                *store
+	log                  log.Logger
+	routeRegister        routing.RouteRegister
+	accessControl        ac.AccessControl
+	orgService           org.Service
+	userService          user.Service
+	folderPermissions    ac.PermissionsService
+	dashboardPermissions ac.PermissionsService
+	notificationService  notifications.EmailSender
+	auditLog             auditlog.Service
+	authInfoService      login.AuthInfoService
+	socialService        social.Service
+	now                  func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	accessControlService ac.Service, orgService org.Service, userService user.Service,
+	folderPermissions ac.FolderPermissionsService, dashboardPermissions ac.DashboardPermissionsService,
+	notificationService notifications.EmailSender, auditLog auditlog.Service, authInfoService login.AuthInfoService,
+	socialService social.Service) (*Service, error) {
+	s := &Service{
+		cfg:                  cfg,
+		store:                &store{db: sqlStore},
+		log:                  log.New("accessrequests"),
+		routeRegister:        routeRegister,
+		accessControl:        accessControl,
+		orgService:           orgService,
+		userService:          userService,
+		folderPermissions:    folderPermissions,
+		dashboardPermissions: dashboardPermissions,
+		notificationService:  notificationService,
+		auditLog:             auditLog,
+		authInfoService:      authInfoService,
+		socialService:        socialService,
+		now:                  time.Now,
+	}
+
+	if !cfg.AccessRequests.Enabled {
+		return s, nil
+	}
+	if err := declareFixedRoles(accessControlService, cfg.AccessRequests.ApproverRole); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.AccessRequests.Enabled
+}
+
+// Run revokes the access of the requests when they expire.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.expire(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) expire(ctx context.Context) {
+	requests, err := s.store.listExpired(ctx, s.now())
+	if err != nil {
+		s.log.Error("Failed to list expired access requests", "error", err)
+		return
+	}
+	for _, request := range requests {
+		if err := s.end(ctx, request, StatusExpired, 0); err != nil {
+			// The access is revoked again on the next run.
+			s.log.Error("Failed to revoke expired access", "orgId", request.OrgID, "uid", request.UID, "error", err)
+		}
+	}
+}
+
+func (s *Service) GetRequests(ctx context.Context, query GetRequestsQuery) ([]*AccessRequest, error) {
+	return s.store.list(ctx, query)
+}
+
+func (s *Service) GetRequest(ctx context.Context, orgID int64, uid string) (*AccessRequest, error) {
+	return s.store.get(ctx, orgID, uid)
+}
+
+// CreateRequest creates a pending request and notifies the approvers of the organization.
+func (s *Service) CreateRequest(ctx context.Context, cmd CreateRequestCommand) (*AccessRequest, error) {
+	request, err := s.newRequest(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if request.isRoleRequest() {
+		if err := s.checkRoleNotSynced(ctx, request.UserID); err != nil {
+			return nil, err
+		}
+		current, err := s.currentRole(ctx, request.OrgID, request.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if current.Includes(request.Role) {
+			return nil, ErrAlreadyGranted
+		}
+	}
+	open, err := s.store.isOpen(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, ErrAlreadyRequested
+	}
+
+	if err := s.store.create(ctx, request); err != nil {
+		return nil, err
+	}
+	s.log.Info("Access requested", "orgId", request.OrgID, "uid", request.UID, "userId", request.UserID, "access", request.target())
+	s.notifyApprovers(ctx, request)
+	return request, nil
+}
+
+func (s *Service) newRequest(cmd CreateRequestCommand) (*AccessRequest, error) {
+	justification := strings.TrimSpace(cmd.Justification)
+	if justification == "" {
+		return nil, ErrJustificationRequired
+	}
+	duration, err := gtime.ParseDuration(cmd.Duration)
+	if err != nil || duration < time.Minute || duration > s.cfg.AccessRequests.MaxDuration {
+		return nil, fmt.Errorf("%w: must be between 1m and %s", ErrInvalidDuration, s.cfg.AccessRequests.MaxDuration)
+	}
+
+	request := &AccessRequest{
+		UID:           util.GenerateShortUID(),
+		OrgID:         cmd.OrgID,
+		UserID:        cmd.UserID,
+		Justification: justification,
+		Duration:      int64(duration / time.Second),
+		Status:        StatusPending,
+		Created:       s.now(),
+		Updated:       s.now(),
+	}
+
+	switch {
+	case cmd.Role != "" && cmd.Resource == "":
+		if !cmd.Role.IsValid() || cmd.Role == org.RoleNone {
+			return nil, fmt.Errorf("%w: invalid role %q", ErrInvalidRequest, cmd.Role)
+		}
+		request.Role = cmd.Role
+	case cmd.Role == "" && cmd.Resource != "":
+		if cmd.Resource != ResourceFolders && cmd.Resource != ResourceDashboards {
+			return nil, ErrUnsupportedResource
+		}
+		if cmd.ResourceUID == "" {
+			return nil, fmt.Errorf("%w: resourceUid is required", ErrInvalidRequest)
+		}
+		switch cmd.Permission {
+		case "View", "Edit", "Admin":
+		default:
+			return nil, ErrInvalidPermissionLevel
+		}
+		request.Resource = cmd.Resource
+		request.ResourceUID = cmd.ResourceUID
+		request.Permission = cmd.Permission
+	default:
+		return nil, fmt.Errorf("%w: either a role or a resource permission must be requested", ErrInvalidRequest)
+	}
+	return request, nil
+}
+
+// Approve grants the requested access until the request expires.
+func (s *Service) Approve(ctx context.Context, cmd ReviewCommand) (*AccessRequest, error) {
+	request, err := s.review(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	expiresAt := now.Add(time.Duration(request.Duration) * time.Second)
+	request.Status = StatusActive
+	request.ReviewedAt = &now
+	request.ExpiresAt = &expiresAt
+	request.Updated = now
+	// The request is marked first so that a single review grants the access.
+	if err := s.store.transition(ctx, request, StatusPending); err != nil {
+		return nil, err
+	}
+	if err := s.grant(ctx, request); err != nil {
+		request.Status = StatusPending
+		request.ReviewedBy = 0
+		request.ReviewComment = ""
+		request.ReviewedAt = nil
+		request.ExpiresAt = nil
+		if rollbackErr := s.store.transition(ctx, request, StatusActive); rollbackErr != nil {
+			s.log.Error("Failed to reset access request", "orgId", request.OrgID, "uid", request.UID, "error", rollbackErr)
+		}
+		return nil, err
+	}
+	// Save the previous access of the user, which is restored when the request ends.
+	if err := s.store.transition(ctx, request, StatusActive); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Access request approved", "orgId", request.OrgID, "uid", request.UID, "userId", request.UserID, "reviewerId", cmd.ReviewerID, "expiresAt", expiresAt)
+	s.notifyRequester(ctx, request)
+	return request, nil
+}
+
+// Deny rejects a pending request.
+func (s *Service) Deny(ctx context.Context, cmd ReviewCommand) (*AccessRequest, error) {
+	request, err := s.review(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	request.Status = StatusDenied
+	request.ReviewedAt = &now
+	request.Updated = now
+	if err := s.store.transition(ctx, request, StatusPending); err != nil {
+		return nil, err
+	}
+
+	s.log.Info("Access request denied", "orgId", request.OrgID, "uid", request.UID, "userId", request.UserID, "reviewerId", cmd.ReviewerID)
+	s.notifyRequester(ctx, request)
+	return request, nil
+}
+
+func (s *Service) review(ctx context.Context, cmd ReviewCommand) (*AccessRequest, error) {
+	request, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != StatusPending {
+		return nil, ErrInvalidStatus
+	}
+	if request.UserID == cmd.ReviewerID {
+		return nil, ErrSelfApproval
+	}
+	request.ReviewedBy = cmd.ReviewerID
+	request.ReviewComment = cmd.Comment
+	return request, nil
+}
+
+// Cancel withdraws a pending request.
+func (s *Service) Cancel(ctx context.Context, orgID int64, uid string) (*AccessRequest, error) {
+	request, err := s.store.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != StatusPending {
+		return nil, ErrInvalidStatus
+	}
+
+	request.Status = StatusCancelled
+	request.Updated = s.now()
+	if err := s.store.transition(ctx, request, StatusPending); err != nil {
+		return nil, err
+	}
+	s.log.Info("Access request cancelled", "orgId", request.OrgID, "uid", request.UID, "userId", request.UserID)
+	return request, nil
+}
+
+// Revoke ends an active request before it expires.
+func (s *Service) Revoke(ctx context.Context, orgID int64, uid string, userID int64) (*AccessRequest, error) {
+	request, err := s.store.get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != StatusActive {
+		return nil, ErrInvalidStatus
+	}
+	if err := s.end(ctx, request, StatusRevoked, userID); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// end marks an active request as expired or revoked and restores the previous access of the user.
+// The request is marked first so that a single Grafana instance restores the access.
+func (s *Service) end(ctx context.Context, request *AccessRequest, status Status, endedBy int64) error {
+	now := s.now()
+	request.Status = status
+	request.EndedBy = endedBy
+	request.EndedAt = &now
+	request.Updated = now
+	if err := s.store.transition(ctx, request, StatusActive); err != nil {
+		if errors.Is(err, ErrInvalidStatus) && status == StatusExpired {
+			return nil
+		}
+		return err
+	}
+
+	if err := s.revoke(ctx, request); err != nil {
+		request.Status = StatusActive
+		request.EndedBy = 0
+		request.EndedAt = nil
+		if rollbackErr := s.store.transition(ctx, request, status); rollbackErr != nil {
+			s.log.Error("Failed to reactivate access request", "orgId", request.OrgID, "uid", request.UID, "error", rollbackErr)
+		}
+		return err
+	}
+
+	s.log.Info("Access ended", "orgId", request.OrgID, "uid", request.UID, "userId", request.UserID, "status", status, "endedBy", endedBy)
+	if status == StatusExpired {
+		// Revocations by users are recorded by the audit log middleware.
+		s.auditLog.Record(ctx, &auditlog.Event{
+			OrgID:        request.OrgID,
+			Source:       auditlog.SourceSystem,
+			ActorLogin:   "grafana",
+			Action:       auditlog.ActionUpdate,
+			ResourceKind: "access-requests",
+			ResourceID:   request.UID,
+			AfterHash:    auditlog.Hash(request),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package accessrequests
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestNewRequest(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.AccessRequests.MaxDuration = 8 * time.Hour
+	s := &Service{cfg: cfg, now: time.Now}
+
+	testCases := []struct {
+		desc string
+		cmd  CreateRequestCommand
+		err  error
+	}{
+		{
+			desc: "role request",
+			cmd:  CreateRequestCommand{Role: org.RoleEditor, Justification: "incident", Duration: "1h"},
+		},
+		{
+			desc: "folder permission request",
+			cmd:  CreateRequestCommand{Resource: ResourceFolders, ResourceUID: "folder", Permission: "Edit", Justification: "incident", Duration: "30m"},
+		},
+		{
+			desc: "justification is required",
+			cmd:  CreateRequestCommand{Role: org.RoleEditor, Justification: "  ", Duration: "1h"},
+			err:  ErrJustificationRequired,
+		},
+		{
+			desc: "duration can't exceed the maximum duration",
+			cmd:  CreateRequestCommand{Role: org.RoleEditor, Justification: "incident", Duration: "9h"},
+			err:  ErrInvalidDuration,
+		},
+		{
+			desc: "duration is at least a minute",
+			cmd:  CreateRequestCommand{Role: org.RoleEditor, Justification: "incident", Duration: "10s"},
+			err:  ErrInvalidDuration,
+		},
+		{
+			desc: "role and resource can't be requested together",
+			cmd:  CreateRequestCommand{Role: org.RoleEditor, Resource: ResourceFolders, ResourceUID: "folder", Permission: "Edit", Justification: "incident", Duration: "1h"},
+			err:  ErrInvalidRequest,
+		},
+		{
+			desc: "None role can't be requested",
+			cmd:  CreateRequestCommand{Role: org.RoleNone, Justification: "incident", Duration: "1h"},
+			err:  ErrInvalidRequest,
+		},
+		{
+			desc: "only folders and dashboards are supported",
+			cmd:  CreateRequestCommand{Resource: "datasources", ResourceUID: "ds", Permission: "Query", Justification: "incident", Duration: "1h"},
+			err:  ErrUnsupportedResource,
+		},
+		{
+			desc: "permission must be a managed permission level",
+			cmd:  CreateRequestCommand{Resource: ResourceDashboards, ResourceUID: "dash", Permission: "Owner", Justification: "incident", Duration: "1h"},
+			err:  ErrInvalidPermissionLevel,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			request, err := s.newRequest(tc.cmd)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, StatusPending, request.Status)
+			require.NotEmpty(t, request.UID)
+		})
+	}
+}
+
+func TestCheckRoleNotSynced(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.LDAPAuthEnabled = true
+	s := &Service{cfg: cfg, socialService: &socialtest.FakeSocialService{}}
+
+	s.authInfoService = &authinfotest.FakeService{ExpectedError: user.ErrUserNotFound}
+	require.NoError(t, s.checkRoleNotSynced(context.Background(), 1))
+
+	s.authInfoService = &authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{AuthModule: login.LDAPAuthModule}}
+	require.ErrorIs(t, s.checkRoleNotSynced(context.Background(), 1), ErrRoleSyncedExternally)
+
+	cfg.LDAPSkipOrgRoleSync = true
+	require.NoError(t, s.checkRoleNotSynced(context.Background(), 1))
+}
+
+type recordingPermissionsService struct {
+	actest.FakePermissionsService
+	permissions map[int64]string
+}
+
+func (f *recordingPermissionsService) SetUserPermission(_ context.Context, _ int64, u ac.User, _, permission string) (*ac.ResourcePermission, error) {
+	f.permissions[u.ID] = permission
+	return nil, nil
+}
+
+func TestRevokeRestoresChangedPermission(t *testing.T) {
+	permissions := &recordingPermissionsService{permissions: map[int64]string{}}
+	s := &Service{folderPermissions: permissions}
+	request := &AccessRequest{UserID: 2, Resource: ResourceFolders, ResourceUID: "folder", Permission: "Admin", Previous: "View"}
+
+	// The user changed their own permission with the granted Admin permission.
+	permissions.permissions[2] = "Edit"
+	require.NoError(t, s.revoke(context.Background(), request))
+	require.Equal(t, "View", permissions.permissions[2])
+}
+
+func TestDecisionToken(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.SecretKey = "secret"
+	cfg.AppURL = "http://localhost:3000/"
+	s := &Service{cfg: cfg}
+	request := &AccessRequest{OrgID: 1, UID: "request"}
+
+	token := s.decisionToken(request, decisionApprove, 5)
+	require.True(t, s.validDecisionToken(request, decisionApprove, 5, token))
+	require.False(t, s.validDecisionToken(request, decisionDeny, 5, token))
+	require.False(t, s.validDecisionToken(request, decisionApprove, 6, token))
+	require.False(t, s.validDecisionToken(&AccessRequest{OrgID: 2, UID: "request"}, decisionApprove, 5, token))
+	require.False(t, s.validDecisionToken(request, decisionApprove, 5, ""))
+
+	require.Equal(t, "http://localhost:3000/access-requests/request/approve?orgId=1&token="+token, s.decisionURL(request, decisionApprove, 5))
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := &store{db: db.InitTestDB(t)}
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	request := &AccessRequest{UID: "request", OrgID: 1, UserID: 2, Resource: ResourceFolders, ResourceUID: "folder", Permission: "Edit",
+		Justification: "incident", Duration: 3600, Status: StatusPending, Created: now, Updated: now}
+	require.NoError(t, s.create(ctx, request))
+
+	open, err := s.isOpen(ctx, &AccessRequest{OrgID: 1, UserID: 2, Resource: ResourceFolders, ResourceUID: "folder"})
+	require.NoError(t, err)
+	require.True(t, open)
+	open, err = s.isOpen(ctx, &AccessRequest{OrgID: 1, UserID: 2, Resource: ResourceFolders, ResourceUID: "other"})
+	require.NoError(t, err)
+	require.False(t, open)
+	open, err = s.isOpen(ctx, &AccessRequest{OrgID: 1, UserID: 2, Role: org.RoleEditor})
+	require.NoError(t, err)
+	require.False(t, open)
+
+	// Role requests overlap with any open role request of the user
+	roleRequest := &AccessRequest{UID: "role", OrgID: 1, UserID: 2, Role: org.RoleEditor,
+		Justification: "incident", Duration: 3600, Status: StatusActive, Created: now, Updated: now}
+	require.NoError(t, s.create(ctx, roleRequest))
+	open, err = s.isOpen(ctx, &AccessRequest{OrgID: 1, UserID: 2, Role: org.RoleAdmin})
+	require.NoError(t, err)
+	require.True(t, open)
+	open, err = s.isOpen(ctx, &AccessRequest{OrgID: 1, UserID: 3, Role: org.RoleAdmin})
+	require.NoError(t, err)
+	require.False(t, open)
+	roleRequest.Status = StatusRevoked
+	require.NoError(t, s.transition(ctx, roleRequest, StatusActive))
+
+	// Approve the request
+	expiresAt := now.Add(time.Hour)
+	request.Status = StatusActive
+	request.ExpiresAt = &expiresAt
+	require.NoError(t, s.transition(ctx, request, StatusPending))
+
+	// A concurrent review of the same request fails
+	require.ErrorIs(t, s.transition(ctx, request, StatusPending), ErrInvalidStatus)
+
+	expired, err := s.listExpired(ctx, now.Add(30*time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, expired)
+	expired, err = s.listExpired(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, "request", expired[0].UID)
+
+	requests, err := s.list(ctx, GetRequestsQuery{OrgID: 1, Status: StatusActive})
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	requests, err = s.list(ctx, GetRequestsQuery{OrgID: 1, UserID: 3})
+	require.NoError(t, err)
+	require.Empty(t, requests)
+
+	_, err = s.get(ctx, 2, "request")
+	require.ErrorIs(t, err, ErrRequestNotFound)
+}
+
+func TestDecisionPage(t *testing.T) {
+	resp := decisionPage(http.StatusOK, decisionPageData{
+		Title:   "Approve access request",
+		Details: []string{"Justification: <script>"},
+		Token:   "token",
+		Button:  "Approve",
+	})
+	require.Equal(t, http.StatusOK, resp.Status())
+	body := string(resp.Body())
+	require.Contains(t, body, `<form method="post">`)
+	require.Contains(t, body, `<input type="hidden" name="token" value="token">`)
+	require.Contains(t, body, "Justification: &lt;script&gt;")
+
+	resp = decisionPage(http.StatusConflict, decisionPageData{Title: "Access request already reviewed"})
+	require.Equal(t, http.StatusConflict, resp.Status())
+	require.NotContains(t, string(resp.Body()), "<form")
+}
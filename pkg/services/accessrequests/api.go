@@ -0,0 +1,470 @@
+package accessrequests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/access-requests", func(requests routing.RouteRegister) {
+		requests.Get("/", routing.Wrap(s.getRequestsHandler))
+		requests.Post("/", authorize(ac.EvalPermission(ActionCreate)), routing.Wrap(s.createHandler))
+
+		requests.Group("/:uid", func(request routing.RouteRegister) {
+			request.Get("/", routing.Wrap(s.getRequestHandler))
+			request.Post("/approve", authorize(ac.EvalPermission(ActionApprove)), routing.Wrap(s.approveHandler))
+			request.Post("/deny", authorize(ac.EvalPermission(ActionApprove)), routing.Wrap(s.denyHandler))
+			request.Post("/cancel", routing.Wrap(s.cancelHandler))
+			request.Post("/revoke", routing.Wrap(s.revokeHandler))
+		})
+	}, middleware.ReqSignedIn)
+
+	// Links of the emails sent to the approvers, which redirect to the login page when needed. The
+	// link opens a confirmation page, the decision is only taken when the page is submitted.
+	s.routeRegister.Get("/access-requests/:uid/:decision", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionApprove)), routing.Wrap(s.decisionPageHandler))
+	s.routeRegister.Post("/access-requests/:uid/:decision", middleware.ReqSignedIn, authorize(ac.EvalPermission(ActionApprove)), routing.Wrap(s.decisionLinkHandler))
+}
+
+// swagger:route GET /access-requests access_requests getAccessRequests
+//
+// Get access requests.
+//
+// Users with the `access.requests:read` permission get the requests of all users of the organization, other users get their own requests.
+//
+// Responses:
+// 200: getAccessRequestsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getRequestsHandler(c *contextmodel.ReqContext) response.Response {
+	query := GetRequestsQuery{
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: c.QueryInt64("userId"),
+		Status: Status(c.Query("status")),
+	}
+	canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionRead))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !canRead {
+		userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+		if err != nil {
+			return response.JSON(http.StatusOK, []*AccessRequest{})
+		}
+		query.UserID = userID
+	}
+
+	requests, err := s.GetRequests(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get access requests", err)
+	}
+	return response.JSON(http.StatusOK, requests)
+}
+
+// swagger:route GET /access-requests/{uid} access_requests getAccessRequest
+//
+// Get access request.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getRequestHandler(c *contextmodel.ReqContext) response.Response {
+	request, err := s.GetRequest(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return requestErrorResponse(err, "Failed to get access request")
+	}
+	if !s.isRequester(c, request) {
+		canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionRead))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !canRead {
+			return response.Error(http.StatusNotFound, "Access request not found", nil)
+		}
+	}
+	return response.JSON(http.StatusOK, request)
+}
+
+// swagger:route POST /access-requests access_requests createAccessRequest
+//
+// Request a basic role, or a permission on a folder or dashboard, for a limited time.
+//
+// The approvers of the organization are notified by email.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateRequestCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Access can only be requested by users", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = userID
+
+	request, err := s.CreateRequest(c.Req.Context(), cmd)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to create access request")
+	}
+	return response.JSON(http.StatusOK, request)
+}
+
+// swagger:route POST /access-requests/{uid}/approve access_requests approveAccessRequest
+//
+// Approve access request.
+//
+// The requested access is granted until the request expires. Approvers need the permission to grant the access themselves: `org.users:write` for roles, and the permissions write action of the folder or dashboard for permissions.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) approveHandler(c *contextmodel.ReqContext) response.Response {
+	return s.reviewHandler(c, web.Params(c.Req)[":uid"], s.Approve)
+}
+
+// swagger:route POST /access-requests/{uid}/deny access_requests denyAccessRequest
+//
+// Deny access request.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) denyHandler(c *contextmodel.ReqContext) response.Response {
+	return s.reviewHandler(c, web.Params(c.Req)[":uid"], s.Deny)
+}
+
+func (s *Service) reviewHandler(c *contextmodel.ReqContext, uid string, review func(context.Context, ReviewCommand) (*AccessRequest, error)) response.Response {
+	cmd := ReviewCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	reviewerID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Access requests can only be reviewed by users", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UID = uid
+	cmd.ReviewerID = reviewerID
+
+	if resp := s.checkCanGrant(c, cmd.OrgID, cmd.UID); resp != nil {
+		return resp
+	}
+	request, err := review(c.Req.Context(), cmd)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to review access request")
+	}
+	return response.JSON(http.StatusOK, request)
+}
+
+// swagger:route POST /access-requests/{uid}/cancel access_requests cancelAccessRequest
+//
+// Cancel a pending access request. Only the user who requested the access can cancel it.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) cancelHandler(c *contextmodel.ReqContext) response.Response {
+	orgID, uid := c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]
+	request, err := s.GetRequest(c.Req.Context(), orgID, uid)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to cancel access request")
+	}
+	if !s.isRequester(c, request) {
+		return response.Error(http.StatusForbidden, "Only the user who requested the access can cancel the request", nil)
+	}
+
+	request, err = s.Cancel(c.Req.Context(), orgID, uid)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to cancel access request")
+	}
+	return response.JSON(http.StatusOK, request)
+}
+
+// swagger:route POST /access-requests/{uid}/revoke access_requests revokeAccessRequest
+//
+// Revoke the access of an active request before it expires.
+//
+// The user who requested the access can give it up, approvers need the permission to grant the access themselves. The previous role or permission of the user is restored.
+//
+// Responses:
+// 200: getAccessRequestResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) revokeHandler(c *contextmodel.ReqContext) response.Response {
+	orgID, uid := c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Access can only be revoked by users", err)
+	}
+	request, err := s.GetRequest(c.Req.Context(), orgID, uid)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to revoke access")
+	}
+	if !s.isRequester(c, request) {
+		canApprove, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionApprove))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !canApprove {
+			return response.Error(http.StatusForbidden, "Not allowed to revoke the access", nil)
+		}
+		if resp := s.checkCanGrant(c, orgID, uid); resp != nil {
+			return resp
+		}
+	}
+
+	request, err = s.Revoke(c.Req.Context(), orgID, uid, userID)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to revoke access")
+	}
+	return response.JSON(http.StatusOK, request)
+}
+
+// decisionPageHandler renders the page asking the approver to confirm the decision of the link
+// they followed.
+func (s *Service) decisionPageHandler(c *contextmodel.ReqContext) response.Response {
+	request, decision, resp := s.checkDecisionLink(c, c.Query("token"))
+	if resp != nil {
+		return resp
+	}
+	if request.Status != StatusPending {
+		return decisionPage(http.StatusConflict, decisionPageData{
+			Title:   "Access request already reviewed",
+			Details: []string{fmt.Sprintf("The request for %s is %s.", request.target(), request.Status)},
+		})
+	}
+
+	requester, err := s.userService.GetByID(c.Req.Context(), &user.GetUserByIDQuery{ID: request.UserID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get the user of the access request", err)
+	}
+	title, button := "Approve access request", "Approve"
+	if decision == decisionDeny {
+		title, button = "Deny access request", "Deny"
+	}
+	return decisionPage(http.StatusOK, decisionPageData{
+		Title: title,
+		Details: []string{
+			fmt.Sprintf("%s requests %s.", requester.NameOrFallback(), request.target()),
+			fmt.Sprintf("Duration: %s", time.Duration(request.Duration)*time.Second),
+			"Justification: " + request.Justification,
+		},
+		Token:  c.Query("token"),
+		Button: button,
+	})
+}
+
+// decisionLinkHandler approves or denies a request from the confirmation page of the links sent
+// to the approvers.
+func (s *Service) decisionLinkHandler(c *contextmodel.ReqContext) response.Response {
+	request, decision, resp := s.checkDecisionLink(c, c.Req.FormValue("token"))
+	if resp != nil {
+		return resp
+	}
+	reviewerID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Access requests can only be reviewed by users", err)
+	}
+	if resp := s.checkCanGrant(c, request.OrgID, request.UID); resp != nil {
+		return resp
+	}
+	// The audit log middleware takes the id from the last parameter of the route, which is the decision.
+	auditlog.SetResource(c.Req.Context(), "access-requests", request.UID)
+
+	review, title := s.Approve, "Access request approved"
+	if decision == decisionDeny {
+		review, title = s.Deny, "Access request denied"
+	}
+	request, err = review(c.Req.Context(), ReviewCommand{
+		OrgID:      request.OrgID,
+		UID:        request.UID,
+		ReviewerID: reviewerID,
+		Comment:    c.Req.FormValue("comment"),
+	})
+	if err != nil {
+		return requestErrorResponse(err, "Failed to review access request")
+	}
+	auditlog.SetAfter(c.Req.Context(), request)
+	return decisionPage(http.StatusOK, decisionPageData{
+		Title:   title,
+		Details: []string{fmt.Sprintf("The request for %s is %s.", request.target(), request.Status)},
+	})
+}
+
+// checkDecisionLink returns the request and decision of an email link, or an error response if the
+// link wasn't sent to the signed in user.
+func (s *Service) checkDecisionLink(c *contextmodel.ReqContext, token string) (*AccessRequest, string, response.Response) {
+	orgID, uid, decision := c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], web.Params(c.Req)[":decision"]
+	if decision != decisionApprove && decision != decisionDeny {
+		return nil, "", response.Error(http.StatusNotFound, "Not found", nil)
+	}
+	reviewerID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return nil, "", response.Error(http.StatusBadRequest, "Access requests can only be reviewed by users", err)
+	}
+	request, err := s.GetRequest(c.Req.Context(), orgID, uid)
+	if err != nil {
+		return nil, "", requestErrorResponse(err, "Failed to review access request")
+	}
+	if !s.validDecisionToken(request, decision, reviewerID, token) {
+		return nil, "", response.Error(http.StatusBadRequest, ErrInvalidDecisionToken.Error(), nil)
+	}
+	return request, decision, nil
+}
+
+func (s *Service) isRequester(c *contextmodel.ReqContext, request *AccessRequest) bool {
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	return err == nil && userID == request.UserID
+}
+
+// checkCanGrant returns a forbidden response if the signed in user can't give the requested access themselves.
+func (s *Service) checkCanGrant(c *contextmodel.ReqContext, orgID int64, uid string) response.Response {
+	request, err := s.GetRequest(c.Req.Context(), orgID, uid)
+	if err != nil {
+		return requestErrorResponse(err, "Failed to get access request")
+	}
+	ok, err := s.canGrant(c.Req.Context(), c.SignedInUser, request)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "Not allowed to grant the requested access", nil)
+	}
+	return nil
+}
+
+func requestErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrRequestNotFound):
+		return response.Error(http.StatusNotFound, "Access request not found", err)
+	case errors.Is(err, ErrAlreadyRequested), errors.Is(err, ErrInvalidStatus):
+		return response.Error(http.StatusConflict, err.Error(), err)
+	case errors.Is(err, ErrInvalidRequest), errors.Is(err, ErrJustificationRequired), errors.Is(err, ErrInvalidDuration),
+		errors.Is(err, ErrAlreadyGranted), errors.Is(err, ErrUnsupportedResource), errors.Is(err, ErrInvalidPermissionLevel),
+		errors.Is(err, ErrRoleSyncedExternally):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	case errors.Is(err, ErrSelfApproval):
+		return response.Error(http.StatusForbidden, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters getAccessRequest approveAccessRequest denyAccessRequest cancelAccessRequest revokeAccessRequest
+type AccessRequestUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getAccessRequests
+type GetAccessRequestsParams struct {
+	// in:query
+	UserID int64 `json:"userId"`
+	// in:query
+	Status Status `json:"status"`
+}
+
+// swagger:parameters createAccessRequest
+type CreateAccessRequestParams struct {
+	// in:body
+	// required:true
+	Body CreateRequestCommand `json:"body"`
+}
+
+// swagger:parameters approveAccessRequest denyAccessRequest
+type ReviewAccessRequestParams struct {
+	// in:body
+	Body ReviewCommand `json:"body"`
+}
+
+// swagger:response getAccessRequestsResponse
+type GetAccessRequestsResponse struct {
+	// in: body
+	Body []AccessRequest `json:"body"`
+}
+
+// swagger:response getAccessRequestResponse
+type GetAccessRequestResponse struct {
+	// in: body
+	Body AccessRequest `json:"body"`
+}
+
+type decisionPageData struct {
+	Title   string
+	Details []string
+	// Token and Button are set when the page asks to confirm the decision.
+	Token  string
+	Button string
+}
+
+// decisionPageTemplate submits the confirmation form to the URL of the page itself.
+var decisionPageTemplate = template.Must(template.New("decision").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Grafana</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Details}}<p>{{.}}</p>
+{{end}}{{if .Button}}<form method="post">
+<input type="hidden" name="token" value="{{.Token}}">
+<p><label for="comment">Comment</label><br><textarea id="comment" name="comment" rows="3" cols="60"></textarea></p>
+<button type="submit">{{.Button}}</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+func decisionPage(status int, data decisionPageData) response.Response {
+	var buf bytes.Buffer
+	if err := decisionPageTemplate.Execute(&buf, data); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to render access request page", err)
+	}
+	return response.Respond(status, buf.Bytes()).SetHeader("Content-Type", "text/html; charset=UTF-8")
+}
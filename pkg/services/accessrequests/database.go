@@ -0,0 +1,92 @@
+package accessrequests
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) create(ctx context.Context, request *AccessRequest) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(request)
+		return err
+	})
+}
+
+func (s *store) get(ctx context.Context, orgID int64, uid string) (*AccessRequest, error) {
+	request := &AccessRequest{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(request)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrRequestNotFound
+		}
+		return nil
+	})
+	return request, err
+}
+
+func (s *store) list(ctx context.Context, query GetRequestsQuery) ([]*AccessRequest, error) {
+	requests := make([]*AccessRequest, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.UserID != 0 {
+			sess.And("user_id = ?", query.UserID)
+		}
+		if query.Status != "" {
+			sess.And("status = ?", query.Status)
+		}
+		return sess.Desc("created").Find(&requests)
+	})
+	return requests, err
+}
+
+// isOpen returns true if the user has a pending or active request which changes the same access: any
+// role request for a role request, or a permission request on the same resource for a permission request.
+// Overlapping requests would restore the access granted by another request when they end.
+func (s *store) isOpen(ctx context.Context, request *AccessRequest) (bool, error) {
+	var open bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ? AND user_id = ?", request.OrgID, request.UserID)
+		if request.isRoleRequest() {
+			sess.And("role <> ''")
+		} else {
+			sess.And("resource = ? AND resource_uid = ?", request.Resource, request.ResourceUID)
+		}
+		var err error
+		open, err = sess.In("status", StatusPending, StatusActive).Exist(&AccessRequest{})
+		return err
+	})
+	return open, err
+}
+
+// transition saves the request if its status in the database is still from. It returns
+// ErrInvalidStatus when the request was changed meanwhile, e.g. by another Grafana instance.
+func (s *store) transition(ctx context.Context, request *AccessRequest, from Status) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND status = ?", request.ID, from).AllCols().Update(request)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrInvalidStatus
+		}
+		return nil
+	})
+}
+
+// listExpired returns the active requests of all organizations which expired before now.
+func (s *store) listExpired(ctx context.Context, now time.Time) ([]*AccessRequest, error) {
+	requests := make([]*AccessRequest, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("status = ? AND expires_at <= ?", StatusActive, now).Find(&requests)
+	})
+	return requests, err
+}
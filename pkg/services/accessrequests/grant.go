@@ -0,0 +1,130 @@
+package accessrequests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// grant gives the requested access to the user and records their previous role or permission.
+func (s *Service) grant(ctx context.Context, request *AccessRequest) error {
+	if request.isRoleRequest() {
+		if err := s.checkRoleNotSynced(ctx, request.UserID); err != nil {
+			return err
+		}
+		current, err := s.currentRole(ctx, request.OrgID, request.UserID)
+		if err != nil {
+			return err
+		}
+		if current.Includes(request.Role) {
+			return ErrAlreadyGranted
+		}
+		request.Previous = string(current)
+		return s.orgService.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: request.OrgID, UserID: request.UserID, Role: request.Role})
+	}
+
+	permissions := s.permissionsService(request.Resource)
+	current, err := s.currentPermission(ctx, permissions, request)
+	if err != nil {
+		return err
+	}
+	request.Previous = current
+	_, err = permissions.SetUserPermission(ctx, request.OrgID, ac.User{ID: request.UserID}, request.ResourceUID, request.Permission)
+	return err
+}
+
+// revoke restores the previous role or permission of the user. It is restored even when the access
+// was changed since it was granted, otherwise users given the Admin permission on a resource could
+// change their own permission and keep it after the request ends.
+func (s *Service) revoke(ctx context.Context, request *AccessRequest) error {
+	if request.isRoleRequest() {
+		return s.orgService.UpdateOrgUser(ctx, &org.UpdateOrgUserCommand{OrgID: request.OrgID, UserID: request.UserID, Role: org.RoleType(request.Previous)})
+	}
+
+	permissions := s.permissionsService(request.Resource)
+	_, err := permissions.SetUserPermission(ctx, request.OrgID, ac.User{ID: request.UserID}, request.ResourceUID, request.Previous)
+	return err
+}
+
+// checkRoleNotSynced fails when the role of the user is synced from an external authentication
+// provider, since the granted role would be replaced on their next login.
+func (s *Service) checkRoleNotSynced(ctx context.Context, userID int64) error {
+	authInfo, err := s.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: userID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+	if authInfo.AuthModule == "" {
+		return nil
+	}
+	if login.IsExternallySynced(s.cfg, authInfo.AuthModule, s.socialService.GetOAuthInfoProvider(authInfo.AuthModule)) {
+		return ErrRoleSyncedExternally
+	}
+	return nil
+}
+
+// currentRole returns the basic role of the user in the organization.
+func (s *Service) currentRole(ctx context.Context, orgID, userID int64) (org.RoleType, error) {
+	users, err := s.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    orgID,
+		UserID:                   userID,
+		DontEnforceAccessControl: true,
+		User:                     ac.BackgroundUser("access_requests", orgID, org.RoleAdmin, nil),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(users) == 0 {
+		return "", fmt.Errorf("user %d is not a member of the organization", userID)
+	}
+	return org.RoleType(users[0].Role), nil
+}
+
+// currentPermission returns the managed permission of the user on the resource of the request,
+// without the permissions inherited from parent folders or granted through teams and roles.
+func (s *Service) currentPermission(ctx context.Context, permissions ac.PermissionsService, request *AccessRequest) (string, error) {
+	requester := ac.BackgroundUser("access_requests", request.OrgID, org.RoleAdmin, []ac.Permission{
+		{Action: request.Resource + ".permissions:read", Scope: request.Resource + ":*"},
+	})
+	resourcePermissions, err := permissions.GetPermissions(ctx, requester, request.ResourceUID)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range resourcePermissions {
+		if p.UserId == request.UserID && p.IsManaged && !p.IsInherited {
+			return permissions.MapActions(p), nil
+		}
+	}
+	return "", nil
+}
+
+func (s *Service) permissionsService(resource string) ac.PermissionsService {
+	if resource == ResourceDashboards {
+		return s.dashboardPermissions
+	}
+	return s.folderPermissions
+}
+
+// canGrant checks that the reviewer can give the requested access themselves.
+func (s *Service) canGrant(ctx context.Context, reviewer identity.Requester, request *AccessRequest) (bool, error) {
+	if request.isRoleRequest() {
+		// Users can't give a role which is higher than their own.
+		if !reviewer.GetIsGrafanaAdmin() && !reviewer.GetOrgRole().Includes(request.Role) {
+			return false, nil
+		}
+		return s.accessControl.Evaluate(ctx, reviewer, ac.EvalPermission(ac.ActionOrgUsersWrite, ac.Scope("users", "id", fmt.Sprint(request.UserID))))
+	}
+	if request.Resource == ResourceDashboards {
+		return s.accessControl.Evaluate(ctx, reviewer, ac.EvalPermission(dashboards.ActionDashboardsPermissionsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(request.ResourceUID)))
+	}
+	return s.accessControl.Evaluate(ctx, reviewer, ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(request.ResourceUID)))
+}
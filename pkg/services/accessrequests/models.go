@@ -0,0 +1,116 @@
+package accessrequests
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	ErrRequestNotFound        = errors.New("access request not found")
+	ErrInvalidRequest         = errors.New("invalid access request")
+	ErrJustificationRequired  = errors.New("justification is required")
+	ErrInvalidDuration        = errors.New("invalid access request duration")
+	ErrAlreadyRequested       = errors.New("another request for the same role or resource is pending or active")
+	ErrAlreadyGranted         = errors.New("the user already has the requested role")
+	ErrRoleSyncedExternally   = errors.New("the role of the user is synced from an external authentication provider")
+	ErrInvalidStatus          = errors.New("the access request can't be changed in its current status")
+	ErrSelfApproval           = errors.New("users can't review their own access requests")
+	ErrInvalidDecisionToken   = errors.New("invalid access request link")
+	ErrUnsupportedResource    = errors.New("unsupported resource, only folders and dashboards are supported")
+	ErrInvalidPermissionLevel = errors.New("invalid permission, only View, Edit and Admin are supported")
+)
+
+type Status string
+
+const (
+	// StatusPending requests wait for a review.
+	StatusPending Status = "pending"
+	// StatusActive requests were approved and the access is granted until they expire.
+	StatusActive Status = "active"
+	// StatusDenied requests were denied by an approver.
+	StatusDenied Status = "denied"
+	// StatusCancelled requests were withdrawn by the requester before a review.
+	StatusCancelled Status = "cancelled"
+	// StatusExpired requests were active until their expiry, the access was revoked.
+	StatusExpired Status = "expired"
+	// StatusRevoked requests were active and ended before their expiry by the requester or an approver.
+	StatusRevoked Status = "revoked"
+)
+
+// Resources which permissions can be requested
+const (
+	ResourceFolders    = "folders"
+	ResourceDashboards = "dashboards"
+)
+
+// AccessRequest is a request of a user for a basic role in an organization, or for a managed
+// permission on a folder or dashboard, for a limited time.
+type AccessRequest struct {
+	ID     int64  `xorm:"pk autoincr 'id'" json:"-"`
+	UID    string `xorm:"uid" json:"uid"`
+	OrgID  int64  `xorm:"org_id" json:"orgId"`
+	UserID int64  `xorm:"user_id" json:"userId"`
+	// Role is the requested basic role, empty for a permission request.
+	Role org.RoleType `xorm:"role" json:"role,omitempty"`
+	// Resource, ResourceUID and Permission are the requested managed permission, e.g. Edit on a folder.
+	Resource      string `xorm:"resource" json:"resource,omitempty"`
+	ResourceUID   string `xorm:"resource_uid" json:"resourceUid,omitempty"`
+	Permission    string `xorm:"permission" json:"permission,omitempty"`
+	Justification string `xorm:"justification" json:"justification"`
+	// Duration of the access in seconds, from the approval.
+	Duration int64  `xorm:"duration" json:"duration"`
+	Status   Status `xorm:"status" json:"status"`
+	// Previous is the basic role or permission of the user before the access was granted, which is
+	// restored when the access ends.
+	Previous      string     `xorm:"previous" json:"previous,omitempty"`
+	ReviewedBy    int64      `xorm:"reviewed_by" json:"reviewedBy,omitempty"`
+	ReviewComment string     `xorm:"review_comment" json:"reviewComment,omitempty"`
+	ReviewedAt    *time.Time `xorm:"reviewed_at" json:"reviewedAt,omitempty"`
+	ExpiresAt     *time.Time `xorm:"expires_at" json:"expiresAt,omitempty"`
+	// EndedBy is the user who revoked the access, 0 when it expired.
+	EndedBy int64      `xorm:"ended_by" json:"endedBy,omitempty"`
+	EndedAt *time.Time `xorm:"ended_at" json:"endedAt,omitempty"`
+	Created time.Time  `json:"created"`
+	Updated time.Time  `json:"updated"`
+}
+
+func (r *AccessRequest) isRoleRequest() bool {
+	return r.Role != ""
+}
+
+// target describes the requested access, e.g. "Editor role" or "Edit permission on folders abc".
+func (r *AccessRequest) target() string {
+	if r.isRoleRequest() {
+		return string(r.Role) + " role"
+	}
+	return r.Permission + " permission on " + r.Resource + " " + r.ResourceUID
+}
+
+type CreateRequestCommand struct {
+	OrgID         int64        `json:"-"`
+	UserID        int64        `json:"-"`
+	Role          org.RoleType `json:"role"`
+	Resource      string       `json:"resource"`
+	ResourceUID   string       `json:"resourceUid"`
+	Permission    string       `json:"permission"`
+	Justification string       `json:"justification"`
+	// Duration is a duration string such as 2h.
+	Duration string `json:"duration"`
+}
+
+// ReviewCommand approves or denies a pending request.
+type ReviewCommand struct {
+	OrgID      int64  `json:"-"`
+	UID        string `json:"-"`
+	ReviewerID int64  `json:"-"`
+	Comment    string `json:"comment"`
+}
+
+type GetRequestsQuery struct {
+	OrgID int64
+	// UserID limits the requests to those of a user, if not 0.
+	UserID int64
+	Status Status
+}
@@ -0,0 +1,124 @@
+package accessrequests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// Decisions of the links sent to the approvers
+const (
+	decisionApprove = "approve"
+	decisionDeny    = "deny"
+)
+
+// notifyApprovers emails the users with the approver role of the organization, with links to
+// approve or deny the request. Every approver gets their own links.
+func (s *Service) notifyApprovers(ctx context.Context, request *AccessRequest) {
+	requester, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: request.UserID})
+	if err != nil {
+		s.log.Error("Failed to get the user of the access request", "orgId", request.OrgID, "uid", request.UID, "error", err)
+		return
+	}
+	users, err := s.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    request.OrgID,
+		DontEnforceAccessControl: true,
+		User:                     ac.BackgroundUser("access_requests", request.OrgID, org.RoleAdmin, nil),
+	})
+	if err != nil {
+		s.log.Error("Failed to get the approvers of the access request", "orgId", request.OrgID, "uid", request.UID, "error", err)
+		return
+	}
+
+	approverRole := org.RoleType(s.cfg.AccessRequests.ApproverRole)
+	notified := 0
+	for _, u := range users {
+		if u.UserID == request.UserID || u.IsDisabled || u.Email == "" || !org.RoleType(u.Role).Includes(approverRole) {
+			continue
+		}
+		s.sendEmail(ctx, request, []string{u.Email}, "Access request from "+requester.Login, map[string]any{
+			"Title": fmt.Sprintf("%s requests %s", requester.NameOrFallback(), request.target()),
+			"Message": fmt.Sprintf("Duration: %s. Justification: %s",
+				time.Duration(request.Duration)*time.Second, request.Justification),
+			"ApproveURL": s.decisionURL(request, decisionApprove, u.UserID),
+			"DenyURL":    s.decisionURL(request, decisionDeny, u.UserID),
+		})
+		notified++
+	}
+	if notified == 0 {
+		s.log.Warn("No approver to notify of the access request", "orgId", request.OrgID, "uid", request.UID)
+	}
+}
+
+// notifyRequester emails the user of a reviewed request.
+func (s *Service) notifyRequester(ctx context.Context, request *AccessRequest) {
+	requester, err := s.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: request.UserID})
+	if err != nil {
+		s.log.Error("Failed to get the user of the access request", "orgId", request.OrgID, "uid", request.UID, "error", err)
+		return
+	}
+	if requester.Email == "" {
+		return
+	}
+
+	message := ""
+	if request.Status == StatusActive && request.ExpiresAt != nil {
+		message = fmt.Sprintf("The access is granted until %s.", request.ExpiresAt.UTC().Format(time.RFC1123))
+	}
+	if request.ReviewComment != "" {
+		message += " Comment: " + request.ReviewComment
+	}
+	s.sendEmail(ctx, request, []string{requester.Email}, "Access request "+string(request.Status), map[string]any{
+		"Title":   fmt.Sprintf("Your request for %s was %s", request.target(), reviewOutcome(request.Status)),
+		"Message": message,
+	})
+}
+
+func (s *Service) sendEmail(ctx context.Context, request *AccessRequest, to []string, subject string, data map[string]any) {
+	err := s.notificationService.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       to,
+		Template: "access_request",
+		Subject:  subject,
+		Data:     data,
+	})
+	if err != nil {
+		s.log.Error("Failed to send access request email", "orgId", request.OrgID, "uid", request.UID, "error", err)
+	}
+}
+
+func reviewOutcome(status Status) string {
+	if status == StatusActive {
+		return "approved"
+	}
+	return string(status)
+}
+
+// decisionURL returns the link which opens the page to approve or deny the request. The token
+// binds the link to the approver it was sent to, who still needs to be signed in with the
+// permission to review the request.
+func (s *Service) decisionURL(request *AccessRequest, decision string, reviewerID int64) string {
+	query := url.Values{}
+	query.Set("orgId", fmt.Sprint(request.OrgID))
+	query.Set("token", s.decisionToken(request, decision, reviewerID))
+	return fmt.Sprintf("%saccess-requests/%s/%s?%s", s.cfg.AppURL, request.UID, decision, query.Encode())
+}
+
+func (s *Service) decisionToken(request *AccessRequest, decision string, reviewerID int64) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.SecretKey))
+	_, _ = fmt.Fprintf(mac, "%d:%s:%s:%d", request.OrgID, request.UID, decision, reviewerID)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) validDecisionToken(request *AccessRequest, decision string, reviewerID int64, token string) bool {
+	expected := s.decisionToken(request, decision, reviewerID)
+	return hmac.Equal([]byte(expected), []byte(token))
+}
@@ -133,13 +133,28 @@ func testIntegrationApiKeyDataAccess(t *testing.T, fn getStore) {
 
 			assert.Nil(t, key.LastUsedAt)
 
-			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID)
+			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID, "10.0.0.1")
 			require.NoError(t, err)
 
 			query := apikey.GetByNameQuery{KeyName: "last-update-at", OrgID: 1}
 			key, err = ss.GetApiKeyByName(context.Background(), &query)
 			assert.Nil(t, err)
 			assert.NotNil(t, key.LastUsedAt)
+			assert.Equal(t, "10.0.0.1", key.LastUsedIP)
+		})
+
+		t.Run("Add a key restricted to permissions", func(t *testing.T) {
+			permissions := []apikey.Permission{
+				{Action: "dashboards:read", Scope: "dashboards:uid:abc"},
+				{Action: "folders:read"},
+			}
+			cmd := apikey.AddCommand{OrgID: 1, Name: "restricted", Key: "asd4", Permissions: permissions}
+			_, err := ss.AddAPIKey(context.Background(), &cmd)
+			require.NoError(t, err)
+
+			key, err := ss.GetAPIKeyByHash(context.Background(), "asd4")
+			require.NoError(t, err)
+			assert.Equal(t, permissions, key.Permissions)
 		})
 
 		t.Run("Add a key with negative lifespan", func(t *testing.T) {
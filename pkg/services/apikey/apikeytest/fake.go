@@ -34,7 +34,7 @@ func (s *Service) DeleteApiKey(ctx context.Context, cmd *apikey.DeleteCommand) e
 func (s *Service) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) (*apikey.APIKey, error) {
 	return s.ExpectedAPIKey, s.ExpectedError
 }
-func (s *Service) UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64) error {
+func (s *Service) UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64, ip string) error {
 	return s.ExpectedError
 }
 func (s *Service) IsDisabled(ctx context.Context, orgID int64) (bool, error) {
@@ -25,9 +25,26 @@ type APIKey struct {
 	Created          time.Time    `db:"created"`
 	Updated          time.Time    `db:"updated"`
 	LastUsedAt       *time.Time   `xorm:"last_used_at" db:"last_used_at"`
+	LastUsedIP       string       `xorm:"last_used_ip" db:"last_used_ip"`
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
+	RevokedAt        *time.Time   `xorm:"revoked_at" db:"revoked_at"`
+	RevokedReason    string       `xorm:"revoked_reason" db:"revoked_reason"`
+	// Permissions restricts the key to a subset of the permissions of its owner.
+	// An empty list means the key is not restricted.
+	Permissions []Permission `xorm:"permissions" db:"permissions"`
+	// RotationInterval is the number of seconds after which the key is rotated automatically.
+	RotationInterval *int64     `xorm:"rotation_interval" db:"rotation_interval"`
+	RotatedAt        *time.Time `xorm:"rotated_at" db:"rotated_at"`
+}
+
+// Permission is an action, optionally limited to a scope, that a restricted key is allowed to use.
+type Permission struct {
+	// example: dashboards:read
+	Action string `json:"action"`
+	// example: dashboards:uid:abc
+	Scope string `json:"scope,omitempty"`
 }
 
 func (k APIKey) TableName() string { return "api_key" }
@@ -40,6 +57,8 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	Permissions      []Permission `json:"-"`
+	RotationInterval *int64       `json:"-"`
 }
 
 type DeleteCommand struct {
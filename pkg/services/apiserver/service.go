@@ -29,6 +29,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/apiserver/endpoints/request"
 	grafanaapiserveroptions "github.com/grafana/grafana/pkg/services/apiserver/options"
 	"github.com/grafana/grafana/pkg/services/apiserver/utils"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -124,6 +125,8 @@ type service struct {
 	contextProvider datasource.PluginContextWrapper
 	pluginStore     pluginstore.Store
 	unified         resource.ResourceClient
+
+	auditLog auditlog.Service
 }
 
 func ProvideService(
@@ -140,6 +143,7 @@ func ProvideService(
 	contextProvider datasource.PluginContextWrapper,
 	pluginStore pluginstore.Store,
 	unified resource.ResourceClient,
+	auditLog auditlog.Service,
 ) (*service, error) {
 	s := &service{
 		cfg:               cfg,
@@ -159,6 +163,7 @@ func ProvideService(
 		pluginStore:       pluginStore,
 		serverLockService: serverLockService,
 		unified:           unified,
+		auditLog:          auditLog,
 	}
 
 	// This will be used when running as a dskit service
@@ -276,6 +281,10 @@ func (s *service) start(ctx context.Context) error {
 	serverConfig.Authorization.Authorizer = s.authorizer
 	serverConfig.Authentication.Authenticator = authenticator.NewAuthenticator(serverConfig.Authentication.Authenticator)
 	serverConfig.TracerProvider = s.tracing.GetTracerProvider()
+	if s.auditLog.IsEnabled() {
+		serverConfig.AuditBackend = &auditLogBackend{auditLog: s.auditLog}
+		serverConfig.AuditPolicyRuleEvaluator = auditLogPolicy{}
+	}
 
 	// setup loopback transport for the aggregator server
 	transport := &roundTripperFunc{ready: make(chan struct{})}
@@ -0,0 +1,176 @@
+// Package auditlog records the mutating calls to the HTTP and Kubernetes style APIs of Grafana:
+// who did what to which resource, with hashes of the resource before and after the change, so that
+// changes can be audited without storing the resources themselves. Request bodies are not recorded,
+// not even hashed, since they can contain credentials.
+package auditlog
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	ActionRead = "auditlogs:read"
+)
+
+// Sources of events
+const (
+	SourceAPI        = "api"
+	SourceKubernetes = "k8s"
+	// SourceSystem events are changes made by Grafana itself, e.g. by background jobs
+	SourceSystem = "system"
+)
+
+// Actions of events
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+var ErrInvalidSearchQuery = errors.New("invalid audit log search query")
+
+type Service interface {
+	// IsEnabled returns true if the audit log records events.
+	IsEnabled() bool
+	// Record sends an event to the sinks of the audit log. Events are written asynchronously.
+	Record(ctx context.Context, event *Event)
+	// Search returns the events that were recorded to the database.
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+	// RouteMiddleware returns the middleware that records the mutating calls to the route with the pattern.
+	RouteMiddleware(pattern string) web.Handler
+}
+
+// Event is a mutating call to an API.
+type Event struct {
+	ID      int64     `json:"id" xorm:"pk autoincr 'id'"`
+	OrgID   int64     `json:"orgId" xorm:"org_id"`
+	Created time.Time `json:"created"`
+	// Source is the API the call was made to, api or k8s
+	Source string `json:"source"`
+
+	ActorID    string `json:"actorId" xorm:"actor_id"`
+	ActorLogin string `json:"actorLogin"`
+	RemoteAddr string `json:"remoteAddr"`
+	UserAgent  string `json:"userAgent"`
+
+	// Action is create, update or delete
+	Action       string `json:"action"`
+	ResourceKind string `json:"resourceKind"`
+	ResourceID   string `json:"resourceId" xorm:"resource_id"`
+
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Route     string `json:"route"`
+	Status    int    `json:"status"`
+	RequestID string `json:"requestId" xorm:"request_id"`
+
+	// BeforeHash and AfterHash are the SHA-256 hashes of the resource before and after the change,
+	// when the API records them
+	BeforeHash string `json:"beforeHash,omitempty"`
+	AfterHash  string `json:"afterHash,omitempty"`
+}
+
+type SearchQuery struct {
+	OrgID        int64
+	From         time.Time
+	To           time.Time
+	Actor        string
+	Action       string
+	ResourceKind string
+	ResourceID   string
+	Page         int
+	Limit        int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+// ActionFromMethod returns the action of a call with an HTTP method.
+func ActionFromMethod(method string) string {
+	switch method {
+	case "POST":
+		return ActionCreate
+	case "DELETE":
+		return ActionDelete
+	default:
+		return ActionUpdate
+	}
+}
+
+// Hash returns the SHA-256 hash of the JSON encoding of a value, or an empty string for nil values.
+func Hash(v any) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return HashBytes(b)
+}
+
+// HashBytes returns the hex encoded SHA-256 hash of the bytes.
+func HashBytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+type annotationsKey struct{}
+
+// Annotations are the details that the handler of a request adds to the event of the request.
+type Annotations struct {
+	ResourceKind string
+	ResourceID   string
+	BeforeHash   string
+	AfterHash    string
+}
+
+// WithAnnotations returns a context that the handler of a request can annotate the event of the request with.
+func WithAnnotations(ctx context.Context) (context.Context, *Annotations) {
+	annotations := &Annotations{}
+	return context.WithValue(ctx, annotationsKey{}, annotations), annotations
+}
+
+func annotationsFromContext(ctx context.Context) *Annotations {
+	annotations, _ := ctx.Value(annotationsKey{}).(*Annotations)
+	return annotations
+}
+
+// IsRecording returns true if the request of the context is recorded, handlers can use it to skip
+// fetching the resource before a change when it is not.
+func IsRecording(ctx context.Context) bool {
+	return annotationsFromContext(ctx) != nil
+}
+
+// SetResource sets the resource that a request changes, when it can't be derived from the route of the request.
+func SetResource(ctx context.Context, kind, id string) {
+	if annotations := annotationsFromContext(ctx); annotations != nil {
+		annotations.ResourceKind = kind
+		annotations.ResourceID = id
+	}
+}
+
+// SetBefore records the hash of a resource before it is changed by a request.
+func SetBefore(ctx context.Context, resource any) {
+	if annotations := annotationsFromContext(ctx); annotations != nil {
+		annotations.BeforeHash = Hash(resource)
+	}
+}
+
+// SetAfter records the hash of a resource after it is changed by a request.
+func SetAfter(ctx context.Context, resource any) {
+	if annotations := annotationsFromContext(ctx); annotations != nil {
+		annotations.AfterHash = Hash(resource)
+	}
+}
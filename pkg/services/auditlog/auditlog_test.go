@@ -0,0 +1,48 @@
+package auditlog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionFromMethod(t *testing.T) {
+	assert.Equal(t, ActionCreate, ActionFromMethod("POST"))
+	assert.Equal(t, ActionUpdate, ActionFromMethod("PUT"))
+	assert.Equal(t, ActionUpdate, ActionFromMethod("PATCH"))
+	assert.Equal(t, ActionDelete, ActionFromMethod("DELETE"))
+}
+
+func TestHash(t *testing.T) {
+	assert.Empty(t, Hash(nil))
+	assert.Equal(t, HashBytes([]byte(`{"name":"prometheus"}`)), Hash(map[string]string{"name": "prometheus"}))
+	assert.NotEqual(t, Hash(map[string]string{"name": "prometheus"}), Hash(map[string]string{"name": "loki"}))
+	assert.Len(t, HashBytes(nil), 64)
+}
+
+func TestAnnotations(t *testing.T) {
+	t.Run("should not annotate requests that are not recorded", func(t *testing.T) {
+		ctx := context.Background()
+		require.False(t, IsRecording(ctx))
+		SetResource(ctx, "datasources", "uid")
+		SetBefore(ctx, "before")
+	})
+
+	t.Run("should annotate requests that are recorded", func(t *testing.T) {
+		ctx, annotations := WithAnnotations(context.Background())
+		require.True(t, IsRecording(ctx))
+
+		SetResource(ctx, "datasources", "uid")
+		SetBefore(ctx, "before")
+		SetAfter(ctx, "after")
+
+		assert.Equal(t, &Annotations{
+			ResourceKind: "datasources",
+			ResourceID:   "uid",
+			BeforeHash:   Hash("before"),
+			AfterHash:    Hash("after"),
+		}, annotations)
+	})
+}
@@ -0,0 +1,120 @@
+package auditlogimpl
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Get("/api/audit-logs", middleware.ReqSignedIn, authorize(ac.EvalPermission(auditlog.ActionRead)), routing.Wrap(s.searchHandler))
+}
+
+// swagger:route GET /audit-logs audit_logs searchAuditLogs
+//
+// Search the audit log of the organization.
+//
+// Returns the mutating calls to the APIs, the most recent first. Grafana Admins can search the
+// events of other organizations with the orgId parameter.
+//
+// Responses:
+// 200: searchAuditLogsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) searchHandler(c *contextmodel.ReqContext) response.Response {
+	query := &auditlog.SearchQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		Actor:        c.Query("actor"),
+		Action:       c.Query("action"),
+		ResourceKind: c.Query("resourceKind"),
+		ResourceID:   c.Query("resourceId"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultPerPage
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+	if c.Req.URL.Query().Has("orgId") {
+		// Events without an organization, like changes to server settings, have the org ID 0
+		if !c.SignedInUser.GetIsGrafanaAdmin() {
+			return response.Error(http.StatusForbidden, "Only Grafana Admins can search the audit log of other organizations", nil)
+		}
+		query.OrgID = c.QueryInt64("orgId")
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		if errors.Is(err, auditlog.ErrInvalidSearchQuery) {
+			return response.Error(http.StatusBadRequest, "Invalid audit log search query", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to search the audit log", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters searchAuditLogs
+type SearchAuditLogsParams struct {
+	// Start of the time range, in milliseconds since the epoch.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// End of the time range, in milliseconds since the epoch.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// ID or login of the actor.
+	// in:query
+	// required:false
+	Actor string `json:"actor"`
+	// create, update or delete.
+	// in:query
+	// required:false
+	Action string `json:"action"`
+	// in:query
+	// required:false
+	ResourceKind string `json:"resourceKind"`
+	// in:query
+	// required:false
+	ResourceID string `json:"resourceId"`
+	// Organization of the events, only for Grafana Admins.
+	// in:query
+	// required:false
+	OrgID int64 `json:"orgId"`
+	// in:query
+	// required:false
+	// default:1
+	Page int `json:"page"`
+	// Number of events per page, at most 1000.
+	// in:query
+	// required:false
+	// default:100
+	PerPage int `json:"perpage"`
+}
+
+// swagger:response searchAuditLogsResponse
+type SearchAuditLogsResponse struct {
+	// in:body
+	Body auditlog.SearchResult `json:"body"`
+}
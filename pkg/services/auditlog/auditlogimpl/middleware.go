@@ -0,0 +1,111 @@
+package auditlogimpl
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// RouteMiddleware returns the middleware that records the mutating calls to the route with the
+// pattern. It is registered as a named middleware of the HTTP server.
+func (s *Service) RouteMiddleware(pattern string) web.Handler {
+	if !s.cfg.AuditLog.Enabled || !s.recordsRoute(pattern) {
+		return web.Middleware(func(next http.Handler) http.Handler { return next })
+	}
+
+	return web.Middleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isMutating(r.Method) || s.isExcluded(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// The handlers of the route read the request from the macaron context
+			c := web.FromContext(r.Context())
+			ctx, annotations := auditlog.WithAnnotations(c.Req.Context())
+			c.Req = c.Req.WithContext(ctx)
+
+			next.ServeHTTP(c.Resp, c.Req)
+
+			event := &auditlog.Event{
+				Source:     auditlog.SourceAPI,
+				RemoteAddr: web.RemoteAddr(c.Req),
+				UserAgent:  c.Req.UserAgent(),
+				Action:     auditlog.ActionFromMethod(c.Req.Method),
+				Method:     c.Req.Method,
+				Path:       c.Req.URL.Path,
+				Route:      pattern,
+				Status:     c.Resp.Status(),
+				RequestID:  tracing.TraceIDFromContext(c.Req.Context(), false),
+				BeforeHash: annotations.BeforeHash,
+				AfterHash:  annotations.AfterHash,
+			}
+			if reqCtx := contexthandler.FromContext(c.Req.Context()); reqCtx != nil && reqCtx.SignedInUser != nil && !reqCtx.SignedInUser.IsNil() {
+				event.ActorID = reqCtx.SignedInUser.GetID()
+				event.ActorLogin = reqCtx.SignedInUser.GetLogin()
+				event.OrgID = reqCtx.SignedInUser.GetOrgID()
+			}
+			event.ResourceKind, event.ResourceID = resourceFromRoute(pattern, web.Params(c.Req))
+			if annotations.ResourceKind != "" {
+				event.ResourceKind, event.ResourceID = annotations.ResourceKind, annotations.ResourceID
+			}
+
+			s.Record(c.Req.Context(), event)
+		})
+	})
+}
+
+// recordsRoute returns false for the routes that are recorded elsewhere, the Kubernetes style
+// APIs are recorded by the audit backend of the API server.
+func (s *Service) recordsRoute(pattern string) bool {
+	return pattern != "/apis" && !strings.HasPrefix(pattern, "/apis/")
+}
+
+func (s *Service) isExcluded(path string) bool {
+	for _, prefix := range s.cfg.AuditLog.ExcludePaths {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// resourceFromRoute returns the kind of the resource from the segments of the pattern before the
+// first parameter, and the identifier of the resource from the value of the last parameter. For
+// example /api/datasources/uid/:uid is the datasources resource with the uid of the request.
+func resourceFromRoute(pattern string, params map[string]string) (string, string) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(pattern, "/api/"), "/"), "/")
+
+	kind := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			break
+		}
+		// Selectors of the identifier are not part of the kind
+		if segment == "uid" || segment == "id" || segment == "name" {
+			continue
+		}
+		kind = append(kind, segment)
+	}
+
+	id := ""
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			id = params[segment]
+		}
+	}
+	return strings.Join(kind, "/"), id
+}
@@ -0,0 +1,179 @@
+package auditlogimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// retentionInterval is how often the events older than the retention are deleted.
+	retentionInterval = time.Hour
+	// writeBatchSize is the maximum number of events written to the sinks at once.
+	writeBatchSize = 100
+)
+
+var _ auditlog.Service = (*Service)(nil)
+
+// sink is a destination of the events of the audit log.
+type sink interface {
+	write(ctx context.Context, events []*auditlog.Event) error
+	close() error
+}
+
+// Service queues the recorded events and writes them to the sinks in the background, so that
+// recording an event does not slow down the request.
+type Service struct {
+	cfg   *setting.Cfg
+	store *store
+	log   log.Logger
+	sinks []sink
+	now   func() time.Time
+
+	events chan *auditlog.Event
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl, accessControlService accesscontrol.Service) (*Service, error) {
+	s := &Service{
+		cfg:    cfg,
+		store:  &store{db: sqlStore},
+		log:    log.New("auditlog"),
+		now:    time.Now,
+		events: make(chan *auditlog.Event, cfg.AuditLog.BufferSize),
+	}
+	if !cfg.AuditLog.Enabled {
+		return s, nil
+	}
+
+	for _, name := range cfg.AuditLog.Sinks {
+		sink, err := s.newSink(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create audit log sink %s: %w", name, err)
+		}
+		s.sinks = append(s.sinks, sink)
+	}
+
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s, nil
+}
+
+func (s *Service) newSink(name string) (sink, error) {
+	switch name {
+	case setting.AuditLogSinkDatabase:
+		return s.store, nil
+	case setting.AuditLogSinkFile:
+		path := s.cfg.AuditLog.FilePath
+		if path == "" {
+			path = filepath.Join(s.cfg.LogsPath, "audit.log")
+		}
+		return newFileSink(path, s.cfg.AuditLog.FileMaxSize, s.cfg.AuditLog.FileMaxDays)
+	case setting.AuditLogSinkSyslog:
+		return newSyslogSink(s.cfg.AuditLog.SyslogNetwork, s.cfg.AuditLog.SyslogAddress, s.cfg.AuditLog.SyslogFacility, s.cfg.AuditLog.SyslogTag)
+	default:
+		return nil, errors.New("unknown sink")
+	}
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.AuditLog.Enabled
+}
+
+func (s *Service) IsEnabled() bool {
+	return s.cfg.AuditLog.Enabled
+}
+
+// Run writes the queued events to the sinks and deletes the events older than the retention.
+func (s *Service) Run(ctx context.Context) error {
+	retentionTicker := time.NewTicker(retentionInterval)
+	defer retentionTicker.Stop()
+
+	for {
+		select {
+		case event := <-s.events:
+			s.write(ctx, s.drain(event))
+		case <-retentionTicker.C:
+			if err := s.store.deleteBefore(ctx, s.now().Add(-s.cfg.AuditLog.Retention)); err != nil {
+				s.log.Error("Failed to delete audit log events older than the retention", "error", err)
+			}
+		case <-ctx.Done():
+			// Write the queued events before shutting down
+			writeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			for len(s.events) > 0 {
+				s.write(writeCtx, s.drain(<-s.events))
+			}
+			cancel()
+			for _, sink := range s.sinks {
+				if err := sink.close(); err != nil {
+					s.log.Warn("Failed to close audit log sink", "error", err)
+				}
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// drain returns the event with the events queued after it, up to the batch size.
+func (s *Service) drain(event *auditlog.Event) []*auditlog.Event {
+	batch := []*auditlog.Event{event}
+	for len(batch) < writeBatchSize {
+		select {
+		case event := <-s.events:
+			batch = append(batch, event)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (s *Service) write(ctx context.Context, events []*auditlog.Event) {
+	for _, sink := range s.sinks {
+		if err := sink.write(ctx, events); err != nil {
+			s.log.Error("Failed to write audit log events", "count", len(events), "error", err)
+		}
+	}
+}
+
+// Record queues the event for writing to the sinks. If the queue is full, the event is written
+// synchronously rather than dropped.
+func (s *Service) Record(ctx context.Context, event *auditlog.Event) {
+	if !s.cfg.AuditLog.Enabled {
+		return
+	}
+	if event.Created.IsZero() {
+		event.Created = s.now()
+	}
+
+	select {
+	case s.events <- event:
+	default:
+		s.log.Warn("Audit log queue is full, writing event synchronously")
+		s.write(context.WithoutCancel(ctx), []*auditlog.Event{event})
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 || query.Limit > maxPerPage {
+		return nil, auditlog.ErrInvalidSearchQuery
+	}
+	if !query.To.IsZero() && query.From.After(query.To) {
+		return nil, auditlog.ErrInvalidSearchQuery
+	}
+	return s.store.search(ctx, query)
+}
@@ -0,0 +1,220 @@
+package auditlogimpl
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+// fakeSink collects the written events.
+type fakeSink struct {
+	mu     sync.Mutex
+	events []*auditlog.Event
+}
+
+func (f *fakeSink) write(_ context.Context, events []*auditlog.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, events...)
+	return nil
+}
+
+func (f *fakeSink) close() error {
+	return nil
+}
+
+func newTestService(t *testing.T, s sink) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.AuditLog.Enabled = true
+	cfg.AuditLog.BufferSize = 10
+	cfg.AuditLog.ExcludePaths = []string{"/api/ds/query"}
+	return &Service{
+		cfg:    cfg,
+		log:    log.NewNopLogger(),
+		sinks:  []sink{s},
+		now:    time.Now,
+		events: make(chan *auditlog.Event, cfg.AuditLog.BufferSize),
+	}
+}
+
+func TestResourceFromRoute(t *testing.T) {
+	tests := []struct {
+		pattern string
+		params  map[string]string
+		kind    string
+		id      string
+	}{
+		{pattern: "/api/datasources/uid/:uid", params: map[string]string{":uid": "abc"}, kind: "datasources", id: "abc"},
+		{pattern: "/api/datasources/:id", params: map[string]string{":id": "1"}, kind: "datasources", id: "1"},
+		{pattern: "/api/folders", kind: "folders"},
+		{pattern: "/api/org/users/:userId", params: map[string]string{":userId": "2"}, kind: "org/users", id: "2"},
+		{pattern: "/api/teams/:teamId/members/:userId", params: map[string]string{":teamId": "3", ":userId": "4"}, kind: "teams", id: "4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			kind, id := resourceFromRoute(tt.pattern, tt.params)
+			assert.Equal(t, tt.kind, kind)
+			assert.Equal(t, tt.id, id)
+		})
+	}
+}
+
+func TestService_RouteMiddleware(t *testing.T) {
+	s := newTestService(t, &fakeSink{})
+
+	m := web.New()
+	route := func(method, pattern string) {
+		m.Handle(method, pattern, []web.Handler{s.RouteMiddleware(pattern), web.Middleware(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.ReadAll(r.Body)
+				auditlog.SetBefore(r.Context(), "before")
+				w.WriteHeader(http.StatusOK)
+			})
+		})})
+	}
+	route(http.MethodPut, "/api/datasources/uid/:uid")
+	route(http.MethodGet, "/api/datasources/uid/:uid")
+	route(http.MethodPost, "/api/ds/query")
+
+	t.Run("should record mutating requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/api/datasources/uid/abc", strings.NewReader(`{"name":"prometheus"}`)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		require.Len(t, s.events, 1)
+		event := <-s.events
+		assert.Equal(t, auditlog.SourceAPI, event.Source)
+		assert.Equal(t, auditlog.ActionUpdate, event.Action)
+		assert.Equal(t, "datasources", event.ResourceKind)
+		assert.Equal(t, "abc", event.ResourceID)
+		assert.Equal(t, "/api/datasources/uid/:uid", event.Route)
+		assert.Equal(t, "/api/datasources/uid/abc", event.Path)
+		assert.Equal(t, http.StatusOK, event.Status)
+		assert.Equal(t, auditlog.Hash("before"), event.BeforeHash)
+		assert.Empty(t, event.AfterHash)
+	})
+
+	t.Run("should not record reads and excluded paths", func(t *testing.T) {
+		m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/datasources/uid/abc", nil))
+		m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/ds/query", strings.NewReader(`{}`)))
+		require.Empty(t, s.events)
+	})
+}
+
+func TestService_Record(t *testing.T) {
+	sink := &fakeSink{}
+	s := newTestService(t, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	for i := 0; i < 25; i++ {
+		s.Record(context.Background(), &auditlog.Event{Action: auditlog.ActionCreate})
+	}
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+
+	// Events are written when the queue is full and before shutting down
+	require.Len(t, sink.events, 25)
+	for _, event := range sink.events {
+		require.False(t, event.Created.IsZero())
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := newFileSink(path, 1, 7)
+	require.NoError(t, err)
+
+	err = sink.write(context.Background(), []*auditlog.Event{
+		{Action: auditlog.ActionCreate, ResourceKind: "folders", ResourceID: "a"},
+		{Action: auditlog.ActionDelete, ResourceKind: "folders", ResourceID: "b"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, sink.close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var ids []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var event auditlog.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ResourceID)
+	}
+	require.Equal(t, []string{"a", "b"}, ids)
+}
+
+func TestIntegrationAuditLogStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := &store{db: db.InitTestDB(t)}
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	err := s.write(ctx, []*auditlog.Event{
+		{OrgID: 1, Created: now.Add(-48 * time.Hour), ActorID: "user:1", ActorLogin: "admin", Action: auditlog.ActionCreate, ResourceKind: "folders", ResourceID: "a"},
+		{OrgID: 1, Created: now.Add(-time.Hour), ActorID: "user:2", ActorLogin: "editor", Action: auditlog.ActionUpdate, ResourceKind: "folders", ResourceID: "a"},
+		{OrgID: 1, Created: now, ActorID: "user:1", ActorLogin: "admin", Action: auditlog.ActionDelete, ResourceKind: "datasources", ResourceID: "b"},
+		{OrgID: 2, Created: now, ActorID: "user:1", ActorLogin: "admin", Action: auditlog.ActionDelete, ResourceKind: "folders", ResourceID: "c"},
+	})
+	require.NoError(t, err)
+
+	t.Run("should search the events of the organization, the most recent first", func(t *testing.T) {
+		result, err := s.search(ctx, &auditlog.SearchQuery{OrgID: 1, Page: 1, Limit: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 2)
+		require.Equal(t, "b", result.Events[0].ResourceID)
+		require.Equal(t, auditlog.ActionUpdate, result.Events[1].Action)
+	})
+
+	t.Run("should filter the events", func(t *testing.T) {
+		result, err := s.search(ctx, &auditlog.SearchQuery{OrgID: 1, Actor: "admin", ResourceKind: "folders", Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result.Events, 1)
+		require.Equal(t, auditlog.ActionCreate, result.Events[0].Action)
+
+		result, err = s.search(ctx, &auditlog.SearchQuery{OrgID: 1, From: now.Add(-2 * time.Hour), Action: auditlog.ActionUpdate, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result.Events, 1)
+		require.Equal(t, "user:2", result.Events[0].ActorID)
+	})
+
+	t.Run("should delete the events older than the retention", func(t *testing.T) {
+		require.NoError(t, s.deleteBefore(ctx, now.Add(-24*time.Hour)))
+
+		result, err := s.search(ctx, &auditlog.SearchQuery{OrgID: 1, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+	})
+}
@@ -0,0 +1,43 @@
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+// fileSink writes the events as JSON lines to a file that is rotated by size and daily.
+type fileSink struct {
+	writer *log.FileLogWriter
+}
+
+func newFileSink(path string, maxSizeMB int, maxDays int64) (*fileSink, error) {
+	writer := log.NewFileWriter()
+	writer.Filename = path
+	writer.Maxsize = maxSizeMB << 20
+	writer.Maxdays = maxDays
+	writer.Maxlines = 0
+	if err := writer.StartLogger(); err != nil {
+		return nil, err
+	}
+	return &fileSink{writer: writer}, nil
+}
+
+func (f *fileSink) write(_ context.Context, events []*auditlog.Event) error {
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := f.writer.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *fileSink) close() error {
+	return f.writer.Close()
+}
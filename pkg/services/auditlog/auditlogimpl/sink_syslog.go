@@ -0,0 +1,60 @@
+//go:build !windows && !nacl && !plan9
+
+package auditlogimpl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/syslog"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+var facilities = map[string]syslog.Priority{
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"auth":   syslog.LOG_AUTH,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogSink writes the events as JSON messages to syslog.
+type syslogSink struct {
+	writer *syslog.Writer
+}
+
+func newSyslogSink(network, address, facility, tag string) (*syslogSink, error) {
+	priority, ok := facilities[facility]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", facility)
+	}
+	writer, err := syslog.Dial(network, address, priority|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{writer: writer}, nil
+}
+
+func (s *syslogSink) write(_ context.Context, events []*auditlog.Event) error {
+	for _, event := range events {
+		msg, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err := s.writer.Info(string(msg)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) close() error {
+	return s.writer.Close()
+}
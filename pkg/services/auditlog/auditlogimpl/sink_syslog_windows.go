@@ -0,0 +1,11 @@
+//go:build windows
+
+package auditlogimpl
+
+import (
+	"errors"
+)
+
+func newSyslogSink(_, _, _, _ string) (sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,91 @@
+package auditlogimpl
+
+import (
+	"context"
+	"time"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+// deleteBatchSize is the number of events deleted in one statement by the retention.
+const deleteBatchSize = 10000
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) write(ctx context.Context, events []*auditlog.Event) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, event := range events {
+			// The events are shared with the other sinks
+			row := *event
+			if _, err := sess.Table("audit_log").Insert(&row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *store) close() error {
+	return nil
+}
+
+func (s *store) search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	result := &auditlog.SearchResult{Events: make([]*auditlog.Event, 0), Page: query.Page, PerPage: query.Limit}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filter := func() *xorm.Session {
+			q := sess.Table("audit_log").Where("org_id = ?", query.OrgID)
+			if !query.From.IsZero() {
+				q = q.And("created >= ?", query.From)
+			}
+			if !query.To.IsZero() {
+				q = q.And("created <= ?", query.To)
+			}
+			if query.Actor != "" {
+				q = q.And("(actor_id = ? OR actor_login = ?)", query.Actor, query.Actor)
+			}
+			if query.Action != "" {
+				q = q.And("action = ?", query.Action)
+			}
+			if query.ResourceKind != "" {
+				q = q.And("resource_kind = ?", query.ResourceKind)
+			}
+			if query.ResourceID != "" {
+				q = q.And("resource_id = ?", query.ResourceID)
+			}
+			return q
+		}
+
+		count, err := filter().Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		return filter().Desc("created", "id").Limit(query.Limit, (query.Page-1)*query.Limit).Find(&result.Events)
+	})
+	return result, err
+}
+
+// deleteBefore deletes the events created before the time in batches, so that the table is not
+// locked for long.
+func (s *store) deleteBefore(ctx context.Context, before time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for {
+			var ids []int64
+			if err := sess.Table("audit_log").Where("created < ?", before).Limit(deleteBatchSize).Cols("id").Find(&ids); err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			if _, err := sess.Table("audit_log").In("id", ids).Delete(&auditlog.Event{}); err != nil {
+				return err
+			}
+		}
+	})
+}
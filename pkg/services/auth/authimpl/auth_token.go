@@ -11,7 +11,9 @@ import (
 
 	"golang.org/x/sync/singleflight"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/models/usertoken"
@@ -31,13 +33,16 @@ var (
 func ProvideUserAuthTokenService(sqlStore db.DB,
 	serverLockService *serverlock.ServerLockService,
 	quotaService quota.Service,
+	bus bus.Bus,
 	cfg *setting.Cfg) (*UserAuthTokenService, error) {
 	s := &UserAuthTokenService{
 		sqlStore:          sqlStore,
 		serverLockService: serverLockService,
+		bus:               bus,
 		cfg:               cfg,
 		log:               log.New("auth"),
 		singleflight:      new(singleflight.Group),
+		roleCache:         localcache.New(sessionRoleCacheTTL, 5*time.Minute),
 	}
 
 	defaultLimits, err := readQuotaConfig(cfg)
@@ -59,12 +64,22 @@ func ProvideUserAuthTokenService(sqlStore db.DB,
 type UserAuthTokenService struct {
 	sqlStore          db.DB
 	serverLockService *serverlock.ServerLockService
+	bus               bus.Bus
 	cfg               *setting.Cfg
 	log               log.Logger
 	singleflight      *singleflight.Group
+	roleCache         *localcache.CacheService
 }
 
 func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User, clientIP net.IP, userAgent string) (*auth.UserToken, error) {
+	policy, err := s.sessionPolicy(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limitConcurrentSessions(ctx, user.ID, policy); err != nil {
+		return nil, err
+	}
+
 	token, hashedToken, err := generateAndHashToken(s.cfg.SecretKey)
 	if err != nil {
 		return nil, err
@@ -104,6 +119,8 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, user *user.User,
 	ctxLogger := s.log.FromContext(ctx)
 	ctxLogger.Debug("User auth token created", "tokenID", userAuthToken.Id, "userID", userAuthToken.UserId, "clientIP", userAuthToken.ClientIp, "userAgent", userAuthToken.UserAgent, "authToken", userAuthToken.AuthToken)
 
+	s.detectNewDevice(ctx, &userAuthToken)
+
 	var userToken auth.UserToken
 	err = userAuthToken.toUserToken(&userToken)
 
@@ -135,9 +152,14 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 
 	if model.RevokedAt > 0 {
 		ctxLogger.Debug("User token has been revoked", "userID", model.UserId, "tokenID", model.Id, "revokedAt", model.RevokedAt)
+		policy, err := s.sessionPolicy(ctx, model.UserId)
+		if err != nil {
+			return nil, err
+		}
 		return nil, &auth.TokenRevokedError{
-			UserID:  model.UserId,
-			TokenID: model.Id,
+			UserID:                model.UserId,
+			TokenID:               model.Id,
+			MaxConcurrentSessions: policy.MaxConcurrentSessions,
 		}
 	}
 
@@ -149,6 +171,18 @@ func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken st
 		}
 	}
 
+	expired, err := s.expiredByPolicy(ctx, &model)
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		ctxLogger.Debug("User token has expired by the session policy of the user", "userID", model.UserId, "tokenID", model.Id, "createdAt", model.CreatedAt, "rotatedAt", model.RotatedAt)
+		return nil, &auth.TokenExpiredError{
+			UserID:  model.UserId,
+			TokenID: model.Id,
+		}
+	}
+
 	// Current incoming token is the previous auth token in the DB and the auth_token_seen is true
 	if model.AuthToken != hashedToken && model.PrevAuthToken == hashedToken && model.AuthTokenSeen {
 		model.AuthTokenSeen = false
@@ -405,8 +439,13 @@ func (s *UserAuthTokenService) GetUserToken(ctx context.Context, userId, userTok
 }
 
 func (s *UserAuthTokenService) GetUserTokens(ctx context.Context, userId int64) ([]*auth.UserToken, error) {
+	policy, err := s.sessionPolicy(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	result := []*auth.UserToken{}
-	err := s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+	err = s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
 		var tokens []*userAuthToken
 		err := dbSession.Where("user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0",
 			userId,
@@ -418,6 +457,9 @@ func (s *UserAuthTokenService) GetUserTokens(ctx context.Context, userId int64)
 		}
 
 		for _, token := range tokens {
+			if tokenExpired(token, policy) {
+				continue
+			}
 			var userToken auth.UserToken
 			if err := token.toUserToken(&userToken); err != nil {
 				return err
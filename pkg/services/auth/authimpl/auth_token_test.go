@@ -12,9 +12,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/singleflight"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -553,9 +556,11 @@ func createTestContext(t *testing.T) *testContext {
 
 	tokenService := &UserAuthTokenService{
 		sqlStore:     sqlstore,
+		bus:          bus.ProvideBus(tracing.InitializeTracerForTest()),
 		cfg:          cfg,
 		log:          log.New("test-logger"),
 		singleflight: new(singleflight.Group),
+		roleCache:    localcache.New(sessionRoleCacheTTL, 5*time.Minute),
 	}
 
 	return &testContext{
@@ -0,0 +1,186 @@
+package authimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// sessionRoleCacheTTL is how long the role of a user is cached to pick their session policy.
+const sessionRoleCacheTTL = time.Minute
+
+var errConcurrentSessionsLimit = errors.New("maximum number of concurrent sessions reached")
+
+// sessionPolicy returns the session policy of a user, picked by the highest role of the user.
+func (s *UserAuthTokenService) sessionPolicy(ctx context.Context, userID int64) (setting.SessionPolicy, error) {
+	if len(s.cfg.Session.RolePolicies) == 0 {
+		return s.cfg.DefaultSessionPolicy(), nil
+	}
+
+	role, err := s.sessionRole(ctx, userID)
+	if err != nil {
+		return setting.SessionPolicy{}, err
+	}
+	return s.cfg.SessionPolicy(role), nil
+}
+
+// sessionRole returns Admin for Grafana server administrators, otherwise the highest role of
+// the user in any organization.
+func (s *UserAuthTokenService) sessionRole(ctx context.Context, userID int64) (identity.RoleType, error) {
+	key := fmt.Sprintf("session-role-%d", userID)
+	if role, ok := s.roleCache.Get(key); ok {
+		return role.(identity.RoleType), nil
+	}
+
+	role := identity.RoleNone
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var usr struct{ IsAdmin bool }
+		if _, err := sess.Table("user").Cols("is_admin").Where("id = ?", userID).Get(&usr); err != nil {
+			return err
+		}
+		if usr.IsAdmin {
+			role = identity.RoleAdmin
+			return nil
+		}
+
+		var orgRoles []string
+		if err := sess.SQL("SELECT role FROM org_user WHERE user_id = ?", userID).Find(&orgRoles); err != nil {
+			return err
+		}
+		for _, r := range orgRoles {
+			if orgRole := identity.RoleType(r); orgRole.IsValid() && orgRole.Includes(role) {
+				role = orgRole
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	s.roleCache.Set(key, role, sessionRoleCacheTTL)
+	return role, nil
+}
+
+// expiredByPolicy returns true if the token is expired by the session policy of its user.
+// The role of the user is only looked up if the strictest policy would expire the token.
+func (s *UserAuthTokenService) expiredByPolicy(ctx context.Context, token *userAuthToken) (bool, error) {
+	if len(s.cfg.Session.RolePolicies) == 0 {
+		return false, nil
+	}
+
+	strictest := s.cfg.DefaultSessionPolicy()
+	for _, p := range s.cfg.Session.RolePolicies {
+		strictest.MaxInactiveLifetime = min(strictest.MaxInactiveLifetime, p.MaxInactiveLifetime)
+		strictest.MaxLifetime = min(strictest.MaxLifetime, p.MaxLifetime)
+	}
+	if !tokenExpired(token, strictest) {
+		return false, nil
+	}
+
+	policy, err := s.sessionPolicy(ctx, token.UserId)
+	if err != nil {
+		return false, err
+	}
+	return tokenExpired(token, policy), nil
+}
+
+func tokenExpired(token *userAuthToken, policy setting.SessionPolicy) bool {
+	now := getTime()
+	return token.CreatedAt <= now.Add(-policy.MaxLifetime).Unix() || token.RotatedAt <= now.Add(-policy.MaxInactiveLifetime).Unix()
+}
+
+// limitConcurrentSessions makes room for a new session of the user, by revoking their oldest
+// sessions or by denying the new one, depending on the configuration.
+func (s *UserAuthTokenService) limitConcurrentSessions(ctx context.Context, userID int64, policy setting.SessionPolicy) error {
+	if policy.MaxConcurrentSessions <= 0 {
+		return nil
+	}
+
+	now := getTime()
+	var ids []int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT id FROM user_auth_token WHERE user_id = ? AND created_at > ? AND rotated_at > ? AND revoked_at = 0 ORDER BY created_at, id",
+			userID,
+			now.Add(-policy.MaxLifetime).Unix(),
+			now.Add(-policy.MaxInactiveLifetime).Unix()).
+			Find(&ids)
+	})
+	if err != nil {
+		return err
+	}
+
+	excess := int64(len(ids)) - policy.MaxConcurrentSessions + 1
+	if excess <= 0 {
+		return nil
+	}
+
+	if s.cfg.Session.ConcurrentSessionsPolicy == setting.ConcurrentSessionsDeny {
+		return &auth.CreateTokenErr{
+			StatusCode:  http.StatusForbidden,
+			InternalErr: errConcurrentSessionsLimit,
+			ExternalErr: fmt.Sprintf("You have reached the maximum number of %d concurrent sessions, sign out of another session first", policy.MaxConcurrentSessions),
+		}
+	}
+
+	evicted := ids[:excess]
+	err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		params := []any{"UPDATE user_auth_token SET revoked_at = ? WHERE id IN (?" + strings.Repeat(",?", len(evicted)-1) + ")", now.Unix()}
+		for _, id := range evicted {
+			params = append(params, id)
+		}
+		_, err := sess.Exec(params...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.log.FromContext(ctx).Info("Revoked oldest sessions of user to respect the concurrent sessions limit", "userID", userID, "count", len(evicted), "limit", policy.MaxConcurrentSessions)
+	return nil
+}
+
+// detectNewDevice publishes a NewDeviceLogin event if the new token is from a device that none
+// of the other tokens of the user are from. Nothing is published for the first session of a user.
+func (s *UserAuthTokenService) detectNewDevice(ctx context.Context, token *userAuthToken) {
+	var known []*userAuthToken
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Cols("user_agent", "client_ip").Where("user_id = ? AND id <> ?", token.UserId, token.Id).Find(&known)
+	})
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to get the devices of the user", "userID", token.UserId, "error", err)
+		return
+	}
+	if len(known) == 0 {
+		return
+	}
+
+	fingerprint := auth.DeviceFingerprint(token.UserAgent, token.ClientIp)
+	for _, t := range known {
+		if auth.DeviceFingerprint(t.UserAgent, t.ClientIp) == fingerprint {
+			return
+		}
+	}
+
+	subnet := auth.ClientSubnet(token.ClientIp)
+	s.log.FromContext(ctx).Info("User logged in from a new device", "userID", token.UserId, "tokenID", token.Id, "clientSubnet", subnet, "userAgent", token.UserAgent)
+	if err := s.bus.Publish(ctx, &events.NewDeviceLogin{
+		Timestamp:    getTime(),
+		UserID:       token.UserId,
+		TokenID:      token.Id,
+		UserAgent:    token.UserAgent,
+		ClientSubnet: subnet,
+		Fingerprint:  fingerprint,
+	}); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to publish new device login event", "userID", token.UserId, "error", err)
+	}
+}
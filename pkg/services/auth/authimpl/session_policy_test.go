@@ -0,0 +1,164 @@
+package authimpl
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestIntegrationConcurrentSessionsLimit(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	usr := &user.User{ID: 10}
+	createTokens := func(t *testing.T, ctx *testContext, count int) []*auth.UserToken {
+		tokens := make([]*auth.UserToken, 0, count)
+		for i := 0; i < count; i++ {
+			token, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+			require.NoError(t, err)
+			tokens = append(tokens, token)
+		}
+		return tokens
+	}
+
+	t.Run("evicts the oldest sessions", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.cfg.Session.MaxConcurrentSessions = 2
+		ctx.tokenService.cfg.Session.ConcurrentSessionsPolicy = setting.ConcurrentSessionsEvictOldest
+
+		tokens := createTokens(t, ctx, 3)
+
+		_, err := ctx.tokenService.LookupToken(context.Background(), tokens[0].UnhashedToken)
+		var revokedErr *auth.TokenRevokedError
+		require.ErrorAs(t, err, &revokedErr)
+		require.Equal(t, int64(2), revokedErr.MaxConcurrentSessions)
+
+		for _, token := range tokens[1:] {
+			_, err := ctx.tokenService.LookupToken(context.Background(), token.UnhashedToken)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("denies new sessions", func(t *testing.T) {
+		ctx := createTestContext(t)
+		ctx.tokenService.cfg.Session.MaxConcurrentSessions = 2
+		ctx.tokenService.cfg.Session.ConcurrentSessionsPolicy = setting.ConcurrentSessionsDeny
+
+		tokens := createTokens(t, ctx, 2)
+
+		_, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+		var createErr *auth.CreateTokenErr
+		require.ErrorAs(t, err, &createErr)
+		require.Equal(t, http.StatusForbidden, createErr.StatusCode)
+
+		require.NoError(t, ctx.tokenService.RevokeToken(context.Background(), tokens[0], false))
+		createTokens(t, ctx, 1)
+	})
+}
+
+func TestIntegrationSessionPolicyLifetimes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	ctx := createTestContext(t)
+	ctx.tokenService.cfg.Session.RolePolicies = map[identity.RoleType]setting.SessionPolicy{
+		identity.RoleAdmin: {MaxInactiveLifetime: time.Hour, MaxLifetime: 8 * time.Hour},
+	}
+
+	admin := &user.User{ID: 1}
+	viewer := &user.User{ID: 2}
+	ctx.tokenService.roleCache.Set("session-role-1", identity.RoleAdmin, time.Hour)
+	ctx.tokenService.roleCache.Set("session-role-2", identity.RoleViewer, time.Hour)
+
+	adminToken, err := ctx.tokenService.CreateToken(context.Background(), admin, net.ParseIP("192.168.10.11"), "some user agent")
+	require.NoError(t, err)
+	viewerToken, err := ctx.tokenService.CreateToken(context.Background(), viewer, net.ParseIP("192.168.10.11"), "some user agent")
+	require.NoError(t, err)
+
+	getTime = func() time.Time { return now.Add(30 * time.Minute) }
+	_, err = ctx.tokenService.LookupToken(context.Background(), adminToken.UnhashedToken)
+	require.NoError(t, err)
+
+	getTime = func() time.Time { return now.Add(2 * time.Hour) }
+	_, err = ctx.tokenService.LookupToken(context.Background(), adminToken.UnhashedToken)
+	var expiredErr *auth.TokenExpiredError
+	require.ErrorAs(t, err, &expiredErr)
+
+	tokens, err := ctx.tokenService.GetUserTokens(context.Background(), admin.ID)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	_, err = ctx.tokenService.LookupToken(context.Background(), viewerToken.UnhashedToken)
+	require.NoError(t, err)
+}
+
+func TestIntegrationNewDeviceLogin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := createTestContext(t)
+	var published []*events.NewDeviceLogin
+	ctx.tokenService.bus.AddEventListener(func(_ context.Context, e *events.NewDeviceLogin) error {
+		published = append(published, e)
+		return nil
+	})
+
+	usr := &user.User{ID: 10}
+	const firefox = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+	const updatedFirefox = "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0"
+	const iPhone = "Mozilla/5.0 (iPhone; CPU iPhone OS 11_0 like Mac OS X) AppleWebKit/604.1.38 (KHTML, like Gecko) Version/11.0 Mobile/15A372 Safari/604.1"
+
+	login := func(ip, userAgent string) {
+		_, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP(ip), userAgent)
+		require.NoError(t, err)
+	}
+
+	login("192.168.10.11", firefox)
+	require.Empty(t, published, "the first session of a user is not a new device")
+
+	login("192.168.10.42", updatedFirefox)
+	require.Empty(t, published, "same browser in the same network")
+
+	login("192.168.10.11", iPhone)
+	require.Len(t, published, 1)
+	require.Equal(t, usr.ID, published[0].UserID)
+	require.Equal(t, "192.168.10.0/24", published[0].ClientSubnet)
+
+	login("10.0.0.1", firefox)
+	require.Len(t, published, 2)
+}
+
+func TestIntegrationNewDeviceLoginListenerError(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := createTestContext(t)
+	ctx.tokenService.bus.AddEventListener(func(_ context.Context, e *events.NewDeviceLogin) error {
+		return errors.New("listener failed")
+	})
+
+	usr := &user.User{ID: 10}
+	_, err := ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("192.168.10.11"), "some user agent")
+	require.NoError(t, err)
+	_, err = ctx.tokenService.CreateToken(context.Background(), usr, net.ParseIP("10.0.0.1"), "some user agent")
+	require.NoError(t, err, "login must not fail because of a new device listener")
+}
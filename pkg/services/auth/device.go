@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/ua-parser/uap-go/uaparser"
+)
+
+const (
+	// Sessions from the same IPv4 /24 or IPv6 /64 network are considered to be from the same location.
+	deviceIPv4PrefixLength = 24
+	deviceIPv6PrefixLength = 64
+)
+
+var uaParser = sync.OnceValue(uaparser.NewFromSaved)
+
+// ClientSubnet returns the network of an IP address used to recognize devices, or an empty string
+// if the address is not valid.
+func ClientSubnet(clientIP string) string {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return (&net.IPNet{IP: ip4.Mask(net.CIDRMask(deviceIPv4PrefixLength, 32)), Mask: net.CIDRMask(deviceIPv4PrefixLength, 32)}).String()
+	}
+	return (&net.IPNet{IP: ip.Mask(net.CIDRMask(deviceIPv6PrefixLength, 128)), Mask: net.CIDRMask(deviceIPv6PrefixLength, 128)}).String()
+}
+
+// DeviceFingerprint identifies the device of a session by its browser, operating system and
+// device type, and the network of its IP address. Versions are ignored, so that browser and
+// system updates don't make a known device new. No geolocation is used.
+func DeviceFingerprint(userAgent, clientIP string) string {
+	client := uaParser().Parse(userAgent)
+	parts := []string{client.UserAgent.Family, client.Os.Family, client.Device.Family, ClientSubnet(clientIP)}
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(hash[:8])
+}
@@ -28,6 +28,7 @@ const (
 	ClientForm        = "auth.client.form"
 	ClientProxy       = "auth.client.proxy"
 	ClientSAML        = "auth.client.saml"
+	ClientMFA         = "auth.client.mfa"
 )
 
 const (
@@ -66,6 +67,9 @@ type ClientParams struct {
 type FetchPermissionsParams struct {
 	// RestrictedActions will restrict the permissions to only these actions
 	RestrictedActions []string
+	// RestrictedPermissions will restrict the permissions to the intersection with these scopes grouped by action.
+	// An action without scopes keeps all the scopes granted for it.
+	RestrictedPermissions map[string][]string
 	// AllowedActions will be added to the identity permissions
 	AllowedActions []string
 	// Note: Kept for backwards compatibility, use AllowedActions instead
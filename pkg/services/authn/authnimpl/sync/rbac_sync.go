@@ -69,11 +69,71 @@ func (s *RBACSync) SyncPermissionsHook(ctx context.Context, ident *authn.Identit
 		}
 		grouped = filtered
 	}
+
+	if restricted := ident.ClientParams.FetchPermissionsParams.RestrictedPermissions; len(restricted) > 0 {
+		grouped = restrictPermissions(grouped, restricted)
+		// The basic role still grants access to routes and features checked by role, restricted identities
+		// only keep the access of their restricted permissions.
+		if ident.OrgRoles == nil {
+			ident.OrgRoles = make(map[int64]org.RoleType, 1)
+		}
+		ident.OrgRoles[ident.OrgID] = org.RoleNone
+	}
 	ident.Permissions[ident.OrgID] = grouped
 
 	return nil
 }
 
+// restrictPermissions returns the intersection between the granted and the restricted permissions.
+// A restricted scope is kept when a granted scope covers it and a granted scope is kept when a restricted one covers it.
+func restrictPermissions(granted, restricted map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(restricted))
+	for action, restrictedScopes := range restricted {
+		grantedScopes, ok := granted[action]
+		if !ok {
+			continue
+		}
+
+		if len(restrictedScopes) == 0 {
+			result[action] = grantedScopes
+			continue
+		}
+
+		scopes := make([]string, 0, len(restrictedScopes))
+		seen := make(map[string]bool, len(restrictedScopes))
+		add := func(scope string) {
+			if !seen[scope] {
+				seen[scope] = true
+				scopes = append(scopes, scope)
+			}
+		}
+
+		for _, grantedScope := range grantedScopes {
+			// scopes are not checked for actions granted without scope
+			if grantedScope == "" {
+				add(grantedScope)
+				continue
+			}
+			for _, restrictedScope := range restrictedScopes {
+				if covers(action, grantedScope, restrictedScope) {
+					add(restrictedScope)
+				} else if covers(action, restrictedScope, grantedScope) {
+					add(grantedScope)
+				}
+			}
+		}
+
+		if len(scopes) > 0 {
+			result[action] = scopes
+		}
+	}
+	return result
+}
+
+func covers(action, scope, target string) bool {
+	return accesscontrol.EvalPermission(action, target).Evaluate(map[string][]string{action: {scope}})
+}
+
 func (s *RBACSync) fetchPermissions(ctx context.Context, ident *authn.Identity) ([]accesscontrol.Permission, error) {
 	ctx, span := s.tracer.Start(ctx, "rbac.sync.fetchPermissions")
 	defer span.End()
@@ -34,6 +34,21 @@ func TestRBACSync_SyncPermission(t *testing.T) {
 				accesscontrol.ActionUsersWrite: {accesscontrol.ScopeUsersAll},
 			},
 		},
+		{
+			name: "restricts the permissions to the restricted scopes",
+			identity: &authn.Identity{ID: "2", Type: claims.TypeServiceAccount, OrgID: 1, ClientParams: authn.ClientParams{
+				SyncPermissions: true,
+				FetchPermissionsParams: authn.FetchPermissionsParams{
+					RestrictedPermissions: map[string][]string{
+						accesscontrol.ActionUsersRead: {"users:id:1"},
+						accesscontrol.ActionTeamsRead: nil,
+					},
+				},
+			}},
+			expectedPermissions: map[string][]string{
+				accesscontrol.ActionUsersRead: {"users:id:1"},
+			},
+		},
 		{
 			name:                "does not load the permissions when SyncPermissions is false",
 			identity:            &authn.Identity{ID: "2", Type: claims.TypeUser, OrgID: 1, ClientParams: authn.ClientParams{SyncPermissions: false}},
@@ -56,6 +71,78 @@ func TestRBACSync_SyncPermission(t *testing.T) {
 	}
 }
 
+func TestRBACSync_SyncPermission_RestrictedRole(t *testing.T) {
+	s := setupTestEnv(t)
+	ident := &authn.Identity{ID: "2", Type: claims.TypeServiceAccount, OrgID: 1, OrgRoles: map[int64]org.RoleType{1: org.RoleAdmin}, ClientParams: authn.ClientParams{
+		SyncPermissions: true,
+		FetchPermissionsParams: authn.FetchPermissionsParams{
+			RestrictedPermissions: map[string][]string{accesscontrol.ActionUsersRead: nil},
+		},
+	}}
+
+	require.NoError(t, s.SyncPermissionsHook(context.Background(), ident, &authn.Request{}))
+	assert.Equal(t, org.RoleNone, ident.GetOrgRole())
+	assert.Equal(t, org.RoleNone, ident.SignedInUser().OrgRole)
+
+	unrestricted := &authn.Identity{ID: "2", Type: claims.TypeServiceAccount, OrgID: 1, OrgRoles: map[int64]org.RoleType{1: org.RoleAdmin}, ClientParams: authn.ClientParams{SyncPermissions: true}}
+	require.NoError(t, s.SyncPermissionsHook(context.Background(), unrestricted, &authn.Request{}))
+	assert.Equal(t, org.RoleAdmin, unrestricted.GetOrgRole())
+}
+
+func TestRBACSync_restrictPermissions(t *testing.T) {
+	type testCase struct {
+		desc       string
+		granted    map[string][]string
+		restricted map[string][]string
+		expected   map[string][]string
+	}
+
+	tests := []testCase{
+		{
+			desc:       "should drop actions that are not restricted",
+			granted:    map[string][]string{"dashboards:read": {"dashboards:*"}, "dashboards:write": {"dashboards:*"}},
+			restricted: map[string][]string{"dashboards:read": nil},
+			expected:   map[string][]string{"dashboards:read": {"dashboards:*"}},
+		},
+		{
+			desc:       "should drop restricted actions that are not granted",
+			granted:    map[string][]string{"dashboards:read": {"dashboards:*"}},
+			restricted: map[string][]string{"dashboards:read": nil, "dashboards:write": nil},
+			expected:   map[string][]string{"dashboards:read": {"dashboards:*"}},
+		},
+		{
+			desc:       "should keep restricted scopes covered by granted scopes",
+			granted:    map[string][]string{"dashboards:read": {"dashboards:*"}},
+			restricted: map[string][]string{"dashboards:read": {"dashboards:uid:1", "dashboards:uid:2"}},
+			expected:   map[string][]string{"dashboards:read": {"dashboards:uid:1", "dashboards:uid:2"}},
+		},
+		{
+			desc:       "should keep granted scopes covered by restricted scopes",
+			granted:    map[string][]string{"dashboards:read": {"dashboards:uid:1", "folders:uid:1"}},
+			restricted: map[string][]string{"dashboards:read": {"dashboards:*"}},
+			expected:   map[string][]string{"dashboards:read": {"dashboards:uid:1"}},
+		},
+		{
+			desc:       "should drop actions without matching scopes",
+			granted:    map[string][]string{"dashboards:read": {"dashboards:uid:1"}},
+			restricted: map[string][]string{"dashboards:read": {"dashboards:uid:2"}},
+			expected:   map[string][]string{},
+		},
+		{
+			desc:       "should keep actions granted without scope",
+			granted:    map[string][]string{"users:read": {""}},
+			restricted: map[string][]string{"users:read": {"users:id:1"}},
+			expected:   map[string][]string{"users:read": {""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.expected, restrictPermissions(tt.granted, tt.restricted))
+		})
+	}
+}
+
 func TestRBACSync_FetchPermissions(t *testing.T) {
 	type testCase struct {
 		name                string
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var (
@@ -71,7 +72,7 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 
 	// Set keyID so we can use it in last used hook
 	r.SetMeta(metaKeyID, strconv.FormatInt(key.ID, 10))
-	if !shouldUpdateLastUsedAt(key) {
+	if !shouldUpdateLastUsedAt(key, web.RemoteAddr(r.HTTPRequest)) {
 		// Hack to just have some value, we will check this key in the hook
 		// and if its not an empty string we will not update last used.
 		r.SetMeta(metaKeySkipLastUsed, "true")
@@ -186,7 +187,7 @@ func (s *APIKey) Hook(ctx context.Context, identity *authn.Identity, r *authn.Re
 		return nil
 	}
 
-	go func(keyID string) {
+	go func(keyID, ip string) {
 		defer func() {
 			if err := recover(); err != nil {
 				s.log.Error("Panic during user last seen sync", "err", err)
@@ -199,11 +200,11 @@ func (s *APIKey) Hook(ctx context.Context, identity *authn.Identity, r *authn.Re
 			return
 		}
 
-		if err := s.apiKeyService.UpdateAPIKeyLastUsedDate(context.Background(), id); err != nil {
+		if err := s.apiKeyService.UpdateAPIKeyLastUsedDate(context.Background(), id, ip); err != nil {
 			s.log.Warn("Failed to update last used date for api key", "id", keyID, "err", err)
 			return
 		}
-	}(r.GetMeta(metaKeyID))
+	}(r.GetMeta(metaKeyID), web.RemoteAddr(r.HTTPRequest))
 
 	return nil
 }
@@ -260,15 +261,34 @@ func newAPIKeyIdentity(key *apikey.APIKey) *authn.Identity {
 }
 
 func newServiceAccountIdentity(key *apikey.APIKey) *authn.Identity {
+	params := authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true}
+	if len(key.Permissions) > 0 {
+		// restricted tokens only get the intersection between the token and the service account permissions
+		restricted := make(map[string][]string, len(key.Permissions))
+		for _, p := range key.Permissions {
+			scopes, ok := restricted[p.Action]
+			if ok && scopes == nil {
+				// action is already allowed for all scopes
+				continue
+			}
+			if p.Scope == "" {
+				restricted[p.Action] = nil
+				continue
+			}
+			restricted[p.Action] = append(scopes, p.Scope)
+		}
+		params.FetchPermissionsParams.RestrictedPermissions = restricted
+	}
+
 	return &authn.Identity{
 		ID:              strconv.FormatInt(*key.ServiceAccountId, 10),
 		Type:            claims.TypeServiceAccount,
 		OrgID:           key.OrgID,
 		AuthenticatedBy: login.APIKeyAuthModule,
-		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		ClientParams:    params,
 	}
 }
 
-func shouldUpdateLastUsedAt(key *apikey.APIKey) bool {
-	return key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > 5*time.Minute
+func shouldUpdateLastUsedAt(key *apikey.APIKey, ip string) bool {
+	return key.LastUsedAt == nil || time.Since(*key.LastUsedAt) > 5*time.Minute || key.LastUsedIP != ip
 }
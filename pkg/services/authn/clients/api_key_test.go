@@ -83,6 +83,42 @@ func TestAPIKey_Authenticate(t *testing.T) {
 				AuthenticatedBy: login.APIKeyAuthModule,
 			},
 		},
+		{
+			desc: "should restrict permissions for token with permissions",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				Header: map[string][]string{
+					"Authorization": {"Bearer " + secret},
+				},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				Permissions: []apikey.Permission{
+					{Action: "dashboards:read", Scope: "dashboards:uid:1"},
+					{Action: "dashboards:read", Scope: "dashboards:uid:2"},
+					{Action: "folders:read"},
+					{Action: "folders:read", Scope: "folders:uid:1"},
+				},
+			},
+			expectedIdentity: &authn.Identity{
+				ID:    "1",
+				Type:  claims.TypeServiceAccount,
+				OrgID: 1,
+				ClientParams: authn.ClientParams{
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					FetchPermissionsParams: authn.FetchPermissionsParams{
+						RestrictedPermissions: map[string][]string{
+							"dashboards:read": {"dashboards:uid:1", "dashboards:uid:2"},
+							"folders:read":    nil,
+						},
+					},
+				},
+				AuthenticatedBy: login.APIKeyAuthModule,
+			},
+		},
 		{
 			desc: "should fail for expired api key",
 			req:  &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{"Authorization": {"Bearer " + secret}}}},
@@ -0,0 +1,162 @@
+// Package lint checks dashboards against best practices, e.g. no deprecated panel types
+// and no hard-coded datasource names. Rules are pluggable, see Rule and Linter.Register.
+package lint
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Problem is a violation of a rule.
+type Problem struct {
+	Rule       string   `json:"rule"`
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	PanelID    int64    `json:"panelId,omitempty"`
+	PanelTitle string   `json:"panelTitle,omitempty"`
+	RefID      string   `json:"refId,omitempty"`
+	Variable   string   `json:"variable,omitempty"`
+}
+
+// Result is the result of linting a dashboard.
+type Result struct {
+	Problems []Problem `json:"problems"`
+}
+
+// HasErrors returns true if a problem has the error severity.
+func (r *Result) HasErrors() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule checks a dashboard. The rule name and severity of the returned problems are set by the linter.
+type Rule interface {
+	Name() string
+	Description() string
+	Severity() Severity
+	Check(dash *Dashboard) []Problem
+}
+
+// RuleInfo describes a rule.
+type RuleInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+}
+
+// Linter checks dashboards against a set of rules.
+type Linter struct {
+	rules map[string]Rule
+}
+
+func New(rules ...Rule) *Linter {
+	l := &Linter{rules: make(map[string]Rule, len(rules))}
+	l.Register(rules...)
+	return l
+}
+
+// NewFromCfg returns a linter with the default rules configured by the [dashboards] lint settings.
+func NewFromCfg(cfg *setting.Cfg) *Linter {
+	l := New(DefaultRules(Settings{
+		MaxPanels:          cfg.DashboardLintMaxPanels,
+		MinRefreshInterval: cfg.DashboardLintMinRefreshInterval,
+	})...)
+	l.Disable(cfg.DashboardLintDisabledRules...)
+	return l
+}
+
+// Register adds rules to the linter, replacing rules with the same name.
+func (l *Linter) Register(rules ...Rule) {
+	for _, r := range rules {
+		l.rules[r.Name()] = r
+	}
+}
+
+// Disable removes rules from the linter.
+func (l *Linter) Disable(names ...string) {
+	for _, name := range names {
+		delete(l.rules, name)
+	}
+}
+
+// Rules returns the rules of the linter sorted by name.
+func (l *Linter) Rules() []RuleInfo {
+	infos := make([]RuleInfo, 0, len(l.rules))
+	for _, r := range l.rules {
+		infos = append(infos, RuleInfo{Name: r.Name(), Description: r.Description(), Severity: r.Severity()})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Lint checks the dashboard JSON model against all rules. Problems are sorted by rule name.
+func (l *Linter) Lint(data *simplejson.Json) *Result {
+	dash := newDashboard(data)
+	result := &Result{Problems: []Problem{}}
+	for _, info := range l.Rules() {
+		rule := l.rules[info.Name]
+		for _, p := range rule.Check(dash) {
+			p.Rule = info.Name
+			if p.Severity == "" {
+				p.Severity = info.Severity
+			}
+			result.Problems = append(result.Problems, p)
+		}
+	}
+	return result
+}
+
+// Dashboard gives rules access to the dashboard JSON model.
+type Dashboard struct {
+	Data *simplejson.Json
+	// Panels are the panels of the dashboard, including the panels of collapsed rows.
+	Panels []Panel
+	// Variables are the template variables of the dashboard.
+	Variables []*simplejson.Json
+}
+
+type Panel struct {
+	ID    int64
+	Title string
+	Type  string
+	Data  *simplejson.Json
+}
+
+func newDashboard(data *simplejson.Json) *Dashboard {
+	dash := &Dashboard{Data: data}
+	var walk func(panels []any)
+	walk = func(panels []any) {
+		for _, p := range panels {
+			panel := simplejson.NewFromAny(p)
+			dash.Panels = append(dash.Panels, Panel{
+				ID:    panel.Get("id").MustInt64(),
+				Title: panel.Get("title").MustString(),
+				Type:  panel.Get("type").MustString(),
+				Data:  panel,
+			})
+			walk(panel.Get("panels").MustArray())
+		}
+	}
+	walk(data.Get("panels").MustArray())
+	// Dashboards of schema version < 16 define panels inside rows.
+	for _, row := range data.Get("rows").MustArray() {
+		walk(simplejson.NewFromAny(row).Get("panels").MustArray())
+	}
+	for _, v := range data.GetPath("templating", "list").MustArray() {
+		dash.Variables = append(dash.Variables, simplejson.NewFromAny(v))
+	}
+	return dash
+}
@@ -0,0 +1,87 @@
+package lint
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const dashboardJSON = `{
+	"title": "Service",
+	"refresh": "5s",
+	"panels": [
+		{"id": 1, "type": "graph", "title": "Requests", "description": "Requests per second",
+		 "datasource": {"type": "prometheus", "uid": "prom"},
+		 "targets": [{"refId": "A"}, {"refId": "B", "datasource": "Prometheus"}]},
+		{"id": 2, "type": "row", "title": "Details", "collapsed": true, "panels": [
+			{"id": 3, "type": "stat", "title": "Errors", "targets": [{"refId": "A"}]}
+		]},
+		{"id": 4, "type": "text", "title": "Help"}
+	],
+	"templating": {"list": [
+		{"name": "ds", "type": "datasource", "query": "prometheus"},
+		{"name": "host", "type": "query", "datasource": "$ds"},
+		{"name": "env", "type": "query", "datasource": "Prometheus"}
+	]}
+}`
+
+func TestLinter(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(dashboardJSON))
+	require.NoError(t, err)
+
+	result := New(DefaultRules(Settings{MaxPanels: 2})...).Lint(data)
+
+	type problem struct {
+		rule     string
+		severity Severity
+		panelID  int64
+		refID    string
+		variable string
+	}
+	var problems []problem
+	for _, p := range result.Problems {
+		require.NotEmpty(t, p.Message)
+		problems = append(problems, problem{p.Rule, p.Severity, p.PanelID, p.RefID, p.Variable})
+	}
+	require.Equal(t, []problem{
+		{rule: RuleDeprecatedPanelType, severity: SeverityWarning, panelID: 1},
+		{rule: RuleHardcodedDatasourceName, severity: SeverityError, panelID: 1, refID: "B"},
+		{rule: RuleHardcodedDatasourceName, severity: SeverityError, variable: "env"},
+		{rule: RuleMaxPanels, severity: SeverityWarning},
+		{rule: RuleMinRefreshInterval, severity: SeverityWarning},
+		{rule: RuleMissingDescription, severity: SeverityWarning},
+		{rule: RuleMissingDescription, severity: SeverityWarning, panelID: 3},
+		{rule: RuleQueryDatasourceUID, severity: SeverityWarning, panelID: 3, refID: "A"},
+	}, problems)
+	require.True(t, result.HasErrors())
+
+	t.Run("Rules can be disabled and added", func(t *testing.T) {
+		linter := New(DefaultRules(Settings{})...)
+		linter.Disable(RuleHardcodedDatasourceName, RuleMissingDescription, RuleDeprecatedPanelType, RuleQueryDatasourceUID, RuleMinRefreshInterval)
+		linter.Register(NewRule("title-prefix", "Titles start with the team", SeverityError, func(dash *Dashboard) []Problem {
+			return []Problem{{Message: "Title does not start with the team"}}
+		}))
+
+		result := linter.Lint(data)
+		require.Len(t, result.Problems, 1)
+		require.Equal(t, "title-prefix", result.Problems[0].Rule)
+		require.Equal(t, SeverityError, result.Problems[0].Severity)
+		require.Len(t, linter.Rules(), 2)
+	})
+
+	t.Run("Settings configure the default rules", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.DashboardLintMinRefreshInterval = time.Second
+		cfg.DashboardLintDisabledRules = []string{RuleMissingDescription}
+
+		for _, p := range NewFromCfg(cfg).Lint(data).Problems {
+			require.NotEqual(t, RuleMissingDescription, p.Rule)
+			require.NotEqual(t, RuleMinRefreshInterval, p.Rule)
+			require.NotEqual(t, RuleMaxPanels, p.Rule)
+		}
+	})
+}
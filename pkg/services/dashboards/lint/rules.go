@@ -0,0 +1,233 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	RuleDeprecatedPanelType     = "deprecated-panel-type"
+	RuleQueryDatasourceUID      = "query-datasource-uid"
+	RuleHardcodedDatasourceName = "hardcoded-datasource-name"
+	RuleMaxPanels               = "max-panels"
+	RuleMissingDescription      = "missing-description"
+	RuleMinRefreshInterval      = "min-refresh-interval"
+)
+
+const (
+	DefaultMaxPanels          = 50
+	DefaultMinRefreshInterval = time.Minute
+)
+
+// builtinDatasources can be referenced by name.
+var builtinDatasources = map[string]bool{
+	"-- Mixed --":     true,
+	"-- Dashboard --": true,
+	"-- Grafana --":   true,
+	"grafana":         true,
+	"__expr__":        true,
+}
+
+// deprecatedPanelTypes are the deprecated panel types and the panel types replacing them.
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// Settings configures the default rules.
+type Settings struct {
+	// MaxPanels is the maximum number of panels of a dashboard.
+	MaxPanels int
+	// MinRefreshInterval is the minimum refresh interval of a dashboard.
+	MinRefreshInterval time.Duration
+}
+
+type rule struct {
+	name        string
+	description string
+	severity    Severity
+	check       func(dash *Dashboard) []Problem
+}
+
+// NewRule returns a rule checking dashboards with the check function.
+func NewRule(name, description string, severity Severity, check func(dash *Dashboard) []Problem) Rule {
+	return &rule{name: name, description: description, severity: severity, check: check}
+}
+
+func (r *rule) Name() string                    { return r.name }
+func (r *rule) Description() string             { return r.description }
+func (r *rule) Severity() Severity              { return r.severity }
+func (r *rule) Check(dash *Dashboard) []Problem { return r.check(dash) }
+
+// DefaultRules returns the built-in rules.
+func DefaultRules(s Settings) []Rule {
+	if s.MaxPanels <= 0 {
+		s.MaxPanels = DefaultMaxPanels
+	}
+	if s.MinRefreshInterval <= 0 {
+		s.MinRefreshInterval = DefaultMinRefreshInterval
+	}
+	return []Rule{
+		NewRule(RuleDeprecatedPanelType, "Panels should not use deprecated panel types", SeverityWarning, checkDeprecatedPanelTypes),
+		NewRule(RuleQueryDatasourceUID, "Queries should reference their datasource by UID", SeverityWarning, checkQueryDatasourceUIDs),
+		NewRule(RuleHardcodedDatasourceName, "Datasources should not be referenced by name", SeverityError, checkHardcodedDatasourceNames),
+		NewRule(RuleMaxPanels, fmt.Sprintf("Dashboards should not have more than %d panels", s.MaxPanels), SeverityWarning, func(dash *Dashboard) []Problem {
+			return checkMaxPanels(dash, s.MaxPanels)
+		}),
+		NewRule(RuleMissingDescription, "Dashboards and panels should have a description", SeverityWarning, checkDescriptions),
+		NewRule(RuleMinRefreshInterval, fmt.Sprintf("Dashboards should not refresh more often than every %s", s.MinRefreshInterval), SeverityWarning, func(dash *Dashboard) []Problem {
+			return checkRefreshInterval(dash, s.MinRefreshInterval)
+		}),
+	}
+}
+
+func panelProblem(p Panel, message string) Problem {
+	return Problem{PanelID: p.ID, PanelTitle: p.Title, Message: message}
+}
+
+func checkDeprecatedPanelTypes(dash *Dashboard) []Problem {
+	var problems []Problem
+	for _, p := range dash.Panels {
+		if replacement, ok := deprecatedPanelTypes[p.Type]; ok {
+			problems = append(problems, panelProblem(p, fmt.Sprintf("Panel type %q is deprecated, use %q instead", p.Type, replacement)))
+		}
+	}
+	return problems
+}
+
+func checkQueryDatasourceUIDs(dash *Dashboard) []Problem {
+	var problems []Problem
+	for _, p := range dash.Panels {
+		if p.Type == "row" {
+			continue
+		}
+		panelDatasource := p.Data.Get("datasource").Interface()
+		for _, t := range p.Data.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(t)
+			ds, ok := target.CheckGet("datasource")
+			datasource := panelDatasource
+			if ok && ds.Interface() != nil {
+				datasource = ds.Interface()
+			}
+			if _, isName := datasource.(string); isName {
+				// Reported by the hardcoded datasource name rule.
+				continue
+			}
+			if datasourceUID(datasource) != "" {
+				continue
+			}
+			problem := panelProblem(p, "Query does not reference a datasource UID and uses the default datasource")
+			problem.RefID = target.Get("refId").MustString()
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+func checkHardcodedDatasourceNames(dash *Dashboard) []Problem {
+	var problems []Problem
+	for _, p := range dash.Panels {
+		if name, ok := hardcodedDatasourceName(p.Data.Get("datasource").Interface()); ok {
+			problems = append(problems, panelProblem(p, fmt.Sprintf("Panel references datasource %q by name", name)))
+		}
+		for _, t := range p.Data.Get("targets").MustArray() {
+			target := simplejson.NewFromAny(t)
+			if name, ok := hardcodedDatasourceName(target.Get("datasource").Interface()); ok {
+				problem := panelProblem(p, fmt.Sprintf("Query references datasource %q by name", name))
+				problem.RefID = target.Get("refId").MustString()
+				problems = append(problems, problem)
+			}
+		}
+	}
+	for _, v := range dash.Variables {
+		if name, ok := hardcodedDatasourceName(v.Get("datasource").Interface()); ok {
+			problems = append(problems, Problem{
+				Variable: v.Get("name").MustString(),
+				Message:  fmt.Sprintf("Variable references datasource %q by name", name),
+			})
+		}
+	}
+	for _, a := range dash.Data.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(a)
+		if name, ok := hardcodedDatasourceName(annotation.Get("datasource").Interface()); ok {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Annotation %q references datasource %q by name", annotation.Get("name").MustString(), name),
+			})
+		}
+	}
+	return problems
+}
+
+func checkMaxPanels(dash *Dashboard, maxPanels int) []Problem {
+	count := 0
+	for _, p := range dash.Panels {
+		if p.Type != "row" {
+			count++
+		}
+	}
+	if count <= maxPanels {
+		return nil
+	}
+	return []Problem{{Message: fmt.Sprintf("Dashboard has %d panels, more than the maximum of %d", count, maxPanels)}}
+}
+
+func checkDescriptions(dash *Dashboard) []Problem {
+	var problems []Problem
+	if strings.TrimSpace(dash.Data.Get("description").MustString()) == "" {
+		problems = append(problems, Problem{Message: "Dashboard has no description"})
+	}
+	for _, p := range dash.Panels {
+		// Rows and text panels are self-explanatory.
+		if p.Type == "row" || p.Type == "text" {
+			continue
+		}
+		if strings.TrimSpace(p.Data.Get("description").MustString()) == "" {
+			problems = append(problems, panelProblem(p, "Panel has no description"))
+		}
+	}
+	return problems
+}
+
+func checkRefreshInterval(dash *Dashboard, minInterval time.Duration) []Problem {
+	refresh, _ := dash.Data.Get("refresh").Interface().(string)
+	if refresh == "" || refresh == "auto" {
+		return nil
+	}
+	d, err := gtime.ParseDuration(refresh)
+	if err != nil {
+		return []Problem{{Severity: SeverityError, Message: fmt.Sprintf("Refresh interval %q is invalid", refresh)}}
+	}
+	if d >= minInterval {
+		return nil
+	}
+	return []Problem{{Message: fmt.Sprintf("Refresh interval %s is shorter than %s", refresh, minInterval)}}
+}
+
+// datasourceUID returns the UID of a datasource reference object.
+func datasourceUID(ds any) string {
+	ref, ok := ds.(map[string]any)
+	if !ok {
+		return ""
+	}
+	uid, _ := ref["uid"].(string)
+	return uid
+}
+
+// hardcodedDatasourceName returns the datasource name of a legacy datasource reference by name.
+// References to datasource variables and built-in datasources are allowed.
+func hardcodedDatasourceName(ds any) (string, bool) {
+	name, ok := ds.(string)
+	if !ok || name == "" || strings.HasPrefix(name, "$") || builtinDatasources[name] {
+		return "", false
+	}
+	return name, true
+}
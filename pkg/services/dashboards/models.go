@@ -221,6 +221,8 @@ type DashboardProvisioning struct {
 	ExternalID  string `xorm:"external_id"`
 	CheckSum    string
 	Updated     int64
+	// CommitSHA is the commit of the git repository the dashboard was provisioned from.
+	CommitSHA string `xorm:"commit_sha"`
 }
 
 type DeleteDashboardCommand struct {
@@ -40,7 +40,10 @@ func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd
 		}
 		cmd.DeletedRows, _ = expiredResponse.RowsAffected()
 
-		return nil
+		// Remove the expired shares and the shares of deleted snapshots
+		deleteSharesSQL := "DELETE FROM dashboard_snapshot_share WHERE expires < ? OR snapshot_id NOT IN (SELECT id FROM dashboard_snapshot)"
+		_, err = sess.Exec(deleteSharesSQL, time.Now())
+		return err
 	})
 }
 
@@ -61,6 +64,8 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			External:           cmd.External,
 			ExternalURL:        cmd.ExternalURL,
 			ExternalDeleteURL:  cmd.ExternalDeleteURL,
+			RefreshInterval:    cmd.RefreshInterval,
+			ShareLinksOnly:     cmd.ShareLinksOnly,
 			Dashboard:          simplejson.New(),
 			DashboardEncrypted: cmd.DashboardEncrypted,
 			Expires:            expires,
@@ -80,6 +85,11 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 
 func (d *DashboardSnapshotStore) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
 	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var sharesSQL = "DELETE FROM dashboard_snapshot_share WHERE snapshot_id IN (SELECT id FROM dashboard_snapshot WHERE delete_key=?)"
+		if _, err := sess.Exec(sharesSQL, cmd.DeleteKey); err != nil {
+			return err
+		}
+
 		var rawSQL = "DELETE FROM dashboard_snapshot WHERE delete_key=?"
 		_, err := sess.Exec(rawSQL, cmd.DeleteKey)
 		return err
@@ -89,7 +99,7 @@ func (d *DashboardSnapshotStore) DeleteDashboardSnapshot(ctx context.Context, cm
 func (d *DashboardSnapshotStore) GetDashboardSnapshot(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotQuery) (*dashboardsnapshots.DashboardSnapshot, error) {
 	var queryResult *dashboardsnapshots.DashboardSnapshot
 	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
-		snapshot := dashboardsnapshots.DashboardSnapshot{Key: query.Key, DeleteKey: query.DeleteKey}
+		snapshot := dashboardsnapshots.DashboardSnapshot{ID: query.ID, Key: query.Key, DeleteKey: query.DeleteKey}
 		has, err := sess.Get(&snapshot)
 
 		if err != nil {
@@ -151,3 +161,91 @@ func (d *DashboardSnapshotStore) SearchDashboardSnapshots(ctx context.Context, q
 	}
 	return queryResult, nil
 }
+
+// GetSnapshotsToRefresh returns the snapshots of all organizations which have a refresh interval and are not expired.
+func (d *DashboardSnapshotStore) GetSnapshotsToRefresh(ctx context.Context, query *dashboardsnapshots.GetSnapshotsToRefreshQuery) (dashboardsnapshots.DashboardSnapshotsList, error) {
+	snapshots := make(dashboardsnapshots.DashboardSnapshotsList, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard_snapshot").
+			Where("refresh_interval > 0 AND external = ? AND expires > ?", d.store.GetDialect().BooleanStr(false), time.Now()).
+			Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+func (d *DashboardSnapshotStore) UpdateDashboardSnapshotData(ctx context.Context, cmd *dashboardsnapshots.UpdateDashboardSnapshotDataCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		snapshot := &dashboardsnapshots.DashboardSnapshot{
+			DashboardEncrypted: cmd.DashboardEncrypted,
+			Updated:            time.Now(),
+		}
+		affected, err := sess.ID(cmd.ID).Cols("dashboard_encrypted", "updated").Update(snapshot)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return dashboardsnapshots.ErrBaseNotFound.Errorf("dashboard snapshot not found")
+		}
+		return nil
+	})
+}
+
+func (d *DashboardSnapshotStore) CreateDashboardSnapshotShare(ctx context.Context, share *dashboardsnapshots.DashboardSnapshotShare) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(share)
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) GetDashboardSnapshotShare(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotShareQuery) (*dashboardsnapshots.DashboardSnapshotShare, error) {
+	share := &dashboardsnapshots.DashboardSnapshotShare{Key: query.Key}
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Get(share)
+		if err != nil {
+			return err
+		} else if !has {
+			return dashboardsnapshots.ErrShareNotFound.Errorf("dashboard snapshot share not found")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func (d *DashboardSnapshotStore) GetDashboardSnapshotShares(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotSharesQuery) ([]*dashboardsnapshots.DashboardSnapshotShare, error) {
+	shares := make([]*dashboardsnapshots.DashboardSnapshotShare, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("snapshot_id = ?", query.SnapshotID).Asc("created").Find(&shares)
+	})
+	return shares, err
+}
+
+func (d *DashboardSnapshotStore) DeleteDashboardSnapshotShare(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotShareCommand) error {
+	return d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Delete(&dashboardsnapshots.DashboardSnapshotShare{Key: cmd.Key, SnapshotID: cmd.SnapshotID})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return dashboardsnapshots.ErrShareNotFound.Errorf("dashboard snapshot share not found")
+		}
+		return nil
+	})
+}
+
+func (d *DashboardSnapshotStore) CountDashboardSnapshotShareView(ctx context.Context, share *dashboardsnapshots.DashboardSnapshotShare) (bool, error) {
+	var counted bool
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "UPDATE dashboard_snapshot_share SET view_count = view_count + 1 WHERE id = ? AND (max_views = 0 OR view_count < max_views)"
+		res, err := sess.Exec(rawSQL, share.ID)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		counted = affected == 1
+		return err
+	})
+	return counted, err
+}
@@ -5,3 +5,11 @@ import (
 )
 
 var ErrBaseNotFound = errutil.NotFound("dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+
+var (
+	ErrShareNotFound        = errutil.NotFound("dashboardsnapshots.share-not-found", errutil.WithPublicMessage("Snapshot share not found"))
+	ErrShareExpired         = errutil.NotFound("dashboardsnapshots.share-expired", errutil.WithPublicMessage("Snapshot share has expired"))
+	ErrShareInvalidPassword = errutil.Unauthorized("dashboardsnapshots.share-invalid-password", errutil.WithPublicMessage("Invalid snapshot share password"))
+	ErrShareViewLimit       = errutil.Forbidden("dashboardsnapshots.share-view-limit", errutil.WithPublicMessage("Snapshot share has reached its view limit"))
+	ErrShareBadRequest      = errutil.BadRequest("dashboardsnapshots.share-bad-request")
+)
@@ -20,6 +20,13 @@ type DashboardSnapshot struct {
 	ExternalURL       string `xorm:"external_url"`
 	ExternalDeleteURL string `xorm:"external_delete_url"`
 
+	// RefreshInterval is the interval in seconds at which the data of the snapshot is
+	// captured again, 0 if the snapshot is never refreshed.
+	RefreshInterval int64
+	// ShareLinksOnly restricts the access by key to signed in users of the organization,
+	// everyone else has to use a share link.
+	ShareLinksOnly bool
+
 	Expires time.Time
 	Created time.Time
 	Updated time.Time
@@ -38,6 +45,9 @@ type DashboardSnapshotDTO struct {
 	External    bool   `json:"external"`
 	ExternalURL string `json:"externalUrl" xorm:"external_url"`
 
+	RefreshInterval int64 `json:"refreshInterval"`
+	ShareLinksOnly  bool  `json:"shareLinksOnly"`
+
 	Expires time.Time `json:"expires"`
 	Created time.Time `json:"created"`
 	Updated time.Time `json:"updated"`
@@ -63,12 +73,28 @@ type CreateDashboardSnapshotCommand struct {
 	// required:false
 	DeleteKey string `json:"deleteKey"`
 
+	// Interval in seconds at which the data of the snapshot is captured again by running the queries
+	// of the dashboard as the user who created the snapshot. Not supported by external snapshots.
+	// required:false
+	RefreshInterval int64 `json:"refreshInterval"`
+
+	// Restrict the access to the snapshot to share links, except for signed in users of the organization.
+	// required:false
+	ShareLinksOnly bool `json:"shareLinksOnly"`
+
 	OrgID  int64 `json:"-"`
 	UserID int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
 }
 
+type UpdateDashboardSnapshotDataCommand struct {
+	ID        int64
+	Dashboard *simplejson.Json
+
+	DashboardEncrypted []byte
+}
+
 type DeleteDashboardSnapshotCommand struct {
 	DeleteKey string `json:"-"`
 }
@@ -77,7 +103,11 @@ type DeleteExpiredSnapshotsCommand struct {
 	DeletedRows int64
 }
 
+// GetSnapshotsToRefreshQuery returns the snapshots with a refresh interval which are not expired.
+type GetSnapshotsToRefreshQuery struct{}
+
 type GetDashboardSnapshotQuery struct {
+	ID        int64
 	Key       string
 	DeleteKey string
 }
@@ -97,3 +127,61 @@ type CreateExternalSnapshotResponse struct {
 	Url       string `json:"url"`
 	DeleteUrl string `json:"deleteUrl"`
 }
+
+// DashboardSnapshotShare is a link to a snapshot which can expire and be protected by a password.
+type DashboardSnapshotShare struct {
+	ID         int64  `xorm:"pk autoincr 'id'" json:"-"`
+	Key        string `json:"key"`
+	OrgID      int64  `xorm:"org_id" json:"-"`
+	SnapshotID int64  `xorm:"snapshot_id" json:"-"`
+	// Password is the hash of the password, empty if the share is not protected.
+	Password string `json:"-"`
+	Salt     string `json:"-"`
+	// MaxViews is the number of times the share can be viewed, 0 if unlimited.
+	MaxViews  int64 `json:"maxViews"`
+	ViewCount int64 `json:"viewCount"`
+	CreatedBy int64 `json:"createdBy"`
+
+	Expires time.Time `json:"expires"`
+	Created time.Time `json:"created"`
+}
+
+// DashboardSnapshotShareDTO without password
+type DashboardSnapshotShareDTO struct {
+	DashboardSnapshotShare
+	Protected bool   `json:"protected"`
+	URL       string `json:"url"`
+}
+
+type CreateDashboardSnapshotShareCommand struct {
+	// Time in seconds until the share expires, the share does not expire if 0.
+	ExpiresIn int64 `json:"expiresIn"`
+	// Password protecting the share, optional.
+	Password string `json:"password"`
+	// Number of times the share can be viewed, unlimited if 0.
+	MaxViews int64 `json:"maxViews"`
+
+	SnapshotID int64 `json:"-"`
+	OrgID      int64 `json:"-"`
+	CreatedBy  int64 `json:"-"`
+}
+
+type GetDashboardSnapshotShareQuery struct {
+	Key string
+}
+
+type GetDashboardSnapshotSharesQuery struct {
+	SnapshotID int64
+}
+
+type DeleteDashboardSnapshotShareCommand struct {
+	Key        string
+	SnapshotID int64
+}
+
+// ViewDashboardSnapshotShareCommand returns the snapshot of a share, after checking the expiry,
+// password and views of the share and counting the view.
+type ViewDashboardSnapshotShareCommand struct {
+	Key      string
+	Password string
+}
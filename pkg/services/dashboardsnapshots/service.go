@@ -17,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -29,6 +30,12 @@ type Service interface {
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
 	ValidateDashboardExists(context.Context, int64, string) error
+	GetSnapshotsToRefresh(context.Context, *GetSnapshotsToRefreshQuery) (DashboardSnapshotsList, error)
+	UpdateDashboardSnapshotData(context.Context, *UpdateDashboardSnapshotDataCommand) error
+	CreateDashboardSnapshotShare(context.Context, *CreateDashboardSnapshotShareCommand) (*DashboardSnapshotShare, error)
+	GetDashboardSnapshotShares(context.Context, *GetDashboardSnapshotSharesQuery) ([]*DashboardSnapshotShare, error)
+	DeleteDashboardSnapshotShare(context.Context, *DeleteDashboardSnapshotShareCommand) error
+	ViewDashboardSnapshotShare(context.Context, *ViewDashboardSnapshotShareCommand) (*DashboardSnapshot, error)
 }
 
 var client = &http.Client{
@@ -230,3 +237,31 @@ func DeleteWithKey(ctx context.Context, key string, svc Service) error {
 
 	return svc.DeleteDashboardSnapshot(ctx, cmd)
 }
+
+// CanManage returns whether the user can share and refresh the snapshot, which requires
+// to have created the snapshot or to be able to edit the dashboard of the snapshot.
+func CanManage(ctx context.Context, snapshot *DashboardSnapshot, user identity.Requester) (bool, error) {
+	if snapshot.OrgID != user.GetOrgID() {
+		return false, nil
+	}
+	if userID, err := identity.UserIdentifier(user.GetID()); err == nil && userID == snapshot.UserID {
+		return true, nil
+	}
+
+	dashboardID := snapshot.Dashboard.Get("id").MustInt64()
+	if dashboardID == 0 {
+		return false, nil
+	}
+	g, err := guardian.New(ctx, dashboardID, user.GetOrgID(), user)
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	canEdit, err := g.CanEdit()
+	if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return false, err
+	}
+	return canEdit, nil
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	refreshCheckInterval = time.Minute
+	defaultMaxDataPoints = 1000
+	minIntervalMs        = 1000
+)
+
+var errRefreshAccessDenied = errors.New("the user who created the snapshot cannot view the dashboard")
+
+// Refresher captures the data of snapshots again by running the queries of their dashboard as
+// the user who created the snapshot, at the refresh interval of the snapshot or on demand.
+type Refresher struct {
+	cfg                  *setting.Cfg
+	log                  log.Logger
+	snapshotService      dashboardsnapshots.Service
+	dashboardService     dashboards.DashboardService
+	queryService         query.Service
+	userService          user.Service
+	accessControlService accesscontrol.Service
+}
+
+func ProvideRefresher(cfg *setting.Cfg, routeRegister routing.RouteRegister, snapshotService dashboardsnapshots.Service,
+	dashboardService dashboards.DashboardService, queryService query.Service, userService user.Service,
+	accessControlService accesscontrol.Service) *Refresher {
+	r := &Refresher{
+		cfg:                  cfg,
+		log:                  log.New("dashboardsnapshots.refresher"),
+		snapshotService:      snapshotService,
+		dashboardService:     dashboardService,
+		queryService:         queryService,
+		userService:          userService,
+		accessControlService: accessControlService,
+	}
+
+	routeRegister.Post("/api/snapshots/:key/refresh", middleware.ReqSignedIn, routing.Wrap(r.refreshHandler))
+
+	return r
+}
+
+func (r *Refresher) IsDisabled() bool {
+	return !r.cfg.SnapshotEnabled
+}
+
+// Run refreshes the snapshots when their refresh interval has elapsed. Instances of a cluster may
+// refresh a snapshot at the same time, which only results in the same data being stored twice.
+func (r *Refresher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(refreshCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.refreshDue(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (r *Refresher) refreshDue(ctx context.Context) {
+	snapshots, err := r.snapshotService.GetSnapshotsToRefresh(ctx, &dashboardsnapshots.GetSnapshotsToRefreshQuery{})
+	if err != nil {
+		r.log.Error("Failed to get snapshots to refresh", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, s := range snapshots {
+		if s.Updated.Add(time.Duration(s.RefreshInterval) * time.Second).After(now) {
+			continue
+		}
+		snapshot, err := r.snapshotService.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: s.Key})
+		if err != nil {
+			r.log.Error("Failed to get snapshot", "snapshotId", s.ID, "error", err)
+			continue
+		}
+		if err := r.Refresh(ctx, snapshot); err != nil {
+			r.log.Warn("Failed to refresh snapshot", "snapshotId", s.ID, "orgId", s.OrgID, "error", err)
+		}
+	}
+}
+
+// Refresh replaces the data of the panels of the snapshot with the results of the queries of the
+// dashboard, in the time range and with the template variable values of the snapshot.
+func (r *Refresher) Refresh(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) error {
+	if snapshot.External {
+		return fmt.Errorf("external snapshots cannot be refreshed")
+	}
+
+	creator, err := r.getCreator(ctx, snapshot)
+	if err != nil {
+		return err
+	}
+
+	dash, err := r.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: snapshot.Dashboard.Get("uid").MustString(), OrgID: snapshot.OrgID})
+	if err != nil {
+		return err
+	}
+	g, err := guardian.NewByDashboard(ctx, dash, snapshot.OrgID, creator)
+	if err != nil {
+		return err
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return errRefreshAccessDenied
+	}
+
+	from := snapshot.Dashboard.GetPath("time", "from").MustString("now-6h")
+	to := snapshot.Dashboard.GetPath("time", "to").MustString("now")
+	queries := panelQueries(dash.Data)
+	variables := newTemplateVariables(snapshot.Dashboard)
+
+	for _, panel := range flattenPanels(snapshot.Dashboard) {
+		panelID := panel.Get("id").MustInt64()
+		targets := queries[panelID]
+		if len(targets) == 0 {
+			continue
+		}
+		if err := variables.interpolate(targets); err != nil {
+			return fmt.Errorf("failed to query panel %d: %w", panelID, err)
+		}
+		setQueryIntervals(targets, from, to)
+
+		resp, err := r.queryService.QueryData(ctx, creator, false, dtos.MetricRequest{From: from, To: to, Queries: targets})
+		if err != nil {
+			return fmt.Errorf("failed to query panel %d: %w", panelID, err)
+		}
+		frames, err := snapshotData(targets, resp)
+		if err != nil {
+			return fmt.Errorf("failed to query panel %d: %w", panelID, err)
+		}
+		panel.Set("snapshotData", frames)
+	}
+
+	snapshot.Dashboard.SetPath([]string{"snapshot", "timestamp"}, time.Now().UTC().Format(time.RFC3339))
+
+	return r.snapshotService.UpdateDashboardSnapshotData(ctx, &dashboardsnapshots.UpdateDashboardSnapshotDataCommand{
+		ID:        snapshot.ID,
+		Dashboard: snapshot.Dashboard,
+	})
+}
+
+// getCreator returns the user who created the snapshot, with the permissions of the user in the
+// organization of the snapshot.
+func (r *Refresher) getCreator(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) (*user.SignedInUser, error) {
+	creator, err := accesscontrol.GetSignedInUserWithPermissions(ctx, r.userService, r.accessControlService, snapshot.UserID, snapshot.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the user who created the snapshot: %w", err)
+	}
+	return creator, nil
+}
+
+func (r *Refresher) refreshHandler(c *contextmodel.ReqContext) response.Response {
+	if !r.cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	snapshot, err := r.snapshotService.GetDashboardSnapshot(c.Req.Context(), &dashboardsnapshots.GetDashboardSnapshotQuery{Key: web.Params(c.Req)[":key"]})
+	if err != nil {
+		return response.Err(err)
+	}
+	canManage, err := dashboardsnapshots.CanManage(c.Req.Context(), snapshot, c.SignedInUser)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Error while checking permissions for snapshot", err)
+	}
+	if !canManage {
+		return response.Error(http.StatusForbidden, "Access denied to this snapshot", nil)
+	}
+
+	if err := r.Refresh(c.Req.Context(), snapshot); err != nil {
+		if errors.Is(err, errRefreshAccessDenied) {
+			return response.Error(http.StatusForbidden, err.Error(), err)
+		}
+		if errors.Is(err, errRefreshTemplateVariables) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to refresh snapshot", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{"message": "Snapshot refreshed"})
+}
+
+// flattenPanels returns the panels of the dashboard, including the panels of rows.
+func flattenPanels(dashboard *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, p := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		if panel.Get("type").MustString() == "row" {
+			panels = append(panels, flattenPanels(panel)...)
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// panelQueries returns the queries of the panels of the dashboard by panel ID, with the data
+// source of the panel if the query does not set one.
+func panelQueries(dashboard *simplejson.Json) map[int64][]*simplejson.Json {
+	queries := make(map[int64][]*simplejson.Json)
+	for _, panel := range flattenPanels(dashboard) {
+		hasExpression := false
+		targets := panel.Get("targets").MustArray()
+		for _, t := range targets {
+			if simplejson.NewFromAny(t).Get("datasource").Get("type").MustString() == "__expr__" {
+				hasExpression = true
+			}
+		}
+
+		var panelQueries []*simplejson.Json
+		for _, t := range targets {
+			// Copy the query so that the dashboard is not modified
+			target := simplejson.NewFromAny(t)
+			raw, err := target.MarshalJSON()
+			if err != nil {
+				continue
+			}
+			q, err := simplejson.NewJson(raw)
+			if err != nil {
+				continue
+			}
+			// Hidden queries are only needed by expressions
+			if !hasExpression && q.Get("hide").MustBool() {
+				continue
+			}
+			if _, ok := q.CheckGet("datasource"); !ok {
+				if ds, ok := panel.CheckGet("datasource"); ok {
+					q.Set("datasource", ds.Interface())
+				}
+			}
+			if _, ok := q.CheckGet("maxDataPoints"); !ok {
+				q.Set("maxDataPoints", panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints))
+			}
+			panelQueries = append(panelQueries, q)
+		}
+		if len(panelQueries) > 0 {
+			queries[panel.Get("id").MustInt64()] = panelQueries
+		}
+	}
+	return queries
+}
+
+// setQueryIntervals sets the interval of queries without one so that they return at most
+// maxDataPoints points in the time range.
+func setQueryIntervals(queries []*simplejson.Json, from, to string) {
+	timeRange := gtime.NewTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom()
+	if err != nil {
+		return
+	}
+	toTime, err := timeRange.ParseTo()
+	if err != nil {
+		return
+	}
+	for _, q := range queries {
+		if _, ok := q.CheckGet("intervalMs"); ok {
+			continue
+		}
+		maxDataPoints := q.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+		intervalMs := toTime.Sub(fromTime).Milliseconds() / max(maxDataPoints, 1)
+		q.Set("intervalMs", max(intervalMs, minIntervalMs))
+	}
+}
+
+// snapshotData returns the frames of the responses of the queries in the format of the
+// snapshotData field of snapshot panels.
+func snapshotData(queries []*simplejson.Json, resp *backend.QueryDataResponse) ([]any, error) {
+	result := make([]any, 0)
+	for _, q := range queries {
+		refID := q.Get("refId").MustString("A")
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		if res.Error != nil {
+			return nil, fmt.Errorf("query %s: %w", refID, res.Error)
+		}
+		for _, frame := range res.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			raw, err := data.FrameToJSON(frame, data.IncludeAll)
+			if err != nil {
+				return nil, err
+			}
+			var frameJSON any
+			if err := json.Unmarshal(raw, &frameJSON); err != nil {
+				return nil, err
+			}
+			result = append(result, frameJSON)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestPanelQueries(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"panels": [
+			{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "maxDataPoints": 500,
+			 "targets": [{"refId": "A", "expr": "up"}, {"refId": "B", "hide": true}]},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [
+				{"id": 3, "type": "stat", "targets": [{"refId": "A", "datasource": {"type": "loki", "uid": "loki"}}]}
+			]},
+			{"id": 4, "type": "text"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	queries := panelQueries(dashboard)
+	require.Len(t, queries, 2)
+
+	require.Len(t, queries[1], 1)
+	require.Equal(t, "A", queries[1][0].Get("refId").MustString())
+	require.Equal(t, "prom", queries[1][0].GetPath("datasource", "uid").MustString())
+	require.Equal(t, int64(500), queries[1][0].Get("maxDataPoints").MustInt64())
+
+	require.Len(t, queries[3], 1)
+	require.Equal(t, "loki", queries[3][0].GetPath("datasource", "uid").MustString())
+	require.Equal(t, int64(defaultMaxDataPoints), queries[3][0].Get("maxDataPoints").MustInt64())
+
+	// The queries are copies of the targets of the dashboard
+	_, ok := dashboard.Get("panels").GetIndex(0).Get("targets").GetIndex(0).CheckGet("maxDataPoints")
+	require.False(t, ok)
+
+	setQueryIntervals(queries[1], "1700000000000", "1700003600000")
+	require.Equal(t, int64(7200), queries[1][0].Get("intervalMs").MustInt64())
+}
+
+func TestSnapshotData(t *testing.T) {
+	queries := []*simplejson.Json{
+		simplejson.NewFromAny(map[string]any{"refId": "A"}),
+		simplejson.NewFromAny(map[string]any{"refId": "B"}),
+	}
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("a", data.NewField("value", nil, []float64{1, 2}))}},
+		"B": {Frames: data.Frames{data.NewFrame("b", data.NewField("value", nil, []float64{3}))}},
+	}}
+
+	frames, err := snapshotData(queries, resp)
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+
+	first := simplejson.NewFromAny(frames[0])
+	require.Equal(t, "A", first.GetPath("schema", "refId").MustString())
+	require.Equal(t, "a", first.GetPath("schema", "name").MustString())
+
+	t.Run("query errors fail the refresh", func(t *testing.T) {
+		resp.Responses["B"] = backend.DataResponse{Error: errors.New("query failed")}
+		_, err := snapshotData(queries, resp)
+		require.Error(t, err)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	cfg := setting.NewCfg()
+	dashboardJSON := `{
+		"uid": "abc",
+		"time": {"from": "now-1h", "to": "now"},
+		"panels": [{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"refId": "A", "expr": "up"}]}]
+	}`
+	dashData, err := simplejson.NewJson([]byte(dashboardJSON))
+	require.NoError(t, err)
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", OrgID: 1, Data: dashData}
+
+	newSnapshot := func(t *testing.T) *dashboardsnapshots.DashboardSnapshot {
+		snapshotDashboard, err := simplejson.NewJson([]byte(dashboardJSON))
+		require.NoError(t, err)
+		return &dashboardsnapshots.DashboardSnapshot{ID: 1, Key: "key", OrgID: 1, UserID: 2, Dashboard: snapshotDashboard}
+	}
+
+	setup := func(t *testing.T, permissions []accesscontrol.Permission) (*Refresher, *query.FakeQueryService, *dashboardsnapshots.MockService) {
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(dash, nil)
+
+		origNewByDashboard := guardian.NewByDashboard
+		t.Cleanup(func() { guardian.NewByDashboard = origNewByDashboard })
+		guardian.InitAccessControlGuardian(cfg, acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), dashSvc)
+
+		queryService := query.NewFakeQueryService(t)
+		snapshotService := dashboardsnapshots.NewMockService(t)
+		r := &Refresher{
+			cfg:              cfg,
+			log:              log.NewNopLogger(),
+			snapshotService:  snapshotService,
+			dashboardService: dashSvc,
+			queryService:     queryService,
+			// The user is returned without permissions, as by the user store.
+			userService:          &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer}},
+			accessControlService: actest.FakeService{ExpectedPermissions: permissions},
+		}
+		return r, queryService, snapshotService
+	}
+
+	t.Run("queries the data with the permissions of the user who created the snapshot", func(t *testing.T) {
+		r, queryService, snapshotService := setup(t, []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("abc")},
+			{Action: "datasources:query", Scope: "datasources:uid:prom"},
+		})
+		queryService.On("QueryData", mock.Anything, mock.MatchedBy(func(u identity.Requester) bool {
+			return len(u.GetPermissions()["datasources:query"]) == 1
+		}), false, mock.Anything).Return(&backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))}},
+		}}, nil)
+		var updated *dashboardsnapshots.UpdateDashboardSnapshotDataCommand
+		snapshotService.On("UpdateDashboardSnapshotData", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			updated = args.Get(1).(*dashboardsnapshots.UpdateDashboardSnapshotDataCommand)
+		}).Return(nil)
+
+		require.NoError(t, r.Refresh(context.Background(), newSnapshot(t)))
+		require.NotNil(t, updated)
+		frames := updated.Dashboard.Get("panels").GetIndex(0).Get("snapshotData").MustArray()
+		require.Len(t, frames, 1)
+	})
+
+	t.Run("fails if the user who created the snapshot cannot view the dashboard", func(t *testing.T) {
+		r, _, _ := setup(t, nil)
+
+		require.ErrorIs(t, r.Refresh(context.Background(), newSnapshot(t)), errRefreshAccessDenied)
+	})
+}
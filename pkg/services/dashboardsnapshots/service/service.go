@@ -2,11 +2,14 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type ServiceImpl struct {
@@ -83,3 +86,112 @@ func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashb
 func (s *ServiceImpl) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
 	return s.store.DeleteExpiredSnapshots(ctx, cmd)
 }
+
+func (s *ServiceImpl) GetSnapshotsToRefresh(ctx context.Context, query *dashboardsnapshots.GetSnapshotsToRefreshQuery) (dashboardsnapshots.DashboardSnapshotsList, error) {
+	return s.store.GetSnapshotsToRefresh(ctx, query)
+}
+
+func (s *ServiceImpl) UpdateDashboardSnapshotData(ctx context.Context, cmd *dashboardsnapshots.UpdateDashboardSnapshotDataCommand) error {
+	marshalledData, err := cmd.Dashboard.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
+	if err != nil {
+		return err
+	}
+
+	cmd.DashboardEncrypted = encryptedDashboard
+
+	return s.store.UpdateDashboardSnapshotData(ctx, cmd)
+}
+
+func (s *ServiceImpl) CreateDashboardSnapshotShare(ctx context.Context, cmd *dashboardsnapshots.CreateDashboardSnapshotShareCommand) (*dashboardsnapshots.DashboardSnapshotShare, error) {
+	if cmd.ExpiresIn < 0 {
+		return nil, dashboardsnapshots.ErrShareBadRequest.Errorf("expiresIn must not be negative")
+	}
+	if cmd.MaxViews < 0 {
+		return nil, dashboardsnapshots.ErrShareBadRequest.Errorf("maxViews must not be negative")
+	}
+
+	key, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	share := &dashboardsnapshots.DashboardSnapshotShare{
+		Key:        key,
+		OrgID:      cmd.OrgID,
+		SnapshotID: cmd.SnapshotID,
+		MaxViews:   cmd.MaxViews,
+		CreatedBy:  cmd.CreatedBy,
+		Expires:    now.Add(time.Hour * 24 * 365 * 50),
+		Created:    now,
+	}
+	if cmd.ExpiresIn > 0 {
+		share.Expires = now.Add(time.Second * time.Duration(cmd.ExpiresIn))
+	}
+
+	if cmd.Password != "" {
+		if share.Salt, err = util.GetRandomString(10); err != nil {
+			return nil, err
+		}
+		if share.Password, err = util.EncodePassword(cmd.Password, share.Salt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.CreateDashboardSnapshotShare(ctx, share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+func (s *ServiceImpl) GetDashboardSnapshotShares(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotSharesQuery) ([]*dashboardsnapshots.DashboardSnapshotShare, error) {
+	return s.store.GetDashboardSnapshotShares(ctx, query)
+}
+
+func (s *ServiceImpl) DeleteDashboardSnapshotShare(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotShareCommand) error {
+	return s.store.DeleteDashboardSnapshotShare(ctx, cmd)
+}
+
+func (s *ServiceImpl) ViewDashboardSnapshotShare(ctx context.Context, cmd *dashboardsnapshots.ViewDashboardSnapshotShareCommand) (*dashboardsnapshots.DashboardSnapshot, error) {
+	share, err := s.store.GetDashboardSnapshotShare(ctx, &dashboardsnapshots.GetDashboardSnapshotShareQuery{Key: cmd.Key})
+	if err != nil {
+		return nil, err
+	}
+
+	if share.Expires.Before(time.Now()) {
+		return nil, dashboardsnapshots.ErrShareExpired.Errorf("dashboard snapshot share expired")
+	}
+
+	if share.Password != "" {
+		password, err := util.EncodePassword(cmd.Password, share.Salt)
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare([]byte(password), []byte(share.Password)) != 1 {
+			return nil, dashboardsnapshots.ErrShareInvalidPassword.Errorf("invalid dashboard snapshot share password")
+		}
+	}
+
+	snapshot, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{ID: share.SnapshotID})
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Expires.Before(time.Now()) {
+		return nil, dashboardsnapshots.ErrBaseNotFound.Errorf("dashboard snapshot expired")
+	}
+
+	counted, err := s.store.CountDashboardSnapshotShareView(ctx, share)
+	if err != nil {
+		return nil, err
+	}
+	if !counted {
+		return nil, dashboardsnapshots.ErrShareViewLimit.Errorf("dashboard snapshot share reached its view limit")
+	}
+
+	return snapshot, nil
+}
@@ -91,6 +91,79 @@ func TestDashboardSnapshotsService(t *testing.T) {
 	})
 }
 
+func TestDashboardSnapshotShares(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	dsStore := dashsnapdb.ProvideStore(sqlStore, cfg)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	s := ProvideService(dsStore, secretsService, &dashboards.FakeDashboardService{})
+	ctx := context.Background()
+
+	dashboard := &common.Unstructured{}
+	require.NoError(t, json.Unmarshal([]byte(`{"id":123}`), dashboard))
+	snapshot, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{
+		Key:       "share-snapshot",
+		DeleteKey: "share-snapshot-delete",
+		OrgID:     1,
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Dashboard: dashboard,
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("protected share with a view limit", func(t *testing.T) {
+		share, err := s.CreateDashboardSnapshotShare(ctx, &dashboardsnapshots.CreateDashboardSnapshotShareCommand{
+			SnapshotID: snapshot.ID,
+			OrgID:      1,
+			Password:   "secret",
+			MaxViews:   1,
+			ExpiresIn:  3600,
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, share.Key)
+		require.NotEqual(t, "secret", share.Password)
+
+		_, err = s.ViewDashboardSnapshotShare(ctx, &dashboardsnapshots.ViewDashboardSnapshotShareCommand{Key: share.Key, Password: "wrong"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrShareInvalidPassword)
+
+		viewed, err := s.ViewDashboardSnapshotShare(ctx, &dashboardsnapshots.ViewDashboardSnapshotShareCommand{Key: share.Key, Password: "secret"})
+		require.NoError(t, err)
+		require.Equal(t, snapshot.ID, viewed.ID)
+		require.Equal(t, int64(123), viewed.Dashboard.Get("id").MustInt64())
+
+		_, err = s.ViewDashboardSnapshotShare(ctx, &dashboardsnapshots.ViewDashboardSnapshotShareCommand{Key: share.Key, Password: "secret"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrShareViewLimit)
+
+		shares, err := s.GetDashboardSnapshotShares(ctx, &dashboardsnapshots.GetDashboardSnapshotSharesQuery{SnapshotID: snapshot.ID})
+		require.NoError(t, err)
+		require.Len(t, shares, 1)
+		require.Equal(t, int64(1), shares[0].ViewCount)
+	})
+
+	t.Run("expired share", func(t *testing.T) {
+		share := &dashboardsnapshots.DashboardSnapshotShare{
+			Key:        "expired",
+			OrgID:      1,
+			SnapshotID: snapshot.ID,
+			Expires:    time.Now().Add(-time.Hour),
+			Created:    time.Now().Add(-2 * time.Hour),
+		}
+		require.NoError(t, dsStore.CreateDashboardSnapshotShare(ctx, share))
+
+		_, err := s.ViewDashboardSnapshotShare(ctx, &dashboardsnapshots.ViewDashboardSnapshotShareCommand{Key: share.Key})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrShareExpired)
+	})
+
+	t.Run("deleting the snapshot deletes its shares", func(t *testing.T) {
+		err := s.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "share-snapshot-delete"})
+		require.NoError(t, err)
+
+		shares, err := s.GetDashboardSnapshotShares(ctx, &dashboardsnapshots.GetDashboardSnapshotSharesQuery{SnapshotID: snapshot.ID})
+		require.NoError(t, err)
+		require.Empty(t, shares)
+	})
+}
+
 func TestValidateDashboardExists(t *testing.T) {
 	sqlStore := db.InitTestReplDB(t)
 	cfg := setting.NewCfg()
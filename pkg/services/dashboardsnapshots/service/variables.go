@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var errRefreshTemplateVariables = errors.New("the queries use template variables that cannot be interpolated")
+
+// variablePattern matches the $var, ${var}, ${var:format} and [[var:format]] syntaxes of template
+// variables in queries.
+var variablePattern = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^}]+))?\}`)
+
+type templateVariable struct {
+	value string
+	text  string
+	// multi is set if more than one value or all the values of the variable are selected.
+	multi bool
+}
+
+// templateVariables holds the values of the template variables of a dashboard, as they were
+// when the snapshot was taken.
+type templateVariables struct {
+	values       map[string]templateVariable
+	adHocFilters bool
+}
+
+func newTemplateVariables(dashboard *simplejson.Json) templateVariables {
+	variables := templateVariables{values: make(map[string]templateVariable)}
+	for _, v := range dashboard.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(v)
+		if variable.Get("type").MustString() == "adhoc" {
+			if len(variable.Get("filters").MustArray()) > 0 {
+				variables.adHocFilters = true
+			}
+			continue
+		}
+		value, multiValue := currentValue(variable.GetPath("current", "value"))
+		text, _ := currentValue(variable.GetPath("current", "text"))
+		variables.values[variable.Get("name").MustString()] = templateVariable{
+			value: value,
+			text:  text,
+			multi: multiValue || value == "$__all",
+		}
+	}
+	return variables
+}
+
+// currentValue returns the selected value of a variable, and whether more than one value is
+// selected.
+func currentValue(current *simplejson.Json) (string, bool) {
+	switch value := current.Interface().(type) {
+	case nil:
+		return "", false
+	case []any:
+		if len(value) == 1 {
+			return fmt.Sprint(value[0]), false
+		}
+		return "", len(value) > 1
+	default:
+		return fmt.Sprint(value), false
+	}
+}
+
+// interpolate replaces the template variables in the string values of the queries with the
+// values of the variables. Variables that are not defined by the dashboard, like the built-in
+// $__interval, are left for the data sources to interpolate.
+func (v templateVariables) interpolate(queries []*simplejson.Json) error {
+	if v.adHocFilters {
+		return fmt.Errorf("%w: ad hoc filters cannot be applied", errRefreshTemplateVariables)
+	}
+	for _, q := range queries {
+		if _, err := v.interpolateValue(q.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v templateVariables) interpolateValue(value any) (any, error) {
+	switch val := value.(type) {
+	case string:
+		return v.interpolateString(val)
+	case map[string]any:
+		for key, item := range val {
+			interpolated, err := v.interpolateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			val[key] = interpolated
+		}
+	case []any:
+		for i, item := range val {
+			interpolated, err := v.interpolateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = interpolated
+		}
+	}
+	return value, nil
+}
+
+func (v templateVariables) interpolateString(s string) (string, error) {
+	var err error
+	result := variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		if err != nil {
+			return match
+		}
+		groups := variablePattern.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		if groups[2] != "" {
+			name, format = groups[2], groups[3]
+		} else if groups[4] != "" {
+			name, format = groups[4], groups[5]
+		}
+
+		variable, ok := v.values[name]
+		if !ok {
+			return match
+		}
+		if variable.multi {
+			err = fmt.Errorf("%w: variable %s has more than one value", errRefreshTemplateVariables, name)
+			return match
+		}
+		formatted, ok := formatVariable(variable, format)
+		if !ok {
+			err = fmt.Errorf("%w: format %s of variable %s is not supported", errRefreshTemplateVariables, format, name)
+			return match
+		}
+		return formatted
+	})
+	return result, err
+}
+
+// formatVariable formats the single value of a variable with the formats that do not depend on
+// the data source.
+func formatVariable(variable templateVariable, format string) (string, bool) {
+	switch format {
+	case "", "raw", "csv", "pipe", "glob", "distributed":
+		return variable.value, true
+	case "text":
+		return variable.text, true
+	case "regex":
+		return regexp.QuoteMeta(variable.value), true
+	case "singlequote":
+		return "'" + strings.ReplaceAll(variable.value, "'", `\'`) + "'", true
+	case "doublequote":
+		return `"` + strings.ReplaceAll(variable.value, `"`, `\"`) + `"`, true
+	case "sqlstring":
+		return "'" + strings.ReplaceAll(variable.value, "'", "''") + "'", true
+	}
+	return "", false
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"templating": {"list": [
+			{"name": "job", "type": "query", "current": {"value": "api.server", "text": "API server"}},
+			{"name": "instance", "type": "custom", "current": {"value": ["host-1"], "text": ["host-1"]}},
+			{"name": "ds", "type": "datasource", "current": {"value": "prom-uid", "text": "Prometheus"}},
+			{"name": "hosts", "type": "custom", "current": {"value": ["host-1", "host-2"], "text": ["host-1", "host-2"]}},
+			{"name": "env", "type": "custom", "current": {"value": ["$__all"], "text": ["All"]}},
+			{"name": "filters", "type": "adhoc", "filters": []}
+		]}
+	}`))
+	require.NoError(t, err)
+	variables := newTemplateVariables(dashboard)
+
+	query := func(t *testing.T, raw string) *simplejson.Json {
+		q, err := simplejson.NewJson([]byte(raw))
+		require.NoError(t, err)
+		return q
+	}
+
+	t.Run("interpolates the values of the snapshot", func(t *testing.T) {
+		q := query(t, `{
+			"refId": "A",
+			"datasource": {"type": "prometheus", "uid": "${ds}"},
+			"expr": "rate(http_requests_total{job=~\"${job:regex}\", instance=\"$instance\"}[$__rate_interval])",
+			"legendFormat": "[[job:text]]"
+		}`)
+		require.NoError(t, variables.interpolate([]*simplejson.Json{q}))
+
+		require.Equal(t, "prom-uid", q.GetPath("datasource", "uid").MustString())
+		require.Equal(t, `rate(http_requests_total{job=~"api\.server", instance="host-1"}[$__rate_interval])`, q.Get("expr").MustString())
+		require.Equal(t, "API server", q.Get("legendFormat").MustString())
+	})
+
+	t.Run("refuses variables with several values", func(t *testing.T) {
+		for _, expr := range []string{"sum by (instance) (up{$hosts})", "up{env=~${env:regex}}"} {
+			err := variables.interpolate([]*simplejson.Json{query(t, `{"expr": "`+expr+`"}`)})
+			require.ErrorIs(t, err, errRefreshTemplateVariables)
+		}
+	})
+
+	t.Run("refuses formats of data sources", func(t *testing.T) {
+		err := variables.interpolate([]*simplejson.Json{query(t, `{"query": "${job:lucene}"}`)})
+		require.ErrorIs(t, err, errRefreshTemplateVariables)
+	})
+
+	t.Run("refuses ad hoc filters", func(t *testing.T) {
+		dashboard.GetPath("templating", "list").GetIndex(5).Set("filters", []any{
+			map[string]any{"key": "job", "operator": "=", "value": "api"},
+		})
+		err := newTemplateVariables(dashboard).interpolate([]*simplejson.Json{query(t, `{"expr": "up"}`)})
+		require.ErrorIs(t, err, errRefreshTemplateVariables)
+	})
+}
@@ -39,6 +39,32 @@ func (_m *MockService) CreateDashboardSnapshot(_a0 context.Context, _a1 *CreateD
 	return r0, r1
 }
 
+// CreateDashboardSnapshotShare provides a mock function with given fields: _a0, _a1
+func (_m *MockService) CreateDashboardSnapshotShare(_a0 context.Context, _a1 *CreateDashboardSnapshotShareCommand) (*DashboardSnapshotShare, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DashboardSnapshotShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *CreateDashboardSnapshotShareCommand) (*DashboardSnapshotShare, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *CreateDashboardSnapshotShareCommand) *DashboardSnapshotShare); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DashboardSnapshotShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *CreateDashboardSnapshotShareCommand) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteDashboardSnapshot provides a mock function with given fields: _a0, _a1
 func (_m *MockService) DeleteDashboardSnapshot(_a0 context.Context, _a1 *DeleteDashboardSnapshotCommand) error {
 	ret := _m.Called(_a0, _a1)
@@ -53,6 +79,20 @@ func (_m *MockService) DeleteDashboardSnapshot(_a0 context.Context, _a1 *DeleteD
 	return r0
 }
 
+// DeleteDashboardSnapshotShare provides a mock function with given fields: _a0, _a1
+func (_m *MockService) DeleteDashboardSnapshotShare(_a0 context.Context, _a1 *DeleteDashboardSnapshotShareCommand) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeleteDashboardSnapshotShareCommand) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteExpiredSnapshots provides a mock function with given fields: _a0, _a1
 func (_m *MockService) DeleteExpiredSnapshots(_a0 context.Context, _a1 *DeleteExpiredSnapshotsCommand) error {
 	ret := _m.Called(_a0, _a1)
@@ -93,6 +133,58 @@ func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboa
 	return r0, r1
 }
 
+// GetDashboardSnapshotShares provides a mock function with given fields: _a0, _a1
+func (_m *MockService) GetDashboardSnapshotShares(_a0 context.Context, _a1 *GetDashboardSnapshotSharesQuery) ([]*DashboardSnapshotShare, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 []*DashboardSnapshotShare
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSnapshotSharesQuery) ([]*DashboardSnapshotShare, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetDashboardSnapshotSharesQuery) []*DashboardSnapshotShare); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*DashboardSnapshotShare)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetDashboardSnapshotSharesQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSnapshotsToRefresh provides a mock function with given fields: _a0, _a1
+func (_m *MockService) GetSnapshotsToRefresh(_a0 context.Context, _a1 *GetSnapshotsToRefreshQuery) (DashboardSnapshotsList, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 DashboardSnapshotsList
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *GetSnapshotsToRefreshQuery) (DashboardSnapshotsList, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *GetSnapshotsToRefreshQuery) DashboardSnapshotsList); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(DashboardSnapshotsList)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *GetSnapshotsToRefreshQuery) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SearchDashboardSnapshots provides a mock function with given fields: _a0, _a1
 func (_m *MockService) SearchDashboardSnapshots(_a0 context.Context, _a1 *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error) {
 	ret := _m.Called(_a0, _a1)
@@ -119,6 +211,20 @@ func (_m *MockService) SearchDashboardSnapshots(_a0 context.Context, _a1 *GetDas
 	return r0, r1
 }
 
+// UpdateDashboardSnapshotData provides a mock function with given fields: _a0, _a1
+func (_m *MockService) UpdateDashboardSnapshotData(_a0 context.Context, _a1 *UpdateDashboardSnapshotDataCommand) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *UpdateDashboardSnapshotDataCommand) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ValidateDashboardExists provides a mock function with given fields: _a0, _a1, _a2
 func (_m *MockService) ValidateDashboardExists(_a0 context.Context, _a1 int64, _a2 string) error {
 	ret := _m.Called(_a0, _a1, _a2)
@@ -133,6 +239,32 @@ func (_m *MockService) ValidateDashboardExists(_a0 context.Context, _a1 int64, _
 	return r0
 }
 
+// ViewDashboardSnapshotShare provides a mock function with given fields: _a0, _a1
+func (_m *MockService) ViewDashboardSnapshotShare(_a0 context.Context, _a1 *ViewDashboardSnapshotShareCommand) (*DashboardSnapshot, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *DashboardSnapshot
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ViewDashboardSnapshotShareCommand) (*DashboardSnapshot, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ViewDashboardSnapshotShareCommand) *DashboardSnapshot); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*DashboardSnapshot)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ViewDashboardSnapshotShareCommand) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewMockService creates a new instance of MockService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockService(t interface {
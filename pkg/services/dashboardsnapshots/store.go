@@ -10,4 +10,14 @@ type Store interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	GetSnapshotsToRefresh(context.Context, *GetSnapshotsToRefreshQuery) (DashboardSnapshotsList, error)
+	UpdateDashboardSnapshotData(context.Context, *UpdateDashboardSnapshotDataCommand) error
+
+	CreateDashboardSnapshotShare(context.Context, *DashboardSnapshotShare) error
+	GetDashboardSnapshotShare(context.Context, *GetDashboardSnapshotShareQuery) (*DashboardSnapshotShare, error)
+	GetDashboardSnapshotShares(context.Context, *GetDashboardSnapshotSharesQuery) ([]*DashboardSnapshotShare, error)
+	DeleteDashboardSnapshotShare(context.Context, *DeleteDashboardSnapshotShareCommand) error
+	// CountDashboardSnapshotShareView counts a view of the share and returns false if the share
+	// has reached its view limit.
+	CountDashboardSnapshotShareView(context.Context, *DashboardSnapshotShare) (bool, error)
 }
@@ -0,0 +1,90 @@
+package dashboardusage
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const defaultDays = 30
+
+func (s *UsageService) registerAPIEndpoints() {
+	s.routeRegister.Get("/api/usage/dashboards/:uid", middleware.ReqSignedIn, routing.Wrap(s.getDashboardUsageHandler))
+}
+
+// swagger:route GET /usage/dashboards/{uid} dashboard_usage getDashboardUsage
+//
+// Get the usage of a dashboard.
+//
+// Returns the views, unique viewers, queries, query errors and average query latency of the
+// dashboard in the last days, by day or month and by panel.
+//
+// Responses:
+// 200: getDashboardUsageResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *UsageService) getDashboardUsageHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	days := c.QueryInt("days")
+	if days == 0 {
+		days = defaultDays
+	}
+	if days < 0 || time.Duration(days)*24*time.Hour > s.cfg.DashboardUsage.Retention {
+		return response.Error(http.StatusBadRequest, ErrInvalidDays.Error(), ErrInvalidDays)
+	}
+
+	dash, err := s.dashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: uid, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	g, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return response.Error(http.StatusForbidden, "Access denied to this dashboard", err)
+	}
+
+	now := s.now().UTC()
+	usage, err := s.GetDashboardUsage(c.Req.Context(), &GetDashboardUsageQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: uid,
+		From:         periodStart(PeriodDay, now).AddDate(0, 0, -days+1),
+		To:           now,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard usage", err)
+	}
+	return response.JSON(http.StatusOK, usage)
+}
+
+// swagger:parameters getDashboardUsage
+type GetDashboardUsageParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Number of days of usage, including today.
+	// in:query
+	// default:30
+	Days int `json:"days"`
+}
+
+// swagger:response getDashboardUsageResponse
+type GetDashboardUsageResponse struct {
+	// in: body
+	Body DashboardUsage `json:"body"`
+}
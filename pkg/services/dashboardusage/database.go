@@ -0,0 +1,352 @@
+package dashboardusage
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// rollupBatchSize is the number of daily rows rolled up in one transaction.
+const rollupBatchSize = 1000
+
+var errConcurrentRollup = errors.New("usage is being rolled up by another instance")
+
+type store struct {
+	db db.DB
+}
+
+// write adds the usage of the buffer to the usage stored in the database.
+func (s *store) write(ctx context.Context, b *buffer) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, usage := range b.usage {
+			if err := addUsage(sess, usage); err != nil {
+				return err
+			}
+		}
+		for key := range b.viewers {
+			viewer := &Viewer{OrgID: key.orgID, DashboardUID: key.dashboardUID, UserID: key.userID, Period: PeriodDay, PeriodStart: key.day}
+			if err := addViewer(sess, viewer); err != nil {
+				return err
+			}
+		}
+		for _, summary := range b.summaries {
+			if err := addSummary(sess, summary); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// addUsage adds the counts to the row of the period, or inserts it if it does not exist.
+func addUsage(sess *db.Session, usage *Usage) error {
+	update := func() (int64, error) {
+		res, err := sess.Exec(`UPDATE dashboard_usage SET views = views + ?, queries = queries + ?, errors = errors + ?, latency_ms = latency_ms + ?
+			WHERE org_id = ? AND dashboard_uid = ? AND panel_id = ? AND period = ? AND period_start = ?`,
+			usage.Views, usage.Queries, usage.Errors, usage.LatencyMs,
+			usage.OrgID, usage.DashboardUID, usage.PanelID, usage.Period, usage.PeriodStart)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	affected, err := update()
+	if err != nil || affected > 0 {
+		return err
+	}
+	if _, err := sess.Insert(usage); err != nil {
+		// Another instance may have inserted the row in the meantime
+		if affected, updateErr := update(); updateErr != nil || affected == 0 {
+			return err
+		}
+	}
+	return nil
+}
+
+func addViewer(sess *db.Session, viewer *Viewer) error {
+	exists := func() (bool, error) {
+		return sess.Table("dashboard_usage_viewer").
+			Where("org_id = ? AND dashboard_uid = ? AND period = ? AND period_start = ? AND user_id = ?",
+				viewer.OrgID, viewer.DashboardUID, viewer.Period, viewer.PeriodStart, viewer.UserID).
+			Exist()
+	}
+
+	ok, err := exists()
+	if err != nil || ok {
+		return err
+	}
+	if _, err := sess.Insert(viewer); err != nil {
+		if ok, existsErr := exists(); existsErr != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+func addSummary(sess *db.Session, summary *Summary) error {
+	update := func() (int64, error) {
+		res, err := sess.Exec(`UPDATE dashboard_usage_summary SET views = views + ?,
+			last_viewed = CASE WHEN last_viewed < ? THEN ? ELSE last_viewed END
+			WHERE org_id = ? AND dashboard_uid = ?`,
+			summary.Views, summary.LastViewed, summary.LastViewed, summary.OrgID, summary.DashboardUID)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+
+	affected, err := update()
+	if err != nil || affected > 0 {
+		return err
+	}
+	if _, err := sess.Insert(summary); err != nil {
+		if affected, updateErr := update(); updateErr != nil || affected == 0 {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *store) getDashboardUsage(ctx context.Context, query *GetDashboardUsageQuery) (*DashboardUsage, error) {
+	result := &DashboardUsage{
+		DashboardUID: query.DashboardUID,
+		From:         query.From,
+		To:           query.To,
+		Periods:      make([]PeriodUsage, 0),
+		Panels:       make([]PanelUsage, 0),
+	}
+
+	// The rows of the periods overlapping the time range
+	periodsSQL := "((period = ? AND period_start >= ?) OR (period = ? AND period_start >= ?)) AND period_start < ?"
+	periodsParams := []any{
+		PeriodDay, periodStart(PeriodDay, query.From).Unix(),
+		PeriodMonth, periodStart(PeriodMonth, query.From).Unix(),
+		query.To.Unix(),
+	}
+
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rows := make([]*Usage, 0)
+		err := sess.Where("org_id = ? AND dashboard_uid = ?", query.OrgID, query.DashboardUID).
+			And(periodsSQL, periodsParams...).
+			Find(&rows)
+		if err != nil {
+			return err
+		}
+		aggregateUsage(result, rows)
+
+		params := append([]any{query.OrgID, query.DashboardUID}, periodsParams...)
+		_, err = sess.SQL("SELECT COUNT(DISTINCT user_id) FROM dashboard_usage_viewer WHERE org_id = ? AND dashboard_uid = ? AND "+periodsSQL, params...).
+			Get(&result.UniqueViewers)
+		if err != nil {
+			return err
+		}
+
+		summary := &Summary{}
+		exists, err := sess.Where("org_id = ? AND dashboard_uid = ?", query.OrgID, query.DashboardUID).Get(summary)
+		if err != nil {
+			return err
+		}
+		if exists {
+			lastViewed := time.Unix(summary.LastViewed, 0).UTC()
+			result.TotalViews = summary.Views
+			result.LastViewed = &lastViewed
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// aggregateUsage sums the rows into the totals, the periods and the panels of the result.
+func aggregateUsage(result *DashboardUsage, rows []*Usage) {
+	type periodKey struct {
+		period Period
+		start  int64
+	}
+	periods := make(map[periodKey]*Usage)
+	panels := make(map[int64]*Usage)
+	var latencyMs int64
+
+	for _, row := range rows {
+		result.Views += row.Views
+		result.Queries += row.Queries
+		result.QueryErrors += row.Errors
+		latencyMs += row.LatencyMs
+
+		key := periodKey{period: row.Period, start: row.PeriodStart}
+		if _, ok := periods[key]; !ok {
+			periods[key] = &Usage{Period: row.Period, PeriodStart: row.PeriodStart}
+		}
+		add(periods[key], row)
+
+		if row.PanelID != 0 {
+			if _, ok := panels[row.PanelID]; !ok {
+				panels[row.PanelID] = &Usage{PanelID: row.PanelID}
+			}
+			add(panels[row.PanelID], row)
+		}
+	}
+	result.AverageLatencyMs = averageLatency(latencyMs, result.Queries)
+
+	for _, p := range periods {
+		result.Periods = append(result.Periods, PeriodUsage{
+			Period:           p.Period,
+			Start:            time.Unix(p.PeriodStart, 0).UTC(),
+			Views:            p.Views,
+			Queries:          p.Queries,
+			QueryErrors:      p.Errors,
+			AverageLatencyMs: averageLatency(p.LatencyMs, p.Queries),
+		})
+	}
+	sort.Slice(result.Periods, func(i, j int) bool {
+		return result.Periods[i].Start.Before(result.Periods[j].Start)
+	})
+
+	for _, p := range panels {
+		result.Panels = append(result.Panels, PanelUsage{
+			PanelID:          p.PanelID,
+			Queries:          p.Queries,
+			QueryErrors:      p.Errors,
+			AverageLatencyMs: averageLatency(p.LatencyMs, p.Queries),
+		})
+	}
+	sort.Slice(result.Panels, func(i, j int) bool {
+		return result.Panels[i].PanelID < result.Panels[j].PanelID
+	})
+}
+
+func add(total *Usage, row *Usage) {
+	total.Views += row.Views
+	total.Queries += row.Queries
+	total.Errors += row.Errors
+	total.LatencyMs += row.LatencyMs
+}
+
+// rollup replaces the daily usage before the given time by monthly usage.
+func (s *store) rollup(ctx context.Context, before time.Time) error {
+	for {
+		n, err := s.rollupUsage(ctx, before)
+		if err != nil {
+			return err
+		}
+		if n < rollupBatchSize {
+			break
+		}
+	}
+	for {
+		n, err := s.rollupViewers(ctx, before)
+		if err != nil {
+			return err
+		}
+		if n < rollupBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *store) rollupUsage(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows := make([]*Usage, 0)
+		err := sess.Where("period = ? AND period_start < ?", PeriodDay, before.Unix()).Limit(rollupBatchSize).Find(&rows)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		n = len(rows)
+
+		ids := make([]int64, 0, len(rows))
+		months := make(map[usageKey]*Usage)
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+			month := periodStart(PeriodMonth, time.Unix(row.PeriodStart, 0)).Unix()
+			key := usageKey{orgID: row.OrgID, dashboardUID: row.DashboardUID, panelID: row.PanelID, day: month}
+			if _, ok := months[key]; !ok {
+				months[key] = &Usage{OrgID: row.OrgID, DashboardUID: row.DashboardUID, PanelID: row.PanelID, Period: PeriodMonth, PeriodStart: month}
+			}
+			add(months[key], row)
+		}
+
+		// Deleting the rows first ensures that they are not rolled up twice by instances of a cluster
+		affected, err := sess.In("id", ids).Delete(&Usage{})
+		if err != nil {
+			return err
+		}
+		if affected != int64(len(ids)) {
+			return errConcurrentRollup
+		}
+		for _, month := range months {
+			if err := addUsage(sess, month); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+func (s *store) rollupViewers(ctx context.Context, before time.Time) (int, error) {
+	var n int
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rows := make([]*Viewer, 0)
+		err := sess.Where("period = ? AND period_start < ?", PeriodDay, before.Unix()).Limit(rollupBatchSize).Find(&rows)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		n = len(rows)
+
+		ids := make([]int64, 0, len(rows))
+		months := make(map[viewerKey]bool)
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+			month := periodStart(PeriodMonth, time.Unix(row.PeriodStart, 0)).Unix()
+			months[viewerKey{orgID: row.OrgID, dashboardUID: row.DashboardUID, userID: row.UserID, day: month}] = true
+		}
+
+		affected, err := sess.In("id", ids).Delete(&Viewer{})
+		if err != nil {
+			return err
+		}
+		if affected != int64(len(ids)) {
+			return errConcurrentRollup
+		}
+		for key := range months {
+			viewer := &Viewer{OrgID: key.orgID, DashboardUID: key.dashboardUID, UserID: key.userID, Period: PeriodMonth, PeriodStart: key.day}
+			if err := addViewer(sess, viewer); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return n, err
+}
+
+// deleteBefore deletes the usage of the periods starting before the given time.
+func (s *store) deleteBefore(ctx context.Context, before time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM dashboard_usage WHERE period_start < ?", before.Unix()); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM dashboard_usage_viewer WHERE period_start < ?", before.Unix())
+		return err
+	})
+}
+
+// deleteOrphaned deletes the usage of deleted dashboards.
+func (s *store) deleteOrphaned(ctx context.Context) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for _, table := range []string{"dashboard_usage", "dashboard_usage_viewer", "dashboard_usage_summary"} {
+			_, err := sess.Exec("DELETE FROM " + table + " WHERE NOT EXISTS (SELECT 1 FROM dashboard WHERE dashboard.org_id = " + table +
+				".org_id AND dashboard.uid = " + table + ".dashboard_uid)")
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package dashboardusage
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidDays = errors.New("days must be between 1 and the retention of the usage")
+
+// Period is the time period a usage row counts.
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodMonth Period = "month"
+)
+
+// Usage is the usage of a dashboard in a period. Views are counted on the row of panel 0,
+// queries are counted on the rows of the panels they were sent by.
+type Usage struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	Period       Period `xorm:"period"`
+	// PeriodStart is the start of the period in seconds since epoch, in UTC.
+	PeriodStart int64 `xorm:"period_start"`
+	Views       int64 `xorm:"views"`
+	Queries     int64 `xorm:"queries"`
+	Errors      int64 `xorm:"errors"`
+	// LatencyMs is the sum of the durations of the queries.
+	LatencyMs int64 `xorm:"latency_ms"`
+}
+
+func (u Usage) TableName() string {
+	return "dashboard_usage"
+}
+
+// Viewer records that a user viewed a dashboard in a period.
+type Viewer struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	UserID       int64  `xorm:"user_id"`
+	Period       Period `xorm:"period"`
+	PeriodStart  int64  `xorm:"period_start"`
+}
+
+func (v Viewer) TableName() string {
+	return "dashboard_usage_viewer"
+}
+
+// Summary is the total views and the last view of a dashboard, which are kept after the
+// usage of the dashboard is deleted by the retention.
+type Summary struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	Views        int64  `xorm:"views"`
+	// LastViewed is the time of the last view in seconds since epoch.
+	LastViewed int64 `xorm:"last_viewed"`
+}
+
+func (s Summary) TableName() string {
+	return "dashboard_usage_summary"
+}
+
+type GetDashboardUsageQuery struct {
+	OrgID        int64
+	DashboardUID string
+	From         time.Time
+	To           time.Time
+}
+
+// DashboardUsage is the usage of a dashboard in a time range.
+type DashboardUsage struct {
+	DashboardUID     string        `json:"dashboardUid"`
+	From             time.Time     `json:"from"`
+	To               time.Time     `json:"to"`
+	Views            int64         `json:"views"`
+	UniqueViewers    int64         `json:"uniqueViewers"`
+	Queries          int64         `json:"queries"`
+	QueryErrors      int64         `json:"queryErrors"`
+	AverageLatencyMs float64       `json:"averageLatencyMs"`
+	TotalViews       int64         `json:"totalViews"`
+	LastViewed       *time.Time    `json:"lastViewed"`
+	Periods          []PeriodUsage `json:"periods"`
+	Panels           []PanelUsage  `json:"panels"`
+}
+
+// PeriodUsage is the usage of a dashboard in a day, or in a month once it is rolled up.
+type PeriodUsage struct {
+	Period           Period    `json:"period"`
+	Start            time.Time `json:"start"`
+	Views            int64     `json:"views"`
+	Queries          int64     `json:"queries"`
+	QueryErrors      int64     `json:"queryErrors"`
+	AverageLatencyMs float64   `json:"averageLatencyMs"`
+}
+
+// PanelUsage is the usage of a panel of a dashboard, which is derived from its queries.
+type PanelUsage struct {
+	PanelID          int64   `json:"panelId"`
+	Queries          int64   `json:"queries"`
+	QueryErrors      int64   `json:"queryErrors"`
+	AverageLatencyMs float64 `json:"averageLatencyMs"`
+}
+
+// periodStart returns the start of the period containing t, in UTC.
+func periodStart(period Period, t time.Time) time.Time {
+	t = t.UTC()
+	if period == PeriodMonth {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func averageLatency(latencyMs, queries int64) float64 {
+	if queries == 0 {
+		return 0
+	}
+	return float64(latencyMs) / float64(queries)
+}
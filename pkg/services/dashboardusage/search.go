@@ -0,0 +1,105 @@
+package dashboardusage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+const (
+	recentUsageDays = 30
+	unusedDays      = 90
+)
+
+func sortOptions(dialect migrator.Dialect) []model.SortOption {
+	return []model.SortOption{
+		{
+			Name:        "views-recent-desc",
+			DisplayName: "Most viewed (last 30 days)",
+			Description: "Sort dashboards by their number of views in the last 30 days, most viewed first",
+			Index:       1,
+			MetaName:    "views",
+			Filter:      []model.SortOptionFilter{usageSorter{column: "views", days: recentUsageDays, descending: true}, searchstore.TitleSorter{}},
+		},
+		{
+			Name:        "views-recent-asc",
+			DisplayName: "Least viewed (last 30 days)",
+			Description: "Sort dashboards by their number of views in the last 30 days, least viewed first",
+			Index:       1,
+			MetaName:    "views",
+			Filter:      []model.SortOptionFilter{usageSorter{column: "views", days: recentUsageDays}, searchstore.TitleSorter{}},
+		},
+		{
+			Name:        "errors-recent-desc",
+			DisplayName: "Most query errors (last 30 days)",
+			Description: "Sort dashboards by their number of query errors in the last 30 days, most errors first",
+			Index:       2,
+			MetaName:    "errors",
+			Filter:      []model.SortOptionFilter{usageSorter{column: "errors", days: recentUsageDays, descending: true}, searchstore.TitleSorter{}},
+		},
+		{
+			Name:        "unused",
+			DisplayName: "Unused for 90 days",
+			Description: "Only dashboards which were not viewed in the last 90 days, least recently viewed first",
+			Index:       3,
+			MetaName:    "views",
+			Filter:      []model.SortOptionFilter{unusedFilter{days: unusedDays, dialect: dialect}, searchstore.TitleSorter{}},
+		},
+	}
+}
+
+// usageSorter sorts the dashboards by the sum of a column of their usage in the last days.
+type usageSorter struct {
+	column     string
+	days       int
+	descending bool
+}
+
+func (s usageSorter) LeftJoin() string {
+	since := periodStart(PeriodDay, time.Now()).AddDate(0, 0, -s.days+1).Unix()
+	return fmt.Sprintf(`(SELECT org_id, dashboard_uid, SUM(%s) AS total FROM dashboard_usage
+		WHERE period = '%s' AND period_start >= %d GROUP BY org_id, dashboard_uid) AS dashboard_usage_recent
+		ON dashboard_usage_recent.org_id = dashboard.org_id AND dashboard_usage_recent.dashboard_uid = dashboard.uid`,
+		s.column, PeriodDay, since)
+}
+
+func (s usageSorter) Select() string {
+	return "COALESCE(dashboard_usage_recent.total, 0) AS sort_meta"
+}
+
+func (s usageSorter) OrderBy() string {
+	// Dashboards without views have no usage rows, they are sorted as having no views on all
+	// databases instead of where each database sorts NULLs.
+	if s.descending {
+		return "COALESCE(dashboard_usage_recent.total, 0) DESC"
+	}
+	return "COALESCE(dashboard_usage_recent.total, 0) ASC"
+}
+
+// unusedFilter only keeps the dashboards which were not viewed in the last days, and sorts
+// them by their last view. Dashboards which were never viewed come first.
+type unusedFilter struct {
+	days    int
+	dialect migrator.Dialect
+}
+
+func (f unusedFilter) LeftJoin() string {
+	return `dashboard_usage_summary ON dashboard_usage_summary.org_id = dashboard.org_id AND dashboard_usage_summary.dashboard_uid = dashboard.uid`
+}
+
+func (f unusedFilter) Where() (string, []any) {
+	since := time.Now().AddDate(0, 0, -f.days).Unix()
+	return "dashboard.is_folder = " + f.dialect.BooleanStr(false) +
+		" AND (dashboard_usage_summary.last_viewed IS NULL OR dashboard_usage_summary.last_viewed < ?)", []any{since}
+}
+
+func (f unusedFilter) Select() string {
+	return "COALESCE(dashboard_usage_summary.views, 0) AS sort_meta"
+}
+
+func (f unusedFilter) OrderBy() string {
+	return "COALESCE(dashboard_usage_summary.last_viewed, 0) ASC"
+}
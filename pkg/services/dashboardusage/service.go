@@ -0,0 +1,272 @@
+// Package dashboardusage records the views and queries of dashboards and panels, so that
+// unused and failing dashboards can be found.
+package dashboardusage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maintenanceInterval is how often the usage is rolled up and the retention is applied.
+const maintenanceInterval = time.Hour
+
+type Service interface {
+	// RecordView records a view of the dashboard by the user.
+	RecordView(ctx context.Context, user identity.Requester, dashboardUID string)
+	// RecordQuery records a query sent by a panel of the dashboard.
+	RecordQuery(ctx context.Context, user identity.Requester, dashboardUID string, panelID int64, duration time.Duration, failed bool)
+	GetDashboardUsage(ctx context.Context, query *GetDashboardUsageQuery) (*DashboardUsage, error)
+}
+
+var _ Service = (*UsageService)(nil)
+
+// UsageService buffers the usage in memory and writes it to the database at the flush
+// interval, so that viewing dashboards does not write to the database.
+type UsageService struct {
+	cfg              *setting.Cfg
+	store            *store
+	log              log.Logger
+	routeRegister    routing.RouteRegister
+	dashboardService dashboards.DashboardService
+	now              func() time.Time
+
+	mu     sync.Mutex
+	buffer *buffer
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, searchService *search.SearchService,
+	dashboardService dashboards.DashboardService) *UsageService {
+	s := &UsageService{
+		cfg:              cfg,
+		store:            &store{db: sqlStore},
+		log:              log.New("dashboardusage"),
+		routeRegister:    routeRegister,
+		dashboardService: dashboardService,
+		now:              time.Now,
+		buffer:           newBuffer(),
+	}
+
+	if cfg.DashboardUsage.Enabled {
+		for _, option := range sortOptions(sqlStore.GetDialect()) {
+			searchService.RegisterSortOption(option)
+		}
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *UsageService) IsDisabled() bool {
+	return !s.cfg.DashboardUsage.Enabled
+}
+
+// Run writes the recorded usage to the database at the flush interval, rolls up the daily
+// usage and deletes the usage older than the retention.
+func (s *UsageService) Run(ctx context.Context) error {
+	flushTicker := time.NewTicker(s.cfg.DashboardUsage.FlushInterval)
+	defer flushTicker.Stop()
+	maintenanceTicker := time.NewTicker(maintenanceInterval)
+	defer maintenanceTicker.Stop()
+
+	for {
+		select {
+		case <-flushTicker.C:
+			s.flush(ctx)
+		case <-maintenanceTicker.C:
+			s.maintain(ctx)
+		case <-ctx.Done():
+			// Write the usage recorded since the last flush before shutting down
+			flushCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *UsageService) RecordView(_ context.Context, user identity.Requester, dashboardUID string) {
+	if !s.cfg.DashboardUsage.Enabled || dashboardUID == "" {
+		return
+	}
+	// Anonymous users and API keys count as views but not as viewers
+	userID, _ := identity.UserIdentifier(user.GetID())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer.addView(user.GetOrgID(), dashboardUID, userID, s.now())
+}
+
+func (s *UsageService) RecordQuery(ctx context.Context, user identity.Requester, dashboardUID string, panelID int64, duration time.Duration, failed bool) {
+	if !s.cfg.DashboardUsage.Enabled || dashboardUID == "" {
+		return
+	}
+	// The dashboard of a query is sent by the client, so the query is only recorded for
+	// dashboards that the user can view.
+	if !s.canView(ctx, user, dashboardUID) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer.addQuery(user.GetOrgID(), dashboardUID, panelID, duration, failed, s.now())
+}
+
+// canView returns whether the user can view the dashboard. Dashboards the user can view are
+// remembered until the next flush, so that the dashboard is not loaded for every query.
+func (s *UsageService) canView(ctx context.Context, user identity.Requester, dashboardUID string) bool {
+	key := viewableKey{orgID: user.GetOrgID(), userID: user.GetID(), dashboardUID: dashboardUID}
+	s.mu.Lock()
+	viewable := s.buffer.viewable[key]
+	s.mu.Unlock()
+	if viewable {
+		return true
+	}
+
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: user.GetOrgID()})
+	if err != nil {
+		return false
+	}
+	g, err := guardian.NewByDashboard(ctx, dash, user.GetOrgID(), user)
+	if err != nil {
+		return false
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buffer.viewable[key] = true
+	return true
+}
+
+func (s *UsageService) GetDashboardUsage(ctx context.Context, query *GetDashboardUsageQuery) (*DashboardUsage, error) {
+	return s.store.getDashboardUsage(ctx, query)
+}
+
+func (s *UsageService) flush(ctx context.Context) {
+	s.mu.Lock()
+	b := s.buffer
+	s.buffer = newBuffer()
+	s.mu.Unlock()
+
+	if b.empty() {
+		return
+	}
+	if err := s.store.write(ctx, b); err != nil {
+		s.log.Error("Failed to write dashboard usage", "error", err)
+	}
+}
+
+func (s *UsageService) maintain(ctx context.Context) {
+	now := s.now()
+	// Only complete months are rolled up, so that a month is either counted by days or as a month
+	rollupBefore := periodStart(PeriodMonth, now.Add(-s.cfg.DashboardUsage.RollupAfter))
+	if err := s.store.rollup(ctx, rollupBefore); err != nil {
+		s.log.Error("Failed to roll up dashboard usage", "error", err)
+	}
+	if err := s.store.deleteBefore(ctx, now.Add(-s.cfg.DashboardUsage.Retention)); err != nil {
+		s.log.Error("Failed to delete dashboard usage older than the retention", "error", err)
+	}
+	if err := s.store.deleteOrphaned(ctx); err != nil {
+		s.log.Error("Failed to delete usage of deleted dashboards", "error", err)
+	}
+}
+
+type usageKey struct {
+	orgID        int64
+	dashboardUID string
+	panelID      int64
+	day          int64
+}
+
+type viewerKey struct {
+	orgID        int64
+	dashboardUID string
+	userID       int64
+	day          int64
+}
+
+type summaryKey struct {
+	orgID        int64
+	dashboardUID string
+}
+
+type viewableKey struct {
+	orgID        int64
+	userID       string
+	dashboardUID string
+}
+
+// buffer is the usage recorded since the last flush.
+type buffer struct {
+	usage     map[usageKey]*Usage
+	viewers   map[viewerKey]bool
+	summaries map[summaryKey]*Summary
+	// viewable are the dashboards that users were allowed to view, it is not written.
+	viewable map[viewableKey]bool
+}
+
+func newBuffer() *buffer {
+	return &buffer{
+		usage:     make(map[usageKey]*Usage),
+		viewers:   make(map[viewerKey]bool),
+		summaries: make(map[summaryKey]*Summary),
+		viewable:  make(map[viewableKey]bool),
+	}
+}
+
+func (b *buffer) empty() bool {
+	return len(b.usage) == 0 && len(b.viewers) == 0 && len(b.summaries) == 0
+}
+
+func (b *buffer) addView(orgID int64, dashboardUID string, userID int64, now time.Time) {
+	day := periodStart(PeriodDay, now).Unix()
+	b.get(usageKey{orgID: orgID, dashboardUID: dashboardUID, day: day}).Views++
+
+	if userID > 0 {
+		b.viewers[viewerKey{orgID: orgID, dashboardUID: dashboardUID, userID: userID, day: day}] = true
+	}
+
+	key := summaryKey{orgID: orgID, dashboardUID: dashboardUID}
+	summary, ok := b.summaries[key]
+	if !ok {
+		summary = &Summary{OrgID: orgID, DashboardUID: dashboardUID}
+		b.summaries[key] = summary
+	}
+	summary.Views++
+	summary.LastViewed = now.Unix()
+}
+
+func (b *buffer) addQuery(orgID int64, dashboardUID string, panelID int64, duration time.Duration, failed bool, now time.Time) {
+	usage := b.get(usageKey{orgID: orgID, dashboardUID: dashboardUID, panelID: panelID, day: periodStart(PeriodDay, now).Unix()})
+	usage.Queries++
+	usage.LatencyMs += duration.Milliseconds()
+	if failed {
+		usage.Errors++
+	}
+}
+
+func (b *buffer) get(key usageKey) *Usage {
+	usage, ok := b.usage[key]
+	if !ok {
+		usage = &Usage{
+			OrgID:        key.orgID,
+			DashboardUID: key.dashboardUID,
+			PanelID:      key.panelID,
+			Period:       PeriodDay,
+			PeriodStart:  key.day,
+		}
+		b.usage[key] = usage
+	}
+	return usage
+}
@@ -0,0 +1,175 @@
+package dashboardusage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestBuffer(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	b := newBuffer()
+	require.True(t, b.empty())
+
+	b.addView(1, "dash", 10, now)
+	b.addView(1, "dash", 10, now.Add(time.Minute))
+	b.addView(1, "dash", 0, now.Add(2*time.Minute))
+	b.addQuery(1, "dash", 2, 100*time.Millisecond, false, now)
+	b.addQuery(1, "dash", 2, 300*time.Millisecond, true, now)
+
+	require.Len(t, b.usage, 2)
+	views := b.usage[usageKey{orgID: 1, dashboardUID: "dash", day: day}]
+	require.Equal(t, int64(3), views.Views)
+	require.Equal(t, PeriodDay, views.Period)
+
+	queries := b.usage[usageKey{orgID: 1, dashboardUID: "dash", panelID: 2, day: day}]
+	require.Equal(t, int64(2), queries.Queries)
+	require.Equal(t, int64(1), queries.Errors)
+	require.Equal(t, int64(400), queries.LatencyMs)
+
+	// Anonymous views are not counted as viewers
+	require.Len(t, b.viewers, 1)
+
+	summary := b.summaries[summaryKey{orgID: 1, dashboardUID: "dash"}]
+	require.Equal(t, int64(3), summary.Views)
+	require.Equal(t, now.Add(2*time.Minute).Unix(), summary.LastViewed)
+}
+
+func TestRecordQuery(t *testing.T) {
+	now := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	dashSvc := dashboards.NewFakeDashboardService(t)
+	dashSvc.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash" && q.OrgID == 1
+	})).Return(&dashboards.Dashboard{ID: 1, UID: "dash", OrgID: 1}, nil)
+	dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+	origNewByDashboard := guardian.NewByDashboard
+	t.Cleanup(func() { guardian.NewByDashboard = origNewByDashboard })
+	fakeGuardian := &guardian.FakeDashboardGuardian{CanViewValue: true}
+	guardian.MockDashboardGuardian(fakeGuardian)
+
+	cfg := setting.NewCfg()
+	cfg.DashboardUsage.Enabled = true
+	s := &UsageService{cfg: cfg, dashboardService: dashSvc, now: func() time.Time { return now }, buffer: newBuffer()}
+	viewer := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	// The dashboard is only loaded once per flush
+	s.RecordQuery(context.Background(), viewer, "dash", 2, time.Second, false)
+	s.RecordQuery(context.Background(), viewer, "dash", 2, time.Second, false)
+	dashSvc.AssertNumberOfCalls(t, "GetDashboard", 1)
+	key := usageKey{orgID: 1, dashboardUID: "dash", panelID: 2, day: day}
+	require.Equal(t, int64(2), s.buffer.usage[key].Queries)
+
+	// Queries of dashboards that don't exist in the organization of the user are not recorded
+	s.RecordQuery(context.Background(), viewer, "unknown", 2, time.Second, false)
+	s.RecordQuery(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 2}, "dash", 2, time.Second, false)
+	require.Len(t, s.buffer.usage, 1)
+
+	// Queries of dashboards that the user cannot view are not recorded
+	fakeGuardian.CanViewValue = false
+	s.RecordQuery(context.Background(), &user.SignedInUser{UserID: 2, OrgID: 1}, "dash", 2, time.Second, false)
+	require.Equal(t, int64(2), s.buffer.usage[key].Queries)
+}
+
+func TestIntegrationDashboardUsage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	s := &store{db: sqlStore}
+	ctx := context.Background()
+
+	err := sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&dashboards.Dashboard{OrgID: 1, UID: "dash", Title: "Dashboard", Slug: "dashboard", Data: simplejson.New(), Created: time.Now(), Updated: time.Now()})
+		return err
+	})
+	require.NoError(t, err)
+
+	february := time.Date(2024, 2, 10, 8, 0, 0, 0, time.UTC)
+	march := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+
+	for _, now := range []time.Time{february, february.AddDate(0, 0, 1), march} {
+		b := newBuffer()
+		b.addView(1, "dash", 10, now)
+		b.addView(1, "dash", 11, now)
+		b.addQuery(1, "dash", 2, 200*time.Millisecond, false, now)
+		b.addQuery(1, "dash", 3, 100*time.Millisecond, true, now)
+		// Usage of a deleted dashboard
+		b.addView(1, "deleted", 10, now)
+		require.NoError(t, s.write(ctx, b))
+	}
+
+	query := &GetDashboardUsageQuery{OrgID: 1, DashboardUID: "dash", From: february, To: march.Add(time.Hour)}
+
+	t.Run("get the usage of a dashboard", func(t *testing.T) {
+		usage, err := s.getDashboardUsage(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), usage.Views)
+		require.Equal(t, int64(6), usage.TotalViews)
+		require.Equal(t, int64(2), usage.UniqueViewers)
+		require.Equal(t, int64(6), usage.Queries)
+		require.Equal(t, int64(3), usage.QueryErrors)
+		require.Equal(t, float64(150), usage.AverageLatencyMs)
+		require.Equal(t, march.Unix(), usage.LastViewed.Unix())
+
+		require.Len(t, usage.Periods, 3)
+		require.Equal(t, PeriodDay, usage.Periods[0].Period)
+		require.Equal(t, int64(2), usage.Periods[0].Views)
+
+		require.Len(t, usage.Panels, 2)
+		require.Equal(t, int64(2), usage.Panels[0].PanelID)
+		require.Equal(t, int64(0), usage.Panels[0].QueryErrors)
+		require.Equal(t, int64(3), usage.Panels[1].QueryErrors)
+	})
+
+	t.Run("roll up the daily usage into monthly usage", func(t *testing.T) {
+		require.NoError(t, s.rollup(ctx, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+
+		usage, err := s.getDashboardUsage(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, int64(6), usage.Views)
+		require.Equal(t, int64(2), usage.UniqueViewers)
+		require.Equal(t, int64(6), usage.Queries)
+
+		require.Len(t, usage.Periods, 2)
+		require.Equal(t, PeriodMonth, usage.Periods[0].Period)
+		require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), usage.Periods[0].Start)
+		require.Equal(t, int64(4), usage.Periods[0].Views)
+		require.Equal(t, PeriodDay, usage.Periods[1].Period)
+	})
+
+	t.Run("delete the usage older than the retention and of deleted dashboards", func(t *testing.T) {
+		require.NoError(t, s.deleteBefore(ctx, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)))
+		require.NoError(t, s.deleteOrphaned(ctx))
+
+		usage, err := s.getDashboardUsage(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), usage.Views)
+		require.Equal(t, int64(6), usage.TotalViews)
+
+		deleted, err := s.getDashboardUsage(ctx, &GetDashboardUsageQuery{OrgID: 1, DashboardUID: "deleted", From: february, To: march})
+		require.NoError(t, err)
+		require.Zero(t, deleted.Views)
+		require.Zero(t, deleted.TotalViews)
+	})
+}
@@ -9,11 +9,14 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/plugins"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -129,6 +132,41 @@ func TestDatasourceProxy_proxyDatasourceRequest(t *testing.T) {
 	}
 }
 
+func TestDatasourceProxy_QueryPolicies(t *testing.T) {
+	jsonData, err := simplejson.NewJson([]byte(`{"queryPolicies": {"policies": [{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"}]}}`))
+	require.NoError(t, err)
+	ds := &datasources.DataSource{Type: datasources.DS_PROMETHEUS, URL: "://host/path", JsonData: jsonData}
+
+	p := DataSourceProxyService{
+		PluginRequestValidator: &fakePluginRequestValidator{},
+		pluginStore: &pluginstore.FakePluginStore{PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: datasources.DS_PROMETHEUS}},
+		}},
+	}
+	proxy := func(signedInUser *user.SignedInUser) int {
+		responseRecorder := httptest.NewRecorder()
+		c := &contextmodel.ReqContext{
+			Context: &web.Context{
+				Req:  &http.Request{URL: &url.URL{Path: "/api/datasources/proxy/uid/ds/api/v1/series"}},
+				Resp: web.NewResponseWriter("GET", responseRecorder),
+			},
+			SignedInUser: signedInUser,
+			Logger:       log.NewNopLogger(),
+		}
+		p.proxyDatasourceRequest(c, ds)
+		return responseRecorder.Result().StatusCode
+	}
+
+	t.Run("denies users whose queries are restricted by a query policy", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, proxy(&user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer, Teams: []int64{1}}))
+	})
+
+	t.Run("proxies the requests of users without query policy", func(t *testing.T) {
+		// The request reaches the proxy, which rejects the invalid URL of the data source.
+		require.Equal(t, http.StatusBadRequest, proxy(&user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer, Teams: []int64{2}}))
+	})
+}
+
 type fakePluginRequestValidator struct{}
 
 func (rv *fakePluginRequestValidator) Validate(_ string, _ *http.Request) error {
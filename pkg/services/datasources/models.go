@@ -3,8 +3,10 @@ package datasources
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -127,6 +129,63 @@ func GetTeamHTTPHeaders(jsonData *simplejson.Json) (*TeamHTTPHeaders, error) {
 	return teamHTTPHeaders, nil
 }
 
+// QueryPolicies restrict what the users of a data source can query, by team or by role.
+type QueryPolicies struct {
+	Policies []QueryPolicy `json:"policies"`
+	// RestrictAccess denies all queries of the users that no policy applies to.
+	RestrictAccess bool `json:"restrictAccess"`
+}
+
+// QueryPolicy applies to the members of a team or to the users with an organization role.
+// A policy without restriction for the type of the data source grants unrestricted access.
+type QueryPolicy struct {
+	TeamID int64  `json:"teamId,omitempty"`
+	Role   string `json:"role,omitempty"`
+	// LabelSelector is added to every selector of Prometheus and Loki queries, e.g. {tenant="team-a"}.
+	LabelSelector string `json:"labelSelector,omitempty"`
+	// AllowedTables are the only tables SQL queries can read from. Entries are
+	// table names as written in queries, optionally schema qualified, or schema.* for all
+	// tables of a schema.
+	AllowedTables []string `json:"allowedTables,omitempty"`
+}
+
+func (ds DataSource) QueryPolicies() (*QueryPolicies, error) {
+	return GetQueryPolicies(ds.JsonData)
+}
+
+func GetQueryPolicies(jsonData *simplejson.Json) (*QueryPolicies, error) {
+	if jsonData == nil {
+		return nil, nil
+	}
+	if _, ok := jsonData.CheckGet("queryPolicies"); !ok {
+		return nil, nil
+	}
+
+	queryPoliciesJSON, err := jsonData.Get("queryPolicies").MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	queryPolicies := &QueryPolicies{}
+	if err := json.Unmarshal(queryPoliciesJSON, queryPolicies); err != nil {
+		return nil, err
+	}
+	for _, policy := range queryPolicies.Policies {
+		if (policy.TeamID == 0) == (policy.Role == "") {
+			return nil, errors.New("query policy must have either a teamId or a role")
+		}
+		if policy.Role != "" && !identity.RoleType(policy.Role).IsValid() {
+			return nil, fmt.Errorf("invalid role %q in query policy", policy.Role)
+		}
+		for _, table := range policy.AllowedTables {
+			if table == "" {
+				return nil, errors.New("allowed table is empty in query policy")
+			}
+		}
+	}
+
+	return queryPolicies, nil
+}
+
 // AllowedCookies parses the jsondata.keepCookies and returns a list of
 // allowed cookies, otherwise an empty list.
 func (ds DataSource) AllowedCookies() []string {
@@ -102,3 +102,65 @@ func TestTeamHTTPHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryPolicies(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		given   string
+		want    *QueryPolicies
+		wantErr bool
+	}{
+		{
+			desc:  "Json data with queryPolicies",
+			given: `{"queryPolicies": {"restrictAccess": true, "policies": [{"teamId": 101, "labelSelector": "{tenant=\"team-a\"}"}, {"role": "Viewer", "allowedTables": ["reporting.*"]}]}}`,
+			want: &QueryPolicies{
+				RestrictAccess: true,
+				Policies: []QueryPolicy{
+					{TeamID: 101, LabelSelector: `{tenant="team-a"}`},
+					{Role: "Viewer", AllowedTables: []string{"reporting.*"}},
+				},
+			},
+		},
+		{
+			desc:  "Json data without queryPolicies",
+			given: `{"foo": "bar"}`,
+			want:  nil,
+		},
+		{
+			desc:    "Policy without team or role",
+			given:   `{"queryPolicies": {"policies": [{"labelSelector": "{tenant=\"team-a\"}"}]}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Policy with both team and role",
+			given:   `{"queryPolicies": {"policies": [{"teamId": 101, "role": "Viewer"}]}}`,
+			wantErr: true,
+		},
+		{
+			desc:    "Policy with invalid role",
+			given:   `{"queryPolicies": {"policies": [{"role": "Guest"}]}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			jsonData, err := simplejson.NewJson([]byte(test.given))
+			require.NoError(t, err)
+
+			ds := DataSource{
+				ID:       1235,
+				JsonData: jsonData,
+				UID:      "test",
+			}
+
+			actual, err := ds.QueryPolicies()
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Package keyfileprovider implements a key encryption key provider using keys from a keyring
+// file, kept outside of the Grafana configuration. The keyring can be encrypted with age, so
+// that it is only readable with the identity of the Grafana server.
+//
+// A keyring has one key per line, an ID followed by the secret:
+//
+//	# Keys are appended when they are rotated
+//	2024-01 0X1zB8hI0yJc9yVvZ0sS3mFq8g3yJ2wVJf5kq6cM4Zk=
+//	2024-07 mR8m0aW0J7bH3o2zXbQe1oTqH7cJ7fQG6mL5m2v9nS4=
+//
+// Data keys are encrypted with the last key, or the key_id of the configuration, and keep
+// the ID of the key so that they can be decrypted after a rotation.
+package keyfileprovider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/grafana/grafana/pkg/services/encryption"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// minSecretLength is the minimum length of the keys of a keyring.
+const minSecretLength = 32
+
+var keyIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+type keyFileProvider struct {
+	encryption   encryption.Internal
+	keys         map[string]string
+	currentKeyID string
+}
+
+// New returns a provider configured by a [security.encryption.keyfile.<key-name>] section.
+func New(section *setting.DynamicSection, enc encryption.Internal) (secrets.Provider, error) {
+	path := section.Key("path").MustString("")
+	if path == "" {
+		return nil, errors.New("keyfile encryption provider requires a path")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	if isAgeEncrypted(data) {
+		identityFile := section.Key("identity_file").MustString("")
+		if identityFile == "" {
+			return nil, fmt.Errorf("keyring %s is encrypted with age, an identity_file is required", path)
+		}
+		if data, err = decryptKeyring(data, identityFile); err != nil {
+			return nil, err
+		}
+	}
+
+	keys, ids, err := parseKeyring(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keyring %s: %w", path, err)
+	}
+	currentKeyID := section.Key("key_id").MustString(ids[len(ids)-1])
+	if _, ok := keys[currentKeyID]; !ok {
+		return nil, fmt.Errorf("key %q not found in keyring %s", currentKeyID, path)
+	}
+
+	return &keyFileProvider{
+		encryption:   enc,
+		keys:         keys,
+		currentKeyID: currentKeyID,
+	}, nil
+}
+
+func (p *keyFileProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	encrypted, err := p.encryption.Encrypt(ctx, blob, p.keys[p.currentKeyID])
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(p.currentKeyID+":"), encrypted...), nil
+}
+
+func (p *keyFileProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	keyID, encrypted, ok := bytes.Cut(blob, []byte(":"))
+	if !ok {
+		return nil, errors.New("missing keyring key ID")
+	}
+	key, ok := p.keys[string(keyID)]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in keyring", keyID)
+	}
+	return p.encryption.Decrypt(ctx, encrypted, key)
+}
+
+func isAgeEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("age-encryption.org/")) || bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header))
+}
+
+func decryptKeyring(data []byte, identityFile string) ([]byte, error) {
+	f, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open age identity file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age identity file: %w", err)
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte("age-encryption.org/")) {
+		r = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+	decrypted, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keyring: %w", err)
+	}
+	return io.ReadAll(decrypted)
+}
+
+// parseKeyring returns the keys of the keyring by ID, and the IDs in the order of the keyring.
+func parseKeyring(data []byte) (map[string]string, []string, error) {
+	keys := make(map[string]string)
+	var ids []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("line %d: expected a key ID and a secret", line)
+		}
+		id, secret := fields[0], fields[1]
+		if !keyIDPattern.MatchString(id) {
+			return nil, nil, fmt.Errorf("line %d: invalid key ID %q", line, id)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, nil, fmt.Errorf("line %d: duplicate key ID %q", line, id)
+		}
+		if len(secret) < minSecretLength {
+			return nil, nil, fmt.Errorf("line %d: key %q is shorter than %d characters", line, id, minSecretLength)
+		}
+		keys[id] = secret
+		ids = append(ids, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil, errors.New("no keys")
+	}
+	return keys, ids, nil
+}
@@ -0,0 +1,126 @@
+package keyfileprovider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/stretchr/testify/require"
+
+	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const keyring = `# test keyring
+2024-01 0X1zB8hI0yJc9yVvZ0sS3mFq8g3yJ2wVJf5kq6cM4Zk=
+2024-07 mR8m0aW0J7bH3o2zXbQe1oTqH7cJ7fQG6mL5m2v9nS4=
+`
+
+func section(t *testing.T, config string) *setting.DynamicSection {
+	t.Helper()
+	cfg, err := setting.NewCfgFromBytes([]byte("[security.encryption.keyfile.v1]\n" + config))
+	require.NoError(t, err)
+	return cfg.SectionWithEnvOverrides("security.encryption.keyfile.v1")
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestKeyFileProvider(t *testing.T) {
+	enc := encryptionservice.SetupTestService(t)
+	ctx := context.Background()
+
+	t.Run("encrypts with the last key", func(t *testing.T) {
+		p, err := New(section(t, "path = "+writeFile(t, "keyring", []byte(keyring))), enc)
+		require.NoError(t, err)
+
+		encrypted, err := p.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(encrypted, []byte("2024-07:")))
+
+		decrypted, err := p.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("decrypts with previous keys", func(t *testing.T) {
+		path := writeFile(t, "keyring", []byte(keyring))
+		previous, err := New(section(t, "path = "+path+"\nkey_id = 2024-01"), enc)
+		require.NoError(t, err)
+		encrypted, err := previous.Encrypt(ctx, []byte("data key"))
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(encrypted, []byte("2024-01:")))
+
+		p, err := New(section(t, "path = "+path), enc)
+		require.NoError(t, err)
+		decrypted, err := p.Decrypt(ctx, encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), decrypted)
+
+		_, err = p.Decrypt(ctx, append([]byte("2023-01:"), encrypted[len("2024-01:"):]...))
+		require.Error(t, err)
+	})
+
+	t.Run("age encrypted keyring", func(t *testing.T) {
+		identity, err := age.GenerateX25519Identity()
+		require.NoError(t, err)
+		identityFile := writeFile(t, "identity", []byte(identity.String()+"\n"))
+
+		var binary, armored bytes.Buffer
+		for _, out := range []io.Writer{&binary, armor.NewWriter(&armored)} {
+			w, err := age.Encrypt(out, identity.Recipient())
+			require.NoError(t, err)
+			_, err = io.WriteString(w, keyring)
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+			if c, ok := out.(io.Closer); ok {
+				require.NoError(t, c.Close())
+			}
+		}
+
+		for name, data := range map[string][]byte{"binary": binary.Bytes(), "armored": armored.Bytes()} {
+			t.Run(name, func(t *testing.T) {
+				path := writeFile(t, "keyring.age", data)
+
+				_, err := New(section(t, "path = "+path), enc)
+				require.ErrorContains(t, err, "identity_file")
+
+				p, err := New(section(t, fmt.Sprintf("path = %s\nidentity_file = %s", path, identityFile)), enc)
+				require.NoError(t, err)
+				encrypted, err := p.Encrypt(ctx, []byte("data key"))
+				require.NoError(t, err)
+				decrypted, err := p.Decrypt(ctx, encrypted)
+				require.NoError(t, err)
+				require.Equal(t, []byte("data key"), decrypted)
+			})
+		}
+	})
+
+	t.Run("invalid configuration", func(t *testing.T) {
+		tests := map[string]string{
+			"missing path":     "",
+			"missing file":     "path = " + filepath.Join(t.TempDir(), "missing"),
+			"unknown key":      "path = " + writeFile(t, "keyring", []byte(keyring)) + "\nkey_id = 2025-01",
+			"short secret":     "path = " + writeFile(t, "keyring", []byte("2024-01 secret\n")),
+			"invalid key id":   "path = " + writeFile(t, "keyring", []byte("2024:01 0X1zB8hI0yJc9yVvZ0sS3mFq8g3yJ2wVJf5kq6cM4Zk=\n")),
+			"duplicate key id": "path = " + writeFile(t, "keyring", []byte(keyring+"2024-01 0X1zB8hI0yJc9yVvZ0sS3mFq8g3yJ2wVJf5kq6cM4Zk=\n")),
+			"empty keyring":    "path = " + writeFile(t, "keyring", []byte("# no keys\n")),
+		}
+		for name, config := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := New(section(t, config), enc)
+				require.Error(t, err)
+			})
+		}
+	})
+}
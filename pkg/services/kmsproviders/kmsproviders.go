@@ -12,6 +12,14 @@ const (
 	// which fallbacks to Grafana's secret key. See the
 	// defaultprovider package for further information.
 	Default = "secretKey.v1"
+
+	// HashicorpVault is the kind of the providers using the transit
+	// secrets engine of HashiCorp Vault. See the vaultprovider package.
+	HashicorpVault = "hashicorpvault"
+
+	// KeyFile is the kind of the providers using keys from a keyring
+	// file. See the keyfileprovider package.
+	KeyFile = "keyfile"
 )
 
 type Service interface {
@@ -1,12 +1,17 @@
 package osskmsproviders
 
 import (
+	"fmt"
+
 	"github.com/grafana/grafana/pkg/services/encryption"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/kmsproviders"
 	grafana "github.com/grafana/grafana/pkg/services/kmsproviders/defaultprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/keyfileprovider"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/vaultprovider"
 	"github.com/grafana/grafana/pkg/services/secrets"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 type Service struct {
@@ -24,7 +29,37 @@ func ProvideService(enc encryption.Internal, cfg *setting.Cfg, features featurem
 }
 
 func (s Service) Provide() (map[secrets.ProviderID]secrets.Provider, error) {
-	return map[secrets.ProviderID]secrets.Provider{
+	providers := map[secrets.ProviderID]secrets.Provider{
 		kmsproviders.Default: grafana.New(s.cfg, s.enc),
-	}, nil
+	}
+
+	available := s.cfg.SectionWithEnvOverrides("security").Key("available_encryption_providers").MustString("")
+	for _, id := range util.SplitString(available) {
+		providerID := kmsproviders.NormalizeProviderID(secrets.ProviderID(id))
+		if _, ok := providers[providerID]; ok {
+			continue
+		}
+		kind, err := providerID.Kind()
+		if err != nil {
+			return nil, err
+		}
+
+		section := s.cfg.SectionWithEnvOverrides(fmt.Sprintf("security.encryption.%s", providerID))
+		var provider secrets.Provider
+		switch kind {
+		case kmsproviders.HashicorpVault:
+			provider, err = vaultprovider.New(section)
+		case kmsproviders.KeyFile:
+			provider, err = keyfileprovider.New(section, s.enc)
+		default:
+			// Other kinds of providers are only available in Grafana Enterprise.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure encryption provider %s: %w", providerID, err)
+		}
+		providers[providerID] = provider
+	}
+
+	return providers, nil
 }
@@ -0,0 +1,169 @@
+// Package vaultprovider implements a key encryption key provider using the transit secrets
+// engine of HashiCorp Vault. Data keys are sent to Vault to be encrypted and decrypted, the
+// key encryption key never leaves Vault.
+package vaultprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const defaultTimeout = 10 * time.Second
+
+type vaultProvider struct {
+	url                  string
+	token                string
+	namespace            string
+	transitEnginePath    string
+	keyRing              string
+	tokenRenewalInterval time.Duration
+	client               *http.Client
+	log                  log.Logger
+}
+
+// New returns a provider configured by a [security.encryption.hashicorpvault.<key-name>] section.
+func New(section *setting.DynamicSection) (secrets.Provider, error) {
+	p := &vaultProvider{
+		url:                  strings.TrimSuffix(section.Key("url").MustString(""), "/"),
+		token:                section.Key("token").MustString(""),
+		namespace:            section.Key("namespace").MustString(""),
+		transitEnginePath:    strings.Trim(section.Key("transit_engine_path").MustString("transit"), "/"),
+		keyRing:              section.Key("key_ring").MustString(""),
+		tokenRenewalInterval: section.Key("token_renewal_interval").MustDuration(5 * time.Minute),
+		client:               &http.Client{Timeout: section.Key("timeout").MustDuration(defaultTimeout)},
+		log:                  log.New("kmsproviders.hashicorpvault"),
+	}
+	if p.url == "" || p.token == "" || p.keyRing == "" {
+		return nil, errors.New("hashicorp vault encryption provider requires url, token and key_ring")
+	}
+
+	if caCert := section.Key("ca_cert").MustString(""); caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read hashicorp vault CA certificate: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", caCert)
+		}
+		p.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+		}
+	}
+	return p, nil
+}
+
+func (p *vaultProvider) Encrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(blob)}
+	if err := p.post(ctx, p.transitPath("encrypt"), body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Ciphertext == "" {
+		return nil, errors.New("hashicorp vault returned no ciphertext")
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+func (p *vaultProvider) Decrypt(ctx context.Context, blob []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(blob)}
+	if err := p.post(ctx, p.transitPath("decrypt"), body, &resp); err != nil {
+		return nil, err
+	}
+	plaintext, err := base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("hashicorp vault returned an invalid plaintext: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Run renews the token periodically, so that periodic service tokens do not expire.
+func (p *vaultProvider) Run(ctx context.Context) error {
+	if p.tokenRenewalInterval <= 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(p.tokenRenewalInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := p.post(ctx, "auth/token/renew-self", map[string]string{}, nil); err != nil {
+				p.log.Warn("Failed to renew hashicorp vault token", "error", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (p *vaultProvider) transitPath(operation string) string {
+	return p.transitEnginePath + "/" + operation + "/" + url.PathEscape(p.keyRing)
+}
+
+func (p *vaultProvider) post(ctx context.Context, path string, body any, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url+"/v1/"+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("hashicorp vault request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var vaultErr struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(respBody, &vaultErr)
+		return fmt.Errorf("hashicorp vault request %s failed with status %d: %s", path, resp.StatusCode, strings.Join(vaultErr.Errors, ", "))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse hashicorp vault response: %w", err)
+	}
+	return nil
+}
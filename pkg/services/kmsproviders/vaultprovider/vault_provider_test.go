@@ -0,0 +1,115 @@
+package vaultprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const testToken = "s.test-token"
+
+// fakeTransit is a stand-in for the transit secrets engine, with a reversible "encryption".
+type fakeTransit struct {
+	renewals atomic.Int32
+}
+
+func (f *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != testToken {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	var req map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	var data map[string]string
+	switch r.URL.Path {
+	case "/v1/transit/encrypt/grafana":
+		data = map[string]string{"ciphertext": "vault:v1:" + reverse(req["plaintext"])}
+	case "/v1/transit/decrypt/grafana":
+		ciphertext, ok := strings.CutPrefix(req["ciphertext"], "vault:v1:")
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid ciphertext"]}`))
+			return
+		}
+		data = map[string]string{"plaintext": reverse(ciphertext)}
+	case "/v1/auth/token/renew-self":
+		f.renewals.Add(1)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"errors":[]}`))
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func section(t *testing.T, config string) *setting.DynamicSection {
+	t.Helper()
+	cfg, err := setting.NewCfgFromBytes([]byte("[security.encryption.hashicorpvault.v1]\n" + config))
+	require.NoError(t, err)
+	return cfg.SectionWithEnvOverrides("security.encryption.hashicorpvault.v1")
+}
+
+func TestVaultProvider(t *testing.T) {
+	transit := &fakeTransit{}
+	server := httptest.NewServer(transit)
+	t.Cleanup(server.Close)
+
+	t.Run("encrypt and decrypt with the transit engine", func(t *testing.T) {
+		p, err := New(section(t, fmt.Sprintf("url = %s/\ntoken = %s\nkey_ring = grafana", server.URL, testToken)))
+		require.NoError(t, err)
+
+		encrypted, err := p.Encrypt(context.Background(), []byte("data key"))
+		require.NoError(t, err)
+		require.Equal(t, "vault:v1:"+reverse(base64.StdEncoding.EncodeToString([]byte("data key"))), string(encrypted))
+
+		decrypted, err := p.Decrypt(context.Background(), encrypted)
+		require.NoError(t, err)
+		require.Equal(t, []byte("data key"), decrypted)
+	})
+
+	t.Run("returns vault errors", func(t *testing.T) {
+		p, err := New(section(t, fmt.Sprintf("url = %s\ntoken = invalid\nkey_ring = grafana", server.URL)))
+		require.NoError(t, err)
+
+		_, err = p.Encrypt(context.Background(), []byte("data key"))
+		require.ErrorContains(t, err, "permission denied")
+	})
+
+	t.Run("requires url, token and key ring", func(t *testing.T) {
+		_, err := New(section(t, fmt.Sprintf("url = %s\ntoken = %s", server.URL, testToken)))
+		require.Error(t, err)
+	})
+
+	t.Run("renews the token", func(t *testing.T) {
+		p, err := New(section(t, fmt.Sprintf("url = %s\ntoken = %s\nkey_ring = grafana\ntoken_renewal_interval = 10ms", server.URL, testToken)))
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- p.(*vaultProvider).Run(ctx) }()
+		require.Eventually(t, func() bool { return transit.renewals.Load() > 0 }, time.Second, 10*time.Millisecond)
+		cancel()
+		require.NoError(t, <-done)
+	})
+}
@@ -0,0 +1,102 @@
+package ldapsync
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+
+	router.Group("/api/admin/ldap/sync", func(syncRoute routing.RouteRegister) {
+		syncRoute.Post("/", authorize(ac.EvalPermission(ac.ActionLDAPUsersSync)), routing.Wrap(s.postSync))
+		syncRoute.Get("/status", authorize(ac.EvalPermission(ac.ActionLDAPStatusRead)), routing.Wrap(s.getSyncStatus))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:parameters postLDAPSync
+type PostLDAPSyncParams struct {
+	// in:body
+	Body PostSyncCommand `json:"body"`
+}
+
+// PostSyncCommand starts an LDAP sync.
+type PostSyncCommand struct {
+	// DryRun reports the changes without applying them.
+	DryRun bool `json:"dryRun"`
+}
+
+// swagger:response postLDAPSyncResponse
+type PostLDAPSyncResponse struct {
+	// in:body
+	Body Report `json:"body"`
+}
+
+// swagger:response getLDAPSyncStatusResponse
+type GetLDAPSyncStatusResponse struct {
+	// in:body
+	Body Status `json:"body"`
+}
+
+// swagger:route POST /admin/ldap/sync admin_ldap postLDAPSync
+//
+// Synchronizes all the users who logged in through LDAP with the LDAP servers and returns the changes.
+// With `dryRun` the changes are only reported.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.user:sync`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: postLDAPSyncResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) postSync(c *contextmodel.ReqContext) response.Response {
+	cmd := PostSyncCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	report, err := s.Sync(c.Req.Context(), cmd.DryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrLDAPDisabled):
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, ErrSyncInProgress):
+			return response.Error(http.StatusConflict, err.Error(), nil)
+		case errors.Is(err, ErrServerUnavailable), errors.Is(err, ErrNoUsersFound):
+			return response.Error(http.StatusInternalServerError, "LDAP sync aborted", err)
+		}
+		return response.Error(http.StatusInternalServerError, "LDAP sync failed", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /admin/ldap/sync/status admin_ldap getLDAPSyncStatus
+//
+// Returns the schedule of the LDAP sync and the report of the last sync which applied changes.
+//
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `ldap.status:read`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: getLDAPSyncStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) getSyncStatus(c *contextmodel.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, s.Status())
+}
@@ -0,0 +1,265 @@
+// Package ldapsync periodically reconciles the Grafana users signed up through LDAP with the
+// LDAP servers, so that changes in the directory apply without waiting for the users to log in.
+package ldapsync
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// defaultSchedule is used when sync_cron is empty.
+	defaultSchedule = "0 1 * * *"
+	// lookupBatchSize is the number of users looked up in the LDAP servers with a single search.
+	lookupBatchSize = 500
+	// lockInterval prevents the other Grafana instances from running the same scheduled sync.
+	lockInterval = time.Minute
+)
+
+type Service struct {
+	cfg                    *ldap.Config
+	adminUser              string
+	ldapService            service.LDAP
+	ldapGroups             ldap.Groups
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService ac.TeamPermissionsService
+	sessionService         auth.UserTokenService
+	identitySynchronizer   authn.IdentitySynchronizer
+	serverLock             *serverlock.ServerLockService
+	log                    log.Logger
+	now                    func() time.Time
+
+	// syncMu prevents concurrent syncs in this instance.
+	syncMu     sync.Mutex
+	reportMu   sync.RWMutex
+	lastReport *Report
+}
+
+func ProvideService(cfg *setting.Cfg, router routing.RouteRegister, accessControl ac.AccessControl,
+	ldapService service.LDAP, ldapGroups ldap.Groups, userService user.Service, orgService org.Service,
+	teamService team.Service, teamPermissionsService ac.TeamPermissionsService, sessionService auth.UserTokenService,
+	identitySynchronizer authn.IdentitySynchronizer, serverLock *serverlock.ServerLockService) *Service {
+	s := &Service{
+		cfg:                    ldap.GetLDAPConfig(cfg),
+		adminUser:              cfg.AdminUser,
+		ldapService:            ldapService,
+		ldapGroups:             ldapGroups,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		sessionService:         sessionService,
+		identitySynchronizer:   identitySynchronizer,
+		serverLock:             serverLock,
+		log:                    log.New("ldap.sync"),
+		now:                    time.Now,
+	}
+	s.registerAPIEndpoints(router, accessControl)
+	return s
+}
+
+// IsDisabled disables the scheduled sync. Syncs can still be started through the API when LDAP is enabled.
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled || !s.cfg.ActiveSyncEnabled
+}
+
+// Run syncs the users with the LDAP servers on the configured schedule.
+func (s *Service) Run(ctx context.Context) error {
+	schedule, err := s.schedule()
+	if err != nil {
+		s.log.Error("Scheduled LDAP sync is disabled", "error", err)
+		return nil
+	}
+
+	for {
+		timer := time.NewTimer(schedule.Next(s.now()).Sub(s.now()))
+		select {
+		case <-timer.C:
+			s.scheduledSync(ctx)
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) scheduledSync(ctx context.Context) {
+	err := s.serverLock.LockAndExecute(ctx, "ldap sync", lockInterval, func(ctx context.Context) {
+		report, err := s.Sync(ctx, false)
+		if err != nil {
+			s.log.Error("Scheduled LDAP sync failed", "error", err)
+			return
+		}
+		s.log.Info("Scheduled LDAP sync finished", "checked", report.Checked, "changed", len(report.Users), "duration", report.FinishedAt.Sub(report.StartedAt))
+	})
+	if err != nil {
+		s.log.Error("Failed to acquire the LDAP sync lock", "error", err)
+	}
+}
+
+func (s *Service) schedule() (cron.Schedule, error) {
+	spec := s.cfg.SyncCron
+	if spec == "" {
+		spec = defaultSchedule
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sync_cron %q: %w", spec, err)
+	}
+	return schedule, nil
+}
+
+// Status returns the state of the scheduled sync and the report of the last applied sync.
+func (s *Service) Status() *Status {
+	status := &Status{
+		Enabled:  !s.IsDisabled(),
+		Schedule: s.cfg.SyncCron,
+	}
+	if status.Schedule == "" {
+		status.Schedule = defaultSchedule
+	}
+	if schedule, err := s.schedule(); err == nil && status.Enabled {
+		next := schedule.Next(s.now())
+		status.NextSync = &next
+	}
+	s.reportMu.RLock()
+	status.LastReport = s.lastReport
+	s.reportMu.RUnlock()
+	return status
+}
+
+// Sync reconciles the Grafana users which last logged in through LDAP with the LDAP servers.
+// Users which are not found in any server are disabled and their sessions are revoked, the org
+// roles, Grafana admin flag and team memberships of the other users are updated from their groups.
+// With dryRun the changes are only reported.
+func (s *Service) Sync(ctx context.Context, dryRun bool) (*Report, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrLDAPDisabled
+	}
+	if !s.syncMu.TryLock() {
+		return nil, ErrSyncInProgress
+	}
+	defer s.syncMu.Unlock()
+
+	report := &Report{DryRun: dryRun, StartedAt: s.now(), Users: []UserChange{}}
+	err := s.sync(ctx, report)
+	report.FinishedAt = s.now()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	if !dryRun {
+		s.reportMu.Lock()
+		s.lastReport = report
+		s.reportMu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) sync(ctx context.Context, report *Report) error {
+	client := s.ldapService.Client()
+	if client == nil {
+		return ErrLDAPDisabled
+	}
+
+	// A server which cannot be reached is skipped by the lookups, which would disable its users.
+	statuses, err := client.Ping()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		if !status.Available {
+			return fmt.Errorf("%w: %s:%d: %v", ErrServerUnavailable, status.Host, status.Port, status.Error)
+		}
+	}
+
+	users, err := s.ldapUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list LDAP users: %w", err)
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	found := make(map[string]*login.ExternalUserInfo, len(users))
+	for start := 0; start < len(users); start += lookupBatchSize {
+		end := min(start+lookupBatchSize, len(users))
+		logins := make([]string, 0, end-start)
+		for _, u := range users[start:end] {
+			logins = append(logins, u.Login)
+		}
+		infos, err := client.Users(logins)
+		if err != nil {
+			return fmt.Errorf("failed to search the LDAP servers: %w", err)
+		}
+		for _, info := range infos {
+			// The first server a user is found in wins, as for logins.
+			if key := strings.ToLower(info.Login); found[key] == nil {
+				found[key] = info
+			}
+		}
+	}
+	if len(found) == 0 {
+		return ErrNoUsersFound
+	}
+
+	for _, u := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		report.Checked++
+		change := s.syncUser(ctx, u, found[strings.ToLower(u.Login)], report.DryRun)
+		if !change.isEmpty() {
+			report.Users = append(report.Users, *change)
+		}
+	}
+	return nil
+}
+
+// ldapUsers returns the Grafana users whose most recent login was through LDAP.
+func (s *Service) ldapUsers(ctx context.Context) ([]*user.UserSearchHitDTO, error) {
+	requester := ac.BackgroundUser("ldap_sync", ac.GlobalOrgID, org.RoleAdmin, []ac.Permission{
+		{Action: ac.ActionUsersRead, Scope: ac.ScopeGlobalUsersAll},
+	})
+
+	var users []*user.UserSearchHitDTO
+	for page := 1; ; page++ {
+		result, err := s.userService.Search(ctx, &user.SearchUsersQuery{
+			SignedInUser: requester,
+			AuthModule:   login.LDAPAuthModule,
+			Page:         page,
+			Limit:        lookupBatchSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, result.Users...)
+		if len(result.Users) < lookupBatchSize {
+			return users, nil
+		}
+	}
+}
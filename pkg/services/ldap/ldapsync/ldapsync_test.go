@@ -0,0 +1,290 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/ldap"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+func TestService_Sync(t *testing.T) {
+	admin, notAdmin := true, false
+	users := []*user.UserSearchHitDTO{
+		{ID: 1, Login: "admin"},
+		{ID: 2, Login: "Alice", Email: "alice@example.org"},
+		{ID: 3, Login: "bob"},
+		{ID: 4, Login: "carol", IsDisabled: true},
+		{ID: 5, Login: "dave", IsDisabled: true},
+	}
+	ldapUsers := []*login.ExternalUserInfo{
+		{Login: "alice", OrgRoles: map[int64]org.RoleType{1: org.RoleEditor}, IsGrafanaAdmin: &admin},
+		{Login: "dave", OrgRoles: map[int64]org.RoleType{1: org.RoleViewer}, IsGrafanaAdmin: &notAdmin},
+	}
+
+	t.Run("dry run should report the changes without applying them", func(t *testing.T) {
+		s, env := setupTestService(t, users, ldapUsers)
+
+		report, err := s.Sync(context.Background(), true)
+		require.NoError(t, err)
+
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 5, report.Checked)
+		require.Len(t, report.Users, 4)
+		assert.Equal(t, UserChange{UserID: 1, Login: "admin", Error: "refusing to disable the Grafana server admin"}, report.Users[0])
+		assert.Equal(t, UserChange{
+			UserID: 2, Login: "Alice", Email: "alice@example.org", IsGrafanaAdmin: &admin,
+			OrgRoles: []OrgRoleChange{{OrgID: 1, OrgName: "Main Org.", From: org.RoleViewer, To: org.RoleEditor}},
+		}, report.Users[1])
+		assert.Equal(t, UserChange{UserID: 3, Login: "bob", Disable: true}, report.Users[2])
+		assert.Equal(t, UserChange{UserID: 5, Login: "dave", Enable: true}, report.Users[3])
+
+		assert.Empty(t, env.disabled)
+		assert.Empty(t, env.revoked)
+		assert.Empty(t, env.synced)
+		assert.Nil(t, s.Status().LastReport)
+	})
+
+	t.Run("should disable missing users and sync the others", func(t *testing.T) {
+		s, env := setupTestService(t, users, ldapUsers)
+
+		report, err := s.Sync(context.Background(), false)
+		require.NoError(t, err)
+
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []int64{3}, env.disabled)
+		assert.Equal(t, []int64{3}, env.revoked)
+		require.Len(t, env.synced, 2)
+		assert.Equal(t, "alice", env.synced[0].Login)
+		assert.True(t, env.synced[0].ClientParams.SyncOrgRoles)
+		assert.True(t, env.synced[0].ClientParams.EnableUser)
+		assert.Equal(t, "dave", env.synced[1].Login)
+		assert.Equal(t, report, s.Status().LastReport)
+	})
+
+	t.Run("should disable users without any matching group", func(t *testing.T) {
+		s, env := setupTestService(t, users[2:3], []*login.ExternalUserInfo{{Login: "bob", IsDisabled: true}})
+
+		report, err := s.Sync(context.Background(), false)
+		require.NoError(t, err)
+
+		require.Len(t, report.Users, 1)
+		assert.True(t, report.Users[0].Disable)
+		assert.Equal(t, []int64{3}, env.disabled)
+		assert.Empty(t, env.synced)
+	})
+
+	t.Run("should abort when a server is unavailable", func(t *testing.T) {
+		s, env := setupTestService(t, users, ldapUsers)
+		env.client.statuses = append(env.client.statuses, &multildap.ServerStatus{Host: "ldap2", Port: 389, Error: errors.New("connection refused")})
+
+		_, err := s.Sync(context.Background(), false)
+		require.ErrorIs(t, err, ErrServerUnavailable)
+		assert.Empty(t, env.disabled)
+		assert.Equal(t, err.Error(), s.Status().LastReport.Error)
+	})
+
+	t.Run("should abort when no user is found", func(t *testing.T) {
+		s, env := setupTestService(t, users, nil)
+
+		_, err := s.Sync(context.Background(), false)
+		require.ErrorIs(t, err, ErrNoUsersFound)
+		assert.Empty(t, env.disabled)
+	})
+
+	t.Run("should fail when LDAP is disabled", func(t *testing.T) {
+		s, _ := setupTestService(t, users, ldapUsers)
+		s.cfg.Enabled = false
+
+		_, err := s.Sync(context.Background(), true)
+		require.ErrorIs(t, err, ErrLDAPDisabled)
+	})
+}
+
+func TestService_SyncTeams(t *testing.T) {
+	users := []*user.UserSearchHitDTO{{ID: 2, Login: "alice"}}
+	ldapUsers := []*login.ExternalUserInfo{{Login: "alice", Groups: []string{"cn=ops"}}}
+
+	s, env := setupTestService(t, users, ldapUsers)
+	env.groups.teams = []ldap.TeamOrgGroupDTO{
+		{TeamName: "ops", OrgName: "Main Org.", GroupDN: "cn=ops"},
+		{TeamName: "missing", OrgName: "Main Org.", GroupDN: "cn=ops"},
+	}
+	env.teams.teams = []*team.TeamDTO{{ID: 10, OrgID: 1, Name: "Ops"}, {ID: 11, OrgID: 1, Name: "dev"}}
+	env.teams.ExpectedMembers = []*team.TeamMemberDTO{{OrgID: 1, TeamID: 11, UserID: 2, External: true}}
+
+	report, err := s.Sync(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Users, 1)
+	assert.Equal(t, []TeamChange{
+		{OrgID: 1, TeamID: 10, TeamName: "Ops", Added: true},
+		{OrgID: 1, TeamID: 11, TeamName: "dev"},
+	}, report.Users[0].Teams)
+
+	env.teamPermissions.On("SetUserPermission", mock.Anything, int64(1), ac.User{ID: 2, IsExternal: true}, "10", "Member").Return(&ac.ResourcePermission{}, nil).Once()
+	env.teamPermissions.On("SetUserPermission", mock.Anything, int64(1), ac.User{ID: 2, IsExternal: true}, "11", "").Return(&ac.ResourcePermission{}, nil).Once()
+
+	report, err = s.Sync(context.Background(), false)
+	require.NoError(t, err)
+	require.Len(t, report.Users, 1)
+	assert.Empty(t, report.Users[0].Error)
+	env.teamPermissions.AssertExpectations(t)
+}
+
+type testEnv struct {
+	client          *fakeLDAPClient
+	groups          *fakeGroups
+	teams           *fakeTeamService
+	teamPermissions *acmock.MockPermissionsService
+	disabled        []int64
+	revoked         []int64
+	synced          []*authn.Identity
+}
+
+func setupTestService(t *testing.T, users []*user.UserSearchHitDTO, ldapUsers []*login.ExternalUserInfo) (*Service, *testEnv) {
+	t.Helper()
+
+	env := &testEnv{
+		client: &fakeLDAPClient{
+			statuses: []*multildap.ServerStatus{{Host: "ldap", Port: 389, Available: true}},
+			users:    ldapUsers,
+		},
+		groups:          &fakeGroups{},
+		teams:           &fakeTeamService{FakeService: teamtest.NewFakeService()},
+		teamPermissions: acmock.NewMockedPermissionsService(),
+	}
+
+	ldapService := service.NewLDAPFakeService()
+	ldapService.ExpectedClient = env.client
+
+	sessionService := authtest.NewFakeUserAuthTokenService()
+	sessionService.RevokeAllUserTokensProvider = func(ctx context.Context, userID int64) error {
+		env.revoked = append(env.revoked, userID)
+		return nil
+	}
+
+	userService := &fakeUserService{FakeUserService: usertest.NewUserServiceFake(), env: env}
+	userService.ExpectedSearchUsers = user.SearchUserQueryResult{Users: users, TotalCount: int64(len(users))}
+
+	orgService := orgtest.NewOrgServiceFake()
+	orgService.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1, Name: "Main Org.", Role: org.RoleViewer}}
+	orgService.ExpectedOrg = &org.Org{ID: 1, Name: "Main Org."}
+
+	s := &Service{
+		cfg:                    &ldap.Config{Enabled: true, ActiveSyncEnabled: true},
+		adminUser:              "admin",
+		ldapService:            ldapService,
+		ldapGroups:             env.groups,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            env.teams,
+		teamPermissionsService: env.teamPermissions,
+		sessionService:         sessionService,
+		identitySynchronizer:   &fakeIdentitySynchronizer{FakeService: &authntest.FakeService{}, env: env},
+		log:                    log.NewNopLogger(),
+		now:                    time.Now,
+	}
+	return s, env
+}
+
+type fakeLDAPClient struct {
+	multildap.IMultiLDAP
+	statuses []*multildap.ServerStatus
+	users    []*login.ExternalUserInfo
+}
+
+func (c *fakeLDAPClient) Ping() ([]*multildap.ServerStatus, error) {
+	return c.statuses, nil
+}
+
+func (c *fakeLDAPClient) Users(logins []string) ([]*login.ExternalUserInfo, error) {
+	var result []*login.ExternalUserInfo
+	for _, u := range c.users {
+		for _, l := range logins {
+			if strings.EqualFold(u.Login, l) {
+				result = append(result, u)
+			}
+		}
+	}
+	return result, nil
+}
+
+type fakeGroups struct {
+	teams []ldap.TeamOrgGroupDTO
+}
+
+func (g *fakeGroups) GetTeams(_ []string, _ []int64) ([]ldap.TeamOrgGroupDTO, error) {
+	return g.teams, nil
+}
+
+type fakeUserService struct {
+	*usertest.FakeUserService
+	env *testEnv
+}
+
+func (s *fakeUserService) Update(ctx context.Context, cmd *user.UpdateUserCommand) error {
+	if cmd.IsDisabled != nil && *cmd.IsDisabled {
+		s.env.disabled = append(s.env.disabled, cmd.UserID)
+	}
+	return nil
+}
+
+type fakeIdentitySynchronizer struct {
+	*authntest.FakeService
+	env *testEnv
+}
+
+func (s *fakeIdentitySynchronizer) SyncIdentity(ctx context.Context, identity *authn.Identity) error {
+	s.env.synced = append(s.env.synced, identity)
+	return nil
+}
+
+type fakeTeamService struct {
+	*teamtest.FakeService
+	teams []*team.TeamDTO
+}
+
+func (s *fakeTeamService) SearchTeams(ctx context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{}
+	for _, t := range s.teams {
+		if query.Name != "" && !strings.EqualFold(query.Name, t.Name) {
+			continue
+		}
+		if len(query.TeamIds) > 0 && !slices.Contains(query.TeamIds, t.ID) {
+			continue
+		}
+		result.Teams = append(result.Teams, t)
+	}
+	return result, nil
+}
+
+func (s *fakeTeamService) IsTeamMember(ctx context.Context, orgID, teamID, userID int64) (bool, error) {
+	for _, m := range s.ExpectedMembers {
+		if m.OrgID == orgID && m.TeamID == teamID && m.UserID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,70 @@
+package ldapsync
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	ErrLDAPDisabled      = errors.New("LDAP is not enabled")
+	ErrSyncInProgress    = errors.New("an LDAP sync is already in progress")
+	ErrServerUnavailable = errors.New("LDAP server is unavailable")
+	// ErrNoUsersFound is returned when none of the Grafana LDAP users are found in the LDAP servers.
+	// It usually means the search filter or the bind account is misconfigured, so the sync is
+	// aborted instead of disabling every user.
+	ErrNoUsersFound = errors.New("none of the Grafana LDAP users were found in the LDAP servers")
+)
+
+// Report describes the changes of an LDAP sync. For a dry run the changes are not applied.
+type Report struct {
+	DryRun     bool         `json:"dryRun"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Checked    int          `json:"checked"`
+	Users      []UserChange `json:"users"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// UserChange describes the changes for a single Grafana user.
+type UserChange struct {
+	UserID         int64           `json:"userId"`
+	Login          string          `json:"login"`
+	Email          string          `json:"email"`
+	Disable        bool            `json:"disable,omitempty"`
+	Enable         bool            `json:"enable,omitempty"`
+	IsGrafanaAdmin *bool           `json:"isGrafanaAdmin,omitempty"`
+	OrgRoles       []OrgRoleChange `json:"orgRoles,omitempty"`
+	Teams          []TeamChange    `json:"teams,omitempty"`
+	Error          string          `json:"error,omitempty"`
+}
+
+// OrgRoleChange describes a change of the org role of a user. An empty From means the user is
+// added to the org, an empty To means the user is removed from it.
+type OrgRoleChange struct {
+	OrgID   int64        `json:"orgId"`
+	OrgName string       `json:"orgName"`
+	From    org.RoleType `json:"from,omitempty"`
+	To      org.RoleType `json:"to,omitempty"`
+}
+
+// TeamChange describes a team membership which is added or removed.
+type TeamChange struct {
+	OrgID    int64  `json:"orgId"`
+	TeamID   int64  `json:"teamId"`
+	TeamName string `json:"teamName"`
+	Added    bool   `json:"added"`
+}
+
+func (c *UserChange) isEmpty() bool {
+	return !c.Disable && !c.Enable && c.IsGrafanaAdmin == nil && len(c.OrgRoles) == 0 && len(c.Teams) == 0 && c.Error == ""
+}
+
+// Status is the state of the scheduled LDAP sync.
+type Status struct {
+	Enabled    bool       `json:"enabled"`
+	Schedule   string     `json:"schedule"`
+	NextSync   *time.Time `json:"nextSync,omitempty"`
+	LastReport *Report    `json:"lastReport,omitempty"`
+}
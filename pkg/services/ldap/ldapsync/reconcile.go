@@ -0,0 +1,251 @@
+package ldapsync
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// syncUser computes and, unless dryRun is set, applies the changes for a single user. info is nil
+// when the user was not found in the LDAP servers. Errors are recorded on the change so that one
+// user does not stop the sync of the others.
+func (s *Service) syncUser(ctx context.Context, usr *user.UserSearchHitDTO, info *login.ExternalUserInfo, dryRun bool) *UserChange {
+	change := &UserChange{UserID: usr.ID, Login: usr.Login, Email: usr.Email}
+
+	// Users without any matching group are disabled at login, so they are disabled here as well.
+	if info == nil || info.IsDisabled {
+		if usr.IsDisabled {
+			return change
+		}
+		if usr.Login == s.adminUser {
+			change.Error = "refusing to disable the Grafana server admin"
+			return change
+		}
+		change.Disable = true
+		if !dryRun {
+			if err := s.disableUser(ctx, usr.ID); err != nil {
+				s.log.Error("Failed to disable user", "userId", usr.ID, "login", usr.Login, "error", err)
+				change.Error = err.Error()
+			}
+		}
+		return change
+	}
+
+	change.Enable = usr.IsDisabled
+	if info.IsGrafanaAdmin != nil && *info.IsGrafanaAdmin != usr.IsAdmin {
+		change.IsGrafanaAdmin = info.IsGrafanaAdmin
+	}
+
+	orgIDs, err := s.orgRoleChanges(ctx, usr.ID, info, change)
+	if err == nil {
+		change.Teams, err = s.teamChanges(ctx, usr.ID, info, orgIDs)
+	}
+	if err != nil {
+		s.log.Error("Failed to compare user with LDAP", "userId", usr.ID, "login", usr.Login, "error", err)
+		change.Error = err.Error()
+		return change
+	}
+
+	if dryRun {
+		return change
+	}
+	// The user is synced even without reported changes to update the name and email.
+	if err := s.identitySynchronizer.SyncIdentity(ctx, s.identityFromLDAPUser(info)); err != nil {
+		s.log.Error("Failed to sync user", "userId", usr.ID, "login", usr.Login, "error", err)
+		change.Error = err.Error()
+		return change
+	}
+	if err := s.applyTeamChanges(ctx, usr.ID, change.Teams); err != nil {
+		s.log.Error("Failed to sync team memberships", "userId", usr.ID, "login", usr.Login, "error", err)
+		change.Error = err.Error()
+	}
+	return change
+}
+
+func (s *Service) disableUser(ctx context.Context, userID int64) error {
+	isDisabled := true
+	if err := s.userService.Update(ctx, &user.UpdateUserCommand{UserID: userID, IsDisabled: &isDisabled}); err != nil {
+		return err
+	}
+	return s.sessionService.RevokeAllUserTokens(ctx, userID)
+}
+
+// orgRoleChanges adds the org role changes applied by the org sync at login to change, and returns
+// the IDs of the orgs the user is a member of after the sync.
+func (s *Service) orgRoleChanges(ctx context.Context, userID int64, info *login.ExternalUserInfo, change *UserChange) ([]int64, error) {
+	current, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	orgIDs := make([]int64, 0, len(current))
+	// Like the org sync, roles are left untouched when no group maps to an org.
+	if s.cfg.SkipOrgRoleSync || len(info.OrgRoles) == 0 {
+		for _, o := range current {
+			orgIDs = append(orgIDs, o.OrgID)
+		}
+		return orgIDs, nil
+	}
+
+	handled := make(map[int64]bool, len(current))
+	for _, o := range current {
+		handled[o.OrgID] = true
+		role := info.OrgRoles[o.OrgID]
+		if role != "" {
+			orgIDs = append(orgIDs, o.OrgID)
+		}
+		if role != o.Role {
+			change.OrgRoles = append(change.OrgRoles, OrgRoleChange{OrgID: o.OrgID, OrgName: o.Name, From: o.Role, To: role})
+		}
+	}
+
+	for orgID, role := range info.OrgRoles {
+		if handled[orgID] {
+			continue
+		}
+		o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID})
+		if err != nil {
+			// Orgs which do not exist are skipped by the org sync.
+			if errors.Is(err, org.ErrOrgNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		orgIDs = append(orgIDs, orgID)
+		change.OrgRoles = append(change.OrgRoles, OrgRoleChange{OrgID: orgID, OrgName: o.Name, To: role})
+	}
+
+	sort.Slice(change.OrgRoles, func(i, j int) bool { return change.OrgRoles[i].OrgID < change.OrgRoles[j].OrgID })
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+	return orgIDs, nil
+}
+
+// teamChanges compares the external team memberships of the user with the teams mapped to the
+// LDAP groups of the user. Memberships which were added manually are never removed.
+func (s *Service) teamChanges(ctx context.Context, userID int64, info *login.ExternalUserInfo, orgIDs []int64) ([]TeamChange, error) {
+	if len(orgIDs) == 0 {
+		return nil, nil
+	}
+	mapped, err := s.ldapGroups.GetTeams(info.Groups, orgIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []TeamChange
+	for _, orgID := range orgIDs {
+		o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID})
+		if err != nil {
+			return nil, err
+		}
+		requester := ac.BackgroundUser("ldap_sync", orgID, org.RoleAdmin, []ac.Permission{
+			{Action: ac.ActionTeamsRead, Scope: ac.ScopeTeamsAll},
+		})
+
+		desired := map[int64]bool{}
+		for _, m := range mapped {
+			if m.OrgName != o.Name {
+				continue
+			}
+			t, err := s.findTeam(ctx, requester, orgID, m.TeamName)
+			if err != nil {
+				return nil, err
+			}
+			// Teams must exist to be synced, as for the team sync at login.
+			if t == nil || desired[t.ID] {
+				continue
+			}
+			desired[t.ID] = true
+			isMember, err := s.teamService.IsTeamMember(ctx, orgID, t.ID, userID)
+			if err != nil {
+				return nil, err
+			}
+			if !isMember {
+				changes = append(changes, TeamChange{OrgID: orgID, TeamID: t.ID, TeamName: t.Name, Added: true})
+			}
+		}
+
+		memberships, err := s.teamService.GetUserTeamMemberships(ctx, orgID, userID, true)
+		if err != nil {
+			return nil, err
+		}
+		var removed []int64
+		for _, m := range memberships {
+			if !desired[m.TeamID] {
+				removed = append(removed, m.TeamID)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		teams, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{OrgID: orgID, TeamIds: removed, SignedInUser: requester})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams.Teams {
+			changes = append(changes, TeamChange{OrgID: orgID, TeamID: t.ID, TeamName: t.Name})
+		}
+	}
+	return changes, nil
+}
+
+func (s *Service) findTeam(ctx context.Context, requester identity.Requester, orgID int64, name string) (*team.TeamDTO, error) {
+	result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{OrgID: orgID, Name: name, Limit: 1, Page: 1, SignedInUser: requester})
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range result.Teams {
+		if strings.EqualFold(t.Name, name) {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// applyTeamChanges adds and removes the memberships as external ones, so that they are told apart
+// from the memberships managed in Grafana.
+func (s *Service) applyTeamChanges(ctx context.Context, userID int64, changes []TeamChange) error {
+	for _, c := range changes {
+		permission := ""
+		if c.Added {
+			permission = team.PermissionTypeMember.String()
+		}
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, c.OrgID, ac.User{ID: userID, IsExternal: true}, strconv.FormatInt(c.TeamID, 10), permission); err != nil {
+			// The team sync at login may have applied the change already.
+			if errors.Is(err, team.ErrTeamMemberNotFound) {
+				continue
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) identityFromLDAPUser(info *login.ExternalUserInfo) *authn.Identity {
+	return &authn.Identity{
+		OrgRoles:        info.OrgRoles,
+		Login:           info.Login,
+		Name:            info.Name,
+		Email:           info.Email,
+		IsGrafanaAdmin:  info.IsGrafanaAdmin,
+		AuthenticatedBy: info.AuthModule,
+		AuthID:          info.AuthId,
+		Groups:          info.Groups,
+		ClientParams: authn.ClientParams{
+			SyncUser:     true,
+			SyncTeams:    true,
+			EnableUser:   true,
+			SyncOrgRoles: !s.cfg.SkipOrgRoleSync,
+			AllowSignUp:  s.cfg.AllowSignUp,
+		},
+	}
+}
@@ -0,0 +1,186 @@
+package brokerinput
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.broker_input")
+)
+
+const (
+	minReconnectDelay = time.Second
+	maxReconnectDelay = time.Minute
+	// stableConnection is how long a connection must stay up for the reconnect delay to be reset.
+	stableConnection = time.Minute
+	// messageQueueSize is how many messages of an input wait to be processed. Messages are dropped
+	// when the queue is full, so that a slow pipeline never blocks the client of the broker.
+	messageQueueSize = 1024
+)
+
+// Processor processes messages received from brokers. It is implemented by pipeline.Pipeline,
+// so messages go through the channel rules in the same way as pushed data.
+type Processor interface {
+	ProcessInput(ctx context.Context, orgID int64, channelID string, body []byte) (bool, error)
+}
+
+// Message is a message received from a broker.
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// subscriber connects to a broker, subscribes to the topics of the input and calls handle for each
+// received message. It blocks until the connection fails or the context is done. handle doesn't
+// block, so it can be called by the read loop of the client.
+type subscriber interface {
+	Subscribe(ctx context.Context, handle func(Message)) error
+}
+
+// Runner subscribes to the configured brokers and publishes their messages into the Live pipeline.
+type Runner struct {
+	inputs        []setting.LiveInputSettings
+	processor     Processor
+	newSubscriber func(setting.LiveInputSettings) (subscriber, error)
+}
+
+func NewRunner(inputs []setting.LiveInputSettings, processor Processor) *Runner {
+	return &Runner{
+		inputs:        inputs,
+		processor:     processor,
+		newSubscriber: newSubscriber,
+	}
+}
+
+func newSubscriber(cfg setting.LiveInputSettings) (subscriber, error) {
+	switch cfg.Type {
+	case setting.LiveInputTypeMQTT:
+		return newMQTTSubscriber(cfg)
+	case setting.LiveInputTypeNATS:
+		return newNATSSubscriber(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported input type: %s", cfg.Type)
+	}
+}
+
+// Run runs all inputs until the context is done. Inputs reconnect to their broker on failures.
+func (r *Runner) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, cfg := range r.inputs {
+		sub, err := r.newSubscriber(cfg)
+		if err != nil {
+			logger.Error("Failed to create Live input", "input", cfg.Name, "error", err)
+			continue
+		}
+		wg.Add(1)
+		go func(cfg setting.LiveInputSettings, sub subscriber) {
+			defer wg.Done()
+			r.runInput(ctx, cfg, sub)
+		}(cfg, sub)
+	}
+	wg.Wait()
+	return nil
+}
+
+func (r *Runner) runInput(ctx context.Context, cfg setting.LiveInputSettings, sub subscriber) {
+	messages := make(chan Message, messageQueueSize)
+	processed := make(chan struct{})
+	defer func() { <-processed }()
+	go func() {
+		defer close(processed)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg := <-messages:
+				r.handleMessage(ctx, cfg, msg)
+			}
+		}
+	}()
+	handle := func(msg Message) {
+		select {
+		case messages <- msg:
+		default:
+			logger.Warn("Dropping Live input message, the pipeline is too slow", "input", cfg.Name, "topic", msg.Topic)
+		}
+	}
+
+	delay := minReconnectDelay
+	for {
+		logger.Debug("Connecting Live input", "input", cfg.Name, "type", cfg.Type)
+		connectedAt := time.Now()
+		err := sub.Subscribe(ctx, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(connectedAt) > stableConnection {
+			delay = minReconnectDelay
+		}
+		logger.Warn("Live input disconnected, reconnecting", "input", cfg.Name, "error", err, "delay", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (r *Runner) handleMessage(ctx context.Context, cfg setting.LiveInputSettings, msg Message) {
+	channel, err := topicToChannel(cfg.ChannelPrefix, cfg.Type, msg.Topic)
+	if err != nil {
+		logger.Warn("Dropping Live input message", "input", cfg.Name, "topic", msg.Topic, "error", err)
+		return
+	}
+	ok, err := r.processor.ProcessInput(ctx, cfg.OrgID, channel, msg.Payload)
+	if err != nil {
+		logger.Error("Error processing Live input message", "input", cfg.Name, "channel", channel, "error", err)
+		return
+	}
+	if !ok {
+		logger.Debug("No pipeline rule to convert Live input message", "input", cfg.Name, "channel", channel)
+	}
+}
+
+// topicToChannel maps the topic of a message to a Live channel. MQTT topic levels and NATS subject
+// tokens become the parts of the channel path, and characters that are not allowed in channel paths
+// are replaced with underscores.
+func topicToChannel(prefix, inputType, topic string) (string, error) {
+	if inputType == setting.LiveInputTypeNATS {
+		topic = strings.ReplaceAll(topic, ".", "/")
+	}
+	parts := strings.Split(strings.Trim(topic, "/"), "/")
+	for i, part := range parts {
+		if part == "" {
+			part = "_"
+		}
+		parts[i] = strings.Map(func(r rune) rune {
+			if isChannelPathRune(r) {
+				return r
+			}
+			return '_'
+		}, part)
+	}
+	channel := prefix + "/" + strings.Join(parts, "/")
+	if _, err := live.ParseChannel(channel); err != nil {
+		return "", fmt.Errorf("invalid channel %s: %w", channel, err)
+	}
+	return channel, nil
+}
+
+func isChannelPathRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+		r == '_' || r == '-' || r == '.' || r == '='
+}
@@ -0,0 +1,167 @@
+package brokerinput
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestTopicToChannel(t *testing.T) {
+	tests := []struct {
+		name      string
+		inputType string
+		topic     string
+		expected  string
+	}{
+		{name: "mqtt topic levels", inputType: setting.LiveInputTypeMQTT, topic: "line1/temperature", expected: "stream/factory/line1/temperature"},
+		{name: "mqtt leading slash", inputType: setting.LiveInputTypeMQTT, topic: "/line1/temperature", expected: "stream/factory/line1/temperature"},
+		{name: "mqtt empty level", inputType: setting.LiveInputTypeMQTT, topic: "line1//temperature", expected: "stream/factory/line1/_/temperature"},
+		{name: "mqtt invalid characters", inputType: setting.LiveInputTypeMQTT, topic: "line 1/temp°C", expected: "stream/factory/line_1/temp_C"},
+		{name: "nats subject tokens", inputType: setting.LiveInputTypeNATS, topic: "line1.temperature", expected: "stream/factory/line1/temperature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			channel, err := topicToChannel("stream/factory", tt.inputType, tt.topic)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, channel)
+		})
+	}
+}
+
+type processedInput struct {
+	orgID   int64
+	channel string
+	body    string
+}
+
+type fakeProcessor struct {
+	mu     sync.Mutex
+	inputs []processedInput
+}
+
+func (p *fakeProcessor) ProcessInput(_ context.Context, orgID int64, channelID string, body []byte) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inputs = append(p.inputs, processedInput{orgID: orgID, channel: channelID, body: string(body)})
+	return true, nil
+}
+
+func (p *fakeProcessor) processed() []processedInput {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]processedInput(nil), p.inputs...)
+}
+
+type fakeSubscriber struct {
+	messages []Message
+	calls    int
+}
+
+func (s *fakeSubscriber) Subscribe(ctx context.Context, handle func(Message)) error {
+	s.calls++
+	if s.calls > 1 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	for _, msg := range s.messages {
+		handle(msg)
+	}
+	return errors.New("connection lost")
+}
+
+func TestRunner(t *testing.T) {
+	processor := &fakeProcessor{}
+	sub := &fakeSubscriber{messages: []Message{
+		{Topic: "line1/temperature", Payload: []byte("21.5")},
+		{Topic: "line2/temperature", Payload: []byte("19")},
+	}}
+	runner := NewRunner([]setting.LiveInputSettings{{
+		Name:          "factory",
+		Type:          setting.LiveInputTypeMQTT,
+		OrgID:         2,
+		ChannelPrefix: "stream/factory",
+	}}, processor)
+	runner.newSubscriber = func(setting.LiveInputSettings) (subscriber, error) {
+		return sub, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+
+	// The subscriber fails after the first connection, the runner reconnects after a delay.
+	require.Eventually(t, func() bool {
+		return len(processor.processed()) == 2
+	}, time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	require.Equal(t, []processedInput{
+		{orgID: 2, channel: "stream/factory/line1/temperature", body: "21.5"},
+		{orgID: 2, channel: "stream/factory/line2/temperature", body: "19"},
+	}, processor.processed())
+}
+
+type blockingProcessor struct {
+	release chan struct{}
+}
+
+func (p *blockingProcessor) ProcessInput(ctx context.Context, _ int64, _ string, _ []byte) (bool, error) {
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+	}
+	return true, nil
+}
+
+type subscriberFunc func(ctx context.Context, handle func(Message)) error
+
+func (f subscriberFunc) Subscribe(ctx context.Context, handle func(Message)) error {
+	return f(ctx, handle)
+}
+
+func TestRunner_SlowPipelineDoesNotBlockSubscriber(t *testing.T) {
+	processor := &blockingProcessor{release: make(chan struct{})}
+	defer close(processor.release)
+	runner := NewRunner([]setting.LiveInputSettings{{
+		Name:          "factory",
+		Type:          setting.LiveInputTypeMQTT,
+		OrgID:         2,
+		ChannelPrefix: "stream/factory",
+	}}, processor)
+
+	// More messages than the queue can hold are received while the pipeline is blocked.
+	delivered := make(chan struct{})
+	runner.newSubscriber = func(setting.LiveInputSettings) (subscriber, error) {
+		return subscriberFunc(func(ctx context.Context, handle func(Message)) error {
+			for i := 0; i < messageQueueSize+10; i++ {
+				handle(Message{Topic: "line1/temperature", Payload: []byte("21.5")})
+			}
+			close(delivered)
+			<-ctx.Done()
+			return ctx.Err()
+		}), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- runner.Run(ctx)
+	}()
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the subscriber is blocked by the pipeline")
+	}
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,120 @@
+package brokerinput
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	mqttKeepAlive   = 30 * time.Second
+	mqttDialTimeout = 10 * time.Second
+	// mqttMaxPacketSize limits the memory used by a single message.
+	mqttMaxPacketSize = 16 * 1024 * 1024
+)
+
+// mqttSubscriber subscribes to MQTT 3.1.1 topics. Subscriptions use QoS 0 or 1, and a clean
+// session is used on every connection since messages sent while Grafana is disconnected are
+// not needed for live data.
+type mqttSubscriber struct {
+	cfg       setting.LiveInputSettings
+	tlsConfig *tls.Config
+	keepAlive time.Duration
+}
+
+func newMQTTSubscriber(cfg setting.LiveInputSettings) (*mqttSubscriber, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mqtt url: %w", err)
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "grafana-" + util.GenerateShortUID()
+	}
+	s := &mqttSubscriber{cfg: cfg, keepAlive: mqttKeepAlive}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		s.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("unsupported mqtt url scheme: %s", u.Scheme)
+	}
+	return s, nil
+}
+
+func (s *mqttSubscriber) Subscribe(ctx context.Context, handle func(Message)) error {
+	connectCtx, cancel := context.WithTimeout(ctx, mqttDialTimeout)
+	defer cancel()
+
+	options := []mqtt.DialOption{
+		mqtt.WithDialer(&net.Dialer{Timeout: mqttDialTimeout}),
+		mqtt.WithMaxPayloadLen(mqttMaxPacketSize),
+	}
+	if s.tlsConfig != nil {
+		options = append(options, mqtt.WithTLSConfig(s.tlsConfig))
+	}
+	cli, err := mqtt.DialContext(connectCtx, s.cfg.URL, options...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = cli.Close() }()
+
+	// The handler is called by the read loop of the client, handle must not block.
+	cli.Handle(mqtt.HandlerFunc(func(msg *mqtt.Message) {
+		handle(Message{Topic: msg.Topic, Payload: msg.Payload})
+	}))
+
+	connectOptions := []mqtt.ConnectOption{
+		mqtt.WithCleanSession(true),
+		mqtt.WithKeepAlive(uint16(s.keepAlive / time.Second)),
+	}
+	if s.cfg.Username != "" {
+		connectOptions = append(connectOptions, mqtt.WithUserNamePassword(s.cfg.Username, s.cfg.Password))
+	}
+	if _, err := cli.Connect(connectCtx, s.cfg.ClientID, connectOptions...); err != nil {
+		return err
+	}
+
+	subscriptions := make([]mqtt.Subscription, 0, len(s.cfg.Topics))
+	for _, topic := range s.cfg.Topics {
+		subscriptions = append(subscriptions, mqtt.Subscription{Topic: topic, QoS: mqtt.QoS(s.cfg.QoS)})
+	}
+	granted, err := cli.Subscribe(connectCtx, subscriptions...)
+	if err != nil {
+		return err
+	}
+	for _, sub := range granted {
+		if sub.QoS == mqtt.SubscribeFailure {
+			return fmt.Errorf("mqtt subscription to %s rejected", sub.Topic)
+		}
+	}
+	logger.Debug("Subscribed to MQTT topics", "input", s.cfg.Name, "topics", s.cfg.Topics)
+
+	// The broker answers pings, so the connection is considered dead if it stops answering.
+	go func() {
+		if err := mqtt.KeepAlive(ctx, cli, s.keepAlive/2, s.keepAlive/2); err != nil {
+			_ = cli.Close()
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = cli.Disconnect(disconnectCtx)
+		return ctx.Err()
+	case <-cli.Done():
+		if err := cli.Err(); err != nil {
+			return err
+		}
+		return errors.New("mqtt broker closed the connection")
+	}
+}
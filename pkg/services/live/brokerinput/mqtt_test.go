@@ -0,0 +1,211 @@
+package brokerinput
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMQTTSubscriber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	brokerErr := make(chan error, 1)
+	go func() {
+		brokerErr <- runFakeMQTTBroker(t, listener)
+	}()
+
+	sub, err := newMQTTSubscriber(setting.LiveInputSettings{
+		Type:     setting.LiveInputTypeMQTT,
+		URL:      "tcp://" + listener.Addr().String(),
+		Username: "user",
+		Password: "secret",
+		ClientID: "grafana-test",
+		Topics:   []string{"factory/#"},
+		QoS:      1,
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	received := make(chan Message, 2)
+	err = sub.Subscribe(ctx, func(msg Message) {
+		received <- msg
+		if len(received) == 2 {
+			cancel()
+		}
+	})
+	require.Error(t, err)
+	require.NoError(t, <-brokerErr)
+	require.Equal(t, Message{Topic: "factory/line1/temperature", Payload: []byte(`{"value":21.5}`)}, <-received)
+	require.Equal(t, Message{Topic: "factory/line2/temperature", Payload: []byte(`{"value":19}`)}, <-received)
+}
+
+func TestMQTTSubscriber_ConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _, _ = readMQTTPacket(bufio.NewReader(conn))
+		_, _ = conn.Write([]byte{mqttConnack << 4, 2, 0, 4})
+	}()
+
+	sub, err := newMQTTSubscriber(setting.LiveInputSettings{
+		URL:    "mqtt://" + listener.Addr().String(),
+		Topics: []string{"factory/#"},
+	})
+	require.NoError(t, err)
+	err = sub.Subscribe(context.Background(), func(Message) {})
+	var connErr *mqtt.ConnectionError
+	require.ErrorAs(t, err, &connErr)
+	require.Equal(t, mqtt.BadUserNameOrPassword, connErr.Code)
+}
+
+// MQTT 3.1.1 control packet types used by the fake broker.
+const (
+	mqttConnect   byte = 1
+	mqttConnack   byte = 2
+	mqttPublish   byte = 3
+	mqttPuback    byte = 4
+	mqttSubscribe byte = 8
+	mqttSuback    byte = 9
+	mqttPingreq   byte = 12
+)
+
+func runFakeMQTTBroker(t *testing.T, listener net.Listener) error {
+	t.Helper()
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+
+	header, body, err := readMQTTPacket(r)
+	if err != nil {
+		return err
+	}
+	require.Equal(t, mqttConnect, header>>4)
+	require.True(t, bytes.Contains(body, []byte("grafana-test")))
+	require.True(t, bytes.Contains(body, []byte("secret")))
+	if _, err := conn.Write([]byte{mqttConnack << 4, 2, 0, 0}); err != nil {
+		return err
+	}
+
+	header, body, err = readMQTTPacket(r)
+	if err != nil {
+		return err
+	}
+	require.Equal(t, mqttSubscribe<<4|0x02, header)
+	topic, rest, err := readMQTTString(body[2:])
+	require.NoError(t, err)
+	require.Equal(t, "factory/#", topic)
+	require.Equal(t, []byte{1}, rest)
+	if _, err := conn.Write([]byte{mqttSuback << 4, 3, body[0], body[1], 1}); err != nil {
+		return err
+	}
+
+	// A QoS 1 message must be acknowledged.
+	var publish bytes.Buffer
+	writeMQTTString(&publish, "factory/line1/temperature")
+	publish.Write([]byte{0, 7})
+	publish.WriteString(`{"value":21.5}`)
+	if _, err := conn.Write(encodeMQTTPacket(mqttPublish<<4|0x02, publish.Bytes())); err != nil {
+		return err
+	}
+	for {
+		header, body, err = readMQTTPacket(r)
+		if err != nil {
+			return err
+		}
+		if header>>4 == mqttPingreq {
+			continue
+		}
+		require.Equal(t, mqttPuback, header>>4)
+		require.Equal(t, []byte{0, 7}, body)
+		break
+	}
+
+	publish.Reset()
+	writeMQTTString(&publish, "factory/line2/temperature")
+	publish.WriteString(`{"value":19}`)
+	_, err = conn.Write(encodeMQTTPacket(mqttPublish<<4, publish.Bytes()))
+	return err
+}
+
+func encodeMQTTPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	n := len(body)
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length := 0
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed mqtt remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length |= int(b&0x7f) << (7 * i)
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func writeMQTTString(buf *bytes.Buffer, s string) {
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(s)))
+	buf.WriteString(s)
+}
+
+func readMQTTString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, errors.New("malformed mqtt string")
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, errors.New("malformed mqtt string")
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
@@ -0,0 +1,99 @@
+package brokerinput
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	natsPingInterval = 30 * time.Second
+	natsDialTimeout  = 10 * time.Second
+)
+
+// natsSubscriber subscribes to subjects of the NATS core protocol. The client does not reconnect
+// by itself, the runner reconnects with a backoff like for other brokers.
+type natsSubscriber struct {
+	cfg       setting.LiveInputSettings
+	tlsConfig *tls.Config
+}
+
+func newNATSSubscriber(cfg setting.LiveInputSettings) (*natsSubscriber, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nats url: %w", err)
+	}
+	s := &natsSubscriber{cfg: cfg}
+	switch u.Scheme {
+	case "nats":
+	case "tls":
+		s.tlsConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	default:
+		return nil, fmt.Errorf("unsupported nats url scheme: %s", u.Scheme)
+	}
+	return s, nil
+}
+
+func (s *natsSubscriber) Subscribe(ctx context.Context, handle func(Message)) error {
+	closed := make(chan struct{})
+	options := []nats.Option{
+		nats.Name("grafana"),
+		nats.NoReconnect(),
+		nats.Timeout(natsDialTimeout),
+		nats.PingInterval(natsPingInterval),
+		nats.ClosedHandler(func(*nats.Conn) { close(closed) }),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			// Slow consumer and permission errors are reported asynchronously.
+			subject := ""
+			if sub != nil {
+				subject = sub.Subject
+			}
+			logger.Warn("NATS input error", "input", s.cfg.Name, "subject", subject, "error", err)
+		}),
+	}
+	if s.tlsConfig != nil {
+		options = append(options, nats.Secure(s.tlsConfig))
+	}
+	if s.cfg.Username != "" {
+		options = append(options, nats.UserInfo(s.cfg.Username, s.cfg.Password))
+	}
+	if s.cfg.Token != "" {
+		options = append(options, nats.Token(s.cfg.Token))
+	}
+	nc, err := nats.Connect(s.cfg.URL, options...)
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+
+	// Messages are delivered by a goroutine of the subscription, handle must not block.
+	for _, subject := range s.cfg.Topics {
+		if _, err := nc.Subscribe(subject, func(msg *nats.Msg) {
+			handle(Message{Topic: msg.Subject, Payload: msg.Data})
+		}); err != nil {
+			return err
+		}
+	}
+	// The server answers the flush once it has processed the subscriptions.
+	if err := nc.FlushTimeout(natsDialTimeout); err != nil {
+		return err
+	}
+	logger.Debug("Subscribed to NATS subjects", "input", s.cfg.Name, "subjects", s.cfg.Topics)
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-closed:
+		if err := nc.LastError(); err != nil {
+			return err
+		}
+		return errors.New("nats connection closed")
+	}
+}
@@ -0,0 +1,136 @@
+package brokerinput
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNATSSubscriber(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- runFakeNATSServer(t, listener)
+	}()
+
+	sub, err := newNATSSubscriber(setting.LiveInputSettings{
+		Type:   setting.LiveInputTypeNATS,
+		URL:    "nats://" + listener.Addr().String(),
+		Token:  "secret",
+		Topics: []string{"factory.>"},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	received := make(chan Message, 2)
+	err = sub.Subscribe(ctx, func(msg Message) {
+		received <- msg
+		if len(received) == 2 {
+			cancel()
+		}
+	})
+	require.Error(t, err)
+	require.NoError(t, <-serverErr)
+	require.Equal(t, Message{Topic: "factory.line1.temperature", Payload: []byte("21.5")}, <-received)
+	require.Equal(t, Message{Topic: "factory.line2.temperature", Payload: []byte("line\r\n19")}, <-received)
+}
+
+func TestNATSSubscriber_Error(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		_, _ = bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+	}()
+
+	sub, err := newNATSSubscriber(setting.LiveInputSettings{
+		URL:    "nats://" + listener.Addr().String(),
+		Topics: []string{"factory.>"},
+	})
+	require.NoError(t, err)
+	err = sub.Subscribe(context.Background(), func(Message) {})
+	require.ErrorIs(t, err, nats.ErrAuthorization)
+}
+
+func runFakeNATSServer(t *testing.T, listener net.Listener) error {
+	t.Helper()
+	conn, err := listener.Accept()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+
+	if _, err := conn.Write([]byte("INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")); err != nil {
+		return err
+	}
+	// The client sends its options and waits for the answer to a ping.
+	line, err := readNATSLine(r)
+	if err != nil {
+		return err
+	}
+	require.True(t, strings.HasPrefix(line, "CONNECT "))
+	require.Contains(t, line, `"auth_token":"secret"`)
+	if line, err = readNATSLine(r); err != nil {
+		return err
+	}
+	require.Equal(t, "PING", line)
+	if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+		return err
+	}
+
+	// The subscriptions are flushed with a ping.
+	if line, err = readNATSLine(r); err != nil {
+		return err
+	}
+	require.True(t, strings.HasPrefix(line, "SUB factory.> "))
+	if line, err = readNATSLine(r); err != nil {
+		return err
+	}
+	require.Equal(t, "PING", line)
+
+	messages := "PONG\r\n" +
+		"PING\r\n" +
+		"MSG factory.line1.temperature 1 4\r\n21.5\r\n" +
+		"MSG factory.line2.temperature 1 _INBOX.reply 8\r\nline\r\n19\r\n"
+	if _, err := conn.Write([]byte(messages)); err != nil {
+		return err
+	}
+	for {
+		line, err := readNATSLine(r)
+		if err != nil {
+			return err
+		}
+		if line == "PONG" {
+			return nil
+		}
+	}
+}
+
+func readNATSLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,6 +40,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/brokerinput"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
@@ -78,7 +80,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, dataSourceService datasources.DataSourceService) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -97,6 +99,9 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		},
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
+		accessControl:     accessControl,
+		sqlWriterStorage:  pipeline.NewSQLWriterStorage(dataSourceService),
+		fileWriterStorage: pipeline.NewFileWriterStorage(filepath.Join(cfg.DataPath, "live", "output")),
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -149,22 +154,36 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	replayLimits := managedstream.FrameBufferLimits{
+		MaxFrames: g.Cfg.LiveReplayMaxFrames,
+		MaxAge:    g.Cfg.LiveReplayMaxAge,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.WithFrameBuffer(managedstream.NewRedisFrameBuffer(redisClient, replayLimits)),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
 			managedstream.NewMemoryFrameCache(),
+			managedstream.WithFrameBuffer(managedstream.NewMemoryFrameBuffer(replayLimits)),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if len(g.Cfg.LiveInputs) > 0 {
+		// Messages of broker inputs are only useful when they go through the channel rules.
+		if err := g.setupPipeline(); err != nil {
+			return nil, err
+		}
+		g.brokerInputRunner = brokerinput.NewRunner(g.Cfg.LiveInputs, g.Pipeline)
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -384,6 +403,7 @@ type GrafanaLive struct {
 	pluginClient          plugins.Client
 	queryDataService      query.Service
 	orgService            org.Service
+	accessControl         accesscontrol.AccessControl
 
 	node         *centrifuge.Node
 	surveyCaller *survey.Caller
@@ -403,6 +423,9 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	sqlWriterStorage    *pipeline.SQLWriterStorage
+	fileWriterStorage   *pipeline.FileWriterStorage
+	brokerInputRunner   *brokerinput.Runner
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -463,6 +486,22 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		})
 	}
 
+	if g.brokerInputRunner != nil {
+		eGroup.Go(func() error {
+			return g.brokerInputRunner.Run(eCtx)
+		})
+	}
+
+	if g.sqlWriterStorage != nil && g.fileWriterStorage != nil {
+		eGroup.Go(func() error {
+			<-ctx.Done()
+			// Flush buffered rows and finish output files on shutdown.
+			g.sqlWriterStorage.Close()
+			g.fileWriterStorage.Close()
+			return ctx.Err()
+		})
+	}
+
 	return eGroup.Wait()
 }
 
@@ -1112,6 +1151,30 @@ func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipe
 	return s.ChannelRules, nil
 }
 
+// setupPipeline creates the Live pipeline with the channel rules and write configs stored in the data path.
+func (g *GrafanaLive) setupPipeline() error {
+	builder := &pipeline.StorageRuleBuilder{
+		Node:              g.node,
+		ManagedStream:     g.ManagedStreamRunner,
+		FrameStorage:      pipeline.NewFrameStorage(),
+		ProcessorStorage:  pipeline.NewFrameProcessorStateStorage(),
+		SQLWriterStorage:  g.sqlWriterStorage,
+		FileWriterStorage: g.fileWriterStorage,
+		Storage: &pipeline.FileStorage{
+			DataPath:       g.Cfg.DataPath,
+			SecretsService: g.SecretsService,
+		},
+		ChannelHandlerGetter: g,
+	}
+	p, err := pipeline.New(pipeline.NewCacheSegmentedTree(builder))
+	if err != nil {
+		return fmt.Errorf("error creating Live pipeline: %w", err)
+	}
+	g.Pipeline = p
+	g.pipelineStorage = builder.Storage
+	return nil
+}
+
 // HandlePipelineConvertTestHTTP ...
 func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -1130,8 +1193,12 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 		Node:                 g.node,
 		ManagedStream:        g.ManagedStreamRunner,
 		FrameStorage:         pipeline.NewFrameStorage(),
+		ProcessorStorage:     pipeline.NewFrameProcessorStateStorage(),
+		SQLWriterStorage:     g.sqlWriterStorage,
+		FileWriterStorage:    g.fileWriterStorage,
 		Storage:              storage,
 		ChannelHandlerGetter: g,
+		DryRun:               true,
 	}
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
@@ -1157,6 +1224,25 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 	})
 }
 
+// checkChannelRuleDatasources checks that the user saving a channel rule can query and write
+// the datasources its SQL outputs insert rows into, since rows are inserted on behalf of the rule author.
+func (g *GrafanaLive) checkChannelRuleDatasources(c *contextmodel.ReqContext, settings pipeline.ChannelRuleSettings) response.Response {
+	for _, uid := range pipeline.SQLOutputDatasourceUIDs(settings) {
+		scope := datasources.ScopeProvider.GetResourceScopeUID(uid)
+		ok, err := g.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, accesscontrol.EvalAll(
+			accesscontrol.EvalPermission(datasources.ActionQuery, scope),
+			accesscontrol.EvalPermission(datasources.ActionWrite, scope),
+		))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to check datasource permissions", err)
+		}
+		if !ok {
+			return response.Error(http.StatusForbidden, fmt.Sprintf("Not allowed to write into datasource %s", uid), nil)
+		}
+	}
+	return nil
+}
+
 // HandleChannelRulesPostHTTP ...
 func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -1168,6 +1254,9 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Error decoding channel rule", err)
 	}
+	if resp := g.checkChannelRuleDatasources(c, cmd.Settings); resp != nil {
+		return resp
+	}
 	rule, err := g.pipelineStorage.CreateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
@@ -1191,6 +1280,9 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	if cmd.Pattern == "" {
 		return response.Error(http.StatusBadRequest, "Rule pattern required", nil)
 	}
+	if resp := g.checkChannelRuleDatasources(c, cmd.Settings); resp != nil {
+		return resp
+	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
@@ -2,6 +2,7 @@ package live
 
 import (
 	"context"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
@@ -15,10 +16,15 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 func TestMain(m *testing.M) {
@@ -37,12 +43,70 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil)
 
 	// Proceeds without live HA if redis is unavaialble
 	require.NoError(t, err)
 }
 
+func Test_provideLiveService_BrokerInputs(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	cfg.LiveInputs = []setting.LiveInputSettings{{
+		Name:          "factory",
+		Type:          setting.LiveInputTypeMQTT,
+		URL:           "tcp://localhost:1883",
+		Topics:        []string{"factory/#"},
+		OrgID:         1,
+		ChannelPrefix: "stream/factory",
+	}}
+
+	g, err := ProvideService(nil, cfg,
+		routing.NewRouteRegister(),
+		nil, nil, nil, nil,
+		db.InitTestDB(t),
+		nil,
+		&usagestats.UsageStatsMock{T: t},
+		nil,
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil)
+	require.NoError(t, err)
+
+	// Broker inputs publish into the pipeline, so it must be created with them
+	require.NotNil(t, g.Pipeline)
+	require.NotNil(t, g.brokerInputRunner)
+}
+
+func TestCheckChannelRuleDatasources(t *testing.T) {
+	g := &GrafanaLive{accessControl: acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())}
+	settings := pipeline.ChannelRuleSettings{
+		FrameOutputters: []*pipeline.FrameOutputterConfig{{
+			Type:            pipeline.FrameOutputTypeSQL,
+			SQLOutputConfig: &pipeline.SQLOutputConfig{DatasourceUID: "pg", Table: "metrics"},
+		}},
+	}
+	newContext := func(permissions map[string][]string) *contextmodel.ReqContext {
+		return &contextmodel.ReqContext{
+			Context:      &web.Context{Req: httptest.NewRequest(http.MethodPost, "/api/live/channel-rules", nil)},
+			SignedInUser: &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: permissions}},
+		}
+	}
+
+	resp := g.checkChannelRuleDatasources(newContext(map[string][]string{
+		datasources.ActionQuery: {"datasources:uid:pg"},
+	}), settings)
+	require.NotNil(t, resp)
+	require.Equal(t, http.StatusForbidden, resp.Status())
+
+	resp = g.checkChannelRuleDatasources(newContext(map[string][]string{
+		datasources.ActionQuery: {"datasources:uid:pg"},
+		datasources.ActionWrite: {"datasources:uid:pg"},
+	}), settings)
+	require.Nil(t, resp)
+
+	// Rules without SQL outputs do not require datasource permissions
+	require.Nil(t, g.checkChannelRuleDatasources(newContext(nil), pipeline.ChannelRuleSettings{}))
+}
+
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
 	doneCh := make(chan struct{})
 	f := func() {
@@ -0,0 +1,95 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// FrameBuffer keeps the recent frames of managed stream channels, so that new subscribers
+// can replay them before receiving live data.
+type FrameBuffer interface {
+	// Append adds a full JSON frame to the buffer of the channel, and drops the frames
+	// exceeding the buffer limits.
+	Append(ctx context.Context, orgID int64, channel string, t time.Time, frameJSON json.RawMessage) error
+	// Range returns the frames of the channel appended since the given time, the oldest first.
+	Range(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error)
+}
+
+// FrameBufferLimits bound the frames kept per channel. Frames are dropped once any of the limits is exceeded.
+type FrameBufferLimits struct {
+	MaxFrames int
+	MaxAge    time.Duration
+}
+
+// SubscribeOptions can be passed by clients as subscription data of a managed stream channel.
+type SubscribeOptions struct {
+	// Replay is how far back buffered frames are sent on subscribe, e.g. 5m. It is limited by the buffer max age.
+	Replay string `json:"replay,omitempty"`
+}
+
+// replayDuration returns the replay duration requested in the subscription data. Subscription data
+// that is not SubscribeOptions is ignored, since managed streams did not support any before.
+func replayDuration(raw json.RawMessage) (time.Duration, error) {
+	var opts SubscribeOptions
+	if len(raw) == 0 || json.Unmarshal(raw, &opts) != nil || opts.Replay == "" {
+		return 0, nil
+	}
+	replay, err := time.ParseDuration(opts.Replay)
+	if err != nil || replay < 0 {
+		return 0, fmt.Errorf("invalid replay duration: %s", opts.Replay)
+	}
+	return replay, nil
+}
+
+// mergeFrames merges buffered frames into a single frame with the schema of the latest frame.
+// Frames pushed before the last schema change are skipped, since their rows can't be merged.
+func mergeFrames(frames []json.RawMessage) (json.RawMessage, bool, error) {
+	if len(frames) == 0 {
+		return nil, false, nil
+	}
+	decoded := make([]*data.Frame, 0, len(frames))
+	for _, raw := range frames {
+		var f data.Frame
+		if err := json.Unmarshal(raw, &f); err != nil {
+			return nil, false, err
+		}
+		decoded = append(decoded, &f)
+	}
+
+	latest := decoded[len(decoded)-1]
+	first := len(decoded) - 1
+	for first > 0 && sameSchema(decoded[first-1], latest) {
+		first--
+	}
+	if first == len(decoded)-1 {
+		return frames[len(frames)-1], true, nil
+	}
+
+	merged := latest.EmptyCopy()
+	for _, f := range decoded[first:] {
+		for row := 0; row < f.Rows(); row++ {
+			merged.AppendRow(f.RowCopy(row)...)
+		}
+	}
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name || a.Fields[i].Type() != b.Fields[i].Type() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// bufferCleanupInterval is how often the buffers of channels without recent frames are removed.
+const bufferCleanupInterval = time.Minute
+
+// MemoryFrameBuffer keeps the recent frames of each channel in a ring buffer in memory.
+type MemoryFrameBuffer struct {
+	mu          sync.Mutex
+	limits      FrameBufferLimits
+	buffers     map[string]*frameRing
+	lastCleanup time.Time
+}
+
+type bufferedFrame struct {
+	time  time.Time
+	frame json.RawMessage
+}
+
+// frameRing is a fixed-size ring of frames, ordered by the time they were appended.
+type frameRing struct {
+	frames []bufferedFrame
+	// start is the index of the oldest frame.
+	start int
+	size  int
+}
+
+func NewMemoryFrameBuffer(limits FrameBufferLimits) *MemoryFrameBuffer {
+	return &MemoryFrameBuffer{
+		limits:  limits,
+		buffers: map[string]*frameRing{},
+	}
+}
+
+func (b *MemoryFrameBuffer) Append(_ context.Context, orgID int64, channel string, t time.Time, frameJSON json.RawMessage) error {
+	if b.limits.MaxFrames <= 0 {
+		return nil
+	}
+	key := orgchannel.PrependOrgID(orgID, channel)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ring, ok := b.buffers[key]
+	if !ok {
+		ring = &frameRing{frames: make([]bufferedFrame, b.limits.MaxFrames)}
+		b.buffers[key] = ring
+	}
+	ring.push(bufferedFrame{time: t, frame: frameJSON})
+	if b.limits.MaxAge > 0 {
+		ring.dropBefore(t.Add(-b.limits.MaxAge))
+		if t.Sub(b.lastCleanup) > bufferCleanupInterval {
+			b.cleanup(t.Add(-b.limits.MaxAge))
+			b.lastCleanup = t
+		}
+	}
+	return nil
+}
+
+// cleanup removes the buffers which frames are all older than the given time.
+func (b *MemoryFrameBuffer) cleanup(before time.Time) {
+	for key, ring := range b.buffers {
+		ring.dropBefore(before)
+		if ring.size == 0 {
+			delete(b.buffers, key)
+		}
+	}
+}
+
+func (b *MemoryFrameBuffer) Range(_ context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	key := orgchannel.PrependOrgID(orgID, channel)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ring, ok := b.buffers[key]
+	if !ok {
+		return nil, nil
+	}
+	if b.limits.MaxAge > 0 {
+		ring.dropBefore(time.Now().Add(-b.limits.MaxAge))
+		if ring.size == 0 {
+			delete(b.buffers, key)
+			return nil, nil
+		}
+	}
+	var result []json.RawMessage
+	for i := 0; i < ring.size; i++ {
+		f := ring.at(i)
+		if !f.time.Before(since) {
+			result = append(result, f.frame)
+		}
+	}
+	return result, nil
+}
+
+func (r *frameRing) push(f bufferedFrame) {
+	if r.size < len(r.frames) {
+		r.frames[(r.start+r.size)%len(r.frames)] = f
+		r.size++
+		return
+	}
+	// The ring is full, overwrite the oldest frame.
+	r.frames[r.start] = f
+	r.start = (r.start + 1) % len(r.frames)
+}
+
+func (r *frameRing) at(i int) bufferedFrame {
+	return r.frames[(r.start+i)%len(r.frames)]
+}
+
+func (r *frameRing) dropBefore(t time.Time) {
+	for r.size > 0 && r.at(0).time.Before(t) {
+		r.frames[r.start] = bufferedFrame{}
+		r.start = (r.start + 1) % len(r.frames)
+		r.size--
+	}
+}
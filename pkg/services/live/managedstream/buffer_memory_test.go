@@ -0,0 +1,50 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryFrameBuffer(t *testing.T) {
+	now := time.Now()
+	b := NewMemoryFrameBuffer(FrameBufferLimits{MaxFrames: 3, MaxAge: time.Minute})
+	for i := 0; i < 5; i++ {
+		err := b.Append(context.Background(), 1, "stream/test/cpu", now.Add(time.Duration(i)*time.Second), json.RawMessage(strconv.Itoa(i)))
+		require.NoError(t, err)
+	}
+
+	// Only the last frames fit in the buffer.
+	frames, err := b.Range(context.Background(), 1, "stream/test/cpu", now)
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage("2"), json.RawMessage("3"), json.RawMessage("4")}, frames)
+
+	frames, err = b.Range(context.Background(), 1, "stream/test/cpu", now.Add(4*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage("4")}, frames)
+
+	// Buffers are per org.
+	frames, err = b.Range(context.Background(), 2, "stream/test/cpu", now)
+	require.NoError(t, err)
+	require.Empty(t, frames)
+
+	// Frames older than the max age are dropped.
+	err = b.Append(context.Background(), 1, "stream/test/cpu", now.Add(2*time.Minute), json.RawMessage("5"))
+	require.NoError(t, err)
+	frames, err = b.Range(context.Background(), 1, "stream/test/cpu", now)
+	require.NoError(t, err)
+	require.Equal(t, []json.RawMessage{json.RawMessage("5")}, frames)
+}
+
+func TestMemoryFrameBuffer_Disabled(t *testing.T) {
+	b := NewMemoryFrameBuffer(FrameBufferLimits{})
+	err := b.Append(context.Background(), 1, "stream/test/cpu", time.Now(), json.RawMessage("1"))
+	require.NoError(t, err)
+	frames, err := b.Range(context.Background(), 1, "stream/test/cpu", time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, frames)
+}
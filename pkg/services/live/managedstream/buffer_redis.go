@@ -0,0 +1,83 @@
+package managedstream
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+// RedisFrameBuffer keeps the recent frames of each channel in a Redis sorted set scored by
+// the time they were appended, so that all Grafana instances share the same buffer.
+type RedisFrameBuffer struct {
+	redisClient *redis.Client
+	limits      FrameBufferLimits
+}
+
+func NewRedisFrameBuffer(redisClient *redis.Client, limits FrameBufferLimits) *RedisFrameBuffer {
+	return &RedisFrameBuffer{
+		redisClient: redisClient,
+		limits:      limits,
+	}
+}
+
+func (b *RedisFrameBuffer) Append(ctx context.Context, orgID int64, channel string, t time.Time, frameJSON json.RawMessage) error {
+	if b.limits.MaxFrames <= 0 {
+		return nil
+	}
+	key := getBufferKey(orgchannel.PrependOrgID(orgID, channel))
+
+	pipe := b.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	// Members of a sorted set are unique, the time prefix keeps identical frames apart.
+	pipe.ZAdd(ctx, key, &redis.Z{
+		Score:  float64(t.UnixMilli()),
+		Member: strconv.FormatInt(t.UnixNano(), 10) + ":" + string(frameJSON),
+	})
+	pipe.ZRemRangeByRank(ctx, key, 0, int64(-b.limits.MaxFrames-1))
+	ttl := frameCacheTTL
+	if b.limits.MaxAge > 0 {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(t.Add(-b.limits.MaxAge).UnixMilli(), 10))
+		ttl = b.limits.MaxAge
+	}
+	pipe.Expire(ctx, key, ttl)
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (b *RedisFrameBuffer) Range(ctx context.Context, orgID int64, channel string, since time.Time) ([]json.RawMessage, error) {
+	key := getBufferKey(orgchannel.PrependOrgID(orgID, channel))
+	from := since
+	if b.limits.MaxAge > 0 {
+		if oldest := time.Now().Add(-b.limits.MaxAge); oldest.After(from) {
+			from = oldest
+		}
+	}
+	members, err := b.redisClient.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]json.RawMessage, 0, len(members))
+	for _, m := range members {
+		_, frame, ok := strings.Cut(m, ":")
+		if !ok {
+			continue
+		}
+		result = append(result, json.RawMessage(frame))
+	}
+	return result, nil
+}
+
+func getBufferKey(channelID string) string {
+	return "gf_live.managed_stream_buffer." + channelID
+}
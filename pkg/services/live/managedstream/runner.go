@@ -42,20 +42,36 @@ type Runner struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameBuffer    FrameBuffer
 }
 
 type LocalPublisher interface {
 	PublishLocal(channel string, data []byte) error
 }
 
+// RunnerOption modifies Runner behavior.
+type RunnerOption func(*Runner)
+
+// WithFrameBuffer keeps the recent frames of channels in the buffer, so that subscribers
+// can replay them.
+func WithFrameBuffer(frameBuffer FrameBuffer) RunnerOption {
+	return func(r *Runner) {
+		r.frameBuffer = frameBuffer
+	}
+}
+
 // NewRunner creates new Runner.
-func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache) *Runner {
-	return &Runner{
+func NewRunner(publisher model.ChannelPublisher, localPublisher LocalPublisher, frameCache FrameCache, opts ...RunnerOption) *Runner {
+	r := &Runner{
 		publisher:      publisher,
 		localPublisher: localPublisher,
 		streams:        map[int64]map[string]*NamespaceStream{},
 		frameCache:     frameCache,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 func (r *Runner) GetManagedChannels(orgID int64) ([]*ManagedChannel, error) {
@@ -126,6 +142,7 @@ func (r *Runner) GetOrCreateStream(orgID int64, scope string, namespace string)
 	s, ok := r.streams[orgID][prefix]
 	if !ok {
 		s = NewNamespaceStream(orgID, scope, namespace, r.publisher, r.localPublisher, r.frameCache)
+		s.frameBuffer = r.frameBuffer
 		r.streams[orgID][prefix] = s
 	}
 	return s, nil
@@ -139,6 +156,7 @@ type NamespaceStream struct {
 	publisher      model.ChannelPublisher
 	localPublisher LocalPublisher
 	frameCache     FrameCache
+	frameBuffer    FrameBuffer
 	rateMu         sync.RWMutex
 	rates          map[string][60]rateEntry
 }
@@ -186,6 +204,13 @@ func (s *NamespaceStream) Push(ctx context.Context, path string, frame *data.Fra
 		return err
 	}
 
+	if s.frameBuffer != nil {
+		if err := s.frameBuffer.Append(ctx, s.orgID, channel, time.Now(), jsonFrameCache.Bytes(data.IncludeAll)); err != nil {
+			// Replay is best effort, live data is still sent.
+			logger.Error("Error buffering managed stream frame", "error", err, "channel", channel)
+		}
+	}
+
 	// When the schema has not changed, just send the data.
 	include := data.IncludeDataOnly
 	if isUpdated {
@@ -241,6 +266,20 @@ func (s *NamespaceStream) GetHandlerForPath(_ string) (model.ChannelHandler, err
 
 func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester, e model.SubscribeEvent) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	reply := model.SubscribeReply{}
+	replay, err := replayDuration(e.Data)
+	if err != nil {
+		return reply, 0, err
+	}
+	if replay > 0 && s.frameBuffer != nil {
+		frameJSON, ok, err := s.replayFrames(ctx, u.GetOrgID(), e.Channel, replay)
+		if err != nil {
+			return reply, 0, err
+		}
+		if ok {
+			reply.Data = frameJSON
+			return reply, backend.SubscribeStreamStatusOK, nil
+		}
+	}
 	frameJSON, ok, err := s.frameCache.GetFrame(ctx, u.GetOrgID(), e.Channel)
 	if err != nil {
 		return reply, 0, err
@@ -251,6 +290,15 @@ func (s *NamespaceStream) OnSubscribe(ctx context.Context, u identity.Requester,
 	return reply, backend.SubscribeStreamStatusOK, nil
 }
 
+// replayFrames returns a frame with the rows of the frames pushed to the channel during the replay duration.
+func (s *NamespaceStream) replayFrames(ctx context.Context, orgID int64, channel string, replay time.Duration) (json.RawMessage, bool, error) {
+	frames, err := s.frameBuffer.Range(ctx, orgID, channel, time.Now().Add(-replay))
+	if err != nil {
+		return nil, false, err
+	}
+	return mergeFrames(frames)
+}
+
 func (s *NamespaceStream) OnPublish(_ context.Context, _ identity.Requester, _ model.PublishEvent) (model.PublishReply, backend.PublishStreamStatus, error) {
 	return model.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
 }
@@ -2,11 +2,16 @@ package managedstream
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/model"
+	"github.com/grafana/grafana/pkg/services/user"
 )
 
 type testPublisher struct {
@@ -83,3 +88,50 @@ func TestGetManagedStreams(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, managedChannels, 7) // Not affected by other org.
 }
+
+func TestManagedStreamReplay(t *testing.T) {
+	publisher := &testPublisher{t: t}
+	runner := NewRunner(publisher.publish, nil, NewMemoryFrameCache(), WithFrameBuffer(NewMemoryFrameBuffer(FrameBufferLimits{
+		MaxFrames: 10,
+		MaxAge:    time.Minute,
+	})))
+	s, err := runner.GetOrCreateStream(1, "stream", "test")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		frame := data.NewFrame("cpu",
+			data.NewField("time", nil, []time.Time{time.Unix(int64(i), 0)}),
+			data.NewField("value", nil, []float64{float64(i)}),
+		)
+		require.NoError(t, s.Push(context.Background(), "cpu", frame))
+	}
+	u := &user.SignedInUser{OrgID: 1}
+
+	// Without replay, only the last frame is sent.
+	reply, status, err := s.OnSubscribe(context.Background(), u, model.SubscribeEvent{Channel: "stream/test/cpu", Path: "cpu"})
+	require.NoError(t, err)
+	require.Equal(t, backend.SubscribeStreamStatusOK, status)
+	var f data.Frame
+	require.NoError(t, json.Unmarshal(reply.Data, &f))
+	require.Equal(t, 1, f.Rows())
+
+	// With replay, the rows of the buffered frames are merged.
+	reply, _, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Path:    "cpu",
+		Data:    json.RawMessage(`{"replay":"5m"}`),
+	})
+	require.NoError(t, err)
+	f = data.Frame{}
+	require.NoError(t, json.Unmarshal(reply.Data, &f))
+	require.Equal(t, 3, f.Rows())
+	require.Equal(t, 0.0, f.Fields[1].At(0))
+	require.Equal(t, 2.0, f.Fields[1].At(2))
+
+	_, _, err = s.OnSubscribe(context.Background(), u, model.SubscribeEvent{
+		Channel: "stream/test/cpu",
+		Path:    "cpu",
+		Data:    json.RawMessage(`{"replay":"soon"}`),
+	})
+	require.Error(t, err)
+}
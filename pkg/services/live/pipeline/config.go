@@ -40,6 +40,8 @@ type ConverterConfig struct {
 	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
 	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
 	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	CSVConverterConfig        *CSVConverterConfig        `json:"csv,omitempty"`
+	PrometheusConverterConfig *PrometheusConverterConfig `json:"prometheus,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -51,10 +53,16 @@ type KeepFieldsFrameProcessorConfig struct {
 }
 
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                         string                             `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig    *DropFieldsFrameProcessorConfig    `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig    *KeepFieldsFrameProcessorConfig    `json:"keepFields,omitempty"`
+	MultipleProcessorConfig      *MultipleFrameProcessorConfig      `json:"multiple,omitempty"`
+	AggregateProcessorConfig     *AggregateFrameProcessorConfig     `json:"aggregate,omitempty"`
+	RateProcessorConfig          *RateFrameProcessorConfig          `json:"rate,omitempty"`
+	RenameFieldsProcessorConfig  *RenameFieldsFrameProcessorConfig  `json:"renameFields,omitempty"`
+	ConvertFieldsProcessorConfig *ConvertFieldsFrameProcessorConfig `json:"convertFields,omitempty"`
+	ExtractLabelsProcessorConfig *ExtractLabelsFrameProcessorConfig `json:"extractLabels,omitempty"`
+	ComputeFieldsProcessorConfig *ComputeFieldsFrameProcessorConfig `json:"computeFields,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -79,6 +87,30 @@ type LokiOutputConfig struct {
 	UID string `json:"uid"`
 }
 
+type SQLOutputConfig struct {
+	// DatasourceUID is the uid of a PostgreSQL or MySQL datasource.
+	DatasourceUID string `json:"datasourceUid"`
+	// Table to insert rows into. It is created from frame fields if it does not exist.
+	Table string `json:"table"`
+	// BatchSize is the maximum number of rows inserted with one statement, 1000 by default.
+	BatchSize int `json:"batchSize,omitempty"`
+	// FlushMilliseconds is how often buffered rows are inserted, 1000 by default.
+	FlushMilliseconds int64 `json:"flushMilliseconds,omitempty"`
+}
+
+type FileOutputConfig struct {
+	// Name of the output files, which are written into the live output directory of Grafana data path.
+	Name string `json:"name"`
+	// Format of the files, csv (default) or parquet.
+	Format string `json:"format,omitempty"`
+	// MaxSizeBytes is the size after which a new file is started, 100MB by default.
+	MaxSizeBytes int64 `json:"maxSizeBytes,omitempty"`
+	// RotateSeconds is the age after which a new file is started, 1 hour by default.
+	RotateSeconds int64 `json:"rotateSeconds,omitempty"`
+	// MaxFiles is the number of files to keep, all files are kept if not set.
+	MaxFiles int `json:"maxFiles,omitempty"`
+}
+
 type MultipleSubscriberConfig struct {
 	Subscribers []SubscriberConfig `json:"subscribers"`
 }
@@ -109,6 +141,8 @@ type FrameOutputterConfig struct {
 	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
 	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
 	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	SQLOutputConfig         *SQLOutputConfig           `json:"sql,omitempty"`
+	FileOutputConfig        *FileOutputConfig          `json:"file,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
@@ -152,4 +186,20 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type CSVConverterConfig struct {
+	// Columns are the names of the columns. If empty, the first line of the input is the header.
+	Columns []string `json:"columns,omitempty"`
+	// Delimiter is a single character that separates values, a comma by default.
+	Delimiter string `json:"delimiter,omitempty"`
+	// TimeColumn is the column with RFC3339 times or Unix timestamps in milliseconds.
+	// If empty, the time of conversion is used.
+	TimeColumn string `json:"timeColumn,omitempty"`
+}
+
+type PrometheusConverterConfig struct {
+	// SingleChannel keeps all metric families in the current channel instead of
+	// sending each of them to channel + / + <metric_name>.
+	SingleChannel bool `json:"singleChannel,omitempty"`
+}
+
 type ManagedStreamOutputConfig struct{}
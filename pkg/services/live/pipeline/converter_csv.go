@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// CSVConverter decodes CSV lines to a single data.Frame with one row per line. Columns
+// which values are all numbers become number fields, other columns become string fields.
+type CSVConverter struct {
+	config      CSVConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+func NewCSVConverter(c CSVConverterConfig) *CSVConverter {
+	return &CSVConverter{config: c}
+}
+
+const ConverterTypeCSV = "csv"
+
+func (c *CSVConverter) Type() string {
+	return ConverterTypeCSV
+}
+
+func (c *CSVConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+	if c.config.Delimiter != "" {
+		r, size := utf8.DecodeRuneInString(c.config.Delimiter)
+		if size != len(c.config.Delimiter) {
+			return nil, fmt.Errorf("delimiter must be a single character: %q", c.config.Delimiter)
+		}
+		reader.Comma = r
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	columns := c.config.Columns
+	if len(columns) == 0 {
+		if len(records) == 0 {
+			return nil, errors.New("no CSV header")
+		}
+		columns = records[0]
+		records = records[1:]
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	timeIdx := -1
+	if c.config.TimeColumn != "" {
+		for i, name := range columns {
+			if name == c.config.TimeColumn {
+				timeIdx = i
+				break
+			}
+		}
+		if timeIdx < 0 {
+			return nil, fmt.Errorf("time column %s not found", c.config.TimeColumn)
+		}
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeTime, len(records))
+	timeField.Name = "time"
+	if timeIdx >= 0 {
+		timeField.Name = c.config.TimeColumn
+	}
+	now := nowTimeFunc()
+	for row, record := range records {
+		if timeIdx < 0 {
+			timeField.Set(row, now)
+			continue
+		}
+		t, err := parseCSVTime(csvValue(record, timeIdx))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", row+1, err)
+		}
+		timeField.Set(row, t)
+	}
+
+	frame := data.NewFrame(vars.Path, timeField)
+	for i, name := range columns {
+		if i == timeIdx {
+			continue
+		}
+		frame.Fields = append(frame.Fields, csvColumnToField(name, records, i))
+	}
+	return []*ChannelFrame{
+		{Channel: "", Frame: frame},
+	}, nil
+}
+
+func csvValue(record []string, idx int) string {
+	if idx >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[idx])
+}
+
+// csvColumnToField returns a nullable number field if all non-empty values of the column are
+// numbers, and a nullable string field otherwise. Empty values are null.
+func csvColumnToField(name string, records [][]string, idx int) *data.Field {
+	numbers := make([]*float64, len(records))
+	numeric := true
+	for row, record := range records {
+		v := csvValue(record, idx)
+		if v == "" {
+			continue
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			numeric = false
+			break
+		}
+		numbers[row] = &f
+	}
+	if numeric {
+		return data.NewField(name, nil, numbers)
+	}
+	values := make([]*string, len(records))
+	for row, record := range records {
+		if v := csvValue(record, idx); v != "" {
+			values[row] = &v
+		}
+	}
+	return data.NewField(name, nil, values)
+}
+
+// parseCSVTime parses RFC3339 times and Unix timestamps in milliseconds.
+func parseCSVTime(v string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC3339 or Unix milliseconds", v)
+	}
+	return t, nil
+}
@@ -0,0 +1,57 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVConverter_Header(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	converter := NewCSVConverter(CSVConverterConfig{})
+	converter.nowTimeFunc = func() time.Time {
+		return now
+	}
+
+	body := []byte("device,temperature,humidity\nsensor1,21.5,40\nsensor2,19,\n")
+	channelFrames, err := converter.Convert(context.Background(), Vars{Path: "test"}, body)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Equal(t, "", channelFrames[0].Channel)
+
+	frame := channelFrames[0].Frame
+	require.Equal(t, "test", frame.Name)
+	require.Equal(t, 2, frame.Rows())
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, now, frame.Fields[0].At(0))
+	require.Equal(t, "device", frame.Fields[1].Name)
+	require.Equal(t, "sensor2", *frame.Fields[1].At(1).(*string))
+	require.Equal(t, 21.5, *frame.Fields[2].At(0).(*float64))
+	require.Equal(t, 40.0, *frame.Fields[3].At(0).(*float64))
+	require.Nil(t, frame.Fields[3].At(1).(*float64))
+}
+
+func TestCSVConverter_TimeColumn(t *testing.T) {
+	converter := NewCSVConverter(CSVConverterConfig{
+		Columns:    []string{"ts", "value"},
+		Delimiter:  ";",
+		TimeColumn: "ts",
+	})
+
+	body := []byte("1609503132000;1\n2021-01-01T12:12:13Z;2\n")
+	channelFrames, err := converter.Convert(context.Background(), Vars{}, body)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+
+	frame := channelFrames[0].Frame
+	require.Len(t, frame.Fields, 2)
+	require.Equal(t, "ts", frame.Fields[0].Name)
+	require.True(t, time.UnixMilli(1609503132000).Equal(frame.Fields[0].At(0).(time.Time)))
+	require.True(t, time.Date(2021, 01, 01, 12, 12, 13, 0, time.UTC).Equal(frame.Fields[0].At(1).(time.Time)))
+	require.Equal(t, 2.0, *frame.Fields[1].At(1).(*float64))
+
+	_, err = converter.Convert(context.Background(), Vars{}, []byte("yesterday;1\n"))
+	require.Error(t, err)
+}
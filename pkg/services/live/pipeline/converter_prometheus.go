@@ -0,0 +1,130 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// PrometheusConverter decodes Prometheus text exposition format and transforms it to
+// one ChannelFrame per metric family, where Channel is constructed from original
+// channel + / + <metric_name>. Each frame has a single row, and each series of the
+// metric family becomes a field with the series labels. Histograms and summaries are
+// expanded to their _bucket (or quantile), _sum and _count series.
+type PrometheusConverter struct {
+	config      PrometheusConverterConfig
+	nowTimeFunc func() time.Time
+}
+
+func NewPrometheusConverter(c PrometheusConverterConfig) *PrometheusConverter {
+	return &PrometheusConverter{config: c}
+}
+
+const ConverterTypePrometheus = "prometheus"
+
+func (c *PrometheusConverter) Type() string {
+	return ConverterTypePrometheus
+}
+
+func (c *PrometheusConverter) Convert(_ context.Context, vars Vars, body []byte) ([]*ChannelFrame, error) {
+	nowTimeFunc := c.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Prometheus text format: %w", err)
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	now := nowTimeFunc()
+	channelFrames := make([]*ChannelFrame, 0, len(families))
+	for _, name := range names {
+		frame := prometheusFamilyToFrame(families[name], now)
+		if len(frame.Fields) == 1 {
+			continue
+		}
+		channel := vars.Channel + "/" + name
+		if c.config.SingleChannel {
+			channel = ""
+		}
+		channelFrames = append(channelFrames, &ChannelFrame{
+			Channel: channel,
+			Frame:   frame,
+		})
+	}
+	return channelFrames, nil
+}
+
+// prometheusFamilyToFrame converts the metric family to a frame with a single row. The time of
+// the row is the latest timestamp of the samples, or now if the samples have no timestamp.
+func prometheusFamilyToFrame(family *dto.MetricFamily, now time.Time) *data.Frame {
+	name := family.GetName()
+	timeField := data.NewField("time", nil, []time.Time{now})
+	frame := data.NewFrame(name, timeField)
+
+	var latest int64
+	addValue := func(fieldName string, labels data.Labels, value float64) {
+		frame.Fields = append(frame.Fields, data.NewField(fieldName, labels, []*float64{finiteOrNil(value)}))
+	}
+	for _, m := range family.GetMetric() {
+		if ts := m.GetTimestampMs(); ts > latest {
+			latest = ts
+		}
+		labels := make(data.Labels, len(m.GetLabel()))
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+
+		switch family.GetType() {
+		case dto.MetricType_COUNTER:
+			addValue(name, labels, m.GetCounter().GetValue())
+		case dto.MetricType_GAUGE:
+			addValue(name, labels, m.GetGauge().GetValue())
+		case dto.MetricType_UNTYPED:
+			addValue(name, labels, m.GetUntyped().GetValue())
+		case dto.MetricType_SUMMARY:
+			summary := m.GetSummary()
+			for _, q := range summary.GetQuantile() {
+				addValue(name, withLabel(labels, "quantile", formatFloat(q.GetQuantile())), q.GetValue())
+			}
+			addValue(name+"_sum", labels, summary.GetSampleSum())
+			addValue(name+"_count", labels, float64(summary.GetSampleCount()))
+		case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+			histogram := m.GetHistogram()
+			for _, b := range histogram.GetBucket() {
+				addValue(name+"_bucket", withLabel(labels, "le", formatFloat(b.GetUpperBound())), float64(b.GetCumulativeCount()))
+			}
+			addValue(name+"_sum", labels, histogram.GetSampleSum())
+			addValue(name+"_count", labels, float64(histogram.GetSampleCount()))
+		}
+	}
+	if latest > 0 {
+		timeField.Set(0, time.UnixMilli(latest))
+	}
+	return frame
+}
+
+func withLabel(labels data.Labels, name, value string) data.Labels {
+	result := labels.Copy()
+	result[name] = value
+	return result
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,53 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrometheusConverter(t *testing.T) {
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	converter := NewPrometheusConverter(PrometheusConverterConfig{})
+	converter.nowTimeFunc = func() time.Time {
+		return now
+	}
+
+	body := []byte(`# HELP machine_temperature Temperature of the machine.
+# TYPE machine_temperature gauge
+machine_temperature{line="1"} 21.5
+machine_temperature{line="2"} 19
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 3
+request_duration_seconds_bucket{le="+Inf"} 5
+request_duration_seconds_sum 1.2
+request_duration_seconds_count 5
+`)
+	channelFrames, err := converter.Convert(context.Background(), Vars{Channel: "stream/factory/metrics"}, body)
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 2)
+
+	require.Equal(t, "stream/factory/metrics/machine_temperature", channelFrames[0].Channel)
+	frame := channelFrames[0].Frame
+	require.Equal(t, 1, frame.Rows())
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, now, frame.Fields[0].At(0))
+	require.Equal(t, data.Labels{"line": "1"}, frame.Fields[1].Labels)
+	require.Equal(t, 21.5, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, data.Labels{"line": "2"}, frame.Fields[2].Labels)
+
+	require.Equal(t, "stream/factory/metrics/request_duration_seconds", channelFrames[1].Channel)
+	frame = channelFrames[1].Frame
+	require.Len(t, frame.Fields, 5)
+	require.Equal(t, "request_duration_seconds_bucket", frame.Fields[1].Name)
+	require.Equal(t, data.Labels{"le": "0.1"}, frame.Fields[1].Labels)
+	require.Equal(t, data.Labels{"le": "+Inf"}, frame.Fields[2].Labels)
+	require.Equal(t, "request_duration_seconds_count", frame.Fields[4].Name)
+	require.Equal(t, 5.0, *frame.Fields[4].At(0).(*float64))
+
+	_, err = converter.Convert(context.Background(), Vars{}, []byte("not a metric line"))
+	require.Error(t, err)
+}
@@ -0,0 +1,377 @@
+// Package expression implements a small arithmetic expression language used to compute fields of live frames.
+//
+// Expressions support numbers, variables, the operators + - * / % ^, parentheses and the functions
+// abs, ceil, floor, round, sqrt, log, exp, min, max and pow. Variables are referenced by name if the name
+// only contains letters, digits, underscores and dots, or with ${name} otherwise, e.g. ${cpu usage} * 100.
+package expression
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed expression.
+type Expression struct {
+	root      node
+	variables []string
+}
+
+// Parse parses an expression.
+func Parse(s string) (*Expression, error) {
+	p := &parser{input: s}
+	p.next()
+	root, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", p.tok, p.tok.pos)
+	}
+	e := &Expression{root: root}
+	seen := map[string]struct{}{}
+	collectVariables(root, seen, &e.variables)
+	return e, nil
+}
+
+// Variables returns the names of the variables referenced by the expression, in order of appearance.
+func (e *Expression) Variables() []string {
+	return e.variables
+}
+
+// Eval evaluates the expression. The lookup function returns the value of a variable, and false if the
+// variable has no value, in which case the evaluation fails.
+func (e *Expression) Eval(lookup func(name string) (float64, bool)) (float64, error) {
+	return e.root.eval(lookup)
+}
+
+type node interface {
+	eval(lookup func(name string) (float64, bool)) (float64, error)
+}
+
+type numberNode float64
+
+func (n numberNode) eval(_ func(string) (float64, bool)) (float64, error) {
+	return float64(n), nil
+}
+
+type variableNode string
+
+func (n variableNode) eval(lookup func(string) (float64, bool)) (float64, error) {
+	v, ok := lookup(string(n))
+	if !ok {
+		return 0, fmt.Errorf("no value for %s", string(n))
+	}
+	return v, nil
+}
+
+type unaryNode struct {
+	operand node
+}
+
+func (n unaryNode) eval(lookup func(string) (float64, bool)) (float64, error) {
+	v, err := n.operand.eval(lookup)
+	return -v, err
+}
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+func (n binaryNode) eval(lookup func(string) (float64, bool)) (float64, error) {
+	l, err := n.left.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := n.right.eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	switch n.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	case '/':
+		return l / r, nil
+	case '%':
+		return math.Mod(l, r), nil
+	case '^':
+		return math.Pow(l, r), nil
+	default:
+		return 0, fmt.Errorf("unknown operator %c", n.op)
+	}
+}
+
+type function struct {
+	args int
+	fn   func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs":   {args: 1, fn: func(a []float64) float64 { return math.Abs(a[0]) }},
+	"ceil":  {args: 1, fn: func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"floor": {args: 1, fn: func(a []float64) float64 { return math.Floor(a[0]) }},
+	"round": {args: 1, fn: func(a []float64) float64 { return math.Round(a[0]) }},
+	"sqrt":  {args: 1, fn: func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"log":   {args: 1, fn: func(a []float64) float64 { return math.Log(a[0]) }},
+	"exp":   {args: 1, fn: func(a []float64) float64 { return math.Exp(a[0]) }},
+	"min":   {args: 2, fn: func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {args: 2, fn: func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+	"pow":   {args: 2, fn: func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+}
+
+type callNode struct {
+	fn   function
+	args []node
+}
+
+func (n callNode) eval(lookup func(string) (float64, bool)) (float64, error) {
+	args := make([]float64, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(lookup)
+		if err != nil {
+			return 0, err
+		}
+		args[i] = v
+	}
+	return n.fn.fn(args), nil
+}
+
+func collectVariables(n node, seen map[string]struct{}, result *[]string) {
+	switch n := n.(type) {
+	case variableNode:
+		if _, ok := seen[string(n)]; !ok {
+			seen[string(n)] = struct{}{}
+			*result = append(*result, string(n))
+		}
+	case unaryNode:
+		collectVariables(n.operand, seen, result)
+	case binaryNode:
+		collectVariables(n.left, seen, result)
+		collectVariables(n.right, seen, result)
+	case callNode:
+		for _, arg := range n.args {
+			collectVariables(arg, seen, result)
+		}
+	}
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenIdent
+	tokenVariable
+	tokenOperator
+	tokenError
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+type parser struct {
+	input string
+	pos   int
+	tok   token
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// next reads the next token of the input.
+func (p *parser) next() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return
+	}
+	c := p.input[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.' && p.pos+1 < len(p.input) && p.input[p.pos+1] >= '0' && p.input[p.pos+1] <= '9':
+		for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		if p.pos < len(p.input) && (p.input[p.pos] == 'e' || p.input[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+				p.pos++
+			}
+		}
+		p.tok = token{kind: tokenNumber, value: p.input[start:p.pos], pos: start}
+	case c == '$' && p.pos+1 < len(p.input) && p.input[p.pos+1] == '{':
+		end := strings.IndexByte(p.input[p.pos+2:], '}')
+		if end < 0 {
+			p.tok = token{kind: tokenError, value: p.input[start:], pos: start}
+			p.pos = len(p.input)
+			return
+		}
+		p.tok = token{kind: tokenVariable, value: p.input[p.pos+2 : p.pos+2+end], pos: start}
+		p.pos += end + 3
+	case isIdentRune(rune(c)):
+		for p.pos < len(p.input) && isIdentRune(rune(p.input[p.pos])) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenIdent, value: p.input[start:p.pos], pos: start}
+	case strings.IndexByte("+-*/%^(),", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenOperator, value: string(c), pos: start}
+	default:
+		p.pos++
+		p.tok = token{kind: tokenError, value: string(c), pos: start}
+	}
+}
+
+func (p *parser) isOperator(ops string) bool {
+	return p.tok.kind == tokenOperator && strings.Contains(ops, p.tok.value)
+}
+
+// parseExpr parses additions and subtractions.
+func (p *parser) parseExpr() (node, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("+-") {
+		op := p.tok.value[0]
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseTerm parses multiplications, divisions and modulos.
+func (p *parser) parseTerm() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOperator("*/%") {
+		op := p.tok.value[0]
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("-") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryNode{operand: operand}, nil
+	}
+	if p.isOperator("+") {
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePower()
+}
+
+// parsePower parses exponentiations, which are right associative.
+func (p *parser) parsePower() (node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOperator("^") {
+		p.next()
+		exponent, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return binaryNode{op: '^', left: base, right: exponent}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == tokenNumber:
+		v, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", tok.value, tok.pos)
+		}
+		p.next()
+		return numberNode(v), nil
+	case tok.kind == tokenVariable:
+		p.next()
+		return variableNode(tok.value), nil
+	case tok.kind == tokenIdent:
+		p.next()
+		if !p.isOperator("(") {
+			return variableNode(tok.value), nil
+		}
+		fn, ok := functions[tok.value]
+		if !ok {
+			return nil, fmt.Errorf("unknown function %s at position %d", tok.value, tok.pos)
+		}
+		p.next()
+		var args []node
+		for !p.isOperator(")") {
+			if len(args) > 0 {
+				if !p.isOperator(",") {
+					return nil, fmt.Errorf("expected \",\" at position %d", p.tok.pos)
+				}
+				p.next()
+			}
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		p.next()
+		if len(args) != fn.args {
+			return nil, fmt.Errorf("function %s expects %d arguments, got %d", tok.value, fn.args, len(args))
+		}
+		return callNode{fn: fn, args: args}, nil
+	case tok.kind == tokenOperator && tok.value == "(":
+		p.next()
+		n, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isOperator(")") {
+			return nil, fmt.Errorf("expected \")\" at position %d", p.tok.pos)
+		}
+		p.next()
+		return n, nil
+	default:
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+}
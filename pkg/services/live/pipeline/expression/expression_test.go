@@ -0,0 +1,84 @@
+package expression
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpression(t *testing.T) {
+	vars := map[string]float64{
+		"a":           2,
+		"b":           3,
+		"cpu.usage":   0.5,
+		"memory used": 1024,
+	}
+	lookup := func(name string) (float64, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	testCases := []struct {
+		expression string
+		expected   float64
+		variables  []string
+	}{
+		{expression: "1 + 2 * 3", expected: 7},
+		{expression: "(1 + 2) * 3", expected: 9},
+		{expression: "-a + b", expected: 1, variables: []string{"a", "b"}},
+		{expression: "a ^ b ^ 2", expected: 512, variables: []string{"a", "b"}},
+		{expression: "-a ^ 2", expected: -4, variables: []string{"a"}},
+		{expression: "7 % b", expected: 1, variables: []string{"b"}},
+		{expression: "cpu.usage * 100", expected: 50, variables: []string{"cpu.usage"}},
+		{expression: "${memory used} / 1e3", expected: 1.024, variables: []string{"memory used"}},
+		{expression: "max(a, b) + min(a, b) + abs(-1) + round(0.6)", expected: 7, variables: []string{"a", "b"}},
+		{expression: "pow(a, 3) + sqrt(16) + floor(1.5) + ceil(1.5)", expected: 15, variables: []string{"a"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expression, func(t *testing.T) {
+			e, err := Parse(tc.expression)
+			require.NoError(t, err)
+			v, err := e.Eval(lookup)
+			require.NoError(t, err)
+			require.InDelta(t, tc.expected, v, 1e-9)
+			require.Equal(t, tc.variables, e.Variables())
+		})
+	}
+
+	t.Run("missing variable", func(t *testing.T) {
+		e, err := Parse("a + c")
+		require.NoError(t, err)
+		_, err = e.Eval(lookup)
+		require.ErrorContains(t, err, "no value for c")
+	})
+
+	t.Run("division by zero", func(t *testing.T) {
+		e, err := Parse("a / 0")
+		require.NoError(t, err)
+		v, err := e.Eval(lookup)
+		require.NoError(t, err)
+		require.True(t, math.IsInf(v, 1))
+	})
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"1 +",
+		"(1 + 2",
+		"1 + 2)",
+		"unknown(1)",
+		"max(1)",
+		"abs(1, 2)",
+		"${unclosed",
+		"a # b",
+		"2x",
+	} {
+		t.Run(expression, func(t *testing.T) {
+			_, err := Parse(expression)
+			require.Error(t, err)
+		})
+	}
+}
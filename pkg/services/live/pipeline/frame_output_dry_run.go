@@ -0,0 +1,25 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DryRunFrameOutput replaces outputs which write frames outside of Grafana Live,
+// like the SQL and file outputs, when rules are only tested.
+type DryRunFrameOutput struct {
+	outputType string
+}
+
+func NewDryRunFrameOutput(outputType string) *DryRunFrameOutput {
+	return &DryRunFrameOutput{outputType: outputType}
+}
+
+func (out *DryRunFrameOutput) Type() string {
+	return out.outputType
+}
+
+func (out *DryRunFrameOutput) OutputFrame(_ context.Context, _ Vars, _ *data.Frame) ([]*ChannelFrame, error) {
+	return nil, nil
+}
@@ -0,0 +1,343 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	FrameOutputTypeFile = "file"
+
+	FileOutputFormatCSV     = "csv"
+	FileOutputFormatParquet = "parquet"
+
+	fileOutputDefaultMaxSizeBytes  = 100 * 1024 * 1024
+	fileOutputDefaultRotateSeconds = 60 * 60
+)
+
+var fileOutputNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// FileWriterStorage keeps open output files across channel rule rebuilds. All files are
+// written into a directory of Grafana data path, since rules are editable by org admins.
+type FileWriterStorage struct {
+	dir string
+	now func() time.Time
+
+	mu      sync.Mutex
+	writers map[string]*fileWriter
+}
+
+func NewFileWriterStorage(dir string) *FileWriterStorage {
+	return &FileWriterStorage{
+		dir:     dir,
+		now:     time.Now,
+		writers: map[string]*fileWriter{},
+	}
+}
+
+func fileWriterKey(orgID int64, name, format string) string {
+	return strconv.FormatInt(orgID, 10) + "|" + name + "|" + format
+}
+
+func (s *FileWriterStorage) getWriter(orgID int64, name, format string) *fileWriter {
+	key := fileWriterKey(orgID, name, format)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.writers[key]
+	if !ok {
+		w = &fileWriter{
+			orgID:  orgID,
+			dir:    filepath.Join(s.dir, strconv.FormatInt(orgID, 10)),
+			name:   name,
+			format: format,
+			now:    s.now,
+		}
+		s.writers[key] = w
+	}
+	return w
+}
+
+// release closes the files of the org which are not written by the given outputs anymore,
+// it is called each time the channel rules of the org are rebuilt.
+func (s *FileWriterStorage) release(orgID int64, used []FileOutputConfig) {
+	keep := make(map[string]struct{}, len(used))
+	for _, config := range used {
+		format := config.Format
+		if format == "" {
+			format = FileOutputFormatCSV
+		}
+		keep[fileWriterKey(orgID, config.Name, format)] = struct{}{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, w := range s.writers {
+		if _, ok := keep[key]; ok || w.orgID != orgID {
+			continue
+		}
+		delete(s.writers, key)
+		w.close()
+	}
+}
+
+// Close finishes and closes all output files.
+func (s *FileWriterStorage) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, w := range s.writers {
+		delete(s.writers, key)
+		w.close()
+	}
+}
+
+// FileFrameOutput appends frame rows to CSV or Parquet files on disk. A new file is started
+// when the current one exceeds the size limit, is older than the rotation interval or the
+// frame schema changes. Parquet files are only readable once they are rotated, since their
+// footer is written when they are closed.
+type FileFrameOutput struct {
+	storage *FileWriterStorage
+	config  FileOutputConfig
+}
+
+func NewFileFrameOutput(storage *FileWriterStorage, config FileOutputConfig) (*FileFrameOutput, error) {
+	if storage == nil || storage.dir == "" {
+		return nil, errors.New("file output is not available")
+	}
+	if !fileOutputNameRe.MatchString(config.Name) {
+		return nil, fmt.Errorf("invalid file output name: %q", config.Name)
+	}
+	switch config.Format {
+	case "":
+		config.Format = FileOutputFormatCSV
+	case FileOutputFormatCSV, FileOutputFormatParquet:
+	default:
+		return nil, fmt.Errorf("unknown file output format: %s", config.Format)
+	}
+	return &FileFrameOutput{storage: storage, config: config}, nil
+}
+
+func (out *FileFrameOutput) Type() string {
+	return FrameOutputTypeFile
+}
+
+func (out *FileFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if frame == nil || len(frame.Fields) == 0 || frame.Rows() == 0 {
+		return nil, nil
+	}
+	return nil, out.storage.getWriter(vars.OrgID, out.config.Name, out.config.Format).write(frame, out.config)
+}
+
+// fileEncoder writes frame rows in the format of an output file.
+type fileEncoder interface {
+	write(frame *data.Frame) error
+	// close finishes the file without closing it.
+	close() error
+}
+
+// fileWriter appends rows to the current file of an output and rotates it.
+type fileWriter struct {
+	orgID  int64
+	dir    string
+	name   string
+	format string
+	now    func() time.Time
+
+	mu      sync.Mutex
+	file    *os.File
+	encoder fileEncoder
+	schema  []string
+	size    int64
+	created time.Time
+}
+
+// fileOutputColumns returns the column names of the frame, with the labels of the fields.
+func fileOutputColumns(frame *data.Frame) []string {
+	columns := make([]string, 0, len(frame.Fields))
+	for _, f := range frame.Fields {
+		name := f.Name
+		if len(f.Labels) > 0 {
+			name += " " + f.Labels.String()
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+func (w *fileWriter) write(frame *data.Frame, config FileOutputConfig) error {
+	// The types are part of the schema since they can't change within a Parquet file.
+	schema := fileOutputColumns(frame)
+	for _, f := range frame.Fields {
+		schema = append(schema, f.Type().String())
+	}
+
+	maxSize := config.MaxSizeBytes
+	if maxSize <= 0 {
+		maxSize = fileOutputDefaultMaxSizeBytes
+	}
+	rotateSeconds := config.RotateSeconds
+	if rotateSeconds <= 0 {
+		rotateSeconds = fileOutputDefaultRotateSeconds
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	if w.file == nil || w.size >= maxSize || now.Sub(w.created) >= time.Duration(rotateSeconds)*time.Second || !slices.Equal(w.schema, schema) {
+		if err := w.rotate(now, frame, schema, config.MaxFiles); err != nil {
+			return err
+		}
+	}
+
+	if err := w.encoder.write(frame); err != nil {
+		return err
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return err
+	}
+	w.size = info.Size()
+	return nil
+}
+
+func (w *fileWriter) rotate(now time.Time, frame *data.Frame, schema []string, maxFiles int) error {
+	w.closeFile()
+	if err := os.MkdirAll(w.dir, 0750); err != nil {
+		return err
+	}
+	path := filepath.Join(w.dir, w.name+"-"+now.UTC().Format("20060102T150405.000000000")+"."+w.format)
+	file, err := os.OpenFile(filepath.Clean(path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	w.file = file
+	w.schema = schema
+	w.size = 0
+	w.created = now
+	if w.format == FileOutputFormatParquet {
+		w.encoder, err = newParquetEncoder(file, frame)
+	} else {
+		w.encoder, err = newCSVEncoder(file, fileOutputColumns(frame))
+	}
+	if err != nil {
+		w.closeFile()
+		return err
+	}
+	if maxFiles > 0 {
+		w.removeOldFiles(maxFiles)
+	}
+	return nil
+}
+
+func (w *fileWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeFile()
+}
+
+// closeFile finishes and closes the current file, if any.
+func (w *fileWriter) closeFile() {
+	if w.file == nil {
+		return
+	}
+	if w.encoder != nil {
+		if err := w.encoder.close(); err != nil {
+			logger.Warn("Error finishing output file", "file", w.file.Name(), "error", err)
+		}
+	}
+	if err := w.file.Close(); err != nil {
+		logger.Warn("Error closing output file", "file", w.file.Name(), "error", err)
+	}
+	w.file, w.encoder, w.schema = nil, nil, nil
+}
+
+// removeOldFiles keeps only the last maxFiles files of the output.
+func (w *fileWriter) removeOldFiles(maxFiles int) {
+	matches, err := filepath.Glob(filepath.Join(w.dir, w.name+"-*."+w.format))
+	if err != nil {
+		return
+	}
+	// Skip files of other outputs which name starts with the same prefix.
+	files := make([]string, 0, len(matches))
+	for _, f := range matches {
+		if !strings.Contains(strings.TrimPrefix(filepath.Base(f), w.name+"-"), "-") {
+			files = append(files, f)
+		}
+	}
+	if len(files) <= maxFiles {
+		return
+	}
+	// File names end with the creation time, so they sort in creation order.
+	sort.Strings(files)
+	for _, f := range files[:len(files)-maxFiles] {
+		if err := os.Remove(f); err != nil {
+			logger.Warn("Error removing output file", "file", f, "error", err)
+		}
+	}
+}
+
+// csvEncoder writes the rows of frames as CSV, after a header with the column names.
+type csvEncoder struct {
+	csv    *csv.Writer
+	record []string
+}
+
+func newCSVEncoder(file *os.File, header []string) (*csvEncoder, error) {
+	e := &csvEncoder{csv: csv.NewWriter(file)}
+	if err := e.csv.Write(header); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvEncoder) write(frame *data.Frame) error {
+	if cap(e.record) < len(frame.Fields) {
+		e.record = make([]string, len(frame.Fields))
+	}
+	record := e.record[:len(frame.Fields)]
+	for i := 0; i < frame.Rows(); i++ {
+		for j, f := range frame.Fields {
+			record[j] = fileOutputValue(f, i)
+		}
+		if err := e.csv.Write(record); err != nil {
+			return err
+		}
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func (e *csvEncoder) close() error {
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+func fileOutputValue(f *data.Field, idx int) string {
+	v, ok := f.ConcreteAt(idx)
+	if !ok {
+		return ""
+	}
+	switch val := v.(type) {
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'f', -1, 32)
+	case string:
+		return val
+	default:
+		return strings.TrimSpace(fmt.Sprintf("%v", val))
+	}
+}
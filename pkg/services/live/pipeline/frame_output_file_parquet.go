@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/compress"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// parquetEncoder writes every frame as a row group of a Parquet file. Columns are nullable
+// and typed after the frame fields: times are stored as UTC timestamps, numbers as 64 bits
+// integers or floats, and other values as strings.
+type parquetEncoder struct {
+	schema *arrow.Schema
+	writer *pqarrow.FileWriter
+}
+
+func newParquetEncoder(w io.Writer, frame *data.Frame) (*parquetEncoder, error) {
+	columns := fileOutputColumns(frame)
+	fields := make([]arrow.Field, len(frame.Fields))
+	for i, f := range frame.Fields {
+		fields[i] = arrow.Field{Name: columns[i], Type: parquetColumnType(f.Type()), Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	// The writer closes its sink when it is closed, the file is closed by the file writer.
+	writer, err := pqarrow.NewFileWriter(schema, struct{ io.Writer }{w}, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return nil, err
+	}
+	return &parquetEncoder{schema: schema, writer: writer}, nil
+}
+
+func parquetColumnType(t data.FieldType) arrow.DataType {
+	switch t.NonNullableType() {
+	case data.FieldTypeTime:
+		return &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}
+	case data.FieldTypeFloat64, data.FieldTypeFloat32:
+		return arrow.PrimitiveTypes.Float64
+	case data.FieldTypeInt8, data.FieldTypeInt16, data.FieldTypeInt32, data.FieldTypeInt64:
+		return arrow.PrimitiveTypes.Int64
+	case data.FieldTypeUint8, data.FieldTypeUint16, data.FieldTypeUint32, data.FieldTypeUint64:
+		return arrow.PrimitiveTypes.Uint64
+	case data.FieldTypeBool:
+		return arrow.FixedWidthTypes.Boolean
+	default:
+		return arrow.BinaryTypes.String
+	}
+}
+
+func (e *parquetEncoder) write(frame *data.Frame) error {
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, e.schema)
+	defer builder.Release()
+
+	for j, f := range frame.Fields {
+		column := builder.Field(j)
+		for i := 0; i < frame.Rows(); i++ {
+			v, ok := f.ConcreteAt(i)
+			if !ok {
+				column.AppendNull()
+				continue
+			}
+			switch b := column.(type) {
+			case *array.TimestampBuilder:
+				b.Append(arrow.Timestamp(v.(time.Time).UnixNano()))
+			case *array.Float64Builder:
+				b.Append(parquetFloat(v))
+			case *array.Int64Builder:
+				b.Append(parquetInt(v))
+			case *array.Uint64Builder:
+				b.Append(parquetUint(v))
+			case *array.BooleanBuilder:
+				b.Append(v.(bool))
+			case *array.StringBuilder:
+				b.Append(fileOutputValue(f, i))
+			}
+		}
+	}
+
+	record := builder.NewRecord()
+	defer record.Release()
+	return e.writer.Write(record)
+}
+
+func (e *parquetEncoder) close() error {
+	return e.writer.Close()
+}
+
+func parquetFloat(v any) float64 {
+	switch n := v.(type) {
+	case float32:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func parquetInt(v any) int64 {
+	switch n := v.(type) {
+	case int8:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int32:
+		return int64(n)
+	case int64:
+		return n
+	}
+	return 0
+}
+
+func parquetUint(v any) uint64 {
+	switch n := v.(type) {
+	case uint8:
+		return uint64(n)
+	case uint16:
+		return uint64(n)
+	case uint32:
+		return uint64(n)
+	case uint64:
+		return n
+	}
+	return 0
+}
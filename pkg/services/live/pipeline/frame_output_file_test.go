@@ -0,0 +1,148 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestFileFrameOutput_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	storage := NewFileWriterStorage(dir)
+	storage.now = func() time.Time {
+		return now
+	}
+	out, err := NewFileFrameOutput(storage, FileOutputConfig{Name: "cpu", RotateSeconds: 60, MaxFiles: 2})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{now}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{nil}),
+	)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+	frame.Fields[1].Set(0, util.Pointer(1.5))
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "1", "cpu-*.csv"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Equal(t, "time,value host=a\n2021-01-01T12:12:12Z,\n2021-01-01T12:12:12Z,1.5\n", string(content))
+
+	// Rotate by time and by schema change, only the last 2 files are kept.
+	now = now.Add(time.Minute)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+	now = now.Add(time.Second)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, data.NewFrame("test", data.NewField("other", nil, []string{"x"})))
+	require.NoError(t, err)
+
+	files, err = filepath.Glob(filepath.Join(dir, "1", "cpu-*.csv"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	content, err = os.ReadFile(files[1])
+	require.NoError(t, err)
+	require.Equal(t, "other\nx\n", string(content))
+}
+
+func TestNewFileFrameOutput_InvalidConfig(t *testing.T) {
+	storage := NewFileWriterStorage(t.TempDir())
+	_, err := NewFileFrameOutput(storage, FileOutputConfig{Name: "../etc/passwd"})
+	require.Error(t, err)
+	_, err = NewFileFrameOutput(storage, FileOutputConfig{Name: "cpu", Format: "xml"})
+	require.Error(t, err)
+}
+
+func TestFileFrameOutput_Parquet(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2021, 01, 01, 12, 12, 12, 0, time.UTC)
+	storage := NewFileWriterStorage(dir)
+	storage.now = func() time.Time {
+		return now
+	}
+	out, err := NewFileFrameOutput(storage, FileOutputConfig{Name: "cpu", Format: FileOutputFormatParquet, RotateSeconds: 60})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{now, now.Add(time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{nil, util.Pointer(1.5)}),
+		data.NewField("count", nil, []int32{1, 2}),
+		data.NewField("status", nil, []string{"ok", "error"}),
+	)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+	// Rotating closes the first file, which is only readable once its footer is written.
+	now = now.Add(time.Minute)
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "1", "cpu-*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+	table, err := pqarrow.ReadTable(context.Background(), f, parquet.NewReaderProperties(memory.DefaultAllocator), pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	defer table.Release()
+
+	require.Equal(t, int64(4), table.NumRows())
+	schema := table.Schema()
+	require.Equal(t, "value host=a", schema.Field(1).Name)
+	require.Equal(t, arrow.TIMESTAMP, schema.Field(0).Type.ID())
+	require.Equal(t, arrow.FLOAT64, schema.Field(1).Type.ID())
+	require.Equal(t, arrow.INT64, schema.Field(2).Type.ID())
+	require.Equal(t, arrow.STRING, schema.Field(3).Type.ID())
+
+	values := table.Column(1).Data().Chunk(0).(*array.Float64)
+	require.True(t, values.IsNull(0))
+	require.Equal(t, 1.5, values.Value(1))
+}
+
+func TestFileWriterStorage_Release(t *testing.T) {
+	dir := t.TempDir()
+	storage := NewFileWriterStorage(dir)
+	out, err := NewFileFrameOutput(storage, FileOutputConfig{Name: "cpu", Format: FileOutputFormatParquet})
+	require.NoError(t, err)
+	frame := data.NewFrame("test", data.NewField("value", nil, []float64{1, 2}))
+	_, err = out.OutputFrame(context.Background(), Vars{OrgID: 1}, frame)
+	require.NoError(t, err)
+
+	// The output is still used by a rule of the org, the file stays open.
+	storage.release(1, []FileOutputConfig{{Name: "cpu", Format: FileOutputFormatParquet}})
+	require.Len(t, storage.writers, 1)
+
+	storage.release(1, nil)
+	require.Empty(t, storage.writers)
+
+	// The released file is finished, so it is readable.
+	files, err := filepath.Glob(filepath.Join(dir, "1", "cpu-*.parquet"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	f, err := os.Open(files[0])
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+	table, err := pqarrow.ReadTable(context.Background(), f, parquet.NewReaderProperties(memory.DefaultAllocator), pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	require.NoError(t, err)
+	defer table.Release()
+	require.Equal(t, int64(2), table.NumRows())
+}
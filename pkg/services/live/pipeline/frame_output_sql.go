@@ -0,0 +1,626 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	_ "github.com/lib/pq"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+const (
+	FrameOutputTypeSQL = "sql"
+
+	sqlDefaultBatchSize         = 1000
+	sqlDefaultFlushMilliseconds = 1000
+	// sqlMaxBufferedRows is how many rows are kept while the database is unavailable,
+	// the oldest rows are dropped above it.
+	sqlMaxBufferedRows = 100000
+	// sqlMaxQueryParams is the maximum number of bind parameters in one statement,
+	// this is the PostgreSQL limit which is lower than the MySQL one.
+	sqlMaxQueryParams = 65535
+	sqlMaxColumnName  = 63
+	// sqlCloseFlushTimeout limits the last flush of a writer which is no longer used.
+	sqlCloseFlushTimeout = 5 * time.Second
+)
+
+var (
+	sqlTableNameRe     = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	sqlColumnInvalidRe = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// SQLWriterStorage keeps SQL writers across channel rule rebuilds, so that database
+// connections and buffered rows are not lost each time rules are rebuilt.
+type SQLWriterStorage struct {
+	dataSourceService datasources.DataSourceService
+
+	mu      sync.Mutex
+	writers map[string]*sqlWriter
+}
+
+func NewSQLWriterStorage(dataSourceService datasources.DataSourceService) *SQLWriterStorage {
+	return &SQLWriterStorage{
+		dataSourceService: dataSourceService,
+		writers:           map[string]*sqlWriter{},
+	}
+}
+
+func sqlWriterKey(orgID int64, config SQLOutputConfig) string {
+	return strconv.FormatInt(orgID, 10) + "|" + config.DatasourceUID + "|" + config.Table
+}
+
+func (s *SQLWriterStorage) getWriter(orgID int64, config SQLOutputConfig) *sqlWriter {
+	key := sqlWriterKey(orgID, config)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w, ok := s.writers[key]
+	if !ok {
+		w = newSQLWriter(orgID, config.DatasourceUID, config.Table, s.openDB)
+		s.writers[key] = w
+		go w.flushPeriodically()
+	}
+	w.setLimits(config.BatchSize, config.FlushMilliseconds)
+	return w
+}
+
+// release closes the writers of the org which are not used by the given outputs anymore,
+// it is called each time the channel rules of the org are rebuilt.
+func (s *SQLWriterStorage) release(orgID int64, used []SQLOutputConfig) {
+	keep := make(map[string]struct{}, len(used))
+	for _, config := range used {
+		keep[sqlWriterKey(orgID, config)] = struct{}{}
+	}
+	s.mu.Lock()
+	var closing []*sqlWriter
+	for key, w := range s.writers {
+		if _, ok := keep[key]; ok || w.orgID != orgID {
+			continue
+		}
+		delete(s.writers, key)
+		closing = append(closing, w)
+	}
+	s.mu.Unlock()
+	for _, w := range closing {
+		w.close()
+	}
+}
+
+// Close flushes the buffered rows of all writers and closes their connections.
+func (s *SQLWriterStorage) Close() {
+	s.mu.Lock()
+	writers := s.writers
+	s.writers = map[string]*sqlWriter{}
+	s.mu.Unlock()
+	for _, w := range writers {
+		w.close()
+	}
+}
+
+func (s *SQLWriterStorage) openDB(ctx context.Context, orgID int64, uid string) (*sql.DB, *sqlDialect, error) {
+	if s.dataSourceService == nil {
+		return nil, nil, errors.New("datasource service is not available")
+	}
+	ds, err := s.dataSourceService.GetDataSource(ctx, &datasources.GetDataSourceQuery{UID: uid, OrgID: orgID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting datasource %s: %w", uid, err)
+	}
+	password, err := s.dataSourceService.DecryptedPassword(ctx, ds)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decrypting datasource password: %w", err)
+	}
+	dialect, dsn, err := sqlDataSourceDSN(ds, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	db, err := sql.Open(dialect.driverName, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	db.SetMaxOpenConns(2)
+	return db, dialect, nil
+}
+
+// SQLOutputDatasourceUIDs returns the uids of the datasources that SQL outputs of the rule
+// insert rows into, so that rules can only be saved by users allowed to write into them.
+func SQLOutputDatasourceUIDs(settings ChannelRuleSettings) []string {
+	var uids []string
+	walkFrameOutputterConfigs(settings, func(config *FrameOutputterConfig) {
+		if config.SQLOutputConfig != nil && !slices.Contains(uids, config.SQLOutputConfig.DatasourceUID) {
+			uids = append(uids, config.SQLOutputConfig.DatasourceUID)
+		}
+	})
+	return uids
+}
+
+// walkFrameOutputterConfigs calls fn for each frame outputter of the rule, including the
+// ones nested in multiple and conditional outputters.
+func walkFrameOutputterConfigs(settings ChannelRuleSettings, fn func(config *FrameOutputterConfig)) {
+	var walk func(config *FrameOutputterConfig)
+	walk = func(config *FrameOutputterConfig) {
+		if config == nil {
+			return
+		}
+		fn(config)
+		if config.MultipleOutputterConfig != nil {
+			for i := range config.MultipleOutputterConfig.Outputters {
+				walk(&config.MultipleOutputterConfig.Outputters[i])
+			}
+		}
+		if config.ConditionalOutputConfig != nil {
+			walk(config.ConditionalOutputConfig.Outputter)
+		}
+	}
+	for _, config := range settings.FrameOutputters {
+		walk(config)
+	}
+}
+
+// SQLFrameOutput inserts frame rows into a table of a PostgreSQL or MySQL datasource.
+// Rows are batched and inserted periodically. The table and its columns are created
+// from frame fields when they do not exist yet.
+type SQLFrameOutput struct {
+	storage *SQLWriterStorage
+	config  SQLOutputConfig
+}
+
+func NewSQLFrameOutput(storage *SQLWriterStorage, config SQLOutputConfig) (*SQLFrameOutput, error) {
+	if config.DatasourceUID == "" {
+		return nil, errors.New("sql output requires a datasource uid")
+	}
+	if !sqlTableNameRe.MatchString(config.Table) {
+		return nil, fmt.Errorf("invalid table name: %q", config.Table)
+	}
+	return &SQLFrameOutput{storage: storage, config: config}, nil
+}
+
+func (out *SQLFrameOutput) Type() string {
+	return FrameOutputTypeSQL
+}
+
+func (out *SQLFrameOutput) OutputFrame(_ context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	rows := frameToSQLRows(frame)
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	out.storage.getWriter(vars.OrgID, out.config).append(rows)
+	return nil, nil
+}
+
+type sqlColumn struct {
+	name      string
+	fieldType data.FieldType
+}
+
+type sqlRow struct {
+	columns []sqlColumn
+	values  []any
+}
+
+// frameToSQLRows converts each frame row into a table row. Column names are derived
+// from field names and labels.
+func frameToSQLRows(frame *data.Frame) []sqlRow {
+	if frame == nil || len(frame.Fields) == 0 {
+		return nil
+	}
+	columns := make([]sqlColumn, 0, len(frame.Fields))
+	seen := map[string]struct{}{}
+	for i, f := range frame.Fields {
+		name := sqlColumnName(f, i)
+		if _, ok := seen[name]; ok {
+			name = name + "_" + strconv.Itoa(i)
+		}
+		seen[name] = struct{}{}
+		columns = append(columns, sqlColumn{name: name, fieldType: f.Type()})
+	}
+	rows := make([]sqlRow, 0, frame.Rows())
+	for i := 0; i < frame.Rows(); i++ {
+		values := make([]any, 0, len(frame.Fields))
+		for _, f := range frame.Fields {
+			values = append(values, sqlValue(f, i))
+		}
+		rows = append(rows, sqlRow{columns: columns, values: values})
+	}
+	return rows
+}
+
+func sqlColumnName(f *data.Field, idx int) string {
+	name := f.Name
+	if len(f.Labels) > 0 {
+		name += "_" + f.Labels.String()
+	}
+	name = strings.Trim(sqlColumnInvalidRe.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if len(name) > sqlMaxColumnName {
+		name = name[:sqlMaxColumnName]
+	}
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "field_" + strconv.Itoa(idx) + name
+	}
+	return name
+}
+
+func sqlValue(f *data.Field, idx int) any {
+	v, ok := f.ConcreteAt(idx)
+	if !ok {
+		return nil
+	}
+	ft := f.Type()
+	switch {
+	case ft.Time():
+		return v.(time.Time).UTC()
+	case ft.Numeric():
+		fv, err := f.FloatAt(idx)
+		if err != nil {
+			return nil
+		}
+		return fv
+	case ft == data.FieldTypeBool || ft == data.FieldTypeNullableBool:
+		return v
+	case ft == data.FieldTypeString || ft == data.FieldTypeNullableString:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+type openSQLDBFunc func(ctx context.Context, orgID int64, uid string) (*sql.DB, *sqlDialect, error)
+
+// sqlWriter batches rows of a single table.
+type sqlWriter struct {
+	orgID  int64
+	uid    string
+	table  string
+	openDB openSQLDBFunc
+
+	mu            sync.Mutex
+	buffer        []sqlRow
+	batchSize     int
+	flushInterval time.Duration
+	flushCh       chan struct{}
+	done          chan struct{}
+	stopped       chan struct{}
+
+	// Accessed only by the flushing goroutine.
+	db      *sql.DB
+	dialect *sqlDialect
+	columns map[string]struct{}
+}
+
+func newSQLWriter(orgID int64, uid string, table string, openDB openSQLDBFunc) *sqlWriter {
+	return &sqlWriter{
+		orgID:         orgID,
+		uid:           uid,
+		table:         table,
+		openDB:        openDB,
+		batchSize:     sqlDefaultBatchSize,
+		flushInterval: sqlDefaultFlushMilliseconds * time.Millisecond,
+		flushCh:       make(chan struct{}, 1),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+}
+
+func (w *sqlWriter) setLimits(batchSize int, flushMilliseconds int64) {
+	if batchSize <= 0 {
+		batchSize = sqlDefaultBatchSize
+	}
+	if flushMilliseconds <= 0 {
+		flushMilliseconds = sqlDefaultFlushMilliseconds
+	}
+	w.mu.Lock()
+	w.batchSize = batchSize
+	w.flushInterval = time.Duration(flushMilliseconds) * time.Millisecond
+	w.mu.Unlock()
+}
+
+func (w *sqlWriter) append(rows []sqlRow) {
+	w.mu.Lock()
+	w.buffer = append(w.buffer, rows...)
+	if len(w.buffer) > sqlMaxBufferedRows {
+		logger.Warn("Dropping rows of SQL output", "table", w.table, "dropped", len(w.buffer)-sqlMaxBufferedRows)
+		w.buffer = w.buffer[len(w.buffer)-sqlMaxBufferedRows:]
+	}
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *sqlWriter) flushPeriodically() {
+	defer close(w.stopped)
+	for {
+		w.mu.Lock()
+		interval := w.flushInterval
+		w.mu.Unlock()
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-w.flushCh:
+			timer.Stop()
+		case <-w.done:
+			timer.Stop()
+			w.closeDB()
+			return
+		}
+		if err := w.flushBuffer(context.Background()); err != nil {
+			logger.Error("Error flush to SQL datasource", "table", w.table, "error", err)
+		}
+	}
+}
+
+// close stops the flushing goroutine after a last flush of the buffered rows.
+func (w *sqlWriter) close() {
+	close(w.done)
+	<-w.stopped
+}
+
+func (w *sqlWriter) closeDB() {
+	ctx, cancel := context.WithTimeout(context.Background(), sqlCloseFlushTimeout)
+	defer cancel()
+	if err := w.flushBuffer(ctx); err != nil {
+		logger.Error("Error flush to SQL datasource", "table", w.table, "error", err)
+	}
+	if w.db != nil {
+		_ = w.db.Close()
+		w.db = nil
+	}
+}
+
+func (w *sqlWriter) flushBuffer(ctx context.Context) error {
+	w.mu.Lock()
+	rows := w.buffer
+	w.buffer = nil
+	batchSize := w.batchSize
+	w.mu.Unlock()
+	if len(rows) == 0 {
+		return nil
+	}
+
+	inserted, err := w.flush(ctx, rows, batchSize)
+	if err != nil {
+		// Each insert statement is atomic, only the rows after the last inserted batch
+		// are kept for the next flush, so that rows are not inserted twice.
+		w.mu.Lock()
+		w.buffer = append(rows[inserted:], w.buffer...)
+		w.mu.Unlock()
+		// Datasource settings may have changed, reconnect on next flush.
+		if w.db != nil {
+			_ = w.db.Close()
+		}
+		w.db = nil
+		w.columns = nil
+	}
+	return err
+}
+
+// flush inserts the rows and returns how many of them were inserted.
+func (w *sqlWriter) flush(ctx context.Context, rows []sqlRow, batchSize int) (int, error) {
+	if w.db == nil {
+		db, dialect, err := w.openDB(ctx, w.orgID, w.uid)
+		if err != nil {
+			return 0, err
+		}
+		w.db, w.dialect = db, dialect
+	}
+	for start := 0; start < len(rows); {
+		// Consecutive rows from frames with the same schema are inserted together.
+		end := start + 1
+		for end < len(rows) && slices.Equal(rows[start].columns, rows[end].columns) {
+			end++
+		}
+		if err := w.ensureColumns(ctx, rows[start].columns); err != nil {
+			return start, err
+		}
+		inserted, err := w.insert(ctx, rows[start:end], batchSize)
+		if err != nil {
+			return start + inserted, err
+		}
+		start = end
+	}
+	return len(rows), nil
+}
+
+// ensureColumns creates the table or adds the columns missing in it.
+func (w *sqlWriter) ensureColumns(ctx context.Context, columns []sqlColumn) error {
+	d := w.dialect
+	if w.columns == nil {
+		defs := make([]string, 0, len(columns))
+		for _, c := range columns {
+			defs = append(defs, d.quote(c.name)+" "+d.columnType(c.fieldType))
+		}
+		query := "CREATE TABLE IF NOT EXISTS " + d.quote(w.table) + " (" + strings.Join(defs, ", ") + ")"
+		if _, err := w.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error creating table %s: %w", w.table, err)
+		}
+		existing, err := w.tableColumns(ctx)
+		if err != nil {
+			return err
+		}
+		w.columns = existing
+	}
+	for _, c := range columns {
+		if _, ok := w.columns[c.name]; ok {
+			continue
+		}
+		query := "ALTER TABLE " + d.quote(w.table) + " ADD COLUMN " + d.quote(c.name) + " " + d.columnType(c.fieldType)
+		if _, err := w.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("error adding column %s to table %s: %w", c.name, w.table, err)
+		}
+		w.columns[c.name] = struct{}{}
+	}
+	return nil
+}
+
+func (w *sqlWriter) tableColumns(ctx context.Context) (map[string]struct{}, error) {
+	rows, err := w.db.QueryContext(ctx, w.dialect.columnsQuery, w.table)
+	if err != nil {
+		return nil, fmt.Errorf("error getting columns of table %s: %w", w.table, err)
+	}
+	defer func() { _ = rows.Close() }()
+	columns := map[string]struct{}{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = struct{}{}
+	}
+	return columns, rows.Err()
+}
+
+// insert inserts the rows in batches and returns how many of them were inserted.
+func (w *sqlWriter) insert(ctx context.Context, rows []sqlRow, batchSize int) (int, error) {
+	d := w.dialect
+	columns := rows[0].columns
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, d.quote(c.name))
+	}
+	prefix := "INSERT INTO " + d.quote(w.table) + " (" + strings.Join(names, ", ") + ") VALUES "
+
+	if maxRows := sqlMaxQueryParams / len(columns); batchSize > maxRows {
+		batchSize = maxRows
+	}
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		args := make([]any, 0, (end-start)*len(columns))
+		for i, row := range rows[start:end] {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("(")
+			for j, v := range row.values {
+				if j > 0 {
+					sb.WriteString(", ")
+				}
+				args = append(args, v)
+				sb.WriteString(d.placeholder(len(args)))
+			}
+			sb.WriteString(")")
+		}
+		if _, err := w.db.ExecContext(ctx, sb.String(), args...); err != nil {
+			return start, fmt.Errorf("error inserting into table %s: %w", w.table, err)
+		}
+	}
+	return len(rows), nil
+}
+
+type sqlDialect struct {
+	driverName   string
+	columnsQuery string
+	quote        func(name string) string
+	placeholder  func(n int) string
+	columnType   func(ft data.FieldType) string
+}
+
+var postgresDialect = &sqlDialect{
+	driverName:   "postgres",
+	columnsQuery: "SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1",
+	quote: func(name string) string {
+		return `"` + name + `"`
+	},
+	placeholder: func(n int) string {
+		return "$" + strconv.Itoa(n)
+	},
+	columnType: func(ft data.FieldType) string {
+		switch {
+		case ft.Time():
+			return "TIMESTAMPTZ"
+		case ft.Numeric():
+			return "DOUBLE PRECISION"
+		case ft == data.FieldTypeBool || ft == data.FieldTypeNullableBool:
+			return "BOOLEAN"
+		default:
+			return "TEXT"
+		}
+	},
+}
+
+var mysqlDialect = &sqlDialect{
+	driverName:   "mysql",
+	columnsQuery: "SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?",
+	quote: func(name string) string {
+		return "`" + name + "`"
+	},
+	placeholder: func(int) string {
+		return "?"
+	},
+	columnType: func(ft data.FieldType) string {
+		switch {
+		case ft.Time():
+			return "DATETIME(6)"
+		case ft.Numeric():
+			return "DOUBLE"
+		case ft == data.FieldTypeBool || ft == data.FieldTypeNullableBool:
+			return "BOOLEAN"
+		default:
+			return "TEXT"
+		}
+	},
+}
+
+// sqlDataSourceDSN builds the connection string of a PostgreSQL or MySQL datasource.
+func sqlDataSourceDSN(ds *datasources.DataSource, password string) (*sqlDialect, string, error) {
+	database := ds.Database
+	if database == "" && ds.JsonData != nil {
+		database = ds.JsonData.Get("database").MustString()
+	}
+	switch ds.Type {
+	case datasources.DS_POSTGRES, "postgres":
+		u := &url.URL{
+			Scheme: "postgres",
+			User:   url.UserPassword(ds.User, password),
+			Host:   ds.URL,
+			Path:   "/" + database,
+		}
+		q := url.Values{}
+		sslMode := "require"
+		if ds.JsonData != nil {
+			sslMode = ds.JsonData.Get("sslmode").MustString(sslMode)
+			for param, key := range map[string]string{"sslrootcert": "sslRootCertFile", "sslcert": "sslCertFile", "sslkey": "sslKeyFile"} {
+				if v := ds.JsonData.Get(key).MustString(); v != "" {
+					q.Set(param, v)
+				}
+			}
+		}
+		q.Set("sslmode", sslMode)
+		u.RawQuery = q.Encode()
+		return postgresDialect, u.String(), nil
+	case datasources.DS_MYSQL:
+		cfg := mysql.NewConfig()
+		cfg.User = ds.User
+		cfg.Passwd = password
+		cfg.Net = "tcp"
+		if strings.HasPrefix(ds.URL, "/") {
+			cfg.Net = "unix"
+		}
+		cfg.Addr = ds.URL
+		cfg.DBName = database
+		cfg.ParseTime = true
+		cfg.Loc = time.UTC
+		return mysqlDialect, cfg.FormatDSN(), nil
+	default:
+		return nil, "", fmt.Errorf("unsupported datasource type for sql output: %s", ds.Type)
+	}
+}
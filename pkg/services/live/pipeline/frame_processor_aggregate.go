@@ -0,0 +1,259 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type AggregateFunction string
+
+const (
+	AggregateFunctionAvg   AggregateFunction = "avg"
+	AggregateFunctionMin   AggregateFunction = "min"
+	AggregateFunctionMax   AggregateFunction = "max"
+	AggregateFunctionSum   AggregateFunction = "sum"
+	AggregateFunctionCount AggregateFunction = "count"
+	AggregateFunctionLast  AggregateFunction = "last"
+)
+
+type AggregateFrameProcessorConfig struct {
+	// TimeField is the name of the time field. The first time field of the frame is used if it is empty.
+	TimeField string `json:"timeField,omitempty"`
+	// WindowMilliseconds is the size of the aggregation window.
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// StepMilliseconds is how often a sliding window is output. Windows are tumbling if it is
+	// zero or equal to WindowMilliseconds.
+	StepMilliseconds int64                  `json:"stepMilliseconds,omitempty"`
+	Fields           []AggregateFieldConfig `json:"fields"`
+}
+
+type AggregateFieldConfig struct {
+	FieldName string            `json:"fieldName"`
+	Function  AggregateFunction `json:"function"`
+	// As is the name of the aggregated field, <fieldName>_<function> by default.
+	As string `json:"as,omitempty"`
+}
+
+// AggregateFrameProcessor aggregates the values of numeric fields over time windows. Values are collected
+// across frames of a channel, and a frame with one row per window is output once a window is complete, i.e.
+// when a value with a time after the end of the window arrives. Frames that do not complete a window are dropped.
+// This allows downsampling high-frequency data before it is sent to subscribers.
+type AggregateFrameProcessor struct {
+	config   AggregateFrameProcessorConfig
+	window   time.Duration
+	step     time.Duration
+	storage  *FrameProcessorStateStorage
+	stateKey string
+}
+
+func NewAggregateFrameProcessor(storage *FrameProcessorStateStorage, config AggregateFrameProcessorConfig) (*AggregateFrameProcessor, error) {
+	if config.WindowMilliseconds <= 0 {
+		return nil, fmt.Errorf("window must be greater than zero")
+	}
+	step := config.StepMilliseconds
+	if step <= 0 {
+		step = config.WindowMilliseconds
+	}
+	if step > config.WindowMilliseconds {
+		return nil, fmt.Errorf("step can't be greater than window")
+	}
+	if len(config.Fields) == 0 {
+		return nil, fmt.Errorf("no fields to aggregate")
+	}
+	for _, f := range config.Fields {
+		switch f.Function {
+		case AggregateFunctionAvg, AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionSum, AggregateFunctionCount, AggregateFunctionLast:
+		default:
+			return nil, fmt.Errorf("unknown aggregate function: %s", f.Function)
+		}
+	}
+	if storage == nil {
+		storage = NewFrameProcessorStateStorage()
+	}
+	return &AggregateFrameProcessor{
+		config:   config,
+		window:   time.Duration(config.WindowMilliseconds) * time.Millisecond,
+		step:     time.Duration(step) * time.Millisecond,
+		storage:  storage,
+		stateKey: frameProcessorStateKey(FrameProcessorTypeAggregate, config),
+	}, nil
+}
+
+const FrameProcessorTypeAggregate = "aggregate"
+
+func (p *AggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeAggregate
+}
+
+type aggregateSample struct {
+	time time.Time
+	// values are the values of the aggregated fields, NaN if the value is missing.
+	values []float64
+}
+
+type aggregateState struct {
+	mu      sync.Mutex
+	samples []aggregateSample
+	// nextEnd is the end of the next window to output.
+	nextEnd time.Time
+}
+
+func (p *AggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIdx, err := timeFieldIndex(frame, p.config.TimeField)
+	if err != nil {
+		return nil, err
+	}
+	timeField := frame.Fields[timeIdx]
+	sources := make([]*data.Field, len(p.config.Fields))
+	for i, f := range p.config.Fields {
+		if idx := fieldIndex(frame, f.FieldName); idx >= 0 {
+			sources[i] = frame.Fields[idx]
+		}
+	}
+
+	out := data.NewFrame(frame.Name, data.NewFieldFromFieldType(data.FieldTypeTime, 0))
+	out.Fields[0].Name = timeField.Name
+	for i, f := range p.config.Fields {
+		field := newFloatField(p.fieldName(f), sources[i])
+		if f.Function == AggregateFunctionCount && field.Config != nil {
+			field.Config.Unit = ""
+		}
+		out.Fields = append(out.Fields, field)
+	}
+
+	state := p.storage.GetOrCreate(p.stateKey, vars.OrgID, vars.Channel, func() any {
+		return &aggregateState{}
+	}).(*aggregateState)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	for row := 0; row < timeField.Len(); row++ {
+		t, ok := timeAt(timeField, row)
+		if !ok {
+			continue
+		}
+		if state.nextEnd.IsZero() {
+			state.nextEnd = t.Truncate(p.step).Add(p.step)
+		}
+		for !t.Before(state.nextEnd) {
+			p.outputWindow(state, out)
+			state.nextEnd = state.nextEnd.Add(p.step)
+			state.prune(state.nextEnd.Add(-p.window))
+			if len(state.samples) == 0 {
+				// Skip the windows without values.
+				if next := t.Truncate(p.step).Add(p.step); next.After(state.nextEnd) {
+					state.nextEnd = next
+				}
+				break
+			}
+		}
+		if t.Before(state.nextEnd.Add(-p.window)) {
+			// The window of the value has already been output.
+			continue
+		}
+		sample := aggregateSample{time: t, values: make([]float64, len(sources))}
+		for i, source := range sources {
+			sample.values[i] = math.NaN()
+			if source == nil || row >= source.Len() {
+				continue
+			}
+			if v, ok := floatAt(source, row); ok {
+				sample.values[i] = v
+			}
+		}
+		state.samples = append(state.samples, sample)
+	}
+
+	if out.Rows() == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func (p *AggregateFrameProcessor) fieldName(f AggregateFieldConfig) string {
+	if f.As != "" {
+		return f.As
+	}
+	return f.FieldName + "_" + string(f.Function)
+}
+
+// outputWindow appends the aggregated values of the window that ends at state.nextEnd to the frame.
+func (p *AggregateFrameProcessor) outputWindow(state *aggregateState, out *data.Frame) {
+	start := state.nextEnd.Add(-p.window)
+	var inWindow []aggregateSample
+	for _, s := range state.samples {
+		if !s.time.Before(start) && s.time.Before(state.nextEnd) {
+			inWindow = append(inWindow, s)
+		}
+	}
+	if len(inWindow) == 0 {
+		return
+	}
+	out.Fields[0].Append(state.nextEnd)
+	for i, f := range p.config.Fields {
+		out.Fields[i+1].Append(aggregate(f.Function, inWindow, i))
+	}
+}
+
+func aggregate(function AggregateFunction, samples []aggregateSample, idx int) *float64 {
+	var result float64
+	var count int
+	var last aggregateSample
+	for _, s := range samples {
+		v := s.values[idx]
+		if math.IsNaN(v) {
+			continue
+		}
+		switch {
+		case count == 0:
+			result = v
+		case function == AggregateFunctionMin:
+			result = math.Min(result, v)
+		case function == AggregateFunctionMax:
+			result = math.Max(result, v)
+		case function == AggregateFunctionAvg, function == AggregateFunctionSum:
+			result += v
+		}
+		if count == 0 || !s.time.Before(last.time) {
+			last = s
+		}
+		count++
+	}
+	switch function {
+	case AggregateFunctionCount:
+		c := float64(count)
+		return &c
+	case AggregateFunctionAvg:
+		if count == 0 {
+			return nil
+		}
+		return finiteOrNil(result / float64(count))
+	case AggregateFunctionLast:
+		if count == 0 {
+			return nil
+		}
+		return finiteOrNil(last.values[idx])
+	default:
+		if count == 0 {
+			return nil
+		}
+		return finiteOrNil(result)
+	}
+}
+
+// prune removes the values before the given time.
+func (s *aggregateState) prune(before time.Time) {
+	n := 0
+	for _, sample := range s.samples {
+		if !sample.time.Before(before) {
+			s.samples[n] = sample
+			n++
+		}
+	}
+	s.samples = s.samples[:n]
+}
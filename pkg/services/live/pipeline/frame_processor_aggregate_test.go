@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateFrameProcessor_Tumbling(t *testing.T) {
+	processor, err := NewAggregateFrameProcessor(NewFrameProcessorStateStorage(), AggregateFrameProcessorConfig{
+		WindowMilliseconds: 10000,
+		Fields: []AggregateFieldConfig{
+			{FieldName: "value", Function: AggregateFunctionAvg},
+			{FieldName: "value", Function: AggregateFunctionMax, As: "peak"},
+		},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(1000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start, start.Add(time.Second), start.Add(5 * time.Second)}),
+		data.NewField("value", nil, []float64{1, 2, 3}),
+	)
+	out, err := processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.Nil(t, out, "frame must be dropped until the window is complete")
+
+	frame = data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(12 * time.Second)}),
+		data.NewField("value", nil, []float64{10}),
+	)
+	out, err = processor.ProcessFrame(context.Background(), vars, frame)
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 1, out.Rows())
+	require.Len(t, out.Fields, 3)
+	require.Equal(t, start.Add(10*time.Second), out.Fields[0].At(0))
+	require.Equal(t, "value_avg", out.Fields[1].Name)
+	require.Equal(t, 2.0, *out.Fields[1].At(0).(*float64))
+	require.Equal(t, "peak", out.Fields[2].Name)
+	require.Equal(t, 3.0, *out.Fields[2].At(0).(*float64))
+}
+
+func TestAggregateFrameProcessor_StateSurvivesRebuild(t *testing.T) {
+	storage := NewFrameProcessorStateStorage()
+	config := AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []AggregateFieldConfig{{FieldName: "value", Function: AggregateFunctionSum}},
+	}
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+	start := time.Unix(1000, 0)
+
+	processor, err := NewAggregateFrameProcessor(storage, config)
+	require.NoError(t, err)
+	out, err := processor.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start}),
+		data.NewField("value", nil, []float64{4}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, out)
+
+	// Channel rules are rebuilt periodically, a new processor with the same config must continue the window.
+	processor, err = NewAggregateFrameProcessor(storage, config)
+	require.NoError(t, err)
+	out, err = processor.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(500 * time.Millisecond), start.Add(1500 * time.Millisecond)}),
+		data.NewField("value", nil, []float64{5, 100}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 1, out.Rows())
+	require.Equal(t, 9.0, *out.Fields[1].At(0).(*float64))
+}
+
+func TestNewAggregateFrameProcessor_InvalidConfig(t *testing.T) {
+	_, err := NewAggregateFrameProcessor(nil, AggregateFrameProcessorConfig{
+		Fields: []AggregateFieldConfig{{FieldName: "value", Function: AggregateFunctionAvg}},
+	})
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(nil, AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		StepMilliseconds:   2000,
+		Fields:             []AggregateFieldConfig{{FieldName: "value", Function: AggregateFunctionAvg}},
+	})
+	require.Error(t, err)
+
+	_, err = NewAggregateFrameProcessor(nil, AggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Fields:             []AggregateFieldConfig{{FieldName: "value", Function: "median"}},
+	})
+	require.Error(t, err)
+}
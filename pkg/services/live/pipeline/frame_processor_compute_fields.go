@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/expression"
+)
+
+type ComputeFieldsFrameProcessorConfig struct {
+	Fields []ComputedFieldConfig `json:"fields"`
+}
+
+type ComputedFieldConfig struct {
+	Name string `json:"name"`
+	// Expression is an arithmetic expression over the numeric fields of the frame, e.g. (used / total) * 100.
+	// Fields which names contain other characters than letters, digits, underscores and dots are referenced
+	// with ${field name}.
+	Expression string `json:"expression"`
+	Unit       string `json:"unit,omitempty"`
+}
+
+// ComputeFieldsFrameProcessor adds fields computed from the other fields of a data.Frame. Fields are computed
+// in order, so an expression can reference the fields computed before it. A computed value is null if a
+// referenced value is missing or if the result is not a finite number.
+type ComputeFieldsFrameProcessor struct {
+	config      ComputeFieldsFrameProcessorConfig
+	expressions []*expression.Expression
+}
+
+func NewComputeFieldsFrameProcessor(config ComputeFieldsFrameProcessorConfig) (*ComputeFieldsFrameProcessor, error) {
+	expressions := make([]*expression.Expression, 0, len(config.Fields))
+	for _, f := range config.Fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("computed field must have a name")
+		}
+		e, err := expression.Parse(f.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression for field %s: %w", f.Name, err)
+		}
+		expressions = append(expressions, e)
+	}
+	return &ComputeFieldsFrameProcessor{config: config, expressions: expressions}, nil
+}
+
+const FrameProcessorTypeComputeFields = "computeFields"
+
+func (p *ComputeFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeComputeFields
+}
+
+func (p *ComputeFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	rows := frame.Rows()
+	for i, f := range p.config.Fields {
+		e := p.expressions[i]
+		// Resolve the referenced fields once per frame.
+		fields := make(map[string]*data.Field, len(e.Variables()))
+		for _, name := range e.Variables() {
+			if idx := fieldIndex(frame, name); idx >= 0 {
+				fields[name] = frame.Fields[idx]
+			}
+		}
+		computed := newFloatField(f.Name, nil)
+		if f.Unit != "" {
+			computed.Config = &data.FieldConfig{Unit: f.Unit}
+		}
+		for row := 0; row < rows; row++ {
+			v, err := e.Eval(func(name string) (float64, bool) {
+				field, ok := fields[name]
+				if !ok || row >= field.Len() {
+					return 0, false
+				}
+				return floatAt(field, row)
+			})
+			if err != nil {
+				computed.Append((*float64)(nil))
+				continue
+			}
+			computed.Append(finiteOrNil(v))
+		}
+		if idx := fieldIndex(frame, f.Name); idx >= 0 {
+			frame.Fields[idx] = computed
+		} else {
+			frame.Fields = append(frame.Fields, computed)
+		}
+	}
+	return frame, nil
+}
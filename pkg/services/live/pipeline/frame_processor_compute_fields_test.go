@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFieldsFrameProcessor(t *testing.T) {
+	processor, err := NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{
+			{Name: "usage", Expression: "used / total * 100", Unit: "percent"},
+			{Name: "free", Expression: "total - used"},
+		},
+	})
+	require.NoError(t, err)
+
+	frame := data.NewFrame("test",
+		data.NewField("used", nil, []float64{25, 10}),
+		data.NewField("total", nil, []float64{100, 0}),
+	)
+	out, err := processor.ProcessFrame(context.Background(), Vars{}, frame)
+	require.NoError(t, err)
+	require.Len(t, out.Fields, 4)
+	require.Equal(t, "usage", out.Fields[2].Name)
+	require.Equal(t, "percent", out.Fields[2].Config.Unit)
+	require.Equal(t, 25.0, *out.Fields[2].At(0).(*float64))
+	require.Nil(t, out.Fields[2].At(1).(*float64), "division by zero must result in null")
+	require.Equal(t, 75.0, *out.Fields[3].At(0).(*float64))
+
+	_, err = NewComputeFieldsFrameProcessor(ComputeFieldsFrameProcessorConfig{
+		Fields: []ComputedFieldConfig{{Name: "broken", Expression: "used / "}},
+	})
+	require.Error(t, err)
+}
+
+type countingFrameProcessor struct {
+	calls int
+}
+
+func (p *countingFrameProcessor) Type() string {
+	return "counting"
+}
+
+func (p *countingFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	p.calls++
+	return frame, nil
+}
+
+func TestAggregateFrameProcessor_InMultipleProcessor(t *testing.T) {
+	aggregate, err := NewAggregateFrameProcessor(NewFrameProcessorStateStorage(), AggregateFrameProcessorConfig{
+		WindowMilliseconds: 10000,
+		Fields:             []AggregateFieldConfig{{FieldName: "value", Function: AggregateFunctionSum}},
+	})
+	require.NoError(t, err)
+	next := &countingFrameProcessor{}
+	processor := NewMultipleFrameProcessor(aggregate, next)
+
+	start := time.Unix(1000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/aggregate"}
+
+	out, err := processor.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start}),
+		data.NewField("value", nil, []float64{1}),
+	))
+	require.NoError(t, err)
+	require.Nil(t, out)
+	require.Zero(t, next.calls, "processors after a dropped frame must not be called")
+
+	out, err = processor.ProcessFrame(context.Background(), vars, data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{start.Add(12 * time.Second)}),
+		data.NewField("value", nil, []float64{2}),
+	))
+	require.NoError(t, err)
+	require.NotNil(t, out)
+	require.Equal(t, 1, next.calls)
+}
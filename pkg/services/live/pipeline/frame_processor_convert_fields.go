@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type ConvertFieldsFrameProcessorConfig struct {
+	Fields []ConvertFieldConfig `json:"fields"`
+}
+
+// ConvertFieldConfig describes a linear conversion of the values of a numeric field, value * multiply + add.
+// For example, Celsius degrees are converted to Fahrenheit degrees with multiply 1.8 and add 32.
+type ConvertFieldConfig struct {
+	FieldName string `json:"fieldName"`
+	// Multiply is 1 by default.
+	Multiply *float64 `json:"multiply,omitempty"`
+	Add      float64  `json:"add,omitempty"`
+	// Unit is set as the unit of the converted field if it is not empty.
+	Unit string `json:"unit,omitempty"`
+}
+
+// ConvertFieldsFrameProcessor converts the values and units of numeric fields. Converted fields
+// are nullable float fields.
+type ConvertFieldsFrameProcessor struct {
+	config ConvertFieldsFrameProcessorConfig
+}
+
+func NewConvertFieldsFrameProcessor(config ConvertFieldsFrameProcessorConfig) *ConvertFieldsFrameProcessor {
+	return &ConvertFieldsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeConvertFields = "convertFields"
+
+func (p *ConvertFieldsFrameProcessor) Type() string {
+	return FrameProcessorTypeConvertFields
+}
+
+func (p *ConvertFieldsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	for _, c := range p.config.Fields {
+		idx := fieldIndex(frame, c.FieldName)
+		if idx < 0 || !frame.Fields[idx].Type().Numeric() {
+			continue
+		}
+		source := frame.Fields[idx]
+		multiply := 1.0
+		if c.Multiply != nil {
+			multiply = *c.Multiply
+		}
+		converted := newFloatField(source.Name, source)
+		for row := 0; row < source.Len(); row++ {
+			v, ok := floatAt(source, row)
+			if !ok {
+				converted.Append((*float64)(nil))
+				continue
+			}
+			converted.Append(finiteOrNil(v*multiply + c.Add))
+		}
+		if c.Unit != "" {
+			if converted.Config == nil {
+				converted.Config = &data.FieldConfig{}
+			}
+			converted.Config.Unit = c.Unit
+		}
+		frame.Fields[idx] = converted
+	}
+	return frame, nil
+}
@@ -0,0 +1,68 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type ExtractLabelsFrameProcessorConfig struct {
+	// FieldNames are the fields whose values become labels. The label names are the field names.
+	FieldNames []string `json:"fieldNames"`
+	// KeepFields keeps the fields in the frame, they are removed by default.
+	KeepFields bool `json:"keepFields,omitempty"`
+}
+
+// ExtractLabelsFrameProcessor turns the values of fields into labels of the other non-time fields of a
+// data.Frame. As a field can only have one set of labels, the value of the last row is used. It is meant for
+// channels where each frame describes a single entity, such as a device pushing its measurements.
+type ExtractLabelsFrameProcessor struct {
+	config ExtractLabelsFrameProcessorConfig
+}
+
+func NewExtractLabelsFrameProcessor(config ExtractLabelsFrameProcessorConfig) *ExtractLabelsFrameProcessor {
+	return &ExtractLabelsFrameProcessor{config: config}
+}
+
+const FrameProcessorTypeExtractLabels = "extractLabels"
+
+func (p *ExtractLabelsFrameProcessor) Type() string {
+	return FrameProcessorTypeExtractLabels
+}
+
+func (p *ExtractLabelsFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	labels := data.Labels{}
+	for _, name := range p.config.FieldNames {
+		idx := fieldIndex(frame, name)
+		if idx < 0 || frame.Fields[idx].Len() == 0 {
+			continue
+		}
+		v, ok := frame.Fields[idx].ConcreteAt(frame.Fields[idx].Len() - 1)
+		if !ok {
+			continue
+		}
+		labels[name] = fmt.Sprint(v)
+	}
+	if len(labels) == 0 {
+		return frame, nil
+	}
+
+	fields := make([]*data.Field, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		if _, ok := labels[field.Name]; ok && !p.config.KeepFields {
+			continue
+		}
+		if !field.Type().Time() && !stringInSlice(field.Name, p.config.FieldNames) {
+			if field.Labels == nil {
+				field.Labels = data.Labels{}
+			}
+			for k, v := range labels {
+				field.Labels[k] = v
+			}
+		}
+		fields = append(fields, field)
+	}
+	frame.Fields = fields
+	return frame, nil
+}
@@ -7,7 +7,8 @@ import (
 )
 
 // MultipleFrameProcessor can combine several FrameProcessor and
-// execute them sequentially.
+// execute them sequentially. Processing stops as soon as one of
+// the processors drops the frame.
 type MultipleFrameProcessor struct {
 	Processors []FrameProcessor
 }
@@ -26,6 +27,10 @@ func (p *MultipleFrameProcessor) ProcessFrame(ctx context.Context, vars Vars, fr
 			logger.Error("Error processing frame", "error", err)
 			return nil, err
 		}
+		if frame == nil {
+			// Processor dropped the frame, nothing left to pass to the next ones.
+			return nil, nil
+		}
 	}
 	return frame, nil
 }
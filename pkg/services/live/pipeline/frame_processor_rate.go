@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+type RateFrameProcessorConfig struct {
+	// TimeField is the name of the time field. The first time field of the frame is used if it is empty.
+	TimeField string            `json:"timeField,omitempty"`
+	Fields    []RateFieldConfig `json:"fields"`
+}
+
+type RateFieldConfig struct {
+	FieldName string `json:"fieldName"`
+	// As is the name of the rate field. The field is replaced with its rate if it is empty.
+	As string `json:"as,omitempty"`
+	// Counter should be set for monotonic counters, a decreasing value is then considered as a counter reset.
+	Counter bool `json:"counter,omitempty"`
+}
+
+// RateFrameProcessor calculates the per-second rate of change of numeric fields. The last value of each field
+// is kept between frames of a channel, so the rate can be calculated for frames that have a single row.
+type RateFrameProcessor struct {
+	config   RateFrameProcessorConfig
+	storage  *FrameProcessorStateStorage
+	stateKey string
+}
+
+func NewRateFrameProcessor(storage *FrameProcessorStateStorage, config RateFrameProcessorConfig) (*RateFrameProcessor, error) {
+	if len(config.Fields) == 0 {
+		return nil, fmt.Errorf("no fields to calculate rate for")
+	}
+	if storage == nil {
+		storage = NewFrameProcessorStateStorage()
+	}
+	return &RateFrameProcessor{
+		config:   config,
+		storage:  storage,
+		stateKey: frameProcessorStateKey(FrameProcessorTypeRate, config),
+	}, nil
+}
+
+const FrameProcessorTypeRate = "rate"
+
+func (p *RateFrameProcessor) Type() string {
+	return FrameProcessorTypeRate
+}
+
+type rateSample struct {
+	time  time.Time
+	value float64
+}
+
+type rateState struct {
+	mu   sync.Mutex
+	last map[string]rateSample
+}
+
+func (p *RateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIdx, err := timeFieldIndex(frame, p.config.TimeField)
+	if err != nil {
+		return nil, err
+	}
+	timeField := frame.Fields[timeIdx]
+
+	state := p.storage.GetOrCreate(p.stateKey, vars.OrgID, vars.Channel, func() any {
+		return &rateState{last: map[string]rateSample{}}
+	}).(*rateState)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	for _, f := range p.config.Fields {
+		idx := fieldIndex(frame, f.FieldName)
+		if idx < 0 {
+			continue
+		}
+		source := frame.Fields[idx]
+		name := f.As
+		if name == "" {
+			name = source.Name
+		}
+		rate := newFloatField(name, source)
+		for row := 0; row < source.Len(); row++ {
+			t, tOK := timeAt(timeField, row)
+			v, vOK := floatAt(source, row)
+			if !tOK || !vOK {
+				rate.Append((*float64)(nil))
+				continue
+			}
+			rate.Append(calculateRate(state.last[f.FieldName], t, v, f.Counter))
+			if prev, ok := state.last[f.FieldName]; !ok || t.After(prev.time) {
+				state.last[f.FieldName] = rateSample{time: t, value: v}
+			}
+		}
+		if f.As == "" {
+			frame.Fields[idx] = rate
+		} else {
+			frame.Fields = append(frame.Fields, rate)
+		}
+	}
+	return frame, nil
+}
+
+func calculateRate(prev rateSample, t time.Time, v float64, counter bool) *float64 {
+	if prev.time.IsZero() || !t.After(prev.time) {
+		return nil
+	}
+	delta := v - prev.value
+	if counter && delta < 0 {
+		// The counter was reset, assume it started from zero.
+		delta = v
+	}
+	return finiteOrNil(delta / t.Sub(prev.time).Seconds())
+}
@@ -0,0 +1,72 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+)
+
+const (
+	// frameProcessorStateTTL is how long the state of a stateful frame processor is kept after the last frame
+	// of its channel was processed.
+	frameProcessorStateTTL = time.Hour
+	// frameProcessorStateCleanupInterval is how often unused states are removed.
+	frameProcessorStateCleanupInterval = time.Minute
+)
+
+// FrameProcessorStateStorage keeps the state of stateful frame processors in memory. Channel rules are rebuilt
+// periodically, so the state can't be kept in processors themselves. Not usable in HA setup.
+type FrameProcessorStateStorage struct {
+	mu          sync.Mutex
+	states      map[string]*frameProcessorState
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type frameProcessorState struct {
+	value    any
+	lastUsed time.Time
+}
+
+func NewFrameProcessorStateStorage() *FrameProcessorStateStorage {
+	return &FrameProcessorStateStorage{
+		states: map[string]*frameProcessorState{},
+		now:    time.Now,
+	}
+}
+
+// GetOrCreate returns the state of the processor with the given key for the channel. The state is created with
+// the create function if it does not exist yet.
+func (s *FrameProcessorStateStorage) GetOrCreate(processorKey string, orgID int64, channel string, create func() any) any {
+	key := processorKey + "|" + orgchannel.PrependOrgID(orgID, channel)
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastCleanup) > frameProcessorStateCleanupInterval {
+		for k, st := range s.states {
+			if now.Sub(st.lastUsed) > frameProcessorStateTTL {
+				delete(s.states, k)
+			}
+		}
+		s.lastCleanup = now
+	}
+	st, ok := s.states[key]
+	if !ok {
+		st = &frameProcessorState{value: create()}
+		s.states[key] = st
+	}
+	st.lastUsed = now
+	return st.value
+}
+
+// frameProcessorStateKey returns a key that identifies a processor by its configuration, so that a processor keeps
+// its state when channel rules are rebuilt, and starts from scratch when its configuration changes.
+func frameProcessorStateKey(processorType string, config any) string {
+	b, err := json.Marshal(config)
+	if err != nil {
+		return processorType
+	}
+	return processorType + "|" + string(b)
+}
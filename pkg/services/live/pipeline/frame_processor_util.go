@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// fieldIndex returns the index of the field with the given name, or -1 if the frame has no such field.
+func fieldIndex(frame *data.Frame, name string) int {
+	for i, f := range frame.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// timeFieldIndex returns the index of the time field with the given name, or of the first time field
+// of the frame if name is empty.
+func timeFieldIndex(frame *data.Frame, name string) (int, error) {
+	for i, f := range frame.Fields {
+		if !f.Type().Time() {
+			continue
+		}
+		if name == "" || f.Name == name {
+			return i, nil
+		}
+	}
+	if name == "" {
+		return -1, fmt.Errorf("frame has no time field")
+	}
+	return -1, fmt.Errorf("time field %s not found", name)
+}
+
+// timeAt returns the time value of the field at the given index.
+func timeAt(field *data.Field, idx int) (time.Time, bool) {
+	switch v := field.At(idx).(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// floatAt returns the numeric value of the field at the given index. It returns false for null values
+// and for fields that are not numeric.
+func floatAt(field *data.Field, idx int) (float64, bool) {
+	if !field.Type().Numeric() {
+		return 0, false
+	}
+	v, err := field.FloatAt(idx)
+	if err != nil || math.IsNaN(v) {
+		return 0, false
+	}
+	return v, true
+}
+
+// newFloatField creates an empty nullable float field that has the labels and config of the source field.
+func newFloatField(name string, source *data.Field) *data.Field {
+	f := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+	f.Name = name
+	if source != nil {
+		if source.Labels != nil {
+			f.Labels = source.Labels.Copy()
+		}
+		if source.Config != nil {
+			cfg := *source.Config
+			f.Config = &cfg
+		}
+	}
+	return f
+}
+
+// finiteOrNil returns a pointer to the value, or nil if it is not a finite number.
+func finiteOrNil(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
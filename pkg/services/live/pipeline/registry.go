@@ -1,5 +1,7 @@
 package pipeline
 
+import "github.com/grafana/grafana/pkg/util"
+
 type EntityInfo struct {
 	Type        string `json:"type"`
 	Description string `json:"description"`
@@ -48,6 +50,23 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeSQL,
+		Description: "insert frame rows into a PostgreSQL or MySQL datasource table",
+		Example: SQLOutputConfig{
+			DatasourceUID: "my-postgres",
+			Table:         "measurements",
+		},
+	},
+	{
+		Type:        FrameOutputTypeFile,
+		Description: "append frame rows to rotating CSV or Parquet files",
+		Example: FileOutputConfig{
+			Name:     "measurements",
+			Format:   FileOutputFormatCSV,
+			MaxFiles: 24,
+		},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
@@ -66,6 +85,18 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypeCSV,
+		Description: "CSV lines, one frame row per line",
+		Example: CSVConverterConfig{
+			Columns:    []string{"time", "device", "temperature"},
+			TimeColumn: "time",
+		},
+	},
+	{
+		Type:        ConverterTypePrometheus,
+		Description: "accept Prometheus text exposition format",
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +110,57 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeAggregate,
+		Description: "aggregate field values over tumbling or sliding time windows",
+		Example: AggregateFrameProcessorConfig{
+			WindowMilliseconds: 10000,
+			Fields: []AggregateFieldConfig{
+				{FieldName: "value", Function: AggregateFunctionAvg},
+			},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeRate,
+		Description: "calculate the per-second rate of change of fields",
+		Example: RateFrameProcessorConfig{
+			Fields: []RateFieldConfig{
+				{FieldName: "bytes", As: "bytes_per_second", Counter: true},
+			},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields",
+		Example: RenameFieldsFrameProcessorConfig{
+			Renames: map[string]string{"temp": "temperature"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeConvertFields,
+		Description: "convert field values and units",
+		Example: ConvertFieldsFrameProcessorConfig{
+			Fields: []ConvertFieldConfig{
+				{FieldName: "temperature", Multiply: util.Pointer(1.8), Add: 32, Unit: "fahrenheit"},
+			},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeExtractLabels,
+		Description: "turn field values into labels of the other fields",
+		Example: ExtractLabelsFrameProcessorConfig{
+			FieldNames: []string{"device"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeComputeFields,
+		Description: "add fields computed with arithmetic expressions",
+		Example: ComputeFieldsFrameProcessorConfig{
+			Fields: []ComputedFieldConfig{
+				{Name: "memory_usage", Expression: "memory_used / memory_total * 100", Unit: "percent"},
+			},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
@@ -14,9 +14,14 @@ type StorageRuleBuilder struct {
 	Node                 *centrifuge.Node
 	ManagedStream        *managedstream.Runner
 	FrameStorage         *FrameStorage
+	ProcessorStorage     *FrameProcessorStateStorage
+	SQLWriterStorage     *SQLWriterStorage
+	FileWriterStorage    *FileWriterStorage
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// DryRun skips writers, SQL and file outputs are validated but never write frames.
+	DryRun bool
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -69,6 +74,16 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypeCSV:
+		if config.CSVConverterConfig == nil {
+			config.CSVConverterConfig = &CSVConverterConfig{}
+		}
+		return NewCSVConverter(*config.CSVConverterConfig), nil
+	case ConverterTypePrometheus:
+		if config.PrometheusConverterConfig == nil {
+			config.PrometheusConverterConfig = &PrometheusConverterConfig{}
+		}
+		return NewPrometheusConverter(*config.PrometheusConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
@@ -104,6 +119,36 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeAggregate:
+		if config.AggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewAggregateFrameProcessor(f.ProcessorStorage, *config.AggregateProcessorConfig)
+	case FrameProcessorTypeRate:
+		if config.RateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRateFrameProcessor(f.ProcessorStorage, *config.RateProcessorConfig)
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeConvertFields:
+		if config.ConvertFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewConvertFieldsFrameProcessor(*config.ConvertFieldsProcessorConfig), nil
+	case FrameProcessorTypeExtractLabels:
+		if config.ExtractLabelsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewExtractLabelsFrameProcessor(*config.ExtractLabelsProcessorConfig), nil
+	case FrameProcessorTypeComputeFields:
+		if config.ComputeFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewComputeFieldsFrameProcessor(*config.ComputeFieldsProcessorConfig)
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -249,6 +294,27 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeSQL:
+		if config.SQLOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		if f.SQLWriterStorage == nil {
+			return nil, fmt.Errorf("%s output is not available", config.Type)
+		}
+		output, err := NewSQLFrameOutput(f.SQLWriterStorage, *config.SQLOutputConfig)
+		if err != nil || !f.DryRun {
+			return output, err
+		}
+		return NewDryRunFrameOutput(config.Type), nil
+	case FrameOutputTypeFile:
+		if config.FileOutputConfig == nil {
+			return nil, missingConfiguration
+		}
+		output, err := NewFileFrameOutput(f.FileWriterStorage, *config.FileOutputConfig)
+		if err != nil || !f.DryRun {
+			return output, err
+		}
+		return NewDryRunFrameOutput(config.Type), nil
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
@@ -376,5 +442,31 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		rules = append(rules, rule)
 	}
 
+	if !f.DryRun {
+		f.releaseWriters(orgID, channelRules)
+	}
 	return rules, nil
 }
+
+// releaseWriters closes the SQL and file writers of the org which are not used by its
+// rules anymore.
+func (f *StorageRuleBuilder) releaseWriters(orgID int64, channelRules []ChannelRule) {
+	var sqlConfigs []SQLOutputConfig
+	var fileConfigs []FileOutputConfig
+	for _, ruleConfig := range channelRules {
+		walkFrameOutputterConfigs(ruleConfig.Settings, func(config *FrameOutputterConfig) {
+			if config.SQLOutputConfig != nil {
+				sqlConfigs = append(sqlConfigs, *config.SQLOutputConfig)
+			}
+			if config.FileOutputConfig != nil {
+				fileConfigs = append(fileConfigs, *config.FileOutputConfig)
+			}
+		})
+	}
+	if f.SQLWriterStorage != nil {
+		f.SQLWriterStorage.release(orgID, sqlConfigs)
+	}
+	if f.FileWriterStorage != nil {
+		f.FileWriterStorage.release(orgID, fileConfigs)
+	}
+}
@@ -24,7 +24,7 @@ func (s *ManagedStreamSubscriber) Type() string {
 	return SubscriberTypeManagedStream
 }
 
-func (s *ManagedStreamSubscriber) Subscribe(ctx context.Context, vars Vars, _ []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
+func (s *ManagedStreamSubscriber) Subscribe(ctx context.Context, vars Vars, data []byte) (model.SubscribeReply, backend.SubscribeStreamStatus, error) {
 	stream, err := s.managedStream.GetOrCreateStream(vars.OrgID, vars.Scope, vars.Namespace)
 	if err != nil {
 		logger.Error("Error getting managed stream", "error", err)
@@ -37,5 +37,6 @@ func (s *ManagedStreamSubscriber) Subscribe(ctx context.Context, vars Vars, _ []
 	return stream.OnSubscribe(ctx, u, model.SubscribeEvent{
 		Channel: vars.Channel,
 		Path:    vars.Path,
+		Data:    data,
 	})
 }
@@ -0,0 +1,18 @@
+// Package mfa provides a second authentication factor for users who log in with a Grafana
+// password, using TOTP authenticator apps, WebAuthn security keys and recovery codes.
+package mfa
+
+import (
+	"context"
+)
+
+type Service interface {
+	// GetStatus returns the second factors of the user and whether the user has to use one.
+	GetStatus(ctx context.Context, userID int64, isServerAdmin bool) (*Status, error)
+	// IsRequired returns true if a policy requires the user to use a second factor.
+	IsRequired(ctx context.Context, userID int64, isServerAdmin bool) (bool, error)
+	// Reset removes all the second factors of the user, for example when a security key was lost.
+	Reset(ctx context.Context, userID int64) error
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, cmd *SetOrgPolicyCommand) error
+}
@@ -0,0 +1,364 @@
+package mfaimpl
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+
+	router.Group("/api/user/mfa", func(mfaRoute routing.RouteRegister) {
+		mfaRoute.Get("/", routing.Wrap(s.getStatusHandler))
+		mfaRoute.Post("/totp", routing.Wrap(s.beginTOTPHandler))
+		mfaRoute.Post("/totp/confirm", routing.Wrap(s.confirmTOTPHandler))
+		mfaRoute.Delete("/totp", routing.Wrap(s.deleteTOTPHandler))
+		mfaRoute.Post("/webauthn/register/begin", routing.Wrap(s.beginWebAuthnRegistrationHandler))
+		mfaRoute.Post("/webauthn/register/finish", routing.Wrap(s.finishWebAuthnRegistrationHandler))
+		mfaRoute.Delete("/webauthn/:id", routing.Wrap(s.deleteWebAuthnCredentialHandler))
+		mfaRoute.Post("/recovery-codes", routing.Wrap(s.recoveryCodesHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	router.Group("/api/org/mfa-policy", func(policyRoute routing.RouteRegister) {
+		policyRoute.Get("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgsRead)), routing.Wrap(s.getOrgPolicyHandler))
+		policyRoute.Put("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite)), routing.Wrap(s.setOrgPolicyHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// userID returns the ID of the signed in user, second factors are only supported for users.
+func userID(c *contextmodel.ReqContext) (int64, response.Response) {
+	if !c.SignedInUser.IsIdentityType(claims.TypeUser) {
+		return 0, response.Error(http.StatusBadRequest, "Only users can use multi-factor authentication", nil)
+	}
+	id, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "Got invalid user id", err)
+	}
+	return id, nil
+}
+
+// swagger:route GET /user/mfa signed_in_user getMFAStatus
+//
+// Get the multi-factor authentication status of the signed in user.
+//
+// Responses:
+// 200: getMFAStatusResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	status, err := s.GetStatus(c.Req.Context(), id, c.SignedInUser.GetIsGrafanaAdmin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /user/mfa/totp signed_in_user beginMFATOTP
+//
+// Start the enrollment of an authenticator app.
+//
+// Returns the secret of the authenticator app and its otpauth URL, usually shown as a QR code.
+// The enrollment has to be confirmed with a code of the app.
+//
+// Responses:
+// 200: beginMFATOTPResponse
+// 401: unauthorisedError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) beginTOTPHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	secret, url, err := s.beginTOTP(c.Req.Context(), id, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enroll authenticator app", err)
+	}
+	return response.JSON(http.StatusOK, BeginTOTPResult{Secret: secret, URL: url})
+}
+
+// swagger:route POST /user/mfa/totp/confirm signed_in_user confirmMFATOTP
+//
+// Confirm the enrollment of an authenticator app with a code of the app.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) confirmTOTPHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	cmd := ConfirmTOTPCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	ok, err := s.verifyTOTP(c.Req.Context(), id, cmd.Code, true)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to confirm authenticator app", err)
+	}
+	if !ok {
+		return response.Err(mfa.ErrInvalidCode.Errorf("invalid code"))
+	}
+	return response.Success("Authenticator app enrolled")
+}
+
+// swagger:route DELETE /user/mfa/totp signed_in_user deleteMFATOTP
+//
+// Remove the authenticator app of the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) deleteTOTPHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	if err := s.removeTOTP(c.Req.Context(), id, c.SignedInUser.GetIsGrafanaAdmin()); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove authenticator app", err)
+	}
+	return response.Success("Authenticator app removed")
+}
+
+// swagger:route POST /user/mfa/webauthn/register/begin signed_in_user beginMFAWebAuthnRegistration
+//
+// Start the registration of a security key.
+//
+// Returns the options to pass to navigator.credentials.create, with binary values encoded in
+// base64url.
+//
+// Responses:
+// 200: beginMFAWebAuthnRegistrationResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) beginWebAuthnRegistrationHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	options, err := s.beginWebAuthnRegistration(c.Req.Context(), id, c.SignedInUser.GetLogin(), c.SignedInUser.GetDisplayName())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register security key", err)
+	}
+	return response.JSON(http.StatusOK, map[string]any{"publicKey": options})
+}
+
+// swagger:route POST /user/mfa/webauthn/register/finish signed_in_user finishMFAWebAuthnRegistration
+//
+// Finish the registration of a security key with its response to navigator.credentials.create.
+//
+// Responses:
+// 200: finishMFAWebAuthnRegistrationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) finishWebAuthnRegistrationHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	cmd := FinishWebAuthnRegistrationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Name == "" {
+		cmd.Name = "Security key"
+	}
+	credential, err := s.finishWebAuthnRegistration(c.Req.Context(), id, cmd.Name, &cmd.Credential)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register security key", err)
+	}
+	return response.JSON(http.StatusOK, credential)
+}
+
+// swagger:route DELETE /user/mfa/webauthn/{id} signed_in_user deleteMFAWebAuthnCredential
+//
+// Remove a security key of the signed in user.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteWebAuthnCredentialHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	credentialID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	if err := s.removeWebAuthnCredential(c.Req.Context(), id, c.SignedInUser.GetIsGrafanaAdmin(), credentialID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove security key", err)
+	}
+	return response.Success("Security key removed")
+}
+
+// swagger:route POST /user/mfa/recovery-codes signed_in_user createMFARecoveryCodes
+//
+// Create recovery codes for the signed in user.
+//
+// Replaces the existing recovery codes. Each code can be used once instead of the second
+// factor, and the codes are only returned by this request.
+//
+// Responses:
+// 200: createMFARecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) recoveryCodesHandler(c *contextmodel.ReqContext) response.Response {
+	id, errResp := userID(c)
+	if errResp != nil {
+		return errResp
+	}
+	codes, err := s.newRecoveryCodes(c.Req.Context(), id, c.SignedInUser.GetIsGrafanaAdmin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, RecoveryCodesResult{Codes: codes})
+}
+
+// swagger:route GET /org/mfa-policy org getOrgMFAPolicy
+//
+// Get the multi-factor authentication policy of the current organization.
+//
+// Responses:
+// 200: getOrgMFAPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get multi-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /org/mfa-policy org setOrgMFAPolicy
+//
+// Set the multi-factor authentication policy of the current organization.
+//
+// Members with at least the required role have to use a second factor to log in with a
+// Grafana password. An empty role removes the policy.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) setOrgPolicyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := mfa.SetOrgPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	if err := s.SetOrgPolicy(c.Req.Context(), &cmd); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set multi-factor authentication policy", err)
+	}
+	return response.Success("Multi-factor authentication policy updated")
+}
+
+type BeginTOTPResult struct {
+	Secret string `json:"secret"`
+	URL    string `json:"url"`
+}
+
+type ConfirmTOTPCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type FinishWebAuthnRegistrationCommand struct {
+	Name       string              `json:"name"`
+	Credential WebAuthnAttestation `json:"credential"`
+}
+
+type RecoveryCodesResult struct {
+	Codes []string `json:"codes"`
+}
+
+// swagger:parameters confirmMFATOTP
+type ConfirmMFATOTPParams struct {
+	// in:body
+	// required:true
+	Body ConfirmTOTPCommand `json:"body"`
+}
+
+// swagger:parameters finishMFAWebAuthnRegistration
+type FinishMFAWebAuthnRegistrationParams struct {
+	// in:body
+	// required:true
+	Body FinishWebAuthnRegistrationCommand `json:"body"`
+}
+
+// swagger:parameters deleteMFAWebAuthnCredential
+type DeleteMFAWebAuthnCredentialParams struct {
+	// in:path
+	// required:true
+	ID int64 `json:"id"`
+}
+
+// swagger:parameters setOrgMFAPolicy
+type SetOrgMFAPolicyParams struct {
+	// in:body
+	// required:true
+	Body mfa.SetOrgPolicyCommand `json:"body"`
+}
+
+// swagger:response getMFAStatusResponse
+type GetMFAStatusResponse struct {
+	// in: body
+	Body mfa.Status `json:"body"`
+}
+
+// swagger:response beginMFATOTPResponse
+type BeginMFATOTPResponse struct {
+	// in: body
+	Body BeginTOTPResult `json:"body"`
+}
+
+// swagger:response beginMFAWebAuthnRegistrationResponse
+type BeginMFAWebAuthnRegistrationResponse struct {
+	// in: body
+	Body map[string]any `json:"body"`
+}
+
+// swagger:response finishMFAWebAuthnRegistrationResponse
+type FinishMFAWebAuthnRegistrationResponse struct {
+	// in: body
+	Body mfa.WebAuthnCredential `json:"body"`
+}
+
+// swagger:response createMFARecoveryCodesResponse
+type CreateMFARecoveryCodesResponse struct {
+	// in: body
+	Body RecoveryCodesResult `json:"body"`
+}
+
+// swagger:response getOrgMFAPolicyResponse
+type GetOrgMFAPolicyResponse struct {
+	// in: body
+	Body mfa.OrgPolicy `json:"body"`
+}
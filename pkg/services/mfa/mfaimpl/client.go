@@ -0,0 +1,222 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxLoginAttempts is the number of invalid second factors accepted for a login before the
+// user has to enter the password again.
+const maxLoginAttempts = 5
+
+// metaKeyVerified marks the requests authenticated with a second factor.
+const metaKeyVerified = "mfaVerified"
+
+var (
+	errMFARequired = errutil.Unauthorized("mfa.required").MustTemplate(
+		"second factor required",
+		errutil.WithPublic("A second authentication factor is required"),
+	)
+	errLoginExpired = errutil.Unauthorized("mfa.login-expired", errutil.WithPublicMessage("The login has expired, please log in again"))
+	errLoginBlocked = errutil.Unauthorized("mfa.login-blocked", errutil.WithPublicMessage("Too many invalid verification codes, please try again later"))
+	errBasicAuth    = errutil.Unauthorized("mfa.basic-auth", errutil.WithPublicMessage("Basic authentication is not allowed for users with multi-factor authentication, use a service account token instead"))
+	errBadMFAForm   = errutil.BadRequest("mfa.invalid-form", errutil.WithPublicMessage("A verification code, a recovery code or a security key response is required"))
+)
+
+// loginHook requires a second factor for users who log in with a Grafana password and have a
+// second factor or are required to use one. The login is suspended until the second factor is
+// verified by the MFA client.
+func (s *Service) loginHook(ctx context.Context, id *authn.Identity, r *authn.Request) error {
+	// The password client is the only one setting the username
+	if id.AuthenticatedBy != login.PasswordAuthModule || r.GetMeta(authn.MetaKeyUsername) == "" || r.GetMeta(metaKeyVerified) == "true" {
+		return nil
+	}
+	// Disabled users are rejected without asking for the second factor
+	if id.IsDisabled {
+		return nil
+	}
+
+	userID, err := id.GetInternalID()
+	if err != nil {
+		return err
+	}
+	status, err := s.GetStatus(ctx, userID, id.GetIsGrafanaAdmin())
+	if err != nil {
+		return err
+	}
+	if !status.Enabled && !status.Required {
+		return nil
+	}
+
+	if r.GetMeta(authn.MetaKeyIsLogin) != "true" {
+		return errBasicAuth.Errorf("user %d has to use a second factor", userID)
+	}
+
+	token, err := generateLoginToken()
+	if err != nil {
+		return err
+	}
+	pending := &pendingLogin{
+		UserID:   userID,
+		Login:    id.Login,
+		Username: r.GetMeta(authn.MetaKeyUsername),
+		IP:       web.RemoteAddr(r.HTTPRequest),
+		Enroll:   !status.Enabled,
+		Expires:  s.now().Add(s.cfg.MFA.LoginTimeout).Unix(),
+	}
+	public := map[string]any{
+		"mfaToken":           token,
+		"methods":            status.Methods(),
+		"enrollmentRequired": pending.Enroll,
+	}
+
+	if pending.Enroll {
+		secret, url, err := s.beginTOTP(ctx, userID, id.Login)
+		if err != nil {
+			return err
+		}
+		public["totpSecret"] = secret
+		public["totpUrl"] = url
+	}
+	if len(status.WebAuthn) > 0 {
+		pending.Challenge, err = generateWebAuthnChallenge()
+		if err != nil {
+			return err
+		}
+		public["webauthn"], err = s.webAuthnRequestOptions(ctx, userID, pending.Challenge)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.savePendingLogin(ctx, token, pending); err != nil {
+		return err
+	}
+	return errMFARequired.Build(errutil.TemplateData{Public: public})
+}
+
+var _ authn.Client = new(Client)
+
+// Client completes the logins suspended by the login hook with a second factor.
+type Client struct {
+	service *Service
+}
+
+type loginForm struct {
+	Token        string             `json:"mfaToken" binding:"Required"`
+	Code         string             `json:"code"`
+	RecoveryCode string             `json:"recoveryCode"`
+	WebAuthn     *WebAuthnAssertion `json:"webauthn"`
+}
+
+func (c *Client) Name() string {
+	return authn.ClientMFA
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.service.cfg.MFA.Enabled
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	form := loginForm{}
+	if err := web.Bind(r.HTTPRequest, &form); err != nil {
+		return nil, errBadMFAForm.Errorf("failed to parse request: %w", err)
+	}
+	if form.Code == "" && form.RecoveryCode == "" && form.WebAuthn == nil {
+		return nil, errBadMFAForm.Errorf("no second factor provided")
+	}
+
+	s := c.service
+	pending, err := s.getPendingLogin(ctx, form.Token)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, errLoginExpired.Errorf("no pending login")
+		}
+		return nil, err
+	}
+	r.SetMeta(authn.MetaKeyUsername, pending.Username)
+
+	ok, err := s.loginAttempts.Validate(ctx, pending.Username)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errLoginBlocked.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
+	}
+
+	ok, err = c.verify(ctx, pending, &form)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		_ = s.loginAttempts.Add(ctx, pending.Username, web.RemoteAddr(r.HTTPRequest))
+		pending.Attempts++
+		if pending.Attempts >= maxLoginAttempts {
+			if err := s.cache.Delete(ctx, pendingLoginKey(form.Token)); err != nil {
+				return nil, err
+			}
+			return nil, errLoginExpired.Errorf("too many invalid second factors")
+		}
+		if err := s.savePendingLogin(ctx, form.Token, pending); err != nil {
+			return nil, err
+		}
+		return nil, mfa.ErrInvalidCode.Errorf("invalid second factor")
+	}
+
+	// A login can only be completed once
+	if err := s.cache.Delete(ctx, pendingLoginKey(form.Token)); err != nil {
+		return nil, err
+	}
+
+	r.SetMeta(authn.MetaKeyAuthModule, "grafana")
+	r.SetMeta(metaKeyVerified, "true")
+	return &authn.Identity{
+		ID:              strconv.FormatInt(pending.UserID, 10),
+		Type:            claims.TypeUser,
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: login.PasswordAuthModule,
+	}, nil
+}
+
+func (c *Client) verify(ctx context.Context, pending *pendingLogin, form *loginForm) (bool, error) {
+	s := c.service
+	switch {
+	case form.Code != "":
+		ok, err := s.verifyTOTP(ctx, pending.UserID, form.Code, pending.Enroll)
+		if errors.Is(err, mfa.ErrTOTPNotEnrolled) {
+			return false, nil
+		}
+		return ok, err
+	case pending.Enroll:
+		// Users enrolling during the login have neither security keys nor recovery codes
+		return false, nil
+	case form.RecoveryCode != "":
+		return s.verifyRecoveryCode(ctx, pending.UserID, form.RecoveryCode)
+	default:
+		if pending.Challenge == "" {
+			return false, nil
+		}
+		return s.verifyWebAuthn(ctx, pending.UserID, pending.Challenge, form.WebAuthn)
+	}
+}
+
+func generateLoginToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
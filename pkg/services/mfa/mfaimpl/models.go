@@ -0,0 +1,74 @@
+package mfaimpl
+
+import (
+	"time"
+)
+
+// userTOTP is the authenticator app of a user. The secret is encrypted and the code of a
+// step can only be used once.
+type userTOTP struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	UserID       int64     `xorm:"user_id"`
+	Secret       string    `xorm:"secret"`
+	Confirmed    bool      `xorm:"confirmed"`
+	LastUsedStep int64     `xorm:"last_used_step"`
+	Created      time.Time `xorm:"created"`
+}
+
+func (userTOTP) TableName() string {
+	return "user_mfa_totp"
+}
+
+type webAuthnCredential struct {
+	ID           int64      `xorm:"pk autoincr 'id'"`
+	UserID       int64      `xorm:"user_id"`
+	Name         string     `xorm:"name"`
+	CredentialID string     `xorm:"credential_id"`
+	PublicKey    string     `xorm:"public_key"`
+	SignCount    int64      `xorm:"sign_count"`
+	Created      time.Time  `xorm:"created"`
+	LastUsed     *time.Time `xorm:"last_used"`
+}
+
+func (webAuthnCredential) TableName() string {
+	return "user_mfa_webauthn_credential"
+}
+
+type recoveryCode struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	UserID   int64     `xorm:"user_id"`
+	CodeHash string    `xorm:"code_hash"`
+	Salt     string    `xorm:"salt"`
+	Used     bool      `xorm:"used"`
+	Created  time.Time `xorm:"created"`
+}
+
+func (recoveryCode) TableName() string {
+	return "user_mfa_recovery_code"
+}
+
+type orgPolicy struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	RequiredRole string    `xorm:"required_role"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (orgPolicy) TableName() string {
+	return "org_mfa_policy"
+}
+
+// pendingLogin is a login waiting for the second factor, stored in the remote cache.
+type pendingLogin struct {
+	UserID   int64  `json:"userId"`
+	Login    string `json:"login"`
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+	// Attempts is the number of invalid second factors provided.
+	Attempts int `json:"attempts"`
+	// Challenge is the WebAuthn challenge of the login.
+	Challenge string `json:"challenge,omitempty"`
+	// Enroll is true if the user has to enroll an authenticator app to complete the login.
+	Enroll  bool  `json:"enroll,omitempty"`
+	Expires int64 `json:"expires"`
+}
@@ -0,0 +1,57 @@
+package mfaimpl
+
+import (
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	recoveryCodeCount = 10
+	// recoveryCodeAlphabet omits characters which are easily confused.
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// generateRecoveryCodes returns the recovery codes to show to the user once, and their hashes
+// to store.
+func generateRecoveryCodes(userID int64, now time.Time) ([]string, []*recoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]*recoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(10, []byte(recoveryCodeAlphabet)...)
+		if err != nil {
+			return nil, nil, err
+		}
+		code = code[:5] + "-" + code[5:]
+
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return nil, nil, err
+		}
+		hash, err := util.EncodePassword(code, salt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code)
+		rows = append(rows, &recoveryCode{UserID: userID, CodeHash: hash, Salt: salt, Created: now})
+	}
+	return codes, rows, nil
+}
+
+// matchRecoveryCode returns the stored recovery code matching the code entered by the user.
+func matchRecoveryCode(code string, rows []*recoveryCode) *recoveryCode {
+	code = strings.ToLower(strings.TrimSpace(code))
+	for _, row := range rows {
+		hash, err := util.EncodePassword(code, row.Salt)
+		if err != nil {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(row.CodeHash)) == 1 {
+			return row
+		}
+	}
+	return nil
+}
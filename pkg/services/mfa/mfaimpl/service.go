@@ -0,0 +1,446 @@
+package mfaimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// webAuthnRegistrationTimeout is the time a user has to register a security key.
+const webAuthnRegistrationTimeout = 5 * time.Minute
+
+var _ mfa.Service = (*Service)(nil)
+
+type Service struct {
+	cfg           *setting.Cfg
+	store         *store
+	log           log.Logger
+	cache         remotecache.CacheStorage
+	secrets       secrets.Service
+	loginAttempts loginattempt.Service
+	orgService    org.Service
+	webAuthn      *webAuthnVerifier
+	now           func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, authnService authn.Service,
+	accessControl accesscontrol.AccessControl, cache *remotecache.RemoteCache, secretsService secrets.Service,
+	loginAttempts loginattempt.Service, orgService org.Service) *Service {
+	s := &Service{
+		cfg:           cfg,
+		store:         &store{db: sqlStore},
+		log:           log.New("mfa"),
+		cache:         cache,
+		secrets:       secretsService,
+		loginAttempts: loginAttempts,
+		orgService:    orgService,
+		webAuthn:      &webAuthnVerifier{rpID: cfg.MFA.WebAuthnRPID, origins: cfg.MFA.WebAuthnOrigins},
+		now:           time.Now,
+	}
+
+	if cfg.MFA.Enabled {
+		authnService.RegisterClient(&Client{service: s})
+		// After the user has been fetched, so that the identity knows if the user is a server admin
+		authnService.RegisterPostAuthHook(s.loginHook, 105)
+		s.registerAPIEndpoints(routeRegister, accessControl)
+	}
+	return s
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64, isServerAdmin bool) (*mfa.Status, error) {
+	required, err := s.IsRequired(ctx, userID, isServerAdmin)
+	if err != nil {
+		return nil, err
+	}
+	status := &mfa.Status{Required: required, WebAuthn: make([]mfa.WebAuthnCredential, 0)}
+
+	totp, err := s.store.getTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.TOTP = totp != nil && totp.Confirmed
+
+	credentials, err := s.store.getWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range credentials {
+		status.WebAuthn = append(status.WebAuthn, mfa.WebAuthnCredential{ID: c.ID, Name: c.Name, Created: c.Created, LastUsed: c.LastUsed})
+	}
+
+	status.RecoveryCodesRemaining, err = s.store.countUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = status.TOTP || len(status.WebAuthn) > 0
+	return status, nil
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64, isServerAdmin bool) (bool, error) {
+	if !s.cfg.MFA.Enabled {
+		return false, nil
+	}
+	if isServerAdmin && s.cfg.MFA.RequiredForServerAdmins {
+		return true, nil
+	}
+
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	roles := make(map[int64]org.RoleType, len(orgs))
+	orgIDs := make([]int64, 0, len(orgs))
+	for _, o := range orgs {
+		roles[o.OrgID] = o.Role
+		orgIDs = append(orgIDs, o.OrgID)
+	}
+
+	policies, err := s.store.getOrgPolicies(ctx, orgIDs)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range policies {
+		requiredRole := org.RoleType(p.RequiredRole)
+		if requiredRole != "" && roles[p.OrgID].Includes(requiredRole) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	if err := s.store.deleteUser(ctx, userID); err != nil {
+		return err
+	}
+	s.log.FromContext(ctx).Info("Reset multi-factor authentication", "userId", userID)
+	return nil
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	policies, err := s.store.getOrgPolicies(ctx, []int64{orgID})
+	if err != nil {
+		return nil, err
+	}
+	policy := &mfa.OrgPolicy{OrgID: orgID}
+	if len(policies) > 0 {
+		policy.RequiredRole = org.RoleType(policies[0].RequiredRole)
+	}
+	return policy, nil
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, cmd *mfa.SetOrgPolicyCommand) error {
+	if err := cmd.Validate(); err != nil {
+		return err
+	}
+	return s.store.setOrgPolicy(ctx, &orgPolicy{OrgID: cmd.OrgID, RequiredRole: string(cmd.RequiredRole), Updated: s.now()})
+}
+
+// beginTOTP creates an authenticator app secret for the user, which has to be confirmed with
+// a code before it can be used to log in.
+func (s *Service) beginTOTP(ctx context.Context, userID int64, account string) (string, string, error) {
+	existing, err := s.store.getTOTP(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if existing != nil && existing.Confirmed {
+		return "", "", mfa.ErrTOTPAlreadyEnrolled.Errorf("user %d already has an authenticator app", userID)
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.store.saveTOTP(ctx, &userTOTP{
+		UserID:  userID,
+		Secret:  base64.StdEncoding.EncodeToString(encrypted),
+		Created: s.now(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+	return secret, totpURL(s.cfg.MFA.Issuer, account, secret), nil
+}
+
+// verifyTOTP verifies a code of the authenticator app of the user. A code of an authenticator
+// app being enrolled confirms it.
+func (s *Service) verifyTOTP(ctx context.Context, userID int64, code string, enroll bool) (bool, error) {
+	totp, err := s.store.getTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if totp == nil || totp.Confirmed == enroll {
+		return false, mfa.ErrTOTPNotEnrolled.Errorf("no authenticator app to verify for user %d", userID)
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(totp.Secret)
+	if err != nil {
+		return false, err
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := validateTOTP(string(secret), code, s.now(), totp.LastUsedStep)
+	if !ok {
+		return false, nil
+	}
+	return s.store.useTOTP(ctx, userID, step)
+}
+
+func (s *Service) removeTOTP(ctx context.Context, userID int64, isServerAdmin bool) error {
+	status, err := s.GetStatus(ctx, userID, isServerAdmin)
+	if err != nil {
+		return err
+	}
+	if status.Required && status.TOTP && len(status.WebAuthn) == 0 {
+		return mfa.ErrLastFactor.Errorf("user %d requires a second factor", userID)
+	}
+	if err := s.store.deleteTOTP(ctx, userID); err != nil {
+		return err
+	}
+	return s.deleteUnusableRecoveryCodes(ctx, userID, status.WebAuthn)
+}
+
+func (s *Service) beginWebAuthnRegistration(ctx context.Context, userID int64, login, name string) (*webAuthnCreationOptions, error) {
+	challenge, err := generateWebAuthnChallenge()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, webAuthnRegistrationKey(userID), []byte(challenge), webAuthnRegistrationTimeout); err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.store.getWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	exclude := make([]webAuthnCredentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		exclude = append(exclude, webAuthnCredentialDescriptor{Type: "public-key", ID: c.CredentialID})
+	}
+
+	return &webAuthnCreationOptions{
+		Challenge: challenge,
+		RP:        webAuthnRelyingParty{ID: s.cfg.MFA.WebAuthnRPID, Name: s.cfg.MFA.Issuer},
+		User: webAuthnUser{
+			ID:          webAuthnEncoding.EncodeToString([]byte(strconv.FormatInt(userID, 10))),
+			Name:        login,
+			DisplayName: name,
+		},
+		PubKeyCredParams: []webAuthnCredentialParameter{
+			{Type: "public-key", Alg: coseAlgES256},
+			{Type: "public-key", Alg: coseAlgEdDSA},
+			{Type: "public-key", Alg: coseAlgRS256},
+		},
+		Timeout:                webAuthnTimeoutMs,
+		Attestation:            "none",
+		ExcludeCredentials:     exclude,
+		AuthenticatorSelection: map[string]string{"userVerification": "discouraged"},
+	}, nil
+}
+
+func (s *Service) finishWebAuthnRegistration(ctx context.Context, userID int64, name string, attestation *WebAuthnAttestation) (*mfa.WebAuthnCredential, error) {
+	challenge, err := s.cache.Get(ctx, webAuthnRegistrationKey(userID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrInvalidCredential.Errorf("no security key is being registered")
+		}
+		return nil, err
+	}
+	// A challenge can only be used once
+	if err := s.cache.Delete(ctx, webAuthnRegistrationKey(userID)); err != nil {
+		return nil, err
+	}
+
+	registered, err := s.webAuthn.verifyAttestation(attestation, string(challenge))
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to verify security key: %w", err)
+	}
+	exists, err := s.store.webAuthnCredentialExists(ctx, registered.credentialID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, mfa.ErrInvalidCredential.Errorf("security key is already registered")
+	}
+
+	credential := &webAuthnCredential{
+		UserID:       userID,
+		Name:         name,
+		CredentialID: registered.credentialID,
+		PublicKey:    registered.publicKey,
+		SignCount:    int64(registered.signCount),
+		Created:      s.now(),
+	}
+	if err := s.store.addWebAuthnCredential(ctx, credential); err != nil {
+		return nil, err
+	}
+	return &mfa.WebAuthnCredential{ID: credential.ID, Name: credential.Name, Created: credential.Created}, nil
+}
+
+func (s *Service) removeWebAuthnCredential(ctx context.Context, userID int64, isServerAdmin bool, id int64) error {
+	status, err := s.GetStatus(ctx, userID, isServerAdmin)
+	if err != nil {
+		return err
+	}
+	remaining := make([]mfa.WebAuthnCredential, 0, len(status.WebAuthn))
+	for _, c := range status.WebAuthn {
+		if c.ID != id {
+			remaining = append(remaining, c)
+		}
+	}
+	if len(remaining) == len(status.WebAuthn) {
+		return mfa.ErrCredentialNotFound.Errorf("security key %d not found", id)
+	}
+	if status.Required && !status.TOTP && len(remaining) == 0 {
+		return mfa.ErrLastFactor.Errorf("user %d requires a second factor", userID)
+	}
+
+	deleted, err := s.store.deleteWebAuthnCredential(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return mfa.ErrCredentialNotFound.Errorf("security key %d not found", id)
+	}
+	if status.TOTP {
+		return nil
+	}
+	return s.deleteUnusableRecoveryCodes(ctx, userID, remaining)
+}
+
+// verifyWebAuthn verifies the response of a security key of the user to the challenge.
+func (s *Service) verifyWebAuthn(ctx context.Context, userID int64, challenge string, assertion *WebAuthnAssertion) (bool, error) {
+	credentials, err := s.store.getWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range credentials {
+		if c.CredentialID != assertion.ID {
+			continue
+		}
+		signCount, err := s.webAuthn.verifyAssertion(assertion, challenge, c.PublicKey, uint32(c.SignCount))
+		if err != nil {
+			s.log.FromContext(ctx).Warn("Failed to verify security key", "userId", userID, "credential", c.ID, "error", err)
+			return false, nil
+		}
+		return s.store.useWebAuthnCredential(ctx, c, int64(signCount), s.now())
+	}
+	return false, nil
+}
+
+func (s *Service) webAuthnRequestOptions(ctx context.Context, userID int64, challenge string) (*webAuthnRequestOptions, error) {
+	credentials, err := s.store.getWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	allow := make([]webAuthnCredentialDescriptor, 0, len(credentials))
+	for _, c := range credentials {
+		allow = append(allow, webAuthnCredentialDescriptor{Type: "public-key", ID: c.CredentialID})
+	}
+	return &webAuthnRequestOptions{
+		Challenge:        challenge,
+		RPID:             s.cfg.MFA.WebAuthnRPID,
+		Timeout:          webAuthnTimeoutMs,
+		AllowCredentials: allow,
+		UserVerification: "discouraged",
+	}, nil
+}
+
+// newRecoveryCodes replaces the recovery codes of the user. Recovery codes can only be
+// created by users with a second factor.
+func (s *Service) newRecoveryCodes(ctx context.Context, userID int64, isServerAdmin bool) ([]string, error) {
+	status, err := s.GetStatus(ctx, userID, isServerAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if !status.Enabled {
+		return nil, mfa.ErrNoFactor.Errorf("user %d has no second factor", userID)
+	}
+
+	codes, rows, err := generateRecoveryCodes(userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.replaceRecoveryCodes(ctx, userID, rows); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) verifyRecoveryCode(ctx context.Context, userID int64, code string) (bool, error) {
+	rows, err := s.store.getUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	row := matchRecoveryCode(code, rows)
+	if row == nil {
+		return false, nil
+	}
+	return s.store.useRecoveryCode(ctx, row.ID)
+}
+
+// deleteUnusableRecoveryCodes deletes the recovery codes of a user without a second factor,
+// so that they cannot be used if a second factor is enrolled later.
+func (s *Service) deleteUnusableRecoveryCodes(ctx context.Context, userID int64, webAuthn []mfa.WebAuthnCredential) error {
+	if len(webAuthn) > 0 {
+		return nil
+	}
+	return s.store.replaceRecoveryCodes(ctx, userID, nil)
+}
+
+func (s *Service) getPendingLogin(ctx context.Context, token string) (*pendingLogin, error) {
+	data, err := s.cache.Get(ctx, pendingLoginKey(token))
+	if err != nil {
+		return nil, err
+	}
+	pending := &pendingLogin{}
+	if err := json.Unmarshal(data, pending); err != nil {
+		return nil, err
+	}
+	if s.now().Unix() >= pending.Expires {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return pending, nil
+}
+
+func (s *Service) savePendingLogin(ctx context.Context, token string, pending *pendingLogin) error {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	expire := time.Unix(pending.Expires, 0).Sub(s.now())
+	if expire <= 0 {
+		return s.cache.Delete(ctx, pendingLoginKey(token))
+	}
+	return s.cache.Set(ctx, pendingLoginKey(token), data, expire)
+}
+
+func webAuthnRegistrationKey(userID int64) string {
+	return "mfa-webauthn-registration-" + strconv.FormatInt(userID, 10)
+}
+
+func pendingLoginKey(token string) string {
+	return "mfa-login-" + token
+}
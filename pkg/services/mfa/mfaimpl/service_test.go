@@ -0,0 +1,263 @@
+package mfaimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/authlib/claims"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationMFA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	s, orgService := newTestService(t)
+
+	t.Run("enroll an authenticator app", func(t *testing.T) {
+		status, err := s.GetStatus(ctx, 1, false)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+
+		secret, url, err := s.beginTOTP(ctx, 1, "admin")
+		require.NoError(t, err)
+		require.Contains(t, url, secret)
+
+		ok, err := s.verifyTOTP(ctx, 1, "000000", true)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = s.verifyTOTP(ctx, 1, code(t, s, secret), true)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		status, err = s.GetStatus(ctx, 1, false)
+		require.NoError(t, err)
+		require.True(t, status.Enabled)
+		require.Equal(t, []mfa.Method{mfa.MethodTOTP}, status.Methods())
+
+		_, _, err = s.beginTOTP(ctx, 1, "admin")
+		require.ErrorIs(t, err, mfa.ErrTOTPAlreadyEnrolled)
+
+		// The code was used to confirm the enrollment
+		s.now = func() time.Time { return time.Unix(1700000030, 0) }
+		ok, err = s.verifyTOTP(ctx, 1, code(t, s, secret), false)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("log in with a second factor", func(t *testing.T) {
+		token := requireMFA(t, s, 1)
+		client := &Client{service: s}
+
+		_, err := client.Authenticate(ctx, mfaRequest(t, map[string]any{"mfaToken": token, "code": "000000"}))
+		require.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+		s.now = func() time.Time { return time.Unix(1700000060, 0) }
+		secret := totpSecret(t, s, 1)
+		r := mfaRequest(t, map[string]any{"mfaToken": token, "code": code(t, s, secret)})
+		id, err := client.Authenticate(ctx, r)
+		require.NoError(t, err)
+		require.Equal(t, "1", id.ID)
+		require.Equal(t, login.PasswordAuthModule, id.AuthenticatedBy)
+		require.Equal(t, "true", r.GetMeta(metaKeyVerified))
+
+		// A login can only be completed once
+		_, err = client.Authenticate(ctx, mfaRequest(t, map[string]any{"mfaToken": token, "code": code(t, s, secret)}))
+		require.ErrorIs(t, err, errLoginExpired)
+	})
+
+	t.Run("expire the login after too many invalid second factors", func(t *testing.T) {
+		token := requireMFA(t, s, 1)
+		client := &Client{service: s}
+		for i := 0; i < maxLoginAttempts-1; i++ {
+			_, err := client.Authenticate(ctx, mfaRequest(t, map[string]any{"mfaToken": token, "code": "000000"}))
+			require.ErrorIs(t, err, mfa.ErrInvalidCode)
+		}
+		_, err := client.Authenticate(ctx, mfaRequest(t, map[string]any{"mfaToken": token, "code": "000000"}))
+		require.ErrorIs(t, err, errLoginExpired)
+	})
+
+	t.Run("reject basic authentication of users with a second factor", func(t *testing.T) {
+		r := &authn.Request{HTTPRequest: &http.Request{Header: http.Header{}}}
+		r.SetMeta(authn.MetaKeyUsername, "admin")
+		err := s.loginHook(ctx, passwordIdentity(1), r)
+		require.ErrorIs(t, err, errBasicAuth)
+	})
+
+	t.Run("log in with recovery codes once", func(t *testing.T) {
+		codes, err := s.newRecoveryCodes(ctx, 1, false)
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+
+		ok, err := s.verifyRecoveryCode(ctx, 1, codes[0])
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = s.verifyRecoveryCode(ctx, 1, codes[0])
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		status, err := s.GetStatus(ctx, 1, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(recoveryCodeCount-1), status.RecoveryCodesRemaining)
+	})
+
+	t.Run("require a second factor with an organization policy", func(t *testing.T) {
+		orgService.ExpectedUserOrgDTO = []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleEditor}}
+
+		require.NoError(t, s.SetOrgPolicy(ctx, &mfa.SetOrgPolicyCommand{OrgID: 1, RequiredRole: org.RoleAdmin}))
+		required, err := s.IsRequired(ctx, 1, false)
+		require.NoError(t, err)
+		require.False(t, required)
+
+		require.NoError(t, s.SetOrgPolicy(ctx, &mfa.SetOrgPolicyCommand{OrgID: 1, RequiredRole: org.RoleViewer}))
+		required, err = s.IsRequired(ctx, 1, false)
+		require.NoError(t, err)
+		require.True(t, required)
+
+		err = s.SetOrgPolicy(ctx, &mfa.SetOrgPolicyCommand{OrgID: 1, RequiredRole: "Owner"})
+		require.ErrorIs(t, err, mfa.ErrInvalidPolicyRole)
+
+		// The last second factor cannot be removed
+		err = s.removeTOTP(ctx, 1, false)
+		require.ErrorIs(t, err, mfa.ErrLastFactor)
+	})
+
+	t.Run("enroll users required to use a second factor when they log in", func(t *testing.T) {
+		s.cfg.MFA.RequiredForServerAdmins = true
+		r := loginRequest()
+		err := s.loginHook(ctx, serverAdminIdentity(2), r)
+		public := requirePublicPayload(t, err)
+		require.Equal(t, true, public["enrollmentRequired"])
+		secret, ok := public["totpSecret"].(string)
+		require.True(t, ok)
+
+		id, err := (&Client{service: s}).Authenticate(ctx, mfaRequest(t, map[string]any{"mfaToken": public["mfaToken"], "code": code(t, s, secret)}))
+		require.NoError(t, err)
+		require.Equal(t, "2", id.ID)
+
+		status, err := s.GetStatus(ctx, 2, true)
+		require.NoError(t, err)
+		require.True(t, status.Required)
+		require.True(t, status.TOTP)
+	})
+
+	t.Run("reset the second factors of a user", func(t *testing.T) {
+		require.NoError(t, s.Reset(ctx, 1))
+		orgService.ExpectedUserOrgDTO = nil
+
+		status, err := s.GetStatus(ctx, 1, false)
+		require.NoError(t, err)
+		require.False(t, status.Enabled)
+		require.Zero(t, status.RecoveryCodesRemaining)
+		require.NoError(t, s.loginHook(ctx, passwordIdentity(1), loginRequest()))
+	})
+}
+
+func newTestService(t *testing.T) (*Service, *orgtest.FakeOrgService) {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.MFA = setting.MFASettings{Enabled: true, Issuer: "Grafana", LoginTimeout: 5 * time.Minute}
+	orgService := orgtest.NewOrgServiceFake()
+	return &Service{
+		cfg:           cfg,
+		store:         &store{db: db.InitTestDB(t)},
+		log:           log.NewNopLogger(),
+		cache:         remotecache.NewFakeCacheStorage(),
+		secrets:       fakes.NewFakeSecretsService(),
+		loginAttempts: loginattempttest.FakeLoginAttemptService{ExpectedValid: true},
+		orgService:    orgService,
+		webAuthn:      &webAuthnVerifier{rpID: "localhost", origins: []string{"http://localhost:3000"}},
+		now:           func() time.Time { return time.Unix(1700000000, 0) },
+	}, orgService
+}
+
+func code(t *testing.T, s *Service, secret string) string {
+	t.Helper()
+	c, err := totpCode(secret, totpStep(s.now()))
+	require.NoError(t, err)
+	return c
+}
+
+func totpSecret(t *testing.T, s *Service, userID int64) string {
+	t.Helper()
+	totp, err := s.store.getTOTP(context.Background(), userID)
+	require.NoError(t, err)
+	// The fake secrets service does not encrypt
+	secret, err := base64.StdEncoding.DecodeString(totp.Secret)
+	require.NoError(t, err)
+	return string(secret)
+}
+
+func passwordIdentity(userID int64) *authn.Identity {
+	return &authn.Identity{ID: strconv.FormatInt(userID, 10), Type: claims.TypeUser, Login: "admin", AuthenticatedBy: login.PasswordAuthModule}
+}
+
+func serverAdminIdentity(userID int64) *authn.Identity {
+	id := passwordIdentity(userID)
+	isAdmin := true
+	id.IsGrafanaAdmin = &isAdmin
+	return id
+}
+
+func loginRequest() *authn.Request {
+	r := &authn.Request{HTTPRequest: &http.Request{Header: http.Header{}, RemoteAddr: "127.0.0.1:1234"}}
+	r.SetMeta(authn.MetaKeyUsername, "admin")
+	r.SetMeta(authn.MetaKeyIsLogin, "true")
+	return r
+}
+
+func mfaRequest(t *testing.T, body map[string]any) *authn.Request {
+	t.Helper()
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	req, err := http.NewRequest(http.MethodPost, "/login/mfa", bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	return &authn.Request{HTTPRequest: req}
+}
+
+func requireMFA(t *testing.T, s *Service, userID int64) string {
+	t.Helper()
+	err := s.loginHook(context.Background(), passwordIdentity(userID), loginRequest())
+	public := requirePublicPayload(t, err)
+	token, ok := public["mfaToken"].(string)
+	require.True(t, ok)
+	return token
+}
+
+func requirePublicPayload(t *testing.T, err error) map[string]any {
+	t.Helper()
+	require.ErrorIs(t, err, errMFARequired)
+	var e errutil.Error
+	require.True(t, errors.As(err, &e))
+	return e.PublicPayload
+}
@@ -0,0 +1,197 @@
+package mfaimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) getTOTP(ctx context.Context, userID int64) (*userTOTP, error) {
+	var result *userTOTP
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		row := &userTOTP{}
+		exists, err := sess.Where("user_id = ?", userID).Get(row)
+		if err != nil || !exists {
+			return err
+		}
+		result = row
+		return nil
+	})
+	return result, err
+}
+
+// saveTOTP replaces the authenticator app of the user.
+func (s *store) saveTOTP(ctx context.Context, totp *userTOTP) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("user_id = ?", totp.UserID).Delete(&userTOTP{}); err != nil {
+			return err
+		}
+		_, err := sess.Insert(totp)
+		return err
+	})
+}
+
+// useTOTP confirms the authenticator app and records the step of the used code. It returns
+// false if a code of the same or a later step was used in the meantime.
+func (s *store) useTOTP(ctx context.Context, userID, step int64) (bool, error) {
+	var ok bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa_totp SET confirmed = ?, last_used_step = ? WHERE user_id = ? AND last_used_step < ?", true, step, userID, step)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		ok = affected > 0
+		return err
+	})
+	return ok, err
+}
+
+func (s *store) deleteTOTP(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("user_id = ?", userID).Delete(&userTOTP{})
+		return err
+	})
+}
+
+func (s *store) getWebAuthnCredentials(ctx context.Context, userID int64) ([]*webAuthnCredential, error) {
+	result := make([]*webAuthnCredential, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("id").Find(&result)
+	})
+	return result, err
+}
+
+func (s *store) addWebAuthnCredential(ctx context.Context, credential *webAuthnCredential) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(credential)
+		return err
+	})
+}
+
+func (s *store) webAuthnCredentialExists(ctx context.Context, credentialID string) (bool, error) {
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Table("user_mfa_webauthn_credential").Where("credential_id = ?", credentialID).Exist()
+		return err
+	})
+	return exists, err
+}
+
+// useWebAuthnCredential records the signature counter of a login. It returns false if the
+// counter was updated in the meantime.
+func (s *store) useWebAuthnCredential(ctx context.Context, credential *webAuthnCredential, signCount int64, now time.Time) (bool, error) {
+	var ok bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa_webauthn_credential SET sign_count = ?, last_used = ? WHERE id = ? AND sign_count = ?",
+			signCount, now, credential.ID, credential.SignCount)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		ok = affected > 0
+		return err
+	})
+	return ok, err
+}
+
+func (s *store) deleteWebAuthnCredential(ctx context.Context, userID, id int64) (bool, error) {
+	var deleted bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("user_id = ? AND id = ?", userID, id).Delete(&webAuthnCredential{})
+		deleted = affected > 0
+		return err
+	})
+	return deleted, err
+}
+
+func (s *store) getUnusedRecoveryCodes(ctx context.Context, userID int64) ([]*recoveryCode, error) {
+	result := make([]*recoveryCode, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ? AND used = ?", userID, false).Find(&result)
+	})
+	return result, err
+}
+
+func (s *store) countUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("user_id = ? AND used = ?", userID, false).Count(&recoveryCode{})
+		return err
+	})
+	return count, err
+}
+
+// replaceRecoveryCodes replaces all the recovery codes of the user.
+func (s *store) replaceRecoveryCodes(ctx context.Context, userID int64, codes []*recoveryCode) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("user_id = ?", userID).Delete(&recoveryCode{}); err != nil {
+			return err
+		}
+		for _, code := range codes {
+			if _, err := sess.Insert(code); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// useRecoveryCode marks the recovery code as used. It returns false if it was used in the
+// meantime.
+func (s *store) useRecoveryCode(ctx context.Context, id int64) (bool, error) {
+	var ok bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa_recovery_code SET used = ? WHERE id = ? AND used = ?", true, id, false)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		ok = affected > 0
+		return err
+	})
+	return ok, err
+}
+
+// deleteUser deletes all the second factors of the user.
+func (s *store) deleteUser(ctx context.Context, userID int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		for _, bean := range []any{&userTOTP{}, &webAuthnCredential{}, &recoveryCode{}} {
+			if _, err := sess.Where("user_id = ?", userID).Delete(bean); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *store) getOrgPolicies(ctx context.Context, orgIDs []int64) ([]*orgPolicy, error) {
+	result := make([]*orgPolicy, 0)
+	if len(orgIDs) == 0 {
+		return result, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.In("org_id", orgIDs).Find(&result)
+	})
+	return result, err
+}
+
+func (s *store) setOrgPolicy(ctx context.Context, policy *orgPolicy) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ?", policy.OrgID).Delete(&orgPolicy{}); err != nil {
+			return err
+		}
+		if policy.RequiredRole == "" {
+			return nil
+		}
+		_, err := sess.Insert(policy)
+		return err
+	})
+}
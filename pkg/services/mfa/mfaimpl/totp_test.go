@@ -0,0 +1,57 @@
+package mfaimpl
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP(t *testing.T) {
+	// The SHA1 test vectors of RFC 6238, truncated to 6 digits
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+	for unix, expected := range vectors {
+		code, err := totpCode(secret, totpStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, unix)
+	}
+
+	t.Run("accept codes of the adjacent periods once", func(t *testing.T) {
+		now := time.Unix(1111111111, 0)
+		previous, err := totpCode(secret, totpStep(now)-1)
+		require.NoError(t, err)
+
+		step, ok := validateTOTP(secret, previous, now, 0)
+		require.True(t, ok)
+		require.Equal(t, totpStep(now)-1, step)
+
+		_, ok = validateTOTP(secret, previous, now, step)
+		require.False(t, ok)
+	})
+
+	t.Run("reject codes of other periods", func(t *testing.T) {
+		now := time.Unix(1111111111, 0)
+		old, err := totpCode(secret, totpStep(now)-2)
+		require.NoError(t, err)
+		_, ok := validateTOTP(secret, old, now, 0)
+		require.False(t, ok)
+		_, ok = validateTOTP(secret, "12345", now, 0)
+		require.False(t, ok)
+	})
+
+	t.Run("generate secrets", func(t *testing.T) {
+		secret, err := generateTOTPSecret()
+		require.NoError(t, err)
+		require.Len(t, secret, 32)
+		require.Contains(t, totpURL("Grafana", "admin@localhost", secret), "otpauth://totp/Grafana:admin@localhost?")
+	})
+}
@@ -0,0 +1,387 @@
+package mfaimpl
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// The subset of WebAuthn (https://www.w3.org/TR/webauthn-2/) needed to use security keys as a
+// second factor. Attestation statements are not verified, the credentials are trusted on
+// registration like TOTP secrets.
+
+const (
+	webAuthnChallengeSize = 32
+	webAuthnTimeoutMs     = 120000
+
+	// COSE algorithms (https://www.iana.org/assignments/cose/cose.xhtml)
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+
+	// COSE key types
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	// Authenticator data flags
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+)
+
+var webAuthnEncoding = base64.RawURLEncoding
+
+// webAuthnCBOR decodes attestation objects and COSE keys, which authenticators encode with the
+// CTAP2 canonical CBOR encoding.
+var webAuthnCBOR = func() cbor.DecMode {
+	dm, err := cbor.DecOptions{
+		DupMapKey:       cbor.DupMapKeyEnforcedAPF,
+		IndefLength:     cbor.IndefLengthForbidden,
+		MaxNestedLevels: 8,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+	return dm
+}()
+
+var (
+	errWebAuthnClientData = errors.New("invalid client data")
+	errWebAuthnAuthData   = errors.New("invalid authenticator data")
+	errWebAuthnPublicKey  = errors.New("unsupported public key")
+	errWebAuthnSignature  = errors.New("invalid signature")
+	errWebAuthnSignCount  = errors.New("signature counter did not increase, the security key may have been cloned")
+)
+
+type webAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type webAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type webAuthnUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type webAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+// webAuthnCreationOptions are the options of navigator.credentials.create, with binary
+// values encoded in base64url.
+type webAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     webAuthnRelyingParty           `json:"rp"`
+	User                   webAuthnUser                   `json:"user"`
+	PubKeyCredParams       []webAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int                            `json:"timeout"`
+	Attestation            string                         `json:"attestation"`
+	ExcludeCredentials     []webAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection map[string]string              `json:"authenticatorSelection"`
+}
+
+// webAuthnRequestOptions are the options of navigator.credentials.get, with binary values
+// encoded in base64url.
+type webAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPID             string                         `json:"rpId"`
+	Timeout          int                            `json:"timeout"`
+	AllowCredentials []webAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+// WebAuthnAttestation is the response of a security key to navigator.credentials.create.
+type WebAuthnAttestation struct {
+	ID       string `json:"id"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+	} `json:"response"`
+}
+
+// WebAuthnAssertion is the response of a security key to navigator.credentials.get.
+type WebAuthnAssertion struct {
+	ID       string `json:"id"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+	} `json:"response"`
+}
+
+type webAuthnClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// webAuthnAttestationObject is the attestation object of a registration, the attestation
+// statement is not verified.
+type webAuthnAttestationObject struct {
+	AuthData []byte `cbor:"authData"`
+}
+
+type webAuthnAuthData struct {
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+// registeredCredential is a security key verified on registration.
+type registeredCredential struct {
+	credentialID string
+	publicKey    string
+	signCount    uint32
+}
+
+func generateWebAuthnChallenge() (string, error) {
+	challenge := make([]byte, webAuthnChallengeSize)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", err
+	}
+	return webAuthnEncoding.EncodeToString(challenge), nil
+}
+
+type webAuthnVerifier struct {
+	rpID    string
+	origins []string
+}
+
+// verifyClientData checks the type, the challenge and the origin of the client data.
+func (v *webAuthnVerifier) verifyClientData(encoded, typ, challenge string) ([]byte, error) {
+	raw, err := webAuthnEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errWebAuthnClientData
+	}
+	clientData := webAuthnClientData{}
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return nil, errWebAuthnClientData
+	}
+	if clientData.Type != typ {
+		return nil, fmt.Errorf("%w: unexpected type %q", errWebAuthnClientData, clientData.Type)
+	}
+	if challenge == "" || clientData.Challenge != challenge {
+		return nil, fmt.Errorf("%w: unexpected challenge", errWebAuthnClientData)
+	}
+	if !slices.Contains(v.origins, clientData.Origin) {
+		return nil, fmt.Errorf("%w: unexpected origin %q", errWebAuthnClientData, clientData.Origin)
+	}
+	return raw, nil
+}
+
+// verifyAuthData checks the relying party and the user presence of the authenticator data.
+func (v *webAuthnVerifier) verifyAuthData(raw []byte) (*webAuthnAuthData, error) {
+	// rpIdHash (32), flags (1) and signCount (4)
+	if len(raw) < 37 {
+		return nil, errWebAuthnAuthData
+	}
+	rpIDHash := sha256.Sum256([]byte(v.rpID))
+	if !bytes.Equal(raw[:32], rpIDHash[:]) {
+		return nil, fmt.Errorf("%w: unexpected relying party", errWebAuthnAuthData)
+	}
+
+	authData := &webAuthnAuthData{flags: raw[32], signCount: binary.BigEndian.Uint32(raw[33:37])}
+	if authData.flags&flagUserPresent == 0 {
+		return nil, fmt.Errorf("%w: user not present", errWebAuthnAuthData)
+	}
+
+	if authData.flags&flagAttestedCredentialData != 0 {
+		// aaguid (16) and credentialIdLength (2)
+		rest := raw[37:]
+		if len(rest) < 18 {
+			return nil, errWebAuthnAuthData
+		}
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if len(rest) < idLength {
+			return nil, errWebAuthnAuthData
+		}
+		authData.credentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		// The public key may be followed by extensions
+		var publicKey cbor.RawMessage
+		if _, err := webAuthnCBOR.UnmarshalFirst(rest, &publicKey); err != nil {
+			return nil, fmt.Errorf("%w: %w", errWebAuthnAuthData, err)
+		}
+		authData.publicKey = publicKey
+	}
+	return authData, nil
+}
+
+// verifyAttestation verifies the response of a security key to a registration and returns
+// the credential to store.
+func (v *webAuthnVerifier) verifyAttestation(attestation *WebAuthnAttestation, challenge string) (*registeredCredential, error) {
+	if _, err := v.verifyClientData(attestation.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	raw, err := webAuthnEncoding.DecodeString(attestation.Response.AttestationObject)
+	if err != nil {
+		return nil, errWebAuthnAuthData
+	}
+	object := webAuthnAttestationObject{}
+	if err := webAuthnCBOR.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("%w: %w", errWebAuthnAuthData, err)
+	}
+	if len(object.AuthData) == 0 {
+		return nil, errWebAuthnAuthData
+	}
+
+	authData, err := v.verifyAuthData(object.AuthData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.credentialID == nil {
+		return nil, fmt.Errorf("%w: missing credential", errWebAuthnAuthData)
+	}
+	if _, err := parseCOSEKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &registeredCredential{
+		credentialID: webAuthnEncoding.EncodeToString(authData.credentialID),
+		publicKey:    webAuthnEncoding.EncodeToString(authData.publicKey),
+		signCount:    authData.signCount,
+	}, nil
+}
+
+// verifyAssertion verifies the response of a security key to a login with the stored public
+// key and signature counter, and returns the new signature counter.
+func (v *webAuthnVerifier) verifyAssertion(assertion *WebAuthnAssertion, challenge, publicKey string, signCount uint32) (uint32, error) {
+	clientData, err := v.verifyClientData(assertion.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := webAuthnEncoding.DecodeString(assertion.Response.AuthenticatorData)
+	if err != nil {
+		return 0, errWebAuthnAuthData
+	}
+	authData, err := v.verifyAuthData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	rawKey, err := webAuthnEncoding.DecodeString(publicKey)
+	if err != nil {
+		return 0, errWebAuthnPublicKey
+	}
+	key, err := parseCOSEKey(rawKey)
+	if err != nil {
+		return 0, err
+	}
+	signature, err := webAuthnEncoding.DecodeString(assertion.Response.Signature)
+	if err != nil {
+		return 0, errWebAuthnSignature
+	}
+
+	clientDataHash := sha256.Sum256(clientData)
+	signed := append(append([]byte{}, rawAuthData...), clientDataHash[:]...)
+	if err := key.verify(signed, signature); err != nil {
+		return 0, err
+	}
+
+	// Security keys without a counter always return 0
+	if (authData.signCount != 0 || signCount != 0) && authData.signCount <= signCount {
+		return 0, errWebAuthnSignCount
+	}
+	return authData.signCount, nil
+}
+
+type coseKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+// coseKeyParameters are the parameters of a COSE key (RFC 8152). The meaning of the negative
+// labels depends on the key type: -1 is the curve of EC2 and OKP keys and the modulus of RSA keys.
+type coseKeyParameters struct {
+	Kty int64           `cbor:"1,keyasint"`
+	Alg int64           `cbor:"3,keyasint"`
+	P1  cbor.RawMessage `cbor:"-1,keyasint"`
+	P2  []byte          `cbor:"-2,keyasint"`
+	P3  []byte          `cbor:"-3,keyasint"`
+}
+
+func parseCOSEKey(raw []byte) (*coseKey, error) {
+	params := coseKeyParameters{}
+	if err := webAuthnCBOR.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("%w: %w", errWebAuthnPublicKey, err)
+	}
+	kty, alg, x, y := params.Kty, params.Alg, params.P2, params.P3
+	var crv int64
+	if kty != coseKtyRSA && len(params.P1) > 0 {
+		if err := webAuthnCBOR.Unmarshal(params.P1, &crv); err != nil {
+			return nil, fmt.Errorf("%w: %w", errWebAuthnPublicKey, err)
+		}
+	}
+
+	switch {
+	case kty == coseKtyEC2 && alg == coseAlgES256 && crv == 1:
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if len(x) != 32 || len(y) != 32 || !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errWebAuthnPublicKey
+		}
+		return &coseKey{alg: alg, key: key}, nil
+	case kty == coseKtyOKP && alg == coseAlgEdDSA && crv == 6:
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errWebAuthnPublicKey
+		}
+		return &coseKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKtyRSA && alg == coseAlgRS256:
+		// For RSA keys, -1 is the modulus and -2 the exponent
+		var n []byte
+		if err := webAuthnCBOR.Unmarshal(params.P1, &n); err != nil {
+			return nil, fmt.Errorf("%w: %w", errWebAuthnPublicKey, err)
+		}
+		e := new(big.Int).SetBytes(x)
+		if len(n) < 256 || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errWebAuthnPublicKey
+		}
+		return &coseKey{alg: alg, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(e.Int64())}}, nil
+	}
+	return nil, fmt.Errorf("%w: key type %d, algorithm %d", errWebAuthnPublicKey, kty, alg)
+}
+
+func (k *coseKey) verify(signed, signature []byte) error {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(signed)
+		if ecdsa.VerifyASN1(key, hash[:], signature) {
+			return nil
+		}
+	case ed25519.PublicKey:
+		if ed25519.Verify(key, signed, signature) {
+			return nil
+		}
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil {
+			return nil
+		}
+	}
+	return errWebAuthnSignature
+}
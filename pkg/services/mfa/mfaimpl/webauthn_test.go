@@ -0,0 +1,156 @@
+package mfaimpl
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCOSEKey(t *testing.T) {
+	key := newTestSecurityKey(t)
+	raw := key.publicKey(t)
+	parsed, err := parseCOSEKey(raw)
+	require.NoError(t, err)
+	require.Equal(t, int64(coseAlgES256), parsed.alg)
+
+	// Malformed and unsupported keys are rejected.
+	for name, invalid := range map[string][]byte{
+		"empty":              {},
+		"truncated":          raw[:len(raw)-1],
+		"indefinite length":  {0xbf, 0x01, 0x02, 0xff},
+		"duplicate map keys": {0xa2, 0x01, 0x02, 0x01, 0x02},
+		"unsupported key":    mustMarshalTestCBOR(t, map[int]any{1: coseKtyEC2, 3: -35}),
+		"point not on curve": mustMarshalTestCBOR(t, map[int]any{1: coseKtyEC2, 3: coseAlgES256, -1: 1, -2: make([]byte, 32), -3: make([]byte, 32)}),
+	} {
+		_, err := parseCOSEKey(invalid)
+		require.ErrorIs(t, err, errWebAuthnPublicKey, name)
+	}
+}
+
+func TestWebAuthn(t *testing.T) {
+	v := &webAuthnVerifier{rpID: "grafana.example.com", origins: []string{"https://grafana.example.com"}}
+	key := newTestSecurityKey(t)
+
+	challenge, err := generateWebAuthnChallenge()
+	require.NoError(t, err)
+
+	attestation := key.attest(t, v.rpID, v.origins[0], challenge)
+	credential, err := v.verifyAttestation(attestation, challenge)
+	require.NoError(t, err)
+	require.Equal(t, webAuthnEncoding.EncodeToString(key.id), credential.credentialID)
+
+	t.Run("reject registrations with another challenge or origin", func(t *testing.T) {
+		_, err := v.verifyAttestation(attestation, "other")
+		require.ErrorIs(t, err, errWebAuthnClientData)
+
+		_, err = v.verifyAttestation(key.attest(t, v.rpID, "https://evil.example.com", challenge), challenge)
+		require.ErrorIs(t, err, errWebAuthnClientData)
+
+		_, err = v.verifyAttestation(key.attest(t, "evil.example.com", v.origins[0], challenge), challenge)
+		require.ErrorIs(t, err, errWebAuthnAuthData)
+	})
+
+	t.Run("verify assertions", func(t *testing.T) {
+		signCount, err := v.verifyAssertion(key.assert(t, v.rpID, v.origins[0], challenge, 1), challenge, credential.publicKey, credential.signCount)
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), signCount)
+	})
+
+	t.Run("reject assertions with a signature counter which did not increase", func(t *testing.T) {
+		_, err := v.verifyAssertion(key.assert(t, v.rpID, v.origins[0], challenge, 1), challenge, credential.publicKey, 1)
+		require.ErrorIs(t, err, errWebAuthnSignCount)
+	})
+
+	t.Run("reject assertions of another key", func(t *testing.T) {
+		other := newTestSecurityKey(t)
+		_, err := v.verifyAssertion(other.assert(t, v.rpID, v.origins[0], challenge, 2), challenge, credential.publicKey, 1)
+		require.ErrorIs(t, err, errWebAuthnSignature)
+	})
+}
+
+type testSecurityKey struct {
+	id  []byte
+	key *ecdsa.PrivateKey
+}
+
+func newTestSecurityKey(t *testing.T) *testSecurityKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	require.NoError(t, err)
+	return &testSecurityKey{id: id, key: key}
+}
+
+func (k *testSecurityKey) authData(rpID string, flags byte, signCount uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append(rpIDHash[:], flags)
+	return binary.BigEndian.AppendUint32(data, signCount)
+}
+
+func (k *testSecurityKey) attest(t *testing.T, rpID, origin, challenge string) *WebAuthnAttestation {
+	t.Helper()
+	data := k.authData(rpID, flagUserPresent|flagAttestedCredentialData, 0)
+	data = append(data, make([]byte, 16)...)
+	data = binary.BigEndian.AppendUint16(data, uint16(len(k.id)))
+	data = append(data, k.id...)
+	data = append(data, k.publicKey(t)...)
+	object := mustMarshalTestCBOR(t, map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": data})
+
+	a := &WebAuthnAttestation{ID: webAuthnEncoding.EncodeToString(k.id)}
+	a.Response.ClientDataJSON = testClientData(t, "webauthn.create", challenge, origin)
+	a.Response.AttestationObject = webAuthnEncoding.EncodeToString(object)
+	return a
+}
+
+func (k *testSecurityKey) assert(t *testing.T, rpID, origin, challenge string, signCount uint32) *WebAuthnAssertion {
+	t.Helper()
+	data := k.authData(rpID, flagUserPresent, signCount)
+	clientData := testClientData(t, "webauthn.get", challenge, origin)
+	raw, err := webAuthnEncoding.DecodeString(clientData)
+	require.NoError(t, err)
+	clientDataHash := sha256.Sum256(raw)
+	hash := sha256.Sum256(append(append([]byte{}, data...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, k.key, hash[:])
+	require.NoError(t, err)
+
+	a := &WebAuthnAssertion{ID: webAuthnEncoding.EncodeToString(k.id)}
+	a.Response.ClientDataJSON = clientData
+	a.Response.AuthenticatorData = webAuthnEncoding.EncodeToString(data)
+	a.Response.Signature = webAuthnEncoding.EncodeToString(signature)
+	return a
+}
+
+func testClientData(t *testing.T, typ, challenge, origin string) string {
+	t.Helper()
+	raw, err := json.Marshal(webAuthnClientData{Type: typ, Challenge: challenge, Origin: origin})
+	require.NoError(t, err)
+	return webAuthnEncoding.EncodeToString(raw)
+}
+
+// publicKey returns the COSE encoding of the public key.
+func (k *testSecurityKey) publicKey(t *testing.T) []byte {
+	t.Helper()
+	return mustMarshalTestCBOR(t, map[int]any{
+		1:  coseKtyEC2,
+		3:  coseAlgES256,
+		-1: 1,
+		-2: k.key.X.FillBytes(make([]byte, 32)),
+		-3: k.key.Y.FillBytes(make([]byte, 32)),
+	})
+}
+
+func mustMarshalTestCBOR(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := cbor.Marshal(v)
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,36 @@
+package mfatest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+var _ mfa.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedStatus   *mfa.Status
+	ExpectedRequired bool
+	ExpectedPolicy   *mfa.OrgPolicy
+	ExpectedErr      error
+}
+
+func (f *FakeService) GetStatus(ctx context.Context, userID int64, isServerAdmin bool) (*mfa.Status, error) {
+	return f.ExpectedStatus, f.ExpectedErr
+}
+
+func (f *FakeService) IsRequired(ctx context.Context, userID int64, isServerAdmin bool) (bool, error) {
+	return f.ExpectedRequired, f.ExpectedErr
+}
+
+func (f *FakeService) Reset(ctx context.Context, userID int64) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	return f.ExpectedPolicy, f.ExpectedErr
+}
+
+func (f *FakeService) SetOrgPolicy(ctx context.Context, cmd *mfa.SetOrgPolicyCommand) error {
+	return f.ExpectedErr
+}
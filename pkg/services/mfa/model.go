@@ -0,0 +1,82 @@
+package mfa
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+type Method string
+
+const (
+	MethodTOTP         Method = "totp"
+	MethodWebAuthn     Method = "webauthn"
+	MethodRecoveryCode Method = "recovery_code"
+)
+
+var (
+	ErrNotEnabled          = errutil.NotFound("mfa.not-enabled", errutil.WithPublicMessage("Multi-factor authentication is not enabled"))
+	ErrInvalidCode         = errutil.Unauthorized("mfa.invalid-code", errutil.WithPublicMessage("Invalid verification code"))
+	ErrTOTPNotEnrolled     = errutil.BadRequest("mfa.totp-not-enrolled", errutil.WithPublicMessage("No authenticator app is being enrolled"))
+	ErrTOTPAlreadyEnrolled = errutil.Conflict("mfa.totp-already-enrolled", errutil.WithPublicMessage("An authenticator app is already enrolled"))
+	ErrCredentialNotFound  = errutil.NotFound("mfa.credential-not-found", errutil.WithPublicMessage("Security key not found"))
+	ErrInvalidCredential   = errutil.BadRequest("mfa.invalid-credential", errutil.WithPublicMessage("Invalid security key response"))
+	ErrLastFactor          = errutil.BadRequest("mfa.last-factor", errutil.WithPublicMessage("The last second factor cannot be removed while multi-factor authentication is required"))
+	ErrNoFactor            = errutil.BadRequest("mfa.no-factor", errutil.WithPublicMessage("Recovery codes require an authenticator app or a security key"))
+	ErrInvalidPolicyRole   = errutil.BadRequest("mfa.invalid-policy-role", errutil.WithPublicMessage("The required role must be empty, Viewer, Editor or Admin"))
+)
+
+// Status is the multi-factor authentication status of a user.
+type Status struct {
+	// Required is true if a policy requires the user to use a second factor.
+	Required bool `json:"required"`
+	// Enabled is true if the user has a second factor.
+	Enabled                bool                 `json:"enabled"`
+	TOTP                   bool                 `json:"totp"`
+	WebAuthn               []WebAuthnCredential `json:"webauthn"`
+	RecoveryCodesRemaining int64                `json:"recoveryCodesRemaining"`
+}
+
+// Methods returns the second factors the user can log in with.
+func (s *Status) Methods() []Method {
+	methods := make([]Method, 0, 3)
+	if s.TOTP {
+		methods = append(methods, MethodTOTP)
+	}
+	if len(s.WebAuthn) > 0 {
+		methods = append(methods, MethodWebAuthn)
+	}
+	if s.RecoveryCodesRemaining > 0 && len(methods) > 0 {
+		methods = append(methods, MethodRecoveryCode)
+	}
+	return methods
+}
+
+// WebAuthnCredential is a security key registered by a user.
+type WebAuthnCredential struct {
+	ID       int64      `json:"id"`
+	Name     string     `json:"name"`
+	Created  time.Time  `json:"created"`
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+// OrgPolicy requires the members of an organization with at least the required role to use
+// a second factor. An empty role does not require a second factor.
+type OrgPolicy struct {
+	OrgID        int64        `json:"orgId"`
+	RequiredRole org.RoleType `json:"requiredRole"`
+}
+
+type SetOrgPolicyCommand struct {
+	OrgID        int64        `json:"-"`
+	RequiredRole org.RoleType `json:"requiredRole"`
+}
+
+func (cmd *SetOrgPolicyCommand) Validate() error {
+	switch cmd.RequiredRole {
+	case "", org.RoleViewer, org.RoleEditor, org.RoleAdmin:
+		return nil
+	}
+	return ErrInvalidPolicyRole.Errorf("invalid role %q", cmd.RequiredRole)
+}
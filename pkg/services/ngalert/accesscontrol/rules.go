@@ -1,6 +1,7 @@
 package accesscontrol
 
 import (
+	"errors"
 	"fmt"
 
 	"golang.org/x/net/context"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/query"
 )
 
 const (
@@ -23,11 +25,15 @@ const (
 
 type RuleService struct {
 	genericService
+	// datasourceCache gets the data sources of the rules to check their query policies. Query policies are not
+	// checked when it is nil.
+	datasourceCache datasources.CacheService
 }
 
-func NewRuleService(ac accesscontrol.AccessControl) *RuleService {
+func NewRuleService(ac accesscontrol.AccessControl, datasourceCache datasources.CacheService) *RuleService {
 	return &RuleService{
-		genericService{ac: ac},
+		genericService:  genericService{ac: ac},
+		datasourceCache: datasourceCache,
 	}
 }
 
@@ -76,6 +82,43 @@ func (r *RuleService) getRulesQueryEvaluator(rules ...*models.AlertRule) accessc
 	return accesscontrol.EvalAll(evals...)
 }
 
+// authorizeQueryPolicies checks that the query policies of the data sources used by the rules don't restrict the
+// queries of the user. The queries of alert rules don't go through the query service, which applies the policies,
+// so users restricted by the policies of a data source can't use it in alert rules.
+func (r *RuleService) authorizeQueryPolicies(ctx context.Context, user identity.Requester, rules ...*models.AlertRule) error {
+	if r.datasourceCache == nil {
+		return nil
+	}
+	checked := make(map[string]struct{}, 2)
+	for _, rule := range rules {
+		for _, q := range rule.Data {
+			if q.QueryType == expr.DatasourceType || q.DatasourceUID == expr.DatasourceUID || q.DatasourceUID == expr.OldDatasourceUID {
+				continue
+			}
+			if _, ok := checked[q.DatasourceUID]; ok {
+				continue
+			}
+			checked[q.DatasourceUID] = struct{}{}
+
+			ds, err := r.datasourceCache.GetDatasourceByUID(ctx, q.DatasourceUID, user, false)
+			if err != nil {
+				if errors.Is(err, datasources.ErrDataSourceNotFound) {
+					// the query fails to evaluate anyway
+					continue
+				}
+				if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
+					return NewAuthorizationErrorGeneric(fmt.Sprintf("query data source '%s'", q.DatasourceUID))
+				}
+				return fmt.Errorf("failed to get data source '%s': %w", q.DatasourceUID, err)
+			}
+			if query.RestrictedByQueryPolicies(user, ds) {
+				return NewAuthorizationErrorGeneric(fmt.Sprintf("query data source '%s' in alert rules, the query policies of the data source restrict the queries of the user", q.DatasourceUID))
+			}
+		}
+	}
+	return nil
+}
+
 // CanReadAllRules returns true when user has access to all folders and can read rules in them.
 func (r *RuleService) CanReadAllRules(ctx context.Context, user identity.Requester) (bool, error) {
 	return r.HasAccess(ctx, user, accesscontrol.EvalAll(
@@ -84,16 +127,20 @@ func (r *RuleService) CanReadAllRules(ctx context.Context, user identity.Request
 	))
 }
 
-// AuthorizeDatasourceAccessForRule checks that user has access to all data sources declared by the rule
+// AuthorizeDatasourceAccessForRule checks that user has access to all data sources declared by the rule,
+// and that the query policies of the data sources don't restrict the queries of the user
 func (r *RuleService) AuthorizeDatasourceAccessForRule(ctx context.Context, user identity.Requester, rule *models.AlertRule) error {
 	ds := r.getRulesQueryEvaluator(rule)
-	return r.HasAccessOrError(ctx, user, ds, func() string {
+	if err := r.HasAccessOrError(ctx, user, ds, func() string {
 		suffix := ""
 		if rule.UID != "" {
 			suffix = fmt.Sprintf(" of the rule UID '%s'", rule.UID)
 		}
 		return fmt.Sprintf("access one or many data sources%s", suffix)
-	})
+	}); err != nil {
+		return err
+	}
+	return r.authorizeQueryPolicies(ctx, user, rule)
 }
 
 // AuthorizeDatasourceAccessForRuleGroup checks that user has access to all data sources declared by the rules in the group
@@ -196,6 +243,9 @@ func (r *RuleService) AuthorizeRuleChanges(ctx context.Context, user identity.Re
 			}); err != nil {
 				return err
 			}
+			if err := r.authorizeQueryPolicies(ctx, user, rule); err != nil {
+				return err
+			}
 		}
 		if !existingGroup {
 			// create a new group, check that user has "read" access to that new group. Otherwise, it will not be able to read it back.
@@ -211,6 +261,9 @@ func (r *RuleService) AuthorizeRuleChanges(ctx context.Context, user identity.Re
 		}); err != nil {
 			return err
 		}
+		if err := r.authorizeQueryPolicies(ctx, user, rule.New); err != nil {
+			return err
+		}
 
 		// Check if the rule is moved from one folder to the current. If yes, then the user must have the authorization to delete rules from the source folder and add rules to the target folder.
 		if rule.Existing.NamespaceUID != rule.New.NamespaceUID {
@@ -11,10 +11,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	fakeDatasources "github.com/grafana/grafana/pkg/services/datasources/fakes"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
@@ -326,7 +328,7 @@ func TestAuthorizeRuleChanges(t *testing.T) {
 				for _, missing := range permissionCombinations {
 					ac := &recordingAccessControlFake{}
 					srv := RuleService{
-						genericService{ac: ac},
+						genericService: genericService{ac: ac},
 					}
 					err := srv.AuthorizeRuleChanges(context.Background(), createUserWithPermissions(missing), groupChanges)
 
@@ -343,7 +345,7 @@ func TestAuthorizeRuleChanges(t *testing.T) {
 				},
 			}
 			srv := RuleService{
-				genericService{ac: ac},
+				genericService: genericService{ac: ac},
 			}
 			err := srv.AuthorizeRuleChanges(context.Background(), createUserWithPermissions(permissions), groupChanges)
 			require.NoError(t, err)
@@ -388,7 +390,7 @@ func TestCheckDatasourcePermissionsForRule(t *testing.T) {
 
 		ac := &recordingAccessControlFake{}
 		svc := RuleService{
-			genericService{ac: ac},
+			genericService: genericService{ac: ac},
 		}
 
 		eval := svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(permissions), rule)
@@ -404,7 +406,7 @@ func TestCheckDatasourcePermissionsForRule(t *testing.T) {
 			},
 		}
 		svc := RuleService{
-			genericService{ac: ac},
+			genericService: genericService{ac: ac},
 		}
 
 		result := svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(nil), rule)
@@ -414,6 +416,37 @@ func TestCheckDatasourcePermissionsForRule(t *testing.T) {
 	})
 }
 
+func TestAuthorizeDatasourceAccessForRuleQueryPolicies(t *testing.T) {
+	query := models.GenerateAlertQuery()
+	rule := models.RuleGen.With(models.RuleMuts.WithQuery(query)).GenerateRef()
+	ds := &datasources.DataSource{
+		UID:  query.DatasourceUID,
+		Type: datasources.DS_PROMETHEUS,
+		JsonData: simplejson.NewFromAny(map[string]any{
+			"queryPolicies": map[string]any{"restrictAccess": true},
+		}),
+	}
+	allow := &recordingAccessControlFake{
+		Callback: func(user identity.Requester, evaluator accesscontrol.Evaluator) (bool, error) {
+			return true, nil
+		},
+	}
+	svc := NewRuleService(allow, &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{ds}})
+
+	t.Run("should deny users restricted by the query policies of the data source", func(t *testing.T) {
+		err := svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(nil), rule)
+		require.ErrorIs(t, err, ErrAuthorizationBase)
+	})
+
+	t.Run("should allow users if the data source has no query policies", func(t *testing.T) {
+		svc := NewRuleService(allow, &fakeDatasources.FakeCacheService{DataSources: []*datasources.DataSource{{
+			UID:  query.DatasourceUID,
+			Type: datasources.DS_PROMETHEUS,
+		}}})
+		require.NoError(t, svc.AuthorizeDatasourceAccessForRule(context.Background(), createUserWithPermissions(nil), rule))
+	})
+}
+
 func Test_authorizeAccessToRuleGroup(t *testing.T) {
 	t.Run("should succeed if user has access to all namespaces", func(t *testing.T) {
 		rules := models.RuleGen.GenerateManyRef(1, 5)
@@ -427,7 +460,7 @@ func Test_authorizeAccessToRuleGroup(t *testing.T) {
 		}
 		ac := &recordingAccessControlFake{}
 		svc := RuleService{
-			genericService{ac: ac},
+			genericService: genericService{ac: ac},
 		}
 
 		result := svc.AuthorizeAccessToRuleGroup(context.Background(), createUserWithPermissions(permissions), rules)
@@ -444,7 +477,7 @@ func Test_authorizeAccessToRuleGroup(t *testing.T) {
 
 		ac := &recordingAccessControlFake{}
 		svc := RuleService{
-			genericService{ac: ac},
+			genericService: genericService{ac: ac},
 		}
 
 		result := svc.AuthorizeAccessToRuleGroup(context.Background(), createUserWithPermissions(map[string][]string{}), rules)
@@ -457,7 +490,7 @@ func Test_authorizeAccessToRuleGroup(t *testing.T) {
 func TestCanReadAllRules(t *testing.T) {
 	ac := &recordingAccessControlFake{}
 	svc := RuleService{
-		genericService{ac: ac},
+		genericService: genericService{ac: ac},
 	}
 
 	testCases := []struct {
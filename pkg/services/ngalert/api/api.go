@@ -89,7 +89,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 		DataProxy: api.DataProxy,
 		ac:        api.AccessControl,
 	}
-	ruleAuthzService := accesscontrol.NewRuleService(api.AccessControl)
+	ruleAuthzService := accesscontrol.NewRuleService(api.AccessControl, api.DatasourceCache)
 
 	// Register endpoints for proxying to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkingAM(
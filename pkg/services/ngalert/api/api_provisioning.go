@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
@@ -365,6 +366,8 @@ func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar de
 	}
 
 	resp := ProvisionedAlertRuleFromAlertRule(createdAlertRule, alerting_models.Provenance(provenance))
+	auditlog.SetResource(c.Req.Context(), "alert-rules", createdAlertRule.UID)
+	auditlog.SetAfter(c.Req.Context(), resp)
 	return response.JSON(http.StatusCreated, resp)
 }
 
@@ -385,6 +388,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
 	provenance := determineProvenance(c)
+	srv.auditAlertRule(c, UID)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -403,11 +407,13 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	}
 
 	resp := ProvisionedAlertRuleFromAlertRule(updatedAlertRule, alerting_models.Provenance(provenance))
+	auditlog.SetAfter(c.Req.Context(), resp)
 	return response.JSON(http.StatusOK, resp)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
 	provenance := determineProvenance(c)
+	srv.auditAlertRule(c, UID)
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.SignedInUser, UID, alerting_models.Provenance(provenance))
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
@@ -503,6 +509,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 		ErrResp(http.StatusBadRequest, err, "")
 	}
 	provenance := determineProvenance(c)
+	srv.auditAlertRuleGroup(c, folderUID, group, auditlog.SetBefore)
 	err = srv.alertRules.ReplaceRuleGroup(c.Req.Context(), c.SignedInUser, groupModel, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -516,11 +523,13 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
+	srv.auditAlertRuleGroup(c, folderUID, group, auditlog.SetAfter)
 	return response.JSON(http.StatusOK, ag)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string) response.Response {
 	provenance := determineProvenance(c)
+	srv.auditAlertRuleGroup(c, folderUID, group, auditlog.SetBefore)
 	err := srv.alertRules.DeleteRuleGroup(c.Req.Context(), c.SignedInUser, folderUID, group, alerting_models.Provenance(provenance))
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
@@ -528,6 +537,36 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// auditAlertRule records the hash of the alert rule in the audit log event of the request,
+// before it is changed by the request.
+func (srv *ProvisioningSrv) auditAlertRule(c *contextmodel.ReqContext, UID string) {
+	ctx := c.Req.Context()
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	rule, provenance, err := srv.alertRules.GetAlertRule(ctx, c.SignedInUser, UID)
+	if err != nil {
+		return
+	}
+	auditlog.SetResource(ctx, "alert-rules", UID)
+	auditlog.SetBefore(ctx, ProvisionedAlertRuleFromAlertRule(rule, provenance))
+}
+
+// auditAlertRuleGroup records the hash of the alert rule group in the audit log event of the request,
+// before or after the change made by the request.
+func (srv *ProvisioningSrv) auditAlertRuleGroup(c *contextmodel.ReqContext, folderUID string, group string, set func(context.Context, any)) {
+	ctx := c.Req.Context()
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	g, err := srv.alertRules.GetRuleGroup(ctx, c.SignedInUser, folderUID, group)
+	if err != nil {
+		return
+	}
+	auditlog.SetResource(ctx, "alert-rule-groups", folderUID+"/"+group)
+	set(ctx, ApiAlertRuleGroupFromAlertRuleGroup(g))
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
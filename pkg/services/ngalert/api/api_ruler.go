@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -94,6 +95,11 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 
 	if finalGroup != "" {
 		loggerCtx = append(loggerCtx, "group", finalGroup)
+		srv.auditRuleGroup(c, ngmodels.AlertRuleGroupKey{
+			OrgID:        c.SignedInUser.GetOrgID(),
+			NamespaceUID: namespace.UID,
+			RuleGroup:    finalGroup,
+		}, auditlog.SetBefore)
 	}
 	logger := srv.log.New(loggerCtx...)
 
@@ -371,6 +377,7 @@ func (srv RulerSrv) checkGroupLimits(group apimodels.PostableRuleGroupConfig) er
 func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
 	var finalChanges *store.GroupDelta
 	var dbConfig *ngmodels.AlertConfiguration
+	srv.auditRuleGroup(c, groupKey, auditlog.SetBefore)
 	err := srv.xactManager.InTransaction(c.Req.Context(), func(tranCtx context.Context) error {
 		id, _ := c.SignedInUser.GetInternalID()
 		userNamespace := c.SignedInUser.GetIdentityType()
@@ -398,7 +405,8 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 
 		newOrUpdatedNotificationSettings := groupChanges.NewOrUpdatedNotificationSettings()
-		if len(newOrUpdatedNotificationSettings) > 0 {
+		newOrUpdatedActiveTimeIntervals := groupChanges.NewOrUpdatedActiveTimeIntervals()
+		if len(newOrUpdatedNotificationSettings) > 0 || len(newOrUpdatedActiveTimeIntervals) > 0 {
 			dbConfig, err = srv.amConfigStore.GetLatestAlertmanagerConfiguration(c.Req.Context(), groupChanges.GroupKey.OrgID)
 			if err != nil {
 				return fmt.Errorf("failed to get latest configuration: %w", err)
@@ -413,6 +421,9 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 					return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
 				}
 			}
+			if err := notifier.ValidateTimeIntervalsExist(&cfg.AlertmanagerConfig, newOrUpdatedActiveTimeIntervals); err != nil {
+				return errors.Join(ngmodels.ErrAlertRuleFailedValidation, err)
+			}
 		}
 
 		if err := verifyProvisionedRulesNotAffected(c.Req.Context(), srv.provenanceStore, c.SignedInUser.GetOrgID(), groupChanges); err != nil {
@@ -507,6 +518,7 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 	}
 
+	srv.auditRuleGroup(c, groupKey, auditlog.SetAfter)
 	return changesToResponse(finalChanges)
 }
 
@@ -576,6 +588,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 			Record:               ApiRecordFromModelRecord(r.Record),
 			Metadata:             AlertRuleMetadataFromModelMetadata(r.Metadata),
+			RelabelConfigs:       ApiRelabelConfigsFromRelabelConfigs(r.RelabelConfigs),
+			ActiveTimeIntervals:  r.ActiveTimeIntervals,
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -724,3 +738,23 @@ func (srv RulerSrv) searchAuthorizedAlertRules(ctx context.Context, q authorized
 	}
 	return byGroupKey, totalGroups, nil
 }
+
+// auditRuleGroup records the hash of the rules of the group in the audit log event of the request,
+// before or after the change made by the request.
+func (srv RulerSrv) auditRuleGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, set func(context.Context, any)) {
+	ctx := c.Req.Context()
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	rules, err := srv.store.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{
+		OrgID:         groupKey.OrgID,
+		NamespaceUIDs: []string{groupKey.NamespaceUID},
+		RuleGroups:    []string{groupKey.RuleGroup},
+	})
+	if err != nil {
+		return
+	}
+	rules.SortByGroupIndex()
+	auditlog.SetResource(ctx, "alert-rule-groups", groupKey.NamespaceUID+"/"+groupKey.RuleGroup)
+	set(ctx, rules)
+}
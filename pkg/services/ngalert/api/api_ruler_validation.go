@@ -144,6 +144,20 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		}
 	}
 
+	if len(in.GrafanaManagedAlert.RelabelConfigs) > 0 {
+		newRule.RelabelConfigs = RelabelConfigsFromApiRelabelConfigs(in.GrafanaManagedAlert.RelabelConfigs)
+		if _, err := newRule.PrometheusRelabelConfigs(); err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("%w: invalid relabel configs: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
+	}
+
+	for _, name := range in.GrafanaManagedAlert.ActiveTimeIntervals {
+		if name == "" {
+			return ngmodels.AlertRule{}, fmt.Errorf("%w: active time interval name cannot be empty", ngmodels.ErrAlertRuleFailedValidation)
+		}
+	}
+	newRule.ActiveTimeIntervals = in.GrafanaManagedAlert.ActiveTimeIntervals
+
 	if in.GrafanaManagedAlert.Metadata != nil {
 		newRule.Metadata.EditorSettings = ngmodels.EditorSettings{
 			SimplifiedQueryAndExpressionsSection: in.GrafanaManagedAlert.Metadata.EditorSettings.SimplifiedQueryAndExpressionsSection,
@@ -184,6 +198,8 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 	newRule.Condition = ""
 	newRule.For = 0
 	newRule.NotificationSettings = nil
+	newRule.RelabelConfigs = nil
+	newRule.ActiveTimeIntervals = nil
 
 	return newRule, nil
 }
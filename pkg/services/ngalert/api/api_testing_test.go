@@ -262,7 +262,7 @@ func TestRouteEvalQueries(t *testing.T) {
 			srv := &TestingApiSrv{
 				authz: accesscontrol.NewRuleService(acMock.New().WithPermissions([]ac.Permission{
 					{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(data1.DatasourceUID)},
-				})),
+				}), nil),
 				tracer: tracing.InitializeTracerForTest(),
 			}
 
@@ -398,7 +398,7 @@ func createTestingApiSrv(t *testing.T, ds *fakes.FakeCacheService, ac *acMock.Mo
 
 	return &TestingApiSrv{
 		DatasourceCache: ds,
-		authz:           accesscontrol.NewRuleService(ac),
+		authz:           accesscontrol.NewRuleService(ac, nil),
 		evaluator:       evaluator,
 		cfg:             config(t),
 		tracer:          tracing.InitializeTracerForTest(),
@@ -34,6 +34,8 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 		Record:               ModelRecordFromApiRecord(a.Record),
+		RelabelConfigs:       RelabelConfigsFromApiRelabelConfigs(a.RelabelConfigs),
+		ActiveTimeIntervals:  a.ActiveTimeIntervals,
 	}, nil
 }
 
@@ -58,6 +60,8 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 		Record:               ApiRecordFromModelRecord(rule.Record),
+		RelabelConfigs:       ApiRelabelConfigsFromRelabelConfigs(rule.RelabelConfigs),
+		ActiveTimeIntervals:  rule.ActiveTimeIntervals,
 	}
 }
 
@@ -209,6 +213,8 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsExportFromNotificationSettings(rule.NotificationSettings),
 		Record:               AlertRuleRecordExportFromRecord(rule.Record),
+		RelabelConfigs:       ApiRelabelConfigsFromRelabelConfigs(rule.RelabelConfigs),
+		ActiveTimeIntervals:  rule.ActiveTimeIntervals,
 	}
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
@@ -569,3 +575,43 @@ func GettableApiReceiversFromReceivers(recvs []*models.Receiver) ([]*definitions
 	}
 	return out, nil
 }
+
+// RelabelConfigsFromApiRelabelConfigs converts []definitions.AlertRuleRelabelConfig to []models.RelabelConfig
+func RelabelConfigsFromApiRelabelConfigs(cfgs []definitions.AlertRuleRelabelConfig) []models.RelabelConfig {
+	if len(cfgs) == 0 {
+		return nil
+	}
+	result := make([]models.RelabelConfig, 0, len(cfgs))
+	for _, c := range cfgs {
+		result = append(result, models.RelabelConfig{
+			SourceLabels: c.SourceLabels,
+			Separator:    c.Separator,
+			Regex:        c.Regex,
+			Modulus:      c.Modulus,
+			TargetLabel:  c.TargetLabel,
+			Replacement:  c.Replacement,
+			Action:       c.Action,
+		})
+	}
+	return result
+}
+
+// ApiRelabelConfigsFromRelabelConfigs converts []models.RelabelConfig to []definitions.AlertRuleRelabelConfig
+func ApiRelabelConfigsFromRelabelConfigs(cfgs []models.RelabelConfig) []definitions.AlertRuleRelabelConfig {
+	if len(cfgs) == 0 {
+		return nil
+	}
+	result := make([]definitions.AlertRuleRelabelConfig, 0, len(cfgs))
+	for _, c := range cfgs {
+		result = append(result, definitions.AlertRuleRelabelConfig{
+			SourceLabels: c.SourceLabels,
+			Separator:    c.Separator,
+			Regex:        c.Regex,
+			Modulus:      c.Modulus,
+			TargetLabel:  c.TargetLabel,
+			Replacement:  c.Replacement,
+			Action:       c.Action,
+		})
+	}
+	return result
+}
@@ -299,6 +299,32 @@ type WebhookIntegration struct {
 	Message                  *string `json:"message,omitempty" yaml:"message,omitempty" hcl:"message"`
 }
 
+type HTTPRequestHMACConfig struct {
+	Secret          *Secret `json:"secret,omitempty" yaml:"secret,omitempty" hcl:"secret"`
+	Header          *string `json:"header,omitempty" yaml:"header,omitempty" hcl:"header"`
+	TimestampHeader *string `json:"timestampHeader,omitempty" yaml:"timestampHeader,omitempty" hcl:"timestamp_header"`
+}
+
+type HTTPRequestTLSConfig struct {
+	CACertificate      *string `json:"caCertificate,omitempty" yaml:"caCertificate,omitempty" hcl:"ca_certificate"`
+	ClientCertificate  *string `json:"clientCertificate,omitempty" yaml:"clientCertificate,omitempty" hcl:"client_certificate"`
+	ClientKey          *Secret `json:"clientKey,omitempty" yaml:"clientKey,omitempty" hcl:"client_key"`
+	InsecureSkipVerify *bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" hcl:"insecure_skip_verify"`
+}
+
+type HTTPRequestIntegration struct {
+	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
+
+	URL string `json:"url" yaml:"url" hcl:"url"`
+
+	Method           *string                `json:"method,omitempty" yaml:"method,omitempty" hcl:"method"`
+	Headers          *map[string]string     `json:"headers,omitempty" yaml:"headers,omitempty" hcl:"headers"`
+	Body             *string                `json:"body,omitempty" yaml:"body,omitempty" hcl:"body"`
+	RetryStatusCodes *string                `json:"retryStatusCodes,omitempty" yaml:"retryStatusCodes,omitempty" hcl:"retry_status_codes"`
+	HMACConfig       *HTTPRequestHMACConfig `json:"hmacConfig,omitempty" yaml:"hmacConfig,omitempty" hcl:"hmac_config,block"`
+	TLSConfig        *HTTPRequestTLSConfig  `json:"tlsConfig,omitempty" yaml:"tlsConfig,omitempty" hcl:"tls_config,block"`
+}
+
 type WecomIntegration struct {
 	DisableResolveMessage *bool `json:"-" yaml:"-" hcl:"disable_resolve_message"`
 
@@ -319,6 +345,7 @@ type ContactPoint struct {
 	Discord      []DiscordIntegration      `json:"discord" yaml:"discord" hcl:"discord,block"`
 	Email        []EmailIntegration        `json:"email" yaml:"email" hcl:"email,block"`
 	Googlechat   []GooglechatIntegration   `json:"googlechat" yaml:"googlechat" hcl:"googlechat,block"`
+	HTTPRequest  []HTTPRequestIntegration  `json:"http" yaml:"http" hcl:"http,block"`
 	Kafka        []KafkaIntegration        `json:"kafka" yaml:"kafka" hcl:"kafka,block"`
 	Line         []LineIntegration         `json:"line" yaml:"line" hcl:"line,block"`
 	Mqtt         []MqttIntegration         `json:"mqtt" yaml:"mqtt" hcl:"mqtt,block"`
@@ -487,6 +487,34 @@ type AlertRuleNotificationSettings struct {
 	MuteTimeIntervals []string `json:"mute_time_intervals,omitempty"`
 }
 
+// AlertRuleRelabelConfig is a Prometheus relabel config that is applied to the labels of the evaluation results of
+// the rule before alert instances are created from them.
+// swagger:model
+type AlertRuleRelabelConfig struct {
+	// The labels whose values are concatenated and matched against the regex.
+	// example: ["pod"]
+	SourceLabels []string `json:"source_labels,omitempty" yaml:"source_labels,omitempty"`
+	// Separator placed between concatenated source label values.
+	// default: ;
+	Separator string `json:"separator,omitempty" yaml:"separator,omitempty"`
+	// Regular expression against which the concatenated source label values are matched.
+	// default: (.*)
+	// example: (.*)-[a-z0-9]+-[a-z0-9]+
+	Regex string `json:"regex,omitempty" yaml:"regex,omitempty"`
+	// Modulus to take of the hash of the source label values. Only used by the hashmod action.
+	Modulus uint64 `json:"modulus,omitempty" yaml:"modulus,omitempty"`
+	// Label to which the resulting value is written.
+	// example: workload
+	TargetLabel string `json:"target_label,omitempty" yaml:"target_label,omitempty"`
+	// Replacement value against which a regex replace is performed if the regex matches.
+	// default: $1
+	Replacement *string `json:"replacement,omitempty" yaml:"replacement,omitempty"`
+	// Action to perform based on the regex matching.
+	// enum: replace,keep,drop,keepequal,dropequal,hashmod,labelmap,labeldrop,labelkeep,lowercase,uppercase
+	// default: replace
+	Action string `json:"action,omitempty" yaml:"action,omitempty"`
+}
+
 // swagger:model
 type Record struct {
 	// Name of the recorded metric.
@@ -511,6 +539,8 @@ type PostableGrafanaRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
 	Metadata             *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	RelabelConfigs       []AlertRuleRelabelConfig       `json:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
+	ActiveTimeIntervals  []string                       `json:"active_time_intervals,omitempty" yaml:"active_time_intervals,omitempty"`
 }
 
 // swagger:model
@@ -533,6 +563,8 @@ type GettableGrafanaRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
 	Metadata             *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	RelabelConfigs       []AlertRuleRelabelConfig       `json:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
+	ActiveTimeIntervals  []string                       `json:"active_time_intervals,omitempty" yaml:"active_time_intervals,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -170,6 +170,10 @@ type ProvisionedAlertRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
 	//example: {"metric":"grafana_alerts_ratio", "from":"A"}
 	Record *Record `json:"record"`
+	// example: [{"source_labels":["pod"],"regex":"(.*)-[a-z0-9]+-[a-z0-9]+","target_label":"workload"},{"action":"labeldrop","regex":"pod"}]
+	RelabelConfigs []AlertRuleRelabelConfig `json:"relabel_configs,omitempty"`
+	// example: ["business-hours"]
+	ActiveTimeIntervals []string `json:"active_time_intervals,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -277,6 +281,8 @@ type AlertRuleExport struct {
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
 	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record,block"`
+	RelabelConfigs       []AlertRuleRelabelConfig             `json:"relabel_configs,omitempty" yaml:"relabel_configs,omitempty"`
+	ActiveTimeIntervals  []string                             `json:"active_time_intervals,omitempty" yaml:"active_time_intervals,omitempty" hcl:"active_time_intervals"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -0,0 +1,198 @@
+package escalation
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const rootURL = "/api/v1/escalation"
+
+// RegisterAPIEndpoints registers the HTTP API of escalation chains, on-call schedules and acknowledgements.
+func (s *Service) RegisterAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	read := authorize(ac.EvalPermission(ac.ActionAlertingNotificationsRead))
+	write := authorize(ac.EvalPermission(ac.ActionAlertingNotificationsWrite))
+	acknowledge := authorize(ac.EvalPermission(ac.ActionAlertingInstanceUpdate))
+
+	routeRegister.Group(rootURL, func(group routing.RouteRegister) {
+		group.Get("/chains", read, routing.Wrap(s.handleListChains))
+		group.Post("/chains", write, routing.Wrap(s.handleCreateChain))
+		group.Get("/chains/:uid", read, routing.Wrap(s.handleGetChain))
+		group.Put("/chains/:uid", write, routing.Wrap(s.handleUpdateChain))
+		group.Delete("/chains/:uid", write, routing.Wrap(s.handleDeleteChain))
+
+		group.Get("/schedules", read, routing.Wrap(s.handleListSchedules))
+		group.Post("/schedules", write, routing.Wrap(s.handleCreateSchedule))
+		group.Get("/schedules/:uid", read, routing.Wrap(s.handleGetSchedule))
+		group.Put("/schedules/:uid", write, routing.Wrap(s.handleUpdateSchedule))
+		group.Delete("/schedules/:uid", write, routing.Wrap(s.handleDeleteSchedule))
+		group.Get("/schedules/:uid/oncall", read, routing.Wrap(s.handleGetOnCall))
+
+		group.Get("/acknowledgements", read, routing.Wrap(s.handleListAcknowledgements))
+		group.Post("/acknowledgements", acknowledge, routing.Wrap(s.handleAcknowledge))
+		group.Delete("/acknowledgements/:key", acknowledge, routing.Wrap(s.handleUnacknowledge))
+	})
+}
+
+func (s *Service) handleListChains(c *contextmodel.ReqContext) response.Response {
+	chains, err := s.ListChains(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list escalation chains", err)
+	}
+	return response.JSON(http.StatusOK, chains)
+}
+
+func (s *Service) handleGetChain(c *contextmodel.ReqContext) response.Response {
+	chain, err := s.GetChain(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get escalation chain", err)
+	}
+	return response.JSON(http.StatusOK, chain)
+}
+
+func (s *Service) handleCreateChain(c *contextmodel.ReqContext) response.Response {
+	var chain Chain
+	if err := web.Bind(c.Req, &chain); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	created, err := s.CreateChain(c.Req.Context(), c.SignedInUser.GetOrgID(), chain)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create escalation chain", err)
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (s *Service) handleUpdateChain(c *contextmodel.ReqContext) response.Response {
+	var chain Chain
+	if err := web.Bind(c.Req, &chain); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	chain.UID = web.Params(c.Req)[":uid"]
+	updated, err := s.UpdateChain(c.Req.Context(), c.SignedInUser.GetOrgID(), chain)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update escalation chain", err)
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (s *Service) handleDeleteChain(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteChain(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete escalation chain", err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) handleListSchedules(c *contextmodel.ReqContext) response.Response {
+	schedules, err := s.ListSchedules(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list on-call schedules", err)
+	}
+	return response.JSON(http.StatusOK, schedules)
+}
+
+func (s *Service) handleGetSchedule(c *contextmodel.ReqContext) response.Response {
+	schedule, err := s.GetSchedule(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get on-call schedule", err)
+	}
+	return response.JSON(http.StatusOK, schedule)
+}
+
+func (s *Service) handleCreateSchedule(c *contextmodel.ReqContext) response.Response {
+	var schedule Schedule
+	if err := web.Bind(c.Req, &schedule); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	created, err := s.CreateSchedule(c.Req.Context(), c.SignedInUser.GetOrgID(), schedule)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create on-call schedule", err)
+	}
+	return response.JSON(http.StatusCreated, created)
+}
+
+func (s *Service) handleUpdateSchedule(c *contextmodel.ReqContext) response.Response {
+	var schedule Schedule
+	if err := web.Bind(c.Req, &schedule); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	schedule.UID = web.Params(c.Req)[":uid"]
+	updated, err := s.UpdateSchedule(c.Req.Context(), c.SignedInUser.GetOrgID(), schedule)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update on-call schedule", err)
+	}
+	return response.JSON(http.StatusOK, updated)
+}
+
+func (s *Service) handleDeleteSchedule(c *contextmodel.ReqContext) response.Response {
+	if err := s.DeleteSchedule(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete on-call schedule", err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+// OnCallResponse is the response of the on-call endpoint.
+type OnCallResponse struct {
+	At          time.Time    `json:"at"`
+	Participant *Participant `json:"participant"`
+}
+
+func (s *Service) handleGetOnCall(c *contextmodel.ReqContext) response.Response {
+	at := s.now()
+	if v := c.Query("at"); v != "" {
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.Error(http.StatusBadRequest, "invalid 'at' parameter, expected RFC3339 timestamp", err)
+		}
+		at = parsed
+	}
+	p, ok, err := s.GetOnCall(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"], at)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get on-call participant", err)
+	}
+	result := OnCallResponse{At: at}
+	if ok {
+		result.Participant = &p
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) handleListAcknowledgements(c *contextmodel.ReqContext) response.Response {
+	acks, err := s.ListAcknowledgements(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list acknowledgements", err)
+	}
+	return response.JSON(http.StatusOK, acks)
+}
+
+// AcknowledgeCommand is the body of the acknowledgement endpoint. Receiver and GroupLabels identify the alert group,
+// and are the same as the ones returned by the alert groups API of the Grafana Alertmanager.
+type AcknowledgeCommand struct {
+	Receiver    string            `json:"receiver"`
+	GroupLabels map[string]string `json:"groupLabels"`
+	Comment     string            `json:"comment"`
+}
+
+func (s *Service) handleAcknowledge(c *contextmodel.ReqContext) response.Response {
+	var cmd AcknowledgeCommand
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	ack, err := s.Acknowledge(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Receiver, cmd.GroupLabels, c.SignedInUser.GetLogin(), cmd.Comment)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to acknowledge alert group", err)
+	}
+	return response.JSON(http.StatusOK, ack)
+}
+
+func (s *Service) handleUnacknowledge(c *contextmodel.ReqContext) response.Response {
+	if err := s.Unacknowledge(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":key"]); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to remove acknowledgement", err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
@@ -0,0 +1,224 @@
+package escalation
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// DefaultEscalationInterval is how often alert groups are checked for escalation.
+const DefaultEscalationInterval = 30 * time.Second
+
+// Alertmanager is the part of the Grafana Alertmanager that is needed to escalate alert groups.
+type Alertmanager interface {
+	GetAlertGroups(ctx context.Context, active, silenced, inhibited bool, filter []string, receiver string) (definitions.AlertGroups, error)
+	PutAlerts(ctx context.Context, alerts definitions.PostableAlerts) error
+}
+
+// AlertmanagerProvider returns the Alertmanager of the organization.
+type AlertmanagerProvider func(orgID int64) (Alertmanager, error)
+
+// Escalator periodically checks active alert groups of notification policies that have an escalation chain attached,
+// and sends the alerts of groups that are not acknowledged to the receivers of the steps that are due.
+//
+// Escalated alerts are sent to the Alertmanager with the labels of the autogenerated notification policy of
+// the step receiver, so they are routed to it regardless of the user-defined notification policies.
+// The alerts are re-sent on every check with a short end time, which means that they resolve on their own
+// once the original alert group is resolved or acknowledged.
+type Escalator struct {
+	store         *Store
+	alertmanagers AlertmanagerProvider
+	clock         clock.Clock
+	interval      time.Duration
+	log           log.Logger
+}
+
+func NewEscalator(store *Store, alertmanagers AlertmanagerProvider, clk clock.Clock, interval time.Duration, logger log.Logger) *Escalator {
+	return &Escalator{
+		store:         store,
+		alertmanagers: alertmanagers,
+		clock:         clk,
+		interval:      interval,
+		log:           logger,
+	}
+}
+
+// Run checks alert groups for escalation until the context is cancelled.
+func (e *Escalator) Run(ctx context.Context) error {
+	ticker := e.clock.Ticker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			orgs, err := e.store.OrgsWithChains(ctx)
+			if err != nil {
+				e.log.Error("Failed to list organizations with escalation chains", "error", err)
+				continue
+			}
+			for _, orgID := range orgs {
+				if err := e.EscalateOrg(ctx, orgID); err != nil {
+					e.log.Error("Failed to escalate alert groups", "orgID", orgID, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// EscalateOrg escalates the alert groups of the organization.
+func (e *Escalator) EscalateOrg(ctx context.Context, orgID int64) error {
+	chains, err := e.store.ListChains(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	schedules, err := e.store.ListSchedules(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	schedulesByUID := make(map[string]Schedule, len(schedules))
+	for _, sc := range schedules {
+		schedulesByUID[sc.UID] = sc
+	}
+	acks, err := e.store.ListAcknowledgements(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	acked := make(map[string]struct{}, len(acks))
+	for _, a := range acks {
+		acked[a.GroupKey] = struct{}{}
+	}
+
+	am, err := e.alertmanagers(orgID)
+	if err != nil {
+		return err
+	}
+
+	now := e.clock.Now()
+	activeGroups := make(map[string]struct{})
+	var alerts []amv2.PostableAlert
+	for _, chain := range chains {
+		// Only the alerts that match the notification policy of the chain are escalated.
+		groups, err := am.GetAlertGroups(ctx, true, false, false, chain.Filter(), regexp.QuoteMeta(chain.Receiver))
+		if err != nil {
+			return err
+		}
+		for _, g := range groups {
+			if g == nil || g.Receiver == nil || g.Receiver.Name == nil || *g.Receiver.Name != chain.Receiver {
+				continue
+			}
+			key := GroupKey(chain.Receiver, g.Labels)
+			activeGroups[key] = struct{}{}
+			if _, ok := acked[key]; ok {
+				continue
+			}
+			alerts = append(alerts, e.escalateGroup(chain, g, schedulesByUID, now)...)
+		}
+	}
+
+	// Acknowledgements of groups that are no longer active are removed so that the next occurrence is escalated again.
+	for key := range acked {
+		if _, ok := activeGroups[key]; ok {
+			continue
+		}
+		if err := e.store.DeleteAcknowledgement(ctx, orgID, key); err != nil {
+			e.log.Warn("Failed to delete acknowledgement of resolved alert group", "orgID", orgID, "groupKey", key, "error", err)
+		}
+	}
+
+	if len(alerts) == 0 {
+		return nil
+	}
+	e.log.Debug("Sending escalated alerts", "orgID", orgID, "count", len(alerts))
+	return am.PutAlerts(ctx, definitions.PostableAlerts{PostableAlerts: alerts})
+}
+
+// escalateGroup returns the alerts to send for each step of the chain that is due for the group.
+func (e *Escalator) escalateGroup(chain Chain, g *amv2.AlertGroup, schedules map[string]Schedule, now time.Time) []amv2.PostableAlert {
+	var start time.Time
+	var firing []*amv2.GettableAlert
+	for _, a := range g.Alerts {
+		if a == nil || a.StartsAt == nil {
+			continue
+		}
+		// Alerts that were sent by another escalation chain are never escalated again.
+		if _, ok := a.Labels[EscalationStepLabel]; ok {
+			continue
+		}
+		firing = append(firing, a)
+		if s := time.Time(*a.StartsAt); start.IsZero() || s.Before(start) {
+			start = s
+		}
+	}
+	if len(firing) == 0 {
+		return nil
+	}
+
+	endsAt := strfmt.DateTime(now.Add(3 * e.interval))
+	var result []amv2.PostableAlert
+	for idx, step := range chain.Steps {
+		due := chain.StepDueAt(idx, start)
+		if now.Before(due) {
+			break
+		}
+
+		var oncall *Participant
+		if step.ScheduleUID != "" {
+			if sc, ok := schedules[step.ScheduleUID]; !ok {
+				e.log.Warn("On-call schedule of escalation step does not exist", "chain", chain.UID, "step", idx, "schedule", step.ScheduleUID)
+			} else if p, ok := sc.OnCall(now); ok {
+				oncall = &p
+			}
+		}
+
+		settings := models.NewDefaultNotificationSettings(step.Receiver)
+		settingsLabels := settings.ToLabels()
+		for _, a := range firing {
+			labels := make(amv2.LabelSet, len(a.Labels)+len(settingsLabels)+2)
+			for k, v := range a.Labels {
+				labels[k] = v
+			}
+			// The alert must only match the autogenerated policy of the step receiver.
+			delete(labels, models.AutogeneratedRouteSettingsHashLabel)
+			for k, v := range settingsLabels {
+				labels[k] = v
+			}
+			labels[EscalationChainLabel] = chain.UID
+			labels[EscalationStepLabel] = strconv.Itoa(idx)
+
+			annotations := make(amv2.LabelSet, len(a.Annotations)+3)
+			for k, v := range a.Annotations {
+				annotations[k] = v
+			}
+			if oncall != nil {
+				annotations[OnCallNameAnnotation] = oncall.Name
+				if oncall.Email != "" {
+					annotations[OnCallEmailAnnotation] = oncall.Email
+				}
+				if oncall.SlackID != "" {
+					annotations[OnCallSlackAnnotation] = oncall.SlackID
+				}
+			}
+
+			result = append(result, amv2.PostableAlert{
+				Annotations: annotations,
+				StartsAt:    strfmt.DateTime(due),
+				EndsAt:      endsAt,
+				Alert: amv2.Alert{
+					Labels:       labels,
+					GeneratorURL: a.GeneratorURL,
+				},
+			})
+		}
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package escalation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeAlertmanager struct {
+	groups  definitions.AlertGroups
+	posted  []amv2.PostableAlert
+	filters [][]string
+}
+
+func (f *fakeAlertmanager) GetAlertGroups(_ context.Context, _, _, _ bool, filter []string, _ string) (definitions.AlertGroups, error) {
+	f.filters = append(f.filters, filter)
+	return f.groups, nil
+}
+
+func (f *fakeAlertmanager) PutAlerts(_ context.Context, alerts definitions.PostableAlerts) error {
+	f.posted = append(f.posted, alerts.PostableAlerts...)
+	return nil
+}
+
+func alertGroup(receiver string, startsAt time.Time, labels amv2.LabelSet) *amv2.AlertGroup {
+	start := strfmt.DateTime(startsAt)
+	return &amv2.AlertGroup{
+		Labels:   amv2.LabelSet{"alertname": labels["alertname"]},
+		Receiver: &amv2.Receiver{Name: &receiver},
+		Alerts: []*amv2.GettableAlert{
+			{
+				StartsAt:    &start,
+				Annotations: amv2.LabelSet{"summary": "something is wrong"},
+				Alert:       amv2.Alert{Labels: labels},
+			},
+		},
+	}
+}
+
+func TestEscalator_EscalateOrg(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	clk.Set(start)
+
+	store := NewStore(kvstore.NewFakeKVStore())
+	sc := testSchedule()
+	sc.UID = "schedule"
+	sc.Start = start
+	require.NoError(t, store.SaveSchedule(ctx, 1, sc))
+	chain := Chain{
+		UID:      "chain",
+		Name:     "team escalation",
+		Receiver: "team",
+		Matchers: definitions.ObjectMatchers{matcher(labels.MatchEqual, "severity", "critical")},
+		Steps: []Step{
+			{Receiver: "lead", Delay: model.Duration(10 * time.Minute), ScheduleUID: sc.UID},
+			{Receiver: "manager", Delay: model.Duration(20 * time.Minute)},
+		},
+	}
+	require.NoError(t, store.SaveChain(ctx, 1, chain))
+
+	am := &fakeAlertmanager{
+		groups: definitions.AlertGroups{
+			alertGroup("team", start, amv2.LabelSet{
+				"alertname":                                "test",
+				models.AutogeneratedRouteLabel:             "true",
+				models.AutogeneratedRouteReceiverNameLabel: "team",
+				models.AutogeneratedRouteSettingsHashLabel: "hash",
+			}),
+		},
+	}
+	e := NewEscalator(store, func(int64) (Alertmanager, error) { return am, nil }, clk, DefaultEscalationInterval, log.NewNopLogger())
+
+	t.Run("nothing is sent before the first step is due", func(t *testing.T) {
+		clk.Add(5 * time.Minute)
+		require.NoError(t, e.EscalateOrg(ctx, 1))
+		require.Empty(t, am.posted)
+		// Only the alert groups of the notification policy of the chain are escalated.
+		require.Equal(t, [][]string{{`severity="critical"`}}, am.filters)
+	})
+
+	t.Run("first step is sent to its receiver with the person on call", func(t *testing.T) {
+		clk.Add(10 * time.Minute)
+		require.NoError(t, e.EscalateOrg(ctx, 1))
+		require.Len(t, am.posted, 1)
+		a := am.posted[0]
+		assert.Equal(t, "lead", a.Labels[models.AutogeneratedRouteReceiverNameLabel])
+		assert.NotContains(t, a.Labels, models.AutogeneratedRouteSettingsHashLabel)
+		assert.Equal(t, "chain", a.Labels[EscalationChainLabel])
+		assert.Equal(t, "0", a.Labels[EscalationStepLabel])
+		assert.Equal(t, "test", a.Labels["alertname"])
+		assert.Equal(t, "alice", a.Annotations[OnCallNameAnnotation])
+		assert.Equal(t, "alice@example.com", a.Annotations[OnCallEmailAnnotation])
+		assert.Equal(t, "something is wrong", a.Annotations["summary"])
+		assert.Equal(t, strfmt.DateTime(start.Add(10*time.Minute)), a.StartsAt)
+	})
+
+	t.Run("both steps are sent once the second step is due", func(t *testing.T) {
+		am.posted = nil
+		clk.Add(20 * time.Minute)
+		require.NoError(t, e.EscalateOrg(ctx, 1))
+		require.Len(t, am.posted, 2)
+		assert.Equal(t, "manager", am.posted[1].Labels[models.AutogeneratedRouteReceiverNameLabel])
+		assert.NotContains(t, am.posted[1].Annotations, OnCallNameAnnotation)
+	})
+
+	t.Run("acknowledged groups are not escalated", func(t *testing.T) {
+		am.posted = nil
+		key := GroupKey("team", map[string]string{"alertname": "test"})
+		require.NoError(t, store.SaveAcknowledgement(ctx, 1, Acknowledgement{GroupKey: key, Receiver: "team"}))
+		require.NoError(t, e.EscalateOrg(ctx, 1))
+		require.Empty(t, am.posted)
+	})
+
+	t.Run("acknowledgements of resolved groups are removed", func(t *testing.T) {
+		am.groups = nil
+		require.NoError(t, e.EscalateOrg(ctx, 1))
+		acks, err := store.ListAcknowledgements(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, acks)
+	})
+}
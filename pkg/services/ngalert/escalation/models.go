@@ -0,0 +1,229 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrChainNotFound           = errutil.NotFound("alerting.escalation.chainNotFound", errutil.WithPublicMessage("Escalation chain not found"))
+	ErrScheduleNotFound        = errutil.NotFound("alerting.escalation.scheduleNotFound", errutil.WithPublicMessage("On-call schedule not found"))
+	ErrAcknowledgementNotFound = errutil.NotFound("alerting.escalation.acknowledgementNotFound", errutil.WithPublicMessage("Acknowledgement not found"))
+	ErrScheduleInUse           = errutil.Conflict("alerting.escalation.scheduleInUse", errutil.WithPublicMessage("On-call schedule is used by an escalation chain"))
+	ErrRouteInUse              = errutil.Conflict("alerting.escalation.routeInUse", errutil.WithPublicMessage("Notification policy already has an escalation chain"))
+	ErrInvalid                 = errutil.BadRequest("alerting.escalation.invalid").MustTemplate("Invalid escalation configuration: {{ .Public.Error }}", errutil.WithPublic("Invalid escalation configuration: {{ .Public.Error }}"))
+)
+
+// MakeErrInvalid creates an error with the ErrInvalid template.
+func MakeErrInvalid(err error) error {
+	return ErrInvalid.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"Error": err.Error(),
+		},
+		Error: err,
+	})
+}
+
+const (
+	// EscalationStepLabel is the label added to alerts that are sent by an escalation step. It contains the index of the step.
+	EscalationStepLabel = "__grafana_escalation_step__"
+	// EscalationChainLabel is the label added to alerts that are sent by an escalation step. It contains the UID of the chain.
+	EscalationChainLabel = "__grafana_escalation_chain__"
+
+	// OnCallNameAnnotation, OnCallEmailAnnotation and OnCallSlackAnnotation are added to alerts that are sent by an
+	// escalation step that references an on-call schedule. They can be used in notification templates, for example
+	// to mention the person on call in a Slack message.
+	OnCallNameAnnotation  = "oncall_name"
+	OnCallEmailAnnotation = "oncall_email"
+	OnCallSlackAnnotation = "oncall_slack_id"
+)
+
+// Chain describes how alert groups are escalated when they are not acknowledged.
+// A chain is attached to the notification policy with the contact point Receiver and the object matchers Matchers:
+// the receiver is notified by the notification policy as usual, then each step notifies its own receiver after
+// the step's delay, until the alert group is acknowledged or resolved.
+type Chain struct {
+	UID      string                     `json:"uid"`
+	Name     string                     `json:"name"`
+	Receiver string                     `json:"receiver"`
+	Matchers definitions.ObjectMatchers `json:"matchers,omitempty"`
+	Steps    []Step                     `json:"steps"`
+}
+
+// Step is a single step of an escalation chain.
+type Step struct {
+	// Receiver is the name of the contact point notified by this step.
+	Receiver string `json:"receiver"`
+	// Delay is the time to wait after the previous step (or after the alert group started firing for the first step).
+	Delay model.Duration `json:"delay"`
+	// ScheduleUID optionally references an on-call schedule. The person on call is added to the annotations
+	// of the alerts that are sent to the receiver.
+	ScheduleUID string `json:"scheduleUid,omitempty"`
+}
+
+// Validate returns an error if the chain is not valid.
+func (c Chain) Validate() error {
+	if c.Name == "" {
+		return errors.New("escalation chain name must not be empty")
+	}
+	if c.Receiver == "" {
+		return errors.New("escalation chain must be attached to a receiver")
+	}
+	if len(c.Steps) == 0 {
+		return errors.New("escalation chain must have at least one step")
+	}
+	for i, s := range c.Steps {
+		if s.Receiver == "" {
+			return fmt.Errorf("step %d: receiver must not be empty", i)
+		}
+		if s.Receiver == c.Receiver {
+			return fmt.Errorf("step %d: receiver must be different from the receiver of the chain", i)
+		}
+		if s.Delay <= 0 {
+			return fmt.Errorf("step %d: delay must be greater than zero", i)
+		}
+	}
+	return nil
+}
+
+// RouteKey returns a key that identifies the notification policy of the chain by its receiver and matchers.
+// The order of the matchers does not matter.
+func (c Chain) RouteKey() string {
+	return routeKey(c.Receiver, c.Matchers)
+}
+
+// Filter returns the matchers of the chain in the format of the alert groups API filter.
+func (c Chain) Filter() []string {
+	filter := make([]string, 0, len(c.Matchers))
+	for _, m := range c.Matchers {
+		filter = append(filter, m.String())
+	}
+	return filter
+}
+
+func routeKey(receiver string, matchers definitions.ObjectMatchers) string {
+	keys := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		keys = append(keys, m.String())
+	}
+	sort.Strings(keys)
+	return receiver + "{" + strings.Join(keys, ",") + "}"
+}
+
+// StepDueAt returns the time at which the step with the given index is due for a group that started firing at start.
+func (c Chain) StepDueAt(idx int, start time.Time) time.Time {
+	due := start
+	for i := 0; i <= idx && i < len(c.Steps); i++ {
+		due = due.Add(time.Duration(c.Steps[i].Delay))
+	}
+	return due
+}
+
+// Schedule is a simple rotation of participants with fixed-length shifts.
+type Schedule struct {
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+	// Start is the beginning of the first shift. Shifts are handed over at Start + n * ShiftLength.
+	Start time.Time `json:"start"`
+	// ShiftLength is the duration of each shift, for example one week.
+	ShiftLength  model.Duration `json:"shiftLength"`
+	Participants []Participant  `json:"participants"`
+	// Overrides replace the participant of the rotation in the given time range.
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// Participant is a person that can be on call.
+type Participant struct {
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	SlackID string `json:"slackId,omitempty"`
+}
+
+// Override replaces the participant of the rotation between Start (inclusive) and End (exclusive).
+type Override struct {
+	Start       time.Time   `json:"start"`
+	End         time.Time   `json:"end"`
+	Participant Participant `json:"participant"`
+}
+
+// Validate returns an error if the schedule is not valid.
+func (s Schedule) Validate() error {
+	if s.Name == "" {
+		return errors.New("schedule name must not be empty")
+	}
+	if s.Start.IsZero() {
+		return errors.New("schedule start must be set")
+	}
+	if s.ShiftLength <= 0 {
+		return errors.New("shift length must be greater than zero")
+	}
+	if len(s.Participants) == 0 {
+		return errors.New("schedule must have at least one participant")
+	}
+	for i, p := range s.Participants {
+		if p.Name == "" {
+			return fmt.Errorf("participant %d: name must not be empty", i)
+		}
+	}
+	for i, o := range s.Overrides {
+		if !o.End.After(o.Start) {
+			return fmt.Errorf("override %d: end must be after start", i)
+		}
+		if o.Participant.Name == "" {
+			return fmt.Errorf("override %d: participant name must not be empty", i)
+		}
+	}
+	return nil
+}
+
+// OnCall returns the participant that is on call at the given time.
+// It returns false if the rotation has not started yet and there is no override for the time.
+func (s Schedule) OnCall(at time.Time) (Participant, bool) {
+	// The latest override wins if several overlap.
+	overrides := make([]Override, len(s.Overrides))
+	copy(overrides, s.Overrides)
+	sort.SliceStable(overrides, func(i, j int) bool {
+		return overrides[i].Start.After(overrides[j].Start)
+	})
+	for _, o := range overrides {
+		if !at.Before(o.Start) && at.Before(o.End) {
+			return o.Participant, true
+		}
+	}
+
+	if len(s.Participants) == 0 || s.ShiftLength <= 0 || at.Before(s.Start) {
+		return Participant{}, false
+	}
+	shift := int64(at.Sub(s.Start) / time.Duration(s.ShiftLength))
+	return s.Participants[shift%int64(len(s.Participants))], true
+}
+
+// Acknowledgement marks an alert group as acknowledged, which stops its escalation.
+// Acknowledgements are removed when the alert group is resolved.
+type Acknowledgement struct {
+	GroupKey       string            `json:"groupKey"`
+	Receiver       string            `json:"receiver"`
+	GroupLabels    map[string]string `json:"groupLabels"`
+	AcknowledgedBy string            `json:"acknowledgedBy"`
+	AcknowledgedAt time.Time         `json:"acknowledgedAt"`
+	Comment        string            `json:"comment,omitempty"`
+}
+
+// GroupKey returns a key that identifies an alert group by its receiver and group labels.
+func GroupKey(receiver string, groupLabels map[string]string) string {
+	ls := make(model.LabelSet, len(groupLabels)+1)
+	for k, v := range groupLabels {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	// Label names starting with "__" are reserved, so this does not conflict with group labels.
+	ls["__receiver__"] = model.LabelValue(receiver)
+	return fmt.Sprintf("%016x", uint64(ls.Fingerprint()))
+}
@@ -0,0 +1,124 @@
+package escalation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestChainValidate(t *testing.T) {
+	valid := func() Chain {
+		return Chain{
+			Name:     "chain",
+			Receiver: "team",
+			Steps: []Step{
+				{Receiver: "lead", Delay: model.Duration(15 * time.Minute)},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(c *Chain)
+		err    string
+	}{
+		{name: "valid", mutate: func(c *Chain) {}},
+		{name: "empty name", mutate: func(c *Chain) { c.Name = "" }, err: "name must not be empty"},
+		{name: "empty receiver", mutate: func(c *Chain) { c.Receiver = "" }, err: "must be attached to a receiver"},
+		{name: "no steps", mutate: func(c *Chain) { c.Steps = nil }, err: "at least one step"},
+		{name: "step without receiver", mutate: func(c *Chain) { c.Steps[0].Receiver = "" }, err: "step 0: receiver must not be empty"},
+		{name: "step with chain receiver", mutate: func(c *Chain) { c.Steps[0].Receiver = "team" }, err: "step 0: receiver must be different"},
+		{name: "step without delay", mutate: func(c *Chain) { c.Steps[0].Delay = 0 }, err: "step 0: delay must be greater than zero"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := valid()
+			tc.mutate(&c)
+			err := c.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestChainRouteKey(t *testing.T) {
+	critical := matcher(labels.MatchEqual, "severity", "critical")
+	team := matcher(labels.MatchRegexp, "team", "a|b")
+
+	a := Chain{Receiver: "team", Matchers: definitions.ObjectMatchers{critical, team}}
+	b := Chain{Receiver: "team", Matchers: definitions.ObjectMatchers{team, critical}}
+	assert.Equal(t, a.RouteKey(), b.RouteKey())
+	assert.NotEqual(t, a.RouteKey(), Chain{Receiver: "team", Matchers: definitions.ObjectMatchers{critical}}.RouteKey())
+	assert.NotEqual(t, a.RouteKey(), Chain{Receiver: "other", Matchers: a.Matchers}.RouteKey())
+	assert.Equal(t, []string{`severity="critical"`, `team=~"a|b"`}, a.Filter())
+}
+
+func TestChainStepDueAt(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := Chain{
+		Steps: []Step{
+			{Receiver: "a", Delay: model.Duration(5 * time.Minute)},
+			{Receiver: "b", Delay: model.Duration(10 * time.Minute)},
+		},
+	}
+	assert.Equal(t, start.Add(5*time.Minute), c.StepDueAt(0, start))
+	assert.Equal(t, start.Add(15*time.Minute), c.StepDueAt(1, start))
+}
+
+func TestScheduleOnCall(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	alice := Participant{Name: "alice"}
+	bob := Participant{Name: "bob"}
+	carol := Participant{Name: "carol"}
+	dave := Participant{Name: "dave"}
+	s := Schedule{
+		Name:         "primary",
+		Start:        start,
+		ShiftLength:  model.Duration(24 * time.Hour),
+		Participants: []Participant{alice, bob},
+		Overrides: []Override{
+			{Start: start.Add(72 * time.Hour), End: start.Add(96 * time.Hour), Participant: carol},
+			{Start: start.Add(80 * time.Hour), End: start.Add(84 * time.Hour), Participant: dave},
+		},
+	}
+	require.NoError(t, s.Validate())
+
+	testCases := []struct {
+		name     string
+		at       time.Time
+		expected Participant
+		ok       bool
+	}{
+		{name: "before start", at: start.Add(-time.Minute)},
+		{name: "first shift", at: start, expected: alice, ok: true},
+		{name: "second shift", at: start.Add(25 * time.Hour), expected: bob, ok: true},
+		{name: "rotation wraps around", at: start.Add(49 * time.Hour), expected: alice, ok: true},
+		{name: "override", at: start.Add(73 * time.Hour), expected: carol, ok: true},
+		{name: "latest override wins", at: start.Add(81 * time.Hour), expected: dave, ok: true},
+		{name: "after override", at: start.Add(97 * time.Hour), expected: alice, ok: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := s.OnCall(tc.at)
+			require.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	k := GroupKey("team", map[string]string{"alertname": "test", "cluster": "a"})
+	assert.Equal(t, k, GroupKey("team", map[string]string{"cluster": "a", "alertname": "test"}))
+	assert.NotEqual(t, k, GroupKey("other", map[string]string{"alertname": "test", "cluster": "a"}))
+	assert.NotEqual(t, k, GroupKey("team", map[string]string{"alertname": "test", "cluster": "b"}))
+	assert.Regexp(t, "^[0-9a-f]{16}$", k)
+}
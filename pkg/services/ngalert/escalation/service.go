@@ -0,0 +1,255 @@
+package escalation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ConfigProvider returns the Alertmanager configuration of the organization, which is used to validate
+// the notification policy and the receivers of escalation chains.
+type ConfigProvider func(ctx context.Context, orgID int64) (definitions.GettableUserConfig, error)
+
+// Service manages escalation chains, on-call schedules and acknowledgements of alert groups.
+type Service struct {
+	store   *Store
+	configs ConfigProvider
+	log     log.Logger
+	now     func() time.Time
+}
+
+func NewService(store *Store, configs ConfigProvider, logger log.Logger) *Service {
+	return &Service{
+		store:   store,
+		configs: configs,
+		log:     logger,
+		now:     time.Now,
+	}
+}
+
+func (s *Service) ListChains(ctx context.Context, orgID int64) ([]Chain, error) {
+	return s.store.ListChains(ctx, orgID)
+}
+
+func (s *Service) GetChain(ctx context.Context, orgID int64, uid string) (Chain, error) {
+	return s.store.GetChain(ctx, orgID, uid)
+}
+
+func (s *Service) CreateChain(ctx context.Context, orgID int64, c Chain) (Chain, error) {
+	if c.UID == "" {
+		c.UID = util.GenerateShortUID()
+	} else if _, err := s.store.GetChain(ctx, orgID, c.UID); err == nil {
+		return Chain{}, MakeErrInvalid(fmt.Errorf("escalation chain with UID %s already exists", c.UID))
+	} else if !errors.Is(err, ErrChainNotFound) {
+		return Chain{}, err
+	}
+	if err := s.validateChain(ctx, orgID, c); err != nil {
+		return Chain{}, err
+	}
+	if err := s.store.SaveChain(ctx, orgID, c); err != nil {
+		return Chain{}, err
+	}
+	return c, nil
+}
+
+func (s *Service) UpdateChain(ctx context.Context, orgID int64, c Chain) (Chain, error) {
+	if _, err := s.store.GetChain(ctx, orgID, c.UID); err != nil {
+		return Chain{}, err
+	}
+	if err := s.validateChain(ctx, orgID, c); err != nil {
+		return Chain{}, err
+	}
+	if err := s.store.SaveChain(ctx, orgID, c); err != nil {
+		return Chain{}, err
+	}
+	return c, nil
+}
+
+func (s *Service) DeleteChain(ctx context.Context, orgID int64, uid string) error {
+	if _, err := s.store.GetChain(ctx, orgID, uid); err != nil {
+		return err
+	}
+	return s.store.DeleteChain(ctx, orgID, uid)
+}
+
+func (s *Service) validateChain(ctx context.Context, orgID int64, c Chain) error {
+	if err := c.Validate(); err != nil {
+		return MakeErrInvalid(err)
+	}
+	if err := s.validateRoute(ctx, orgID, c); err != nil {
+		return err
+	}
+	for i, step := range c.Steps {
+		if step.ScheduleUID == "" {
+			continue
+		}
+		if _, err := s.store.GetSchedule(ctx, orgID, step.ScheduleUID); err != nil {
+			if errors.Is(err, ErrScheduleNotFound) {
+				return MakeErrInvalid(fmt.Errorf("step %d: on-call schedule %s does not exist", i, step.ScheduleUID))
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRoute checks that the chain is attached to a notification policy of the Alertmanager configuration,
+// that no other chain is attached to it, and that the receivers of the steps exist.
+func (s *Service) validateRoute(ctx context.Context, orgID int64, c Chain) error {
+	cfg, err := s.configs(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	receivers := make(map[string]struct{}, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	for i, step := range c.Steps {
+		if _, ok := receivers[step.Receiver]; !ok {
+			return MakeErrInvalid(fmt.Errorf("step %d: receiver %s does not exist", i, step.Receiver))
+		}
+	}
+
+	key := c.RouteKey()
+	if !hasRoute(cfg.AlertmanagerConfig.Route, "", key) {
+		return MakeErrInvalid(fmt.Errorf("no notification policy with receiver %s and matchers %v", c.Receiver, c.Filter()))
+	}
+	chains, err := s.store.ListChains(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, other := range chains {
+		if other.UID != c.UID && other.RouteKey() == key {
+			return ErrRouteInUse.Errorf("notification policy of escalation chain %s already has escalation chain %s", c.UID, other.UID)
+		}
+	}
+	return nil
+}
+
+// hasRoute returns true if the route or one of its nested routes has the given key. Routes without a receiver
+// inherit the receiver of their parent.
+func hasRoute(r *definitions.Route, parentReceiver string, key string) bool {
+	if r == nil {
+		return false
+	}
+	receiver := r.Receiver
+	if receiver == "" {
+		receiver = parentReceiver
+	}
+	if routeKey(receiver, r.ObjectMatchers) == key {
+		return true
+	}
+	for _, child := range r.Routes {
+		if hasRoute(child, receiver, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) ListSchedules(ctx context.Context, orgID int64) ([]Schedule, error) {
+	return s.store.ListSchedules(ctx, orgID)
+}
+
+func (s *Service) GetSchedule(ctx context.Context, orgID int64, uid string) (Schedule, error) {
+	return s.store.GetSchedule(ctx, orgID, uid)
+}
+
+func (s *Service) CreateSchedule(ctx context.Context, orgID int64, sc Schedule) (Schedule, error) {
+	if sc.UID == "" {
+		sc.UID = util.GenerateShortUID()
+	} else if _, err := s.store.GetSchedule(ctx, orgID, sc.UID); err == nil {
+		return Schedule{}, MakeErrInvalid(fmt.Errorf("on-call schedule with UID %s already exists", sc.UID))
+	} else if !errors.Is(err, ErrScheduleNotFound) {
+		return Schedule{}, err
+	}
+	if err := sc.Validate(); err != nil {
+		return Schedule{}, MakeErrInvalid(err)
+	}
+	if err := s.store.SaveSchedule(ctx, orgID, sc); err != nil {
+		return Schedule{}, err
+	}
+	return sc, nil
+}
+
+func (s *Service) UpdateSchedule(ctx context.Context, orgID int64, sc Schedule) (Schedule, error) {
+	if _, err := s.store.GetSchedule(ctx, orgID, sc.UID); err != nil {
+		return Schedule{}, err
+	}
+	if err := sc.Validate(); err != nil {
+		return Schedule{}, MakeErrInvalid(err)
+	}
+	if err := s.store.SaveSchedule(ctx, orgID, sc); err != nil {
+		return Schedule{}, err
+	}
+	return sc, nil
+}
+
+// DeleteSchedule deletes the on-call schedule. It returns ErrScheduleInUse if an escalation chain references the schedule.
+func (s *Service) DeleteSchedule(ctx context.Context, orgID int64, uid string) error {
+	if _, err := s.store.GetSchedule(ctx, orgID, uid); err != nil {
+		return err
+	}
+	chains, err := s.store.ListChains(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, c := range chains {
+		for _, step := range c.Steps {
+			if step.ScheduleUID == uid {
+				return ErrScheduleInUse.Errorf("on-call schedule %s is used by escalation chain %s", uid, c.UID)
+			}
+		}
+	}
+	return s.store.DeleteSchedule(ctx, orgID, uid)
+}
+
+// GetOnCall returns the participant of the schedule that is on call at the given time.
+func (s *Service) GetOnCall(ctx context.Context, orgID int64, uid string, at time.Time) (Participant, bool, error) {
+	sc, err := s.store.GetSchedule(ctx, orgID, uid)
+	if err != nil {
+		return Participant{}, false, err
+	}
+	p, ok := sc.OnCall(at)
+	return p, ok, nil
+}
+
+func (s *Service) ListAcknowledgements(ctx context.Context, orgID int64) ([]Acknowledgement, error) {
+	return s.store.ListAcknowledgements(ctx, orgID)
+}
+
+// Acknowledge acknowledges the alert group of the receiver with the given group labels, which stops its escalation.
+func (s *Service) Acknowledge(ctx context.Context, orgID int64, receiver string, groupLabels map[string]string, by string, comment string) (Acknowledgement, error) {
+	if receiver == "" {
+		return Acknowledgement{}, MakeErrInvalid(errors.New("receiver must not be empty"))
+	}
+	if groupLabels == nil {
+		groupLabels = map[string]string{}
+	}
+	ack := Acknowledgement{
+		GroupKey:       GroupKey(receiver, groupLabels),
+		Receiver:       receiver,
+		GroupLabels:    groupLabels,
+		AcknowledgedBy: by,
+		AcknowledgedAt: s.now(),
+		Comment:        comment,
+	}
+	if err := s.store.SaveAcknowledgement(ctx, orgID, ack); err != nil {
+		return Acknowledgement{}, err
+	}
+	s.log.Info("Alert group acknowledged", "orgID", orgID, "groupKey", ack.GroupKey, "by", by)
+	return ack, nil
+}
+
+// Unacknowledge removes the acknowledgement of the alert group, which resumes its escalation.
+func (s *Service) Unacknowledge(ctx context.Context, orgID int64, groupKey string) error {
+	if _, err := s.store.GetAcknowledgement(ctx, orgID, groupKey); err != nil {
+		return err
+	}
+	return s.store.DeleteAcknowledgement(ctx, orgID, groupKey)
+}
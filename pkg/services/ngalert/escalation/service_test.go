@@ -0,0 +1,206 @@
+package escalation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func newTestService() *Service {
+	return NewService(NewStore(kvstore.NewFakeKVStore()), testConfig, log.NewNopLogger())
+}
+
+// testConfig has a default policy and a nested policy of the team which routes critical alerts to the team receiver.
+func testConfig(context.Context, int64) (definitions.GettableUserConfig, error) {
+	cfg := definitions.GettableUserConfig{}
+	cfg.AlertmanagerConfig.Route = &definitions.Route{
+		Receiver: "default",
+		Routes: []*definitions.Route{
+			{
+				ObjectMatchers: definitions.ObjectMatchers{matcher(labels.MatchEqual, "team", "a")},
+				Routes: []*definitions.Route{
+					{
+						Receiver:       "team",
+						ObjectMatchers: definitions.ObjectMatchers{matcher(labels.MatchEqual, "severity", "critical")},
+					},
+				},
+			},
+		},
+	}
+	for _, name := range []string{"default", "team", "lead", "manager"} {
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, &definitions.GettableApiReceiver{
+			Receiver: config.Receiver{Name: name},
+		})
+	}
+	return cfg, nil
+}
+
+func matcher(typ labels.MatchType, name, value string) *labels.Matcher {
+	m, err := labels.NewMatcher(typ, name, value)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func teamMatchers() definitions.ObjectMatchers {
+	return definitions.ObjectMatchers{matcher(labels.MatchEqual, "severity", "critical")}
+}
+
+func testSchedule() Schedule {
+	return Schedule{
+		Name:         "primary",
+		Start:        time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		ShiftLength:  model.Duration(7 * 24 * time.Hour),
+		Participants: []Participant{{Name: "alice", Email: "alice@example.com"}},
+	}
+}
+
+func TestService_Chains(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService()
+
+	chain := Chain{
+		Name:     "team escalation",
+		Receiver: "team",
+		Matchers: teamMatchers(),
+		Steps:    []Step{{Receiver: "lead", Delay: model.Duration(15 * time.Minute)}},
+	}
+
+	created, err := s.CreateChain(ctx, 1, chain)
+	require.NoError(t, err)
+	require.NotEmpty(t, created.UID)
+
+	_, err = s.CreateChain(ctx, 1, created)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	// Only one chain can be attached to a notification policy.
+	_, err = s.CreateChain(ctx, 1, chain)
+	require.ErrorIs(t, err, ErrRouteInUse)
+
+	got, err := s.GetChain(ctx, 1, created.UID)
+	require.NoError(t, err)
+	assert.Equal(t, created, got)
+
+	_, err = s.GetChain(ctx, 2, created.UID)
+	require.ErrorIs(t, err, ErrChainNotFound)
+
+	created.Steps[0].ScheduleUID = "missing"
+	_, err = s.UpdateChain(ctx, 1, created)
+	require.ErrorIs(t, err, ErrInvalid)
+
+	created.Steps[0].ScheduleUID = ""
+	created.Name = "renamed"
+	_, err = s.UpdateChain(ctx, 1, created)
+	require.NoError(t, err)
+
+	chains, err := s.ListChains(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, chains, 1)
+	assert.Equal(t, "renamed", chains[0].Name)
+
+	require.NoError(t, s.DeleteChain(ctx, 1, created.UID))
+	require.ErrorIs(t, s.DeleteChain(ctx, 1, created.UID), ErrChainNotFound)
+}
+
+func TestService_ChainRoute(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name  string
+		chain Chain
+		err   string
+	}{
+		{
+			name:  "nested policy inherits the receiver of its parent",
+			chain: Chain{Name: "default", Receiver: "default", Matchers: definitions.ObjectMatchers{matcher(labels.MatchEqual, "team", "a")}},
+		},
+		{
+			name:  "default policy",
+			chain: Chain{Name: "default", Receiver: "default"},
+		},
+		{
+			name:  "receiver without notification policy",
+			chain: Chain{Name: "lead", Receiver: "lead"},
+			err:   "no notification policy with receiver lead",
+		},
+		{
+			name:  "matchers of another policy",
+			chain: Chain{Name: "team", Receiver: "team", Matchers: definitions.ObjectMatchers{matcher(labels.MatchEqual, "team", "a")}},
+			err:   "no notification policy with receiver team",
+		},
+		{
+			name:  "unknown step receiver",
+			chain: Chain{Name: "team", Receiver: "team", Matchers: teamMatchers(), Steps: []Step{{Receiver: "missing", Delay: model.Duration(time.Minute)}}},
+			err:   "step 0: receiver missing does not exist",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.chain.Steps == nil {
+				tc.chain.Steps = []Step{{Receiver: "manager", Delay: model.Duration(time.Minute)}}
+			}
+			_, err := newTestService().CreateChain(ctx, 1, tc.chain)
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalid)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestService_DeleteScheduleInUse(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService()
+
+	sc, err := s.CreateSchedule(ctx, 1, testSchedule())
+	require.NoError(t, err)
+
+	chain, err := s.CreateChain(ctx, 1, Chain{
+		Name:     "team escalation",
+		Receiver: "team",
+		Matchers: teamMatchers(),
+		Steps:    []Step{{Receiver: "lead", Delay: model.Duration(15 * time.Minute), ScheduleUID: sc.UID}},
+	})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, s.DeleteSchedule(ctx, 1, sc.UID), ErrScheduleInUse)
+
+	require.NoError(t, s.DeleteChain(ctx, 1, chain.UID))
+	require.NoError(t, s.DeleteSchedule(ctx, 1, sc.UID))
+}
+
+func TestService_Acknowledge(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Acknowledge(ctx, 1, "", nil, "admin", "")
+	require.ErrorIs(t, err, ErrInvalid)
+
+	ack, err := s.Acknowledge(ctx, 1, "team", map[string]string{"alertname": "test"}, "admin", "looking into it")
+	require.NoError(t, err)
+	assert.Equal(t, GroupKey("team", map[string]string{"alertname": "test"}), ack.GroupKey)
+	assert.Equal(t, now, ack.AcknowledgedAt)
+
+	acks, err := s.ListAcknowledgements(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, acks, 1)
+	assert.Equal(t, "admin", acks[0].AcknowledgedBy)
+
+	require.NoError(t, s.Unacknowledge(ctx, 1, ack.GroupKey))
+	require.ErrorIs(t, s.Unacknowledge(ctx, 1, ack.GroupKey), ErrAcknowledgementNotFound)
+}
@@ -0,0 +1,148 @@
+package escalation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const (
+	kvNamespace = "alerting.escalation"
+
+	chainKeyPrefix    = "chain/"
+	scheduleKeyPrefix = "schedule/"
+	ackKeyPrefix      = "ack/"
+)
+
+// Store persists escalation chains, on-call schedules and acknowledgements in the key-value store.
+type Store struct {
+	kv kvstore.KVStore
+}
+
+func NewStore(kv kvstore.KVStore) *Store {
+	return &Store{kv: kv}
+}
+
+func (s *Store) ListChains(ctx context.Context, orgID int64) ([]Chain, error) {
+	return list[Chain](ctx, s.kv, orgID, chainKeyPrefix, func(c Chain) string { return c.Name })
+}
+
+func (s *Store) GetChain(ctx context.Context, orgID int64, uid string) (Chain, error) {
+	return get[Chain](ctx, s.kv, orgID, chainKeyPrefix+uid, ErrChainNotFound.Errorf("escalation chain %s not found", uid))
+}
+
+func (s *Store) SaveChain(ctx context.Context, orgID int64, c Chain) error {
+	return save(ctx, s.kv, orgID, chainKeyPrefix+c.UID, c)
+}
+
+func (s *Store) DeleteChain(ctx context.Context, orgID int64, uid string) error {
+	return s.kv.Del(ctx, orgID, kvNamespace, chainKeyPrefix+uid)
+}
+
+func (s *Store) ListSchedules(ctx context.Context, orgID int64) ([]Schedule, error) {
+	return list[Schedule](ctx, s.kv, orgID, scheduleKeyPrefix, func(sc Schedule) string { return sc.Name })
+}
+
+func (s *Store) GetSchedule(ctx context.Context, orgID int64, uid string) (Schedule, error) {
+	return get[Schedule](ctx, s.kv, orgID, scheduleKeyPrefix+uid, ErrScheduleNotFound.Errorf("on-call schedule %s not found", uid))
+}
+
+func (s *Store) SaveSchedule(ctx context.Context, orgID int64, sc Schedule) error {
+	return save(ctx, s.kv, orgID, scheduleKeyPrefix+sc.UID, sc)
+}
+
+func (s *Store) DeleteSchedule(ctx context.Context, orgID int64, uid string) error {
+	return s.kv.Del(ctx, orgID, kvNamespace, scheduleKeyPrefix+uid)
+}
+
+func (s *Store) ListAcknowledgements(ctx context.Context, orgID int64) ([]Acknowledgement, error) {
+	return list[Acknowledgement](ctx, s.kv, orgID, ackKeyPrefix, func(a Acknowledgement) string { return a.GroupKey })
+}
+
+func (s *Store) GetAcknowledgement(ctx context.Context, orgID int64, groupKey string) (Acknowledgement, error) {
+	return get[Acknowledgement](ctx, s.kv, orgID, ackKeyPrefix+groupKey, ErrAcknowledgementNotFound.Errorf("alert group %s is not acknowledged", groupKey))
+}
+
+func (s *Store) SaveAcknowledgement(ctx context.Context, orgID int64, a Acknowledgement) error {
+	return save(ctx, s.kv, orgID, ackKeyPrefix+a.GroupKey, a)
+}
+
+func (s *Store) DeleteAcknowledgement(ctx context.Context, orgID int64, groupKey string) error {
+	return s.kv.Del(ctx, orgID, kvNamespace, ackKeyPrefix+groupKey)
+}
+
+// OrgsWithChains returns the IDs of organizations that have at least one escalation chain.
+func (s *Store) OrgsWithChains(ctx context.Context) ([]int64, error) {
+	keys, err := s.kv.Keys(ctx, kvstore.AllOrganizations, kvNamespace, chainKeyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[int64]struct{})
+	result := make([]int64, 0)
+	for _, k := range keys {
+		if _, ok := seen[k.OrgId]; ok {
+			continue
+		}
+		seen[k.OrgId] = struct{}{}
+		result = append(result, k.OrgId)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result, nil
+}
+
+func get[T any](ctx context.Context, kv kvstore.KVStore, orgID int64, key string, notFound error) (T, error) {
+	var result T
+	raw, ok, err := kv.Get(ctx, orgID, kvNamespace, key)
+	if err != nil {
+		return result, err
+	}
+	if !ok {
+		return result, notFound
+	}
+	if err := json.Unmarshal([]byte(raw), &result); err != nil {
+		return result, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return result, nil
+}
+
+func list[T any](ctx context.Context, kv kvstore.KVStore, orgID int64, prefix string, sortKey func(T) string) ([]T, error) {
+	keys, err := kv.Keys(ctx, orgID, kvNamespace, prefix)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]T, 0, len(keys))
+	for _, k := range keys {
+		// Keys matches by prefix, make sure we do not pick up keys of other types.
+		if !strings.HasPrefix(k.Key, prefix) {
+			continue
+		}
+		raw, ok, err := kv.Get(ctx, orgID, kvNamespace, k.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		var item T
+		if err := json.Unmarshal([]byte(raw), &item); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s: %w", k.Key, err)
+		}
+		result = append(result, item)
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return sortKey(result[i]) < sortKey(result[j])
+	})
+	return result, nil
+}
+
+func save(ctx context.Context, kv kvstore.KVStore, orgID int64, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return kv.Set(ctx, orgID, kvNamespace, key, string(b))
+}
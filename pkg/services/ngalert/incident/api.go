@@ -0,0 +1,130 @@
+package incident
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	rootURL = "/api/v1/incidents"
+
+	// annotationsLimit is the maximum number of annotations returned for each rule and dashboard of an incident.
+	annotationsLimit = 100
+)
+
+// Service exposes incidents over the HTTP API.
+type Service struct {
+	store       *Store
+	annotations annotations.Repository
+	now         func() time.Time
+}
+
+func NewService(store *Store, annotationsRepo annotations.Repository) *Service {
+	return &Service{
+		store:       store,
+		annotations: annotationsRepo,
+		now:         time.Now,
+	}
+}
+
+// RegisterAPIEndpoints registers the HTTP API of incidents.
+func (s *Service) RegisterAPIEndpoints(routeRegister routing.RouteRegister, accessControl ac.AccessControl) {
+	authorize := ac.Middleware(accessControl)
+	read := authorize(ac.EvalPermission(ac.ActionAlertingInstanceRead))
+
+	routeRegister.Group(rootURL, func(group routing.RouteRegister) {
+		group.Get("/", read, routing.Wrap(s.handleListIncidents))
+		group.Get("/:uid", read, routing.Wrap(s.handleGetIncident))
+		group.Get("/:uid/annotations", read, routing.Wrap(s.handleGetAnnotations))
+	})
+}
+
+func (s *Service) handleListIncidents(c *contextmodel.ReqContext) response.Response {
+	status := Status(c.Query("status"))
+	if status != "" && status != StatusOpen && status != StatusResolved {
+		return response.Err(ErrInvalidStatus.Errorf("invalid status %s", status))
+	}
+	incidents, err := s.store.ListIncidents(c.Req.Context(), c.SignedInUser.GetOrgID(), status)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list incidents", err)
+	}
+	return response.JSON(http.StatusOK, incidents)
+}
+
+func (s *Service) handleGetIncident(c *contextmodel.ReqContext) response.Response {
+	inc, err := s.store.GetIncident(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get incident", err)
+	}
+	return response.JSON(http.StatusOK, inc)
+}
+
+func (s *Service) handleGetAnnotations(c *contextmodel.ReqContext) response.Response {
+	inc, err := s.store.GetIncident(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get incident", err)
+	}
+	items, err := s.LinkedAnnotations(c.Req.Context(), c.SignedInUser, inc)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get annotations of incident", err)
+	}
+	return response.JSON(http.StatusOK, items)
+}
+
+// LinkedAnnotations returns the annotations created during the incident by its rules, and the annotations
+// of its dashboards. Annotations are sorted by time.
+func (s *Service) LinkedAnnotations(ctx context.Context, user identity.Requester, inc Incident) ([]*annotations.ItemDTO, error) {
+	from := inc.StartedAt
+	to := s.now()
+	if inc.ResolvedAt != nil {
+		to = *inc.ResolvedAt
+	}
+
+	queries := make([]*annotations.ItemQuery, 0, len(inc.Alerts)+len(inc.Dashboards))
+	rules := make(map[int64]struct{})
+	for _, a := range inc.Alerts {
+		if _, ok := rules[a.RuleID]; ok || a.RuleID == 0 {
+			continue
+		}
+		rules[a.RuleID] = struct{}{}
+		queries = append(queries, &annotations.ItemQuery{AlertID: a.RuleID, Type: "alert"})
+	}
+	for _, d := range inc.Dashboards {
+		queries = append(queries, &annotations.ItemQuery{DashboardUID: d.UID})
+	}
+
+	seen := make(map[int64]struct{})
+	result := make([]*annotations.ItemDTO, 0)
+	for _, q := range queries {
+		q.OrgID = user.GetOrgID()
+		q.SignedInUser = user
+		q.From = from.UnixMilli()
+		q.To = to.UnixMilli()
+		q.Limit = annotationsLimit
+		items, err := s.annotations.Find(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if _, ok := seen[item.ID]; ok {
+				continue
+			}
+			seen[item.ID] = struct{}{}
+			result = append(result, item)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time < result[j].Time
+	})
+	return result, nil
+}
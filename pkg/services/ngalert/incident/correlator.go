@@ -0,0 +1,270 @@
+package incident
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// cleanupInterval is how often resolved incidents that are older than the retention are deleted.
+const cleanupInterval = time.Hour
+
+// Correlator groups firing alert instances into incidents. It receives the state transitions of alert rules
+// in the same way as the state historian does, and is registered with the state manager next to it.
+//
+// An alert instance that starts firing joins the open incident that shares the value of the most correlation
+// labels with it, if an alert instance of that incident started firing less than the correlation window ago.
+// Otherwise, a new incident is created. Alert instances that have none of the correlation labels are not correlated.
+// Once an alert instance is part of an incident, all of its state transitions are added to the timeline of the
+// incident. The incident is resolved when none of its alert instances are firing anymore.
+type Correlator struct {
+	store *Store
+	cfg   setting.UnifiedAlertingIncidentCorrelationSettings
+	clock clock.Clock
+	log   log.Logger
+
+	// mtx serializes the updates of incidents, as state transitions of different rules are recorded concurrently.
+	mtx sync.Mutex
+}
+
+func NewCorrelator(store *Store, cfg setting.UnifiedAlertingIncidentCorrelationSettings, clk clock.Clock, logger log.Logger) *Correlator {
+	return &Correlator{
+		store: store,
+		cfg:   cfg,
+		clock: clk,
+		log:   logger,
+	}
+}
+
+// Run deletes resolved incidents that are older than the retention until the context is cancelled.
+func (c *Correlator) Run(ctx context.Context) error {
+	if c.cfg.Retention <= 0 {
+		return nil
+	}
+	ticker := c.clock.Ticker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			deleted, err := c.store.DeleteResolvedBefore(ctx, c.clock.Now().Add(-c.cfg.Retention))
+			if err != nil {
+				c.log.Error("Failed to delete resolved incidents", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				c.log.Debug("Deleted resolved incidents", "count", deleted)
+			}
+		}
+	}
+}
+
+// Record correlates the state transitions of the rule with the open incidents of its organization.
+// It implements state.Historian.
+func (c *Correlator) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	// Copy the transitions before starting the goroutine, the states may be mutated by the next evaluation.
+	transitions := make([]transition, 0, len(states))
+	for _, s := range states {
+		if !s.Changed() {
+			continue
+		}
+		transitions = append(transitions, newTransition(s, c.clock.Now()))
+	}
+
+	errCh := make(chan error, 1)
+	if len(transitions) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// Like the state historians, use a new context so that the work is not interrupted by the evaluation.
+	writeCtx, cancel := context.WithTimeout(context.Background(), historian.StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		if err := c.correlate(ctx, rule, transitions); err != nil {
+			c.log.FromContext(ctx).Error("Failed to correlate alert instances", "error", err)
+			errCh <- err
+		}
+	}(writeCtx)
+	return errCh
+}
+
+// transition is the part of a state transition that is needed for correlation.
+type transition struct {
+	fingerprint   string
+	labels        map[string]string
+	state         string
+	previousState string
+	firing        bool
+	alerting      bool
+	at            time.Time
+}
+
+func newTransition(s state.StateTransition, now time.Time) transition {
+	at := s.LastEvaluationTime
+	if at.IsZero() {
+		at = now
+	}
+	labels := make(map[string]string, len(s.Labels))
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	return transition{
+		fingerprint:   s.CacheID.String(),
+		labels:        labels,
+		state:         s.Formatted(),
+		previousState: s.PreviousFormatted(),
+		firing:        isFiring(s.State.State),
+		alerting:      s.State.State == eval.Alerting,
+		at:            at,
+	}
+}
+
+// isFiring returns true if alert instances in the given state are sent to the Alertmanager as firing.
+func isFiring(s eval.State) bool {
+	return s == eval.Alerting || s == eval.Error || s == eval.NoData
+}
+
+func (c *Correlator) correlate(ctx context.Context, rule history_model.RuleMeta, transitions []transition) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	stored, err := c.store.ListIncidents(ctx, rule.OrgID, StatusOpen)
+	if err != nil {
+		return err
+	}
+	open := make([]*Incident, 0, len(stored))
+	for i := range stored {
+		open = append(open, &stored[i])
+	}
+
+	var dashboard *Dashboard
+	if rule.DashboardUID != "" {
+		dashboard = &Dashboard{UID: rule.DashboardUID, PanelID: rule.PanelID}
+	}
+
+	changed := make(map[string]*Incident)
+	for _, t := range transitions {
+		if inc, idx := findIncident(open, rule.UID, t.fingerprint); inc != nil {
+			a := &inc.Alerts[idx]
+			a.State = t.state
+			a.Firing = t.firing
+			a.LastChangeAt = t.at
+			if t.alerting {
+				inc.LastFiringAt = t.at
+			}
+			inc.addEvent(Event{
+				Time:          t.at,
+				Type:          EventStateChanged,
+				RuleUID:       rule.UID,
+				RuleTitle:     rule.Title,
+				Fingerprint:   t.fingerprint,
+				PreviousState: t.previousState,
+				State:         t.state,
+			})
+			changed[inc.UID] = inc
+			continue
+		}
+
+		if !t.alerting {
+			continue
+		}
+		correlationLabels := c.correlationLabels(t.labels)
+		if len(correlationLabels) == 0 {
+			continue
+		}
+		inc := c.match(open, correlationLabels, t.at)
+		if inc == nil {
+			inc = &Incident{
+				UID:               util.GenerateShortUID(),
+				Status:            StatusOpen,
+				StartedAt:         t.at,
+				CorrelationLabels: make(map[string]string, len(correlationLabels)),
+			}
+			inc.addEvent(Event{Time: t.at, Type: EventCreated})
+			open = append(open, inc)
+		}
+		inc.addAlert(Alert{
+			RuleID:       rule.ID,
+			RuleUID:      rule.UID,
+			RuleTitle:    rule.Title,
+			Fingerprint:  t.fingerprint,
+			Labels:       t.labels,
+			State:        t.state,
+			Firing:       t.firing,
+			AddedAt:      t.at,
+			LastChangeAt: t.at,
+		}, correlationLabels, dashboard)
+		changed[inc.UID] = inc
+	}
+
+	for _, inc := range changed {
+		if !inc.isFiring() {
+			inc.resolve(c.clock.Now())
+		}
+		if err := c.store.SaveIncident(ctx, rule.OrgID, *inc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// correlationLabels returns the correlation labels of the alert instance.
+func (c *Correlator) correlationLabels(labels map[string]string) map[string]string {
+	result := make(map[string]string, len(c.cfg.Labels))
+	for _, name := range c.cfg.Labels {
+		if v := labels[name]; v != "" {
+			result[name] = v
+		}
+	}
+	return result
+}
+
+// match returns the open incident that shares the most correlation labels with the alert instance and is still
+// within the correlation window. If several incidents share as many labels, the oldest one is returned.
+func (c *Correlator) match(open []*Incident, correlationLabels map[string]string, at time.Time) *Incident {
+	var best *Incident
+	bestShared := 0
+	for _, inc := range open {
+		if !inc.IsOpen() || at.Sub(inc.LastFiringAt) > c.cfg.Window {
+			continue
+		}
+		shared := inc.sharedLabels(correlationLabels)
+		if shared == 0 {
+			continue
+		}
+		if shared > bestShared || (shared == bestShared && inc.StartedAt.Before(best.StartedAt)) {
+			best = inc
+			bestShared = shared
+		}
+	}
+	return best
+}
+
+func findIncident(open []*Incident, ruleUID, fingerprint string) (*Incident, int) {
+	for _, inc := range open {
+		if !inc.IsOpen() {
+			continue
+		}
+		if idx := inc.findAlert(ruleUID, fingerprint); idx >= 0 {
+			return inc, idx
+		}
+	}
+	return nil, -1
+}
@@ -0,0 +1,166 @@
+package incident
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func setupCorrelator(t *testing.T) (*Correlator, *Store, *clock.Mock) {
+	t.Helper()
+	clk := clock.NewMock()
+	clk.Set(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewStore(kvstore.NewFakeKVStore())
+	cfg := setting.UnifiedAlertingIncidentCorrelationSettings{
+		Enabled:   true,
+		Labels:    []string{"cluster", "service"},
+		Window:    5 * time.Minute,
+		Retention: 24 * time.Hour,
+	}
+	return NewCorrelator(store, cfg, clk, log.NewNopLogger()), store, clk
+}
+
+func ruleMeta(id int64, uid string) history_model.RuleMeta {
+	return history_model.RuleMeta{ID: id, OrgID: 1, UID: uid, Title: "Rule " + uid, DashboardUID: "dash", PanelID: id}
+}
+
+func transitionTo(fp uint64, from, to eval.State, at time.Time, labels data.Labels) state.StateTransition {
+	return state.StateTransition{
+		State: &state.State{
+			OrgID:              1,
+			CacheID:            data.Fingerprint(fp),
+			State:              to,
+			Labels:             labels,
+			LastEvaluationTime: at,
+		},
+		PreviousState: from,
+	}
+}
+
+func record(t *testing.T, c *Correlator, rule history_model.RuleMeta, states ...state.StateTransition) {
+	t.Helper()
+	require.NoError(t, <-c.Record(context.Background(), rule, states))
+}
+
+func TestCorrelator(t *testing.T) {
+	t.Run("alert instances sharing a correlation label within the window are grouped", func(t *testing.T) {
+		c, store, clk := setupCorrelator(t)
+		now := clk.Now()
+
+		record(t, c, ruleMeta(1, "a"), transitionTo(1, eval.Normal, eval.Alerting, now, data.Labels{"cluster": "prod", "service": "api"}))
+		record(t, c, ruleMeta(2, "b"), transitionTo(2, eval.Pending, eval.Alerting, now.Add(2*time.Minute), data.Labels{"cluster": "prod", "service": "db"}))
+		// Outside of the correlation window.
+		record(t, c, ruleMeta(3, "c"), transitionTo(3, eval.Normal, eval.Alerting, now.Add(10*time.Minute), data.Labels{"cluster": "prod"}))
+		// No shared correlation label.
+		record(t, c, ruleMeta(4, "d"), transitionTo(4, eval.Normal, eval.Alerting, now.Add(3*time.Minute), data.Labels{"cluster": "dev"}))
+		// No correlation label at all.
+		record(t, c, ruleMeta(5, "e"), transitionTo(5, eval.Normal, eval.Alerting, now.Add(3*time.Minute), data.Labels{"team": "x"}))
+
+		incidents, err := store.ListIncidents(context.Background(), 1, StatusOpen)
+		require.NoError(t, err)
+		require.Len(t, incidents, 3)
+
+		var grouped Incident
+		for _, inc := range incidents {
+			if len(inc.Alerts) == 2 {
+				grouped = inc
+			}
+		}
+		require.Len(t, grouped.Alerts, 2)
+		assert.Equal(t, "a", grouped.Alerts[0].RuleUID)
+		assert.Equal(t, "b", grouped.Alerts[1].RuleUID)
+		assert.Equal(t, map[string]string{"cluster": "prod"}, grouped.CommonLabels)
+		assert.Equal(t, map[string]string{"cluster": "prod", "service": "api"}, grouped.CorrelationLabels)
+		assert.Equal(t, "Rule a and 1 other rules (cluster=prod, service=api)", grouped.Title)
+		assert.Equal(t, []Dashboard{{UID: "dash", PanelID: 1}, {UID: "dash", PanelID: 2}}, grouped.Dashboards)
+		require.Len(t, grouped.Timeline, 3)
+		assert.Equal(t, EventCreated, grouped.Timeline[0].Type)
+		assert.Equal(t, EventAlertAdded, grouped.Timeline[1].Type)
+		assert.Equal(t, EventAlertAdded, grouped.Timeline[2].Type)
+	})
+
+	t.Run("incident is resolved when none of its alert instances are firing", func(t *testing.T) {
+		c, store, clk := setupCorrelator(t)
+		now := clk.Now()
+		labels := data.Labels{"cluster": "prod"}
+
+		record(t, c, ruleMeta(1, "a"), transitionTo(1, eval.Normal, eval.Alerting, now, labels))
+		record(t, c, ruleMeta(2, "b"), transitionTo(2, eval.Normal, eval.Alerting, now, labels))
+		record(t, c, ruleMeta(1, "a"), transitionTo(1, eval.Alerting, eval.Normal, now.Add(time.Minute), labels))
+
+		incidents, err := store.ListIncidents(context.Background(), 1, StatusOpen)
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		assert.Equal(t, EventStateChanged, incidents[0].Timeline[len(incidents[0].Timeline)-1].Type)
+
+		clk.Add(2 * time.Minute)
+		record(t, c, ruleMeta(2, "b"), transitionTo(2, eval.Alerting, eval.Normal, clk.Now(), labels))
+
+		incidents, err = store.ListIncidents(context.Background(), 1, StatusOpen)
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+
+		resolved, err := store.ListIncidents(context.Background(), 1, StatusResolved)
+		require.NoError(t, err)
+		require.Len(t, resolved, 1)
+		require.NotNil(t, resolved[0].ResolvedAt)
+		assert.Equal(t, clk.Now(), *resolved[0].ResolvedAt)
+		assert.Equal(t, EventResolved, resolved[0].Timeline[len(resolved[0].Timeline)-1].Type)
+
+		// A new alert instance does not join the resolved incident.
+		record(t, c, ruleMeta(1, "a"), transitionTo(1, eval.Normal, eval.Alerting, clk.Now(), labels))
+		incidents, err = store.ListIncidents(context.Background(), 1, StatusOpen)
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		assert.NotEqual(t, resolved[0].UID, incidents[0].UID)
+	})
+
+	t.Run("unchanged states are ignored", func(t *testing.T) {
+		c, store, clk := setupCorrelator(t)
+		record(t, c, ruleMeta(1, "a"), transitionTo(1, eval.Alerting, eval.Alerting, clk.Now(), data.Labels{"cluster": "prod"}))
+
+		incidents, err := store.ListIncidents(context.Background(), 1, "")
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+	})
+}
+
+func TestStore_SaveIncident(t *testing.T) {
+	ctx := context.Background()
+	store := NewStore(kvstore.NewFakeKVStore())
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	inc := Incident{UID: "incident", Status: StatusOpen, StartedAt: now}
+	require.NoError(t, store.SaveIncident(ctx, 1, inc))
+
+	inc.resolve(now.Add(time.Hour))
+	require.NoError(t, store.SaveIncident(ctx, 1, inc))
+
+	open, err := store.ListIncidents(ctx, 1, StatusOpen)
+	require.NoError(t, err)
+	require.Empty(t, open)
+
+	all, err := store.ListIncidents(ctx, 1, "")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, StatusResolved, all[0].Status)
+
+	got, err := store.GetIncident(ctx, 1, "incident")
+	require.NoError(t, err)
+	assert.Equal(t, all[0], got)
+
+	_, err = store.GetIncident(ctx, 2, "incident")
+	require.ErrorIs(t, err, ErrIncidentNotFound)
+}
@@ -0,0 +1,214 @@
+package incident
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+var (
+	ErrIncidentNotFound = errutil.NotFound("alerting.incident.notFound", errutil.WithPublicMessage("Incident not found"))
+	ErrInvalidStatus    = errutil.BadRequest("alerting.incident.invalidStatus", errutil.WithPublicMessage("Invalid incident status, expected 'open' or 'resolved'"))
+)
+
+// maxTimelineEvents is the maximum number of events kept in the timeline of an incident. The oldest events are
+// dropped first, except for the event that created the incident.
+const maxTimelineEvents = 1000
+
+type Status string
+
+const (
+	StatusOpen     Status = "open"
+	StatusResolved Status = "resolved"
+)
+
+// Incident is a group of alert instances of one or many rules that started firing close to each other and share
+// the value of at least one correlation label.
+type Incident struct {
+	UID    string `json:"uid"`
+	Title  string `json:"title"`
+	Status Status `json:"status"`
+
+	StartedAt time.Time `json:"startedAt"`
+	// LastFiringAt is the last time an alert instance of the incident started firing. New alert instances are only
+	// correlated with the incident if they start firing within the correlation window after it.
+	LastFiringAt time.Time  `json:"lastFiringAt"`
+	ResolvedAt   *time.Time `json:"resolvedAt,omitempty"`
+
+	// CorrelationLabels are the values of the correlation labels of the alert instances of the incident.
+	CorrelationLabels map[string]string `json:"correlationLabels"`
+	// CommonLabels are the labels that all alert instances of the incident have in common.
+	CommonLabels map[string]string `json:"commonLabels"`
+
+	Alerts     []Alert     `json:"alerts"`
+	Dashboards []Dashboard `json:"dashboards"`
+	Timeline   []Event     `json:"timeline"`
+}
+
+// Alert is an alert instance that is part of an incident.
+type Alert struct {
+	RuleID      int64             `json:"ruleId"`
+	RuleUID     string            `json:"ruleUid"`
+	RuleTitle   string            `json:"ruleTitle"`
+	Fingerprint string            `json:"fingerprint"`
+	Labels      map[string]string `json:"labels"`
+	State       string            `json:"state"`
+	Firing      bool              `json:"firing"`
+	// AddedAt is the time the alert instance was added to the incident.
+	AddedAt time.Time `json:"addedAt"`
+	// LastChangeAt is the time of the last state transition of the alert instance.
+	LastChangeAt time.Time `json:"lastChangeAt"`
+}
+
+// Dashboard is a dashboard panel linked to a rule of an incident.
+type Dashboard struct {
+	UID     string `json:"uid"`
+	PanelID int64  `json:"panelId,omitempty"`
+}
+
+type EventType string
+
+const (
+	EventCreated      EventType = "created"
+	EventAlertAdded   EventType = "alert_added"
+	EventStateChanged EventType = "state_changed"
+	EventResolved     EventType = "resolved"
+)
+
+// Event is an entry of the timeline of an incident.
+type Event struct {
+	Time          time.Time `json:"time"`
+	Type          EventType `json:"type"`
+	RuleUID       string    `json:"ruleUid,omitempty"`
+	RuleTitle     string    `json:"ruleTitle,omitempty"`
+	Fingerprint   string    `json:"fingerprint,omitempty"`
+	PreviousState string    `json:"previousState,omitempty"`
+	State         string    `json:"state,omitempty"`
+}
+
+// IsOpen returns true if the incident is not resolved.
+func (i *Incident) IsOpen() bool {
+	return i.Status == StatusOpen
+}
+
+func (i *Incident) findAlert(ruleUID, fingerprint string) int {
+	for idx, a := range i.Alerts {
+		if a.RuleUID == ruleUID && a.Fingerprint == fingerprint {
+			return idx
+		}
+	}
+	return -1
+}
+
+// sharedLabels returns the number of correlation labels the incident has in common with the given labels.
+func (i *Incident) sharedLabels(correlationLabels map[string]string) int {
+	n := 0
+	for k, v := range correlationLabels {
+		if i.CorrelationLabels[k] == v {
+			n++
+		}
+	}
+	return n
+}
+
+func (i *Incident) isFiring() bool {
+	for _, a := range i.Alerts {
+		if a.Firing {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Incident) addEvent(e Event) {
+	i.Timeline = append(i.Timeline, e)
+	if len(i.Timeline) > maxTimelineEvents {
+		// Keep the event that created the incident.
+		i.Timeline = append(i.Timeline[:1], i.Timeline[len(i.Timeline)-maxTimelineEvents+1:]...)
+	}
+}
+
+func (i *Incident) addAlert(a Alert, correlationLabels map[string]string, dashboard *Dashboard) {
+	i.Alerts = append(i.Alerts, a)
+	i.LastFiringAt = a.AddedAt
+	for k, v := range correlationLabels {
+		if _, ok := i.CorrelationLabels[k]; !ok {
+			i.CorrelationLabels[k] = v
+		}
+	}
+	if dashboard != nil && !i.hasDashboard(*dashboard) {
+		i.Dashboards = append(i.Dashboards, *dashboard)
+	}
+	i.addEvent(Event{
+		Time:        a.AddedAt,
+		Type:        EventAlertAdded,
+		RuleUID:     a.RuleUID,
+		RuleTitle:   a.RuleTitle,
+		Fingerprint: a.Fingerprint,
+		State:       a.State,
+	})
+	i.refresh()
+}
+
+func (i *Incident) hasDashboard(d Dashboard) bool {
+	for _, existing := range i.Dashboards {
+		if existing == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Incident) resolve(at time.Time) {
+	i.Status = StatusResolved
+	i.ResolvedAt = &at
+	i.addEvent(Event{Time: at, Type: EventResolved})
+}
+
+// refresh updates the common labels and the title of the incident.
+func (i *Incident) refresh() {
+	var common map[string]string
+	for _, a := range i.Alerts {
+		if common == nil {
+			common = make(map[string]string, len(a.Labels))
+			for k, v := range a.Labels {
+				common[k] = v
+			}
+			continue
+		}
+		for k, v := range common {
+			if a.Labels[k] != v {
+				delete(common, k)
+			}
+		}
+	}
+	i.CommonLabels = common
+
+	rules := make(map[string]struct{})
+	for _, a := range i.Alerts {
+		rules[a.RuleUID] = struct{}{}
+	}
+	title := ""
+	if len(i.Alerts) > 0 {
+		title = i.Alerts[0].RuleTitle
+	}
+	if len(rules) > 1 {
+		title = fmt.Sprintf("%s and %d other rules", title, len(rules)-1)
+	}
+	if len(i.CorrelationLabels) > 0 {
+		keys := make([]string, 0, len(i.CorrelationLabels))
+		for k := range i.CorrelationLabels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, k+"="+i.CorrelationLabels[k])
+		}
+		title = fmt.Sprintf("%s (%s)", title, strings.Join(pairs, ", "))
+	}
+	i.Title = title
+}
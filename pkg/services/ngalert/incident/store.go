@@ -0,0 +1,120 @@
+package incident
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+)
+
+const kvNamespace = "alerting.incident"
+
+// Store persists incidents in the key-value store. Open and resolved incidents are stored under different key
+// prefixes, so that correlation only needs to load the open ones.
+type Store struct {
+	kv kvstore.KVStore
+}
+
+func NewStore(kv kvstore.KVStore) *Store {
+	return &Store{kv: kv}
+}
+
+func keyPrefix(status Status) string {
+	return string(status) + "/"
+}
+
+// ListIncidents returns the incidents of the organization with the given status, or all incidents if status is empty.
+// Incidents are sorted by start time, the most recent first.
+func (s *Store) ListIncidents(ctx context.Context, orgID int64, status Status) ([]Incident, error) {
+	statuses := []Status{StatusOpen, StatusResolved}
+	if status != "" {
+		statuses = []Status{status}
+	}
+	result := make([]Incident, 0)
+	for _, st := range statuses {
+		keys, err := s.kv.Keys(ctx, orgID, kvNamespace, keyPrefix(st))
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			inc, ok, err := s.get(ctx, orgID, k.Key)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, inc)
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+	return result, nil
+}
+
+// GetIncident returns the incident with the given UID, regardless of its status.
+func (s *Store) GetIncident(ctx context.Context, orgID int64, uid string) (Incident, error) {
+	for _, st := range []Status{StatusOpen, StatusResolved} {
+		inc, ok, err := s.get(ctx, orgID, keyPrefix(st)+uid)
+		if err != nil {
+			return Incident{}, err
+		}
+		if ok {
+			return inc, nil
+		}
+	}
+	return Incident{}, ErrIncidentNotFound.Errorf("incident %s not found", uid)
+}
+
+// SaveIncident stores the incident under the prefix of its status, and removes it from the other one.
+func (s *Store) SaveIncident(ctx context.Context, orgID int64, inc Incident) error {
+	b, err := json.Marshal(inc)
+	if err != nil {
+		return err
+	}
+	if err := s.kv.Set(ctx, orgID, kvNamespace, keyPrefix(inc.Status)+inc.UID, string(b)); err != nil {
+		return err
+	}
+	if inc.Status == StatusResolved {
+		return s.kv.Del(ctx, orgID, kvNamespace, keyPrefix(StatusOpen)+inc.UID)
+	}
+	return s.kv.Del(ctx, orgID, kvNamespace, keyPrefix(StatusResolved)+inc.UID)
+}
+
+// DeleteResolvedBefore deletes the resolved incidents of all organizations that were resolved before the given time.
+func (s *Store) DeleteResolvedBefore(ctx context.Context, before time.Time) (int, error) {
+	keys, err := s.kv.Keys(ctx, kvstore.AllOrganizations, kvNamespace, keyPrefix(StatusResolved))
+	if err != nil {
+		return 0, err
+	}
+	deleted := 0
+	for _, k := range keys {
+		inc, ok, err := s.get(ctx, k.OrgId, k.Key)
+		if err != nil {
+			return deleted, err
+		}
+		if !ok || inc.ResolvedAt == nil || !inc.ResolvedAt.Before(before) {
+			continue
+		}
+		if err := s.kv.Del(ctx, k.OrgId, kvNamespace, k.Key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *Store) get(ctx context.Context, orgID int64, key string) (Incident, bool, error) {
+	raw, ok, err := s.kv.Get(ctx, orgID, kvNamespace, key)
+	if err != nil || !ok {
+		return Incident{}, false, err
+	}
+	var inc Incident
+	if err := json.Unmarshal([]byte(raw), &inc); err != nil {
+		return Incident{}, false, fmt.Errorf("failed to unmarshal incident %s: %w", key, err)
+	}
+	return inc, true, nil
+}
@@ -164,6 +164,8 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	// StateReasonPausedBySchedule is the reason of the states of a rule that is outside of its active time intervals.
+	StateReasonPausedBySchedule = "Paused by schedule"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -270,6 +272,11 @@ type AlertRule struct {
 	IsPaused             bool
 	NotificationSettings []NotificationSettings
 	Metadata             AlertRuleMetadata
+	// RelabelConfigs are applied to the labels of the evaluation results before alert instances are created from them.
+	RelabelConfigs []RelabelConfig
+	// ActiveTimeIntervals are the names of the time intervals during which the rule is evaluated. Outside of them,
+	// the rule is not evaluated and its states are held. The rule is always evaluated if the list is empty.
+	ActiveTimeIntervals []string
 }
 
 type AlertRuleMetadata struct {
@@ -587,6 +594,16 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid notification settings: %w", err))
 		}
 	}
+
+	if _, err := alertRule.PrometheusRelabelConfigs(); err != nil {
+		return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid relabel configs: %w", err))
+	}
+
+	for _, name := range alertRule.ActiveTimeIntervals {
+		if name == "" {
+			return fmt.Errorf("%w: active time interval name cannot be empty", ErrAlertRuleFailedValidation)
+		}
+	}
 	return nil
 }
 
@@ -622,6 +639,8 @@ func clearRecordingRuleIgnoredFields(rule *AlertRule) {
 	rule.Condition = ""
 	rule.For = 0
 	rule.NotificationSettings = nil
+	rule.RelabelConfigs = nil
+	rule.ActiveTimeIntervals = nil
 }
 
 func (alertRule *AlertRule) ResourceType() string {
@@ -14,6 +14,7 @@ import (
 	alertingNotify "github.com/grafana/alerting/notify"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels_config"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/httprequest"
 )
 
 // GetReceiverQuery represents a query for a single receiver.
@@ -535,6 +536,15 @@ func ValidateIntegration(ctx context.Context, integration alertingNotify.Grafana
 		return fmt.Errorf("settings should not be empty")
 	}
 
+	if integration.Type == httprequest.Type {
+		decrypt, err := httprequest.NewDecryptFunc(ctx, integration.SecureSettings, decryptFunc)
+		if err != nil {
+			return err
+		}
+		_, err = httprequest.NewConfig(integration.Settings, decrypt)
+		return err
+	}
+
 	_, err := alertingNotify.BuildReceiverConfiguration(ctx, &alertingNotify.APIReceiver{
 		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
 			Integrations: []*alertingNotify.GrafanaIntegrationConfig{&integration},
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	prommodels "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/relabel"
+)
+
+// relabelActions are the supported relabel actions.
+var relabelActions = map[relabel.Action]struct{}{
+	relabel.Replace:   {},
+	relabel.Keep:      {},
+	relabel.Drop:      {},
+	relabel.KeepEqual: {},
+	relabel.DropEqual: {},
+	relabel.HashMod:   {},
+	relabel.LabelMap:  {},
+	relabel.LabelDrop: {},
+	relabel.LabelKeep: {},
+	relabel.Lowercase: {},
+	relabel.Uppercase: {},
+}
+
+// RelabelConfig is a Prometheus relabel config that is applied to the labels of the evaluation results of an alert rule
+// before alert instances are created from them. It can be used to drop or rewrite labels of query results that would
+// otherwise create many short-lived alert instances, such as pod names.
+// Empty fields use the defaults of Prometheus: the action is "replace", the separator is ";", the regex is "(.*)"
+// and the replacement is "$1".
+type RelabelConfig struct {
+	SourceLabels []string `json:"source_labels,omitempty"`
+	Separator    string   `json:"separator,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	Modulus      uint64   `json:"modulus,omitempty"`
+	TargetLabel  string   `json:"target_label,omitempty"`
+	// Replacement is a pointer because an empty replacement is valid, and removes the target label.
+	Replacement *string `json:"replacement,omitempty"`
+	Action      string  `json:"action,omitempty"`
+}
+
+// PrometheusConfig converts the config to a Prometheus relabel config. It returns an error if the config is not valid.
+func (c RelabelConfig) PrometheusConfig() (*relabel.Config, error) {
+	cfg := relabel.DefaultRelabelConfig
+	if c.Action != "" {
+		cfg.Action = relabel.Action(strings.ToLower(c.Action))
+	}
+	if _, ok := relabelActions[cfg.Action]; !ok {
+		return nil, fmt.Errorf("unknown relabel action %q", c.Action)
+	}
+	if c.Separator != "" {
+		cfg.Separator = c.Separator
+	}
+	if c.Regex != "" {
+		re, err := relabel.NewRegexp(c.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", c.Regex, err)
+		}
+		cfg.Regex = re
+	}
+	if c.Replacement != nil {
+		cfg.Replacement = *c.Replacement
+	}
+	cfg.Modulus = c.Modulus
+	cfg.TargetLabel = c.TargetLabel
+	cfg.SourceLabels = make(prommodels.LabelNames, 0, len(c.SourceLabels))
+	for _, l := range c.SourceLabels {
+		cfg.SourceLabels = append(cfg.SourceLabels, prommodels.LabelName(l))
+	}
+
+	switch cfg.Action {
+	case relabel.Replace, relabel.HashMod, relabel.Lowercase, relabel.Uppercase, relabel.KeepEqual, relabel.DropEqual:
+		if cfg.TargetLabel == "" {
+			return nil, fmt.Errorf("relabel action %s requires a target label", cfg.Action)
+		}
+	}
+	switch cfg.Action {
+	case relabel.HashMod, relabel.Lowercase, relabel.Uppercase, relabel.KeepEqual, relabel.DropEqual, relabel.Keep, relabel.Drop:
+		if len(cfg.SourceLabels) == 0 {
+			return nil, fmt.Errorf("relabel action %s requires source labels", cfg.Action)
+		}
+	case relabel.LabelMap, relabel.LabelDrop, relabel.LabelKeep:
+		if len(cfg.SourceLabels) > 0 || cfg.TargetLabel != "" {
+			return nil, fmt.Errorf("relabel action %s does not support source labels or a target label", cfg.Action)
+		}
+	}
+	if cfg.Action == relabel.HashMod && cfg.Modulus == 0 {
+		return nil, errors.New("relabel action hashmod requires a modulus greater than zero")
+	}
+	return &cfg, nil
+}
+
+// PrometheusRelabelConfigs converts the relabel configs of the rule to Prometheus relabel configs.
+func (alertRule *AlertRule) PrometheusRelabelConfigs() ([]*relabel.Config, error) {
+	result := make([]*relabel.Config, 0, len(alertRule.RelabelConfigs))
+	for i, c := range alertRule.RelabelConfigs {
+		cfg, err := c.PrometheusConfig()
+		if err != nil {
+			return nil, fmt.Errorf("relabel config %d: %w", i, err)
+		}
+		result = append(result, cfg)
+	}
+	return result, nil
+}
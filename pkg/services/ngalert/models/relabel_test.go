@@ -0,0 +1,47 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/relabel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRelabelConfig_PrometheusConfig(t *testing.T) {
+	t.Run("defaults are applied", func(t *testing.T) {
+		cfg, err := RelabelConfig{SourceLabels: []string{"pod"}, TargetLabel: "workload"}.PrometheusConfig()
+		require.NoError(t, err)
+		assert.Equal(t, relabel.Replace, cfg.Action)
+		assert.Equal(t, ";", cfg.Separator)
+		assert.Equal(t, "$1", cfg.Replacement)
+		assert.Equal(t, relabel.DefaultRelabelConfig.Regex.String(), cfg.Regex.String())
+	})
+
+	t.Run("empty replacement is kept", func(t *testing.T) {
+		cfg, err := RelabelConfig{TargetLabel: "pod", Replacement: util.Pointer("")}.PrometheusConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "", cfg.Replacement)
+	})
+
+	testCases := []struct {
+		name string
+		cfg  RelabelConfig
+		err  string
+	}{
+		{name: "unknown action", cfg: RelabelConfig{Action: "rename"}, err: "unknown relabel action"},
+		{name: "invalid regex", cfg: RelabelConfig{TargetLabel: "a", Regex: "("}, err: "invalid regex"},
+		{name: "replace without target", cfg: RelabelConfig{SourceLabels: []string{"a"}}, err: "requires a target label"},
+		{name: "keep without source", cfg: RelabelConfig{Action: "keep", Regex: "a"}, err: "requires source labels"},
+		{name: "labeldrop with source", cfg: RelabelConfig{Action: "labeldrop", SourceLabels: []string{"a"}}, err: "does not support source labels"},
+		{name: "hashmod without modulus", cfg: RelabelConfig{Action: "hashmod", SourceLabels: []string{"a"}, TargetLabel: "b"}, err: "modulus"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.cfg.PrometheusConfig()
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
@@ -662,6 +662,15 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
 
+	for _, c := range r.RelabelConfigs {
+		result.RelabelConfigs = append(result.RelabelConfigs, CopyRelabelConfig(c))
+	}
+
+	if r.ActiveTimeIntervals != nil {
+		result.ActiveTimeIntervals = make([]string, len(r.ActiveTimeIntervals))
+		copy(result.ActiveTimeIntervals, r.ActiveTimeIntervals)
+	}
+
 	if len(mutators) > 0 {
 		for _, mutator := range mutators {
 			mutator(&result)
@@ -1335,3 +1344,17 @@ func ConvertToRecordingRule(rule *AlertRule) {
 func nameToUid(name string) string { // Avoid legacy_storage.NameToUid import cycle.
 	return base64.RawURLEncoding.EncodeToString([]byte(name))
 }
+
+// CopyRelabelConfig creates a deep copy of RelabelConfig.
+func CopyRelabelConfig(c RelabelConfig) RelabelConfig {
+	result := c
+	if c.SourceLabels != nil {
+		result.SourceLabels = make([]string, len(c.SourceLabels))
+		copy(result.SourceLabels, c.SourceLabels)
+	}
+	if c.Replacement != nil {
+		r := *c.Replacement
+		result.Replacement = &r
+	}
+	return result
+}
@@ -20,6 +20,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/httprequest"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/setting"
@@ -385,6 +386,11 @@ func (am *alertmanager) AppURL() string {
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
 func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) ([]*alertingNotify.Integration, error) {
+	// Integrations that are implemented in Grafana are built separately because the alerting package does not know about them.
+	receiver, grafanaIntegrations, err := am.buildGrafanaIntegrations(receiver, tmpl)
+	if err != nil {
+		return nil, err
+	}
 	receiverCfg, err := alertingNotify.BuildReceiverConfiguration(context.Background(), receiver, am.decryptFn)
 	if err != nil {
 		return nil, err
@@ -408,7 +414,45 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
-	return integrations, nil
+	return append(integrations, grafanaIntegrations...), nil
+}
+
+// buildGrafanaIntegrations builds the integrations of the receiver that are implemented in Grafana,
+// and returns a copy of the receiver without them.
+func (am *alertmanager) buildGrafanaIntegrations(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template) (*alertingNotify.APIReceiver, []*alertingNotify.Integration, error) {
+	var rest []*alertingNotify.GrafanaIntegrationConfig
+	var result []*alertingNotify.Integration
+	for idx, cfg := range receiver.GrafanaIntegrations.Integrations {
+		if cfg.Type != httprequest.Type {
+			rest = append(rest, cfg)
+			continue
+		}
+		decrypt, err := httprequest.NewDecryptFunc(context.Background(), cfg.SecureSettings, am.decryptFn)
+		if err != nil {
+			return nil, nil, err
+		}
+		settings, err := httprequest.NewConfig(cfg.Settings, decrypt)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to validate integration %q (UID %s) of type %q: %w", cfg.Name, cfg.UID, cfg.Type, err)
+		}
+		meta := receivers.Metadata{
+			UID:                   cfg.UID,
+			Name:                  cfg.Name,
+			Type:                  cfg.Type,
+			DisableResolveMessage: cfg.DisableResolveMessage,
+		}
+		n, err := httprequest.New(settings, meta, tmpl, LoggerFactory("ngalert.notifier."+cfg.Type, "notifierUID", cfg.UID))
+		if err != nil {
+			return nil, nil, err
+		}
+		result = append(result, alertingNotify.NewIntegration(n, n, cfg.Type, idx, cfg.Name))
+	}
+	if len(result) == 0 {
+		return receiver, nil, nil
+	}
+	r := *receiver
+	r.GrafanaIntegrations = alertingNotify.GrafanaIntegrations{Integrations: rest}
+	return &r, result, nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
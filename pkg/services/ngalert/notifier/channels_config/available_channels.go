@@ -966,6 +966,114 @@ func GetAvailableNotifiers() []*NotifierPlugin {
 				},
 			},
 		},
+		{
+			Type:        "http",
+			Name:        "HTTP Request",
+			Description: "Sends a fully templated HTTP request to a URL",
+			Heading:     "HTTP request settings",
+			Info:        "The method, URL, header values and body are templates that can use the same data as notification templates.",
+			Options: []NotifierOption{
+				{
+					Label:        "HTTP Method",
+					Description:  "The HTTP method of the request. Default is POST.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "method",
+					Placeholder:  "POST",
+				},
+				{
+					Label:        "URL",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "url",
+					Required:     true,
+				},
+				{
+					Label:        "Headers",
+					Description:  "HTTP headers of the request. Values can use templates.",
+					Element:      ElementTypeKeyValueMap,
+					InputType:    InputTypeText,
+					PropertyName: "headers",
+				},
+				{
+					Label:        "Body",
+					Description:  "Templated body of the request. If empty, the notification data is sent as JSON.",
+					Element:      ElementTypeTextArea,
+					PropertyName: "body",
+				},
+				{
+					Label:        "Retry status codes",
+					Description:  "Comma-separated list of response status codes, or classes of status codes, that should be retried. Default is 429,5xx.",
+					Element:      ElementTypeInput,
+					InputType:    InputTypeText,
+					PropertyName: "retryStatusCodes",
+					Placeholder:  "429,5xx",
+				},
+				{
+					Label:        "HMAC signature",
+					Description:  "Signs the request body with HMAC-SHA256",
+					Element:      ElementTypeSubform,
+					PropertyName: "hmacConfig",
+					SubformOptions: []NotifierOption{
+						{
+							Label:        "Secret",
+							Element:      ElementTypeInput,
+							InputType:    InputTypePassword,
+							PropertyName: "secret",
+							Required:     true,
+							Secure:       true,
+						},
+						{
+							Label:        "Signature header",
+							Description:  "The header that contains the hex-encoded signature.",
+							Element:      ElementTypeInput,
+							InputType:    InputTypeText,
+							PropertyName: "header",
+							Placeholder:  "X-Grafana-Alerting-Signature",
+						},
+						{
+							Label:        "Timestamp header",
+							Description:  "If set, the Unix timestamp of the request is sent in this header and signed together with the body as \"<timestamp>:<body>\".",
+							Element:      ElementTypeInput,
+							InputType:    InputTypeText,
+							PropertyName: "timestampHeader",
+						},
+					},
+				},
+				{
+					Label:        "TLS",
+					Description:  "TLS configuration of the HTTP client",
+					Element:      ElementTypeSubform,
+					PropertyName: "tlsConfig",
+					SubformOptions: []NotifierOption{
+						{
+							Label:        "CA certificate",
+							Description:  "PEM-encoded certificate authority used to verify the server certificate.",
+							Element:      ElementTypeTextArea,
+							PropertyName: "caCertificate",
+						},
+						{
+							Label:        "Client certificate",
+							Description:  "PEM-encoded client certificate for mutual TLS.",
+							Element:      ElementTypeTextArea,
+							PropertyName: "clientCertificate",
+						},
+						{
+							Label:        "Client key",
+							Description:  "PEM-encoded private key of the client certificate.",
+							Element:      ElementTypeTextArea,
+							PropertyName: "clientKey",
+							Secure:       true,
+						},
+						{
+							Label:        "Skip TLS verification",
+							Element:      ElementTypeCheckbox,
+							PropertyName: "insecureSkipVerify",
+						},
+					},
+				},
+			},
+		},
 		{
 			Type:        "wecom",
 			Name:        "WeCom",
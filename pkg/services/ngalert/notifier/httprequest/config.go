@@ -0,0 +1,221 @@
+package httprequest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Type is the integration type of the templated HTTP request contact point.
+const Type = "http"
+
+const (
+	DefaultMethod          = http.MethodPost
+	DefaultSignatureHeader = "X-Grafana-Alerting-Signature"
+	DefaultMaxResponseSize = 1024
+)
+
+// DefaultRetryStatusCodes are the response status codes that are retried when no retry policy is configured.
+var DefaultRetryStatusCodes = []string{"429", "5xx"}
+
+var supportedMethods = map[string]struct{}{
+	http.MethodGet:    {},
+	http.MethodPost:   {},
+	http.MethodPut:    {},
+	http.MethodPatch:  {},
+	http.MethodDelete: {},
+}
+
+// DecryptFunc returns the decrypted value of a secure setting, or fallback if the setting is not set.
+type DecryptFunc func(key string, fallback string) string
+
+// Config is the configuration of the templated HTTP request integration.
+// Method, URL, header values and Body are templates that are executed with the notification template data.
+type Config struct {
+	Method  string
+	URL     string
+	Headers map[string]string
+	Body    string
+
+	HMAC  *HMACConfig
+	TLS   *TLSConfig
+	Retry RetryConfig
+}
+
+// HMACConfig configures signing of the request body.
+type HMACConfig struct {
+	Secret string
+	// Header is the name of the header that holds the hex-encoded signature.
+	Header string
+	// TimestampHeader, if set, is the name of a header that holds the Unix timestamp of the request.
+	// The timestamp is then included in the signed payload as "<timestamp>:<body>".
+	TimestampHeader string
+}
+
+// TLSConfig configures the TLS client used to send requests.
+type TLSConfig struct {
+	CACertificate      string
+	ClientCertificate  string
+	ClientKey          string
+	InsecureSkipVerify bool
+}
+
+// RetryConfig describes which response codes should be retried by the notification pipeline.
+type RetryConfig struct {
+	// StatusCodes is a list of status codes (e.g. "503") or classes of status codes (e.g. "5xx").
+	StatusCodes []string
+}
+
+type rawConfig struct {
+	Method     string            `json:"method,omitempty" yaml:"method,omitempty"`
+	URL        string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body       string            `json:"body,omitempty" yaml:"body,omitempty"`
+	HMACConfig *struct {
+		Header          string `json:"header,omitempty" yaml:"header,omitempty"`
+		TimestampHeader string `json:"timestampHeader,omitempty" yaml:"timestampHeader,omitempty"`
+		Secret          string `json:"secret,omitempty" yaml:"secret,omitempty"`
+	} `json:"hmacConfig,omitempty" yaml:"hmacConfig,omitempty"`
+	TLSConfig *struct {
+		CACertificate      string `json:"caCertificate,omitempty" yaml:"caCertificate,omitempty"`
+		ClientCertificate  string `json:"clientCertificate,omitempty" yaml:"clientCertificate,omitempty"`
+		ClientKey          string `json:"clientKey,omitempty" yaml:"clientKey,omitempty"`
+		InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty"`
+	} `json:"tlsConfig,omitempty" yaml:"tlsConfig,omitempty"`
+	// RetryStatusCodes is a comma-separated list of status codes or classes of status codes, e.g. "429,5xx".
+	RetryStatusCodes string `json:"retryStatusCodes,omitempty" yaml:"retryStatusCodes,omitempty"`
+}
+
+// NewConfig parses and validates the settings of the integration. Secure settings are read by using decryptFn.
+func NewConfig(settings json.RawMessage, decryptFn DecryptFunc) (Config, error) {
+	raw := rawConfig{}
+	if err := json.Unmarshal(settings, &raw); err != nil {
+		return Config{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+	}
+
+	if raw.URL == "" {
+		return Config{}, errors.New("required field 'url' is not specified")
+	}
+	// The URL may contain templates, so only validate it when it does not.
+	if !strings.Contains(raw.URL, "{{") {
+		if _, err := url.Parse(raw.URL); err != nil {
+			return Config{}, fmt.Errorf("field 'url' is not a valid URL: %w", err)
+		}
+	}
+
+	// Templated methods are upper-cased once rendered, upper-casing the template would change
+	// the names of its functions and fields.
+	method := raw.Method
+	if method == "" {
+		method = DefaultMethod
+	}
+	if !strings.Contains(method, "{{") {
+		method = strings.ToUpper(method)
+		if _, ok := supportedMethods[method]; !ok {
+			return Config{}, fmt.Errorf("unsupported HTTP method '%s'", raw.Method)
+		}
+	}
+
+	result := Config{
+		Method:  method,
+		URL:     raw.URL,
+		Headers: raw.Headers,
+		Body:    raw.Body,
+	}
+
+	if raw.HMACConfig != nil {
+		secret := decryptFn("hmacConfig.secret", raw.HMACConfig.Secret)
+		if secret == "" {
+			return Config{}, errors.New("required field 'hmacConfig.secret' is not specified")
+		}
+		header := raw.HMACConfig.Header
+		if header == "" {
+			header = DefaultSignatureHeader
+		}
+		result.HMAC = &HMACConfig{
+			Secret:          secret,
+			Header:          header,
+			TimestampHeader: raw.HMACConfig.TimestampHeader,
+		}
+	}
+
+	if raw.TLSConfig != nil {
+		tlsCfg := &TLSConfig{
+			CACertificate:      raw.TLSConfig.CACertificate,
+			ClientCertificate:  raw.TLSConfig.ClientCertificate,
+			ClientKey:          decryptFn("tlsConfig.clientKey", raw.TLSConfig.ClientKey),
+			InsecureSkipVerify: raw.TLSConfig.InsecureSkipVerify,
+		}
+		if (tlsCfg.ClientCertificate == "") != (tlsCfg.ClientKey == "") {
+			return Config{}, errors.New("both 'tlsConfig.clientCertificate' and 'tlsConfig.clientKey' must be specified")
+		}
+		if _, err := tlsCfg.toTLSConfig(); err != nil {
+			return Config{}, err
+		}
+		result.TLS = tlsCfg
+	}
+
+	codes := DefaultRetryStatusCodes
+	if strings.TrimSpace(raw.RetryStatusCodes) != "" {
+		codes = nil
+		for _, code := range strings.Split(raw.RetryStatusCodes, ",") {
+			code = strings.TrimSpace(code)
+			if err := validateStatusCodePattern(code); err != nil {
+				return Config{}, err
+			}
+			codes = append(codes, code)
+		}
+	}
+	result.Retry = RetryConfig{StatusCodes: codes}
+
+	return result, nil
+}
+
+// NewDecryptFunc returns a DecryptFunc that decodes the base64-encoded secure settings of an integration
+// and decrypts them by using decryptFn.
+func NewDecryptFunc(ctx context.Context, secureSettings map[string]string, decryptFn func(ctx context.Context, sjd map[string][]byte, key, fallback string) string) (DecryptFunc, error) {
+	secure := make(map[string][]byte, len(secureSettings))
+	for k, v := range secureSettings {
+		d, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode secure setting '%s': %w", k, err)
+		}
+		secure[k] = d
+	}
+	return func(key string, fallback string) string {
+		return decryptFn(ctx, secure, key, fallback)
+	}, nil
+}
+
+func validateStatusCodePattern(pattern string) error {
+	if len(pattern) == 3 && strings.HasSuffix(strings.ToLower(pattern), "xx") {
+		if pattern[0] >= '1' && pattern[0] <= '5' {
+			return nil
+		}
+	}
+	code, err := strconv.Atoi(pattern)
+	if err != nil || code < 100 || code > 599 {
+		return fmt.Errorf("invalid retry status code '%s', expected a status code like '503' or a class like '5xx'", pattern)
+	}
+	return nil
+}
+
+// ShouldRetry returns true if the status code matches the retry policy.
+func (r RetryConfig) ShouldRetry(statusCode int) bool {
+	s := strconv.Itoa(statusCode)
+	for _, pattern := range r.StatusCodes {
+		if pattern == s {
+			return true
+		}
+		if len(pattern) == 3 && strings.HasSuffix(strings.ToLower(pattern), "xx") && pattern[0] == s[0] {
+			return true
+		}
+	}
+	return false
+}
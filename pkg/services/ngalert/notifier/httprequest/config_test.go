@@ -0,0 +1,145 @@
+package httprequest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noDecrypt(_ string, fallback string) string {
+	return fallback
+}
+
+func TestNewConfig(t *testing.T) {
+	testCases := []struct {
+		name        string
+		settings    string
+		decryptFn   DecryptFunc
+		expected    Config
+		expectedErr string
+	}{
+		{
+			name:     "minimal configuration",
+			settings: `{"url": "http://localhost/api"}`,
+			expected: Config{
+				Method: DefaultMethod,
+				URL:    "http://localhost/api",
+				Retry:  RetryConfig{StatusCodes: DefaultRetryStatusCodes},
+			},
+		},
+		{
+			name: "full configuration",
+			settings: `{
+				"method": "put",
+				"url": "http://localhost/api/{{ .GroupLabels.alertname }}",
+				"headers": {"X-Team": "{{ .CommonLabels.team }}"},
+				"body": "{{ .Status }}",
+				"retryStatusCodes": "408, 5xx",
+				"hmacConfig": {"timestampHeader": "X-Timestamp", "secret": "plain"}
+			}`,
+			decryptFn: func(key string, fallback string) string {
+				if key == "hmacConfig.secret" {
+					return "decrypted"
+				}
+				return fallback
+			},
+			expected: Config{
+				Method:  "PUT",
+				URL:     "http://localhost/api/{{ .GroupLabels.alertname }}",
+				Headers: map[string]string{"X-Team": "{{ .CommonLabels.team }}"},
+				Body:    "{{ .Status }}",
+				HMAC: &HMACConfig{
+					Secret:          "decrypted",
+					Header:          DefaultSignatureHeader,
+					TimestampHeader: "X-Timestamp",
+				},
+				Retry: RetryConfig{StatusCodes: []string{"408", "5xx"}},
+			},
+		},
+		{
+			name:     "templated method",
+			settings: `{"url": "http://localhost/api", "method": "{{ if eq .Status \"resolved\" }}delete{{ else }}put{{ end }}"}`,
+			expected: Config{
+				Method: `{{ if eq .Status "resolved" }}delete{{ else }}put{{ end }}`,
+				URL:    "http://localhost/api",
+				Retry:  RetryConfig{StatusCodes: DefaultRetryStatusCodes},
+			},
+		},
+		{
+			name:        "missing url",
+			settings:    `{}`,
+			expectedErr: "required field 'url' is not specified",
+		},
+		{
+			name:        "unsupported method",
+			settings:    `{"url": "http://localhost", "method": "CONNECT"}`,
+			expectedErr: "unsupported HTTP method 'CONNECT'",
+		},
+		{
+			name:        "missing hmac secret",
+			settings:    `{"url": "http://localhost", "hmacConfig": {}}`,
+			expectedErr: "required field 'hmacConfig.secret' is not specified",
+		},
+		{
+			name:        "client certificate without key",
+			settings:    `{"url": "http://localhost", "tlsConfig": {"clientCertificate": "cert"}}`,
+			expectedErr: "both 'tlsConfig.clientCertificate' and 'tlsConfig.clientKey' must be specified",
+		},
+		{
+			name:        "invalid CA certificate",
+			settings:    `{"url": "http://localhost", "tlsConfig": {"caCertificate": "invalid"}}`,
+			expectedErr: "failed to parse 'tlsConfig.caCertificate'",
+		},
+		{
+			name:        "invalid retry status code",
+			settings:    `{"url": "http://localhost", "retryStatusCodes": "429,9xx"}`,
+			expectedErr: "invalid retry status code '9xx'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			decryptFn := tc.decryptFn
+			if decryptFn == nil {
+				decryptFn = noDecrypt
+			}
+			actual, err := NewConfig(json.RawMessage(tc.settings), decryptFn)
+			if tc.expectedErr != "" {
+				require.ErrorContains(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestNewDecryptFunc(t *testing.T) {
+	secure := map[string]string{
+		"hmacConfig.secret": base64.StdEncoding.EncodeToString([]byte("encrypted")),
+	}
+	decrypt, err := NewDecryptFunc(context.Background(), secure, func(_ context.Context, sjd map[string][]byte, key, fallback string) string {
+		if v, ok := sjd[key]; ok {
+			return "decrypted-" + string(v)
+		}
+		return fallback
+	})
+	require.NoError(t, err)
+	require.Equal(t, "decrypted-encrypted", decrypt("hmacConfig.secret", ""))
+	require.Equal(t, "fallback", decrypt("tlsConfig.clientKey", "fallback"))
+
+	_, err = NewDecryptFunc(context.Background(), map[string]string{"key": "not base64!"}, nil)
+	require.Error(t, err)
+}
+
+func TestRetryConfig_ShouldRetry(t *testing.T) {
+	cfg := RetryConfig{StatusCodes: []string{"408", "5xx"}}
+	require.True(t, cfg.ShouldRetry(408))
+	require.True(t, cfg.ShouldRetry(500))
+	require.True(t, cfg.ShouldRetry(503))
+	require.False(t, cfg.ShouldRetry(400))
+	require.False(t, cfg.ShouldRetry(429))
+}
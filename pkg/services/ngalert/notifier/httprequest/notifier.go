@@ -0,0 +1,165 @@
+package httprequest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
+)
+
+const defaultTimeout = 30 * time.Second
+
+// Notifier sends a fully templated HTTP request for a group of alerts.
+type Notifier struct {
+	*receivers.Base
+	cfg    Config
+	tmpl   *templates.Template
+	log    logging.Logger
+	client *http.Client
+	now    func() time.Time
+}
+
+// New creates a new Notifier. It returns an error if the TLS configuration cannot be loaded.
+func New(cfg Config, meta receivers.Metadata, tmpl *templates.Template, logger logging.Logger) (*Notifier, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.TLS != nil {
+		tlsCfg, err := cfg.TLS.toTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+	return &Notifier{
+		Base: receivers.NewBase(meta),
+		cfg:  cfg,
+		tmpl: tmpl,
+		log:  logger,
+		client: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: transport,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// Notify renders the request templates and sends the request.
+// The returned bool tells the notification pipeline whether the failed request should be retried,
+// which is decided by the configured retry policy.
+func (n *Notifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	var tmplErr error
+	tmpl, data := templates.TmplText(ctx, n.tmpl, as, n.log, &tmplErr)
+
+	method := strings.ToUpper(strings.TrimSpace(tmpl(n.cfg.Method)))
+	u := strings.TrimSpace(tmpl(n.cfg.URL))
+	headers := make(map[string]string, len(n.cfg.Headers))
+	for k, v := range n.cfg.Headers {
+		headers[k] = tmpl(v)
+	}
+
+	var body []byte
+	if n.cfg.Body != "" {
+		body = []byte(tmpl(n.cfg.Body))
+	} else {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal template data: %w", err)
+		}
+		body = b
+	}
+
+	if tmplErr != nil {
+		// Do not send a partially rendered request to an arbitrary endpoint.
+		return false, fmt.Errorf("failed to template HTTP request: %w", tmplErr)
+	}
+	if _, ok := supportedMethods[method]; !ok {
+		return false, fmt.Errorf("unsupported HTTP method '%s'", method)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Grafana")
+	if _, ok := headers["Content-Type"]; !ok {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if n.cfg.HMAC != nil {
+		n.sign(req, body)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// Network errors are always worth retrying.
+		return true, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.log.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if resp.StatusCode/100 == 2 {
+		n.log.Debug("HTTP request sent", "method", method, "statusCode", resp.StatusCode)
+		return true, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, DefaultMaxResponseSize))
+	err = fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	return n.cfg.Retry.ShouldRetry(resp.StatusCode), err
+}
+
+// SendResolved returns true if resolved notifications should be sent.
+func (n *Notifier) SendResolved() bool {
+	return !n.GetDisableResolveMessage()
+}
+
+// sign computes an HMAC-SHA256 signature of the body and sets it on the request.
+func (n *Notifier) sign(req *http.Request, body []byte) {
+	mac := hmac.New(sha256.New, []byte(n.cfg.HMAC.Secret))
+	if n.cfg.HMAC.TimestampHeader != "" {
+		ts := strconv.FormatInt(n.now().Unix(), 10)
+		req.Header.Set(n.cfg.HMAC.TimestampHeader, ts)
+		_, _ = mac.Write([]byte(ts + ":"))
+	}
+	_, _ = mac.Write(body)
+	req.Header.Set(n.cfg.HMAC.Header, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func (c *TLSConfig) toTLSConfig() (*tls.Config, error) {
+	// #nosec G402 -- skipping verification is an explicit opt-in of the user.
+	cfg := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CACertificate != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(c.CACertificate)) {
+			return nil, errors.New("failed to parse 'tlsConfig.caCertificate'")
+		}
+		cfg.RootCAs = pool
+	}
+	if c.ClientCertificate != "" {
+		cert, err := tls.X509KeyPair([]byte(c.ClientCertificate), []byte(c.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
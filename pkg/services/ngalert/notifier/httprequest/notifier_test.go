@@ -0,0 +1,149 @@
+package httprequest
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/alerting/logging"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotifier(t *testing.T, cfg Config) *Notifier {
+	t.Helper()
+	tmpl, err := template.FromGlobs([]string{})
+	require.NoError(t, err)
+	tmpl.ExternalURL, err = url.Parse("http://localhost")
+	require.NoError(t, err)
+
+	n, err := New(cfg, receivers.Metadata{Name: "test", Type: Type}, tmpl, &logging.FakeLogger{})
+	require.NoError(t, err)
+	n.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return n
+}
+
+func testAlerts() []*types.Alert {
+	return []*types.Alert{
+		{
+			Alert: model.Alert{
+				Labels:      model.LabelSet{"alertname": "HighLatency", "team": "payments"},
+				Annotations: model.LabelSet{"summary": "latency is high"},
+				StartsAt:    time.Now(),
+			},
+		},
+	}
+}
+
+func TestNotify(t *testing.T) {
+	var (
+		gotMethod  string
+		gotPath    string
+		gotHeaders http.Header
+		gotBody    string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod = r.Method
+		gotPath = r.URL.Path
+		gotHeaders = r.Header.Clone()
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(server.Close)
+
+	n := newTestNotifier(t, Config{
+		Method:  "{{ if eq .Status \"firing\" }}PUT{{ else }}DELETE{{ end }}",
+		URL:     server.URL + "/tickets/{{ .CommonLabels.alertname }}",
+		Headers: map[string]string{"X-Team": "{{ .CommonLabels.team }}"},
+		Body:    `{"summary": "{{ .CommonAnnotations.summary }}", "count": {{ len .Alerts.Firing }}}`,
+		HMAC: &HMACConfig{
+			Secret:          "secret",
+			Header:          DefaultSignatureHeader,
+			TimestampHeader: "X-Timestamp",
+		},
+		Retry: RetryConfig{StatusCodes: DefaultRetryStatusCodes},
+	})
+
+	retry, err := n.Notify(context.Background(), testAlerts()...)
+	require.NoError(t, err)
+	require.True(t, retry)
+
+	require.Equal(t, http.MethodPut, gotMethod)
+	require.Equal(t, "/tickets/HighLatency", gotPath)
+	require.Equal(t, "payments", gotHeaders.Get("X-Team"))
+	require.Equal(t, "application/json", gotHeaders.Get("Content-Type"))
+	require.Equal(t, `{"summary": "latency is high", "count": 1}`, gotBody)
+
+	require.Equal(t, "1700000000", gotHeaders.Get("X-Timestamp"))
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, _ = mac.Write([]byte("1700000000:" + gotBody))
+	require.Equal(t, hex.EncodeToString(mac.Sum(nil)), gotHeaders.Get(DefaultSignatureHeader))
+}
+
+func TestNotify_DefaultBody(t *testing.T) {
+	var gotBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+	}))
+	t.Cleanup(server.Close)
+
+	n := newTestNotifier(t, Config{Method: DefaultMethod, URL: server.URL})
+	_, err := n.Notify(context.Background(), testAlerts()...)
+	require.NoError(t, err)
+	require.Contains(t, string(gotBody), `"alertname":"HighLatency"`)
+}
+
+func TestNotify_RetryPolicy(t *testing.T) {
+	testCases := []struct {
+		name          string
+		statusCode    int
+		retryCodes    []string
+		expectedRetry bool
+	}{
+		{name: "server error is retried by default", statusCode: http.StatusServiceUnavailable, retryCodes: DefaultRetryStatusCodes, expectedRetry: true},
+		{name: "rate limit is retried by default", statusCode: http.StatusTooManyRequests, retryCodes: DefaultRetryStatusCodes, expectedRetry: true},
+		{name: "client error is not retried by default", statusCode: http.StatusBadRequest, retryCodes: DefaultRetryStatusCodes, expectedRetry: false},
+		{name: "custom status code is retried", statusCode: http.StatusConflict, retryCodes: []string{"409"}, expectedRetry: true},
+		{name: "server error is not retried if not configured", statusCode: http.StatusInternalServerError, retryCodes: []string{"409"}, expectedRetry: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+				_, _ = w.Write([]byte("something went wrong"))
+			}))
+			t.Cleanup(server.Close)
+
+			n := newTestNotifier(t, Config{
+				Method: DefaultMethod,
+				URL:    server.URL,
+				Retry:  RetryConfig{StatusCodes: tc.retryCodes},
+			})
+			retry, err := n.Notify(context.Background(), testAlerts()...)
+			require.ErrorContains(t, err, "something went wrong")
+			require.Equal(t, tc.expectedRetry, retry)
+		})
+	}
+}
+
+func TestNotify_TemplateError(t *testing.T) {
+	n := newTestNotifier(t, Config{
+		Method: DefaultMethod,
+		URL:    "http://localhost/{{ .Invalid.Field }}",
+	})
+	retry, err := n.Notify(context.Background(), testAlerts()...)
+	require.ErrorContains(t, err, "failed to template HTTP request")
+	require.False(t, retry)
+}
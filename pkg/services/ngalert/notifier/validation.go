@@ -94,6 +94,24 @@ func (n staticValidator) Validate(settings models.NotificationSettings) error {
 	return errors.Join(errs...)
 }
 
+// ValidateTimeIntervalsExist checks that all the given names reference existing mute timings or time intervals.
+func ValidateTimeIntervalsExist[R receiver](am apiAlertingConfig[R], names []string) error {
+	available := make(map[string]struct{})
+	for _, interval := range am.GetMuteTimeIntervals() {
+		available[interval.Name] = struct{}{}
+	}
+	for _, interval := range am.GetTimeIntervals() {
+		available[interval.Name] = struct{}{}
+	}
+	var errs []error
+	for _, name := range names {
+		if _, ok := available[name]; !ok {
+			errs = append(errs, ErrorTimeIntervalDoesNotExist{ErrorReferenceInvalid: ErrorReferenceInvalid{Reference: name}})
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // NotificationSettingsValidatorProvider provides a NotificationSettingsValidator for a given orgID.
 type NotificationSettingsValidatorProvider interface {
 	Validator(ctx context.Context, orgID int64) (NotificationSettingsValidator, error)
@@ -195,6 +195,15 @@ func (svc *MuteTimingService) UpdateMuteTiming(ctx context.Context, mt definitio
 				return err
 			}
 
+			// rules that are active only during the time interval are not renamed, refuse to leave them dangling
+			keys, err := svc.ruleNotificationsStore.ListActiveTimeIntervalRules(ctx, orgID, old.Name)
+			if err != nil {
+				return err
+			}
+			if len(keys) > 0 {
+				return MakeErrTimeIntervalInUse(false, keys)
+			}
+
 			err = svc.provenanceStore.DeleteProvenance(ctx, &definitions.MuteTimeInterval{MuteTimeInterval: old}, orgID)
 			if err != nil {
 				return err
@@ -267,6 +276,13 @@ func (svc *MuteTimingService) DeleteMuteTiming(ctx context.Context, nameOrUID st
 		if len(keys) > 0 {
 			return MakeErrTimeIntervalInUse(false, maps.Keys(keys))
 		}
+		activeKeys, err := svc.ruleNotificationsStore.ListActiveTimeIntervalRules(ctx, orgID, existing.Name)
+		if err != nil {
+			return err
+		}
+		if len(activeKeys) > 0 {
+			return MakeErrTimeIntervalInUse(false, activeKeys)
+		}
 
 		if err := svc.configStore.Save(ctx, revision, orgID); err != nil {
 			return err
@@ -740,13 +740,15 @@ func TestUpdateMuteTimings(t *testing.T) {
 		require.EqualValues(t, calculateMuteTimeIntervalFingerprint(interval), result.Version)
 		require.Equal(t, legacy_storage.NameToUid(result.Name), result.UID)
 
-		require.Len(t, ruleStore.Calls, 1)
+		require.Len(t, ruleStore.Calls, 2)
 		assert.Equal(t, "RenameTimeIntervalInNotificationSettings", ruleStore.Calls[0].Method)
 		assert.Equal(t, orgID, ruleStore.Calls[0].Args[1])
 		assert.Equal(t, original.Name, ruleStore.Calls[0].Args[2])
 		assert.Equal(t, interval.Name, ruleStore.Calls[0].Args[3])
 		assert.NotNil(t, ruleStore.Calls[0].Args[4])
 		assert.False(t, ruleStore.Calls[0].Args[5].(bool))
+		assert.Equal(t, "ListActiveTimeIntervalRules", ruleStore.Calls[1].Method)
+		assert.Equal(t, original.Name, ruleStore.Calls[1].Args[2])
 
 		prov.AssertCalled(t, "SetProvenance", mock.Anything, mock.MatchedBy(func(m *definitions.MuteTimeInterval) bool {
 			return m.Name == interval.Name
@@ -769,6 +771,38 @@ func TestUpdateMuteTimings(t *testing.T) {
 		assert.Truef(t, isMuteTimeInUseInRoutes(interval.Name, revision.Config.AlertmanagerConfig.Route), "There are no references to the new time interval")
 	})
 
+	t.Run("returns ErrTimeIntervalInUse if rules are active during the renamed interval", func(t *testing.T) {
+		sut, store, prov := createMuteTimingSvcSut()
+
+		store.GetFn = func(ctx context.Context, orgID int64) (*legacy_storage.ConfigRevision, error) {
+			return &legacy_storage.ConfigRevision{Config: initialConfig()}, nil
+		}
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(expectedProvenance, nil)
+
+		ruleStore := &fakeAlertRuleNotificationStore{
+			ListActiveTimeIntervalRulesFn: func(ctx context.Context, orgID int64, name string) ([]models.AlertRuleKey, error) {
+				assertInTransaction(t, ctx)
+				return []models.AlertRuleKey{models.GenerateRuleKey(orgID)}, nil
+			},
+		}
+		sut.ruleNotificationsStore = ruleStore
+
+		interval := expected
+		interval.Name = "another-time-interval"
+		timing := definitions.MuteTimeInterval{
+			UID:              expectedUID,
+			MuteTimeInterval: interval,
+			Version:          originalVersion,
+			Provenance:       definitions.Provenance(expectedProvenance),
+		}
+
+		_, err := sut.UpdateMuteTiming(context.Background(), timing, orgID)
+		require.ErrorIs(t, err, ErrTimeIntervalInUse)
+
+		require.Len(t, store.Calls, 1)
+		require.Equal(t, "Get", store.Calls[0].Method)
+	})
+
 	t.Run("returns ErrTimeIntervalDependentResourcesProvenance if route has different provenance status", func(t *testing.T) {
 		sut, store, prov := createMuteTimingSvcSut()
 
@@ -1027,6 +1061,32 @@ func TestDeleteMuteTimings(t *testing.T) {
 		require.Equal(t, "ListNotificationSettings", ruleNsStore.Calls[0].Method)
 	})
 
+	t.Run("returns ErrTimeIntervalInUse if rules are active during the mute timing", func(t *testing.T) {
+		sut, store, prov := createMuteTimingSvcSut()
+		ruleKey := models.GenerateRuleKey(orgID)
+		ruleNsStore := fakeAlertRuleNotificationStore{
+			ListActiveTimeIntervalRulesFn: func(ctx context.Context, o int64, name string) ([]models.AlertRuleKey, error) {
+				assertInTransaction(t, ctx)
+				assert.Equal(t, orgID, o)
+				assert.Equal(t, timingToDelete.Name, name)
+				return []models.AlertRuleKey{ruleKey}, nil
+			},
+		}
+		sut.ruleNotificationsStore = &ruleNsStore
+		store.GetFn = func(ctx context.Context, orgID int64) (*legacy_storage.ConfigRevision, error) {
+			return &legacy_storage.ConfigRevision{Config: initialConfig()}, nil
+		}
+		prov.EXPECT().GetProvenance(mock.Anything, mock.Anything, mock.Anything).Return(models.ProvenanceAPI, nil)
+
+		err := sut.DeleteMuteTiming(context.Background(), timingToDelete.Name, orgID, definitions.Provenance(models.ProvenanceAPI), correctVersion)
+
+		require.ErrorIs(t, err, ErrTimeIntervalInUse)
+		require.Len(t, store.Calls, 1)
+		require.Len(t, ruleNsStore.Calls, 2)
+		require.Equal(t, "ListActiveTimeIntervalRules", ruleNsStore.Calls[1].Method)
+		prov.AssertNotCalled(t, "DeleteProvenance", mock.Anything, mock.Anything, mock.Anything)
+	})
+
 	t.Run("returns ErrVersionConflict if provided version does not match", func(t *testing.T) {
 		sut, store, prov := createMuteTimingSvcSut()
 		store.GetFn = func(ctx context.Context, orgID int64) (*legacy_storage.ConfigRevision, error) {
@@ -173,6 +173,7 @@ type fakeAlertRuleNotificationStore struct {
 	RenameReceiverInNotificationSettingsFn     func(ctx context.Context, orgID int64, oldReceiver, newReceiver string, validateProvenance func(models.Provenance) bool, dryRun bool) ([]models.AlertRuleKey, []models.AlertRuleKey, error)
 	RenameTimeIntervalInNotificationSettingsFn func(ctx context.Context, orgID int64, old, new string, validate func(models.Provenance) bool, dryRun bool) ([]models.AlertRuleKey, []models.AlertRuleKey, error)
 	ListNotificationSettingsFn                 func(ctx context.Context, q models.ListNotificationSettingsQuery) (map[models.AlertRuleKey][]models.NotificationSettings, error)
+	ListActiveTimeIntervalRulesFn              func(ctx context.Context, orgID int64, timeInterval string) ([]models.AlertRuleKey, error)
 }
 
 func (f *fakeAlertRuleNotificationStore) RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string, validateProvenance func(models.Provenance) bool, dryRun bool) ([]models.AlertRuleKey, []models.AlertRuleKey, error) {
@@ -220,6 +221,21 @@ func (f *fakeAlertRuleNotificationStore) ListNotificationSettings(ctx context.Co
 	return nil, nil
 }
 
+func (f *fakeAlertRuleNotificationStore) ListActiveTimeIntervalRules(ctx context.Context, orgID int64, timeInterval string) ([]models.AlertRuleKey, error) {
+	call := call{
+		Method: "ListActiveTimeIntervalRules",
+		Args:   []interface{}{ctx, orgID, timeInterval},
+	}
+	f.Calls = append(f.Calls, call)
+
+	if f.ListActiveTimeIntervalRulesFn != nil {
+		return f.ListActiveTimeIntervalRulesFn(ctx, orgID, timeInterval)
+	}
+
+	// Default values when no function hook is provided
+	return nil, nil
+}
+
 type fakeReceiverService struct {
 	Calls                                  []call
 	GetReceiversFunc                       func(ctx context.Context, query models.GetReceiversQuery, user identity.Requester) ([]*models.Receiver, error)
@@ -62,6 +62,7 @@ func newRuleFactory(
 	logger log.Logger,
 	tracer tracing.Tracer,
 	recordingWriter RecordingWriter,
+	timeIntervals TimeIntervalProvider,
 	evalAppliedHook evalAppliedFunc,
 	stopAppliedHook stopAppliedFunc,
 ) ruleFactoryFunc {
@@ -96,6 +97,7 @@ func newRuleFactory(
 			met,
 			logger,
 			tracer,
+			timeIntervals,
 			evalAppliedHook,
 			stopAppliedHook,
 		)
@@ -126,6 +128,8 @@ type alertRule struct {
 	stateManager *state.Manager
 	evalFactory  eval.EvaluatorFactory
 	ruleProvider ruleProvider
+	// timeIntervals provides the active time intervals of the rule. It can be nil, in which case the rule is always evaluated.
+	timeIntervals TimeIntervalProvider
 
 	// Event hooks that are only used in tests.
 	evalAppliedHook evalAppliedFunc
@@ -150,6 +154,7 @@ func newAlertRule(
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
+	timeIntervals TimeIntervalProvider,
 	evalAppliedHook func(ngmodels.AlertRuleKey, time.Time),
 	stopAppliedHook func(ngmodels.AlertRuleKey),
 ) *alertRule {
@@ -168,6 +173,7 @@ func newAlertRule(
 		stateManager:         stateManager,
 		evalFactory:          evalFactory,
 		ruleProvider:         ruleProvider,
+		timeIntervals:        timeIntervals,
 		evalAppliedHook:      evalAppliedHook,
 		stopAppliedHook:      stopAppliedHook,
 		metrics:              met,
@@ -294,6 +300,11 @@ func (a *alertRule) Run() error {
 						logger.Debug("Skip rule evaluation because it is paused")
 						return
 					}
+					if !a.isActive(grafanaCtx, ctx.rule, ctx.scheduledAt, logger) {
+						logger.Debug("Skip rule evaluation because it is outside of its active time intervals")
+						a.holdState(grafanaCtx, ctx, logger)
+						return
+					}
 
 					// Only increment evaluation counter once, not per-retry.
 					if attempt == 1 {
@@ -474,6 +485,44 @@ func (a *alertRule) expireAndSend(ctx context.Context, states []state.StateTrans
 	}
 }
 
+// isActive returns true if the rule should be evaluated at the given time. A rule is active if it has no active time
+// intervals, or if the time is within at least one of them. Rules that reference time intervals that do not exist are
+// evaluated, so that a misconfiguration does not silently disable them.
+func (a *alertRule) isActive(ctx context.Context, rule *ngmodels.AlertRule, now time.Time, logger log.Logger) bool {
+	if len(rule.ActiveTimeIntervals) == 0 || a.timeIntervals == nil {
+		return true
+	}
+	intervals, err := a.timeIntervals.GetMuteTimings(ctx, rule.OrgID)
+	if err != nil {
+		logger.Error("Failed to get time intervals, evaluating the rule", "error", err)
+		return true
+	}
+	byName := make(map[string]definitions.MuteTimeInterval, len(intervals))
+	for _, interval := range intervals {
+		byName[interval.Name] = interval
+	}
+	for _, name := range rule.ActiveTimeIntervals {
+		interval, ok := byName[name]
+		if !ok {
+			logger.Warn("Active time interval of the rule does not exist, evaluating the rule", "timeInterval", name)
+			return true
+		}
+		for _, ti := range interval.TimeIntervals {
+			if ti.ContainsTime(now.UTC()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// holdState keeps the current states of the rule while it is outside of its active time intervals.
+func (a *alertRule) holdState(ctx context.Context, e *Evaluation, logger log.Logger) {
+	a.stateManager.HoldStatesByRuleUID(ctx, e.scheduledAt, e.rule, func(ctx context.Context, statesToSend state.StateTransitions) {
+		a.send(ctx, logger, statesToSend)
+	})
+}
+
 func (a *alertRule) resetState(ctx context.Context, isPaused bool) {
 	rule := a.ruleProvider.get(a.key)
 	reason := ngmodels.StateReasonUpdated
@@ -3,6 +3,7 @@ package schedule
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -12,6 +13,8 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/timeinterval"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	prometheusModel "github.com/prometheus/common/model"
@@ -267,7 +270,7 @@ func TestAlertRule(t *testing.T) {
 }
 
 func blankRuleForTests(ctx context.Context, key models.AlertRuleKey) *alertRule {
-	return newAlertRule(ctx, key, nil, false, 0, nil, nil, nil, nil, nil, nil, log.NewNopLogger(), nil, nil, nil)
+	return newAlertRule(ctx, key, nil, false, 0, nil, nil, nil, nil, nil, nil, log.NewNopLogger(), nil, nil, nil, nil)
 }
 
 func TestRuleRoutine(t *testing.T) {
@@ -827,7 +830,7 @@ func TestRuleRoutine(t *testing.T) {
 }
 
 func ruleFactoryFromScheduler(sch *schedule) ruleFactory {
-	return newRuleFactory(sch.appURL, sch.disableGrafanaFolder, sch.maxAttempts, sch.alertsSender, sch.stateManager, sch.evaluatorFactory, &sch.schedulableAlertRules, sch.clock, sch.rrCfg, sch.metrics, sch.log, sch.tracer, sch.recordingWriter, sch.evalAppliedFunc, sch.stopAppliedFunc)
+	return newRuleFactory(sch.appURL, sch.disableGrafanaFolder, sch.maxAttempts, sch.alertsSender, sch.stateManager, sch.evaluatorFactory, &sch.schedulableAlertRules, sch.clock, sch.rrCfg, sch.metrics, sch.log, sch.tracer, sch.recordingWriter, sch.timeIntervals, sch.evalAppliedFunc, sch.stopAppliedFunc)
 }
 
 func stateForRule(rule *models.AlertRule, ts time.Time, evalState eval.State) *state.State {
@@ -859,3 +862,79 @@ func stateForRule(rule *models.AlertRule, ts time.Time, evalState eval.State) *s
 
 	return s
 }
+
+type fakeTimeIntervalProvider struct {
+	intervals []definitions.MuteTimeInterval
+	err       error
+}
+
+func (f *fakeTimeIntervalProvider) GetMuteTimings(_ context.Context, _ int64) ([]definitions.MuteTimeInterval, error) {
+	return f.intervals, f.err
+}
+
+func TestAlertRuleIsActive(t *testing.T) {
+	gen := models.RuleGen
+	businessHours := definitions.MuteTimeInterval{
+		MuteTimeInterval: config.MuteTimeInterval{
+			Name: "business-hours",
+			TimeIntervals: []timeinterval.TimeInterval{
+				{Times: []timeinterval.TimeRange{{StartMinute: 9 * 60, EndMinute: 17 * 60}}},
+			},
+		},
+	}
+	inHours := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	outOfHours := time.Date(2024, 1, 2, 20, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc      string
+		intervals []string
+		provider  TimeIntervalProvider
+		now       time.Time
+		expected  bool
+	}{
+		{
+			desc:     "rule without active time intervals is active",
+			provider: &fakeTimeIntervalProvider{intervals: []definitions.MuteTimeInterval{businessHours}},
+			now:      outOfHours,
+			expected: true,
+		},
+		{
+			desc:      "rule is active within its time interval",
+			intervals: []string{"business-hours"},
+			provider:  &fakeTimeIntervalProvider{intervals: []definitions.MuteTimeInterval{businessHours}},
+			now:       inHours,
+			expected:  true,
+		},
+		{
+			desc:      "rule is not active outside of its time interval",
+			intervals: []string{"business-hours"},
+			provider:  &fakeTimeIntervalProvider{intervals: []definitions.MuteTimeInterval{businessHours}},
+			now:       outOfHours,
+			expected:  false,
+		},
+		{
+			desc:      "rule referencing a missing time interval is active",
+			intervals: []string{"business-hours", "missing"},
+			provider:  &fakeTimeIntervalProvider{intervals: []definitions.MuteTimeInterval{businessHours}},
+			now:       outOfHours,
+			expected:  true,
+		},
+		{
+			desc:      "rule is active if time intervals cannot be fetched",
+			intervals: []string{"business-hours"},
+			provider:  &fakeTimeIntervalProvider{err: errors.New("failed")},
+			now:       outOfHours,
+			expected:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			rule := gen.GenerateRef()
+			rule.ActiveTimeIntervals = tc.intervals
+			r := blankRuleForTests(context.Background(), rule.GetKey())
+			r.timeIntervals = tc.provider
+			require.Equal(t, tc.expected, r.isActive(context.Background(), rule, tc.now, log.NewNopLogger()))
+		})
+	}
+}
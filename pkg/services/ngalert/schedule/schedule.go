@@ -52,6 +52,11 @@ type RecordingWriter interface {
 	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
 }
 
+// TimeIntervalProvider provides the time intervals of an organization that alert rules can use as active time intervals.
+type TimeIntervalProvider interface {
+	GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error)
+}
+
 type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
@@ -100,6 +105,8 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	timeIntervals TimeIntervalProvider
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -119,6 +126,7 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	TimeIntervals        TimeIntervalProvider
 }
 
 // NewScheduler returns a new scheduler.
@@ -148,6 +156,7 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
 		recordingWriter:       cfg.RecordingWriter,
+		timeIntervals:         cfg.TimeIntervals,
 	}
 
 	return &sch
@@ -266,6 +275,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.log,
 		sch.tracer,
 		sch.recordingWriter,
+		sch.timeIntervals,
 		sch.evalAppliedFunc,
 		sch.stopAppliedFunc,
 	)
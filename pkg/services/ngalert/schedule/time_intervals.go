@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// AlertmanagerConfigReader reads the latest Alertmanager configuration of an organization.
+type AlertmanagerConfigReader interface {
+	GetLatestAlertmanagerConfiguration(ctx context.Context, orgID int64) (*models.AlertConfiguration, error)
+}
+
+// TimeIntervalCache provides the time intervals of the Alertmanager configuration of the organizations without
+// loading and parsing the configuration for every evaluation of every rule. The configuration of an organization is
+// checked at most once per refresh interval, and only parsed again when its hash changed.
+type TimeIntervalCache struct {
+	store           AlertmanagerConfigReader
+	clock           clock.Clock
+	refreshInterval time.Duration
+
+	mtx  sync.Mutex
+	orgs map[int64]cachedTimeIntervals
+}
+
+type cachedTimeIntervals struct {
+	hash      string
+	checkedAt time.Time
+	intervals []definitions.MuteTimeInterval
+}
+
+// NewTimeIntervalCache creates a TimeIntervalCache. The refresh interval is usually the base interval of the scheduler,
+// so that changes of the time intervals are taken into account on the next tick.
+func NewTimeIntervalCache(store AlertmanagerConfigReader, clk clock.Clock, refreshInterval time.Duration) *TimeIntervalCache {
+	return &TimeIntervalCache{
+		store:           store,
+		clock:           clk,
+		refreshInterval: refreshInterval,
+		orgs:            make(map[int64]cachedTimeIntervals),
+	}
+}
+
+// GetMuteTimings returns the mute timings and time intervals of the organization.
+func (c *TimeIntervalCache) GetMuteTimings(ctx context.Context, orgID int64) ([]definitions.MuteTimeInterval, error) {
+	now := c.clock.Now()
+	c.mtx.Lock()
+	cached, ok := c.orgs[orgID]
+	c.mtx.Unlock()
+	if ok && now.Sub(cached.checkedAt) < c.refreshInterval {
+		return cached.intervals, nil
+	}
+
+	amConfig, err := c.store.GetLatestAlertmanagerConfiguration(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || cached.hash != amConfig.ConfigurationHash {
+		cached.intervals, err = parseTimeIntervals(amConfig.AlertmanagerConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		cached.hash = amConfig.ConfigurationHash
+	}
+	cached.checkedAt = now
+
+	c.mtx.Lock()
+	c.orgs[orgID] = cached
+	c.mtx.Unlock()
+	return cached.intervals, nil
+}
+
+func parseTimeIntervals(raw string) ([]definitions.MuteTimeInterval, error) {
+	cfg := definitions.PostableUserConfig{}
+	if err := json.Unmarshal([]byte(raw), &cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse Alertmanager configuration: %w", err)
+	}
+	intervals := make([]definitions.MuteTimeInterval, 0, len(cfg.AlertmanagerConfig.MuteTimeIntervals)+len(cfg.AlertmanagerConfig.TimeIntervals))
+	for _, interval := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		intervals = append(intervals, definitions.MuteTimeInterval{MuteTimeInterval: interval})
+	}
+	for _, interval := range cfg.AlertmanagerConfig.TimeIntervals {
+		intervals = append(intervals, definitions.MuteTimeInterval{MuteTimeInterval: config.MuteTimeInterval(interval)})
+	}
+	return intervals, nil
+}
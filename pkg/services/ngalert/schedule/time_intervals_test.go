@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeAlertmanagerConfigReader struct {
+	calls  int
+	config *models.AlertConfiguration
+}
+
+func (f *fakeAlertmanagerConfigReader) GetLatestAlertmanagerConfiguration(_ context.Context, _ int64) (*models.AlertConfiguration, error) {
+	f.calls++
+	return f.config, nil
+}
+
+const timeIntervalsConfig = `{
+	"alertmanager_config": {
+		"route": {"receiver": "default"},
+		"receivers": [{"name": "default"}],
+		"mute_time_intervals": [{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]}],
+		"time_intervals": [{"name": "business-hours", "time_intervals": [{"times": [{"start_time": "09:00", "end_time": "17:00"}]}]}]
+	}
+}`
+
+func TestTimeIntervalCache(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	reader := &fakeAlertmanagerConfigReader{config: &models.AlertConfiguration{AlertmanagerConfiguration: timeIntervalsConfig, ConfigurationHash: "1"}}
+	cache := NewTimeIntervalCache(reader, clk, 10*time.Second)
+
+	intervals, err := cache.GetMuteTimings(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, intervals, 2)
+	require.Equal(t, "weekends", intervals[0].Name)
+	require.Equal(t, "business-hours", intervals[1].Name)
+	require.Equal(t, 1, reader.calls)
+
+	t.Run("configuration is not read again within the refresh interval", func(t *testing.T) {
+		clk.Add(5 * time.Second)
+		_, err := cache.GetMuteTimings(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, 1, reader.calls)
+	})
+
+	t.Run("configuration is not parsed again if the hash did not change", func(t *testing.T) {
+		reader.config = &models.AlertConfiguration{AlertmanagerConfiguration: "invalid", ConfigurationHash: "1"}
+		clk.Add(10 * time.Second)
+		intervals, err := cache.GetMuteTimings(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, intervals, 2)
+		require.Equal(t, 2, reader.calls)
+	})
+
+	t.Run("configuration is parsed again if the hash changed", func(t *testing.T) {
+		reader.config = &models.AlertConfiguration{AlertmanagerConfiguration: `{"alertmanager_config": {"route": {"receiver": "default"}, "receivers": [{"name": "default"}]}}`, ConfigurationHash: "2"}
+		clk.Add(10 * time.Second)
+		intervals, err := cache.GetMuteTimings(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, intervals)
+		require.Equal(t, 3, reader.calls)
+	})
+
+	t.Run("organizations are cached separately", func(t *testing.T) {
+		_, err := cache.GetMuteTimings(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, 4, reader.calls)
+	})
+}
@@ -58,6 +58,8 @@ type Manager struct {
 	rulesPerRuleGroupLimit         int64
 
 	persister StatePersister
+
+	relabelConfigs *relabelCache
 }
 
 type ManagerCfg struct {
@@ -109,6 +111,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
 		persister:                      statePersister,
 		tracer:                         cfg.Tracer,
+		relabelConfigs:                 newRelabelCache(),
 	}
 
 	if m.applyNoDataAndErrorToAllStates {
@@ -245,6 +248,7 @@ func (st *Manager) DeleteStateByRuleUID(ctx context.Context, ruleKey ngModels.Al
 	logger.Debug("Resetting state of the rule")
 
 	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	st.relabelConfigs.delete(ruleKey)
 
 	if len(states) == 0 {
 		return nil
@@ -308,6 +312,58 @@ func (st *Manager) ResetStateByRuleUID(ctx context.Context, rule *ngModels.Alert
 	return transitions
 }
 
+// HoldStatesByRuleUID keeps the current states of a rule that is not evaluated because it is outside of its active
+// time intervals. The states keep their state and their reason is set to StateReasonPausedBySchedule. Their
+// evaluation and end times are moved forward so that they are neither deleted as stale nor resolved by the Alertmanager,
+// and the rule continues from the held states once it is evaluated again.
+// This will update the states in cache/store and call send with the states that need to be sent to the alertmanager.
+func (st *Manager) HoldStatesByRuleUID(ctx context.Context, evaluatedAt time.Time, alertRule *ngModels.AlertRule, send Sender) StateTransitions {
+	ctx, span := st.tracer.Start(ctx, "alert rule state hold", trace.WithAttributes(
+		attribute.String("rule_uid", alertRule.UID),
+		attribute.Int64("org_id", alertRule.OrgID),
+		attribute.Int64("rule_version", alertRule.Version),
+		attribute.String("tick", evaluatedAt.UTC().Format(time.RFC3339Nano))))
+	defer span.End()
+
+	logger := st.log.FromContext(ctx)
+	states := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false)
+	if len(states) == 0 {
+		return nil
+	}
+	logger.Debug("Holding states of the rule outside of its active time intervals", "states", len(states))
+
+	transitions := make(StateTransitions, 0, len(states))
+	for _, s := range states {
+		oldState := s.State
+		oldReason := s.StateReason
+		s.StateReason = ngModels.StateReasonPausedBySchedule
+		s.LastEvaluationTime = evaluatedAt
+		if s.State != eval.Normal {
+			s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		}
+		transitions = append(transitions, StateTransition{
+			State:               s,
+			PreviousState:       oldState,
+			PreviousStateReason: oldReason,
+		})
+	}
+
+	var statesToSend StateTransitions
+	if send != nil {
+		statesToSend = st.updateLastSentAt(transitions, evaluatedAt)
+	}
+
+	st.persister.Sync(ctx, span, transitions)
+	if st.historian != nil {
+		st.historian.Record(ctx, history_model.NewRuleMeta(alertRule, logger), transitions)
+	}
+
+	if send != nil {
+		send(ctx, statesToSend)
+	}
+	return transitions
+}
+
 // ProcessEvalResults updates the current states that belong to a rule with the evaluation results.
 // if extraLabels is not empty, those labels will be added to every state. The extraLabels take precedence over rule labels and result labels
 // This will update the states in cache/store and return the state transitions that need to be sent to the alertmanager.
@@ -417,6 +473,7 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
 	}
+	results = relabelResults(st.relabelConfigs, alertRule, results, logger)
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
 		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
@@ -2288,3 +2288,68 @@ func mergeLabels(a, b data.Labels) data.Labels {
 	}
 	return result
 }
+
+func TestHoldStatesByRuleUID(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithFor(0), gen.WithInterval(time.Minute)).GenerateRef()
+	results := eval.Results{
+		eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()))(),
+		eval.ResultGen(eval.WithState(eval.Normal), eval.WithEvaluatedAt(clk.Now()))(),
+	}
+	st.ProcessEvalResults(ctx, clk.Now(), rule, results, nil, nil)
+	firingSince := clk.Now()
+
+	t.Run("should keep the states and set the reason", func(t *testing.T) {
+		clk.Add(time.Hour)
+		var sent state.StateTransitions
+		held := st.HoldStatesByRuleUID(ctx, clk.Now(), rule, func(_ context.Context, states state.StateTransitions) {
+			sent = states
+		})
+		require.Len(t, held, 2)
+		for _, s := range held {
+			assert.True(t, s.Changed())
+			assert.Equal(t, models.StateReasonPausedBySchedule, s.StateReason)
+			assert.Equal(t, clk.Now(), s.LastEvaluationTime)
+			if s.State.State == eval.Alerting {
+				assert.Equal(t, firingSince, s.StartsAt)
+				assert.True(t, s.EndsAt.After(clk.Now()))
+			}
+		}
+		require.Len(t, sent, 1)
+		assert.Equal(t, eval.Alerting, sent[0].State.State)
+	})
+
+	t.Run("should not change the states again", func(t *testing.T) {
+		clk.Add(time.Minute)
+		held := st.HoldStatesByRuleUID(ctx, clk.Now(), rule, nil)
+		require.Len(t, held, 2)
+		for _, s := range held {
+			assert.False(t, s.Changed())
+		}
+	})
+
+	t.Run("should continue from the held states", func(t *testing.T) {
+		clk.Add(time.Minute)
+		next := eval.Results{
+			eval.ResultGen(eval.WithState(eval.Alerting), eval.WithEvaluatedAt(clk.Now()), eval.WithLabels(results[0].Instance))(),
+		}
+		processed := st.ProcessEvalResults(ctx, clk.Now(), rule, next, nil, nil)
+		require.NotEmpty(t, processed)
+		assert.Equal(t, eval.Alerting, processed[0].State.State)
+		assert.Equal(t, firingSince, processed[0].StartsAt)
+		assert.Empty(t, processed[0].StateReason)
+	})
+}
@@ -2,6 +2,7 @@ package state
 
 import (
 	"context"
+	"errors"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
@@ -30,6 +31,32 @@ type Historian interface {
 	Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error
 }
 
+// Historians records state transitions to several historians at once.
+// Unlike historian.MultipleBackend, it does not serve queries, so it can be used for consumers of state transitions
+// that do not store state history, for example incident correlation.
+type Historians []Historian
+
+func (h Historians) Record(ctx context.Context, rule history_model.RuleMeta, states []StateTransition) <-chan error {
+	jobs := make([]<-chan error, 0, len(h))
+	for _, historian := range h {
+		jobs = append(jobs, historian.Record(ctx, rule, states))
+	}
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(errCh)
+		errs := make([]error, 0)
+		for _, ch := range jobs {
+			if err := <-ch; err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if err := errors.Join(errs...); err != nil {
+			errCh <- err
+		}
+	}()
+	return errCh
+}
+
 // ImageCapturer captures images.
 //
 //go:generate mockgen -destination=image_mock.go -package=state github.com/grafana/grafana/pkg/services/ngalert/state ImageCapturer
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/relabel"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// relabelResults applies the relabel configs of the rule to the labels of the evaluation results, before they are
+// used to calculate the alert instances. Results whose labels are dropped by the relabel configs are removed.
+// If several results end up with the same labels, they are merged into the result with the most severe state.
+//
+// Results in the NoData and Error states are not relabeled because their labels are not returned by the queries.
+func relabelResults(configs *relabelCache, rule *ngModels.AlertRule, results eval.Results, logger log.Logger) eval.Results {
+	if len(rule.RelabelConfigs) == 0 {
+		return results
+	}
+	cfgs, err := configs.get(rule)
+	if err != nil {
+		// Relabel configs are validated when the rule is saved, so this should never happen.
+		logger.Error("Failed to parse relabel configs, results are not relabeled", "error", err)
+		return results
+	}
+
+	relabeled := make(eval.Results, 0, len(results))
+	indexes := make(map[data.Fingerprint]int, len(results))
+	dropped := 0
+	for _, result := range results {
+		if result.State == eval.NoData || result.State == eval.Error {
+			relabeled = append(relabeled, result)
+			continue
+		}
+		lbls, keep := relabelLabels(result.Instance, cfgs)
+		if !keep {
+			dropped++
+			continue
+		}
+		result.Instance = lbls
+		fp := lbls.Fingerprint()
+		if idx, ok := indexes[fp]; ok {
+			if severity(result.State) > severity(relabeled[idx].State) {
+				relabeled[idx] = result
+			}
+			continue
+		}
+		indexes[fp] = len(relabeled)
+		relabeled = append(relabeled, result)
+	}
+	if len(relabeled) != len(results) {
+		logger.Debug("Relabeled evaluation results", "results", len(results), "dropped", dropped, "merged", len(results)-dropped-len(relabeled))
+	}
+	return relabeled
+}
+
+// relabelCache keeps the relabel configs of the rules, so that their regular expressions are compiled once per
+// version of a rule instead of on every evaluation.
+type relabelCache struct {
+	mtx     sync.Mutex
+	configs map[ngModels.AlertRuleKey]compiledRelabelConfigs
+}
+
+type compiledRelabelConfigs struct {
+	version int64
+	configs []*relabel.Config
+	err     error
+}
+
+func newRelabelCache() *relabelCache {
+	return &relabelCache{configs: make(map[ngModels.AlertRuleKey]compiledRelabelConfigs)}
+}
+
+// get returns the compiled relabel configs of the rule, and compiles them if the rule changed since the last call.
+func (c *relabelCache) get(rule *ngModels.AlertRule) ([]*relabel.Config, error) {
+	key := rule.GetKey()
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if compiled, ok := c.configs[key]; ok && compiled.version == rule.Version {
+		return compiled.configs, compiled.err
+	}
+	cfgs, err := rule.PrometheusRelabelConfigs()
+	c.configs[key] = compiledRelabelConfigs{version: rule.Version, configs: cfgs, err: err}
+	return cfgs, err
+}
+
+func (c *relabelCache) delete(key ngModels.AlertRuleKey) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.configs, key)
+}
+
+func relabelLabels(l data.Labels, cfgs []*relabel.Config) (data.Labels, bool) {
+	lbls, keep := relabel.Process(labels.FromMap(l), cfgs...)
+	if !keep {
+		return nil, false
+	}
+	return lbls.Map(), true
+}
+
+// severity orders the states of evaluation results, so that merged results keep the state that matters most.
+func severity(s eval.State) int {
+	switch s {
+	case eval.Alerting:
+		return 2
+	case eval.Pending:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRelabelResults(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		RelabelConfigs: []ngmodels.RelabelConfig{
+			{SourceLabels: []string{"pod"}, Regex: "(.*)-[a-z0-9]+-[a-z0-9]+", TargetLabel: "workload"},
+			{Action: "labeldrop", Regex: "pod"},
+			{Action: "drop", SourceLabels: []string{"namespace"}, Regex: "kube-system"},
+		},
+	}
+
+	results := eval.Results{
+		{State: eval.Normal, Instance: data.Labels{"pod": "api-5d8f7c9b6-abcde", "namespace": "default"}},
+		{State: eval.Alerting, Instance: data.Labels{"pod": "api-5d8f7c9b6-fghij", "namespace": "default"}},
+		{State: eval.Alerting, Instance: data.Labels{"pod": "dns-7f9c5d6b8-klmno", "namespace": "kube-system"}},
+		{State: eval.NoData, Instance: data.Labels{"datasource_uid": "ds", "ref_id": "A"}},
+	}
+
+	relabeled := relabelResults(newRelabelCache(), rule, results, log.NewNopLogger())
+	require.Len(t, relabeled, 2)
+	assert.Equal(t, eval.Alerting, relabeled[0].State)
+	assert.Equal(t, data.Labels{"workload": "api", "namespace": "default"}, relabeled[0].Instance)
+	assert.Equal(t, eval.NoData, relabeled[1].State)
+	assert.Equal(t, data.Labels{"datasource_uid": "ds", "ref_id": "A"}, relabeled[1].Instance)
+
+	t.Run("results are not modified without relabel configs", func(t *testing.T) {
+		assert.Equal(t, results, relabelResults(newRelabelCache(), &ngmodels.AlertRule{}, results, log.NewNopLogger()))
+	})
+}
+
+func TestRelabelCache(t *testing.T) {
+	c := newRelabelCache()
+	rule := &ngmodels.AlertRule{
+		OrgID:          1,
+		UID:            "rule",
+		Version:        1,
+		RelabelConfigs: []ngmodels.RelabelConfig{{Action: "labeldrop", Regex: "pod"}},
+	}
+
+	first, err := c.get(rule)
+	require.NoError(t, err)
+	second, err := c.get(rule)
+	require.NoError(t, err)
+	require.Same(t, first[0], second[0], "configs are compiled once per rule version")
+
+	rule.Version = 2
+	rule.RelabelConfigs = []ngmodels.RelabelConfig{{Action: "labeldrop", Regex: "container"}}
+	updated, err := c.get(rule)
+	require.NoError(t, err)
+	require.NotSame(t, first[0], updated[0])
+	assert.Equal(t, "container", updated[0].Regex.String())
+
+	c.delete(rule.GetKey())
+	require.Empty(t, c.configs)
+}
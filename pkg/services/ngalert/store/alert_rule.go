@@ -808,20 +808,53 @@ func (st DBstore) ListNotificationSettings(ctx context.Context, q ngmodels.ListN
 }
 
 func (st DBstore) filterByContentInNotificationSettings(value string, sess *xorm.Session) (*xorm.Session, error) {
+	return st.filterByJSONContent("notification_settings", value, sess)
+}
+
+// filterByJSONContent filters rows whose JSON column contains the given string value.
+func (st DBstore) filterByJSONContent(column string, value string, sess *xorm.Session) (*xorm.Session, error) {
 	if value == "" {
 		return sess, nil
 	}
 	// marshall string according to JSON rules so we follow escaping rules.
 	b, err := json.Marshal(value)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshall string for notification settings content filter: %w", err)
+		return nil, fmt.Errorf("failed to marshall string for %s content filter: %w", column, err)
 	}
 	var search = string(b)
 	if st.SQLStore.GetDialect().DriverName() != migrator.SQLite {
 		// this escapes escaped double quote (\") to \\\"
 		search = strings.ReplaceAll(strings.ReplaceAll(search, `\`, `\\`), `"`, `\"`)
 	}
-	return sess.And(fmt.Sprintf("notification_settings %s ?", st.SQLStore.GetDialect().LikeStr()), "%"+search+"%"), nil
+	return sess.And(fmt.Sprintf("%s %s ?", column, st.SQLStore.GetDialect().LikeStr()), "%"+search+"%"), nil
+}
+
+// ListActiveTimeIntervalRules returns the keys of the rules of the organization that use the time interval as an
+// active time interval.
+func (st DBstore) ListActiveTimeIntervalRules(ctx context.Context, orgID int64, timeInterval string) ([]ngmodels.AlertRuleKey, error) {
+	var rules []alertRule
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		query, err := st.filterByJSONContent("active_time_intervals", timeInterval, sess.Table(alertRule{}).Select("uid, active_time_intervals").Where("org_id = ?", orgID))
+		if err != nil {
+			return err
+		}
+		return query.Find(&rules)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result []ngmodels.AlertRuleKey
+	for _, rule := range rules {
+		var intervals []string
+		if err := json.Unmarshal([]byte(rule.ActiveTimeIntervals), &intervals); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal active time intervals of rule %s: %w", rule.UID, err)
+		}
+		// the LIKE filter can match a part of another name, so check the names exactly
+		if slices.Contains(intervals, timeInterval) {
+			result = append(result, ngmodels.AlertRuleKey{OrgID: orgID, UID: rule.UID})
+		}
+	}
+	return result, nil
 }
 
 func (st DBstore) RenameReceiverInNotificationSettings(ctx context.Context, orgID int64, oldReceiver, newReceiver string, validateProvenance func(ngmodels.Provenance) bool, dryRun bool) ([]ngmodels.AlertRuleKey, []ngmodels.AlertRuleKey, error) {
@@ -88,6 +88,20 @@ func alertRuleToModelsAlertRule(ar alertRule, l log.Logger) (models.AlertRule, e
 		}
 	}
 
+	if ar.RelabelConfigs != "" {
+		err = json.Unmarshal([]byte(ar.RelabelConfigs), &result.RelabelConfigs)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("failed to parse relabel configs: %w", err)
+		}
+	}
+
+	if ar.ActiveTimeIntervals != "" {
+		err = json.Unmarshal([]byte(ar.ActiveTimeIntervals), &result.ActiveTimeIntervals)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("failed to parse active time intervals: %w", err)
+		}
+	}
+
 	return result, nil
 }
 
@@ -165,6 +179,22 @@ func alertRuleFromModelsAlertRule(ar models.AlertRule) (alertRule, error) {
 	}
 	result.Metadata = string(metadata)
 
+	if len(ar.RelabelConfigs) > 0 {
+		relabelConfigsData, err := json.Marshal(ar.RelabelConfigs)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("failed to marshal relabel configs: %w", err)
+		}
+		result.RelabelConfigs = string(relabelConfigsData)
+	}
+
+	if len(ar.ActiveTimeIntervals) > 0 {
+		activeTimeIntervalsData, err := json.Marshal(ar.ActiveTimeIntervals)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("failed to marshal active time intervals: %w", err)
+		}
+		result.ActiveTimeIntervals = string(activeTimeIntervalsData)
+	}
+
 	return result, nil
 }
 
@@ -192,5 +222,7 @@ func alertRuleToAlertRuleVersion(rule alertRule) alertRuleVersion {
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: rule.NotificationSettings,
 		Metadata:             rule.Metadata,
+		RelabelConfigs:       rule.RelabelConfigs,
+		ActiveTimeIntervals:  rule.ActiveTimeIntervals,
 	}
 }
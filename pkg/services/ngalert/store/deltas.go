@@ -52,6 +52,26 @@ func (c *GroupDelta) NewOrUpdatedNotificationSettings() []models.NotificationSet
 	return settings
 }
 
+// NewOrUpdatedActiveTimeIntervals returns the active time intervals of new rules and of updated rules whose active
+// time intervals changed.
+func (c *GroupDelta) NewOrUpdatedActiveTimeIntervals() []string {
+	var intervals []string
+	for _, rule := range c.New {
+		intervals = append(intervals, rule.ActiveTimeIntervals...)
+	}
+	for _, delta := range c.Update {
+		if len(delta.New.ActiveTimeIntervals) == 0 {
+			continue
+		}
+		d := delta.Diff.GetDiffsForField("ActiveTimeIntervals")
+		if len(d) == 0 {
+			continue
+		}
+		intervals = append(intervals, delta.New.ActiveTimeIntervals...)
+	}
+	return intervals
+}
+
 type RuleReader interface {
 	ListAlertRules(ctx context.Context, query *models.ListAlertRulesQuery) (models.RulesGroup, error)
 	GetAlertRulesGroupByRuleUID(ctx context.Context, query *models.GetAlertRulesGroupByRuleUIDQuery) ([]*models.AlertRule, error)
@@ -27,6 +27,8 @@ type alertRule struct {
 	IsPaused             bool
 	NotificationSettings string `xorm:"notification_settings"`
 	Metadata             string `xorm:"metadata"`
+	RelabelConfigs       string `xorm:"relabel_configs"`
+	ActiveTimeIntervals  string `xorm:"active_time_intervals"`
 }
 
 func (a alertRule) TableName() string {
@@ -61,6 +63,8 @@ type alertRuleVersion struct {
 	IsPaused             bool
 	NotificationSettings string `xorm:"notification_settings"`
 	Metadata             string `xorm:"metadata"`
+	RelabelConfigs       string `xorm:"relabel_configs"`
+	ActiveTimeIntervals  string `xorm:"active_time_intervals"`
 }
 
 func (a alertRuleVersion) TableName() string {
@@ -19,6 +19,15 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	// CommitSHA is the commit of the git repository the files are from, if they are from one.
+	// It is recorded in Commits for the provisioned folders and alert rules.
+	CommitSHA string
+	Commits   CommitRecorder
+}
+
+// CommitRecorder records the commit of the git repository a resource was provisioned from.
+type CommitRecorder interface {
+	Record(ctx context.Context, orgID int64, kind, uid, commitSHA string) error
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -66,7 +75,9 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 		logger,
 		cfg.FolderService,
 		cfg.DashboardProvService,
-		cfg.RuleService)
+		cfg.RuleService,
+		cfg.Commits,
+		cfg.CommitSHA)
 	err = ruleProvisioner.Provision(ctx, files)
 	if err != nil {
 		return fmt.Errorf("alert rules: %w", err)
@@ -14,6 +14,7 @@ import (
 	alert_models "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -25,12 +26,16 @@ func NewAlertRuleProvisioner(
 	logger log.Logger,
 	folderService folder.Service,
 	dashboardProvService dashboards.DashboardProvisioningService,
-	ruleService provisioning.AlertRuleService) AlertRuleProvisioner {
+	ruleService provisioning.AlertRuleService,
+	commits CommitRecorder,
+	commitSHA string) AlertRuleProvisioner {
 	return &defaultAlertRuleProvisioner{
 		logger:               logger,
 		folderService:        folderService,
 		dashboardProvService: dashboardProvService,
 		ruleService:          ruleService,
+		commits:              commits,
+		commitSHA:            commitSHA,
 	}
 }
 
@@ -39,6 +44,8 @@ type defaultAlertRuleProvisioner struct {
 	folderService        folder.Service
 	dashboardProvService dashboards.DashboardProvisioningService
 	ruleService          provisioning.AlertRuleService
+	commits              CommitRecorder
+	commitSHA            string
 }
 
 func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
@@ -51,6 +58,9 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 				prov.logger.Error("failed to get or create folder", "folder", group.FolderFullpath, "org", group.OrgID, "err", err)
 				return err
 			}
+			if err := prov.recordCommit(ctx, group.OrgID, gitsource.KindFolder, folderUID); err != nil {
+				return err
+			}
 			prov.logger.Debug("provisioning alert rule group",
 				"org", group.OrgID,
 				"folder", group.FolderFullpath,
@@ -63,6 +73,9 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 				if err != nil {
 					return err
 				}
+				if err := prov.recordCommit(ctx, rule.OrgID, gitsource.KindAlertRule, rule.UID); err != nil {
+					return err
+				}
 			}
 			err = prov.ruleService.UpdateRuleGroup(ctx, u, folderUID, group.Title, group.Interval)
 			if err != nil {
@@ -79,6 +92,17 @@ func (prov *defaultAlertRuleProvisioner) Provision(ctx context.Context,
 	return nil
 }
 
+// recordCommit records the commit of the git repository the resource was provisioned from, if the files are from one.
+func (prov *defaultAlertRuleProvisioner) recordCommit(ctx context.Context, orgID int64, kind, uid string) error {
+	if prov.commits == nil || prov.commitSHA == "" {
+		return nil
+	}
+	if err := prov.commits.Record(ctx, orgID, kind, uid, prov.commitSHA); err != nil {
+		return fmt.Errorf("failed to record the commit of %s %s: %w", kind, uid, err)
+	}
+	return nil
+}
+
 func (prov *defaultAlertRuleProvisioner) provisionRule(
 	ctx context.Context,
 	user identity.Requester,
@@ -77,6 +77,8 @@ type AlertRuleV1 struct {
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 	Record               *RecordV1               `json:"record" yaml:"record"`
+	RelabelConfigs       []RelabelConfigV1       `json:"relabel_configs,omitempty" yaml:"relabel_configs"`
+	ActiveTimeIntervals  []values.StringValue    `json:"active_time_intervals,omitempty" yaml:"active_time_intervals"`
 }
 
 func withFallback(value, fallback string) *string {
@@ -156,6 +158,18 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		}
 		alertRule.Record = &record
 	}
+	for _, relabelConfig := range rule.RelabelConfigs {
+		alertRule.RelabelConfigs = append(alertRule.RelabelConfigs, relabelConfig.mapToModel())
+	}
+	if _, err := alertRule.PrometheusRelabelConfigs(); err != nil {
+		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+	}
+	for _, interval := range rule.ActiveTimeIntervals {
+		if interval.Value() == "" {
+			continue
+		}
+		alertRule.ActiveTimeIntervals = append(alertRule.ActiveTimeIntervals, interval.Value())
+	}
 	return alertRule, nil
 }
 
@@ -275,3 +289,32 @@ func (record *RecordV1) mapToModel() (models.Record, error) {
 		From:   record.From.Value(),
 	}, nil
 }
+
+type RelabelConfigV1 struct {
+	SourceLabels []values.StringValue `json:"source_labels" yaml:"source_labels"`
+	Separator    values.StringValue   `json:"separator" yaml:"separator"`
+	Regex        values.StringValue   `json:"regex" yaml:"regex"`
+	Modulus      values.Int64Value    `json:"modulus" yaml:"modulus"`
+	TargetLabel  values.StringValue   `json:"target_label" yaml:"target_label"`
+	// Replacement is a pointer because an empty replacement is valid, and removes the target label.
+	Replacement *values.StringValue `json:"replacement" yaml:"replacement"`
+	Action      values.StringValue  `json:"action" yaml:"action"`
+}
+
+func (cfg *RelabelConfigV1) mapToModel() models.RelabelConfig {
+	result := models.RelabelConfig{
+		Separator:   cfg.Separator.Value(),
+		Regex:       cfg.Regex.Value(),
+		Modulus:     uint64(cfg.Modulus.Value()),
+		TargetLabel: cfg.TargetLabel.Value(),
+		Action:      cfg.Action.Value(),
+	}
+	for _, l := range cfg.SourceLabels {
+		result.SourceLabels = append(result.SourceLabels, l.Value())
+	}
+	if cfg.Replacement != nil {
+		replacement := cfg.Replacement.Value()
+		result.Replacement = &replacement
+	}
+	return result
+}
@@ -199,6 +199,36 @@ func TestRules(t *testing.T) {
 		require.Len(t, ruleMapped.NotificationSettings, 1)
 		require.Equal(t, models.NotificationSettings{Receiver: "test-receiver"}, ruleMapped.NotificationSettings[0])
 	})
+	t.Run("a rule with relabel configs should map them correctly", func(t *testing.T) {
+		rule := validRuleV1(t)
+		var cfgs []RelabelConfigV1
+		err := yaml.Unmarshal([]byte(`
+- source_labels: [pod]
+  regex: (.*)-[a-z0-9]+-[a-z0-9]+
+  target_label: workload
+- action: labeldrop
+  regex: pod
+- source_labels: [team]
+  target_label: owner
+  replacement: ""
+`), &cfgs)
+		require.NoError(t, err)
+		rule.RelabelConfigs = cfgs
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		empty := ""
+		require.Equal(t, []models.RelabelConfig{
+			{SourceLabels: []string{"pod"}, Regex: "(.*)-[a-z0-9]+-[a-z0-9]+", TargetLabel: "workload"},
+			{Action: "labeldrop", Regex: "pod"},
+			{SourceLabels: []string{"team"}, TargetLabel: "owner", Replacement: &empty},
+		}, ruleMapped.RelabelConfigs)
+	})
+	t.Run("a rule with an invalid relabel config should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		rule.RelabelConfigs = []RelabelConfigV1{{Action: stringToStringValue("hashmod"), SourceLabels: []values.StringValue{stringToStringValue("pod")}, TargetLabel: stringToStringValue("shard")}}
+		_, err := rule.mapToModel(1)
+		require.ErrorContains(t, err, "requires a modulus")
+	})
 }
 
 func TestNotificationsSettingsV1MapToModel(t *testing.T) {
@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 )
 
@@ -21,6 +23,10 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	SetAlertingProvisioner(fn AlertingProvisionFunc)
+	SetCommitStore(commits *gitsource.CommitStore)
+	PushDashboardChanges(ctx context.Context, name string, externalID string, data *simplejson.Json, message string, author gitsource.Signature) error
+	GetDashboardPushStatus(name string, externalID string) *gitsource.PushStatus
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
@@ -153,6 +159,18 @@ func getFileReaders(
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -1,6 +1,11 @@
 package dashboards
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
+)
 
 // Calls is a mock implementation of the provisioner interface
 type calls struct {
@@ -67,3 +72,19 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// SetAlertingProvisioner not implemented for mocks
+func (dpm *ProvisionerMock) SetAlertingProvisioner(fn AlertingProvisionFunc) {}
+
+// SetCommitStore not implemented for mocks
+func (dpm *ProvisionerMock) SetCommitStore(commits *gitsource.CommitStore) {}
+
+// PushDashboardChanges not implemented for mocks
+func (dpm *ProvisionerMock) PushDashboardChanges(ctx context.Context, name string, externalID string, data *simplejson.Json, message string, author gitsource.Signature) error {
+	return nil
+}
+
+// GetDashboardPushStatus not implemented for mocks
+func (dpm *ProvisionerMock) GetDashboardPushStatus(name string, externalID string) *gitsource.PushStatus {
+	return nil
+}
@@ -43,6 +43,9 @@ type FileReader struct {
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
 	dbWriteAccessRestricted bool
+
+	// git is set for readers of git provisioning sources.
+	git *gitSource
 }
 
 // NewDashboardFileReader returns a new filereader based on `config`
@@ -78,6 +81,10 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 
 // pollChanges periodically runs walkDisk based on interval specified in the config.
 func (fr *FileReader) pollChanges(ctx context.Context) {
+	if fr.git != nil {
+		go fr.git.runPushes(ctx, fr.log)
+	}
+
 	ticker := time.NewTicker(time.Duration(int64(time.Second) * fr.Cfg.UpdateIntervalSeconds))
 	for {
 		select {
@@ -94,6 +101,15 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	if fr.git != nil {
+		// Dashboards of the existing checkout are still provisioned when the repository is unavailable.
+		if sha, err := fr.git.sync(ctx); err != nil {
+			fr.log.Error("Failed to sync git repository", "error", err)
+		} else {
+			fr.log.Debug("Synced git repository", "commit", sha)
+		}
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -124,12 +140,28 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 	}
 
 	fr.mux.Lock()
-	defer fr.mux.Unlock()
-
 	fr.usageTracker = usageTracker
+	fr.mux.Unlock()
+
+	if fr.git != nil {
+		// Alert rules are provisioned after dashboards, since they may be in folders created by them.
+		if err := fr.git.provisionAlerting(ctx); err != nil {
+			return fmt.Errorf("failed to provision alerting: %w", err)
+		}
+	}
 	return nil
 }
 
+// recordFolderCommit records the commit a folder of a git provisioning source was provisioned from.
+func (fr *FileReader) recordFolderCommit(ctx context.Context, folderUID string) {
+	if fr.git == nil {
+		return
+	}
+	if err := fr.git.recordFolder(ctx, fr.Cfg.OrgID, folderUID); err != nil {
+		fr.log.Error("Failed to record the commit of folder", "folderUid", folderUID, "error", err)
+	}
+}
+
 func (fr *FileReader) changeWritePermissions(restrict bool) {
 	fr.mux.Lock()
 	defer fr.mux.Unlock()
@@ -151,6 +183,7 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 	if err != nil && !errors.Is(err, ErrFolderNameMissing) {
 		return fmt.Errorf("%w with name %q: %w", ErrGetOrCreateFolder, fr.Cfg.Folder, err)
 	}
+	fr.recordFolderCommit(ctx, folderUID)
 
 	// save dashboards based on json files
 	for path, fileInfo := range filesFoundOnDisk {
@@ -181,6 +214,7 @@ func (fr *FileReader) storeDashboardsInFoldersFromFileStructure(ctx context.Cont
 		if err != nil && !errors.Is(err, ErrFolderNameMissing) {
 			return fmt.Errorf("%w with name %q from file system structure: %w", ErrGetOrCreateFolder, folderName, err)
 		}
+		fr.recordFolderCommit(ctx, folderUID)
 
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, folderUID, fileInfo, dashboardRefs)
 		usageTracker.track(provisioningMetadata)
@@ -305,6 +339,9 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 			Updated:    resolvedFileInfo.ModTime().Unix(),
 			CheckSum:   jsonFile.checkSum,
 		}
+		if fr.git != nil {
+			dp.CommitSHA = fr.git.currentCommitSHA()
+		}
 		_, err := fr.dashboardProvisioningService.SaveProvisionedDashboard(ctx, dash, dp)
 		if err != nil {
 			return provisioningMetadata, err
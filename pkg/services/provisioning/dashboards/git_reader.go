@@ -0,0 +1,298 @@
+package dashboards
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// AlertingProvisionFunc provisions the alerting resources defined in the files of a directory,
+// commitSHA is the commit of the git repository the files are from.
+type AlertingProvisionFunc func(ctx context.Context, path string, commitSHA string) error
+
+// pushQueueSize is the number of dashboard changes waiting to be pushed per git source.
+const pushQueueSize = 100
+
+var gitCheckoutNameRe = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// gitSource keeps the checkout of a git provisioning source up to date. Dashboards are read
+// from the checkout by the file reader, and alerting resources from alertingPath if set.
+// Dashboards edited in the UI are pushed in the background, see runPushes.
+type gitSource struct {
+	repo         *gitsource.Repository
+	alertingPath string
+	pushBranch   string
+	pushes       chan gitPush
+	now          func() time.Time
+
+	mu                  sync.Mutex
+	commitSHA           string
+	alertingCommitSHA   string
+	alertingProvisioner AlertingProvisionFunc
+	commits             *gitsource.CommitStore
+	recordedFolders     map[string]string
+	pushStatus          map[string]gitsource.PushStatus
+}
+
+// gitPush is a dashboard file to commit to the push branch.
+type gitPush struct {
+	externalID string
+	path       string
+	content    []byte
+	message    string
+	author     gitsource.Signature
+}
+
+// NewDashboardGitReader returns a file reader for dashboards of a git repository. The options are:
+//   - url: the URL of the repository, which can include credentials
+//   - branch: the branch to provision from, main by default
+//   - path: the directory of dashboards in the repository, its root by default
+//   - checkoutPath: where the repository is cloned, a temporary directory by default
+//   - alertingPath: an optional directory of the repository with alerting provisioning files
+//   - pushBranch: an optional branch dashboards edited in the UI are committed to, it requires allowUiUpdates
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	url, _ := cfg.Options["url"].(string)
+	if url == "" {
+		return nil, errors.New("failed to load dashboards, url param is not set")
+	}
+	branch, _ := cfg.Options["branch"].(string)
+	subPath, _ := cfg.Options["path"].(string)
+	alertingPath, _ := cfg.Options["alertingPath"].(string)
+	pushBranch, _ := cfg.Options["pushBranch"].(string)
+	if pushBranch != "" && !cfg.AllowUIUpdates {
+		return nil, errors.New("'pushBranch' requires 'allowUiUpdates' to be enabled")
+	}
+	for _, p := range []string{subPath, alertingPath} {
+		if !filepath.IsLocal(filepath.FromSlash(p)) && p != "" {
+			return nil, fmt.Errorf("path %q is outside of the repository", p)
+		}
+	}
+	checkoutPath, _ := cfg.Options["checkoutPath"].(string)
+	if checkoutPath == "" {
+		name := gitCheckoutNameRe.ReplaceAllString(cfg.Name, "_")
+		checkoutPath = filepath.Join(os.TempDir(), "grafana-provisioning-git", strconv.FormatInt(cfg.OrgID, 10)+"_"+name)
+	}
+
+	// The file reader walks the dashboards directory of the checkout.
+	fileCfg := *cfg
+	fileCfg.Options = make(map[string]any, len(cfg.Options))
+	for k, v := range cfg.Options {
+		fileCfg.Options[k] = v
+	}
+	fileCfg.Options["path"] = filepath.Join(checkoutPath, filepath.FromSlash(subPath))
+
+	reader, err := NewDashboardFileReader(&fileCfg, log, service, dashboardStore, folderService)
+	if err != nil {
+		return nil, err
+	}
+	reader.git = &gitSource{
+		repo:            gitsource.New(url, branch, checkoutPath),
+		alertingPath:    alertingPath,
+		pushBranch:      pushBranch,
+		pushes:          make(chan gitPush, pushQueueSize),
+		now:             time.Now,
+		recordedFolders: map[string]string{},
+		pushStatus:      map[string]gitsource.PushStatus{},
+	}
+	return reader, nil
+}
+
+// sync checks out the latest commit of the branch and returns its SHA.
+func (g *gitSource) sync(ctx context.Context) (string, error) {
+	sha, err := g.repo.Sync(ctx)
+	if err != nil {
+		return "", err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.commitSHA = sha
+	return sha, nil
+}
+
+func (g *gitSource) currentCommitSHA() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.commitSHA
+}
+
+func (g *gitSource) setAlertingProvisioner(fn AlertingProvisionFunc) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.alertingProvisioner = fn
+}
+
+func (g *gitSource) setCommitStore(commits *gitsource.CommitStore) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.commits = commits
+}
+
+// recordFolder records the current commit as the commit the folder was provisioned from.
+// It is recorded once per commit, since folders are looked up for each dashboard file.
+func (g *gitSource) recordFolder(ctx context.Context, orgID int64, uid string) error {
+	g.mu.Lock()
+	commits, sha := g.commits, g.commitSHA
+	recorded := g.recordedFolders[uid] == sha
+	g.mu.Unlock()
+	if commits == nil || sha == "" || uid == "" || recorded {
+		return nil
+	}
+	if err := commits.Record(ctx, orgID, gitsource.KindFolder, uid, sha); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.recordedFolders[uid] = sha
+	g.mu.Unlock()
+	return nil
+}
+
+// provisionAlerting applies the alerting files of the checkout when they were not applied for the current commit yet.
+func (g *gitSource) provisionAlerting(ctx context.Context) error {
+	g.mu.Lock()
+	fn, sha := g.alertingProvisioner, g.commitSHA
+	upToDate := g.alertingCommitSHA == sha
+	g.mu.Unlock()
+	if g.alertingPath == "" || fn == nil || sha == "" || upToDate {
+		return nil
+	}
+	if err := fn(ctx, filepath.Join(g.repo.Dir, filepath.FromSlash(g.alertingPath)), sha); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.alertingCommitSHA = sha
+	g.mu.Unlock()
+	return nil
+}
+
+// queueDashboard queues the dashboard to be committed to the file it was provisioned from on the push branch.
+func (g *gitSource) queueDashboard(externalID string, data *simplejson.Json, message string, author gitsource.Signature) error {
+	root, err := filepath.Abs(g.repo.Dir)
+	if err != nil {
+		return err
+	}
+	// External ids are resolved paths, see FileReader.resolvedPath.
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	rel, err := filepath.Rel(root, externalID)
+	if err != nil || !filepath.IsLocal(rel) {
+		return fmt.Errorf("dashboard file %s is not in the repository", externalID)
+	}
+
+	dash := data.MustMap()
+	delete(dash, "id")
+	content, err := json.MarshalIndent(dash, "", "  ")
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		message = "Update " + filepath.ToSlash(rel)
+	}
+	push := gitPush{externalID: externalID, path: rel, content: append(content, '\n'), message: message, author: author}
+	// The status is set first, the push can complete before this function returns.
+	g.setPushStatus(externalID, gitsource.PushStatus{State: gitsource.PushStatePending})
+	select {
+	case g.pushes <- push:
+		return nil
+	default:
+		err := errors.New("too many dashboard changes waiting to be pushed")
+		g.setPushStatus(externalID, gitsource.PushStatus{State: gitsource.PushStateFailed, Error: err.Error()})
+		return err
+	}
+}
+
+// runPushes pushes the queued dashboard changes until the context is done. Pushes run in the
+// background so that saving a dashboard doesn't wait for the remote repository, their result
+// is available with getPushStatus.
+func (g *gitSource) runPushes(ctx context.Context, logger log.Logger) {
+	for {
+		select {
+		case push := <-g.pushes:
+			sha, err := g.repo.Push(ctx, g.pushBranch, map[string][]byte{push.path: push.content}, push.message, push.author)
+			if err != nil {
+				logger.Error("Failed to push dashboard changes", "file", push.path, "branch", g.pushBranch, "error", err)
+				g.setPushStatus(push.externalID, gitsource.PushStatus{State: gitsource.PushStateFailed, Error: err.Error()})
+				continue
+			}
+			g.setPushStatus(push.externalID, gitsource.PushStatus{State: gitsource.PushStatePushed, CommitSHA: sha})
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (g *gitSource) setPushStatus(externalID string, status gitsource.PushStatus) {
+	status.Branch = g.pushBranch
+	status.Updated = g.now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pushStatus[externalID] = status
+}
+
+func (g *gitSource) getPushStatus(externalID string) *gitsource.PushStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	status, ok := g.pushStatus[externalID]
+	if !ok {
+		return nil
+	}
+	return &status
+}
+
+// PushDashboardChanges queues a dashboard saved in the UI to be committed to the git repository it was
+// provisioned from, if the provisioner is a git provisioner with a push branch. Other provisioners are ignored.
+func (provider *Provisioner) PushDashboardChanges(ctx context.Context, name string, externalID string, data *simplejson.Json, message string, author gitsource.Signature) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name != name {
+			continue
+		}
+		if reader.git == nil || reader.git.pushBranch == "" {
+			return nil
+		}
+		return reader.git.queueDashboard(externalID, data, message, author)
+	}
+	return nil
+}
+
+// GetDashboardPushStatus returns the state of the last push of the changes of a dashboard, if any.
+func (provider *Provisioner) GetDashboardPushStatus(name string, externalID string) *gitsource.PushStatus {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == name && reader.git != nil {
+			return reader.git.getPushStatus(externalID)
+		}
+	}
+	return nil
+}
+
+// SetAlertingProvisioner sets the function provisioning alerting files of git provisioners.
+func (provider *Provisioner) SetAlertingProvisioner(fn AlertingProvisionFunc) {
+	for _, reader := range provider.fileReaders {
+		if reader.git != nil {
+			reader.git.setAlertingProvisioner(fn)
+		}
+	}
+}
+
+// SetCommitStore sets the store git provisioners record the commits of provisioned folders in.
+func (provider *Provisioner) SetCommitStore(commits *gitsource.CommitStore) {
+	for _, reader := range provider.fileReaders {
+		if reader.git != nil {
+			reader.git.setCommitStore(commits)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
+)
+
+func TestDashboardGitReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	git := func(dir string, args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+		return strings.TrimSpace(string(out))
+	}
+
+	// Create a local bare repository with a dashboard and an alerting directory.
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	work := t.TempDir()
+	git("", "init", "--quiet", "--bare", remote)
+	git(work, "init", "--quiet", "--initial-branch", "main")
+	dashboard, err := os.ReadFile(filepath.Join(oneDashboard, "dashboard1.json"))
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "dashboards"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "dashboard1.json"), dashboard, 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "alerting"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "alerting", "rules.yaml"), []byte("apiVersion: 1\n"), 0600))
+	git(work, "add", "-A")
+	git(work, "commit", "--quiet", "-m", "Add dashboards")
+	git(work, "push", "--quiet", remote, "HEAD:refs/heads/main")
+	commitSHA := git(work, "rev-parse", "HEAD")
+
+	cfg := &config{
+		Name:           configName,
+		Type:           "git",
+		OrgID:          1,
+		AllowUIUpdates: true,
+		Options: map[string]any{
+			"url":          remote,
+			"path":         "dashboards",
+			"alertingPath": "alerting",
+			"pushBranch":   "grafana-edits",
+			"checkoutPath": filepath.Join(t.TempDir(), "checkout"),
+		},
+	}
+
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+	var saved *dashboards.DashboardProvisioning
+	fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil).Once()
+	fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).
+		Return(&dashboards.Dashboard{}, nil).Once().
+		Run(func(args mock.Arguments) {
+			saved = args.Get(2).(*dashboards.DashboardProvisioning)
+		})
+
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), fakeService, &fakeDashboardStore{}, nil)
+	require.NoError(t, err)
+	provisioner := &Provisioner{fileReaders: []*FileReader{reader}, configs: []*config{cfg}}
+
+	var alertingPaths []string
+	var alertingCommits []string
+	provisioner.SetAlertingProvisioner(func(_ context.Context, path string, sha string) error {
+		alertingPaths = append(alertingPaths, path)
+		alertingCommits = append(alertingCommits, sha)
+		return nil
+	})
+
+	require.NoError(t, reader.walkDisk(context.Background()))
+	require.NotNil(t, saved)
+	require.Equal(t, commitSHA, saved.CommitSHA)
+	require.Equal(t, []string{filepath.Join(cfg.Options["checkoutPath"].(string), "alerting")}, alertingPaths)
+	require.Equal(t, []string{commitSHA}, alertingCommits)
+
+	// Alerting files are provisioned only once per commit.
+	require.NoError(t, reader.git.provisionAlerting(context.Background()))
+	require.Len(t, alertingPaths, 1)
+
+	t.Run("UI edits are pushed to the push branch in the background", func(t *testing.T) {
+		data := simplejson.New()
+		data.Set("id", 2)
+		data.Set("title", "Edited in the UI")
+		err := provisioner.PushDashboardChanges(context.Background(), configName, saved.ExternalID, data, "Edit dashboard", gitsource.Signature{Name: "admin"})
+		require.NoError(t, err)
+		status := provisioner.GetDashboardPushStatus(configName, saved.ExternalID)
+		require.NotNil(t, status)
+		require.Equal(t, gitsource.PushStatePending, status.State)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go reader.git.runPushes(ctx, log.New("test-logger"))
+		require.Eventually(t, func() bool {
+			return provisioner.GetDashboardPushStatus(configName, saved.ExternalID).State != gitsource.PushStatePending
+		}, 10*time.Second, 10*time.Millisecond)
+		status = provisioner.GetDashboardPushStatus(configName, saved.ExternalID)
+		require.Equal(t, gitsource.PushStatePushed, status.State, status.Error)
+		require.Equal(t, "grafana-edits", status.Branch)
+		require.Equal(t, git("", "--git-dir", remote, "rev-parse", "grafana-edits"), status.CommitSHA)
+
+		content := git("", "--git-dir", remote, "show", "grafana-edits:dashboards/dashboard1.json")
+		require.Contains(t, content, `"title": "Edited in the UI"`)
+		require.NotContains(t, content, `"id"`)
+		require.Equal(t, "admin", git("", "--git-dir", remote, "log", "-1", "--format=%an", "grafana-edits"))
+	})
+
+	t.Run("Failed pushes are reported in the push status", func(t *testing.T) {
+		// Pushes to an invalid branch name are rejected by git.
+		failing := &gitSource{
+			repo:       reader.git.repo,
+			pushBranch: "invalid..branch",
+			pushes:     make(chan gitPush, 1),
+			now:        reader.git.now,
+			pushStatus: map[string]gitsource.PushStatus{},
+		}
+		require.NoError(t, failing.queueDashboard(saved.ExternalID, simplejson.New(), "", gitsource.Signature{}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go failing.runPushes(ctx, log.New("test-logger"))
+		require.Eventually(t, func() bool {
+			return failing.getPushStatus(saved.ExternalID).State == gitsource.PushStateFailed
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NotEmpty(t, failing.getPushStatus(saved.ExternalID).Error)
+	})
+
+	t.Run("Push branch requires UI updates", func(t *testing.T) {
+		noUpdates := *cfg
+		noUpdates.AllowUIUpdates = false
+		_, err := NewDashboardGitReader(&noUpdates, log.New("test-logger"), fakeService, &fakeDashboardStore{}, nil)
+		require.Error(t, err)
+	})
+}
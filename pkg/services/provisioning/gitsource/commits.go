@@ -0,0 +1,66 @@
+package gitsource
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// Kinds of resources whose commit is recorded by the CommitStore. Dashboards keep
+// their commit in the dashboard provisioning table instead.
+const (
+	KindFolder    = "folder"
+	KindAlertRule = "alert_rule"
+)
+
+// ProvisionedCommit is the commit of the git repository a resource was provisioned from.
+type ProvisionedCommit struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	Kind      string `xorm:"kind"`
+	UID       string `xorm:"uid"`
+	CommitSHA string `xorm:"commit_sha"`
+	Updated   int64  `xorm:"updated"`
+}
+
+// CommitStore records the commits folders and alert rules were provisioned from.
+type CommitStore struct {
+	db  db.DB
+	now func() time.Time
+}
+
+func NewCommitStore(db db.DB) *CommitStore {
+	return &CommitStore{db: db, now: time.Now}
+}
+
+// Record saves the commit a resource was provisioned from, replacing the previous one.
+func (s *CommitStore) Record(ctx context.Context, orgID int64, kind, uid, commitSHA string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := ProvisionedCommit{}
+		exists, err := sess.Where("org_id = ? AND kind = ? AND uid = ?", orgID, kind, uid).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if exists {
+			existing.CommitSHA = commitSHA
+			existing.Updated = s.now().Unix()
+			_, err = sess.ID(existing.ID).Cols("commit_sha", "updated").Update(&existing)
+			return err
+		}
+		_, err = sess.Insert(&ProvisionedCommit{OrgID: orgID, Kind: kind, UID: uid, CommitSHA: commitSHA, Updated: s.now().Unix()})
+		return err
+	})
+}
+
+// Get returns the commit a resource was provisioned from, if it was provisioned from a git repository.
+func (s *CommitStore) Get(ctx context.Context, orgID int64, kind, uid string) (string, bool, error) {
+	commit := ProvisionedCommit{}
+	var exists bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("org_id = ? AND kind = ? AND uid = ?", orgID, kind, uid).Get(&commit)
+		return err
+	})
+	return commit.CommitSHA, exists, err
+}
@@ -0,0 +1,48 @@
+package gitsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationCommitStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	s := NewCommitStore(db.InitTestDB(t))
+	s.now = func() time.Time { return time.Unix(1000, 0) }
+	ctx := context.Background()
+
+	_, ok, err := s.Get(ctx, 1, KindAlertRule, "rule")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, s.Record(ctx, 1, KindAlertRule, "rule", "first"))
+	require.NoError(t, s.Record(ctx, 1, KindFolder, "rule", "folder"))
+	require.NoError(t, s.Record(ctx, 1, KindAlertRule, "rule", "second"))
+
+	sha, ok, err := s.Get(ctx, 1, KindAlertRule, "rule")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "second", sha)
+
+	sha, ok, err = s.Get(ctx, 1, KindFolder, "rule")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, "folder", sha)
+
+	_, ok, err = s.Get(ctx, 2, KindAlertRule, "rule")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
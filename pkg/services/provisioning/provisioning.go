@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
@@ -29,6 +31,7 @@ import (
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/searchV2"
@@ -93,6 +96,8 @@ func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
+	dashProvisioner.SetAlertingProvisioner(ps.provisionAlertingFromPath)
+	dashProvisioner.SetCommitStore(gitsource.NewCommitStore(ps.SQLStore))
 	ps.dashboardProvisioner = dashProvisioner
 	return nil
 }
@@ -106,6 +111,8 @@ type ProvisioningService interface {
 	ProvisionAlerting(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	PushDashboardChanges(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, data *simplejson.Json, message string, user identity.Requester) error
+	GetDashboardPushStatus(provisioning *dashboardservice.DashboardProvisioning) *gitsource.PushStatus
 }
 
 // Used for testing purposes
@@ -259,7 +266,13 @@ func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) erro
 }
 
 func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error {
-	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	return ps.provisionAlertingFromPath(ctx, filepath.Join(ps.Cfg.ProvisioningPath, "alerting"), "")
+}
+
+// provisionAlertingFromPath provisions the alerting resources defined in the files of a directory,
+// which can also be a directory of a git provisioning source. In that case commitSHA is the commit
+// the files are from, it is recorded for the provisioned folders and alert rules.
+func (ps *ProvisioningServiceImpl) provisionAlertingFromPath(ctx context.Context, alertingPath string, commitSHA string) error {
 	st := store.DBstore{
 		Cfg:              ps.Cfg.UnifiedAlerting,
 		SQLStore:         ps.SQLStore,
@@ -279,7 +292,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		ps.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit,
 		ps.log,
 		notifier.NewCachedNotificationSettingsValidationService(&st),
-		alertingauthz.NewRuleService(ps.ac),
+		alertingauthz.NewRuleService(ps.ac, nil),
 	)
 	configStore := legacy_storage.NewAlertmanagerConfigStore(&st)
 	receiverSvc := notifier.NewReceiverService(
@@ -307,6 +320,10 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		CommitSHA:                  commitSHA,
+	}
+	if commitSHA != "" {
+		cfg.Commits = gitsource.NewCommitStore(ps.SQLStore)
 	}
 	return ps.provisionAlerting(ctx, cfg)
 }
@@ -319,6 +336,22 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+// PushDashboardChanges commits a provisioned dashboard saved in the UI back to its provisioning source,
+// if the source supports it. Only git sources with a push branch do at the moment.
+func (ps *ProvisioningServiceImpl) PushDashboardChanges(ctx context.Context, provisioning *dashboardservice.DashboardProvisioning, data *simplejson.Json, message string, user identity.Requester) error {
+	author := gitsource.Signature{}
+	if user != nil {
+		author.Name = user.GetLogin()
+		author.Email = user.GetEmail()
+	}
+	return ps.dashboardProvisioner.PushDashboardChanges(ctx, provisioning.Name, provisioning.ExternalID, data, message, author)
+}
+
+// GetDashboardPushStatus returns the state of the last push of the changes of a provisioned dashboard, if any.
+func (ps *ProvisioningServiceImpl) GetDashboardPushStatus(provisioning *dashboardservice.DashboardProvisioning) *gitsource.PushStatus {
+	return ps.dashboardProvisioner.GetDashboardPushStatus(provisioning.Name, provisioning.ExternalID)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
@@ -1,6 +1,13 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/provisioning/gitsource"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -10,6 +17,7 @@ type Calls struct {
 	ProvisionAlerting                   []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	PushDashboardChanges                []any
 	Run                                 []any
 }
 
@@ -21,6 +29,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	PushDashboardChangesFunc                func(provisioning *dashboards.DashboardProvisioning, data *simplejson.Json) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -83,6 +92,18 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) PushDashboardChanges(ctx context.Context, provisioning *dashboards.DashboardProvisioning, data *simplejson.Json, message string, user identity.Requester) error {
+	mock.Calls.PushDashboardChanges = append(mock.Calls.PushDashboardChanges, provisioning)
+	if mock.PushDashboardChangesFunc != nil {
+		return mock.PushDashboardChangesFunc(provisioning, data)
+	}
+	return nil
+}
+
+func (mock *ProvisioningServiceMock) GetDashboardPushStatus(provisioning *dashboards.DashboardProvisioning) *gitsource.PushStatus {
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
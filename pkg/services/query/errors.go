@@ -10,4 +10,5 @@ var (
 	ErrMissingDataSourceInfo = errutil.BadRequest("query.missingDataSourceInfo").MustTemplate("query missing datasource info: {{ .Public.RefId }}", errutil.WithPublic("Query {{ .Public.RefId }} is missing datasource information"))
 	ErrQueryParamMismatch    = errutil.BadRequest("query.headerMismatch", errutil.WithPublicMessage("The request headers point to a different plugin than is defined in the request body")).Errorf("plugin header/body mismatch")
 	ErrDuplicateRefId        = errutil.BadRequest("query.duplicateRefId", errutil.WithPublicMessage("Multiple queries using the same RefId is not allowed ")).Errorf("multiple queries using the same RefId is not allowed")
+	ErrQueryPolicyDenied     = errutil.Forbidden("query.policyDenied").MustTemplate("query denied by data source query policies: {{ .Public.RefId }}: {{ .Public.Reason }}", errutil.WithPublic("Query {{ .Public.RefId }} is not allowed by the query policies of the data source: {{ .Public.Reason }}"))
 )
@@ -307,6 +307,11 @@ func (s *ServiceImpl) parseMetricRequest(ctx context.Context, user identity.Requ
 			req.parsedQueries[ds.UID] = []parsedQuery{}
 		}
 
+		if err := applyQueryPolicies(user, ds, query); err != nil {
+			s.log.Info("Query denied by data source query policies", "datasource", ds.UID, "error", err)
+			return nil, err
+		}
+
 		modelJSON, err := query.MarshalJSON()
 		if err != nil {
 			return nil, err
@@ -0,0 +1,327 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+)
+
+// applyQueryPolicies enforces the query policies of the data source on a query of the user.
+// Label selectors are added to Prometheus and Loki queries, and SQL queries are checked against
+// the allowed tables. The query is rewritten in place, and applying the policies again to a
+// rewritten query doesn't change it.
+func applyQueryPolicies(user identity.Requester, ds *datasources.DataSource, query *simplejson.Json) error {
+	refID := query.Get("refId").MustString("A")
+	denied := func(reason string) error {
+		return ErrQueryPolicyDenied.Build(errutil.TemplateData{
+			Public: map[string]any{
+				"RefId":  refID,
+				"Reason": reason,
+			},
+		})
+	}
+
+	queryPolicies, err := ds.QueryPolicies()
+	if err != nil {
+		return denied("invalid query policies")
+	}
+	if queryPolicies == nil {
+		return nil
+	}
+
+	policies := applicableQueryPolicies(user, queryPolicies.Policies)
+	if len(policies) == 0 {
+		if queryPolicies.RestrictAccess {
+			return denied("no query policy applies to the user")
+		}
+		return nil
+	}
+
+	switch ds.Type {
+	case datasources.DS_PROMETHEUS, datasources.DS_LOKI:
+		selectors := make([]string, 0, len(policies))
+		for _, p := range policies {
+			if p.LabelSelector == "" {
+				return nil
+			}
+			if !slices.Contains(selectors, p.LabelSelector) {
+				selectors = append(selectors, p.LabelSelector)
+			}
+		}
+		if len(selectors) > 1 {
+			return denied("the teams and role of the user have different label selectors")
+		}
+		matchers, err := parser.ParseMetricSelector(selectors[0])
+		if err != nil {
+			return denied("invalid label selector")
+		}
+
+		expr := query.Get("expr").MustString()
+		if strings.TrimSpace(expr) == "" {
+			return nil
+		}
+		if ds.Type == datasources.DS_LOKI {
+			expr, err = injectLogQLMatchers(expr, matchers)
+		} else {
+			expr, err = injectPromQLMatchers(expr, matchers)
+		}
+		if err != nil {
+			return denied(err.Error())
+		}
+		query.Set("expr", expr)
+		return nil
+	case datasources.DS_MYSQL, datasources.DS_POSTGRES, "postgres", datasources.DS_MSSQL:
+		var allowedTables []string
+		for _, p := range policies {
+			if len(p.AllowedTables) == 0 {
+				return nil
+			}
+			allowedTables = append(allowedTables, p.AllowedTables...)
+		}
+
+		rawSQL := query.Get("rawSql").MustString()
+		if strings.TrimSpace(rawSQL) == "" {
+			return nil
+		}
+		if err := checkSQLTables(sqlDialectOf(ds.Type), rawSQL, allowedTables); err != nil {
+			return denied(err.Error())
+		}
+		return nil
+	default:
+		return denied(fmt.Sprintf("query policies are not supported by %s data sources", ds.Type))
+	}
+}
+
+// RestrictedByQueryPolicies returns whether the query policies of the data source restrict the
+// queries of the user. Requests which don't go through the query service, like data source proxy
+// and resource calls, can't be checked against the policies and are denied for these users.
+func RestrictedByQueryPolicies(user identity.Requester, ds *datasources.DataSource) bool {
+	queryPolicies, err := ds.QueryPolicies()
+	if err != nil {
+		return true
+	}
+	if queryPolicies == nil {
+		return false
+	}
+
+	policies := applicableQueryPolicies(user, queryPolicies.Policies)
+	if len(policies) == 0 {
+		return queryPolicies.RestrictAccess
+	}
+	for _, p := range policies {
+		switch ds.Type {
+		case datasources.DS_PROMETHEUS, datasources.DS_LOKI:
+			if p.LabelSelector == "" {
+				return false
+			}
+		case datasources.DS_MYSQL, datasources.DS_POSTGRES, "postgres", datasources.DS_MSSQL:
+			if len(p.AllowedTables) == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// applicableQueryPolicies returns the policies of the teams and of the organization role of the user.
+func applicableQueryPolicies(user identity.Requester, policies []datasources.QueryPolicy) []datasources.QueryPolicy {
+	if user == nil {
+		return nil
+	}
+
+	teams := user.GetTeams()
+	role := string(user.GetOrgRole())
+	applicable := make([]datasources.QueryPolicy, 0)
+	for _, p := range policies {
+		if p.TeamID != 0 && slices.Contains(teams, p.TeamID) || p.Role != "" && p.Role == role {
+			applicable = append(applicable, p)
+		}
+	}
+	return applicable
+}
+
+func sqlDialectOf(dsType string) sqlDialect {
+	switch dsType {
+	case datasources.DS_POSTGRES, "postgres":
+		return sqlDialectPostgres
+	case datasources.DS_MSSQL:
+		return sqlDialectMSSQL
+	default:
+		return sqlDialectMySQL
+	}
+}
+
+// promQLMacro matches the variables that the Prometheus data source interpolates in the backend,
+// like $__rate_interval.
+var promQLMacro = regexp.MustCompile(`\$__[A-Za-z_]+`)
+
+// injectPromQLMatchers adds the matchers to every selector of a PromQL query. The query is
+// rewritten as text, so that it stays as written by the user apart from the added matchers.
+func injectPromQLMatchers(expr string, matchers []*labels.Matcher) (string, error) {
+	parsed, err := parser.ParseExpr(promQLMacroPlaceholders(expr))
+	if err != nil {
+		return "", errors.New("the query can't be parsed to apply the label selector")
+	}
+
+	type insertion struct {
+		pos  int
+		text string
+	}
+	var insertions []insertion
+	parser.Inspect(parsed, func(node parser.Node, _ []parser.Node) error {
+		vs, ok := node.(*parser.VectorSelector)
+		if !ok {
+			return nil
+		}
+		missing := missingMatchers(vs.LabelMatchers, matchers)
+		if len(missing) == 0 {
+			return nil
+		}
+
+		start := int(vs.PositionRange().Start)
+		if start < 0 || start >= len(expr) {
+			return nil
+		}
+		pos := start
+		if expr[pos] != '{' {
+			// Skip the metric name, the matchers go in the braces after it or in new braces.
+			for pos < len(expr) && isPromQLNameChar(expr[pos]) {
+				pos++
+			}
+			end := pos
+			for end < len(expr) && (expr[end] == ' ' || expr[end] == '\t' || expr[end] == '\n' || expr[end] == '\r') {
+				end++
+			}
+			if end >= len(expr) || expr[end] != '{' {
+				insertions = append(insertions, insertion{pos: pos, text: "{" + strings.Join(missing, ",") + "}"})
+				return nil
+			}
+			pos = end
+		}
+		insertions = append(insertions, insertion{pos: pos + 1, text: matchersInBraces(expr[pos+1:], missing)})
+		return nil
+	})
+
+	sort.Slice(insertions, func(i, j int) bool { return insertions[i].pos > insertions[j].pos })
+	for _, ins := range insertions {
+		expr = expr[:ins.pos] + ins.text + expr[ins.pos:]
+	}
+	return expr, nil
+}
+
+// promQLMacroPlaceholders replaces the backend variables of a PromQL query with literals of the
+// same length, so that positions in the parsed query are positions in the query. Variables in
+// brackets are durations, other variables are numbers.
+func promQLMacroPlaceholders(expr string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range promQLMacro.FindAllStringIndex(expr, -1) {
+		b.WriteString(expr[last:loc[0]])
+		before := strings.TrimRight(expr[:loc[0]], " \t\n\r")
+		length := loc[1] - loc[0]
+		if strings.HasSuffix(before, "[") || strings.HasSuffix(before, ":") || strings.HasSuffix(strings.ToLower(before), "offset") {
+			b.WriteString(strings.Repeat("0", length-2) + "1s")
+		} else {
+			b.WriteString(strings.Repeat("0", length-1) + "1")
+		}
+		last = loc[1]
+	}
+	b.WriteString(expr[last:])
+	return b.String()
+}
+
+func isPromQLNameChar(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// missingMatchers returns the matchers that the selector doesn't have yet, formatted as PromQL.
+func missingMatchers(existing []*labels.Matcher, matchers []*labels.Matcher) []string {
+	missing := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		found := slices.ContainsFunc(existing, func(e *labels.Matcher) bool {
+			return e.Name == m.Name && e.Type == m.Type && e.Value == m.Value
+		})
+		if !found {
+			missing = append(missing, m.String())
+		}
+	}
+	return missing
+}
+
+// matchersInBraces returns the text to insert after an opening brace followed by rest.
+func matchersInBraces(rest string, matchers []string) string {
+	text := strings.Join(matchers, ",")
+	if strings.HasPrefix(strings.TrimSpace(rest), "}") {
+		return text
+	}
+	return text + ","
+}
+
+// injectLogQLMatchers adds the matchers to every stream selector of a LogQL query. Outside of
+// string literals, braces are only used by stream selectors.
+func injectLogQLMatchers(expr string, matchers []*labels.Matcher) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; c {
+		case '"', '`':
+			end := logQLStringEnd(expr, i)
+			if end < 0 {
+				return "", errors.New("the query has an unterminated string")
+			}
+			b.WriteString(expr[i:end])
+			i = end
+		case '{':
+			end := i + 1
+			for end < len(expr) && expr[end] != '}' {
+				if expr[end] == '"' || expr[end] == '`' {
+					if end = logQLStringEnd(expr, end); end < 0 {
+						return "", errors.New("the query has an unterminated string")
+					}
+					continue
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return "", errors.New("the query has an unterminated stream selector")
+			}
+			existing, err := parser.ParseMetricSelector(expr[i : end+1])
+			if err != nil {
+				return "", errors.New("the query can't be parsed to apply the label selector")
+			}
+			b.WriteByte('{')
+			if missing := missingMatchers(existing, matchers); len(missing) > 0 {
+				b.WriteString(matchersInBraces(expr[i+1:], missing))
+			}
+			i++
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// logQLStringEnd returns the index after the string literal at i, or -1 if it is not terminated.
+func logQLStringEnd(expr string, i int) int {
+	quote := expr[i]
+	for j := i + 1; j < len(expr); j++ {
+		switch {
+		case expr[j] == '\\' && quote == '"':
+			j++
+		case expr[j] == quote:
+			return j + 1
+		}
+	}
+	return -1
+}
@@ -0,0 +1,625 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// sqlDialect changes how comments, strings and identifiers are tokenized, to read a query the
+// way the database does.
+type sqlDialect int
+
+const (
+	sqlDialectMySQL sqlDialect = iota
+	sqlDialectPostgres
+	sqlDialectMSSQL
+)
+
+type sqlTokenKind int
+
+const (
+	sqlTokenWord sqlTokenKind = iota
+	sqlTokenQuotedIdentifier
+	sqlTokenString
+	sqlTokenNumber
+	sqlTokenPunct
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+func (t sqlToken) isPunct(p string) bool {
+	return t.kind == sqlTokenPunct && t.text == p
+}
+
+func (t sqlToken) isKeyword(keywords ...string) bool {
+	if t.kind != sqlTokenWord {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(t.text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// sqlStatementKeywords are the keywords SQL queries restricted by query policies can start with.
+var sqlStatementKeywords = []string{"SELECT", "WITH", "VALUES", "TABLE"}
+
+// sqlDeniedNames are statements and functions that run dynamic SQL or read data outside of
+// tables, so that the tables they read from can't be known from the query text. Functions that
+// change the settings of the connection, like the search path that resolves unqualified table
+// names, are denied too because the settings outlive the query on pooled connections.
+var sqlDeniedNames = map[string]bool{
+	"exec": true, "execute": true, "sp_executesql": true, "xp_cmdshell": true,
+	"openquery": true, "openrowset": true, "opendatasource": true,
+	"dblink": true, "dblink_exec": true, "dblink_open": true, "dblink_send_query": true,
+	"query_to_xml": true, "query_to_xmlschema": true, "query_to_xml_and_xmlschema": true,
+	"table_to_xml": true, "table_to_xmlschema": true, "table_to_xml_and_xmlschema": true,
+	"schema_to_xml": true, "schema_to_xml_and_xmlschema": true, "cursor_to_xml": true,
+	"database_to_xml": true, "database_to_xml_and_xmlschema": true,
+	"ts_stat": true, "ts_rewrite": true,
+	"pg_read_file": true, "pg_read_binary_file": true, "pg_ls_dir": true, "lo_import": true, "lo_get": true,
+	"load_file":  true,
+	"set_config": true,
+}
+
+// sqlTableListEnd are the keywords that end the list of tables of a FROM clause.
+var sqlTableListEnd = []string{
+	"SELECT", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "OFFSET", "FETCH", "FOR", "UNION", "EXCEPT",
+	"INTERSECT", "MINUS", "WINDOW", "QUALIFY", "RETURNING", "INTO", "VALUES", "SET", "OPTION",
+}
+
+// sqlAliasReserved are the keywords that can follow a table reference and that are not its alias.
+var sqlAliasReserved = []string{
+	"ON", "USING", "JOIN", "INNER", "LEFT", "RIGHT", "FULL", "OUTER", "CROSS", "NATURAL", "STRAIGHT_JOIN",
+	"LATERAL", "TABLESAMPLE", "WITH", "USE", "FORCE", "IGNORE", "PARTITION", "AS", "APPLY",
+}
+
+// sqlFromFunctions are the functions that take a FROM keyword in their arguments.
+var sqlFromFunctions = []string{"EXTRACT", "SUBSTRING", "SUBSTR", "TRIM", "OVERLAY"}
+
+// sqlTableName is a table reference, split in its schema qualified parts.
+type sqlTableName []string
+
+func (n sqlTableName) String() string {
+	return strings.Join(n, ".")
+}
+
+// matches returns true if the table is the allowed table, or if the allowed table is
+// schema.* and the table is in the schema.
+func (n sqlTableName) matches(allowed sqlTableName) bool {
+	if len(n) != len(allowed) {
+		return false
+	}
+	for i := range n {
+		if i == len(n)-1 && allowed[i] == "*" {
+			return true
+		}
+		if n[i] != allowed[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAllowedTable parses an allow-list entry like the unquoted table names of queries.
+func parseAllowedTable(dialect sqlDialect, table string) sqlTableName {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = normalizeSQLIdentifier(dialect, sqlToken{kind: sqlTokenWord, text: strings.TrimSpace(part)})
+	}
+	return parts
+}
+
+// normalizeSQLIdentifier folds unquoted identifiers to lower case for PostgreSQL, which is what
+// the database does. MySQL and MSSQL identifiers are kept as they are, so that the allow-list
+// never matches a table that differs by case on case sensitive databases.
+func normalizeSQLIdentifier(dialect sqlDialect, t sqlToken) string {
+	if t.kind == sqlTokenWord && dialect == sqlDialectPostgres {
+		return strings.ToLower(t.text)
+	}
+	return t.text
+}
+
+// checkSQLTables returns an error if the query reads from a table that is not allowed, or if
+// the tables it reads from can't be known from its text.
+func checkSQLTables(dialect sqlDialect, rawSQL string, allowedTables []string) error {
+	tables, err := sqlTableReferences(dialect, rawSQL)
+	if err != nil {
+		return err
+	}
+
+	allowed := make([]sqlTableName, 0, len(allowedTables))
+	for _, table := range allowedTables {
+		allowed = append(allowed, parseAllowedTable(dialect, table))
+	}
+
+	for _, table := range tables {
+		found := false
+		for _, a := range allowed {
+			if table.matches(a) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("table %s is not allowed", table)
+		}
+	}
+	return nil
+}
+
+type sqlFrameKind int
+
+const (
+	sqlFrameGroup sqlFrameKind = iota
+	sqlFrameFromFunction
+	sqlFrameCTE
+)
+
+type sqlFrame struct {
+	kind sqlFrameKind
+	// tableList is true in a FROM clause, where a comma is followed by another table.
+	tableList bool
+	// ctes are the names of the common table expressions defined in the frame.
+	ctes map[string]bool
+	// cteName is the name of the common table expression whose body is the frame.
+	cteName string
+}
+
+// sqlTableWalker finds the tables a query reads from.
+type sqlTableWalker struct {
+	dialect sqlDialect
+	tokens  []sqlToken
+	stack   []*sqlFrame
+	tables  []sqlTableName
+	// nextFrame is the frame to push on the next opening parenthesis.
+	nextFrame *sqlFrame
+}
+
+// sqlTableReferences returns the tables a single SELECT statement reads from, leaving out the
+// common table expressions it defines.
+func sqlTableReferences(dialect sqlDialect, rawSQL string) ([]sqlTableName, error) {
+	tokens, err := tokenizeSQL(dialect, rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	if !tokens[0].isPunct("(") && !tokens[0].isKeyword(sqlStatementKeywords...) {
+		return nil, errors.New("only SELECT statements are allowed")
+	}
+
+	w := &sqlTableWalker{dialect: dialect, tokens: tokens, stack: []*sqlFrame{{}}}
+	if err := w.walk(); err != nil {
+		return nil, err
+	}
+	return w.tables, nil
+}
+
+func (w *sqlTableWalker) top() *sqlFrame {
+	return w.stack[len(w.stack)-1]
+}
+
+func (w *sqlTableWalker) token(i int) sqlToken {
+	if i >= 0 && i < len(w.tokens) {
+		return w.tokens[i]
+	}
+	return sqlToken{kind: sqlTokenPunct}
+}
+
+func (w *sqlTableWalker) walk() error {
+	for i := 0; i < len(w.tokens); {
+		t := w.tokens[i]
+		if (t.kind == sqlTokenWord || t.kind == sqlTokenQuotedIdentifier) && sqlDeniedNames[strings.ToLower(t.text)] {
+			return fmt.Errorf("%s is not allowed", t.text)
+		}
+
+		switch {
+		case t.isPunct(";"):
+			if len(w.stack) != 1 {
+				return errors.New("unbalanced parentheses")
+			}
+			if i != len(w.tokens)-1 {
+				return errors.New("multiple statements are not allowed")
+			}
+			i++
+		case t.isPunct("(") || t.isPunct("["):
+			frame := w.nextFrame
+			w.nextFrame = nil
+			if frame == nil {
+				frame = &sqlFrame{}
+				if t.isPunct("(") && w.token(i-1).isKeyword(sqlFromFunctions...) {
+					frame.kind = sqlFrameFromFunction
+				}
+			}
+			w.stack = append(w.stack, frame)
+			i++
+			if frame.tableList {
+				i = w.readTable(i)
+			}
+		case t.isPunct(")") || t.isPunct("]"):
+			if len(w.stack) == 1 {
+				return errors.New("unbalanced parentheses")
+			}
+			frame := w.top()
+			w.stack = w.stack[:len(w.stack)-1]
+			i++
+			if frame.kind == sqlFrameCTE {
+				w.top().ctes[frame.cteName] = true
+				if w.token(i).isPunct(",") {
+					i = w.readCTE(i+1, false)
+				}
+			}
+		case t.isPunct(","):
+			i++
+			if w.top().tableList {
+				i = w.readTable(i)
+			}
+		case t.isKeyword("FROM"):
+			i++
+			if w.top().kind == sqlFrameFromFunction || w.isDistinctFrom(i-1) {
+				continue
+			}
+			w.top().tableList = true
+			i = w.readTable(i)
+		case t.isKeyword("JOIN", "STRAIGHT_JOIN", "APPLY", "UPDATE"):
+			w.top().tableList = true
+			i = w.readTable(i + 1)
+		case t.isKeyword("INTO", "TABLE"):
+			i = w.readTable(i + 1)
+		case t.isKeyword("USING") && !w.token(i+1).isPunct("("):
+			i = w.readTable(i + 1)
+		case t.isKeyword("WITH"):
+			i++
+			recursive := w.token(i).isKeyword("RECURSIVE")
+			if recursive {
+				i++
+			}
+			i = w.readCTE(i, recursive)
+		case t.isKeyword(sqlTableListEnd...):
+			w.top().tableList = false
+			i++
+		default:
+			i++
+		}
+	}
+
+	if len(w.stack) != 1 {
+		return errors.New("unbalanced parentheses")
+	}
+	return nil
+}
+
+// isDistinctFrom returns whether the FROM keyword at i is part of an IS [NOT] DISTINCT FROM
+// comparison, and not the FROM clause of a SELECT DISTINCT.
+func (w *sqlTableWalker) isDistinctFrom(i int) bool {
+	if !w.token(i - 1).isKeyword("DISTINCT") {
+		return false
+	}
+	if w.token(i - 2).isKeyword("NOT") {
+		return w.token(i - 3).isKeyword("IS")
+	}
+	return w.token(i - 2).isKeyword("IS")
+}
+
+// readTable reads the table reference at i, if any, and returns the index of the next token.
+// A parenthesis at i opens a derived table or a parenthesized join.
+func (w *sqlTableWalker) readTable(i int) int {
+	for {
+		if w.token(i).isKeyword("ONLY", "LATERAL") {
+			i++
+			continue
+		}
+		// ODBC outer join escape, {OJ t1 LEFT OUTER JOIN t2 ON ...}
+		if w.token(i).isPunct("{") && w.token(i+1).isKeyword("OJ") {
+			i += 2
+			continue
+		}
+		break
+	}
+	if w.token(i).isPunct("(") {
+		w.nextFrame = &sqlFrame{tableList: true}
+		return i
+	}
+
+	var name sqlTableName
+	for {
+		t := w.token(i)
+		if t.isPunct(".") && len(name) > 0 {
+			// MSSQL db..table uses the default schema.
+			name = append(name, "")
+		} else if t.kind == sqlTokenQuotedIdentifier || t.kind == sqlTokenString && w.dialect == sqlDialectMySQL ||
+			t.kind == sqlTokenWord && (len(name) > 0 || !t.isKeyword(sqlAliasReserved...) && !t.isKeyword(sqlTableListEnd...)) {
+			name = append(name, normalizeSQLIdentifier(w.dialect, t))
+			i++
+		} else {
+			break
+		}
+		if !w.token(i).isPunct(".") {
+			break
+		}
+		i++
+	}
+	if len(name) == 0 {
+		return i
+	}
+
+	if len(name) == 1 && w.isCTE(name[0]) {
+		return i
+	}
+	w.tables = append(w.tables, name)
+	return i
+}
+
+// readCTE reads the name of the common table expression defined at i, and prepares the frame
+// of its body. Names are only visible after their definition, unless they are recursive.
+func (w *sqlTableWalker) readCTE(i int, recursive bool) int {
+	t := w.token(i)
+	if t.kind != sqlTokenWord && t.kind != sqlTokenQuotedIdentifier {
+		return i
+	}
+	name := normalizeSQLIdentifier(w.dialect, t)
+	j := i + 1
+	if w.token(j).isPunct("(") {
+		for depth := 0; j < len(w.tokens); j++ {
+			if w.token(j).isPunct("(") {
+				depth++
+			} else if w.token(j).isPunct(")") {
+				depth--
+				if depth == 0 {
+					j++
+					break
+				}
+			}
+		}
+	}
+	if !w.token(j).isKeyword("AS") {
+		return i
+	}
+	j++
+	if w.token(j).isKeyword("NOT") {
+		j++
+	}
+	if w.token(j).isKeyword("MATERIALIZED") {
+		j++
+	}
+	if !w.token(j).isPunct("(") {
+		return i
+	}
+
+	if w.top().ctes == nil {
+		w.top().ctes = map[string]bool{}
+	}
+	if recursive {
+		w.top().ctes[name] = true
+	}
+	w.nextFrame = &sqlFrame{kind: sqlFrameCTE, cteName: name}
+	return j
+}
+
+func (w *sqlTableWalker) isCTE(name string) bool {
+	for _, frame := range w.stack {
+		if frame.ctes[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeSQL splits a query into tokens, leaving out comments. It returns an error for string
+// literals that databases read differently depending on their configuration.
+func tokenizeSQL(dialect sqlDialect, rawSQL string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	s := []rune(rawSQL)
+	// mysqlExecutableComment is true within a MySQL /*! ... */ comment, whose content is executed.
+	mysqlExecutableComment := false
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		next := rune(0)
+		if i+1 < len(s) {
+			next = s[i+1]
+		}
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '-' && next == '-' && (dialect != sqlDialectMySQL || i+2 >= len(s) || unicode.IsSpace(s[i+2]) || unicode.IsControl(s[i+2])),
+			c == '#' && dialect == sqlDialectMySQL:
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '/' && next == '*':
+			if dialect == sqlDialectMySQL && i+2 < len(s) && s[i+2] == '!' {
+				i += 3
+				for i < len(s) && unicode.IsDigit(s[i]) {
+					i++
+				}
+				mysqlExecutableComment = true
+				continue
+			}
+			end, err := skipSQLComment(dialect, s, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '*' && next == '/' && mysqlExecutableComment:
+			mysqlExecutableComment = false
+			i += 2
+		case c == '\'':
+			end, err := scanSQLString(s, i, '\'', dialect != sqlDialectMSSQL, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenString, text: string(s[i+1 : end-1])})
+			i = end
+		case c == '"' && dialect == sqlDialectMySQL:
+			end, err := scanSQLString(s, i, '"', true, false)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenString, text: string(s[i+1 : end-1])})
+			i = end
+		case c == '"', c == '`' && dialect == sqlDialectMySQL, c == '[' && dialect == sqlDialectMSSQL:
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end, err := scanSQLQuotedIdentifier(s, i, closing)
+			if err != nil {
+				return nil, err
+			}
+			text := string(s[i+1 : end-1])
+			text = strings.ReplaceAll(text, string([]rune{closing, closing}), string(closing))
+			tokens = append(tokens, sqlToken{kind: sqlTokenQuotedIdentifier, text: text})
+			i = end
+		case c == '$' && dialect == sqlDialectPostgres && isDollarQuote(s, i):
+			end, err := skipDollarQuotedString(s, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenString})
+			i = end
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(s) && unicode.IsDigit(s[i]) {
+				i++
+			}
+			if i < len(s) && s[i] == '.' {
+				i++
+				for i < len(s) && unicode.IsDigit(s[i]) {
+					i++
+				}
+			}
+			if i+1 < len(s) && (s[i] == 'e' || s[i] == 'E') && (unicode.IsDigit(s[i+1]) || s[i+1] == '+' || s[i+1] == '-') {
+				i += 2
+				for i < len(s) && unicode.IsDigit(s[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenNumber, text: string(s[start:i])})
+		case isSQLWordStart(dialect, c):
+			start := i
+			for i < len(s) && isSQLWordPart(dialect, s[i]) {
+				i++
+			}
+			word := string(s[start:i])
+			if dialect == sqlDialectPostgres && strings.EqualFold(word, "E") && i < len(s) && s[i] == '\'' {
+				end, err := scanSQLString(s, i, '\'', false, true)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, sqlToken{kind: sqlTokenString, text: string(s[i+1 : end-1])})
+				i = end
+				continue
+			}
+			tokens = append(tokens, sqlToken{kind: sqlTokenWord, text: word})
+		default:
+			tokens = append(tokens, sqlToken{kind: sqlTokenPunct, text: string(c)})
+			i++
+		}
+	}
+
+	return tokens, nil
+}
+
+func isSQLWordStart(dialect sqlDialect, c rune) bool {
+	return c == '_' || c == '$' || unicode.IsLetter(c) || dialect == sqlDialectMSSQL && (c == '@' || c == '#')
+}
+
+func isSQLWordPart(dialect sqlDialect, c rune) bool {
+	return isSQLWordStart(dialect, c) || unicode.IsDigit(c)
+}
+
+// skipSQLComment returns the index after the block comment at i. PostgreSQL and MSSQL comments
+// nest.
+func skipSQLComment(dialect sqlDialect, s []rune, i int) (int, error) {
+	depth := 0
+	for i < len(s) {
+		switch {
+		case i+1 < len(s) && s[i] == '/' && s[i+1] == '*' && (depth == 0 || dialect != sqlDialectMySQL):
+			depth++
+			i += 2
+		case i+1 < len(s) && s[i] == '*' && s[i+1] == '/':
+			depth--
+			i += 2
+			if depth == 0 {
+				return i, nil
+			}
+		default:
+			i++
+		}
+	}
+	return 0, errors.New("unterminated comment")
+}
+
+// scanSQLString returns the index after the string literal at i. A backslash before a quote
+// escapes it or not depending on the configuration of MySQL and PostgreSQL, so such strings
+// are rejected where it is ambiguous.
+func scanSQLString(s []rune, i int, quote rune, ambiguousBackslash bool, backslashEscapes bool) (int, error) {
+	for i++; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && backslashEscapes:
+			i++
+		case s[i] == '\\' && ambiguousBackslash && i+1 < len(s) && s[i+1] == quote:
+			return 0, errors.New("backslash before a quote in a string literal is not allowed")
+		case s[i] == quote && i+1 < len(s) && s[i+1] == quote:
+			i++
+		case s[i] == quote:
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated string literal")
+}
+
+func scanSQLQuotedIdentifier(s []rune, i int, closing rune) (int, error) {
+	for i++; i < len(s); i++ {
+		if s[i] != closing {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == closing {
+			i++
+			continue
+		}
+		return i + 1, nil
+	}
+	return 0, errors.New("unterminated quoted identifier")
+}
+
+// isDollarQuote returns true if a PostgreSQL $tag$ string starts at i. Grafana macros like
+// $__timeFilter( are not followed by a dollar sign.
+func isDollarQuote(s []rune, i int) bool {
+	_, ok := dollarQuoteTag(s, i)
+	return ok
+}
+
+func dollarQuoteTag(s []rune, i int) (string, bool) {
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case s[j] == '$':
+			return string(s[i : j+1]), true
+		case s[j] == '_' || unicode.IsLetter(s[j]) || j > i+1 && unicode.IsDigit(s[j]):
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func skipDollarQuotedString(s []rune, i int) (int, error) {
+	tag, _ := dollarQuoteTag(s, i)
+	rest := string(s[i+len([]rune(tag)):])
+	end := strings.Index(rest, tag)
+	if end < 0 {
+		return 0, errors.New("unterminated dollar-quoted string")
+	}
+	return i + len([]rune(tag)) + len([]rune(rest[:end])) + len([]rune(tag)), nil
+}
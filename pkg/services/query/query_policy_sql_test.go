@@ -0,0 +1,264 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSQLTables(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		dialect sqlDialect
+		sql     string
+		allowed []string
+		wantErr string
+	}{
+		{
+			desc:    "allowed table",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT time, value FROM orders_team_a WHERE $__timeFilter(time) ORDER BY 1`,
+			allowed: []string{"orders_team_a"},
+		},
+		{
+			desc:    "table that is not allowed",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders_team_b`,
+			allowed: []string{"orders_team_a"},
+			wantErr: "table orders_team_b is not allowed",
+		},
+		{
+			desc:    "joined table that is not allowed",
+			dialect: sqlDialectMySQL,
+			sql:     "SELECT * FROM orders o LEFT JOIN `customers` c ON o.customer_id = c.id",
+			allowed: []string{"orders"},
+			wantErr: "table customers is not allowed",
+		},
+		{
+			desc:    "comma separated tables",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT a.x, b.y FROM orders a, secret b WHERE a.id IN (1, 2) GROUP BY a.x, b.y`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "subquery",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders WHERE id IN (SELECT order_id FROM secret)`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "parenthesized join",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT * FROM (secret JOIN orders ON true)`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "odbc outer join escape",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT * FROM {OJ secret LEFT OUTER JOIN orders ON true}`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "derived table with alias",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT t.x FROM (SELECT x, y FROM orders) AS t, orders_archive`,
+			allowed: []string{"orders", "orders_archive"},
+		},
+		{
+			desc:    "schema wildcard",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM reporting.orders JOIN reporting.customers USING (customer_id)`,
+			allowed: []string{"reporting.*"},
+		},
+		{
+			desc:    "schema wildcard doesn't allow other schemas",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM public.orders`,
+			allowed: []string{"reporting.*"},
+			wantErr: "table public.orders is not allowed",
+		},
+		{
+			desc:    "unqualified allowed table doesn't allow qualified tables",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT * FROM otherdb.orders`,
+			allowed: []string{"orders"},
+			wantErr: "table otherdb.orders is not allowed",
+		},
+		{
+			desc:    "postgres folds unquoted identifiers",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM Reporting.ORDERS`,
+			allowed: []string{"reporting.orders"},
+		},
+		{
+			desc:    "postgres quoted identifiers are case sensitive",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM "ORDERS"`,
+			allowed: []string{"orders"},
+			wantErr: "table ORDERS is not allowed",
+		},
+		{
+			desc:    "mssql bracket identifiers",
+			dialect: sqlDialectMSSQL,
+			sql:     `SELECT TOP 10 * FROM [dbo].[orders] WITH (NOLOCK)`,
+			allowed: []string{"dbo.orders"},
+		},
+		{
+			desc:    "common table expressions",
+			dialect: sqlDialectPostgres,
+			sql:     `WITH recent AS (SELECT * FROM orders), totals (n) AS (SELECT count(*) FROM recent) SELECT * FROM recent, totals`,
+			allowed: []string{"orders"},
+		},
+		{
+			desc:    "common table expression reading its own name reads the table",
+			dialect: sqlDialectPostgres,
+			sql:     `WITH secret AS (SELECT * FROM secret) SELECT * FROM secret`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "common table expression of a subquery is not visible outside of it",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM (WITH secret AS (SELECT 1) SELECT * FROM secret) t, secret`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "window named like a table",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM secret WINDOW secret AS (ORDER BY x)`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "from in function arguments",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT extract(epoch FROM time), trim(BOTH ' ' FROM name), a IS DISTINCT FROM b, a IS NOT DISTINCT FROM b FROM orders`,
+			allowed: []string{"orders"},
+		},
+		{
+			desc:    "select distinct without columns",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT DISTINCT FROM secret WHERE CAST(password AS int) = 1`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "subquery in function arguments",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT extract(epoch FROM (SELECT max(time) FROM secret)) FROM orders`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "table in comments and strings",
+			dialect: sqlDialectPostgres,
+			sql:     "SELECT 'FROM secret' AS s, $$ FROM secret $$ FROM orders -- FROM secret\n/* FROM /* nested */ secret */",
+			allowed: []string{"orders"},
+		},
+		{
+			desc:    "postgres nested comments",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders /* /* */ ' */ UNION SELECT * FROM secret -- '`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "postgres escape strings",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT E'\' FROM secret' FROM orders`,
+			allowed: []string{"orders"},
+		},
+		{
+			desc:    "backslash before a quote",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT 'a\' FROM secret -- ' FROM orders`,
+			allowed: []string{"orders"},
+			wantErr: "backslash before a quote in a string literal is not allowed",
+		},
+		{
+			desc:    "mysql executable comment",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT * FROM orders /*!50000 UNION SELECT * FROM secret */`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "mysql double dash without space is not a comment",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT 1--1 FROM secret`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "mysql decimal followed by a keyword",
+			dialect: sqlDialectMySQL,
+			sql:     `SELECT 1.FROM secret`,
+			allowed: []string{"orders"},
+			wantErr: "table secret is not allowed",
+		},
+		{
+			desc:    "multiple statements",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders; SELECT * FROM orders`,
+			allowed: []string{"orders"},
+			wantErr: "multiple statements are not allowed",
+		},
+		{
+			desc:    "trailing semicolon",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders;`,
+			allowed: []string{"orders"},
+		},
+		{
+			desc:    "statements other than select",
+			dialect: sqlDialectPostgres,
+			sql:     `DO $$ BEGIN PERFORM 1; END $$`,
+			allowed: []string{"orders"},
+			wantErr: "only SELECT statements are allowed",
+		},
+		{
+			desc:    "dynamic sql",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT query_to_xml('SELECT * FROM secret', true, true, '') FROM orders`,
+			allowed: []string{"orders"},
+			wantErr: "query_to_xml is not allowed",
+		},
+		{
+			desc:    "search path change",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT set_config('search_path', 'private', false) FROM orders`,
+			allowed: []string{"orders"},
+			wantErr: "set_config is not allowed",
+		},
+		{
+			desc:    "mssql dynamic sql",
+			dialect: sqlDialectMSSQL,
+			sql:     `SELECT * FROM orders EXEC('SELECT * FROM secret')`,
+			allowed: []string{"orders"},
+			wantErr: "EXEC is not allowed",
+		},
+		{
+			desc:    "unbalanced parentheses",
+			dialect: sqlDialectPostgres,
+			sql:     `SELECT * FROM orders WHERE (a = 1`,
+			allowed: []string{"orders"},
+			wantErr: "unbalanced parentheses",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := checkSQLTables(tc.dialect, tc.sql, tc.allowed)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
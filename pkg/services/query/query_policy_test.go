@@ -0,0 +1,195 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestInjectPromQLMatchers(t *testing.T) {
+	matchers, err := parser.ParseMetricSelector(`{tenant="team-a"}`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		expr string
+		want string
+	}{
+		{
+			expr: `up`,
+			want: `up{tenant="team-a"}`,
+		},
+		{
+			expr: `rate(http_requests_total{job="api"}[$__rate_interval])`,
+			want: `rate(http_requests_total{tenant="team-a",job="api"}[$__rate_interval])`,
+		},
+		{
+			expr: `sum by (job) (up{}) / count(node:cpu:ratio offset $__interval) * $__range_s`,
+			want: `sum by (job) (up{tenant="team-a"}) / count(node:cpu:ratio{tenant="team-a"} offset $__interval) * $__range_s`,
+		},
+		{
+			expr: `{__name__=~"up|down"}`,
+			want: `{tenant="team-a",__name__=~"up|down"}`,
+		},
+		{
+			expr: `max_over_time(rate(up [5m])[1h:$__interval])`,
+			want: `max_over_time(rate(up{tenant="team-a"} [5m])[1h:$__interval])`,
+		},
+		{
+			expr: `up{tenant="team-a"}`,
+			want: `up{tenant="team-a"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := injectPromQLMatchers(tc.expr, matchers)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+
+			again, err := injectPromQLMatchers(got, matchers)
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+
+	_, err = injectPromQLMatchers(`sum(up`, matchers)
+	require.Error(t, err)
+}
+
+func TestInjectLogQLMatchers(t *testing.T) {
+	matchers, err := parser.ParseMetricSelector(`{tenant="team-a"}`)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		expr string
+		want string
+	}{
+		{
+			expr: `{app="api"} |= "error"`,
+			want: `{tenant="team-a",app="api"} |= "error"`,
+		},
+		{
+			expr: "sum(count_over_time({app=\"api\"} | json | line_format `{{.msg}}` [$__auto])) / sum(count_over_time({app=~\"a|b\"}[5m]))",
+			want: "sum(count_over_time({tenant=\"team-a\",app=\"api\"} | json | line_format `{{.msg}}` [$__auto])) / sum(count_over_time({tenant=\"team-a\",app=~\"a|b\"}[5m]))",
+		},
+		{
+			expr: `{app="api"} | label_format msg="{{.level}} \"{x}\""`,
+			want: `{tenant="team-a",app="api"} | label_format msg="{{.level}} \"{x}\""`,
+		},
+		{
+			expr: `{tenant="team-a", app="api"}`,
+			want: `{tenant="team-a", app="api"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := injectLogQLMatchers(tc.expr, matchers)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err = injectLogQLMatchers(`{app="api"`, matchers)
+	require.Error(t, err)
+}
+
+func TestApplyQueryPolicies(t *testing.T) {
+	newDataSource := func(t *testing.T, dsType string, queryPolicies string) *datasources.DataSource {
+		jsonData, err := simplejson.NewJson([]byte(`{"queryPolicies": ` + queryPolicies + `}`))
+		require.NoError(t, err)
+		return &datasources.DataSource{UID: "ds", Type: dsType, JsonData: jsonData}
+	}
+	newQuery := func(t *testing.T, model string) *simplejson.Json {
+		query, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		return query
+	}
+
+	teamA := &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer, Teams: []int64{1}}
+	teamB := &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer, Teams: []int64{2}}
+	bothTeams := &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleViewer, Teams: []int64{1, 2}}
+	admin := &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleAdmin, Teams: []int64{1}}
+	noTeam := &user.SignedInUser{OrgID: 1, OrgRole: identity.RoleEditor}
+
+	prometheus := newDataSource(t, datasources.DS_PROMETHEUS, `{"policies": [
+		{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"},
+		{"teamId": 2, "labelSelector": "{tenant=\"team-b\"}"},
+		{"role": "Admin"}
+	]}`)
+
+	t.Run("adds the label selector of the team of the user", func(t *testing.T) {
+		query := newQuery(t, `{"refId": "A", "expr": "up"}`)
+		require.NoError(t, applyQueryPolicies(teamA, prometheus, query))
+		assert.Equal(t, `up{tenant="team-a"}`, query.Get("expr").MustString())
+
+		query = newQuery(t, `{"refId": "A", "expr": "up"}`)
+		require.NoError(t, applyQueryPolicies(teamB, prometheus, query))
+		assert.Equal(t, `up{tenant="team-b"}`, query.Get("expr").MustString())
+	})
+
+	t.Run("a policy without label selector grants unrestricted access", func(t *testing.T) {
+		query := newQuery(t, `{"refId": "A", "expr": "up"}`)
+		require.NoError(t, applyQueryPolicies(admin, prometheus, query))
+		assert.Equal(t, "up", query.Get("expr").MustString())
+	})
+
+	t.Run("denies users with different label selectors", func(t *testing.T) {
+		query := newQuery(t, `{"refId": "A", "expr": "up"}`)
+		err := applyQueryPolicies(bothTeams, prometheus, query)
+		require.ErrorIs(t, err, ErrQueryPolicyDenied)
+	})
+
+	t.Run("users without policy are unrestricted unless access is restricted", func(t *testing.T) {
+		query := newQuery(t, `{"refId": "A", "expr": "up"}`)
+		require.NoError(t, applyQueryPolicies(noTeam, prometheus, query))
+		assert.Equal(t, "up", query.Get("expr").MustString())
+
+		restricted := newDataSource(t, datasources.DS_PROMETHEUS, `{"restrictAccess": true, "policies": [{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"}]}`)
+		err := applyQueryPolicies(noTeam, restricted, query)
+		require.ErrorIs(t, err, ErrQueryPolicyDenied)
+	})
+
+	t.Run("checks the tables of SQL queries", func(t *testing.T) {
+		postgres := newDataSource(t, datasources.DS_POSTGRES, `{"policies": [{"role": "Viewer", "allowedTables": ["reporting.*"]}]}`)
+
+		query := newQuery(t, `{"refId": "A", "rawSql": "SELECT * FROM reporting.orders"}`)
+		require.NoError(t, applyQueryPolicies(teamA, postgres, query))
+
+		query = newQuery(t, `{"refId": "A", "rawSql": "SELECT * FROM public.users"}`)
+		err := applyQueryPolicies(teamA, postgres, query)
+		require.ErrorIs(t, err, ErrQueryPolicyDenied)
+		require.ErrorContains(t, err, "table public.users is not allowed")
+
+		query = newQuery(t, `{"refId": "A", "rawSql": "SELECT * FROM public.users"}`)
+		require.NoError(t, applyQueryPolicies(noTeam, postgres, query))
+	})
+
+	t.Run("denies restricted queries to data sources without policy support", func(t *testing.T) {
+		graphite := newDataSource(t, datasources.DS_GRAPHITE, `{"policies": [{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"}]}`)
+		query := newQuery(t, `{"refId": "A", "target": "*"}`)
+		err := applyQueryPolicies(teamA, graphite, query)
+		require.ErrorIs(t, err, ErrQueryPolicyDenied)
+
+		require.NoError(t, applyQueryPolicies(noTeam, graphite, query))
+	})
+
+	t.Run("users are restricted if the query policies change their queries", func(t *testing.T) {
+		assert.True(t, RestrictedByQueryPolicies(teamA, prometheus))
+		assert.True(t, RestrictedByQueryPolicies(bothTeams, prometheus))
+		assert.False(t, RestrictedByQueryPolicies(admin, prometheus))
+		assert.False(t, RestrictedByQueryPolicies(noTeam, prometheus))
+
+		restricted := newDataSource(t, datasources.DS_PROMETHEUS, `{"restrictAccess": true, "policies": [{"teamId": 1, "labelSelector": "{tenant=\"team-a\"}"}]}`)
+		assert.True(t, RestrictedByQueryPolicies(noTeam, restricted))
+
+		assert.False(t, RestrictedByQueryPolicies(teamA, &datasources.DataSource{Type: datasources.DS_PROMETHEUS}))
+	})
+}
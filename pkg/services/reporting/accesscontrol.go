@@ -0,0 +1,55 @@
+package reporting
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead   = "scheduled.reports:read"
+	ActionCreate = "scheduled.reports:create"
+	ActionWrite  = "scheduled.reports:write"
+	ActionDelete = "scheduled.reports:delete"
+	ActionSend   = "scheduled.reports:send"
+)
+
+var (
+	reportReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:scheduled.reports:reader",
+		DisplayName: "Scheduled report reader",
+		Description: "List and read scheduled reports",
+		Group:       "Reporting",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead},
+		},
+	}
+
+	reportWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:scheduled.reports:writer",
+		DisplayName: "Scheduled report writer",
+		Description: "Create, update, delete, send, list and read scheduled reports",
+		Group:       "Reporting",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead},
+			{Action: ActionCreate},
+			{Action: ActionWrite},
+			{Action: ActionDelete},
+			{Action: ActionSend},
+		},
+	}
+)
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	grants := []string{string(org.RoleAdmin)}
+
+	reportReader := accesscontrol.RoleRegistration{
+		Role:   reportReaderRole,
+		Grants: grants,
+	}
+	reportWriter := accesscontrol.RoleRegistration{
+		Role:   reportWriterRole,
+		Grants: grants,
+	}
+
+	return ac.DeclareFixedRoles(reportWriter, reportReader)
+}
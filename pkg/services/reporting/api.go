@@ -0,0 +1,252 @@
+package reporting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/scheduled-reports", func(reports routing.RouteRegister) {
+		reports.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.getReportsHandler))
+		reports.Post("/", authorize(ac.EvalPermission(ActionCreate)), routing.Wrap(s.createHandler))
+
+		reports.Group("/:uid", func(report routing.RouteRegister) {
+			report.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.getReportHandler))
+			report.Put("/", authorize(ac.EvalPermission(ActionWrite)), routing.Wrap(s.updateHandler))
+			report.Delete("/", authorize(ac.EvalPermission(ActionDelete)), routing.Wrap(s.deleteHandler))
+			report.Post("/send", authorize(ac.EvalPermission(ActionSend)), routing.Wrap(s.sendHandler))
+		})
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /scheduled-reports scheduled_reports getScheduledReports
+//
+// Get all reports of the organization.
+//
+// Responses:
+// 200: getScheduledReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getReportsHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.GetReports(c.Req.Context(), GetReportsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: c.Query("dashboardUid"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get reports", err)
+	}
+	return response.JSON(http.StatusOK, reports)
+}
+
+// swagger:route GET /scheduled-reports/{uid} scheduled_reports getScheduledReport
+//
+// Get report.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getReportHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.GetReport(c.Req.Context(), GetReportQuery{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return reportErrorResponse(err, "Failed to get report")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route POST /scheduled-reports scheduled_reports createScheduledReport
+//
+// Create report.
+//
+// The report renders the dashboard as the user who creates it.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Reports can only be created by users", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.CreatedBy = userID
+
+	if resp := s.checkDashboard(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+	report, err := s.CreateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return reportErrorResponse(err, "Failed to create report")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route PUT /scheduled-reports/{uid} scheduled_reports updateScheduledReport
+//
+// Update report.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetID())
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Reports can only be updated by users", err)
+	}
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UpdatedBy = userID
+
+	if resp := s.checkDashboard(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+	report, err := s.UpdateReport(c.Req.Context(), cmd)
+	if err != nil {
+		return reportErrorResponse(err, "Failed to update report")
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route DELETE /scheduled-reports/{uid} scheduled_reports deleteScheduledReport
+//
+// Delete report.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteReport(c.Req.Context(), DeleteReportCommand{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return reportErrorResponse(err, "Failed to delete report")
+	}
+	return response.Success("Report deleted")
+}
+
+// swagger:route POST /scheduled-reports/{uid}/send scheduled_reports sendScheduledReport
+//
+// Send report immediately, independently of its schedule.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) sendHandler(c *contextmodel.ReqContext) response.Response {
+	// Rendering and sending the report can take longer than the request.
+	ctx := context.WithoutCancel(c.Req.Context())
+	err := s.SendReport(ctx, GetReportQuery{UID: web.Params(c.Req)[":uid"], OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return reportErrorResponse(err, "Failed to send report")
+	}
+	return response.Success("Report sent")
+}
+
+// checkDashboard returns an error response if the dashboard of the report does not exist or
+// the signed in user cannot view it.
+func (s *Service) checkDashboard(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return response.Error(http.StatusBadRequest, ErrDashboardUIDRequired.Error(), nil)
+	}
+	dash, err := s.dashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get dashboard", err)
+	}
+	g, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := g.CanView(); err != nil || !canView {
+		return response.Error(http.StatusForbidden, "Access denied to this dashboard", err)
+	}
+	return nil
+}
+
+func reportErrorResponse(err error, message string) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, "Report not found", err)
+	case errors.Is(err, ErrInvalidSchedule), errors.Is(err, ErrNoRecipients), errors.Is(err, ErrTooManyRecipients),
+		errors.Is(err, ErrInvalidRecipient), errors.Is(err, ErrInvalidFormat), errors.Is(err, ErrDashboardUIDRequired),
+		errors.Is(err, ErrNameRequired), errors.Is(err, ErrInvalidOrientation):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters getScheduledReport updateScheduledReport deleteScheduledReport sendScheduledReport
+type ReportUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getScheduledReports
+type GetReportsParams struct {
+	// in:query
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:parameters createScheduledReport
+type CreateReportParams struct {
+	// in:body
+	// required:true
+	Body ReportSpec `json:"body"`
+}
+
+// swagger:parameters updateScheduledReport
+type UpdateReportParams struct {
+	// in:body
+	// required:true
+	Body ReportSpec `json:"body"`
+}
+
+// swagger:response getScheduledReportsResponse
+type GetReportsResponse struct {
+	// in: body
+	Body []Report `json:"body"`
+}
+
+// swagger:response getScheduledReportResponse
+type GetReportResponse struct {
+	// in: body
+	Body Report `json:"body"`
+}
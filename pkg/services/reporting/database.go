@@ -0,0 +1,104 @@
+package reporting
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store struct {
+	db db.DB
+}
+
+func (s *store) create(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *store) update(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(report.ID).AllCols().Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *store) get(ctx context.Context, query GetReportQuery) (*Report, error) {
+	report := &Report{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *store) list(ctx context.Context, query GetReportsQuery) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		return sess.Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// listEnabled returns the enabled reports of all organizations.
+func (s *store) listEnabled(ctx context.Context) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ?", s.db.GetDialect().BooleanStr(true)).Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *store) delete(ctx context.Context, cmd DeleteReportCommand) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", cmd.OrgID, cmd.UID).Delete(&Report{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+// claimRun sets the last run of the report to the scheduled time if it was not changed since
+// the report was read, so that only one Grafana instance of a cluster sends a scheduled report.
+func (s *store) claimRun(ctx context.Context, report *Report, scheduled time.Time) (bool, error) {
+	var claimed bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE scheduled_report SET last_run_at = ? WHERE id = ? AND last_run_at = ?", scheduled.Unix(), report.ID, report.LastRunAt)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		claimed = affected == 1
+		return err
+	})
+	return claimed, err
+}
+
+func (s *store) setLastError(ctx context.Context, id int64, lastError string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE scheduled_report SET last_error = ? WHERE id = ?", lastError, id)
+		return err
+	})
+}
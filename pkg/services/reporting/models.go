@@ -0,0 +1,112 @@
+package reporting
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrReportNotFound       = errors.New("report not found")
+	ErrInvalidSchedule      = errors.New("invalid schedule")
+	ErrNoRecipients         = errors.New("report has no recipients")
+	ErrTooManyRecipients    = errors.New("report has too many recipients")
+	ErrInvalidRecipient     = errors.New("invalid recipient email address")
+	ErrInvalidFormat        = errors.New("invalid report format")
+	ErrInvalidOrientation   = errors.New("invalid report orientation")
+	ErrDashboardUIDRequired = errors.New("dashboard UID is required")
+	ErrNameRequired         = errors.New("report name is required")
+)
+
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatCSV Format = "csv"
+)
+
+type Orientation string
+
+const (
+	OrientationLandscape Orientation = "landscape"
+	OrientationPortrait  Orientation = "portrait"
+)
+
+// Report is the definition of a dashboard report sent by email on a schedule.
+type Report struct {
+	ID           int64  `xorm:"pk autoincr 'id'" json:"id"`
+	UID          string `xorm:"uid" json:"uid"`
+	OrgID        int64  `xorm:"org_id" json:"orgId"`
+	Name         string `xorm:"name" json:"name"`
+	DashboardUID string `xorm:"dashboard_uid" json:"dashboardUid"`
+	// PanelIDs are the panels of the report, all panels of the dashboard if empty.
+	PanelIDs []int64 `xorm:"panel_ids" json:"panelIds"`
+	// Variables are the template variable values of the dashboard, e.g. {"env": ["prod"]}.
+	Variables map[string][]string `xorm:"variables" json:"variables"`
+	TimeFrom  string              `xorm:"time_from" json:"timeFrom"`
+	TimeTo    string              `xorm:"time_to" json:"timeTo"`
+	Timezone  string              `xorm:"timezone" json:"timezone"`
+	// Schedule is a cron expression with five fields, evaluated in the timezone of the report.
+	Schedule    string      `xorm:"schedule" json:"schedule"`
+	Recipients  []string    `xorm:"recipients" json:"recipients"`
+	Formats     []Format    `xorm:"formats" json:"formats"`
+	Orientation Orientation `xorm:"orientation" json:"orientation"`
+	Message     string      `xorm:"message" json:"message"`
+	Enabled     bool        `xorm:"enabled" json:"enabled"`
+	// CreatedBy is the user who created or last updated the report, the dashboard is rendered as this user.
+	CreatedBy int64     `xorm:"created_by" json:"createdBy"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	// LastRunAt is the scheduled time of the last run in seconds since epoch, 0 if it never ran.
+	LastRunAt int64  `xorm:"last_run_at" json:"lastRunAt,omitempty"`
+	LastError string `xorm:"last_error" json:"lastError,omitempty"`
+}
+
+func (r Report) TableName() string {
+	return "scheduled_report"
+}
+
+// ReportSpec is the part of a report set by users.
+type ReportSpec struct {
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	PanelIDs     []int64             `json:"panelIds"`
+	Variables    map[string][]string `json:"variables"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Timezone     string              `json:"timezone"`
+	Schedule     string              `json:"schedule"`
+	Recipients   []string            `json:"recipients"`
+	Formats      []Format            `json:"formats"`
+	Orientation  Orientation         `json:"orientation"`
+	Message      string              `json:"message"`
+	Enabled      bool                `json:"enabled"`
+}
+
+type CreateReportCommand struct {
+	ReportSpec
+	OrgID     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+}
+
+type UpdateReportCommand struct {
+	ReportSpec
+	UID       string `json:"-"`
+	OrgID     int64  `json:"-"`
+	UpdatedBy int64  `json:"-"`
+}
+
+type GetReportQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type GetReportsQuery struct {
+	OrgID int64
+	// DashboardUID filters the reports by dashboard if set.
+	DashboardUID string
+}
+
+type DeleteReportCommand struct {
+	UID   string
+	OrgID int64
+}
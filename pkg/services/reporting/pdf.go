@@ -0,0 +1,83 @@
+package reporting
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/grafana/gofpdf"
+)
+
+const (
+	pdfMargin       = 10.0
+	pdfHeaderHeight = 18.0
+	pdfTitleHeight  = 7.0
+	pdfFooterHeight = 10.0
+)
+
+// pdfImage is a rendered panel of the report.
+type pdfImage struct {
+	title string
+	png   []byte
+}
+
+// buildPDF lays out the panels of a report on A4 pages, one panel per page in landscape
+// orientation and two panels per page in portrait orientation.
+func buildPDF(title, timeRange string, orientation Orientation, images []pdfImage) ([]byte, error) {
+	orientationStr, perPage := "L", 1
+	if orientation == OrientationPortrait {
+		orientationStr, perPage = "P", 2
+	}
+
+	pdf := gofpdf.New(orientationStr, "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 2*pdfMargin
+	contentHeight := pageHeight - 2*pdfMargin - pdfHeaderHeight - pdfFooterHeight
+	slotHeight := contentHeight / float64(perPage)
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin - pdfFooterHeight/2)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, pdfFooterHeight/2, fmt.Sprintf("%d / {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+
+	for i, image := range images {
+		slot := i % perPage
+		if slot == 0 {
+			pdf.AddPage()
+			pdf.SetFont("Helvetica", "B", 16)
+			pdf.CellFormat(contentWidth, pdfHeaderHeight/2, tr(title), "", 1, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.CellFormat(contentWidth, pdfHeaderHeight/2, tr(timeRange), "", 1, "L", false, 0, "")
+		}
+		top := pdfMargin + pdfHeaderHeight + float64(slot)*slotHeight
+
+		pdf.SetXY(pdfMargin, top)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(contentWidth, pdfTitleHeight, tr(image.title), "", 1, "L", false, 0, "")
+
+		name := fmt.Sprintf("panel-%d", i)
+		info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(image.png))
+		if err := pdf.Error(); err != nil {
+			return nil, err
+		}
+		// Scale the image to the available space keeping its aspect ratio.
+		width, height := contentWidth, slotHeight-pdfTitleHeight
+		if ratio := info.Width() / info.Height(); width/ratio > height {
+			width = height * ratio
+		} else {
+			height = width / ratio
+		}
+		pdf.ImageOptions(name, pdfMargin+(contentWidth-width)/2, top+pdfTitleHeight, width, height, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
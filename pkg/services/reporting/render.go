@@ -0,0 +1,203 @@
+package reporting
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+const (
+	defaultTimeFrom = "now-6h"
+	defaultTimeTo   = "now"
+)
+
+// reportPanel is a panel of the dashboard included in a report.
+type reportPanel struct {
+	ID    int64
+	Title string
+	x, y  int
+}
+
+func checkDashboardAccess(ctx context.Context, dash *dashboards.Dashboard, renderUser *user.SignedInUser) error {
+	g, err := guardian.NewByDashboard(ctx, dash, renderUser.GetOrgID(), renderUser)
+	if err != nil {
+		return err
+	}
+	canView, err := g.CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return errDashboardAccessDenied
+	}
+	return nil
+}
+
+// timeRange returns the time range of the report, the time range of the dashboard if the
+// report does not set one.
+func timeRange(report *Report, dash *dashboards.Dashboard) (string, string) {
+	if report.TimeFrom != "" && report.TimeTo != "" {
+		return report.TimeFrom, report.TimeTo
+	}
+	from := dash.Data.GetPath("time", "from").MustString(defaultTimeFrom)
+	to := dash.Data.GetPath("time", "to").MustString(defaultTimeTo)
+	return from, to
+}
+
+// reportPanels returns the panels of the report in the order of the dashboard layout. All panels
+// of the dashboard except rows are included if the report does not select panels.
+func reportPanels(report *Report, data *simplejson.Json) []reportPanel {
+	var panels []reportPanel
+	var collect func(list []any)
+	collect = func(list []any) {
+		for _, p := range list {
+			panel := simplejson.NewFromAny(p)
+			if panel.Get("type").MustString() == "row" {
+				collect(panel.Get("panels").MustArray())
+				continue
+			}
+			panels = append(panels, reportPanel{
+				ID:    panel.Get("id").MustInt64(),
+				Title: panel.Get("title").MustString(),
+				x:     panel.GetPath("gridPos", "x").MustInt(),
+				y:     panel.GetPath("gridPos", "y").MustInt(),
+			})
+		}
+	}
+	collect(data.Get("panels").MustArray())
+	sort.SliceStable(panels, func(i, j int) bool {
+		if panels[i].y != panels[j].y {
+			return panels[i].y < panels[j].y
+		}
+		return panels[i].x < panels[j].x
+	})
+
+	if len(report.PanelIDs) == 0 {
+		return panels
+	}
+	selected := make(map[int64]bool, len(report.PanelIDs))
+	for _, id := range report.PanelIDs {
+		selected[id] = true
+	}
+	filtered := make([]reportPanel, 0, len(report.PanelIDs))
+	for _, p := range panels {
+		if selected[p.ID] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// panelPath returns the path of the page rendering a single panel of the dashboard.
+func panelPath(report *Report, dash *dashboards.Dashboard, panelID int64) string {
+	from, to := timeRange(report, dash)
+	u := url.URL{}
+	u.Path = path.Join("d-solo", dash.UID, dash.Slug)
+	p := u.Query()
+	p.Add("orgId", strconv.FormatInt(dash.OrgID, 10))
+	p.Add("panelId", strconv.FormatInt(panelID, 10))
+	p.Add("from", from)
+	p.Add("to", to)
+	if report.Timezone != "" {
+		p.Add("tz", report.Timezone)
+	}
+	for name, values := range report.Variables {
+		for _, v := range values {
+			p.Add("var-"+name, v)
+		}
+	}
+	u.RawQuery = p.Encode()
+	return u.String()
+}
+
+// render renders the formats of the report and returns them as email attachments.
+func (s *Service) render(ctx context.Context, report *Report, dash *dashboards.Dashboard, renderUser *user.SignedInUser) ([]*notifications.SendEmailAttachFile, error) {
+	panels := reportPanels(report, dash.Data)
+	if len(panels) == 0 {
+		return nil, fmt.Errorf("dashboard %s has no panels to report", dash.UID)
+	}
+
+	authOpts := rendering.AuthOpts{
+		OrgID:   report.OrgID,
+		UserID:  renderUser.UserID,
+		OrgRole: renderUser.OrgRole,
+	}
+	session, err := s.renderService.CreateRenderingSession(ctx, authOpts, rendering.SessionOpts{
+		Expiry:                     s.cfg.Reporting.RenderTimeout * 2,
+		RefreshExpiryOnEachRequest: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rendering session: %w", err)
+	}
+	defer session.Dispose(ctx)
+
+	commonOpts := func(panelID int64) rendering.CommonOpts {
+		return rendering.CommonOpts{
+			TimeoutOpts:     rendering.TimeoutOpts{Timeout: s.cfg.Reporting.RenderTimeout},
+			AuthOpts:        authOpts,
+			Path:            panelPath(report, dash, panelID),
+			Timezone:        report.Timezone,
+			ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+		}
+	}
+
+	var files []*notifications.SendEmailAttachFile
+	for _, format := range report.Formats {
+		switch format {
+		case FormatPDF:
+			images := make([]pdfImage, 0, len(panels))
+			for _, panel := range panels {
+				result, err := s.renderService.Render(ctx, rendering.RenderPNG, rendering.Opts{
+					CommonOpts: commonOpts(panel.ID),
+					ErrorOpts: rendering.ErrorOpts{
+						ErrorConcurrentLimitReached: true,
+						ErrorRenderUnavailable:      true,
+					},
+					Width:  s.cfg.Reporting.ImageWidth,
+					Height: s.cfg.Reporting.ImageHeight,
+					Theme:  models.ThemeLight,
+				}, session)
+				if err != nil {
+					return nil, fmt.Errorf("failed to render panel %d: %w", panel.ID, err)
+				}
+				content, err := os.ReadFile(result.FilePath)
+				if err != nil {
+					return nil, err
+				}
+				images = append(images, pdfImage{title: panel.Title, png: content})
+			}
+			from, to := timeRange(report, dash)
+			content, err := buildPDF(dash.Title, fmt.Sprintf("%s to %s", from, to), report.Orientation, images)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build PDF: %w", err)
+			}
+			files = append(files, &notifications.SendEmailAttachFile{Name: dash.Slug + ".pdf", Content: content})
+		case FormatCSV:
+			for _, panel := range panels {
+				result, err := s.renderService.RenderCSV(ctx, rendering.CSVOpts{CommonOpts: commonOpts(panel.ID)}, session)
+				if err != nil {
+					return nil, fmt.Errorf("failed to render CSV of panel %d: %w", panel.ID, err)
+				}
+				content, err := os.ReadFile(result.FilePath)
+				if err != nil {
+					return nil, err
+				}
+				name := fmt.Sprintf("%s-panel-%d.csv", dash.Slug, panel.ID)
+				files = append(files, &notifications.SendEmailAttachFile{Name: name, Content: content})
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,338 @@
+// Package reporting sends dashboards as PDF and CSV reports by email on a schedule.
+package reporting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// schedulerInterval is how often the scheduler checks for due reports. Cron schedules
+// have a resolution of one minute.
+const schedulerInterval = time.Minute
+
+type Service struct {
+	cfg                  *setting.Cfg
+	store                *store
+	log                  log.Logger
+	routeRegister        routing.RouteRegister
+	accessControl        ac.AccessControl
+	accessControlService ac.Service
+	renderService        rendering.Service
+	notificationService  notifications.EmailSender
+	dashboardService     dashboards.DashboardService
+	userService          user.Service
+	now                  func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	accessControlService ac.Service, renderService rendering.Service, notificationService notifications.EmailSender,
+	dashboardService dashboards.DashboardService, userService user.Service) (*Service, error) {
+	s := &Service{
+		cfg:                  cfg,
+		store:                &store{db: sqlStore},
+		log:                  log.New("reporting"),
+		routeRegister:        routeRegister,
+		accessControl:        accessControl,
+		accessControlService: accessControlService,
+		renderService:        renderService,
+		notificationService:  notificationService,
+		dashboardService:     dashboardService,
+		userService:          userService,
+		now:                  time.Now,
+	}
+
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+	return s, nil
+}
+
+// IsDisabled disables the scheduler of reports. Reports can still be managed and sent manually.
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Reporting.Enabled
+}
+
+// Run sends the reports when they are due.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sendDueReports(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) sendDueReports(ctx context.Context) {
+	reports, err := s.store.listEnabled(ctx)
+	if err != nil {
+		s.log.Error("Failed to list reports", "error", err)
+		return
+	}
+	now := s.now()
+	for _, report := range reports {
+		scheduled, due, err := dueRun(report, now)
+		if err != nil {
+			s.log.Warn("Invalid report schedule", "orgId", report.OrgID, "uid", report.UID, "error", err)
+			continue
+		}
+		if !due {
+			continue
+		}
+		claimed, err := s.store.claimRun(ctx, report, scheduled)
+		if err != nil {
+			s.log.Error("Failed to update report", "orgId", report.OrgID, "uid", report.UID, "error", err)
+			continue
+		}
+		// Another Grafana instance sends the report.
+		if !claimed {
+			continue
+		}
+		s.log.Info("Sending scheduled report", "orgId", report.OrgID, "uid", report.UID, "scheduled", scheduled)
+		s.sendAndRecord(ctx, report)
+	}
+}
+
+// sendAndRecord sends the report and records the outcome as the last error of the report.
+func (s *Service) sendAndRecord(ctx context.Context, report *Report) {
+	lastError := ""
+	if err := s.send(ctx, report); err != nil {
+		s.log.Error("Failed to send report", "orgId", report.OrgID, "uid", report.UID, "error", err)
+		lastError = err.Error()
+	}
+	if err := s.store.setLastError(ctx, report.ID, lastError); err != nil {
+		s.log.Error("Failed to update report", "orgId", report.OrgID, "uid", report.UID, "error", err)
+	}
+}
+
+// dueRun returns the latest scheduled time of the report which is not after now, if the report
+// has not run for it yet. Runs missed while Grafana was down are sent only once.
+func dueRun(report *Report, now time.Time) (time.Time, bool, error) {
+	schedule, loc, err := parseSchedule(report.Schedule, report.Timezone)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	// Schedule changes apply from the time of the change.
+	since := report.Updated
+	if last := time.Unix(report.LastRunAt, 0); report.LastRunAt > 0 && last.After(since) {
+		since = last
+	}
+	var scheduled time.Time
+	for next := schedule.Next(since.In(loc)); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		scheduled = next
+	}
+	return scheduled, !scheduled.IsZero(), nil
+}
+
+func parseSchedule(schedule, timezone string) (cron.Schedule, *time.Location, error) {
+	loc := time.UTC
+	if timezone != "" && timezone != "browser" && timezone != "utc" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSchedule, timezone)
+		}
+	}
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	return parsed, loc, nil
+}
+
+func (s *Service) validate(spec *ReportSpec) error {
+	if spec.Name == "" {
+		return ErrNameRequired
+	}
+	if spec.DashboardUID == "" {
+		return ErrDashboardUIDRequired
+	}
+	if _, _, err := parseSchedule(spec.Schedule, spec.Timezone); err != nil {
+		return err
+	}
+	if len(spec.Recipients) == 0 {
+		return ErrNoRecipients
+	}
+	if len(spec.Recipients) > s.cfg.Reporting.MaxRecipients {
+		return ErrTooManyRecipients
+	}
+	for _, r := range spec.Recipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidRecipient, r)
+		}
+	}
+	if len(spec.Formats) == 0 {
+		spec.Formats = []Format{FormatPDF}
+	}
+	for _, f := range spec.Formats {
+		if f != FormatPDF && f != FormatCSV {
+			return fmt.Errorf("%w: %q", ErrInvalidFormat, f)
+		}
+	}
+	switch spec.Orientation {
+	case "":
+		spec.Orientation = OrientationLandscape
+	case OrientationLandscape, OrientationPortrait:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidOrientation, spec.Orientation)
+	}
+	if spec.TimeFrom == "" || spec.TimeTo == "" {
+		spec.TimeFrom, spec.TimeTo = "", ""
+	}
+	return nil
+}
+
+func (s *Service) CreateReport(ctx context.Context, cmd CreateReportCommand) (*Report, error) {
+	if err := s.validate(&cmd.ReportSpec); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	report := &Report{
+		UID:       util.GenerateShortUID(),
+		OrgID:     cmd.OrgID,
+		CreatedBy: cmd.CreatedBy,
+		Created:   now,
+		Updated:   now,
+	}
+	report.apply(cmd.ReportSpec)
+	if err := s.store.create(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) UpdateReport(ctx context.Context, cmd UpdateReportCommand) (*Report, error) {
+	if err := s.validate(&cmd.ReportSpec); err != nil {
+		return nil, err
+	}
+	report, err := s.store.get(ctx, GetReportQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	report.apply(cmd.ReportSpec)
+	// The report is rendered as the user who changed it last, so that the updated dashboard and
+	// variables can't expose data the user can't view.
+	report.CreatedBy = cmd.UpdatedBy
+	report.Updated = s.now()
+	if err := s.store.update(ctx, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *Service) GetReport(ctx context.Context, query GetReportQuery) (*Report, error) {
+	return s.store.get(ctx, query)
+}
+
+func (s *Service) GetReports(ctx context.Context, query GetReportsQuery) ([]*Report, error) {
+	return s.store.list(ctx, query)
+}
+
+func (s *Service) DeleteReport(ctx context.Context, cmd DeleteReportCommand) error {
+	return s.store.delete(ctx, cmd)
+}
+
+// SendReport renders and sends the report immediately, independently of its schedule.
+func (s *Service) SendReport(ctx context.Context, query GetReportQuery) error {
+	report, err := s.store.get(ctx, query)
+	if err != nil {
+		return err
+	}
+	err = s.send(ctx, report)
+	lastError := ""
+	if err != nil {
+		lastError = err.Error()
+	}
+	if err := s.store.setLastError(ctx, report.ID, lastError); err != nil {
+		s.log.Error("Failed to update report", "orgId", report.OrgID, "uid", report.UID, "error", err)
+	}
+	return err
+}
+
+func (r *Report) apply(spec ReportSpec) {
+	r.Name = spec.Name
+	r.DashboardUID = spec.DashboardUID
+	r.PanelIDs = spec.PanelIDs
+	r.Variables = spec.Variables
+	r.TimeFrom = spec.TimeFrom
+	r.TimeTo = spec.TimeTo
+	r.Timezone = spec.Timezone
+	r.Schedule = spec.Schedule
+	r.Recipients = spec.Recipients
+	r.Formats = spec.Formats
+	r.Orientation = spec.Orientation
+	r.Message = spec.Message
+	r.Enabled = spec.Enabled
+}
+
+// send renders the report as the user who created it and sends it to the recipients.
+func (s *Service) send(ctx context.Context, report *Report) error {
+	renderUser, err := s.getRenderUser(ctx, report)
+	if err != nil {
+		return err
+	}
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: report.DashboardUID, OrgID: report.OrgID})
+	if err != nil {
+		return err
+	}
+	if err := checkDashboardAccess(ctx, dash, renderUser); err != nil {
+		return err
+	}
+
+	files, err := s.render(ctx, report, dash, renderUser)
+	if err != nil {
+		return err
+	}
+
+	from, to := timeRange(report, dash)
+	err = s.notificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       report.Recipients,
+			Template: "report",
+			Subject:  report.Name,
+			Data: map[string]any{
+				"Name":           report.Name,
+				"DashboardTitle": dash.Title,
+				"DashboardURL":   dashboards.GetFullDashboardURL(dash.UID, dash.Slug),
+				"TimeRange":      fmt.Sprintf("%s to %s", from, to),
+				"Message":        report.Message,
+			},
+			AttachedFiles: files,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// getRenderUser returns the user who created the report, with the permissions of the user in
+// the organization of the report.
+func (s *Service) getRenderUser(ctx context.Context, report *Report) (*user.SignedInUser, error) {
+	renderUser, err := ac.GetSignedInUserWithPermissions(ctx, s.userService, s.accessControlService, report.CreatedBy, report.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the user of the report: %w", err)
+	}
+	return renderUser, nil
+}
+
+var errDashboardAccessDenied = errors.New("the user of the report cannot view the dashboard")
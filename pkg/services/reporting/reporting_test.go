@@ -0,0 +1,198 @@
+package reporting
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestDueRun(t *testing.T) {
+	updated := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc      string
+		report    Report
+		now       time.Time
+		due       bool
+		scheduled time.Time
+	}{
+		{
+			desc:   "not due before the first scheduled time",
+			report: Report{Schedule: "0 9 * * *", Updated: updated},
+			now:    updated.Add(20 * time.Minute),
+		},
+		{
+			desc:      "due at the scheduled time",
+			report:    Report{Schedule: "0 9 * * *", Updated: updated},
+			now:       updated.Add(30 * time.Minute),
+			due:       true,
+			scheduled: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:   "not due again after the last run",
+			report: Report{Schedule: "0 9 * * *", Updated: updated, LastRunAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC).Unix()},
+			now:    updated.Add(2 * time.Hour),
+		},
+		{
+			desc:      "missed runs are sent once",
+			report:    Report{Schedule: "0 9 * * *", Updated: updated, LastRunAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC).Unix()},
+			now:       time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+			due:       true,
+			scheduled: time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:      "schedule is evaluated in the timezone of the report",
+			report:    Report{Schedule: "0 10 * * *", Timezone: "Europe/Paris", Updated: updated},
+			now:       updated.Add(30 * time.Minute),
+			due:       true,
+			scheduled: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			scheduled, due, err := dueRun(&tc.report, tc.now)
+			require.NoError(t, err)
+			require.Equal(t, tc.due, due)
+			if tc.due {
+				require.True(t, tc.scheduled.Equal(scheduled), "expected %s, got %s", tc.scheduled, scheduled)
+			}
+		})
+	}
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		_, _, err := dueRun(&Report{Schedule: "every day"}, updated)
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+
+		_, _, err = dueRun(&Report{Schedule: "0 9 * * *", Timezone: "Mars/Olympus"}, updated)
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+}
+
+func TestReportPanels(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{
+		"time": {"from": "now-24h", "to": "now"},
+		"panels": [
+			{"id": 2, "type": "stat", "title": "Errors", "gridPos": {"x": 12, "y": 0}},
+			{"id": 1, "type": "timeseries", "title": "Requests", "gridPos": {"x": 0, "y": 0}},
+			{"id": 3, "type": "row", "title": "Details", "collapsed": true, "gridPos": {"x": 0, "y": 8}, "panels": [
+				{"id": 4, "type": "table", "title": "Hosts", "gridPos": {"x": 0, "y": 9}}
+			]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	ids := func(panels []reportPanel) []int64 {
+		var ids []int64
+		for _, p := range panels {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int64{1, 2, 4}, ids(reportPanels(&Report{}, data)))
+	require.Equal(t, []int64{2, 4}, ids(reportPanels(&Report{PanelIDs: []int64{4, 2}}, data)))
+
+	t.Run("panel path", func(t *testing.T) {
+		dash := &dashboards.Dashboard{UID: "abc", Slug: "service", OrgID: 1, Data: data}
+		report := &Report{Timezone: "Europe/Paris", Variables: map[string][]string{"env": {"prod", "dev"}}}
+
+		u, err := url.Parse(panelPath(report, dash, 4))
+		require.NoError(t, err)
+		require.Equal(t, "d-solo/abc/service", u.Path)
+		require.Equal(t, url.Values{
+			"orgId":   {"1"},
+			"panelId": {"4"},
+			"from":    {"now-24h"},
+			"to":      {"now"},
+			"tz":      {"Europe/Paris"},
+			"var-env": {"prod", "dev"},
+		}, u.Query())
+	})
+}
+
+// fakeRenderingSession is a rendering session whose key is never requested by the mocked
+// rendering service.
+type fakeRenderingSession struct {
+	rendering.Session
+}
+
+func (fakeRenderingSession) Dispose(context.Context) {}
+
+func TestSend(t *testing.T) {
+	cfg := setting.NewCfg()
+	dash := &dashboards.Dashboard{ID: 1, UID: "abc", Slug: "service", OrgID: 1, Title: "Service", Data: simplejson.NewFromAny(map[string]any{
+		"panels": []any{map[string]any{"id": 1, "type": "table", "title": "Hosts"}},
+	})}
+	report := &Report{ID: 1, UID: "report", OrgID: 1, CreatedBy: 2, Name: "Daily", DashboardUID: "abc", Recipients: []string{"ops@example.com"}, Formats: []Format{FormatCSV}}
+
+	csvPath := filepath.Join(t.TempDir(), "panel.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("host,requests\na,1\n"), 0600))
+
+	setup := func(t *testing.T, permissions []ac.Permission) (*Service, *rendering.MockService, *notifications.NotificationServiceMock) {
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).Return(dash, nil)
+
+		origNewByDashboard := guardian.NewByDashboard
+		t.Cleanup(func() { guardian.NewByDashboard = origNewByDashboard })
+		guardian.InitAccessControlGuardian(cfg, acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), dashSvc)
+
+		renderService := rendering.NewMockService(gomock.NewController(t))
+		notificationService := notifications.MockNotificationService()
+		s := &Service{
+			cfg:                  cfg,
+			log:                  log.NewNopLogger(),
+			accessControlService: actest.FakeService{ExpectedPermissions: permissions},
+			renderService:        renderService,
+			notificationService:  notificationService,
+			dashboardService:     dashSvc,
+			// The user is returned without permissions, as by the user store.
+			userService: &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer}},
+			now:         time.Now,
+		}
+		return s, renderService, notificationService
+	}
+
+	t.Run("renders the report with the permissions of the user who created it", func(t *testing.T) {
+		s, renderService, notificationService := setup(t, []ac.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("abc")},
+		})
+		renderService.EXPECT().CreateRenderingSession(gomock.Any(), gomock.Any(), gomock.Any()).Return(fakeRenderingSession{}, nil)
+		renderService.EXPECT().RenderCSV(gomock.Any(), gomock.Any(), gomock.Any()).Return(&rendering.RenderCSVResult{FilePath: csvPath}, nil)
+
+		require.NoError(t, s.send(context.Background(), report))
+		require.Equal(t, []string{"ops@example.com"}, notificationService.EmailSync.To)
+		require.Len(t, notificationService.EmailSync.AttachedFiles, 1)
+		require.Equal(t, "service-panel-1.csv", notificationService.EmailSync.AttachedFiles[0].Name)
+	})
+
+	t.Run("fails if the user who created the report cannot view the dashboard", func(t *testing.T) {
+		s, _, notificationService := setup(t, nil)
+
+		require.ErrorIs(t, s.send(context.Background(), report), errDashboardAccessDenied)
+		require.Empty(t, notificationService.EmailSync.To)
+	})
+}
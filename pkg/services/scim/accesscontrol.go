@@ -0,0 +1,35 @@
+package scim
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionUsersRead   = "scim.users:read"
+	ActionUsersWrite  = "scim.users:write"
+	ActionGroupsRead  = "scim.groups:read"
+	ActionGroupsWrite = "scim.groups:write"
+)
+
+var provisionerRole = accesscontrol.RoleDTO{
+	Name:        "fixed:scim:provisioner",
+	DisplayName: "SCIM provisioner",
+	Description: "Provision the users and teams of the organization with SCIM",
+	Group:       "SCIM",
+	Permissions: []accesscontrol.Permission{
+		{Action: ActionUsersRead},
+		{Action: ActionUsersWrite},
+		{Action: ActionGroupsRead},
+		{Action: ActionGroupsWrite},
+	},
+}
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	provisioner := accesscontrol.RoleRegistration{
+		Role:   provisionerRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return ac.DeclareFixedRoles(provisioner)
+}
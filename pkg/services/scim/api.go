@@ -0,0 +1,450 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/authlib/claims"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(router routing.RouteRegister, ac accesscontrol.AccessControl) {
+	authorize := accesscontrol.Middleware(ac)
+
+	router.Group("/api/scim/v2", func(scimRoute routing.RouteRegister) {
+		scimRoute.Get("/ServiceProviderConfig", routing.Wrap(s.serviceProviderConfigHandler))
+		scimRoute.Get("/ResourceTypes", routing.Wrap(s.resourceTypesHandler))
+
+		scimRoute.Group("/Users", func(users routing.RouteRegister) {
+			users.Get("/", authorize(accesscontrol.EvalPermission(ActionUsersRead)), routing.Wrap(s.listUsersHandler))
+			users.Post("/", authorize(accesscontrol.EvalPermission(ActionUsersWrite)), routing.Wrap(s.createUserHandler))
+			users.Get("/:id", authorize(accesscontrol.EvalPermission(ActionUsersRead)), routing.Wrap(s.getUserHandler))
+			users.Put("/:id", authorize(accesscontrol.EvalPermission(ActionUsersWrite)), routing.Wrap(s.replaceUserHandler))
+			users.Patch("/:id", authorize(accesscontrol.EvalPermission(ActionUsersWrite)), routing.Wrap(s.patchUserHandler))
+			users.Delete("/:id", authorize(accesscontrol.EvalPermission(ActionUsersWrite)), routing.Wrap(s.deleteUserHandler))
+		})
+
+		scimRoute.Group("/Groups", func(groups routing.RouteRegister) {
+			groups.Get("/", authorize(accesscontrol.EvalPermission(ActionGroupsRead)), routing.Wrap(s.listGroupsHandler))
+			groups.Post("/", authorize(accesscontrol.EvalPermission(ActionGroupsWrite)), routing.Wrap(s.createGroupHandler))
+			groups.Get("/:id", authorize(accesscontrol.EvalPermission(ActionGroupsRead)), routing.Wrap(s.getGroupHandler))
+			groups.Put("/:id", authorize(accesscontrol.EvalPermission(ActionGroupsWrite)), routing.Wrap(s.replaceGroupHandler))
+			groups.Patch("/:id", authorize(accesscontrol.EvalPermission(ActionGroupsWrite)), routing.Wrap(s.patchGroupHandler))
+			groups.Delete("/:id", authorize(accesscontrol.EvalPermission(ActionGroupsWrite)), routing.Wrap(s.deleteGroupHandler))
+		})
+	}, middleware.ReqSignedIn, reqServiceAccount)
+}
+
+// reqServiceAccount only allows requests authenticated with a service account token.
+func reqServiceAccount(c *contextmodel.ReqContext) {
+	if !c.SignedInUser.IsIdentityType(claims.TypeServiceAccount) {
+		jsonResponse(http.StatusForbidden, newError(http.StatusForbidden, "", "SCIM requests have to be authenticated with a service account token")).WriteTo(c)
+	}
+}
+
+func jsonResponse(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", contentType)
+}
+
+func errorResponse(c *contextmodel.ReqContext, err error) response.Response {
+	var scimErr *Error
+	if errors.As(err, &scimErr) {
+		return jsonResponse(scimErr.statusCode(), scimErr)
+	}
+	c.Logger.Error("SCIM request failed", "error", err)
+	return jsonResponse(http.StatusInternalServerError, newError(http.StatusInternalServerError, "", "Internal server error"))
+}
+
+// bind decodes the request body, identity providers send application/scim+json or application/json.
+func bind(c *contextmodel.ReqContext, v any) error {
+	if c.Req.Body == nil {
+		return newError(http.StatusBadRequest, errTypeInvalidSyntax, "missing request body")
+	}
+	defer func() { _ = c.Req.Body.Close() }()
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return newError(http.StatusBadRequest, errTypeInvalidSyntax, "invalid request body: %s", err)
+	}
+	return nil
+}
+
+type listQuery struct {
+	filter     filter
+	startIndex int
+	count      int
+}
+
+func parseListQuery(c *contextmodel.ReqContext) (*listQuery, error) {
+	q := &listQuery{startIndex: 1, count: maxResults}
+	if v := c.Query("filter"); v != "" {
+		f, err := parseFilter(v)
+		if err != nil {
+			return nil, err
+		}
+		q.filter = f
+	}
+	if v := c.Query("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "invalid startIndex %q", v)
+		}
+		q.startIndex = max(n, 1)
+	}
+	if v := c.Query("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "invalid count %q", v)
+		}
+		q.count = min(max(n, 0), maxResults)
+	}
+	return q, nil
+}
+
+// page returns the page of the resources, the start index is 1-based.
+func (q *listQuery) page(resources []any) *ListResponse {
+	start := min(q.startIndex-1, len(resources))
+	end := min(start+q.count, len(resources))
+	return &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   q.startIndex,
+		ItemsPerPage: end - start,
+		Resources:    resources[start:end],
+	}
+}
+
+// swagger:route GET /scim/v2/ServiceProviderConfig scim getSCIMServiceProviderConfig
+//
+// Get the SCIM features supported by Grafana.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) serviceProviderConfigHandler(c *contextmodel.ReqContext) response.Response {
+	return jsonResponse(http.StatusOK, map[string]any{
+		"schemas":          []string{SchemaServiceProviderConfig},
+		"documentationUri": "https://grafana.com/docs/grafana/latest/developers/http_api/scim/",
+		"patch":            map[string]any{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": maxResults},
+		"changePassword":   map[string]any{"supported": false},
+		"sort":             map[string]any{"supported": false},
+		"etag":             map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with the token of a Grafana service account",
+			"primary":     true,
+		}},
+		"meta": Meta{ResourceType: "ServiceProviderConfig", Location: s.cfg.AppURL + "api/scim/v2/ServiceProviderConfig"},
+	})
+}
+
+// swagger:route GET /scim/v2/ResourceTypes scim getSCIMResourceTypes
+//
+// Get the SCIM resource types supported by Grafana.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) resourceTypesHandler(c *contextmodel.ReqContext) response.Response {
+	resourceType := func(name, endpoint, schema string) map[string]any {
+		return map[string]any{
+			"schemas":  []string{SchemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": "/" + endpoint,
+			"schema":   schema,
+			"meta":     Meta{ResourceType: "ResourceType", Location: s.location("ResourceTypes", name)},
+		}
+	}
+	q := &listQuery{startIndex: 1, count: maxResults}
+	return jsonResponse(http.StatusOK, q.page([]any{
+		resourceType(resourceTypeUser, "Users", SchemaUser),
+		resourceType(resourceTypeGroup, "Groups", SchemaGroup),
+	}))
+}
+
+// swagger:route GET /scim/v2/Users scim listSCIMUsers
+//
+// List the users of the organization.
+//
+// Supports the `filter`, `startIndex` and `count` query parameters.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listUsersHandler(c *contextmodel.ReqContext) response.Response {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	users, err := s.getUsers(c.Req.Context(), c.SignedInUser.GetOrgID(), "")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	resources, err := filterResources(users, q.filter)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, q.page(resources))
+}
+
+// swagger:route POST /scim/v2/Users scim createSCIMUser
+//
+// Create a user in the organization.
+//
+// Existing users of other organizations are added to the organization.
+//
+// Responses:
+// 201: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) createUserHandler(c *contextmodel.ReqContext) response.Response {
+	resource := &User{}
+	if err := bind(c, resource); err != nil {
+		return errorResponse(c, err)
+	}
+	created, err := s.createUser(c.Req.Context(), c.SignedInUser.GetOrgID(), resource)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusCreated, created).SetHeader("Location", created.Meta.Location)
+}
+
+// swagger:route GET /scim/v2/Users/{id} scim getSCIMUser
+//
+// Get a user of the organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getUserHandler(c *contextmodel.ReqContext) response.Response {
+	resource, err := s.getUser(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"])
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, resource)
+}
+
+// swagger:route PUT /scim/v2/Users/{id} scim replaceSCIMUser
+//
+// Update a user of the organization.
+//
+// Server administrators and users of other organizations cannot be changed.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) replaceUserHandler(c *contextmodel.ReqContext) response.Response {
+	resource := &User{}
+	if err := bind(c, resource); err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := s.replaceUser(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"], resource)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, updated)
+}
+
+// swagger:route PATCH /scim/v2/Users/{id} scim patchSCIMUser
+//
+// Apply add, replace and remove operations to a user of the organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) patchUserHandler(c *contextmodel.ReqContext) response.Response {
+	req := &PatchRequest{}
+	if err := bind(c, req); err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := s.patchUser(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"], req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, updated)
+}
+
+// swagger:route DELETE /scim/v2/Users/{id} scim deleteSCIMUser
+//
+// Remove a user from the organization.
+//
+// Users who are not a member of another organization are deleted.
+//
+// Responses:
+// 204: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteUserHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.deleteUser(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"]); err != nil {
+		return errorResponse(c, err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+// swagger:route GET /scim/v2/Groups scim listSCIMGroups
+//
+// List the teams of the organization.
+//
+// Supports the `filter`, `startIndex` and `count` query parameters.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listGroupsHandler(c *contextmodel.ReqContext) response.Response {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	groups, err := s.getGroups(c.Req.Context(), c.SignedInUser.GetOrgID(), "")
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	resources, err := filterResources(groups, q.filter)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, q.page(resources))
+}
+
+// swagger:route POST /scim/v2/Groups scim createSCIMGroup
+//
+// Create a team in the organization.
+//
+// Responses:
+// 201: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) createGroupHandler(c *contextmodel.ReqContext) response.Response {
+	resource := &Group{}
+	if err := bind(c, resource); err != nil {
+		return errorResponse(c, err)
+	}
+	created, err := s.createGroup(c.Req.Context(), c.SignedInUser.GetOrgID(), resource)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusCreated, created).SetHeader("Location", created.Meta.Location)
+}
+
+// swagger:route GET /scim/v2/Groups/{id} scim getSCIMGroup
+//
+// Get a team of the organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getGroupHandler(c *contextmodel.ReqContext) response.Response {
+	resource, err := s.getGroup(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"])
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, resource)
+}
+
+// swagger:route PUT /scim/v2/Groups/{id} scim replaceSCIMGroup
+//
+// Update the name and the members of a team of the organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) replaceGroupHandler(c *contextmodel.ReqContext) response.Response {
+	resource := &Group{}
+	if err := bind(c, resource); err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := s.replaceGroup(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"], resource)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, updated)
+}
+
+// swagger:route PATCH /scim/v2/Groups/{id} scim patchSCIMGroup
+//
+// Apply add, replace and remove operations to a team of the organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) patchGroupHandler(c *contextmodel.ReqContext) response.Response {
+	req := &PatchRequest{}
+	if err := bind(c, req); err != nil {
+		return errorResponse(c, err)
+	}
+	updated, err := s.patchGroup(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"], req)
+	if err != nil {
+		return errorResponse(c, err)
+	}
+	return jsonResponse(http.StatusOK, updated)
+}
+
+// swagger:route DELETE /scim/v2/Groups/{id} scim deleteSCIMGroup
+//
+// Delete a team of the organization.
+//
+// Responses:
+// 204: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteGroupHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.deleteGroup(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":id"]); err != nil {
+		return errorResponse(c, err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
@@ -0,0 +1,522 @@
+package scim
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// filter is a parsed SCIM filter, see https://www.rfc-editor.org/rfc/rfc7644#section-3.4.2.2.
+// Filters are evaluated against the JSON representation of a resource.
+type filter interface {
+	matches(resource map[string]any) bool
+}
+
+type andFilter struct {
+	left, right filter
+}
+
+func (f *andFilter) matches(resource map[string]any) bool {
+	return f.left.matches(resource) && f.right.matches(resource)
+}
+
+type orFilter struct {
+	left, right filter
+}
+
+func (f *orFilter) matches(resource map[string]any) bool {
+	return f.left.matches(resource) || f.right.matches(resource)
+}
+
+type notFilter struct {
+	filter filter
+}
+
+func (f *notFilter) matches(resource map[string]any) bool {
+	return !f.filter.matches(resource)
+}
+
+// attributeFilter compares the values of an attribute, like `userName eq "admin"`.
+type attributeFilter struct {
+	path      []string
+	op        string
+	value     any
+	caseExact bool
+}
+
+func (f *attributeFilter) matches(resource map[string]any) bool {
+	values := attributeValues(resource, f.path)
+	switch {
+	case f.op == "pr":
+		return hasPresentValue(values)
+	case f.value == nil && f.op == "eq":
+		return !hasPresentValue(values)
+	case f.value == nil && f.op == "ne":
+		return hasPresentValue(values)
+	case f.op == "ne":
+		for _, v := range values {
+			if compare(v, "eq", f.value, f.caseExact) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compare(v, f.op, f.value, f.caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuePathFilter matches resources with an element of a multi-valued attribute matching
+// the filter, like `emails[type eq "work" and value ew "@example.com"]`.
+type valuePathFilter struct {
+	path   []string
+	filter filter
+}
+
+func (f *valuePathFilter) matches(resource map[string]any) bool {
+	for _, v := range attributeValues(resource, f.path) {
+		if element, ok := v.(map[string]any); ok && f.filter.matches(element) {
+			return true
+		}
+	}
+	return false
+}
+
+// caseExactAttributes are compared case sensitively, all other string attributes are not.
+var caseExactAttributes = map[string]bool{
+	"id":            true,
+	"externalid":    true,
+	"members.value": true,
+	"groups.value":  true,
+}
+
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// parseFilter parses the filter of a list request.
+func parseFilter(s string) (filter, error) {
+	return parseFilterOf("", s)
+}
+
+// parseFilterOf parses a filter of the elements of the given multi-valued attribute.
+func parseFilterOf(parent, s string) (filter, error) {
+	tokens, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, invalidFilter("empty filter")
+	}
+	p := &filterParser{tokens: tokens, parent: parent}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t != nil {
+		return nil, invalidFilter("unexpected %q", t.text)
+	}
+	return f, nil
+}
+
+func invalidFilter(format string, args ...any) *Error {
+	return newError(http.StatusBadRequest, errTypeInvalidFilter, "invalid filter: "+format, args...)
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(s); {
+		switch c := s[i]; c {
+		case ' ', '\t', '\n', '\r':
+			i++
+		case '(':
+			tokens = append(tokens, filterToken{kind: tokenOpen, text: "("})
+			i++
+		case ')':
+			tokens = append(tokens, filterToken{kind: tokenClose, text: ")"})
+			i++
+		case '[':
+			tokens = append(tokens, filterToken{kind: tokenOpenBracket, text: "["})
+			i++
+		case ']':
+			tokens = append(tokens, filterToken{kind: tokenCloseBracket, text: "]"})
+			i++
+		case '"':
+			end := i + 1
+			for ; end < len(s) && s[end] != '"'; end++ {
+				if s[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(s) {
+				return nil, invalidFilter("unterminated string")
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &value); err != nil {
+				return nil, invalidFilter("invalid string %s", s[i:end+1])
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: value})
+			i = end + 1
+		default:
+			end := i
+			for ; end < len(s) && !strings.ContainsRune(" \t\n\r()[]\"", rune(s[end])); end++ {
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+	parent string
+}
+
+func (p *filterParser) peek() *filterToken {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *filterParser) next() *filterToken {
+	t := p.peek()
+	if t != nil {
+		p.pos++
+	}
+	return t
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t != nil && t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, text string) error {
+	t := p.next()
+	if t == nil {
+		return invalidFilter("expected %q at the end", text)
+	}
+	if t.kind != kind {
+		return invalidFilter("expected %q instead of %q", text, t.text)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filter, error) {
+	if p.peekKeyword("not") {
+		p.next()
+		if err := p.expect(tokenOpen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenClose, ")"); err != nil {
+			return nil, err
+		}
+		return &notFilter{filter: f}, nil
+	}
+
+	t := p.next()
+	if t == nil {
+		return nil, invalidFilter("unexpected end")
+	}
+	switch t.kind {
+	case tokenOpen:
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenClose, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case tokenWord:
+		return p.parseAttribute(t.text)
+	default:
+		return nil, invalidFilter("unexpected %q", t.text)
+	}
+}
+
+func (p *filterParser) parseAttribute(name string) (filter, error) {
+	path, err := attributePath(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t != nil && t.kind == tokenOpenBracket {
+		if p.parent != "" {
+			return nil, invalidFilter("nested attribute filters are not supported")
+		}
+		p.next()
+		inner := &filterParser{tokens: p.tokens, pos: p.pos, parent: strings.Join(path, ".")}
+		f, err := inner.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.pos = inner.pos
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return &valuePathFilter{path: path, filter: f}, nil
+	}
+
+	t := p.next()
+	if t == nil || t.kind != tokenWord {
+		return nil, invalidFilter("expected an operator after %q", name)
+	}
+	op := strings.ToLower(t.text)
+	fullPath := strings.Join(path, ".")
+	if p.parent != "" {
+		fullPath = p.parent + "." + fullPath
+	}
+	f := &attributeFilter{path: path, op: op, caseExact: caseExactAttributes[fullPath]}
+	if op == "pr" {
+		return f, nil
+	}
+	if !comparisonOperators[op] {
+		return nil, invalidFilter("unknown operator %q", t.text)
+	}
+
+	t = p.next()
+	if t == nil {
+		return nil, invalidFilter("expected a value after %q", op)
+	}
+	switch {
+	case t.kind == tokenString:
+		f.value = t.text
+	case t.kind != tokenWord:
+		return nil, invalidFilter("expected a value instead of %q", t.text)
+	case strings.EqualFold(t.text, "true"):
+		f.value = true
+	case strings.EqualFold(t.text, "false"):
+		f.value = false
+	case strings.EqualFold(t.text, "null"):
+		f.value = nil
+	default:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, invalidFilter("invalid value %q", t.text)
+		}
+		f.value = n
+	}
+
+	switch v := f.value.(type) {
+	case bool:
+		if op != "eq" && op != "ne" {
+			return nil, invalidFilter("operator %q cannot be used with booleans", op)
+		}
+	case nil:
+		if op != "eq" && op != "ne" {
+			return nil, invalidFilter("operator %q cannot be used with null", op)
+		}
+	case float64:
+		if op == "co" || op == "sw" || op == "ew" {
+			return nil, invalidFilter("operator %q cannot be used with %v", op, v)
+		}
+	}
+	return f, nil
+}
+
+// attributePath returns the lower case path of an attribute name without the schema prefix,
+// like ["name", "givenname"] for `urn:ietf:params:scim:schemas:core:2.0:User:name.givenName`.
+func attributePath(name string) ([]string, error) {
+	name = strings.ToLower(trimSchema(name))
+	path := strings.Split(name, ".")
+	for _, part := range path {
+		if part == "" {
+			return nil, invalidFilter("invalid attribute %q", name)
+		}
+	}
+	return path, nil
+}
+
+func trimSchema(name string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		prefix := schema + ":"
+		if len(name) > len(prefix) && strings.EqualFold(name[:len(prefix)], prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// attributeValues returns the values of an attribute of a resource, the values of multi-valued
+// attributes are flattened.
+func attributeValues(node any, path []string) []any {
+	if len(path) == 0 {
+		if list, ok := node.([]any); ok {
+			return list
+		}
+		return []any{node}
+	}
+	switch n := node.(type) {
+	case map[string]any:
+		key, ok := lookupKey(n, path[0])
+		if !ok {
+			return nil
+		}
+		return attributeValues(n[key], path[1:])
+	case []any:
+		var result []any
+		for _, element := range n {
+			result = append(result, attributeValues(element, path)...)
+		}
+		return result
+	}
+	return nil
+}
+
+// lookupKey returns the key of the attribute in the map, attribute names are case insensitive.
+func lookupKey(m map[string]any, name string) (string, bool) {
+	if _, ok := m[name]; ok {
+		return name, true
+	}
+	for key := range m {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func hasPresentValue(values []any) bool {
+	for _, v := range values {
+		switch value := v.(type) {
+		case nil:
+		case string:
+			if value != "" {
+				return true
+			}
+		case []any:
+			if len(value) > 0 {
+				return true
+			}
+		case map[string]any:
+			if len(value) > 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func compare(value any, op string, literal any, caseExact bool) bool {
+	// A complex attribute without a sub-attribute is compared by its value sub-attribute
+	if m, ok := value.(map[string]any); ok {
+		key, ok := lookupKey(m, "value")
+		if !ok {
+			return false
+		}
+		value = m[key]
+	}
+
+	switch l := literal.(type) {
+	case string:
+		s, ok := value.(string)
+		if !ok {
+			return false
+		}
+		if !caseExact {
+			s, l = strings.ToLower(s), strings.ToLower(l)
+		}
+		switch op {
+		case "eq":
+			return s == l
+		case "co":
+			return strings.Contains(s, l)
+		case "sw":
+			return strings.HasPrefix(s, l)
+		case "ew":
+			return strings.HasSuffix(s, l)
+		case "gt":
+			return s > l
+		case "ge":
+			return s >= l
+		case "lt":
+			return s < l
+		case "le":
+			return s <= l
+		}
+	case float64:
+		n, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return n == l
+		case "gt":
+			return n > l
+		case "ge":
+			return n >= l
+		case "lt":
+			return n < l
+		case "le":
+			return n <= l
+		}
+	case bool:
+		b, ok := value.(bool)
+		return ok && op == "eq" && b == l
+	}
+	return false
+}
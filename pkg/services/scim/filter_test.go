@@ -0,0 +1,92 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"id":          "AbC123",
+		"externalId":  "ext-1",
+		"userName":    "Alice",
+		"displayName": "Alice Smith",
+		"active":      true,
+		"name":        map[string]any{"formatted": "Alice Smith"},
+		"emails": []any{
+			map[string]any{"value": "alice@example.com", "type": "work", "primary": true},
+			map[string]any{"value": "alice@home.example", "type": "home"},
+		},
+		"meta": map[string]any{"lastModified": "2024-03-01T10:00:00Z"},
+	}
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: `userName eq "alice"`, matches: true},
+		{filter: `USERNAME Eq "ALICE"`, matches: true},
+		{filter: `userName eq "bob"`, matches: false},
+		{filter: `userName ne "bob"`, matches: true},
+		{filter: `id eq "abc123"`, matches: false},
+		{filter: `id eq "AbC123"`, matches: true},
+		{filter: `externalId eq "ext-1"`, matches: true},
+		{filter: `displayName co "smith"`, matches: true},
+		{filter: `displayName sw "Alice"`, matches: true},
+		{filter: `displayName ew "Alice"`, matches: false},
+		{filter: `name.formatted eq "alice smith"`, matches: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, matches: true},
+		{filter: `title pr`, matches: false},
+		{filter: `emails pr`, matches: true},
+		{filter: `emails eq "alice@home.example"`, matches: true},
+		{filter: `emails.value ew "@example.com"`, matches: true},
+		{filter: `emails[type eq "work" and value co "@example.com"]`, matches: true},
+		{filter: `emails[type eq "home" and value co "@example.com"]`, matches: false},
+		{filter: `active eq true`, matches: true},
+		{filter: `active eq false`, matches: false},
+		{filter: `title eq null`, matches: true},
+		{filter: `meta.lastModified gt "2024-01-01T00:00:00Z"`, matches: true},
+		{filter: `meta.lastModified lt "2024-01-01T00:00:00Z"`, matches: false},
+		{filter: `userName eq "bob" or userName eq "alice"`, matches: true},
+		{filter: `userName eq "alice" and active eq false`, matches: false},
+		{filter: `not (userName eq "bob")`, matches: true},
+		{filter: `(userName eq "bob" or userName eq "alice") and (active eq true)`, matches: true},
+		{filter: `userName eq "bob" or userName eq "alice" and active eq false`, matches: false},
+		{filter: `displayName eq "Alice \"Smith\""`, matches: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			require.Equal(t, tt.matches, f.matches(resource))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	tests := []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName is "alice"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`active gt true`,
+		`userName co 1`,
+		`(userName eq "alice"`,
+		`userName eq "alice")`,
+		`emails[type eq "work"`,
+		`not userName eq "alice"`,
+		`userName eq "alice" and`,
+	}
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := parseFilter(tt)
+			require.Error(t, err)
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			require.Equal(t, errTypeInvalidFilter, scimErr.ScimType)
+		})
+	}
+}
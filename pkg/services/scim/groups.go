@@ -0,0 +1,248 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+)
+
+func (s *Service) toGroup(t *orgTeam, externalID string, members []Reference) *Group {
+	created, updated := t.Created, t.Updated
+	if members == nil {
+		members = make([]Reference, 0)
+	}
+	return &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          t.UID,
+		ExternalID:  externalID,
+		DisplayName: t.Name,
+		Members:     members,
+		Meta: &Meta{
+			ResourceType: resourceTypeGroup,
+			Created:      &created,
+			LastModified: &updated,
+			Location:     s.location("Groups", t.UID),
+		},
+	}
+}
+
+// getGroups returns the teams of the organization. If uid is not empty, only the team with this UID is returned.
+func (s *Service) getGroups(ctx context.Context, orgID int64, uid string) ([]*Group, error) {
+	teams, err := s.store.listTeams(ctx, orgID, uid)
+	if err != nil || len(teams) == 0 {
+		return nil, err
+	}
+
+	var teamID int64
+	if uid != "" {
+		teamID = teams[0].ID
+	}
+	memberships, err := s.store.listMemberships(ctx, orgID, teamID, 0)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[int64][]Reference)
+	for _, m := range memberships {
+		members[m.TeamID] = append(members[m.TeamID], Reference{
+			Value:   m.UserUID,
+			Ref:     s.location("Users", m.UserUID),
+			Display: m.UserLogin,
+		})
+	}
+
+	externalIDs, err := s.store.getExternalIDs(ctx, orgID, resourceTypeGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*Group, 0, len(teams))
+	for _, t := range teams {
+		result = append(result, s.toGroup(t, externalIDs[t.ID], members[t.ID]))
+	}
+	return result, nil
+}
+
+func (s *Service) getGroup(ctx context.Context, orgID int64, uid string) (*Group, error) {
+	groups, err := s.getGroups(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, errNotFound(resourceTypeGroup, uid)
+	}
+	return groups[0], nil
+}
+
+func (s *Service) getOrgTeam(ctx context.Context, orgID int64, uid string) (*orgTeam, error) {
+	teams, err := s.store.listTeams(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(teams) == 0 {
+		return nil, errNotFound(resourceTypeGroup, uid)
+	}
+	return teams[0], nil
+}
+
+// resolveMembers returns the IDs of the members, members have to be users of the organization.
+func (s *Service) resolveMembers(ctx context.Context, orgID int64, members []Reference) (map[int64]bool, error) {
+	ids := make(map[int64]bool, len(members))
+	if len(members) == 0 {
+		return ids, nil
+	}
+
+	users, err := s.store.listUsers(ctx, orgID, "")
+	if err != nil {
+		return nil, err
+	}
+	byUID := make(map[string]int64, len(users))
+	for _, u := range users {
+		byUID[u.UID] = u.ID
+	}
+	for _, m := range members {
+		id, ok := byUID[m.Value]
+		if !ok {
+			return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "member %q is not a user of the organization", m.Value)
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+func (s *Service) createGroup(ctx context.Context, orgID int64, resource *Group) (*Group, error) {
+	if resource.DisplayName == "" {
+		return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "displayName is required")
+	}
+	members, err := s.resolveMembers(ctx, orgID, resource.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.teamService.CreateTeam(ctx, resource.DisplayName, "", orgID)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return nil, newError(http.StatusConflict, errTypeUniqueness, "group %q already exists", resource.DisplayName)
+		}
+		return nil, err
+	}
+	if err := s.setMembers(ctx, orgID, t.ID, members); err != nil {
+		return nil, err
+	}
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeGroup, t.ID, resource.ExternalID); err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("Provisioned team", "orgId", orgID, "teamId", t.ID)
+	return s.getGroup(ctx, orgID, t.UID)
+}
+
+// replaceGroup updates the name and the members of the team.
+func (s *Service) replaceGroup(ctx context.Context, orgID int64, uid string, resource *Group) (*Group, error) {
+	current, err := s.getOrgTeam(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if resource.DisplayName == "" {
+		return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "displayName is required")
+	}
+	members, err := s.resolveMembers(ctx, orgID, resource.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if resource.DisplayName != current.Name {
+		if err := s.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{
+			ID:    current.ID,
+			Name:  resource.DisplayName,
+			Email: current.Email,
+			OrgID: orgID,
+		}); err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				return nil, newError(http.StatusConflict, errTypeUniqueness, "group %q already exists", resource.DisplayName)
+			}
+			return nil, err
+		}
+	}
+	if err := s.setMembers(ctx, orgID, current.ID, members); err != nil {
+		return nil, err
+	}
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeGroup, current.ID, resource.ExternalID); err != nil {
+		return nil, err
+	}
+	return s.getGroup(ctx, orgID, uid)
+}
+
+// setMembers adds and removes members of the team. Members keep their team permission.
+func (s *Service) setMembers(ctx context.Context, orgID, teamID int64, members map[int64]bool) error {
+	memberships, err := s.store.listMemberships(ctx, orgID, teamID, 0)
+	if err != nil {
+		return err
+	}
+	current := make(map[int64]bool, len(memberships))
+	for _, m := range memberships {
+		current[m.UserID] = true
+	}
+
+	teamIDString := strconv.FormatInt(teamID, 10)
+	for userID := range members {
+		if current[userID] {
+			continue
+		}
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, teamIDString, team.PermissionTypeMember.String()); err != nil {
+			return err
+		}
+	}
+	for userID := range current {
+		if members[userID] {
+			continue
+		}
+		if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, teamIDString, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) patchGroup(ctx context.Context, orgID int64, uid string, req *PatchRequest) (*Group, error) {
+	current, err := s.getGroup(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toResourceMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPatch(m, req.Operations); err != nil {
+		return nil, err
+	}
+
+	patched := &Group{}
+	if err := fromResourceMap(m, patched); err != nil {
+		return nil, err
+	}
+	return s.replaceGroup(ctx, orgID, uid, patched)
+}
+
+func (s *Service) deleteGroup(ctx context.Context, orgID int64, uid string) error {
+	current, err := s.getOrgTeam(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	if err := s.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: orgID, ID: current.ID}); err != nil {
+		return err
+	}
+	// Clear associated team assignments, managed role and permissions
+	if err := s.accessControlService.DeleteTeamPermissions(ctx, orgID, current.ID); err != nil {
+		return err
+	}
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeGroup, current.ID, ""); err != nil {
+		return err
+	}
+
+	s.log.FromContext(ctx).Info("Deprovisioned team", "orgId", orgID, "teamId", current.ID)
+	return nil
+}
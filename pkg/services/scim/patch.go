@@ -0,0 +1,340 @@
+package scim
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// patchPath is the parsed path of a PATCH operation, see https://www.rfc-editor.org/rfc/rfc7644#section-3.5.2.
+type patchPath struct {
+	raw string
+	// attribute is the lower case name of the attribute.
+	attribute string
+	// filter selects elements of a multi-valued attribute.
+	filter filter
+	// subAttribute is the lower case name of a sub-attribute of the attribute or of the filtered elements.
+	subAttribute string
+	// template is the element created by add and replace operations if no element matches a
+	// filter of the form `attribute eq "value"`, like `emails[type eq "work"].value`.
+	template map[string]any
+}
+
+func invalidPath(format string, args ...any) *Error {
+	return newError(http.StatusBadRequest, errTypeInvalidPath, format, args...)
+}
+
+func parsePatchPath(raw string) (*patchPath, error) {
+	p := &patchPath{raw: raw}
+	name := strings.TrimSpace(trimSchema(raw))
+
+	rest := ""
+	if start := strings.IndexByte(name, '['); start >= 0 {
+		end := strings.LastIndexByte(name, ']')
+		if end < start {
+			return nil, invalidPath("invalid path %q", raw)
+		}
+		p.attribute = strings.ToLower(name[:start])
+		f, err := parseFilterOf(p.attribute, name[start+1:end])
+		if err != nil {
+			return nil, invalidPath("invalid path %q: %s", raw, err)
+		}
+		p.filter = f
+		if af, ok := f.(*attributeFilter); ok && af.op == "eq" && len(af.path) == 1 && af.value != nil {
+			p.template = map[string]any{af.path[0]: af.value}
+		}
+		rest = name[end+1:]
+		if rest != "" && !strings.HasPrefix(rest, ".") {
+			return nil, invalidPath("invalid path %q", raw)
+		}
+		rest = strings.TrimPrefix(rest, ".")
+	} else {
+		attribute, sub, found := strings.Cut(name, ".")
+		if found && sub == "" {
+			return nil, invalidPath("invalid path %q", raw)
+		}
+		p.attribute = strings.ToLower(attribute)
+		rest = sub
+	}
+
+	if strings.Contains(rest, ".") {
+		return nil, invalidPath("invalid path %q", raw)
+	}
+	p.subAttribute = strings.ToLower(rest)
+	if p.attribute == "" {
+		return nil, invalidPath("invalid path %q", raw)
+	}
+	return p, nil
+}
+
+// applyPatch applies PATCH operations to the JSON representation of a resource.
+func applyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, op := range operations {
+		if err := applyPatchOperation(resource, op); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applyPatchOperation(resource map[string]any, op PatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return newError(http.StatusBadRequest, errTypeInvalidSyntax, "unsupported operation %q", op.Op)
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return newError(http.StatusBadRequest, errTypeNoTarget, "remove operations require a path")
+		}
+		values, ok := op.Value.(map[string]any)
+		if !ok {
+			return newError(http.StatusBadRequest, errTypeInvalidValue, "%s operations without a path require an object", op.Op)
+		}
+		for key, value := range values {
+			if err := applyPatchOperation(resource, PatchOperation{Op: op.Op, Path: key, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "add":
+		if op.Value == nil {
+			return newError(http.StatusBadRequest, errTypeInvalidValue, "add operations require a value")
+		}
+		return path.add(resource, op.Value)
+	case "replace":
+		return path.replace(resource, op.Value)
+	default:
+		return path.remove(resource, op.Value)
+	}
+}
+
+// key returns the key of the attribute in the resource.
+func (p *patchPath) key(resource map[string]any) string {
+	if key, ok := lookupKey(resource, p.attribute); ok {
+		return key
+	}
+	return p.attribute
+}
+
+func (p *patchPath) add(resource map[string]any, value any) error {
+	key := p.key(resource)
+	if p.filter != nil {
+		return p.setMatching(resource, key, value, false)
+	}
+	if p.subAttribute != "" {
+		return setSubAttribute(resource, key, p.subAttribute, value)
+	}
+
+	switch current := resource[key].(type) {
+	case []any:
+		resource[key] = appendMissing(current, value)
+	case map[string]any:
+		values, ok := value.(map[string]any)
+		if !ok {
+			return newError(http.StatusBadRequest, errTypeInvalidValue, "%q requires an object", p.raw)
+		}
+		merge(current, values)
+	default:
+		resource[key] = value
+	}
+	return nil
+}
+
+func (p *patchPath) replace(resource map[string]any, value any) error {
+	key := p.key(resource)
+	if p.filter != nil {
+		return p.setMatching(resource, key, value, true)
+	}
+	if p.subAttribute != "" {
+		return setSubAttribute(resource, key, p.subAttribute, value)
+	}
+	resource[key] = value
+	return nil
+}
+
+func (p *patchPath) remove(resource map[string]any, value any) error {
+	key, ok := lookupKey(resource, p.attribute)
+	if !ok {
+		return nil
+	}
+
+	list, isList := resource[key].([]any)
+	switch {
+	case p.filter != nil:
+		if !isList {
+			return invalidPath("%q is not multi-valued", p.attribute)
+		}
+		kept := make([]any, 0, len(list))
+		for _, element := range list {
+			m, ok := element.(map[string]any)
+			if !ok || !p.filter.matches(m) {
+				kept = append(kept, element)
+				continue
+			}
+			if p.subAttribute != "" {
+				if k, ok := lookupKey(m, p.subAttribute); ok {
+					delete(m, k)
+				}
+				kept = append(kept, m)
+			}
+		}
+		resource[key] = kept
+	case p.subAttribute != "":
+		elements := list
+		if !isList {
+			elements = []any{resource[key]}
+		}
+		for _, element := range elements {
+			if m, ok := element.(map[string]any); ok {
+				if k, ok := lookupKey(m, p.subAttribute); ok {
+					delete(m, k)
+				}
+			}
+		}
+	case isList && value != nil:
+		// Some identity providers remove elements by value instead of with a filter,
+		// like {"op": "remove", "path": "members", "value": [{"value": "id"}]}
+		removed := toList(value)
+		kept := make([]any, 0, len(list))
+		for _, element := range list {
+			if !containsElement(removed, element) {
+				kept = append(kept, element)
+			}
+		}
+		resource[key] = kept
+	default:
+		delete(resource, key)
+	}
+	return nil
+}
+
+// setMatching sets the elements of a multi-valued attribute matching the filter, or their sub-attribute.
+func (p *patchPath) setMatching(resource map[string]any, key string, value any, replace bool) error {
+	var list []any
+	switch current := resource[key].(type) {
+	case nil:
+	case []any:
+		list = current
+	default:
+		return invalidPath("%q is not multi-valued", p.attribute)
+	}
+
+	values, isMap := value.(map[string]any)
+	if p.subAttribute == "" && !isMap {
+		return newError(http.StatusBadRequest, errTypeInvalidValue, "%q requires an object", p.raw)
+	}
+
+	matched := false
+	for i, element := range list {
+		m, ok := element.(map[string]any)
+		if !ok || !p.filter.matches(m) {
+			continue
+		}
+		matched = true
+		switch {
+		case p.subAttribute != "":
+			m[subKey(m, p.subAttribute)] = value
+		case replace:
+			list[i] = values
+		default:
+			merge(m, values)
+		}
+	}
+
+	if !matched {
+		if p.template == nil {
+			return newError(http.StatusBadRequest, errTypeNoTarget, "no value matches %q", p.raw)
+		}
+		element := make(map[string]any, len(p.template)+1)
+		for k, v := range p.template {
+			element[k] = v
+		}
+		if p.subAttribute != "" {
+			element[p.subAttribute] = value
+		} else {
+			merge(element, values)
+		}
+		list = append(list, element)
+	}
+	resource[key] = list
+	return nil
+}
+
+func setSubAttribute(resource map[string]any, key, sub string, value any) error {
+	switch current := resource[key].(type) {
+	case nil:
+		resource[key] = map[string]any{sub: value}
+	case map[string]any:
+		current[subKey(current, sub)] = value
+	case []any:
+		for _, element := range current {
+			if m, ok := element.(map[string]any); ok {
+				m[subKey(m, sub)] = value
+			}
+		}
+	default:
+		return invalidPath("%q has no sub-attributes", key)
+	}
+	return nil
+}
+
+func subKey(m map[string]any, name string) string {
+	if key, ok := lookupKey(m, name); ok {
+		return key
+	}
+	return name
+}
+
+func merge(dst, src map[string]any) {
+	for k, v := range src {
+		dst[subKey(dst, k)] = v
+	}
+}
+
+func toList(value any) []any {
+	if list, ok := value.([]any); ok {
+		return list
+	}
+	return []any{value}
+}
+
+func appendMissing(list []any, value any) []any {
+	for _, element := range toList(value) {
+		if !containsElement(list, element) {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+// containsElement reports whether the list contains the element, complex elements with
+// the same value sub-attribute are considered equal.
+func containsElement(list []any, element any) bool {
+	for _, e := range list {
+		if sameElement(e, element) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameElement(a, b any) bool {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if aok && bok {
+		ak, aHasValue := lookupKey(am, "value")
+		bk, bHasValue := lookupKey(bm, "value")
+		if aHasValue && bHasValue {
+			return reflect.DeepEqual(am[ak], bm[bk])
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,193 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch_User(t *testing.T) {
+	newUser := func() *User {
+		active := true
+		return &User{
+			Schemas:     []string{SchemaUser},
+			ID:          "abc",
+			UserName:    "alice",
+			DisplayName: "Alice Smith",
+			Name:        &Name{Formatted: "Alice Smith"},
+			Emails:      []Email{{Value: "alice@example.com", Type: "work", Primary: true}},
+			Active:      &active,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		operations []PatchOperation
+		expected   func(u *User)
+	}{
+		{
+			name:       "replace without path",
+			operations: []PatchOperation{{Op: "Replace", Value: map[string]any{"active": false, "displayName": "Alice Jones"}}},
+			expected: func(u *User) {
+				active := false
+				u.Active = &active
+				u.DisplayName = "Alice Jones"
+			},
+		},
+		{
+			name:       "replace attribute",
+			operations: []PatchOperation{{Op: "replace", Path: "userName", Value: "alice.smith"}},
+			expected:   func(u *User) { u.UserName = "alice.smith" },
+		},
+		{
+			name:       "replace sub-attribute",
+			operations: []PatchOperation{{Op: "replace", Path: "name.givenName", Value: "Alicia"}},
+			expected:   func(u *User) { u.Name.GivenName = "Alicia" },
+		},
+		{
+			name:       "replace filtered sub-attribute",
+			operations: []PatchOperation{{Op: "replace", Path: `emails[type eq "work"].value`, Value: "alice@example.org"}},
+			expected:   func(u *User) { u.Emails[0].Value = "alice@example.org" },
+		},
+		{
+			name:       "add filtered sub-attribute without match",
+			operations: []PatchOperation{{Op: "add", Path: `emails[type eq "home"].value`, Value: "alice@home.example"}},
+			expected: func(u *User) {
+				u.Emails = append(u.Emails, Email{Value: "alice@home.example", Type: "home"})
+			},
+		},
+		{
+			name:       "add to multi-valued attribute",
+			operations: []PatchOperation{{Op: "add", Path: "emails", Value: []any{map[string]any{"value": "alice@home.example", "type": "home"}}}},
+			expected: func(u *User) {
+				u.Emails = append(u.Emails, Email{Value: "alice@home.example", Type: "home"})
+			},
+		},
+		{
+			name:       "remove attribute",
+			operations: []PatchOperation{{Op: "remove", Path: "displayName"}},
+			expected:   func(u *User) { u.DisplayName = "" },
+		},
+		{
+			name:       "remove filtered elements",
+			operations: []PatchOperation{{Op: "remove", Path: `emails[type eq "work"]`}},
+			expected:   func(u *User) { u.Emails = []Email{} },
+		},
+		{
+			name:       "schema prefix",
+			operations: []PatchOperation{{Op: "replace", Path: "urn:ietf:params:scim:schemas:core:2.0:User:active", Value: false}},
+			expected: func(u *User) {
+				active := false
+				u.Active = &active
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := toResourceMap(newUser())
+			require.NoError(t, err)
+			require.NoError(t, applyPatch(m, tt.operations))
+
+			patched := &User{}
+			require.NoError(t, fromResourceMap(m, patched))
+			expected := newUser()
+			tt.expected(expected)
+			require.Equal(t, expected, patched)
+		})
+	}
+}
+
+func TestApplyPatch_GroupMembers(t *testing.T) {
+	newGroup := func() *Group {
+		return &Group{
+			Schemas:     []string{SchemaGroup},
+			ID:          "team",
+			DisplayName: "Team",
+			Members:     []Reference{{Value: "a", Display: "alice"}, {Value: "b", Display: "bob"}},
+		}
+	}
+	members := func(g *Group) []string {
+		values := make([]string, 0, len(g.Members))
+		for _, m := range g.Members {
+			values = append(values, m.Value)
+		}
+		return values
+	}
+
+	tests := []struct {
+		name       string
+		operations []PatchOperation
+		expected   []string
+	}{
+		{
+			name:       "add members",
+			operations: []PatchOperation{{Op: "add", Path: "members", Value: []any{map[string]any{"value": "b"}, map[string]any{"value": "c"}}}},
+			expected:   []string{"a", "b", "c"},
+		},
+		{
+			name:       "add members without path",
+			operations: []PatchOperation{{Op: "add", Value: map[string]any{"members": []any{map[string]any{"value": "c"}}}}},
+			expected:   []string{"a", "b", "c"},
+		},
+		{
+			name:       "remove member with filter",
+			operations: []PatchOperation{{Op: "remove", Path: `members[value eq "a"]`}},
+			expected:   []string{"b"},
+		},
+		{
+			name:       "member values are case sensitive",
+			operations: []PatchOperation{{Op: "remove", Path: `members[value eq "A"]`}},
+			expected:   []string{"a", "b"},
+		},
+		{
+			name:       "remove member by value",
+			operations: []PatchOperation{{Op: "Remove", Path: "members", Value: []any{map[string]any{"value": "b"}}}},
+			expected:   []string{"a"},
+		},
+		{
+			name:       "remove all members",
+			operations: []PatchOperation{{Op: "remove", Path: "members"}},
+			expected:   []string{},
+		},
+		{
+			name:       "replace members",
+			operations: []PatchOperation{{Op: "replace", Path: "members", Value: []any{map[string]any{"value": "c"}}}},
+			expected:   []string{"c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := toResourceMap(newGroup())
+			require.NoError(t, err)
+			require.NoError(t, applyPatch(m, tt.operations))
+
+			patched := &Group{}
+			require.NoError(t, fromResourceMap(m, patched))
+			require.Equal(t, tt.expected, members(patched))
+		})
+	}
+}
+
+func TestApplyPatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation PatchOperation
+		scimType  string
+	}{
+		{name: "unknown operation", operation: PatchOperation{Op: "move", Path: "userName"}, scimType: errTypeInvalidSyntax},
+		{name: "remove without path", operation: PatchOperation{Op: "remove"}, scimType: errTypeNoTarget},
+		{name: "add without value", operation: PatchOperation{Op: "add", Path: "userName"}, scimType: errTypeInvalidValue},
+		{name: "invalid path", operation: PatchOperation{Op: "replace", Path: "name.", Value: "x"}, scimType: errTypeInvalidPath},
+		{name: "invalid filter", operation: PatchOperation{Op: "replace", Path: `emails[type]`, Value: "x"}, scimType: errTypeInvalidPath},
+		{name: "no target", operation: PatchOperation{Op: "replace", Path: `emails[value co "x"].type`, Value: "x"}, scimType: errTypeNoTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := map[string]any{"userName": "alice", "emails": []any{}}
+			err := applyPatch(m, []PatchOperation{tt.operation})
+			var scimErr *Error
+			require.ErrorAs(t, err, &scimErr)
+			require.Equal(t, tt.scimType, scimErr.ScimType)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package scim implements a SCIM 2.0 server (RFC 7643, RFC 7644) to provision the users
+// and teams of an organization from an identity provider.
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	cfg                    *setting.Cfg
+	store                  *store
+	log                    log.Logger
+	accessControlService   accesscontrol.Service
+	userService            user.Service
+	orgService             org.Service
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	tokenService           auth.UserTokenService
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl,
+	accessControlService accesscontrol.Service, userService user.Service, orgService org.Service, teamService team.Service,
+	teamPermissionsService accesscontrol.TeamPermissionsService, tokenService auth.UserTokenService) (*Service, error) {
+	s := &Service{
+		cfg:                    cfg,
+		store:                  &store{db: sqlStore},
+		log:                    log.New("scim"),
+		accessControlService:   accessControlService,
+		userService:            userService,
+		orgService:             orgService,
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		tokenService:           tokenService,
+	}
+
+	if !cfg.SCIM.Enabled {
+		return s, nil
+	}
+	if err := declareFixedRoles(accessControlService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints(routeRegister, accessControl)
+	return s, nil
+}
+
+// location returns the URL of a resource.
+func (s *Service) location(endpoint, id string) string {
+	return s.cfg.AppURL + "api/scim/v2/" + endpoint + "/" + id
+}
+
+// checkManaged returns an error if the attributes of the user cannot be changed by the
+// organization. Server administrators and users of other organizations are not managed
+// by the identity provider of a single organization.
+func (s *Service) checkManaged(ctx context.Context, u *orgUser) error {
+	if u.IsAdmin {
+		return newError(http.StatusForbidden, "", "user %q is a server administrator and cannot be changed with SCIM", u.Login)
+	}
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: u.ID})
+	if err != nil {
+		return err
+	}
+	if len(orgs) > 1 {
+		return newError(http.StatusForbidden, "", "user %q is a member of other organizations and cannot be changed with SCIM", u.Login)
+	}
+	return nil
+}
+
+// toResourceMap returns the JSON representation of a resource, used to filter and patch resources.
+func toResourceMap(resource any) (map[string]any, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func fromResourceMap(m map[string]any, resource any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, resource); err != nil {
+		return newError(http.StatusBadRequest, errTypeInvalidValue, "invalid value: %s", err)
+	}
+	return nil
+}
+
+// filterResources returns the resources matching the filter.
+func filterResources[T any](resources []T, f filter) ([]any, error) {
+	result := make([]any, 0, len(resources))
+	for _, r := range resources {
+		if f != nil {
+			m, err := toResourceMap(r)
+			if err != nil {
+				return nil, err
+			}
+			if !f.matches(m) {
+				continue
+			}
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
@@ -0,0 +1,155 @@
+package scim
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// scimResource stores the external ID the identity provider assigned to a user or team.
+type scimResource struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	ResourceType string    `xorm:"resource_type"`
+	ResourceID   int64     `xorm:"resource_id"`
+	ExternalID   string    `xorm:"external_id"`
+	Created      time.Time `xorm:"created"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (scimResource) TableName() string {
+	return "scim_resource"
+}
+
+type orgUser struct {
+	ID         int64     `xorm:"id"`
+	UID        string    `xorm:"uid"`
+	Login      string    `xorm:"login"`
+	Email      string    `xorm:"email"`
+	Name       string    `xorm:"name"`
+	IsDisabled bool      `xorm:"is_disabled"`
+	IsAdmin    bool      `xorm:"is_admin"`
+	Created    time.Time `xorm:"created"`
+	Updated    time.Time `xorm:"updated"`
+}
+
+type orgTeam struct {
+	ID      int64     `xorm:"id"`
+	UID     string    `xorm:"uid"`
+	Name    string    `xorm:"name"`
+	Email   string    `xorm:"email"`
+	Created time.Time `xorm:"created"`
+	Updated time.Time `xorm:"updated"`
+}
+
+type teamMembership struct {
+	TeamID    int64  `xorm:"team_id"`
+	TeamUID   string `xorm:"team_uid"`
+	TeamName  string `xorm:"team_name"`
+	UserID    int64  `xorm:"user_id"`
+	UserUID   string `xorm:"user_uid"`
+	UserLogin string `xorm:"user_login"`
+}
+
+type store struct {
+	db db.DB
+}
+
+// listUsers returns the users of the organization, without service accounts. If uid is
+// not empty, only the user with this UID is returned.
+func (s *store) listUsers(ctx context.Context, orgID int64, uid string) ([]*orgUser, error) {
+	users := make([]*orgUser, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.db.GetDialect()
+		sql := `SELECT u.id, u.uid, u.login, u.email, u.name, u.is_disabled, u.is_admin, u.created, u.updated
+			FROM org_user ou
+			INNER JOIN ` + dialect.Quote("user") + ` u ON u.id = ou.user_id
+			WHERE ou.org_id = ? AND u.is_service_account = ?`
+		args := []any{orgID, dialect.BooleanStr(false)}
+		if uid != "" {
+			sql += " AND u.uid = ?"
+			args = append(args, uid)
+		}
+		return sess.SQL(sql+" ORDER BY u.id", args...).Find(&users)
+	})
+	return users, err
+}
+
+// listTeams returns the teams of the organization. If uid is not empty, only the team
+// with this UID is returned.
+func (s *store) listTeams(ctx context.Context, orgID int64, uid string) ([]*orgTeam, error) {
+	teams := make([]*orgTeam, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "SELECT id, uid, name, email, created, updated FROM team WHERE org_id = ?"
+		args := []any{orgID}
+		if uid != "" {
+			sql += " AND uid = ?"
+			args = append(args, uid)
+		}
+		return sess.SQL(sql+" ORDER BY id", args...).Find(&teams)
+	})
+	return teams, err
+}
+
+// listMemberships returns the team memberships of the organization, optionally only of a
+// team or of a user.
+func (s *store) listMemberships(ctx context.Context, orgID, teamID, userID int64) ([]*teamMembership, error) {
+	memberships := make([]*teamMembership, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := `SELECT t.id AS team_id, t.uid AS team_uid, t.name AS team_name, u.id AS user_id, u.uid AS user_uid, u.login AS user_login
+			FROM team_member tm
+			INNER JOIN team t ON t.id = tm.team_id
+			INNER JOIN ` + s.db.GetDialect().Quote("user") + ` u ON u.id = tm.user_id
+			WHERE tm.org_id = ?`
+		args := []any{orgID}
+		if teamID != 0 {
+			sql += " AND tm.team_id = ?"
+			args = append(args, teamID)
+		}
+		if userID != 0 {
+			sql += " AND tm.user_id = ?"
+			args = append(args, userID)
+		}
+		return sess.SQL(sql+" ORDER BY t.name, u.login", args...).Find(&memberships)
+	})
+	return memberships, err
+}
+
+// getExternalIDs returns the external IDs of the resources of a type by resource ID.
+func (s *store) getExternalIDs(ctx context.Context, orgID int64, resourceType string) (map[int64]string, error) {
+	ids := make(map[int64]string)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var resources []*scimResource
+		if err := sess.Where("org_id = ? AND resource_type = ?", orgID, resourceType).Find(&resources); err != nil {
+			return err
+		}
+		for _, r := range resources {
+			ids[r.ResourceID] = r.ExternalID
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// setExternalID replaces the external ID of a resource, an empty external ID deletes it.
+func (s *store) setExternalID(ctx context.Context, orgID int64, resourceType string, resourceID int64, externalID string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND resource_type = ? AND resource_id = ?", orgID, resourceType, resourceID).Delete(&scimResource{}); err != nil {
+			return err
+		}
+		if externalID == "" {
+			return nil
+		}
+		now := time.Now()
+		_, err := sess.Insert(&scimResource{
+			OrgID:        orgID,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			ExternalID:   externalID,
+			Created:      now,
+			Updated:      now,
+		})
+		return err
+	})
+}
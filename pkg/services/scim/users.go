@@ -0,0 +1,293 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func (s *Service) toUser(u *orgUser, externalID string, groups []Reference) *User {
+	active := !u.IsDisabled
+	created, updated := u.Created, u.Updated
+	resource := &User{
+		Schemas:     []string{SchemaUser},
+		ID:          u.UID,
+		ExternalID:  externalID,
+		UserName:    u.Login,
+		DisplayName: u.Name,
+		Active:      &active,
+		Groups:      groups,
+		Meta: &Meta{
+			ResourceType: resourceTypeUser,
+			Created:      &created,
+			LastModified: &updated,
+			Location:     s.location("Users", u.UID),
+		},
+	}
+	if u.Name != "" {
+		resource.Name = &Name{Formatted: u.Name}
+	}
+	if u.Email != "" {
+		resource.Emails = []Email{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	return resource
+}
+
+// getUsers returns the users of the organization. If uid is not empty, only the user with this UID is returned.
+func (s *Service) getUsers(ctx context.Context, orgID int64, uid string) ([]*User, error) {
+	users, err := s.store.listUsers(ctx, orgID, uid)
+	if err != nil || len(users) == 0 {
+		return nil, err
+	}
+
+	var userID int64
+	if uid != "" {
+		userID = users[0].ID
+	}
+	memberships, err := s.store.listMemberships(ctx, orgID, 0, userID)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[int64][]Reference)
+	for _, m := range memberships {
+		groups[m.UserID] = append(groups[m.UserID], Reference{
+			Value:   m.TeamUID,
+			Ref:     s.location("Groups", m.TeamUID),
+			Display: m.TeamName,
+		})
+	}
+
+	externalIDs, err := s.store.getExternalIDs(ctx, orgID, resourceTypeUser)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*User, 0, len(users))
+	for _, u := range users {
+		result = append(result, s.toUser(u, externalIDs[u.ID], groups[u.ID]))
+	}
+	return result, nil
+}
+
+func (s *Service) getUser(ctx context.Context, orgID int64, uid string) (*User, error) {
+	users, err := s.getUsers(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errNotFound(resourceTypeUser, uid)
+	}
+	return users[0], nil
+}
+
+func (s *Service) getOrgUser(ctx context.Context, orgID int64, uid string) (*orgUser, error) {
+	users, err := s.store.listUsers(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, errNotFound(resourceTypeUser, uid)
+	}
+	return users[0], nil
+}
+
+// createUser creates a user in the organization. Existing users of other organizations are
+// added to the organization, without changing their attributes.
+func (s *Service) createUser(ctx context.Context, orgID int64, resource *User) (*User, error) {
+	if resource.UserName == "" {
+		return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "userName is required")
+	}
+	email := resource.primaryEmail()
+
+	existing, err := s.findUser(ctx, resource.UserName, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID int64
+	var uid string
+	if existing != nil {
+		if existing.IsServiceAccount {
+			return nil, newError(http.StatusConflict, errTypeUniqueness, "userName %q is used by a service account", resource.UserName)
+		}
+		members, err := s.store.listUsers(ctx, orgID, existing.UID)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) > 0 {
+			return nil, newError(http.StatusConflict, errTypeUniqueness, "user %q already exists", resource.UserName)
+		}
+		userID, uid = existing.ID, existing.UID
+	} else {
+		created, err := s.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        resource.UserName,
+			Email:        email,
+			Name:         resource.fullName(),
+			IsDisabled:   resource.Active != nil && !*resource.Active,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			if errors.Is(err, user.ErrUserAlreadyExists) {
+				return nil, newError(http.StatusConflict, errTypeUniqueness, "user %q already exists", resource.UserName)
+			}
+			return nil, err
+		}
+		userID, uid = created.ID, created.UID
+	}
+
+	if err := s.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{
+		OrgID:  orgID,
+		UserID: userID,
+		Role:   org.RoleType(s.cfg.SCIM.DefaultOrgRole),
+	}); err != nil {
+		return nil, err
+	}
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeUser, userID, resource.ExternalID); err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("Provisioned user", "orgId", orgID, "userId", userID, "existing", existing != nil)
+	return s.getUser(ctx, orgID, uid)
+}
+
+// findUser returns the user with the login or email, or nil.
+func (s *Service) findUser(ctx context.Context, login, email string) (*user.User, error) {
+	u, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+	if err == nil || !errors.Is(err, user.ErrUserNotFound) {
+		return u, err
+	}
+	if email == "" {
+		return nil, nil
+	}
+	u, err = s.userService.GetByEmail(ctx, &user.GetUserByEmailQuery{Email: email})
+	if errors.Is(err, user.ErrUserNotFound) {
+		return nil, nil
+	}
+	return u, err
+}
+
+// replaceUser updates the user with the attributes of the resource. Attributes missing in
+// the resource are not changed.
+func (s *Service) replaceUser(ctx context.Context, orgID int64, uid string, resource *User) (*User, error) {
+	current, err := s.getOrgUser(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	if resource.UserName == "" {
+		return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "userName is required")
+	}
+
+	cmd := &user.UpdateUserCommand{UserID: current.ID}
+	changed := false
+	if !strings.EqualFold(resource.UserName, current.Login) {
+		cmd.Login = resource.UserName
+		changed = true
+	}
+	if email := resource.primaryEmail(); email != "" && !strings.EqualFold(email, current.Email) {
+		cmd.Email = email
+		changed = true
+	}
+	if name := resource.fullName(); name != "" && name != current.Name {
+		cmd.Name = name
+		changed = true
+	}
+	if resource.Active != nil && *resource.Active == current.IsDisabled {
+		disabled := !*resource.Active
+		cmd.IsDisabled = &disabled
+		changed = true
+	}
+
+	if changed {
+		if err := s.checkManaged(ctx, current); err != nil {
+			return nil, err
+		}
+		if err := s.userService.Update(ctx, cmd); err != nil {
+			if errors.Is(err, user.ErrEmailConflict) || errors.Is(err, user.ErrUserAlreadyExists) || errors.Is(err, user.ErrCaseInsensitive) {
+				return nil, newError(http.StatusConflict, errTypeUniqueness, "userName or email of %q is already used", resource.UserName)
+			}
+			return nil, err
+		}
+		// Deprovisioned users are signed out
+		if cmd.IsDisabled != nil && *cmd.IsDisabled {
+			if err := s.tokenService.RevokeAllUserTokens(ctx, current.ID); err != nil {
+				return nil, err
+			}
+		}
+		s.log.FromContext(ctx).Info("Updated provisioned user", "orgId", orgID, "userId", current.ID)
+	}
+
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeUser, current.ID, resource.ExternalID); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, orgID, uid)
+}
+
+func (s *Service) patchUser(ctx context.Context, orgID int64, uid string, req *PatchRequest) (*User, error) {
+	current, err := s.getUser(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	m, err := toResourceMap(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPatch(m, req.Operations); err != nil {
+		return nil, err
+	}
+	// Some identity providers send booleans as strings
+	if key, ok := lookupKey(m, "active"); ok {
+		if v, ok := m[key].(string); ok {
+			active, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, newError(http.StatusBadRequest, errTypeInvalidValue, "invalid active %q", v)
+			}
+			m[key] = active
+		}
+	}
+
+	patched := &User{}
+	if err := fromResourceMap(m, patched); err != nil {
+		return nil, err
+	}
+	return s.replaceUser(ctx, orgID, uid, patched)
+}
+
+// deleteUser removes the user from the organization, users without other organizations are deleted.
+func (s *Service) deleteUser(ctx context.Context, orgID int64, uid string) error {
+	current, err := s.getOrgUser(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	cmd := &org.RemoveOrgUserCommand{
+		UserID:                   current.ID,
+		OrgID:                    orgID,
+		ShouldDeleteOrphanedUser: !current.IsAdmin,
+	}
+	if err := s.orgService.RemoveOrgUser(ctx, cmd); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return newError(http.StatusBadRequest, errTypeMutability, "cannot remove the last administrator of the organization")
+		}
+		return err
+	}
+
+	permissionsOrgID := orgID
+	if cmd.UserWasDeleted {
+		permissionsOrgID = accesscontrol.GlobalOrgID
+	}
+	if err := s.accessControlService.DeleteUserPermissions(ctx, permissionsOrgID, current.ID); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete permissions for user", "userId", current.ID, "orgId", permissionsOrgID, "error", err)
+	}
+	if err := s.store.setExternalID(ctx, orgID, resourceTypeUser, current.ID, ""); err != nil {
+		return err
+	}
+
+	s.log.FromContext(ctx).Info("Deprovisioned user", "orgId", orgID, "userId", current.ID, "deleted", cmd.UserWasDeleted)
+	return nil
+}
@@ -499,6 +499,53 @@ func (s *SecretsService) ReEncryptDataKeys(ctx context.Context) error {
 	return nil
 }
 
+// ReEncryptDataKeysToProvider re-encrypts all data keys with the given provider, so that
+// the keys of a previous provider can be retired. It fails if any data key could not be
+// re-encrypted, the command can be run again once the cause is fixed.
+func (s *SecretsService) ReEncryptDataKeysToProvider(ctx context.Context, providerID secrets.ProviderID) error {
+	providerID = kmsproviders.NormalizeProviderID(providerID)
+	s.log.Info("Data keys re-encryption to provider triggered", "provider", providerID)
+
+	if s.features.IsEnabled(ctx, featuremgmt.FlagDisableEnvelopeEncryption) {
+		s.log.Info("Envelope encryption is not enabled but trying to init providers anyway...")
+
+		if err := s.InitProviders(); err != nil {
+			s.log.Error("Envelope encryption providers initialization failed", "error", err)
+			return err
+		}
+	}
+
+	if _, ok := s.providers[providerID]; !ok {
+		return fmt.Errorf("encryption provider %s is not configured", providerID)
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if err := s.store.ReEncryptDataKeys(ctx, s.providers, providerID); err != nil {
+		s.log.Error("Data keys re-encryption failed", "error", err)
+		return err
+	}
+	s.dataKeyCache.flush()
+
+	keys, err := s.store.GetAllDataKeys(ctx)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, k := range keys {
+		if kmsproviders.NormalizeProviderID(k.Provider) != providerID {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d data keys could not be re-encrypted with %s, see the logs for details", failed, len(keys), providerID)
+	}
+
+	s.log.Info("Data keys re-encryption to provider finished successfully", "provider", providerID, "keys", len(keys))
+	return nil
+}
+
 func (s *SecretsService) Run(ctx context.Context) error {
 	gc := time.NewTicker(
 		s.cfg.SectionWithEnvOverrides("security.encryption").Key("data_keys_cache_cleanup_interval").
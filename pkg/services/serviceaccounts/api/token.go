@@ -3,11 +3,14 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/grafana/authlib/claims"
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/web"
@@ -28,6 +31,8 @@ type TokenDTO struct {
 	Created *time.Time `json:"created"`
 	// example: 2022-03-23T10:31:02Z
 	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// example: 192.168.1.1
+	LastUsedIP string `json:"lastUsedIp"`
 	// example: 2022-03-23T10:31:02Z
 	Expiration *time.Time `json:"expiration"`
 	// example: 0
@@ -36,6 +41,16 @@ type TokenDTO struct {
 	HasExpired bool `json:"hasExpired"`
 	// example: false
 	IsRevoked *bool `json:"isRevoked"`
+	// example: 2022-03-23T10:31:02Z
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// example: Reported as leaked by secret scanning
+	RevokedReason string `json:"revokedReason,omitempty"`
+	// Permissions the token is restricted to, empty when the token has all the permissions of the service account.
+	Permissions []apikey.Permission `json:"permissions,omitempty"`
+	// example: 2592000
+	RotationIntervalSeconds *int64 `json:"rotationIntervalSeconds,omitempty"`
+	// example: 2022-03-23T10:31:02Z
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
 }
 
 func hasExpired(expiration *int64) bool {
@@ -96,14 +111,20 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 		}
 
 		result[i] = TokenDTO{
-			Id:                     token.ID,
-			Name:                   token.Name,
-			Created:                &token.Created,
-			Expiration:             expiration,
-			SecondsUntilExpiration: &secondsUntilExpiration,
-			HasExpired:             isExpired,
-			LastUsedAt:             token.LastUsedAt,
-			IsRevoked:              token.IsRevoked,
+			Id:                      token.ID,
+			Name:                    token.Name,
+			Created:                 &token.Created,
+			Expiration:              expiration,
+			SecondsUntilExpiration:  &secondsUntilExpiration,
+			HasExpired:              isExpired,
+			LastUsedAt:              token.LastUsedAt,
+			LastUsedIP:              token.LastUsedIP,
+			IsRevoked:               token.IsRevoked,
+			RevokedAt:               token.RevokedAt,
+			RevokedReason:           token.RevokedReason,
+			Permissions:             token.Permissions,
+			RotationIntervalSeconds: token.RotationInterval,
+			RotatedAt:               token.RotatedAt,
 		}
 	}
 
@@ -144,33 +165,120 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	// Force affected service account to be the one referenced in the URL
 	cmd.OrgId = c.SignedInUser.GetOrgID()
 
-	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+	if resp := api.validateSecondsToLive(cmd.SecondsToLive); resp != nil {
+		return resp
+	}
+
+	newKeyInfo, err := satokengen.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+
+	cmd.Key = newKeyInfo.HashedKey
+
+	apiKey, err := api.service.AddServiceAccountToken(c.Req.Context(), saID, &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to add service account token", err)
+	}
+
+	result := &dtos.NewApiKeyResult{
+		ID:   apiKey.ID,
+		Name: apiKey.Name,
+		Key:  newKeyInfo.ClientSecret,
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken replaces a service account token with a new one
+//
+// The new token keeps the permissions of the rotated token. The rotated token remains valid for the overlap window
+// so that clients can switch to the new token without downtime.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: createTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	// confirm service account exists
+	if _, err := api.service.RetrieveServiceAccount(c.Req.Context(), c.SignedInUser.GetOrgID(), saID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to retrieve service account", err)
+	}
+
+	cmd := serviceaccounts.RotateServiceAccountTokenCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	orgID := c.SignedInUser.GetOrgID()
+	tokens, err := api.service.ListTokens(c.Req.Context(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &orgID,
+		ServiceAccountID: &saID,
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to retrieve service account tokens", err)
+	}
+
+	var token *apikey.APIKey
+	for i := range tokens {
+		if tokens[i].ID == tokenID {
+			token = &tokens[i]
+			break
 		}
 	}
+	if token == nil {
+		return response.Err(serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found", tokenID))
+	}
 
-	if api.cfg.SATokenExpirationDayLimit > 0 {
-		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
-		expirationDate := time.Now().Add(time.Duration(cmd.SecondsToLive) * time.Second).Truncate(24 * time.Hour)
-		if expirationDate.After(dayExpireLimit) {
-			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+	if cmd.Name == "" {
+		cmd.Name = serviceaccounts.RotatedTokenName(token.Name, time.Now())
+	}
+
+	// keep the lifetime of the rotated token unless a new one is given
+	if cmd.SecondsToLive == 0 && token.Expires != nil {
+		if lifetime := *token.Expires - token.Created.Unix(); lifetime > 0 {
+			cmd.SecondsToLive = lifetime
 		}
 	}
 
+	if cmd.OverlapSeconds == nil {
+		overlap := int64(api.cfg.SATokenRotationOverlap.Seconds())
+		cmd.OverlapSeconds = &overlap
+	}
+
+	if resp := api.validateSecondsToLive(cmd.SecondsToLive); resp != nil {
+		return resp
+	}
+
 	newKeyInfo, err := satokengen.New(ServiceID)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
 	}
-
 	cmd.Key = newKeyInfo.HashedKey
 
-	apiKey, err := api.service.AddServiceAccountToken(c.Req.Context(), saID, &cmd)
+	apiKey, err := api.service.RotateServiceAccountToken(c.Req.Context(), saID, tokenID, &cmd)
 	if err != nil {
-		return response.ErrOrFallback(http.StatusInternalServerError, "failed to add service account token", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to rotate service account token", err)
 	}
 
 	result := &dtos.NewApiKeyResult{
@@ -182,6 +290,76 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	return response.JSON(http.StatusOK, result)
 }
 
+// swagger:route POST /serviceaccounts/tokens/successor service_accounts getTokenSuccessor
+//
+// # GetTokenSuccessor returns the token that replaced the token of the request in a scheduled rotation
+//
+// The request must be authenticated with the rotated token, which remains valid for the overlap window
+// after the scheduled rotation. The secret of the new token can only be retrieved once.
+//
+// Responses:
+// 200: createTokenResponse
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) GetTokenSuccessor(c *contextmodel.ReqContext) response.Response {
+	token := requestToken(c.Req)
+	if !c.SignedInUser.IsIdentityType(claims.TypeServiceAccount) || token == "" {
+		return response.Error(http.StatusUnauthorized, "Request must be authenticated with a service account token", nil)
+	}
+
+	saID, err := c.SignedInUser.GetInternalID()
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get service account ID", err)
+	}
+
+	apiKey, secret, err := api.service.GetServiceAccountTokenSuccessor(c.Req.Context(), saID, token)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to retrieve the successor of the service account token", err)
+	}
+
+	result := &dtos.NewApiKeyResult{
+		ID:   apiKey.ID,
+		Name: apiKey.Name,
+		Key:  secret,
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// requestToken returns the token the request is authenticated with.
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if username, password, ok := r.BasicAuth(); ok && username == "api_key" {
+		return password
+	}
+	return ""
+}
+
+// validateSecondsToLive checks the token lifetime against the configured limits.
+func (api *ServiceAccountsAPI) validateSecondsToLive(secondsToLive int64) response.Response {
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	if api.cfg.SATokenExpirationDayLimit > 0 {
+		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
+		expirationDate := time.Now().Add(time.Duration(secondsToLive) * time.Second).Truncate(24 * time.Hour)
+		if expirationDate.After(dayExpireLimit) {
+			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+		}
+	}
+
+	return nil
+}
+
 // swagger:route DELETE /serviceaccounts/{serviceAccountId}/tokens/{tokenId} service_accounts deleteToken
 //
 // # DeleteToken deletes service account tokens
@@ -235,6 +413,16 @@ type CreateTokenParams struct {
 	Body serviceaccounts.AddServiceAccountTokenCommand
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenCommand
+}
+
 // swagger:parameters deleteToken
 type DeleteTokenParams struct {
 	// in:path
@@ -178,3 +178,155 @@ func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	type TestCase struct {
+		desc           string
+		saID           int64
+		tokenID        int64
+		body           string
+		tokenTTL       int64
+		permissions    []accesscontrol.Permission
+		expectedTokens []apikey.APIKey
+		expectedErr    error
+		expectedCode   int
+	}
+
+	tests := []TestCase{
+		{
+			desc:           "should be able to rotate service account token with correct permission",
+			saID:           1,
+			tokenID:        1,
+			body:           `{}`,
+			tokenTTL:       -1,
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedTokens: []apikey.APIKey{{ID: 1, Name: "test"}},
+			expectedCode:   http.StatusOK,
+		},
+		{
+			desc:           "should not be able to rotate service account token with wrong permission",
+			saID:           2,
+			tokenID:        1,
+			body:           `{}`,
+			tokenTTL:       -1,
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedTokens: []apikey.APIKey{{ID: 1, Name: "test"}},
+			expectedCode:   http.StatusForbidden,
+		},
+		{
+			desc:           "should not be able to rotate service account token that does not exist",
+			saID:           1,
+			tokenID:        2,
+			body:           `{}`,
+			tokenTTL:       -1,
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedTokens: []apikey.APIKey{{ID: 1, Name: "test"}},
+			expectedCode:   http.StatusNotFound,
+		},
+		{
+			desc:           "should not be able to rotate service account token without expiration if max ttl is configured",
+			saID:           1,
+			tokenID:        1,
+			body:           `{}`,
+			tokenTTL:       10 * int64(time.Hour),
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedTokens: []apikey.APIKey{{ID: 1, Name: "test"}},
+			expectedCode:   http.StatusBadRequest,
+		},
+		{
+			desc:           "should be able to rotate service account token keeping its lifetime if max ttl is configured",
+			saID:           1,
+			tokenID:        1,
+			body:           `{}`,
+			tokenTTL:       10 * int64(time.Hour),
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedTokens: []apikey.APIKey{{ID: 1, Name: "test", Created: time.Now(), Expires: int64Ptr(time.Now().Add(time.Hour).Unix())}},
+			expectedCode:   http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.cfg.ApiKeyMaxSecondsToLive = tt.tokenTTL
+				a.service = &satests.FakeServiceAccountService{
+					ExpectedErr:                  tt.expectedErr,
+					ExpectedAPIKey:               &apikey.APIKey{ID: 2, Name: "test-rotated"},
+					ExpectedServiceAccountTokens: tt.expectedTokens,
+				}
+			})
+
+			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens/%d/rotate", tt.saID, tt.tokenID), strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestServiceAccountsAPI_GetTokenSuccessor(t *testing.T) {
+	type TestCase struct {
+		desc         string
+		user         *user.SignedInUser
+		token        string
+		expectedErr  error
+		expectedCode int
+	}
+
+	tests := []TestCase{
+		{
+			desc:         "should return the successor of the token of the request",
+			user:         &user.SignedInUser{OrgID: 1, UserID: 1, IsServiceAccount: true},
+			token:        "glsa_rotated",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not return a successor to users",
+			user:         &user.SignedInUser{OrgID: 1, UserID: 1},
+			token:        "glsa_rotated",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			desc:         "should not return a successor without a token",
+			user:         &user.SignedInUser{OrgID: 1, UserID: 1, IsServiceAccount: true},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			desc:         "should not return a successor for a token that was not rotated",
+			user:         &user.SignedInUser{OrgID: 1, UserID: 1, IsServiceAccount: true},
+			token:        "glsa_rotated",
+			expectedErr:  serviceaccounts.ErrServiceAccountTokenNoSuccessor.Errorf("no successor"),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.service = &satests.FakeServiceAccountService{
+					ExpectedErr:    tt.expectedErr,
+					ExpectedAPIKey: &apikey.APIKey{ID: 2, Name: "test-rotated"},
+					ExpectedSecret: "glsa_successor",
+				}
+			})
+
+			req := server.NewRequest(http.MethodPost, "/api/serviceaccounts/tokens/successor", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			webtest.RequestWithSignedInUser(req, tt.user)
+			res, err := server.Send(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
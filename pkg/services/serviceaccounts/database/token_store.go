@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -11,7 +12,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 )
 
-const maxRetrievedTokens = 300
+const (
+	maxRetrievedTokens     = 300
+	maxRevokedReasonLength = 255
+)
 
 func (s *ServiceAccountsStoreImpl) ListTokens(
 	ctx context.Context, query *serviceaccounts.GetSATokensQuery,
@@ -56,6 +60,10 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 			Key:              cmd.Key,
 			SecondsToLive:    cmd.SecondsToLive,
 			ServiceAccountID: &serviceAccountId,
+			Permissions:      cmd.Permissions,
+		}
+		if cmd.RotationIntervalSeconds > 0 {
+			addKeyCmd.RotationInterval = &cmd.RotationIntervalSeconds
 		}
 
 		key, err := s.apiKeyService.AddAPIKey(ctx, addKeyCmd)
@@ -92,11 +100,109 @@ func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context
 	})
 }
 
-func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
-	rawSQL := "UPDATE api_key SET is_revoked = ? WHERE id=? and org_id=? and service_account_id=?"
+// RotateServiceAccountToken adds a new token with the permissions and rotation interval of the rotated token
+// and shortens the validity of the rotated token to the overlap window.
+func (s *ServiceAccountsStoreImpl) RotateServiceAccountToken(ctx context.Context, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	var key *apikey.APIKey
+	// The new token is only added if the rotated token is expired too, so that a failed rotation never leaves two live tokens.
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		var token apikey.APIKey
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			has, err := sess.Where("id=? and org_id=? and service_account_id=?", tokenId, cmd.OrgId, serviceAccountId).Get(&token)
+			if err != nil {
+				return err
+			}
+			if !has {
+				return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenId, serviceAccountId)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if token.IsRevoked != nil && *token.IsRevoked {
+			return serviceaccounts.ErrServiceAccountTokenRevoked.Errorf("service account token with id %d is revoked", tokenId)
+		}
+
+		addCmd := &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:          cmd.Name,
+			OrgId:         cmd.OrgId,
+			Key:           cmd.Key,
+			SecondsToLive: cmd.SecondsToLive,
+			Permissions:   token.Permissions,
+		}
+		if token.RotationInterval != nil {
+			addCmd.RotationIntervalSeconds = *token.RotationInterval
+		}
+		key, err = s.AddServiceAccountToken(ctx, serviceAccountId, addCmd)
+		if err != nil {
+			return err
+		}
+
+		var overlap int64
+		if cmd.OverlapSeconds != nil {
+			overlap = *cmd.OverlapSeconds
+		}
+		now := time.Now()
+		expires := now.Add(time.Duration(overlap) * time.Second).Unix()
+		if token.Expires != nil && *token.Expires < expires {
+			// the rotated token already expires within the overlap window
+			expires = *token.Expires
+		}
+
+		// rotated_at keeps the rotated token from being rotated again by the scheduled rotation
+		rawSQL := "UPDATE api_key SET expires = ?, rotated_at = ? WHERE id=? and org_id=? and service_account_id=?"
+		err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec(rawSQL, expires, now, tokenId, cmd.OrgId, serviceAccountId)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", "failed to expire rotated token", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ListTokensDueForRotation returns the service account tokens with a rotation interval that has elapsed,
+// that are neither expired, revoked nor already rotated.
+func (s *ServiceAccountsStoreImpl) ListTokensDueForRotation(ctx context.Context, now time.Time) ([]apikey.APIKey, error) {
+	var tokens []apikey.APIKey
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("service_account_id IS NOT NULL").
+			And("rotation_interval > 0").
+			And("rotated_at IS NULL").
+			And("(is_revoked IS NULL OR is_revoked = ?)", s.sqlStore.GetDialect().BooleanStr(false)).
+			And("(expires IS NULL OR expires > ?)", now.Unix()).
+			Find(&tokens)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", "failed to list tokens due for rotation", err)
+	}
+
+	due := make([]apikey.APIKey, 0, len(tokens))
+	for _, t := range tokens {
+		if !t.Created.Add(time.Duration(*t.RotationInterval) * time.Second).After(now) {
+			due = append(due, t)
+		}
+	}
+	return due, nil
+}
+
+// RevokeServiceAccountToken revokes the token and records why it was revoked.
+func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64, reason string) error {
+	rawSQL := "UPDATE api_key SET is_revoked = ?, revoked_at = ?, revoked_reason = ? WHERE id=? and org_id=? and service_account_id=?"
+
+	if len(reason) > maxRevokedReasonLength {
+		reason = reason[:maxRevokedReasonLength]
+	}
 
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		result, err := sess.Exec(rawSQL, s.sqlStore.GetDialect().BooleanStr(true), tokenId, orgId, serviceAccountId)
+		result, err := sess.Exec(rawSQL, s.sqlStore.GetDialect().BooleanStr(true), time.Now(), reason, tokenId, orgId, serviceAccountId)
 		if err != nil {
 			return err
 		}
@@ -3,10 +3,12 @@ package database
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
 )
@@ -113,7 +115,7 @@ func TestStore_RevokeServiceAccountToken(t *testing.T) {
 	require.NoError(t, err)
 
 	// Revoke SAT
-	err = store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, newKey.ID)
+	err = store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, newKey.ID, "leaked")
 	require.NoError(t, err)
 
 	// Verify against DB
@@ -126,6 +128,8 @@ func TestStore_RevokeServiceAccountToken(t *testing.T) {
 	for _, k := range keys {
 		if k.Name == keyName {
 			require.True(t, *k.IsRevoked)
+			require.NotNil(t, k.RevokedAt)
+			require.Equal(t, "leaked", k.RevokedReason)
 			return
 		}
 	}
@@ -133,6 +137,131 @@ func TestStore_RevokeServiceAccountToken(t *testing.T) {
 	require.Fail(t, "Key not found")
 }
 
+func TestStore_RotateServiceAccountToken(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	addToken := func(t *testing.T, name string) *apikey.APIKey {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:        name,
+			OrgId:       sa.OrgID,
+			Key:         key.HashedKey,
+			Permissions: []apikey.Permission{{Action: "dashboards:read", Scope: "dashboards:uid:1"}},
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	rotate := func(t *testing.T, token *apikey.APIKey, overlap int64) (*apikey.APIKey, error) {
+		key, err := apikeygen.New(sa.OrgID, token.Name+"-rotated")
+		require.NoError(t, err)
+
+		return store.RotateServiceAccountToken(context.Background(), sa.ID, token.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+			Name:           token.Name + "-rotated",
+			OrgId:          sa.OrgID,
+			Key:            key.HashedKey,
+			OverlapSeconds: &overlap,
+		})
+	}
+
+	getToken := func(t *testing.T, name string) apikey.APIKey {
+		keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+			OrgID:            &sa.OrgID,
+			ServiceAccountID: &sa.ID,
+		})
+		require.NoError(t, err)
+		for _, k := range keys {
+			if k.Name == name {
+				return k
+			}
+		}
+		require.Fail(t, "Key not found")
+		return apikey.APIKey{}
+	}
+
+	t.Run("should add a token with the same permissions and expire the rotated token", func(t *testing.T) {
+		token := addToken(t, "rotate")
+
+		rotated, err := rotate(t, token, 3600)
+		require.NoError(t, err)
+		require.Equal(t, "rotate-rotated", rotated.Name)
+
+		newToken := getToken(t, "rotate-rotated")
+		require.Equal(t, token.Permissions, newToken.Permissions)
+
+		oldToken := getToken(t, "rotate")
+		require.NotNil(t, oldToken.Expires)
+		require.InDelta(t, time.Now().Add(time.Hour).Unix(), *oldToken.Expires, 5)
+	})
+
+	t.Run("should not rotate a revoked token", func(t *testing.T) {
+		token := addToken(t, "revoked")
+		require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, token.ID, "leaked"))
+
+		_, err := rotate(t, token, 0)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenRevoked)
+	})
+
+	t.Run("should fail for unknown token", func(t *testing.T) {
+		_, err := rotate(t, &apikey.APIKey{ID: 1000, Name: "unknown"}, 0)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+	})
+}
+
+func TestStore_ListTokensDueForRotation(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	addToken := func(t *testing.T, name string, rotationInterval int64) *apikey.APIKey {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:                    name,
+			OrgId:                   sa.OrgID,
+			Key:                     key.HashedKey,
+			RotationIntervalSeconds: rotationInterval,
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	due := addToken(t, "due", 3600)
+	addToken(t, "not-scheduled", 0)
+	addToken(t, "not-due", 3*3600)
+	revoked := addToken(t, "revoked", 3600)
+	require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, revoked.ID, "leaked"))
+
+	now := time.Now().Add(2 * time.Hour)
+	tokens, err := store.ListTokensDueForRotation(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, due.ID, tokens[0].ID)
+
+	key, err := apikeygen.New(sa.OrgID, "due-rotated")
+	require.NoError(t, err)
+	overlap := int64(3600)
+	rotated, err := store.RotateServiceAccountToken(context.Background(), sa.ID, due.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		Name:           "due-rotated",
+		OrgId:          sa.OrgID,
+		Key:            key.HashedKey,
+		OverlapSeconds: &overlap,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3600), *rotated.RotationInterval)
+
+	// the rotated token is not rotated again, its successor is due an interval after its creation
+	tokens, err = store.ListTokensDueForRotation(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, rotated.ID, tokens[0].ID)
+}
+
 func TestStore_DeleteServiceAccountToken(t *testing.T) {
 	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
 	db, store := setupTestDatabase(t)
@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/satokengen"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/database"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/secretscan"
@@ -23,6 +28,12 @@ import (
 const (
 	metricsCollectionInterval = time.Minute * 30
 	defaultSecretScanInterval = time.Minute * 5
+	tokenRotationInterval     = time.Minute
+
+	// tokenServiceID is the service ID of the tokens created through the service accounts API.
+	tokenServiceID = "sa"
+	// tokenSuccessorSecretType is the type of the secrets holding the tokens issued by a scheduled rotation.
+	tokenSuccessorSecretType = "serviceaccount-token-successor"
 )
 
 type ServiceAccountsService struct {
@@ -34,6 +45,11 @@ type ServiceAccountsService struct {
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
+
+	apiKeyService        apikey.Service
+	skvStore             secretskvs.SecretsKVStore
+	tokenRotationOverlap time.Duration
+	serverLock           *serverlock.ServerLockService
 }
 
 func ProvideServiceAccountsService(
@@ -45,6 +61,8 @@ func ProvideServiceAccountsService(
 	userService user.Service,
 	orgService org.Service,
 	accesscontrolService accesscontrol.Service,
+	secretsService secrets.Service,
+	serverLock *serverlock.ServerLockService,
 ) (*ServiceAccountsService, error) {
 	serviceAccountsStore := database.ProvideServiceAccountsStore(
 		cfg,
@@ -55,11 +73,16 @@ func ProvideServiceAccountsService(
 		orgService,
 	)
 	s := &ServiceAccountsService{
-		acService:     accesscontrolService,
-		store:         serviceAccountsStore,
-		log:           log.New("serviceaccounts"),
-		backgroundLog: log.New("serviceaccounts.background"),
+		acService:            accesscontrolService,
+		store:                serviceAccountsStore,
+		log:                  log.New("serviceaccounts"),
+		backgroundLog:        log.New("serviceaccounts.background"),
+		apiKeyService:        apiKeyService,
+		tokenRotationOverlap: cfg.SATokenRotationOverlap,
+		serverLock:           serverLock,
 	}
+	// Using SQL store to avoid a cyclic dependency
+	s.skvStore = secretskvs.NewSQLSecretsKVStore(store, secretsService, s.log)
 
 	if err := RegisterRoles(accesscontrolService); err != nil {
 		s.log.Error("Failed to register roles", "error", err)
@@ -114,6 +137,9 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	tokenRotationTicker := time.NewTicker(tokenRotationInterval)
+	defer tokenRotationTicker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -136,8 +162,79 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-tokenRotationTicker.C:
+			sa.scheduledTokenRotation(ctx)
+		}
+	}
+}
+
+// scheduledTokenRotation rotates the tokens due for rotation on a single Grafana instance, two instances rotating
+// the same token would both create a successor.
+func (sa *ServiceAccountsService) scheduledTokenRotation(ctx context.Context) {
+	err := sa.serverLock.LockAndExecute(ctx, "service account token rotation", tokenRotationInterval, func(ctx context.Context) {
+		sa.backgroundLog.Debug("Rotating tokens due for rotation")
+
+		if err := sa.rotateDueTokens(ctx); err != nil {
+			sa.backgroundLog.Warn("Failed to rotate tokens", "error", err.Error())
+		}
+	})
+	if err != nil {
+		sa.backgroundLog.Warn("Failed to acquire the token rotation lock", "error", err.Error())
+	}
+}
+
+// rotateDueTokens replaces the tokens whose rotation interval has elapsed. The secret of a new token is
+// kept encrypted until the token it replaces retrieves it, see GetServiceAccountTokenSuccessor.
+func (sa *ServiceAccountsService) rotateDueTokens(ctx context.Context) error {
+	tokens, err := sa.store.ListTokensDueForRotation(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, token := range tokens {
+		if err := sa.rotateToken(ctx, token); err != nil {
+			errs = append(errs, fmt.Errorf("failed to rotate token %d: %w", token.ID, err))
+			continue
+		}
+		sa.backgroundLog.Info("Rotated service account token", "tokenId", token.ID, "serviceAccountId", *token.ServiceAccountId)
+	}
+	return errors.Join(errs...)
+}
+
+func (sa *ServiceAccountsService) rotateToken(ctx context.Context, token apikey.APIKey) error {
+	newKeyInfo, err := satokengen.New(tokenServiceID)
+	if err != nil {
+		return err
+	}
+
+	// Store the secret first, the rotated token must be able to retrieve its successor before it expires.
+	namespace := strconv.FormatInt(token.ID, 10)
+	if err := sa.skvStore.Set(ctx, token.OrgID, namespace, tokenSuccessorSecretType, newKeyInfo.ClientSecret); err != nil {
+		return err
+	}
+
+	overlap := int64(sa.tokenRotationOverlap.Seconds())
+	cmd := &serviceaccounts.RotateServiceAccountTokenCommand{
+		Name:           serviceaccounts.RotatedTokenName(token.Name, time.Now()),
+		OrgId:          token.OrgID,
+		Key:            newKeyInfo.HashedKey,
+		OverlapSeconds: &overlap,
+	}
+	// keep the lifetime of the rotated token
+	if token.Expires != nil {
+		if lifetime := *token.Expires - token.Created.Unix(); lifetime > 0 {
+			cmd.SecondsToLive = lifetime
 		}
 	}
+
+	if _, err := sa.store.RotateServiceAccountToken(ctx, *token.ServiceAccountId, token.ID, cmd); err != nil {
+		if err := sa.skvStore.Del(ctx, token.OrgID, namespace, tokenSuccessorSecretType); err != nil {
+			sa.backgroundLog.Warn("Failed to delete the successor of a token that could not be rotated", "tokenId", token.ID, "error", err)
+		}
+		return err
+	}
+	return nil
 }
 
 var _ serviceaccounts.Service = (*ServiceAccountsService)(nil)
@@ -218,9 +315,73 @@ func (sa *ServiceAccountsService) AddServiceAccountToken(ctx context.Context, se
 	if err := validServiceAccountID(serviceAccountID); err != nil {
 		return nil, err
 	}
+	if err := validTokenPermissions(query.Permissions); err != nil {
+		return nil, err
+	}
+	if query.RotationIntervalSeconds < 0 {
+		return nil, serviceaccounts.ErrInvalidTokenRotationInterval.Errorf("invalid rotation interval %d", query.RotationIntervalSeconds)
+	}
 	return sa.store.AddServiceAccountToken(ctx, serviceAccountID, query)
 }
 
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(cmd.OrgId); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+	if cmd.OverlapSeconds != nil && *cmd.OverlapSeconds < 0 {
+		return nil, serviceaccounts.ErrInvalidTokenExpiration.Errorf("invalid overlap value %d", *cmd.OverlapSeconds)
+	}
+	return sa.store.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
+
+// GetServiceAccountTokenSuccessor returns the token that replaced the given token in a scheduled rotation,
+// together with its secret. The secret can only be retrieved once.
+func (sa *ServiceAccountsService) GetServiceAccountTokenSuccessor(ctx context.Context, serviceAccountID int64, token string) (*apikey.APIKey, string, error) {
+	key, err := sa.getTokenBySecret(ctx, token)
+	if err != nil {
+		return nil, "", err
+	}
+	if key.ServiceAccountId == nil || *key.ServiceAccountId != serviceAccountID {
+		return nil, "", serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("token does not belong to service account with id %d", serviceAccountID)
+	}
+
+	namespace := strconv.FormatInt(key.ID, 10)
+	secret, ok, err := sa.skvStore.Get(ctx, key.OrgID, namespace, tokenSuccessorSecretType)
+	if err != nil {
+		return nil, "", err
+	}
+	if !ok {
+		return nil, "", serviceaccounts.ErrServiceAccountTokenNoSuccessor.Errorf("service account token with id %d has no successor", key.ID)
+	}
+
+	successor, err := sa.getTokenBySecret(ctx, secret)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := sa.skvStore.Del(ctx, key.OrgID, namespace, tokenSuccessorSecretType); err != nil {
+		return nil, "", err
+	}
+	return successor, secret, nil
+}
+
+func (sa *ServiceAccountsService) getTokenBySecret(ctx context.Context, secret string) (*apikey.APIKey, error) {
+	decoded, err := satokengen.Decode(secret)
+	if err != nil {
+		return nil, serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("invalid service account token: %w", err)
+	}
+	hash, err := decoded.Hash()
+	if err != nil {
+		return nil, err
+	}
+	return sa.apiKeyService.GetAPIKeyByHash(ctx, hash)
+}
+
 func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID int64, tokenID int64) error {
 	if err := validOrgID(orgID); err != nil {
 		return err
@@ -231,7 +392,11 @@ func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context,
 	if err := validServiceAccountTokenID(tokenID); err != nil {
 		return err
 	}
-	return sa.store.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID)
+	if err := sa.store.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID); err != nil {
+		return err
+	}
+	// a successor that was not retrieved yet is of no use anymore
+	return sa.skvStore.Del(ctx, orgID, strconv.FormatInt(tokenID, 10), tokenSuccessorSecretType)
 }
 
 func (sa *ServiceAccountsService) MigrateApiKey(ctx context.Context, orgID, keyID int64) error {
@@ -268,6 +433,17 @@ func validServiceAccountTokenID(tokenID int64) error {
 	}
 	return nil
 }
+func validTokenPermissions(permissions []apikey.Permission) error {
+	for _, p := range permissions {
+		if p.Action == "" {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("permission action is required")
+		}
+		if p.Scope != "" && !accesscontrol.ValidateScope(p.Scope) {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("invalid scope %s for action %s", p.Scope, p.Action)
+		}
+	}
+	return nil
+}
 func validAPIKeyID(apiKeyID int64) error {
 	if apiKeyID == 0 {
 		return serviceaccounts.ErrServiceAccountInvalidAPIKeyID.Errorf("invalid API key ID 0 has been specified")
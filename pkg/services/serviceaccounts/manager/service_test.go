@@ -3,13 +3,17 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/satokengen"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/apikey/apikeytest"
 	"github.com/grafana/grafana/pkg/services/org"
+	secretskvs "github.com/grafana/grafana/pkg/services/secrets/kvstore"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 )
 
@@ -89,10 +93,20 @@ func (f *FakeServiceAccountStore) ListTokens(ctx context.Context, query *service
 }
 
 // RevokeServiceAccountToken is a fake revoking a service account token.
-func (f *FakeServiceAccountStore) RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
+func (f *FakeServiceAccountStore) RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64, reason string) error {
 	return f.ExpectedError
 }
 
+// RotateServiceAccountToken is a fake rotating a service account token.
+func (f *FakeServiceAccountStore) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedError
+}
+
+// ListTokensDueForRotation is a fake listing tokens due for rotation.
+func (f *FakeServiceAccountStore) ListTokensDueForRotation(ctx context.Context, now time.Time) ([]apikey.APIKey, error) {
+	return f.ExpectedAPIKeys, f.ExpectedError
+}
+
 // AddServiceAccountToken is a fake adding a service account token.
 func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, serviceAccountID int64, cmd *serviceaccounts.AddServiceAccountTokenCommand) (*apikey.APIKey, error) {
 	return f.ExpectedAPIKey, f.ExpectedError
@@ -119,7 +133,7 @@ func (f *SecretsCheckerFake) CheckTokens(ctx context.Context) error {
 func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 	storeMock := newServiceAccountStoreFake()
 	acSvc := actest.FakeService{}
-	svc := ServiceAccountsService{acSvc, storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0}
+	svc := ServiceAccountsService{acSvc, storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0, nil, nil, 0, nil}
 	testOrgId := 1
 
 	t.Run("should create service account", func(t *testing.T) {
@@ -146,3 +160,34 @@ func TestProvideServiceAccount_DeleteServiceAccount(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestServiceAccountsService_RotateDueTokens(t *testing.T) {
+	saID := int64(1)
+	token := apikey.APIKey{ID: 1, OrgID: 1, Name: "ci", ServiceAccountId: &saID, Created: time.Now().Add(-time.Hour)}
+	storeMock := newServiceAccountStoreFake()
+	storeMock.ExpectedAPIKeys = []apikey.APIKey{token}
+	storeMock.ExpectedAPIKey = &apikey.APIKey{ID: 2, OrgID: 1, Name: "ci-rotated", ServiceAccountId: &saID}
+	skvStore := secretskvs.NewFakeSecretsKVStore()
+	apiKeyService := &apikeytest.Service{ExpectedAPIKey: &token}
+	svc := ServiceAccountsService{actest.FakeService{}, storeMock, log.New("test"), log.New("background.test"), &SecretsCheckerFake{}, false, 0, apiKeyService, skvStore, time.Hour, nil}
+
+	rotated, err := satokengen.New(tokenServiceID)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.rotateDueTokens(context.Background()))
+
+	t.Run("should not return the successor to another service account", func(t *testing.T) {
+		_, _, err := svc.GetServiceAccountTokenSuccessor(context.Background(), 2, rotated.ClientSecret)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+	})
+
+	t.Run("should return the successor of the rotated token once", func(t *testing.T) {
+		_, secret, err := svc.GetServiceAccountTokenSuccessor(context.Background(), saID, rotated.ClientSecret)
+		require.NoError(t, err)
+		_, err = satokengen.Decode(secret)
+		require.NoError(t, err)
+
+		_, _, err = svc.GetServiceAccountTokenSuccessor(context.Background(), saID, rotated.ClientSecret)
+		require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNoSuccessor)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/org"
 )
 
@@ -42,6 +43,10 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrInvalidTokenPermissions           = errutil.ValidationFailed("serviceaccounts.ErrInvalidTokenPermissions", errutil.WithPublicMessage("invalid service account token permissions"))
+	ErrServiceAccountTokenRevoked        = errutil.BadRequest("serviceaccounts.ErrTokenRevoked", errutil.WithPublicMessage("service account token is revoked"))
+	ErrInvalidTokenRotationInterval      = errutil.ValidationFailed("serviceaccounts.ErrInvalidTokenRotationInterval", errutil.WithPublicMessage("invalid service account token rotation interval"))
+	ErrServiceAccountTokenNoSuccessor    = errutil.NotFound("serviceaccounts.ErrTokenNoSuccessor", errutil.WithPublicMessage("service account token has no successor to retrieve"))
 )
 
 type MigrationResult struct {
@@ -103,6 +108,23 @@ type AddServiceAccountTokenCommand struct {
 	OrgId         int64  `json:"-"`
 	Key           string `json:"-"`
 	SecondsToLive int64  `json:"secondsToLive"`
+	// Permissions restricts the token to a subset of the service account permissions.
+	// When empty the token has all the permissions of the service account.
+	Permissions []apikey.Permission `json:"permissions"`
+	// RotationIntervalSeconds after which the token is rotated automatically, zero disables scheduled rotation.
+	RotationIntervalSeconds int64 `json:"rotationIntervalSeconds"`
+}
+
+// swagger:model
+type RotateServiceAccountTokenCommand struct {
+	// Name of the new token, defaults to the name of the rotated token suffixed with the rotation time.
+	Name  string `json:"name"`
+	OrgId int64  `json:"-"`
+	Key   string `json:"-"`
+	// SecondsToLive of the new token, defaults to the lifetime of the rotated token.
+	SecondsToLive int64 `json:"secondsToLive"`
+	// OverlapSeconds during which the rotated token remains valid, defaults to the configured token_rotation_overlap.
+	OverlapSeconds *int64 `json:"overlapSeconds"`
 }
 
 type SearchOrgServiceAccountsQuery struct {
@@ -219,3 +241,24 @@ func IsExternalServiceAccount(login string) bool {
 
 	return parts[0] == ServiceAccountPrefix && parts[2] == ExtSvcPrefix
 }
+
+// RotatedTokenName returns the name of the token that replaces a rotated token. The timestamp suffix
+// of an earlier rotation is replaced so that the name doesn't grow with every rotation.
+func RotatedTokenName(name string, rotatedAt time.Time) string {
+	if i := strings.LastIndex(name, "-"); i > 0 && isUnixTimestamp(name[i+1:]) {
+		name = name[:i]
+	}
+	return fmt.Sprintf("%s-%d", name, rotatedAt.Unix())
+}
+
+func isUnixTimestamp(s string) bool {
+	if len(s) != 10 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -123,6 +123,14 @@ func (s *ServiceAccountsProxy) ListTokens(ctx context.Context, query *serviceacc
 	return s.proxiedService.ListTokens(ctx, query)
 }
 
+func (s *ServiceAccountsProxy) RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return s.proxiedService.RotateServiceAccountToken(ctx, serviceAccountID, tokenID, cmd)
+}
+
+func (s *ServiceAccountsProxy) GetServiceAccountTokenSuccessor(ctx context.Context, serviceAccountID int64, token string) (*apikey.APIKey, string, error) {
+	return s.proxiedService.GetServiceAccountTokenSuccessor(ctx, serviceAccountID, token)
+}
+
 func (s *ServiceAccountsProxy) MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error {
 	return s.proxiedService.MigrateApiKey(ctx, orgID, keyId)
 }
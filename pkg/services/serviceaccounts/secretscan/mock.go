@@ -25,9 +25,9 @@ func (m *MockTokenRetriever) ListTokens(
 }
 
 func (m *MockTokenRetriever) RevokeServiceAccountToken(
-	ctx context.Context, orgID, serviceAccountID, tokenID int64,
+	ctx context.Context, orgID, serviceAccountID, tokenID int64, reason string,
 ) error {
-	m.revokeCalls = append(m.revokeCalls, []any{orgID, serviceAccountID, tokenID})
+	m.revokeCalls = append(m.revokeCalls, []any{orgID, serviceAccountID, tokenID, reason})
 
 	return m.errRevoke
 }
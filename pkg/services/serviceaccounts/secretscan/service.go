@@ -27,7 +27,7 @@ type WebHookClient interface {
 
 type SATokenRetriever interface {
 	ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error)
-	RevokeServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
+	RevokeServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64, reason string) error
 }
 
 // Secret Scan Service is grafana's service for checking leaked keys.
@@ -120,7 +120,7 @@ func (s *Service) CheckTokens(ctx context.Context) error {
 
 		if s.revoke {
 			if err := s.store.RevokeServiceAccountToken(
-				ctx, leakedToken.OrgID, *leakedToken.ServiceAccountId, leakedToken.ID); err != nil {
+				ctx, leakedToken.OrgID, *leakedToken.ServiceAccountId, leakedToken.ID, revokedReason(&secretscanToken)); err != nil {
 				s.logger.Error("Failed to delete leaked token. Revoke manually.",
 					"error", err, "url", secretscanToken.URL, "reported_at", secretscanToken.ReportedAt,
 					"token_id", leakedToken.ID, "token", leakedToken.Name, "org", leakedToken.OrgID,
@@ -143,6 +143,14 @@ func (s *Service) CheckTokens(ctx context.Context) error {
 	return nil
 }
 
+// revokedReason returns the reason recorded on tokens revoked after being reported as leaked.
+func revokedReason(token *Token) string {
+	if token.URL == "" {
+		return "Reported as leaked by secret scanning"
+	}
+	return fmt.Sprintf("Reported as leaked by secret scanning at %s", token.URL)
+}
+
 // filterCheckableTokens returns a list of tokens that can be checked and a map of tokens to their hashes.
 func (*Service) filterCheckableTokens(tokens []apikey.APIKey) ([]string, map[string]apikey.APIKey) {
 	hashes := make([]string, 0, len(tokens))
@@ -90,7 +90,7 @@ func TestService_CheckTokens(t *testing.T) {
 				IsRevoked:        &falseBool,
 			}},
 			wantHashes:   []string{"test-hash-1", "test-hash-2"},
-			leakedTokens: []Token{{Hash: "test-hash-2"}},
+			leakedTokens: []Token{{Hash: "test-hash-2", URL: "https://example.com/leak"}},
 			notify:       true,
 			revoke:       true,
 		},
@@ -157,6 +157,9 @@ func TestService_CheckTokens(t *testing.T) {
 			if len(tt.leakedTokens) > 0 {
 				if tt.revoke {
 					assert.Len(t, tokenStore.revokeCalls, len(tt.leakedTokens))
+					for i := range tt.leakedTokens {
+						assert.Equal(t, revokedReason(&tt.leakedTokens[i]), tokenStore.revokeCalls[i][3])
+					}
 				} else {
 					assert.Empty(t, tokenStore.revokeCalls)
 				}
@@ -29,6 +29,9 @@ type Service interface {
 		cmd *AddServiceAccountTokenCommand) (*apikey.APIKey, error)
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	ListTokens(ctx context.Context, query *GetSATokensQuery) ([]apikey.APIKey, error)
+	RotateServiceAccountToken(ctx context.Context, serviceAccountID, tokenID int64,
+		cmd *RotateServiceAccountTokenCommand) (*apikey.APIKey, error)
+	GetServiceAccountTokenSuccessor(ctx context.Context, serviceAccountID int64, token string) (*apikey.APIKey, string, error)
 
 	// API specific functions
 	MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error
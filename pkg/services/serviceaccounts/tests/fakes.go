@@ -11,6 +11,7 @@ import (
 type FakeServiceAccountService struct {
 	ExpectedAPIKey                         *apikey.APIKey
 	ExpectedErr                            error
+	ExpectedSecret                         string
 	ExpectedMigrationResult                *serviceaccounts.MigrationResult
 	ExpectedSearchOrgServiceAccountsResult *serviceaccounts.SearchOrgServiceAccountsResult
 	ExpectedServiceAccount                 *serviceaccounts.ServiceAccountDTO
@@ -71,3 +72,11 @@ func (f *FakeServiceAccountService) SearchOrgServiceAccounts(ctx context.Context
 func (f *FakeServiceAccountService) DeleteServiceAccountToken(ctx context.Context, orgID, id, tokenID int64) error {
 	return f.ExpectedErr
 }
+
+func (f *FakeServiceAccountService) RotateServiceAccountToken(ctx context.Context, id, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedErr
+}
+
+func (f *FakeServiceAccountService) GetServiceAccountTokenSuccessor(ctx context.Context, serviceAccountID int64, token string) (*apikey.APIKey, string, error) {
+	return f.ExpectedAPIKey, f.ExpectedSecret, f.ExpectedErr
+}
@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAccessRequestMigrations(mg *Migrator) {
+	accessRequestV1 := Table{
+		Name: "access_request",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "permission", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "justification", Type: DB_Text, Nullable: false},
+			{Name: "duration", Type: DB_BigInt, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "previous", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "reviewed_by", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "review_comment", Type: DB_Text, Nullable: true},
+			{Name: "reviewed_at", Type: DB_DateTime, Nullable: true},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "ended_by", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "ended_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "user_id"}},
+			{Cols: []string{"status", "expires_at"}},
+		},
+	}
+
+	mg.AddMigration("create access_request table v1", NewAddTableMigration(accessRequestV1))
+	mg.AddMigration("add unique index access_request.org_id-uid", NewAddIndexMigration(accessRequestV1, accessRequestV1.Indices[0]))
+	mg.AddMigration("add index access_request.org_id-user_id", NewAddIndexMigration(accessRequestV1, accessRequestV1.Indices[1]))
+	mg.AddMigration("add index access_request.status-expires_at", NewAddIndexMigration(accessRequestV1, accessRequestV1.Indices[2]))
+}
@@ -100,4 +100,29 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	mg.AddMigration("Add last_used_ip column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "last_used_ip", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+
+	mg.AddMigration("Add revoked_at column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "revoked_at", Type: DB_DateTime, Nullable: true,
+	}))
+
+	mg.AddMigration("Add revoked_reason column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "revoked_reason", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
+
+	// permissions restricts a service account token to a subset of the service account permissions.
+	mg.AddMigration("Add permissions column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "permissions", Type: DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("Add rotation_interval column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "rotation_interval", Type: DB_BigInt, Nullable: true,
+	}))
+
+	mg.AddMigration("Add rotated_at column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "rotated_at", Type: DB_DateTime, Nullable: true,
+	}))
 }
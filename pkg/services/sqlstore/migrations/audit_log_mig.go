@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAuditLogMigrations(mg *Migrator) {
+	auditLogV1 := Table{
+		Name: "audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "source", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "actor_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "remote_addr", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "user_agent", Type: DB_Text, Nullable: true},
+			{Name: "action", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "resource_kind", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "path", Type: DB_Text, Nullable: false},
+			{Name: "route", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "status", Type: DB_Int, Nullable: false},
+			{Name: "request_id", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "before_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "after_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "resource_kind", "resource_id"}},
+		},
+	}
+
+	mg.AddMigration("create audit_log table v1", NewAddTableMigration(auditLogV1))
+	mg.AddMigration("add index audit_log.org_id-created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[0]))
+	mg.AddMigration("add index audit_log.created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[1]))
+	mg.AddMigration("add index audit_log.org_id-resource_kind-resource_id", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[2]))
+}
@@ -71,4 +71,36 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add column refresh_interval to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "refresh_interval", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("Add column share_links_only to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "share_links_only", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+
+	snapshotShareV1 := Table{
+		Name: "dashboard_snapshot_share",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "snapshot_id", Type: DB_BigInt, Nullable: false},
+			{Name: "password", Type: DB_NVarchar, Length: 255, Nullable: true},
+			{Name: "salt", Type: DB_NVarchar, Length: 50, Nullable: true},
+			{Name: "max_views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "view_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "expires", Type: DB_DateTime, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"key"}, Type: UniqueIndex},
+			{Cols: []string{"snapshot_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_snapshot_share table v1", NewAddTableMigration(snapshotShareV1))
+	addTableIndicesMigrations(mg, "v1", snapshotShareV1)
 }
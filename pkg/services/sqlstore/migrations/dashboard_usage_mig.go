@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardUsageMigrations(mg *Migrator) {
+	dashboardUsageV1 := Table{
+		Name: "dashboard_usage",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "period", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "period_start", Type: DB_BigInt, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "queries", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "errors", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "latency_ms", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Name: "UQE_dashboard_usage_dashboard_period", Cols: []string{"org_id", "dashboard_uid", "panel_id", "period", "period_start"}, Type: UniqueIndex},
+			{Cols: []string{"period", "period_start"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage table v1", NewAddTableMigration(dashboardUsageV1))
+	addTableIndicesMigrations(mg, "v1", dashboardUsageV1)
+
+	dashboardUsageViewerV1 := Table{
+		Name: "dashboard_usage_viewer",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "period", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "period_start", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Name: "UQE_dashboard_usage_viewer_dashboard_period", Cols: []string{"org_id", "dashboard_uid", "period", "period_start", "user_id"}, Type: UniqueIndex},
+			{Cols: []string{"period", "period_start"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_viewer table v1", NewAddTableMigration(dashboardUsageViewerV1))
+	addTableIndicesMigrations(mg, "v1", dashboardUsageViewerV1)
+
+	dashboardUsageSummaryV1 := Table{
+		Name: "dashboard_usage_summary",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "views", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_viewed", Type: DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "last_viewed"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_usage_summary table v1", NewAddTableMigration(dashboardUsageSummaryV1))
+	addTableIndicesMigrations(mg, "v1", dashboardUsageSummaryV1)
+}
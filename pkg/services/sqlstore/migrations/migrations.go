@@ -131,6 +131,24 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddReceiverActionScopesMigration(mg)
 
 	ualert.AddRuleMetadata(mg)
+
+	ualert.AddRuleRelabelConfigsColumns(mg)
+
+	ualert.AddRuleActiveTimeIntervalsColumns(mg)
+
+	addScheduledReportMigrations(mg)
+
+	addDashboardUsageMigrations(mg)
+
+	addMFAMigrations(mg)
+
+	addSCIMMigrations(mg)
+
+	addAuditLogMigrations(mg)
+
+	addAccessRequestMigrations(mg)
+
+	addProvisionedCommitMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,25 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addProvisionedCommitMigrations(mg *Migrator) {
+	provisionedCommitV1 := Table{
+		Name: "provisioned_commit",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "kind", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "commit_sha", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create provisioned_commit table v1", NewAddTableMigration(provisionedCommitV1))
+	mg.AddMigration("add unique index provisioned_commit.org_id-kind-uid", NewAddIndexMigration(provisionedCommitV1, provisionedCommitV1.Indices[0]))
+}
@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addScheduledReportMigrations(mg *Migrator) {
+	scheduledReportV1 := Table{
+		Name: "scheduled_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_ids", Type: DB_Text, Nullable: true},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "time_from", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "schedule", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "formats", Type: DB_Text, Nullable: false},
+			{Name: "orientation", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "last_run_at", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_error", Type: DB_Text, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled_report table v1", NewAddTableMigration(scheduledReportV1))
+	mg.AddMigration("add unique index scheduled_report.org_id-uid", NewAddIndexMigration(scheduledReportV1, scheduledReportV1.Indices[0]))
+	mg.AddMigration("add index scheduled_report.org_id-dashboard_uid", NewAddIndexMigration(scheduledReportV1, scheduledReportV1.Indices[1]))
+}
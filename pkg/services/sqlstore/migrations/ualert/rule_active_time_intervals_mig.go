@@ -0,0 +1,20 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleActiveTimeIntervalsColumns creates a column for active time intervals in the alert_rule and alert_rule_version tables.
+func AddRuleActiveTimeIntervalsColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add active_time_intervals column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "active_time_intervals",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add active_time_intervals column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "active_time_intervals",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
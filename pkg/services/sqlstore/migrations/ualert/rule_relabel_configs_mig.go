@@ -0,0 +1,20 @@
+package ualert
+
+import (
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// AddRuleRelabelConfigsColumns creates a column for relabel configs in the alert_rule and alert_rule_version tables.
+func AddRuleRelabelConfigsColumns(mg *migrator.Migrator) {
+	mg.AddMigration("add relabel_configs column to alert_rule table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, &migrator.Column{
+		Name:     "relabel_configs",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add relabel_configs column to alert_rule_version table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, &migrator.Column{
+		Name:     "relabel_configs",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}))
+}
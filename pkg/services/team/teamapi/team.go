@@ -1,6 +1,7 @@
 package teamapi
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/preference/prefapi"
@@ -56,6 +58,7 @@ func (tapi *TeamAPI) createTeam(c *contextmodel.ReqContext) response.Response {
 			c.Logger.Error("Could not add creator to team", "error", err)
 		}
 	}
+	tapi.auditTeam(c, t.ID, auditlog.SetAfter)
 
 	return response.JSON(http.StatusOK, &util.DynMap{
 		"teamId":  t.ID,
@@ -86,12 +89,14 @@ func (tapi *TeamAPI) updateTeam(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
 	}
 
+	tapi.auditTeam(c, cmd.ID, auditlog.SetBefore)
 	if err := tapi.teamService.UpdateTeam(c.Req.Context(), &cmd); err != nil {
 		if errors.Is(err, team.ErrTeamNameTaken) {
 			return response.Error(http.StatusBadRequest, "Team name taken", err)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to update Team", err)
 	}
+	tapi.auditTeam(c, cmd.ID, auditlog.SetAfter)
 
 	return response.Success("Team updated")
 }
@@ -113,6 +118,7 @@ func (tapi *TeamAPI) deleteTeamByID(c *contextmodel.ReqContext) response.Respons
 		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
 	}
 
+	tapi.auditTeam(c, teamID, auditlog.SetBefore)
 	if err := tapi.teamService.DeleteTeam(c.Req.Context(), &team.DeleteTeamCommand{OrgID: orgID, ID: teamID}); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
 			return response.Error(http.StatusNotFound, "Failed to delete Team. ID not found", nil)
@@ -388,3 +394,18 @@ func (tapi *TeamAPI) getAccessControlMetadata(c *contextmodel.ReqContext,
 	ids := map[string]bool{resourceID: true}
 	return tapi.getMultiAccessControlMetadata(c, prefix, ids)[resourceID]
 }
+
+// auditTeam records the hash of the team in the audit log event of the request, before or after
+// the change made by the request.
+func (tapi *TeamAPI) auditTeam(c *contextmodel.ReqContext, teamID int64, set func(context.Context, any)) {
+	ctx := c.Req.Context()
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	t, err := tapi.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{OrgID: c.SignedInUser.GetOrgID(), ID: teamID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return
+	}
+	auditlog.SetResource(ctx, "teams", strconv.FormatInt(teamID, 10))
+	set(ctx, t)
+}
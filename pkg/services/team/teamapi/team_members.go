@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/team"
@@ -91,6 +92,7 @@ func (tapi *TeamAPI) addTeamMember(c *contextmodel.ReqContext) response.Response
 		return response.Error(http.StatusBadRequest, "User is already added to this team", nil)
 	}
 
+	tapi.auditTeamMembers(c, teamID, auditlog.SetBefore)
 	err = addOrUpdateTeamMember(
 		c.Req.Context(), tapi.teamPermissionsService,
 		cmd.UserID, c.SignedInUser.GetOrgID(), teamID, team.PermissionTypeMember.String(),
@@ -98,6 +100,7 @@ func (tapi *TeamAPI) addTeamMember(c *contextmodel.ReqContext) response.Response
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to add Member to Team", err)
 	}
+	tapi.auditTeamMembers(c, teamID, auditlog.SetAfter)
 
 	return response.JSON(http.StatusOK, &util.DynMap{
 		"message": "Member added to Team",
@@ -137,10 +140,12 @@ func (tapi *TeamAPI) updateTeamMember(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusNotFound, "Team member not found.", nil)
 	}
 
+	tapi.auditTeamMembers(c, teamId, auditlog.SetBefore)
 	err = addOrUpdateTeamMember(c.Req.Context(), tapi.teamPermissionsService, userId, orgId, teamId, cmd.Permission.String())
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to update team member.", err)
 	}
+	tapi.auditTeamMembers(c, teamId, auditlog.SetAfter)
 	return response.Success("Team member updated")
 }
 
@@ -176,6 +181,7 @@ func (tapi *TeamAPI) setTeamMemberships(c *contextmodel.ReqContext) response.Res
 		return response.Error(http.StatusInternalServerError, "Failed to parse team membership updates", err)
 	}
 
+	tapi.auditTeamMembers(c, teamId, auditlog.SetBefore)
 	_, err = tapi.teamPermissionsService.SetPermissions(c.Req.Context(), orgId, strconv.FormatInt(teamId, 10), teamMemberships...)
 	if err != nil {
 		if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, team.ErrTeamNotFound) {
@@ -183,6 +189,7 @@ func (tapi *TeamAPI) setTeamMemberships(c *contextmodel.ReqContext) response.Res
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to update team memberships", err)
 	}
+	tapi.auditTeamMembers(c, teamId, auditlog.SetAfter)
 
 	return response.Success("Team memberships have been updated")
 }
@@ -275,6 +282,7 @@ func (tapi *TeamAPI) removeTeamMember(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusBadRequest, "userId is invalid", err)
 	}
 
+	tapi.auditTeamMembers(c, teamId, auditlog.SetBefore)
 	teamIDString := strconv.FormatInt(teamId, 10)
 	if _, err := tapi.teamPermissionsService.SetUserPermission(c.Req.Context(), orgId, accesscontrol.User{ID: userId}, teamIDString, ""); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
@@ -287,9 +295,25 @@ func (tapi *TeamAPI) removeTeamMember(c *contextmodel.ReqContext) response.Respo
 
 		return response.Error(http.StatusInternalServerError, "Failed to remove Member from Team", err)
 	}
+	tapi.auditTeamMembers(c, teamId, auditlog.SetAfter)
 	return response.Success("Team Member removed")
 }
 
+// auditTeamMembers records the hash of the members of the team in the audit log event of the
+// request, before or after the change made by the request.
+func (tapi *TeamAPI) auditTeamMembers(c *contextmodel.ReqContext, teamID int64, set func(context.Context, any)) {
+	ctx := c.Req.Context()
+	if !auditlog.IsRecording(ctx) {
+		return
+	}
+	members, err := tapi.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: c.SignedInUser.GetOrgID(), TeamID: teamID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return
+	}
+	auditlog.SetResource(ctx, "teams", strconv.FormatInt(teamID, 10))
+	set(ctx, members)
+}
+
 // addOrUpdateTeamMember adds or updates a team member.
 //
 // Stubbable by tests.
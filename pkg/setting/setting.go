@@ -142,6 +142,18 @@ type Cfg struct {
 	// SMTP email settings
 	Smtp SmtpSettings
 
+	// Dashboard reports
+	Reporting ReportingSettings
+
+	// Dashboard usage analytics
+	DashboardUsage DashboardUsageSettings
+
+	// Audit log of API calls
+	AuditLog AuditLogSettings
+
+	// Temporary elevated access
+	AccessRequests AccessRequestsSettings
+
 	// Rendering
 	ImagesDir                      string
 	CSVsDir                        string
@@ -224,6 +236,10 @@ type Cfg struct {
 	DashboardVersionsToKeep  int
 	MinRefreshInterval       string
 	DefaultHomeDashboardPath string
+	// Dashboard linting
+	DashboardLintMaxPanels          int
+	DashboardLintMinRefreshInterval time.Duration
+	DashboardLintDisabledRules      []string
 
 	// Auth
 	LoginCookieName               string
@@ -260,6 +276,15 @@ type Cfg struct {
 	// Auth proxy settings
 	AuthProxy AuthProxySettings
 
+	// Multi-factor authentication
+	MFA MFASettings
+
+	// SCIM provisioning
+	SCIM SCIMSettings
+
+	// Session limits and lifetimes by role
+	Session SessionSettings
+
 	// OAuth
 	OAuthAutoLogin                       bool
 	OAuthCookieMaxAge                    int
@@ -317,6 +342,7 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	SATokenRotationOverlap    time.Duration
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -351,6 +377,8 @@ type Cfg struct {
 
 	// Only used in https://snapshots.raintank.io/
 	SnapshotPublicMode bool
+	// Minimum interval at which the data of snapshots can be refreshed
+	SnapshotMinRefreshInterval time.Duration
 
 	ErrTemplateName string
 
@@ -429,6 +457,13 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveReplayMaxFrames is the maximum number of frames buffered per managed stream channel
+	// for replay on subscribe. 0 disables the replay buffer.
+	LiveReplayMaxFrames int
+	// LiveReplayMaxAge is how long frames of managed stream channels are buffered for replay.
+	LiveReplayMaxAge time.Duration
+	// LiveInputs are message brokers Live subscribes to, to publish their messages into the Live pipeline.
+	LiveInputs []LiveInputSettings
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -1109,6 +1144,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.DashboardVersionsToKeep = dashboards.Key("versions_to_keep").MustInt(20)
 	cfg.MinRefreshInterval = valueAsString(dashboards, "min_refresh_interval", "5s")
 	cfg.DefaultHomeDashboardPath = dashboards.Key("default_home_dashboard_path").MustString("")
+	cfg.DashboardLintMaxPanels = dashboards.Key("lint_max_panels").MustInt(50)
+	cfg.DashboardLintMinRefreshInterval = dashboards.Key("lint_min_refresh_interval").MustDuration(time.Minute)
+	cfg.DashboardLintDisabledRules = util.SplitString(dashboards.Key("lint_disabled_rules").MustString(""))
 
 	if err := readUserSettings(iniFile, cfg); err != nil {
 		return err
@@ -1237,9 +1275,24 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAuthExtJWTSettings()
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
+	cfg.readMFASettings()
+	cfg.readSCIMSettings()
+	if err := cfg.readSessionPolicySettings(); err != nil {
+		return err
+	}
 	if err := cfg.readSmtpSettings(); err != nil {
 		return err
 	}
+	cfg.readReportingSettings()
+	if err := cfg.readDashboardUsageSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readAuditLogSettings(); err != nil {
+		return err
+	}
+	if err := cfg.readAccessRequestsSettings(); err != nil {
+		return err
+	}
 	if err := cfg.readAnnotationSettings(); err != nil {
 		return err
 	}
@@ -1752,6 +1805,15 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+
+	rotationOverlap, err := gtime.ParseDuration(valueAsString(serviceAccount, "token_rotation_overlap", "24h"))
+	if err != nil {
+		return err
+	}
+	if rotationOverlap < 0 {
+		return errors.New("token_rotation_overlap must not be negative")
+	}
+	cfg.SATokenRotationOverlap = rotationOverlap
 	return nil
 }
 
@@ -1873,6 +1935,7 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.SnapshotMinRefreshInterval = snapshots.Key("min_refresh_interval").MustDuration(5 * time.Minute)
 
 	return nil
 }
@@ -2026,6 +2089,12 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
 
+	cfg.LiveReplayMaxFrames = section.Key("replay_max_frames").MustInt(1000)
+	if cfg.LiveReplayMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] replay_max_frames", cfg.LiveReplayMaxFrames)
+	}
+	cfg.LiveReplayMaxAge = section.Key("replay_max_age").MustDuration(5 * time.Minute)
+
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
 
@@ -2044,7 +2113,9 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 
 	cfg.LiveAllowedOrigins = originPatterns
-	return nil
+
+	cfg.LiveInputs, err = readLiveInputSettings(iniFile)
+	return err
 }
 
 func (cfg *Cfg) readPublicDashboardsSettings() {
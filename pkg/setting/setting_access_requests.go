@@ -0,0 +1,32 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type AccessRequestsSettings struct {
+	// Enabled enables requesting temporary roles and permissions.
+	Enabled bool
+	// ApproverRole is the basic role which is granted the approval of requests.
+	ApproverRole string
+	// MaxDuration is the maximum duration of the access granted by a request.
+	MaxDuration time.Duration
+}
+
+func (cfg *Cfg) readAccessRequestsSettings() error {
+	sec := cfg.Raw.Section("access_requests")
+	cfg.AccessRequests.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.AccessRequests.ApproverRole = valueAsString(sec, "approver_role", "Admin")
+	switch cfg.AccessRequests.ApproverRole {
+	case "Viewer", "Editor", "Admin":
+	default:
+		return fmt.Errorf("[access_requests] invalid approver_role %q", cfg.AccessRequests.ApproverRole)
+	}
+	cfg.AccessRequests.MaxDuration = sec.Key("max_duration").MustDuration(24 * time.Hour)
+	if cfg.AccessRequests.MaxDuration <= 0 {
+		return errors.New("[access_requests] max_duration must be positive")
+	}
+	return nil
+}
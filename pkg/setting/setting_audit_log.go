@@ -0,0 +1,75 @@
+package setting
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Sinks of the audit log
+const (
+	AuditLogSinkDatabase = "database"
+	AuditLogSinkFile     = "file"
+	AuditLogSinkSyslog   = "syslog"
+)
+
+type AuditLogSettings struct {
+	// Enabled enables recording the mutating calls to the APIs.
+	Enabled bool
+	// Sinks are the destinations of the events: database, file and syslog.
+	Sinks []string
+	// Retention is the age after which events are deleted from the database.
+	Retention time.Duration
+	// ExcludePaths are path prefixes of requests that are not recorded.
+	ExcludePaths []string
+	// BufferSize is the number of events that are queued for writing to the sinks.
+	BufferSize int
+
+	// File sink, FilePath defaults to audit.log in the logs directory
+	FilePath    string
+	FileMaxSize int
+	FileMaxDays int64
+
+	// Syslog sink
+	SyslogNetwork  string
+	SyslogAddress  string
+	SyslogFacility string
+	SyslogTag      string
+}
+
+func (cfg *Cfg) readAuditLogSettings() error {
+	sec := cfg.Raw.Section("audit_log")
+	cfg.AuditLog.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.AuditLog.Sinks = util.SplitString(valueAsString(sec, "sinks", AuditLogSinkDatabase))
+	for _, sink := range cfg.AuditLog.Sinks {
+		if !slices.Contains([]string{AuditLogSinkDatabase, AuditLogSinkFile, AuditLogSinkSyslog}, sink) {
+			return fmt.Errorf("[audit_log] unknown sink %q", sink)
+		}
+	}
+
+	var err error
+	cfg.AuditLog.Retention, err = gtime.ParseDuration(valueAsString(sec, "retention", "90d"))
+	if err != nil {
+		return err
+	}
+	cfg.AuditLog.ExcludePaths = util.SplitString(valueAsString(sec, "exclude_paths", "/api/ds/query,/api/frontend-metrics,/api/live/"))
+	cfg.AuditLog.BufferSize = sec.Key("buffer_size").MustInt(1000)
+	if cfg.AuditLog.BufferSize < 0 {
+		return errors.New("[audit_log] buffer_size can't be negative")
+	}
+
+	cfg.AuditLog.FilePath = valueAsString(sec, "file_path", "")
+	cfg.AuditLog.FileMaxSize = sec.Key("file_max_size_mb").MustInt(256)
+	cfg.AuditLog.FileMaxDays = sec.Key("file_max_days").MustInt64(90)
+
+	cfg.AuditLog.SyslogNetwork = valueAsString(sec, "syslog_network", "")
+	cfg.AuditLog.SyslogAddress = valueAsString(sec, "syslog_address", "")
+	cfg.AuditLog.SyslogFacility = valueAsString(sec, "syslog_facility", "local0")
+	cfg.AuditLog.SyslogTag = valueAsString(sec, "syslog_tag", "grafana-audit")
+	return nil
+}
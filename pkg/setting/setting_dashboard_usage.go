@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+type DashboardUsageSettings struct {
+	// Enabled enables recording the views and queries of dashboards.
+	Enabled bool
+	// FlushInterval is how often the recorded usage is written to the database.
+	FlushInterval time.Duration
+	// RollupAfter is the age after which the daily usage is rolled up into monthly usage.
+	RollupAfter time.Duration
+	// Retention is the age after which the usage is deleted. The total views and the
+	// last view of dashboards are kept.
+	Retention time.Duration
+}
+
+func (cfg *Cfg) readDashboardUsageSettings() error {
+	sec := cfg.Raw.Section("dashboard_usage")
+	cfg.DashboardUsage.Enabled = sec.Key("enabled").MustBool(true)
+	cfg.DashboardUsage.FlushInterval = sec.Key("flush_interval").MustDuration(time.Minute)
+
+	var err error
+	cfg.DashboardUsage.RollupAfter, err = gtime.ParseDuration(valueAsString(sec, "rollup_after", "90d"))
+	if err != nil {
+		return err
+	}
+	cfg.DashboardUsage.Retention, err = gtime.ParseDuration(valueAsString(sec, "retention", "395d"))
+	if err != nil {
+		return err
+	}
+
+	// The daily usage is needed for the "last 30 days" search sort options
+	if minRollupAfter := 31 * 24 * time.Hour; cfg.DashboardUsage.RollupAfter < minRollupAfter {
+		cfg.DashboardUsage.RollupAfter = minRollupAfter
+	}
+	if cfg.DashboardUsage.Retention < cfg.DashboardUsage.RollupAfter {
+		return fmt.Errorf("[dashboard_usage] retention must be longer than rollup_after")
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package setting
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	LiveInputTypeMQTT = "mqtt"
+	LiveInputTypeNATS = "nats"
+)
+
+// LiveInputSettings configures a message broker that Grafana Live subscribes to. Messages
+// are published into the Live pipeline in a channel built from the channel prefix and
+// the topic (or subject) of the message.
+type LiveInputSettings struct {
+	Name string
+	// Type is either mqtt or nats.
+	Type string
+	// URL of the broker, e.g. tcp://localhost:1883, ssl://localhost:8883 or nats://localhost:4222.
+	URL      string
+	Username string
+	Password string
+	// Token is used to authenticate with NATS, it is ignored for MQTT.
+	Token    string
+	ClientID string
+	// Topics are MQTT topic filters or NATS subjects to subscribe to. Wildcards are supported.
+	Topics []string
+	// QoS is the MQTT quality of service of the subscriptions, 0 or 1.
+	QoS   int
+	OrgID int64
+	// ChannelPrefix is the scope and namespace of the channels of the messages, e.g. stream/factory.
+	ChannelPrefix string
+}
+
+// readLiveInputSettings reads broker inputs of Grafana Live. They look like:
+// [live.input.<name>]
+// type = mqtt
+// url = tcp://localhost:1883
+// topics = factory/#
+// channel_prefix = stream/factory
+func readLiveInputSettings(iniFile *ini.File) ([]LiveInputSettings, error) {
+	var inputs []LiveInputSettings
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), "live.input.") {
+			continue
+		}
+		input := LiveInputSettings{
+			Name:          strings.TrimPrefix(section.Name(), "live.input."),
+			Type:          section.Key("type").MustString(""),
+			URL:           section.Key("url").MustString(""),
+			Username:      section.Key("username").MustString(""),
+			Password:      section.Key("password").MustString(""),
+			Token:         section.Key("token").MustString(""),
+			ClientID:      section.Key("client_id").MustString(""),
+			Topics:        util.SplitString(section.Key("topics").MustString("")),
+			QoS:           section.Key("qos").MustInt(0),
+			OrgID:         section.Key("org_id").MustInt64(1),
+			ChannelPrefix: strings.Trim(section.Key("channel_prefix").MustString(""), "/"),
+		}
+		if err := input.validate(); err != nil {
+			return nil, fmt.Errorf("[%s]: %w", section.Name(), err)
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+func (s LiveInputSettings) validate() error {
+	switch s.Type {
+	case LiveInputTypeMQTT, LiveInputTypeNATS:
+	default:
+		return fmt.Errorf("unsupported input type %q, expected %s or %s", s.Type, LiveInputTypeMQTT, LiveInputTypeNATS)
+	}
+	if s.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+	if len(s.Topics) == 0 {
+		return fmt.Errorf("at least one topic is required")
+	}
+	if s.QoS < 0 || s.QoS > 1 {
+		return fmt.Errorf("unsupported qos %d, expected 0 or 1", s.QoS)
+	}
+	if strings.Count(s.ChannelPrefix, "/") < 1 {
+		return fmt.Errorf("channel_prefix must contain a scope and a namespace, e.g. stream/factory")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package setting
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type MFASettings struct {
+	// Enabled enables the second factor for users who log in with a Grafana password.
+	Enabled bool
+	// RequiredForServerAdmins requires Grafana server administrators to use a second factor.
+	RequiredForServerAdmins bool
+	// Issuer is the name of the TOTP issuer shown by authenticator apps.
+	Issuer string
+	// LoginTimeout is the time a user has to provide the second factor after the password.
+	LoginTimeout time.Duration
+	// WebAuthnRPID is the relying party ID of WebAuthn security keys, the domain of Grafana.
+	WebAuthnRPID string
+	// WebAuthnOrigins are the origins allowed to use WebAuthn security keys.
+	WebAuthnOrigins []string
+}
+
+func (cfg *Cfg) readMFASettings() {
+	sec := cfg.Raw.Section("auth.mfa")
+	cfg.MFA.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.MFA.RequiredForServerAdmins = sec.Key("required_for_server_admins").MustBool(false)
+	cfg.MFA.Issuer = valueAsString(sec, "issuer", "Grafana")
+	cfg.MFA.LoginTimeout = sec.Key("login_timeout").MustDuration(5 * time.Minute)
+
+	var rootURL *url.URL
+	if u, err := url.Parse(cfg.AppURL); err == nil {
+		rootURL = u
+	}
+
+	cfg.MFA.WebAuthnRPID = valueAsString(sec, "webauthn_rp_id", "")
+	if cfg.MFA.WebAuthnRPID == "" && rootURL != nil {
+		cfg.MFA.WebAuthnRPID = rootURL.Hostname()
+	}
+	cfg.MFA.WebAuthnOrigins = util.SplitString(valueAsString(sec, "webauthn_origins", ""))
+	if len(cfg.MFA.WebAuthnOrigins) == 0 && rootURL != nil {
+		cfg.MFA.WebAuthnOrigins = []string{rootURL.Scheme + "://" + rootURL.Host}
+	}
+}
@@ -0,0 +1,26 @@
+package setting
+
+import (
+	"time"
+)
+
+type ReportingSettings struct {
+	// Enabled enables the scheduling of dashboard reports.
+	Enabled bool
+	// RenderTimeout is the timeout of rendering a panel of a report.
+	RenderTimeout time.Duration
+	// ImageWidth and ImageHeight are the size of rendered panels.
+	ImageWidth  int
+	ImageHeight int
+	// MaxRecipients is the maximum number of recipients of a report.
+	MaxRecipients int
+}
+
+func (cfg *Cfg) readReportingSettings() {
+	sec := cfg.Raw.Section("reporting")
+	cfg.Reporting.Enabled = sec.Key("enabled").MustBool(true)
+	cfg.Reporting.RenderTimeout = sec.Key("render_timeout").MustDuration(time.Minute)
+	cfg.Reporting.ImageWidth = sec.Key("image_width").MustInt(1200)
+	cfg.Reporting.ImageHeight = sec.Key("image_height").MustInt(600)
+	cfg.Reporting.MaxRecipients = sec.Key("max_recipients").MustInt(50)
+}
@@ -0,0 +1,23 @@
+package setting
+
+import (
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+type SCIMSettings struct {
+	// Enabled enables the SCIM 2.0 API to provision users and teams.
+	Enabled bool
+	// DefaultOrgRole is the role of users added to an organization through SCIM.
+	DefaultOrgRole string
+}
+
+func (cfg *Cfg) readSCIMSettings() {
+	sec := cfg.Raw.Section("auth.scim")
+	cfg.SCIM.Enabled = sec.Key("enabled").MustBool(false)
+	cfg.SCIM.DefaultOrgRole = sec.Key("default_org_role").In(
+		string(identity.RoleViewer), []string{
+			string(identity.RoleViewer),
+			string(identity.RoleEditor),
+			string(identity.RoleAdmin),
+		})
+}
@@ -0,0 +1,106 @@
+package setting
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+const (
+	// ConcurrentSessionsEvictOldest revokes the oldest sessions of a user to make room for a new one.
+	ConcurrentSessionsEvictOldest = "evict_oldest"
+	// ConcurrentSessionsDeny rejects logins of users who have the maximum number of sessions.
+	ConcurrentSessionsDeny = "deny"
+)
+
+// SessionPolicy restricts the sessions of users.
+type SessionPolicy struct {
+	MaxInactiveLifetime time.Duration
+	MaxLifetime         time.Duration
+	// MaxConcurrentSessions is the maximum number of active sessions of a user, 0 for no limit.
+	MaxConcurrentSessions int64
+}
+
+type SessionSettings struct {
+	// ConcurrentSessionsPolicy is applied on login when a user has the maximum number of sessions.
+	ConcurrentSessionsPolicy string
+	// MaxConcurrentSessions is the limit of users without a role policy, 0 for no limit.
+	MaxConcurrentSessions int64
+	// RolePolicies are the policies of the users by their highest role, Grafana server
+	// administrators have the Admin policy. Role lifetimes can't exceed the default ones.
+	RolePolicies map[identity.RoleType]SessionPolicy
+}
+
+// DefaultSessionPolicy returns the session policy of the users without a role policy.
+func (cfg *Cfg) DefaultSessionPolicy() SessionPolicy {
+	return SessionPolicy{
+		MaxInactiveLifetime:   cfg.LoginMaxInactiveLifetime,
+		MaxLifetime:           cfg.LoginMaxLifetime,
+		MaxConcurrentSessions: cfg.Session.MaxConcurrentSessions,
+	}
+}
+
+// SessionPolicy returns the session policy of the users with the role.
+func (cfg *Cfg) SessionPolicy(role identity.RoleType) SessionPolicy {
+	if p, ok := cfg.Session.RolePolicies[role]; ok {
+		return p
+	}
+	return cfg.DefaultSessionPolicy()
+}
+
+func (cfg *Cfg) readSessionPolicySettings() error {
+	auth := cfg.Raw.Section("auth")
+	cfg.Session = SessionSettings{
+		ConcurrentSessionsPolicy: auth.Key("login_concurrent_sessions_policy").In(ConcurrentSessionsEvictOldest,
+			[]string{ConcurrentSessionsEvictOldest, ConcurrentSessionsDeny}),
+		MaxConcurrentSessions: auth.Key("login_maximum_concurrent_sessions").MustInt64(0),
+		RolePolicies:          make(map[identity.RoleType]SessionPolicy),
+	}
+
+	for _, role := range []identity.RoleType{identity.RoleViewer, identity.RoleEditor, identity.RoleAdmin} {
+		sec, err := cfg.Raw.GetSection("auth.session." + strings.ToLower(string(role)))
+		if err != nil {
+			continue
+		}
+		inactive := valueAsString(sec, "login_maximum_inactive_lifetime_duration", "")
+		lifetime := valueAsString(sec, "login_maximum_lifetime_duration", "")
+		concurrent := valueAsString(sec, "login_maximum_concurrent_sessions", "")
+		if inactive == "" && lifetime == "" && concurrent == "" {
+			continue
+		}
+
+		policy := cfg.DefaultSessionPolicy()
+		if inactive != "" {
+			if policy.MaxInactiveLifetime, err = gtime.ParseDuration(inactive); err != nil {
+				return err
+			}
+		}
+		if lifetime != "" {
+			if policy.MaxLifetime, err = gtime.ParseDuration(lifetime); err != nil {
+				return err
+			}
+		}
+		if concurrent != "" {
+			if policy.MaxConcurrentSessions, err = strconv.ParseInt(concurrent, 10, 64); err != nil {
+				return err
+			}
+		}
+
+		// Expired sessions are cleaned up with the default lifetimes, role policies can only be stricter.
+		if policy.MaxInactiveLifetime > cfg.LoginMaxInactiveLifetime {
+			cfg.Logger.Warn("Session inactive lifetime of role exceeds login_maximum_inactive_lifetime_duration, using the default", "role", role)
+			policy.MaxInactiveLifetime = cfg.LoginMaxInactiveLifetime
+		}
+		if policy.MaxLifetime > cfg.LoginMaxLifetime {
+			cfg.Logger.Warn("Session lifetime of role exceeds login_maximum_lifetime_duration, using the default", "role", role)
+			policy.MaxLifetime = cfg.LoginMaxLifetime
+		}
+		cfg.Session.RolePolicies[role] = policy
+	}
+
+	return nil
+}
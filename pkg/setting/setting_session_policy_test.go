@@ -0,0 +1,47 @@
+package setting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+func TestSessionPolicySettings(t *testing.T) {
+	cfg, err := NewCfgFromBytes([]byte(`
+[auth]
+login_maximum_inactive_lifetime_duration = 1d
+login_maximum_lifetime_duration = 7d
+login_maximum_concurrent_sessions = 5
+login_concurrent_sessions_policy = deny
+
+[auth.session.admin]
+login_maximum_inactive_lifetime_duration = 1h
+login_maximum_concurrent_sessions = 2
+
+[auth.session.editor]
+login_maximum_lifetime_duration = 30d
+
+[auth.session.viewer]
+login_maximum_inactive_lifetime_duration =
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, ConcurrentSessionsDeny, cfg.Session.ConcurrentSessionsPolicy)
+	require.Equal(t, SessionPolicy{
+		MaxInactiveLifetime:   time.Hour,
+		MaxLifetime:           7 * 24 * time.Hour,
+		MaxConcurrentSessions: 2,
+	}, cfg.SessionPolicy(identity.RoleAdmin))
+	require.Equal(t, SessionPolicy{
+		MaxInactiveLifetime:   24 * time.Hour,
+		MaxLifetime:           7 * 24 * time.Hour,
+		MaxConcurrentSessions: 5,
+	}, cfg.SessionPolicy(identity.RoleEditor), "role lifetimes can't exceed the default ones")
+
+	_, ok := cfg.Session.RolePolicies[identity.RoleViewer]
+	require.False(t, ok, "empty role sections have no policy")
+	require.Equal(t, cfg.DefaultSessionPolicy(), cfg.SessionPolicy(identity.RoleViewer))
+}
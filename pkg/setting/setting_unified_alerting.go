@@ -65,6 +65,9 @@ const (
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+
+	incidentCorrelationDefaultWindow    = 5 * time.Minute
+	incidentCorrelationDefaultRetention = 30 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -108,6 +111,7 @@ type UnifiedAlertingSettings struct {
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	SkipClustering                bool
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	IncidentCorrelation           UnifiedAlertingIncidentCorrelationSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
 
@@ -171,6 +175,16 @@ type UnifiedAlertingStateHistorySettings struct {
 	ExternalLabels        map[string]string
 }
 
+type UnifiedAlertingIncidentCorrelationSettings struct {
+	Enabled bool
+	// Labels are the names of the labels used to correlate alert instances.
+	Labels []string
+	// Window is the maximum time between the last firing alert instance of an incident and a new one.
+	Window time.Duration
+	// Retention is how long resolved incidents are kept.
+	Retention time.Duration
+}
+
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
 // It hides the implementation details of the Enabled and simplifies its usage.
 func (u *UnifiedAlertingSettings) IsEnabled() bool {
@@ -420,6 +434,18 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	incidentCorrelation := iniFile.Section("unified_alerting.incident_correlation")
+	uaCfgIncidentCorrelation := UnifiedAlertingIncidentCorrelationSettings{
+		Enabled:   incidentCorrelation.Key("enabled").MustBool(false),
+		Labels:    util.SplitString(incidentCorrelation.Key("labels").MustString("cluster, namespace, service")),
+		Window:    incidentCorrelation.Key("window").MustDuration(incidentCorrelationDefaultWindow),
+		Retention: incidentCorrelation.Key("retention").MustDuration(incidentCorrelationDefaultRetention),
+	}
+	if uaCfgIncidentCorrelation.Window <= 0 {
+		return fmt.Errorf("value of setting 'window' in section 'unified_alerting.incident_correlation' must be greater than zero")
+	}
+	uaCfg.IncidentCorrelation = uaCfgIncidentCorrelation
+
 	rr := iniFile.Section("recording_rules")
 	uaCfgRecordingRules := RecordingRuleSettings{
 		Enabled:           rr.Key("enabled").MustBool(false),